@@ -0,0 +1,94 @@
+"""
+Context8 all-in-one: producer and MCP server in one process.
+
+Runs the NautilusTrader producer (with embedded analytics) and the stdio
+MCP server side by side on the in-memory event bus, so trying Context8
+needs no Docker or Redis:
+
+    python context8.py                      # producer + MCP server on stdio
+    python context8.py --no-producer        # MCP server only (e.g. with --redis-url)
+    python context8.py --redis-url redis://localhost:6379
+
+Point an MCP client (Cursor, Claude Desktop) at this command; the process
+exits when the client disconnects. The MCP protocol owns stdout, so
+everything else the process writes to stdout (NautilusTrader's logger
+included) is redirected to stderr.
+"""
+import argparse
+import asyncio
+import io
+import os
+import sys
+import threading
+
+ROOT = os.path.dirname(os.path.abspath(__file__))
+sys.path[:0] = [os.path.join(ROOT, "mcp-server"), os.path.join(ROOT, "producer")]
+
+DEFAULT_BUS_URL = "memory://context8"
+
+
+def parse_args(argv: list[str] | None = None) -> argparse.Namespace:
+    parser = argparse.ArgumentParser(prog="context8", description="Run the Context8 stack in one process")
+    parser.add_argument(
+        "--redis-url",
+        default=DEFAULT_BUS_URL,
+        help=f"Bus shared by producer and MCP server (default: {DEFAULT_BUS_URL}; redis://... for Redis)",
+    )
+    parser.add_argument("--no-producer", action="store_true", help="Run only the MCP server")
+    parser.add_argument("--config", default=os.getenv("CONFIG_FILE"), help="Producer YAML or TOML config file")
+    return parser.parse_args(argv)
+
+
+def _claim_stdout():
+    """Keep the real stdout for MCP and send fd 1 (and sys.stdout) to stderr."""
+    mcp_fd = os.dup(1)
+    os.dup2(2, 1)
+    sys.stdout = sys.stderr
+    return io.TextIOWrapper(os.fdopen(mcp_fd, "wb"), encoding="utf-8", line_buffering=True)
+
+
+def _run_mcp_server(mcp_stdout, exit_process: bool) -> None:
+    import anyio
+    import server
+
+    try:
+        asyncio.run(server.main(stdout=anyio.wrap_file(mcp_stdout)))
+    finally:
+        if exit_process:
+            # The MCP client is gone; the producer's node.run() has no stop hook from here
+            print("context8: MCP client disconnected, exiting", file=sys.stderr)
+            os._exit(0)
+
+
+def main(argv: list[str] | None = None) -> None:
+    args = parse_args(argv)
+    os.environ["REDIS_URL"] = args.redis_url
+
+    from src.event_bus import get_memory_bus, is_memory_url
+    import memory_bus
+
+    if is_memory_url(args.redis_url):
+        # Writer leases need Redis Lua scripts
+        os.environ["NT_ENABLE_MULTI_INSTANCE"] = "false"
+        name = memory_bus.bus_name(args.redis_url)
+        memory_bus.register_bus(name, get_memory_bus(name))
+
+    mcp_thread = threading.Thread(
+        target=_run_mcp_server, args=(_claim_stdout(), not args.no_producer), name="mcp-server", daemon=True
+    )
+    mcp_thread.start()
+
+    if args.no_producer:
+        mcp_thread.join()
+        return
+
+    # The producer installs signal handlers, so it keeps the main thread;
+    # the process exits when either side stops (the MCP thread is a daemon)
+    from src.main import main as producer_main
+
+    producer_argv = ["--config", args.config] if args.config else []
+    producer_main(producer_argv + ["serve"])
+
+
+if __name__ == "__main__":
+    main()
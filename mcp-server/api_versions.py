@@ -0,0 +1,75 @@
+"""
+Versioned REST paths and deprecated aliases.
+
+Public REST endpoints live under a version prefix (/v1/report). Paths
+served before versioning (/api/report) stay available as aliases of the
+versioned route and mark every response as deprecated:
+
+    Deprecation: @1792108800                  (RFC 9745, when the alias was deprecated)
+    Sunset: Sat, 17 Apr 2027 00:00:00 GMT     (RFC 8594, API_SUNSET)
+    Link: </v1/report>; rel="successor-version"
+
+Aliases are left out of the OpenAPI document and counted in
+mcp_rest_deprecated_requests_total so operators can see who still uses
+them before the sunset date.
+"""
+import os
+from datetime import date, datetime, timezone
+from email.utils import format_datetime
+
+from starlette.routing import Route
+
+import metrics
+
+API_VERSION = "v1"
+
+# When the unversioned paths were deprecated
+DEPRECATED_ON = date(2026, 10, 16)
+
+# Default removal date of deprecated aliases (override with API_SUNSET=YYYY-MM-DD)
+DEFAULT_SUNSET = date(2027, 4, 17)
+
+
+def versioned_path(path: str) -> str:
+    """Path under the current API version prefix."""
+    return f"/{API_VERSION}{path}"
+
+
+def sunset_date() -> date:
+    value = os.getenv("API_SUNSET")
+    return date.fromisoformat(value) if value else DEFAULT_SUNSET
+
+
+def _midnight_utc(day: date) -> datetime:
+    return datetime(day.year, day.month, day.day, tzinfo=timezone.utc)
+
+
+def deprecation_headers(successor: str) -> dict[str, str]:
+    """Headers announcing a deprecated alias and its replacement."""
+    return {
+        "Deprecation": f"@{int(_midnight_utc(DEPRECATED_ON).timestamp())}",
+        "Sunset": format_datetime(_midnight_utc(sunset_date()), usegmt=True),
+        "Link": f'<{successor}>; rel="successor-version"',
+    }
+
+
+def deprecated_alias(endpoint, successor: str):
+    """Wrap a route handler so its responses carry deprecation headers."""
+
+    async def alias(request):
+        metrics.rest_deprecated_requests.labels(path=request.url.path).inc()
+        response = await endpoint(request)
+        response.headers.update(deprecation_headers(successor))
+        return response
+
+    alias.__name__ = f"{endpoint.__name__}_deprecated"
+    return alias
+
+
+def versioned_routes(path: str, endpoint, methods: list[str], legacy: tuple[str, ...] = ()) -> list[Route]:
+    """The versioned route for path plus deprecated aliases at the legacy paths."""
+    current = versioned_path(path)
+    return [Route(current, endpoint, methods=methods)] + [
+        Route(old, deprecated_alias(endpoint, current), methods=methods, include_in_schema=False)
+        for old in legacy
+    ]
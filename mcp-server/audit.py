@@ -0,0 +1,120 @@
+"""
+Audit log of tool invocations.
+
+Every tool call and REST report request is appended to the `mcp:audit`
+Redis Stream (tool, symbol, api key, latency, outcome), trimmed to
+AUDIT_MAXLEN entries.
+"""
+import logging
+import os
+import time
+from contextvars import ContextVar
+from typing import Any
+
+from cache import RedisCache
+from correlation import current_correlation_id
+
+logger = logging.getLogger(__name__)
+
+AUDIT_STREAM = "mcp:audit"
+DEFAULT_AUDIT_MAXLEN = 100000
+
+API_KEY_HEADER = "X-API-Key"
+
+api_key_var: ContextVar[str | None] = ContextVar("api_key", default=None)
+
+
+def mask_api_key(api_key: str | None) -> str:
+    """Mask an API key so only its last four characters are stored."""
+    if not api_key:
+        return "anonymous"
+    return f"...{api_key[-4:]}"
+
+
+class APIKeyContextMiddleware:
+    """ASGI middleware that exposes the request's API key to the audit log."""
+
+    def __init__(self, app):
+        self.app = app
+
+    async def __call__(self, scope, receive, send):
+        if scope["type"] not in ("http", "websocket"):
+            await self.app(scope, receive, send)
+            return
+
+        api_key = None
+        for name, value in scope.get("headers", []):
+            if name.decode("latin-1").lower() == API_KEY_HEADER.lower():
+                api_key = value.decode("latin-1")
+                break
+
+        token = api_key_var.set(api_key)
+        try:
+            await self.app(scope, receive, send)
+        finally:
+            api_key_var.reset(token)
+
+
+class AuditLog:
+    """Writes and queries tool invocation records in Redis."""
+
+    def __init__(self, cache: RedisCache, maxlen: int | None = None):
+        self.cache = cache
+        if maxlen is None:
+            maxlen = int(os.getenv("AUDIT_MAXLEN", str(DEFAULT_AUDIT_MAXLEN)))
+        self.maxlen = maxlen
+
+    async def record(
+        self,
+        tool: str,
+        symbol: str | None,
+        latency_ms: float,
+        outcome: str,
+    ) -> None:
+        """Append an invocation record. Failures never fail the request."""
+        if not self.cache.client:
+            return
+
+        fields = {
+            "ts": str(int(time.time() * 1000)),
+            "tool": tool,
+            "symbol": symbol or "",
+            "api_key": mask_api_key(api_key_var.get()),
+            "latency_ms": f"{latency_ms:.2f}",
+            "outcome": outcome,
+            "correlation_id": current_correlation_id(),
+        }
+
+        try:
+            await self.cache.client.xadd(AUDIT_STREAM, fields, maxlen=self.maxlen, approximate=True)
+        except Exception as e:
+            logger.warning(f"Failed to write audit record for {tool}: {e}")
+
+    async def recent(
+        self,
+        count: int = 100,
+        tool: str | None = None,
+        symbol: str | None = None,
+        api_key: str | None = None,
+    ) -> list[dict[str, Any]]:
+        """
+        Return the most recent invocations, newest first.
+
+        Filters are applied after reading `count` entries, so fewer
+        records may be returned when filtering.
+        """
+        if not self.cache.client:
+            raise RuntimeError("Redis client not connected")
+
+        entries = await self.cache.client.xrevrange(AUDIT_STREAM, count=count)
+
+        records = []
+        for entry_id, fields in entries:
+            if tool and fields.get("tool") != tool:
+                continue
+            if symbol and fields.get("symbol") != symbol:
+                continue
+            if api_key and fields.get("api_key") != mask_api_key(api_key):
+                continue
+            records.append({"id": entry_id, **fields})
+        return records
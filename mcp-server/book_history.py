@@ -0,0 +1,77 @@
+"""
+Liquidity heatmaps from the producer's order book history.
+
+The producer snapshots each symbol's top-N book every few seconds into the
+sorted set book_history:{symbol} (scored by epoch millis; members are
+base64 zlib-compressed JSON {"ts", "mid", "bids", "asks"}). This module
+turns a window of snapshots into a price x time matrix: rows are price
+buckets of bucket_bps (relative to the latest mid), columns are snapshots,
+and cells hold the resting quantity in that bucket at that time.
+"""
+import base64
+import json
+import math
+import zlib
+from datetime import datetime, timezone
+from typing import Any
+
+DEFAULT_LOOKBACK_SEC = 900
+DEFAULT_BUCKET_BPS = 5.0
+
+# Price buckets are widened to keep the matrix within this many rows
+MAX_PRICE_BUCKETS = 200
+
+
+def decode_snapshot(member: str | bytes) -> dict[str, Any]:
+    """Decode a book_history sorted-set member."""
+    return json.loads(zlib.decompress(base64.b64decode(member)))
+
+
+def _iso(ms: int) -> str:
+    return datetime.fromtimestamp(ms / 1000, tz=timezone.utc).isoformat().replace("+00:00", "Z")
+
+
+def build_heatmap(snapshots: list[dict[str, Any]], bucket_bps: float) -> dict[str, Any]:
+    """Price x time liquidity matrix from snapshots ordered by time.
+
+    Returns:
+        times/mids per column, price_buckets (lower bound of each row,
+        ascending), and bid_qty/ask_qty matrices indexed [row][column].
+        bucket_bps is the bucket width actually used, which is wider than
+        requested when the price range would exceed MAX_PRICE_BUCKETS rows.
+    """
+    if not snapshots:
+        return {
+            "snapshot_count": 0, "bucket_bps": bucket_bps, "times": [], "mids": [],
+            "price_buckets": [], "bid_qty": [], "ask_qty": [],
+        }
+
+    prices = [p for snap in snapshots for p, _ in snap["bids"] + snap["asks"]]
+    low, high = min(prices), max(prices)
+    reference_mid = snapshots[-1]["mid"]
+
+    size = reference_mid * bucket_bps / 10_000
+    rows = math.floor(high / size) - math.floor(low / size) + 1
+    if rows > MAX_PRICE_BUCKETS:
+        # Two rows of slack for partial buckets at either end
+        bucket_bps *= rows / (MAX_PRICE_BUCKETS - 2)
+        size = reference_mid * bucket_bps / 10_000
+        rows = math.floor(high / size) - math.floor(low / size) + 1
+    first = math.floor(low / size)
+
+    bid_qty = [[0.0] * len(snapshots) for _ in range(rows)]
+    ask_qty = [[0.0] * len(snapshots) for _ in range(rows)]
+    for col, snap in enumerate(snapshots):
+        for matrix, levels in ((bid_qty, snap["bids"]), (ask_qty, snap["asks"])):
+            for price, qty in levels:
+                matrix[math.floor(price / size) - first][col] += qty
+
+    return {
+        "snapshot_count": len(snapshots),
+        "bucket_bps": round(bucket_bps, 4),
+        "times": [_iso(snap["ts"]) for snap in snapshots],
+        "mids": [snap["mid"] for snap in snapshots],
+        "price_buckets": [round((first + row) * size, 8) for row in range(rows)],
+        "bid_qty": [[round(q, 8) for q in row] for row in bid_qty],
+        "ask_qty": [[round(q, 8) for q in row] for row in ask_qty],
+    }
@@ -0,0 +1,596 @@
+"""
+Redis cache reader shared by the Context8 MCP transports.
+Reports are read from `report:{symbol}` hashes written by the producer:
+one JSON field per report section, which the fast and slow cycles (or the
+producer's fast and deep nodes) update separately. HGETALL returns them
+all and assemble_report rebuilds the document; a report still stored as a
+JSON string by an older producer is read with GET.
+
+Reads can be served by read replicas (REDIS_REPLICA_URLS, comma-separated)
+so read availability doesn't depend on the primary the producer writes
+to. Replicas are used round-robin; a replica whose read fails is skipped
+for REPLICA_RETRY_AFTER_SEC and the read moves on to the next replica,
+then to the primary. Writes (audit, quotas, request log) always go to
+the primary (`client`).
+
+Report pub/sub can live on its own endpoint (REDIS_PUBSUB_URL), matching
+a producer that separates it from the cache. Each endpoint takes its own
+password and TLS files (REDIS_PASSWORD / REDIS_TLS_*, and
+REDIS_PUBSUB_PASSWORD / REDIS_PUBSUB_TLS_*); TLS is selected by a
+rediss:// URL and replicas use the primary's credentials.
+
+Concurrent identical reads are coalesced: a read of a report, footprint or
+trade tape that arrives while the same read is in flight waits for that
+round trip instead of issuing its own (singleflight), so a burst of agents
+asking for one symbol costs one Redis read. Each caller parses its own copy
+of the value. CACHE_COALESCE_READS=false turns this off.
+"""
+import asyncio
+import json
+import logging
+import os
+import time
+from dataclasses import dataclass
+from enum import Enum
+from typing import Any, Awaitable, Callable, TypeVar
+from urllib.parse import urlsplit
+
+import redis.asyncio as aioredis
+
+import memory_bus
+import metrics
+import report_versions
+
+logger = logging.getLogger(__name__)
+
+# Reports whose updatedAt is older than this are served but flagged stale
+DEFAULT_STALE_AFTER_MS = 5000
+
+# Stream recording which report generation answered each request
+REQUEST_LOG_STREAM = "mcp:requests"
+REQUEST_LOG_MAXLEN = 10000
+
+# Seconds a replica whose read failed is skipped
+REPLICA_RETRY_AFTER_SEC = 5.0
+
+# Report hash layout (producer/src/reporters/redis_cache.py): the fast report's
+# section order, sections owned by the slow cycle, and the slow cycle's provenance
+REPORT_SECTIONS_FIELD = "_sections"
+SLOW_REPORT_FIELDS = ("analytics", "liquidity", "anomalies", "slow_cycle_updated_at")
+SLOW_PROVENANCE_FIELD = "provenance:slow"
+
+# Watch registrations whose threshold rules the producer checks, and the
+# per-watch streams it appends crossings to (producer/src/reporters/thresholds.py)
+WATCH_REGISTRY_KEY = "watch:registrations"
+WATCH_EVENTS_PREFIX = "watch:events:"
+
+T = TypeVar("T")
+
+
+class CacheStatus(str, Enum):
+    """Outcome of a cache lookup."""
+
+    HIT = "hit"
+    MISS = "miss"
+    STALE = "stale"
+
+
+@dataclass
+class CacheResult:
+    """Report lookup result with explicit cache status."""
+
+    status: CacheStatus
+    report: dict[str, Any] | None = None
+    age_ms: int | None = None
+    latency_ms: float = 0.0
+
+    @property
+    def found(self) -> bool:
+        """Whether a report was returned (fresh or stale)."""
+        return self.report is not None
+
+    def to_meta(self) -> dict[str, Any]:
+        """Render cache information for result metadata."""
+        return {
+            "cache_status": self.status.value,
+            "cache_age_ms": self.age_ms,
+            "cache_latency_ms": round(self.latency_ms, 2),
+        }
+
+
+@dataclass
+class Replica:
+    """A read replica and when it may be used again after a failure."""
+
+    url: str
+    client: Any
+    down_until: float = 0.0
+
+    @property
+    def name(self) -> str:
+        """host:port of the replica (no credentials), for logs and metrics."""
+        if memory_bus.is_memory_url(self.url):
+            return self.url
+        parts = urlsplit(self.url)
+        return f"{parts.hostname}:{parts.port or 6379}"
+
+
+def connection_options(prefix: str = "REDIS") -> dict[str, str]:
+    """Password and ssl_* connection arguments from {prefix}_PASSWORD and {prefix}_TLS_*."""
+    options = {}
+    for setting, option in (
+        ("PASSWORD", "password"),
+        ("TLS_CA_CERT", "ssl_ca_certs"),
+        ("TLS_CERT", "ssl_certfile"),
+        ("TLS_KEY", "ssl_keyfile"),
+    ):
+        value = os.getenv(f"{prefix}_{setting}", "")
+        if value:
+            options[option] = value
+    return options
+
+
+def assemble_report(fields: dict[str, str]) -> dict[str, Any] | None:
+    """Report rebuilt from its hash fields (HGETALL reply), or None before the first fast report.
+
+    Sections are taken in the fast report's order; slow-cycle sections
+    replace the fast report's defaults (an empty anomalies list) and
+    slow-cycle provenance entries are added for the sections they describe.
+    """
+    if not fields or REPORT_SECTIONS_FIELD not in fields:
+        return None
+
+    report: dict[str, Any] = {}
+    for name in json.loads(fields[REPORT_SECTIONS_FIELD]):
+        if name in fields:
+            report[name] = json.loads(fields[name])
+        elif name == "anomalies":
+            report[name] = []
+    for name in SLOW_REPORT_FIELDS:
+        if name not in report and name in fields:
+            report[name] = json.loads(fields[name])
+
+    if SLOW_PROVENANCE_FIELD in fields:
+        fast = report.get("provenance") or []
+        sections = {entry.get("section") for entry in fast}
+        report["provenance"] = fast + [
+            entry for entry in json.loads(fields[SLOW_PROVENANCE_FIELD]) if entry.get("section") not in sections
+        ]
+    return report
+
+
+def _parse_report(raw: dict[str, str] | str | None) -> dict[str, Any] | None:
+    """Report from a hash reply, or from a legacy JSON string."""
+    if isinstance(raw, (str, bytes)):
+        return json.loads(raw)
+    return assemble_report(raw)
+
+
+async def _read_report(client, key: str) -> dict[str, str] | str | None:
+    """HGETALL a report, or GET it if an older producer stored it as a string."""
+    try:
+        return await client.hgetall(key)
+    except aioredis.ResponseError:
+        return await client.get(key)
+
+
+def _replica_urls() -> list[str]:
+    return [url.strip() for url in os.getenv("REDIS_REPLICA_URLS", "").split(",") if url.strip()]
+
+
+class RedisCache:
+    """Redis cache reader for market reports."""
+
+    def __init__(
+        self,
+        redis_url: str,
+        stale_after_ms: int | None = None,
+        replica_urls: list[str] | None = None,
+        pubsub_url: str | None = None,
+    ):
+        """Initialize Redis connection."""
+        self.redis_url = redis_url
+        self.client: aioredis.Redis | None = None
+        # Client for report pub/sub: the primary unless REDIS_PUBSUB_URL is set
+        self.pubsub_url = pubsub_url if pubsub_url is not None else os.getenv("REDIS_PUBSUB_URL", "")
+        self.pubsub_client: aioredis.Redis | None = None
+        if stale_after_ms is None:
+            stale_after_ms = int(os.getenv("CACHE_STALE_AFTER_MS", str(DEFAULT_STALE_AFTER_MS)))
+        self.stale_after_ms = stale_after_ms
+        self.replica_urls = _replica_urls() if replica_urls is None else replica_urls
+        self.replicas: list[Replica] = []
+        self._next_replica = 0
+        self.coalesce_reads = os.getenv("CACHE_COALESCE_READS", "true").lower() not in ("0", "false", "no")
+        # Key -> GET in flight, joined by identical GETs until it completes
+        self._inflight: dict[str, asyncio.Future] = {}
+
+    @staticmethod
+    async def _open(url: str, options: dict[str, str] | None = None):
+        if memory_bus.is_memory_url(url):
+            return memory_bus.connect(url)
+        return await aioredis.from_url(url, encoding="utf-8", decode_responses=True, **(options or {}))
+
+    async def connect(self):
+        """Connect to Redis (the primary must be reachable; replicas may be down)."""
+        try:
+            self.client = await self._open(self.redis_url, connection_options())
+            if memory_bus.is_memory_url(self.redis_url):
+                logger.info(f"Using in-memory bus '{memory_bus.bus_name(self.redis_url)}'")
+            else:
+                # Test connection
+                await self.client.ping()
+                logger.info(f"Connected to Redis at {self.redis_url}")
+        except Exception as e:
+            logger.error(f"Failed to connect to Redis: {e}")
+            raise
+
+        self.pubsub_client = self.client
+        if self.pubsub_url and self.pubsub_url != self.redis_url:
+            try:
+                self.pubsub_client = await self._open(self.pubsub_url, connection_options("REDIS_PUBSUB"))
+                if not memory_bus.is_memory_url(self.pubsub_url):
+                    await self.pubsub_client.ping()
+                logger.info(f"Connected to Redis pub/sub at {self.pubsub_url}")
+            except Exception as e:
+                logger.error(f"Failed to connect to Redis pub/sub: {e}")
+                raise
+
+        for url in self.replica_urls:
+            replica = Replica(url=url, client=await self._open(url, connection_options()))
+            self.replicas.append(replica)
+            try:
+                await replica.client.ping()
+                logger.info(f"Connected to Redis replica {replica.name}")
+            except (aioredis.RedisError, OSError) as e:
+                self._mark_down(replica, e)
+
+    async def close(self):
+        """Close Redis connection."""
+        for replica in self.replicas:
+            await replica.client.aclose()
+        self.replicas = []
+        if self.pubsub_client and self.pubsub_client is not self.client:
+            await self.pubsub_client.aclose()
+        self.pubsub_client = None
+        if self.client:
+            await self.client.aclose()
+            logger.info("Redis connection closed")
+
+    def _mark_down(self, replica: Replica, error: Exception) -> None:
+        replica.down_until = time.monotonic() + REPLICA_RETRY_AFTER_SEC
+        metrics.cache_replica_failovers.labels(replica=replica.name).inc()
+        logger.warning(
+            f"Redis replica {replica.name} failed ({error}); "
+            f"skipping it for {REPLICA_RETRY_AFTER_SEC:g}s"
+        )
+
+    def _available_replicas(self) -> list[Replica]:
+        """Replicas not marked down, starting from the next in round-robin order."""
+        if not self.replicas:
+            return []
+        start = self._next_replica % len(self.replicas)
+        self._next_replica += 1
+        now = time.monotonic()
+        ordered = self.replicas[start:] + self.replicas[:start]
+        return [replica for replica in ordered if replica.down_until <= now]
+
+    async def _read(self, read: Callable[[Any], Awaitable[T]]) -> T:
+        """Run a read on a replica, failing over to the other replicas and then the primary."""
+        if not self.client:
+            raise RuntimeError("Redis client not connected")
+        for replica in self._available_replicas():
+            try:
+                return await read(replica.client)
+            except (aioredis.RedisError, OSError) as e:
+                self._mark_down(replica, e)
+        return await read(self.client)
+
+    async def _get(self, key: str) -> str | None:
+        """GET a key, sharing the round trip of an identical GET already in flight."""
+        return await self._coalesced(key, lambda client: client.get(key))
+
+    async def _coalesced(self, key: str, read: Callable[[Any], Awaitable[T]]) -> T:
+        """Run a read of key, sharing the round trip of an identical read already in flight."""
+        if not self.coalesce_reads:
+            return await self._read(read)
+
+        inflight = self._inflight.get(key)
+        if inflight is None:
+            inflight = self._inflight[key] = asyncio.ensure_future(self._read(read))
+            inflight.add_done_callback(lambda _: self._inflight.pop(key, None))
+        else:
+            metrics.cache_coalesced_reads.labels(family=key.split(":", 1)[0]).inc()
+        # Shielded so a cancelled caller doesn't cancel the read for the others
+        return await asyncio.shield(inflight)
+
+    async def get_report(self, symbol: str) -> CacheResult:
+        """
+        Fetch market report from Redis cache.
+
+        Args:
+            symbol: Trading symbol (e.g., BTCUSDT)
+
+        Returns:
+            CacheResult with status hit, stale (report older than
+            stale_after_ms) or miss (no report cached)
+        """
+        if not self.client:
+            raise RuntimeError("Redis client not connected")
+
+        cache_key = f"report:{symbol}"
+        start = time.perf_counter()
+
+        try:
+            raw = await self._coalesced(cache_key, lambda client: _read_report(client, cache_key))
+            return self._to_result(symbol, _parse_report(raw), (time.perf_counter() - start) * 1000)
+        except json.JSONDecodeError as e:
+            logger.error(f"Failed to parse JSON for {symbol}: {e}")
+            raise
+        except Exception as e:
+            logger.error(f"Failed to get report for {symbol}: {e}")
+            raise
+
+    async def get_reports(self, symbols: list[str]) -> dict[str, CacheResult]:
+        """
+        Fetch several reports in one round trip.
+
+        Reports that fail to parse are left out of the result (and logged).
+        """
+        if not self.client:
+            raise RuntimeError("Redis client not connected")
+
+        async def read(client) -> list[dict[str, str] | str | None]:
+            pipe = client.pipeline(transaction=False)
+            for symbol in symbols:
+                pipe.hgetall(f"report:{symbol}")
+            values = await pipe.execute(raise_on_error=False)
+            # Reports still stored as strings by an older producer
+            for i, value in enumerate(values):
+                if isinstance(value, aioredis.ResponseError):
+                    values[i] = await client.get(f"report:{symbols[i]}")
+            return values
+
+        start = time.perf_counter()
+        values = await self._read(read)
+        latency_ms = (time.perf_counter() - start) * 1000
+
+        results = {}
+        for symbol, raw in zip(symbols, values):
+            try:
+                results[symbol] = self._to_result(symbol, _parse_report(raw), latency_ms)
+            except json.JSONDecodeError as e:
+                logger.error(f"Failed to parse JSON for {symbol}: {e}")
+        return results
+
+    def _to_result(self, symbol: str, report: dict[str, Any] | None, latency_ms: float) -> CacheResult:
+        if report is None:
+            logger.debug(f"Symbol {symbol} not found in cache")
+            return CacheResult(status=CacheStatus.MISS, latency_ms=latency_ms)
+
+        # Reports from an older producer are served in the current schema
+        report = report_versions.migrate(report)
+        age_ms = self._report_age_ms(report)
+
+        if age_ms is not None and age_ms > self.stale_after_ms:
+            logger.debug(f"Retrieved stale report for {symbol} (age={age_ms}ms)")
+            status = CacheStatus.STALE
+        else:
+            logger.debug(f"Retrieved report for {symbol}")
+            status = CacheStatus.HIT
+
+        return CacheResult(
+            status=status,
+            report=report,
+            age_ms=age_ms,
+            latency_ms=latency_ms,
+        )
+
+    async def list_symbols(self) -> list[str]:
+        """Symbols with a published report (report:{symbol}), sorted."""
+        if not self.client:
+            raise RuntimeError("Redis client not connected")
+        async def read(client) -> list[str]:
+            symbols = []
+            async for key in client.scan_iter("report:*"):
+                # Skip the producer's writer lease keys (report:writer:{symbol})
+                if not key.startswith("report:writer:"):
+                    symbols.append(key[len("report:"):])
+            return sorted(symbols)
+
+        return await self._read(read)
+
+    async def get_book_history(self, symbol: str, since_ms: int) -> list[str]:
+        """
+        Fetch order book snapshots recorded since since_ms, oldest first.
+
+        Returns:
+            Encoded snapshots from book_history:{symbol} (see book_history.py)
+        """
+        if not self.client:
+            raise RuntimeError("Redis client not connected")
+        return await self._read(
+            lambda client: client.zrangebyscore(f"book_history:{symbol}", since_ms, "+inf")
+        )
+
+    async def get_footprint(self, symbol: str) -> dict[str, Any] | None:
+        """
+        Fetch the producer's latest footprint ladder (footprint:{symbol}).
+
+        Returns:
+            Footprint dict, or None if none was published recently
+        """
+        if not self.client:
+            raise RuntimeError("Redis client not connected")
+        json_str = await self._get(f"footprint:{symbol}")
+        return json.loads(json_str) if json_str else None
+
+    async def get_trades(self, symbol: str) -> dict[str, Any] | None:
+        """
+        Fetch the producer's latest trade tape (trades:{symbol}).
+
+        Returns:
+            Tape dict with trades oldest first, or None if none was published recently
+        """
+        if not self.client:
+            raise RuntimeError("Redis client not connected")
+        json_str = await self._get(f"trades:{symbol}")
+        return json.loads(json_str) if json_str else None
+
+    async def get_digests(self, symbol: str, minutes: list[str]) -> list[dict[str, Any]]:
+        """
+        Fetch the producer's per-minute digests (digest:{symbol}:{minute}) in one round trip.
+
+        Args:
+            minutes: UTC minutes as YYYY-MM-DDTHH:MM
+
+        Returns:
+            Digests in the order of minutes; minutes without one (no data, expired) are left out
+        """
+        if not self.client:
+            raise RuntimeError("Redis client not connected")
+
+        async def read(client) -> list[str | None]:
+            pipe = client.pipeline(transaction=False)
+            for minute in minutes:
+                pipe.get(f"digest:{symbol}:{minute}")
+            return await pipe.execute()
+
+        return [json.loads(value) for value in await self._read(read) if value]
+
+    async def get_daily_stats(self, symbol: str, dates: list[str]) -> list[dict[str, Any]]:
+        """
+        Fetch the producer's daily statistics (daily:{symbol}:{date}) in one round trip.
+
+        Args:
+            dates: UTC dates as YYYY-MM-DD
+
+        Returns:
+            Statistics in the order of dates; days without them (no data, expired) are left out
+        """
+        if not self.client:
+            raise RuntimeError("Redis client not connected")
+
+        async def read(client) -> list[str | None]:
+            pipe = client.pipeline(transaction=False)
+            for date in dates:
+                pipe.get(f"daily:{symbol}:{date}")
+            return await pipe.execute()
+
+        return [json.loads(value) for value in await self._read(read) if value]
+
+    async def get_venue_report(self, venue: str) -> dict[str, Any] | None:
+        """
+        Fetch the producer's venue stress index (venue_report:{venue}).
+
+        Returns:
+            Venue report dict, or None if none was published recently
+        """
+        if not self.client:
+            raise RuntimeError("Redis client not connected")
+        json_str = await self._get(f"venue_report:{venue}")
+        return json.loads(json_str) if json_str else None
+
+    async def get_stream_last_id(self, stream_key: str) -> str | None:
+        """
+        Fetch the id of the newest entry of a stream (ms-seq, see pipeline.py).
+
+        Returns:
+            Entry id, or None if the stream is missing or empty
+        """
+        if not self.client:
+            raise RuntimeError("Redis client not connected")
+        entries = await self._read(lambda client: client.xrevrange(stream_key, count=1))
+        return entries[0][0] if entries else None
+
+    async def get_value(self, key: str) -> str | None:
+        """Fetch a raw string value (e.g. the producer heartbeat key)."""
+        if not self.client:
+            raise RuntimeError("Redis client not connected")
+        return await self._get(key)
+
+    async def register_watch(self, watch_id: str, registration: dict[str, Any]) -> None:
+        """
+        Write (or refresh) a watch registration for the producer's threshold detection.
+
+        Args:
+            registration: {"symbols": [...], "rules": [...], "expires_at": epoch ms}
+        """
+        if not self.client:
+            raise RuntimeError("Redis client not connected")
+        await self.client.hset(WATCH_REGISTRY_KEY, watch_id, json.dumps(registration))
+
+    async def unregister_watch(self, watch_id: str) -> None:
+        """Delete a watch registration and its event stream."""
+        if not self.client:
+            raise RuntimeError("Redis client not connected")
+        pipe = self.client.pipeline(transaction=False)
+        pipe.hdel(WATCH_REGISTRY_KEY, watch_id)
+        pipe.delete(f"{WATCH_EVENTS_PREFIX}{watch_id}")
+        await pipe.execute()
+
+    async def read_watch_events(self, watch_id: str, after_id: str, count: int = 100) -> list[tuple[str, dict[str, Any]]]:
+        """
+        Fetch threshold crossings the producer appended to watch:events:{watch_id} after an entry id.
+
+        Read from the primary, so a cursor never runs ahead of a lagging replica.
+
+        Returns:
+            (entry id, event) pairs, oldest first
+        """
+        if not self.client:
+            raise RuntimeError("Redis client not connected")
+        replies = await self.client.xread({f"{WATCH_EVENTS_PREFIX}{watch_id}": after_id}, count=count)
+        events = []
+        for _stream, entries in replies or []:
+            for entry_id, fields in entries:
+                try:
+                    events.append((entry_id, json.loads(fields["data"])))
+                except (KeyError, ValueError):
+                    logger.warning(f"Skipping malformed watch event {entry_id} of {watch_id}")
+        return events
+
+    async def log_request(
+        self,
+        correlation_id: str,
+        source: str,
+        symbol: str,
+        result: CacheResult,
+    ) -> None:
+        """
+        Append a request record to the request-log stream.
+
+        The record carries the report's updatedAt and writer node so a
+        request can be matched with the producer's report_published log.
+        Failures are logged and never fail the request.
+        """
+        if not self.client:
+            return
+
+        fields = {
+            "correlation_id": correlation_id,
+            "source": source,
+            "symbol": symbol,
+            "cache_status": result.status.value,
+            "latency_ms": f"{result.latency_ms:.2f}",
+        }
+        if result.report:
+            fields["updated_at"] = str(result.report.get("updatedAt", ""))
+            fields["writer_node"] = str(result.report.get("writer", {}).get("nodeId", ""))
+
+        try:
+            await self.client.xadd(
+                REQUEST_LOG_STREAM,
+                fields,
+                maxlen=REQUEST_LOG_MAXLEN,
+                approximate=True,
+            )
+        except Exception as e:
+            logger.warning(f"Failed to write request log for {symbol}: {e}")
+
+    @staticmethod
+    def _report_age_ms(report: dict[str, Any]) -> int | None:
+        """Age of a cached report based on its updatedAt epoch millis."""
+        updated_at = report.get("updatedAt")
+        if not isinstance(updated_at, (int, float)):
+            return None
+        return max(0, int(time.time() * 1000 - updated_at))
@@ -0,0 +1,187 @@
+"""
+Operational commands for the Context8 MCP servers.
+
+Ships in every server image so the pipeline can be inspected from the
+same artifact that serves it:
+
+    python cli.py [--config FILE] serve [stdio|sse|rest]
+    python cli.py check-config
+    python cli.py probe-redis
+    python cli.py list-symbols
+    python cli.py dump-report BTCUSDT
+    python cli.py check-contract [--file REPORT.json | --symbol BTCUSDT]
+"""
+import argparse
+import asyncio
+import json
+import os
+import sys
+import time
+
+import config
+from cache import CacheStatus, RedisCache
+
+LEASE_KEY_PREFIX = "report:writer:"
+
+
+def parse_args(argv: list[str] | None = None) -> argparse.Namespace:
+    parser = argparse.ArgumentParser(prog="cli.py", description="Context8 MCP operations")
+    parser.add_argument("--config", default=os.getenv("CONFIG_FILE"), help="YAML or TOML config file")
+    commands = parser.add_subparsers(dest="command", required=True)
+    serve = commands.add_parser("serve", help="Run a server")
+    serve.add_argument("transport", nargs="?", choices=["stdio", "sse", "rest"], default="stdio")
+    commands.add_parser("check-config", help="Validate configuration and print effective values")
+    commands.add_parser("probe-redis", help="Check Redis connectivity and report keys")
+    commands.add_parser("list-symbols", help="List cached symbols with cache status and age")
+    dump = commands.add_parser("dump-report", help="Print the cached report for a symbol")
+    dump.add_argument("symbol", help="Trading symbol (e.g., BTCUSDT)")
+    contract = commands.add_parser(
+        "check-contract",
+        help="Check a report against the GraphQL/OpenAPI schemas (REST image; default: bundled sample)",
+    )
+    source = contract.add_mutually_exclusive_group()
+    source.add_argument("--file", help="Report JSON file (e.g., a producer dump-report)")
+    source.add_argument("--symbol", help="Check the live cached report for a symbol")
+    return parser.parse_args(argv)
+
+
+def _cache() -> RedisCache:
+    return RedisCache(os.getenv("REDIS_URL", "redis://localhost:6379"))
+
+
+async def _report_symbols(cache: RedisCache) -> list[str]:
+    symbols = []
+    async for key in cache.client.scan_iter("report:*"):
+        if not key.startswith(LEASE_KEY_PREFIX):
+            symbols.append(key[len("report:"):])
+    return sorted(symbols)
+
+
+async def probe_redis() -> int:
+    """Check Redis connectivity and print server state."""
+    cache = _cache()
+    try:
+        start = time.perf_counter()
+        await cache.connect()
+        connect_ms = (time.perf_counter() - start) * 1000
+
+        start = time.perf_counter()
+        await cache.client.ping()
+        ping_ms = (time.perf_counter() - start) * 1000
+
+        info = await cache.client.info("server")
+        print(json.dumps({
+            "ok": True,
+            "redis_url": cache.redis_url,
+            "connect_ms": round(connect_ms, 2),
+            "ping_ms": round(ping_ms, 2),
+            "redis_version": info.get("redis_version"),
+            "report_keys": len(await _report_symbols(cache)),
+        }, indent=2))
+        return 0
+    except Exception as e:
+        print(json.dumps({"ok": False, "redis_url": cache.redis_url, "error": str(e)}, indent=2))
+        return 1
+    finally:
+        await cache.close()
+
+
+async def list_symbols() -> int:
+    """List cached symbols with their cache status and age."""
+    cache = _cache()
+    await cache.connect()
+    try:
+        rows = []
+        for symbol in await _report_symbols(cache):
+            result = await cache.get_report(symbol)
+            rows.append({
+                "symbol": symbol,
+                "cache_status": result.status.value,
+                "age_ms": result.age_ms,
+                "writer": (result.report or {}).get("writer", {}).get("nodeId"),
+            })
+        print(json.dumps({"symbols": rows, "count": len(rows)}, indent=2))
+        return 0
+    finally:
+        await cache.close()
+
+
+async def dump_report(symbol: str) -> int:
+    """Print the cached report for a symbol."""
+    cache = _cache()
+    await cache.connect()
+    try:
+        result = await cache.get_report(symbol.upper())
+        if result.status == CacheStatus.MISS:
+            print(f"no report cached for {symbol.upper()}", file=sys.stderr)
+            return 1
+        print(json.dumps({"cache": result.to_meta(), "report": result.report}, indent=2))
+        return 0
+    finally:
+        await cache.close()
+
+
+async def check_contract(path: str | None, symbol: str | None) -> int:
+    """Check a report (bundled sample, file or cached) for drift against the server schemas."""
+    import report_contract
+
+    if symbol:
+        cache = _cache()
+        await cache.connect()
+        try:
+            result = await cache.get_report(symbol.upper())
+        finally:
+            await cache.close()
+        if result.status == CacheStatus.MISS:
+            print(f"no report cached for {symbol.upper()}", file=sys.stderr)
+            return 1
+        report, source = result.report, f"report:{symbol.upper()}"
+    elif path:
+        with open(path) as f:
+            report, source = json.load(f), path
+    else:
+        report, source = report_contract.load_sample(), report_contract.SAMPLE_FILE
+
+    problems = report_contract.check_report(report)
+    print(json.dumps({"source": source, "compatible": not problems, "problems": problems}, indent=2))
+    return 1 if problems else 0
+
+
+def serve(transport: str) -> None:
+    if transport == "rest":
+        import uvicorn
+        from rest_server import app
+        uvicorn.run(app, host="0.0.0.0", port=int(os.getenv("PORT", "8080")), log_level="info")
+    elif transport == "sse":
+        import sse_server
+        asyncio.run(sse_server.main())
+    else:
+        import server
+        asyncio.run(server.main())
+
+
+def main(argv: list[str] | None = None) -> int:
+    args = parse_args(argv)
+
+    if args.command == "check-config":
+        result = config.validate(args.config)
+        print(json.dumps(result, indent=2))
+        return 0 if result["valid"] else 1
+
+    if args.config:
+        config.apply_config_file(args.config)
+
+    if args.command == "serve":
+        serve(args.transport)
+        return 0
+    if args.command == "probe-redis":
+        return asyncio.run(probe_redis())
+    if args.command == "list-symbols":
+        return asyncio.run(list_symbols())
+    if args.command == "check-contract":
+        return asyncio.run(check_contract(args.file, args.symbol))
+    return asyncio.run(dump_report(args.symbol))
+
+
+if __name__ == "__main__":
+    sys.exit(main())
@@ -0,0 +1,83 @@
+"""
+Report completeness.
+
+For the first seconds after a symbol starts, the producer publishes
+reports before every input has arrived: the book may be empty, the 24h
+ticker not yet received (placeholder zeros) and the slow cycle not yet
+run (no volume profile or liquidity features). Completeness is the share
+of these sections that are populated, from 0 (nothing yet) to 1:
+
+    book            best bid and ask are set
+    depth           top-20 levels on both sides
+    ticker          24h volume received
+    flow            flow windows computed
+    slow_cycle      the slow cycle has enriched the report
+    volume_profile  analytics.volume_profile present
+
+Tools can pass min_completeness to get REPORT_WARMING_UP instead of a
+report whose zeros mean "not computed yet".
+"""
+from typing import Any, Callable
+
+import errors
+from errors import ErrorResponse
+
+
+def _book(report: dict[str, Any]) -> bool:
+    bid, ask = report.get("best_bid") or {}, report.get("best_ask") or {}
+    return bool(bid.get("price")) and bool(ask.get("price"))
+
+
+def _depth(report: dict[str, Any]) -> bool:
+    depth = report.get("depth") or {}
+    return bool(depth.get("top20_bid")) and bool(depth.get("top20_ask"))
+
+
+def _ticker(report: dict[str, Any]) -> bool:
+    return bool(report.get("volume_24h"))
+
+
+def _flow(report: dict[str, Any]) -> bool:
+    return bool((report.get("flow") or {}).get("windows"))
+
+
+def _slow_cycle(report: dict[str, Any]) -> bool:
+    return report.get("slow_cycle_updated_at") is not None
+
+
+def _volume_profile(report: dict[str, Any]) -> bool:
+    return bool((report.get("analytics") or {}).get("volume_profile"))
+
+
+SECTIONS: dict[str, Callable[[dict[str, Any]], bool]] = {
+    "book": _book,
+    "depth": _depth,
+    "ticker": _ticker,
+    "flow": _flow,
+    "slow_cycle": _slow_cycle,
+    "volume_profile": _volume_profile,
+}
+
+
+def missing_sections(report: dict[str, Any]) -> list[str]:
+    """Sections not populated yet, in SECTIONS order."""
+    return [name for name, populated in SECTIONS.items() if not populated(report)]
+
+
+def completeness(report: dict[str, Any]) -> float:
+    """Share of SECTIONS populated, 0..1."""
+    return round(1 - len(missing_sections(report)) / len(SECTIONS), 2)
+
+
+def warming_up_error(report: dict[str, Any], min_completeness: float) -> ErrorResponse | None:
+    """REPORT_WARMING_UP if the report is below min_completeness, else None."""
+    score = completeness(report)
+    if score >= min_completeness:
+        return None
+    missing = missing_sections(report)
+    return ErrorResponse(
+        errors.REPORT_WARMING_UP,
+        f"Report for {report.get('symbol')} is warming up: completeness {score:g} < {min_completeness:g} "
+        f"(missing {', '.join(missing)})",
+        details={"completeness": score, "min_completeness": min_completeness, "missing": missing},
+    )
@@ -0,0 +1,259 @@
+"""
+Config file support for the Context8 MCP servers.
+
+Settings are environment variables, optionally seeded from a YAML or TOML
+file given with --config (or CONFIG_FILE). File keys map to variable
+names, with nested sections joined by "_":
+
+    redis_url: redis://redis:6379
+    quota:
+      daily: 1000        # QUOTA_DAILY
+    slo:
+      latency_ms: 150    # SLO_LATENCY_MS
+
+Variables already set in the environment override file values.
+
+Validate a configuration without starting a server:
+
+    python config.py validate --config context8.yaml
+"""
+import argparse
+import json
+import os
+import sys
+import tomllib
+from datetime import date
+from typing import Any, Callable
+from zoneinfo import ZoneInfo, ZoneInfoNotFoundError
+
+import yaml
+
+
+def _positive_int(value: str) -> None:
+    if int(value) < 0:
+        raise ValueError("must be >= 0")
+
+
+def _fraction(value: str) -> None:
+    if not 0 < float(value) < 1:
+        raise ValueError("must be between 0 and 1")
+
+
+def _user_password(value: str) -> None:
+    if value and ":" not in value:
+        raise ValueError("must be in user:password form")
+
+
+def _quota_overrides(value: str) -> None:
+    for entry in filter(None, (e.strip() for e in value.split(","))):
+        _, daily, monthly = entry.rsplit(":", 2)
+        int(daily), int(monthly)
+
+
+def _iso_date(value: str) -> None:
+    if value:
+        date.fromisoformat(value)
+
+
+def _boolean(value: str) -> None:
+    if value.lower() not in ("", "0", "1", "true", "false", "yes", "no"):
+        raise ValueError("must be true or false")
+
+
+def _time_format(value: str) -> None:
+    if value not in ("rfc3339", "epoch_ms"):
+        raise ValueError("must be rfc3339 or epoch_ms")
+
+
+def _field_naming(value: str) -> None:
+    if value not in ("original", "snake_case", "camelCase"):
+        raise ValueError("must be original, snake_case or camelCase")
+
+
+def _timezone(value: str) -> None:
+    if value:
+        try:
+            ZoneInfo(value)
+        except ZoneInfoNotFoundError:
+            raise ValueError(f"unknown IANA timezone: {value}") from None
+
+
+def _existing_file(value: str) -> None:
+    if value and not os.path.isfile(value):
+        raise ValueError(f"file not found: {value}")
+
+
+def _existing_dir(value: str) -> None:
+    if value and not os.path.isdir(value):
+        raise ValueError(f"directory not found: {value}")
+
+
+# Known settings and their validators (None = any string)
+SETTINGS: dict[str, Callable[[str], Any] | None] = {
+    "REDIS_URL": None,
+    "REDIS_PASSWORD": None,
+    "REDIS_TLS_CA_CERT": _existing_file,
+    "REDIS_TLS_CERT": _existing_file,
+    "REDIS_TLS_KEY": _existing_file,
+    "REDIS_REPLICA_URLS": None,
+    "REDIS_PUBSUB_URL": None,
+    "REDIS_PUBSUB_PASSWORD": None,
+    "REDIS_PUBSUB_TLS_CA_CERT": _existing_file,
+    "REDIS_PUBSUB_TLS_CERT": _existing_file,
+    "REDIS_PUBSUB_TLS_KEY": _existing_file,
+    "PORT": _positive_int,
+    "CACHE_STALE_AFTER_MS": _positive_int,
+    "CACHE_COALESCE_READS": _boolean,
+    "AUDIT_MAXLEN": _positive_int,
+    "POPULARITY_RETENTION_DAYS": _positive_int,
+    "ADMIN_TOKEN": None,
+    "ADMIN_API_KEYS": None,
+    "QUOTA_DAILY": _positive_int,
+    "QUOTA_MONTHLY": _positive_int,
+    "QUOTA_OVERRIDES": _quota_overrides,
+    "QUOTA_ANONYMOUS_DAILY": _positive_int,
+    "QUOTA_ANONYMOUS_MONTHLY": _positive_int,
+    "QUOTA_API_KEYS": None,
+    "TENANTS_FILE": _existing_file,
+    "TENANTS_REFRESH_SEC": _positive_int,
+    "MCP_API_KEY": None,
+    "METRICS_PORT": _positive_int,
+    "METRICS_BASIC_AUTH": _user_password,
+    "WS_QUEUE_SIZE": _positive_int,
+    "SSE_REPLAY_SIZE": _positive_int,
+    "WARMUP_TIMEOUT_SEC": float,
+    "MCP_SESSION_IDLE_SEC": _positive_int,
+    "MCP_MAX_CONCURRENT_REQUESTS": _positive_int,
+    "MCP_MAX_QUEUED_REQUESTS": _positive_int,
+    "MCP_QUEUE_WAIT_MS": _positive_int,
+    "MCP_MAX_REQUESTS_PER_SESSION": _positive_int,
+    "MCP_MAX_SSE_CONNECTIONS": _positive_int,
+    "MCP_VALIDATE_OUTPUT": _boolean,
+    "API_SUNSET": _iso_date,
+    "DEPTH_CHART_MAX_BPS": float,
+    "HISTORY_DIR": _existing_dir,
+    "TIME_FORMAT": _time_format,
+    "TIME_ZONE": _timezone,
+    "FIELD_NAMING": _field_naming,
+    "SLO_FRESHNESS_MS": float,
+    "SLO_FRESHNESS_TARGET": _fraction,
+    "SLO_LATENCY_MS": float,
+    "SLO_LATENCY_TARGET": _fraction,
+}
+
+# Settings masked when printing the effective configuration
+SECRETS = {"REDIS_PASSWORD", "REDIS_PUBSUB_PASSWORD", "ADMIN_TOKEN", "ADMIN_API_KEYS", "QUOTA_OVERRIDES", "QUOTA_API_KEYS", "MCP_API_KEY", "METRICS_BASIC_AUTH"}
+
+
+def _flatten(data: dict, prefix: str = "") -> dict[str, str]:
+    """Flatten nested config sections into environment-style settings."""
+    settings = {}
+    for key, value in data.items():
+        name = f"{prefix}{key}".upper()
+        if isinstance(value, dict):
+            settings.update(_flatten(value, f"{name}_"))
+        elif isinstance(value, list):
+            settings[name] = ",".join(str(v) for v in value)
+        elif isinstance(value, bool):
+            settings[name] = "true" if value else "false"
+        else:
+            settings[name] = str(value)
+    return settings
+
+
+def load_config_file(path: str) -> dict[str, str]:
+    """
+    Read a YAML (.yaml/.yml) or TOML (.toml) config file as settings.
+
+    Raises:
+        ValueError: If the file type is unsupported or the file is malformed
+    """
+    if path.endswith(".toml"):
+        try:
+            with open(path, "rb") as f:
+                data = tomllib.load(f)
+        except (OSError, tomllib.TOMLDecodeError) as e:
+            raise ValueError(f"Failed to read config file {path}: {e}") from e
+    elif path.endswith((".yaml", ".yml")):
+        try:
+            with open(path) as f:
+                data = yaml.safe_load(f) or {}
+        except (OSError, yaml.YAMLError) as e:
+            raise ValueError(f"Failed to read config file {path}: {e}") from e
+    else:
+        raise ValueError(f"Unsupported config file type: {path} (use .yaml, .yml or .toml)")
+
+    if not isinstance(data, dict):
+        raise ValueError(f"Config file {path} must contain a mapping")
+    return _flatten(data)
+
+
+def apply_config_file(path: str) -> dict[str, str]:
+    """Seed the environment from a config file; existing env vars win."""
+    settings = load_config_file(path)
+    for name, value in settings.items():
+        os.environ.setdefault(name, value)
+    return settings
+
+
+def load(argv: list[str] | None = None) -> str | None:
+    """
+    Apply the config file named by --config or CONFIG_FILE, if any.
+
+    Call before creating servers so every component sees the settings.
+
+    Returns:
+        Path of the applied config file
+    """
+    parser = argparse.ArgumentParser(add_help=False)
+    parser.add_argument("--config", default=os.getenv("CONFIG_FILE"))
+    args, _ = parser.parse_known_args(argv)
+    if args.config:
+        apply_config_file(args.config)
+    return args.config
+
+
+def validate(config_path: str | None) -> dict[str, Any]:
+    """Validate the effective configuration (file plus environment)."""
+    errors, unknown = [], []
+    if config_path:
+        try:
+            unknown = sorted(set(apply_config_file(config_path)) - set(SETTINGS))
+        except ValueError as e:
+            return {"valid": False, "config_file": config_path, "errors": [str(e)]}
+
+    effective = {}
+    for name, check in SETTINGS.items():
+        value = os.getenv(name)
+        if value is None:
+            continue
+        effective[name] = "***" if name in SECRETS and value else value
+        if check:
+            try:
+                check(value)
+            except ValueError as e:
+                errors.append(f"{name}: {e}")
+
+    return {
+        "valid": not errors,
+        "config_file": config_path,
+        "errors": errors,
+        "unknown_settings": unknown,
+        "config": effective,
+    }
+
+
+def main(argv: list[str] | None = None) -> int:
+    parser = argparse.ArgumentParser(prog="config.py", description="Context8 MCP configuration")
+    parser.add_argument("--config", default=os.getenv("CONFIG_FILE"), help="YAML or TOML config file")
+    commands = parser.add_subparsers(dest="command", required=True)
+    commands.add_parser("validate", help="Validate configuration and print effective values")
+    args = parser.parse_args(argv)
+
+    result = validate(args.config)
+    print(json.dumps(result, indent=2))
+    return 0 if result["valid"] else 1
+
+
+if __name__ == "__main__":
+    sys.exit(main())
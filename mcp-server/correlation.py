@@ -0,0 +1,108 @@
+"""
+Correlation ID propagation for MCP and REST requests.
+
+The ID is taken from the X-Correlation-ID request header (or generated),
+stored in a context variable for the duration of the request, echoed in the
+response and attached to log records.
+
+MCP tool calls get their own ID rather than their connection's: on the SSE
+transport calls run in the context of the long-lived GET /sse request. A
+call uses the correlation_id a client puts in the request's _meta, else the
+X-Correlation-ID header of the POST that carried it, else a fresh one.
+
+Client-supplied IDs end up in log lines, audit entries and the request log,
+so only IDs matching VALID_ID are used; anything else is replaced by a
+fresh one.
+"""
+import logging
+import re
+import uuid
+from contextvars import ContextVar
+from typing import Any
+
+HEADER_NAME = "X-Correlation-ID"
+VALID_ID = re.compile(r"[A-Za-z0-9._-]{1,128}")
+
+correlation_id_var: ContextVar[str | None] = ContextVar("correlation_id", default=None)
+
+
+def new_correlation_id() -> str:
+    """Generate a correlation ID for a request."""
+    return uuid.uuid4().hex
+
+
+def valid_correlation_id(value: Any) -> str | None:
+    """The value if it is usable as a client-supplied correlation ID, else None."""
+    if isinstance(value, str) and VALID_ID.fullmatch(value):
+        return value
+    return None
+
+
+def request_correlation_id(request_context: Any) -> str | None:
+    """Valid correlation ID a client sent with an MCP request (_meta, then the HTTP header), if any."""
+    meta = getattr(request_context, "meta", None)
+    correlation_id = valid_correlation_id(getattr(meta, "correlation_id", None) if meta is not None else None)
+    if correlation_id:
+        return correlation_id
+    request = getattr(request_context, "request", None)
+    headers = getattr(request, "headers", None)
+    if headers is not None:
+        return valid_correlation_id(headers.get(HEADER_NAME))
+    return None
+
+
+def current_correlation_id() -> str:
+    """Correlation ID of the current request, generating one if unset."""
+    correlation_id = correlation_id_var.get()
+    if correlation_id is None:
+        correlation_id = new_correlation_id()
+        correlation_id_var.set(correlation_id)
+    return correlation_id
+
+
+class CorrelationIDFilter(logging.Filter):
+    """Logging filter that adds correlation_id to every record."""
+
+    def filter(self, record: logging.LogRecord) -> bool:
+        record.correlation_id = correlation_id_var.get() or "-"
+        return True
+
+
+def install_log_filter() -> None:
+    """Attach the correlation ID filter to all root handlers."""
+    for handler in logging.getLogger().handlers:
+        handler.addFilter(CorrelationIDFilter())
+
+
+class CorrelationIDMiddleware:
+    """ASGI middleware that scopes a correlation ID to each HTTP request."""
+
+    def __init__(self, app):
+        self.app = app
+
+    async def __call__(self, scope, receive, send):
+        if scope["type"] != "http":
+            await self.app(scope, receive, send)
+            return
+
+        correlation_id = None
+        for name, value in scope.get("headers", []):
+            if name.decode("latin-1").lower() == HEADER_NAME.lower():
+                correlation_id = valid_correlation_id(value.decode("latin-1"))
+                break
+        if not correlation_id:
+            correlation_id = new_correlation_id()
+
+        token = correlation_id_var.set(correlation_id)
+
+        async def send_with_header(message):
+            if message["type"] == "http.response.start":
+                headers = list(message.get("headers", []))
+                headers.append((HEADER_NAME.lower().encode("latin-1"), correlation_id.encode("latin-1")))
+                message["headers"] = headers
+            await send(message)
+
+        try:
+            await self.app(scope, receive, send_with_header)
+        finally:
+            correlation_id_var.reset(token)
@@ -0,0 +1,68 @@
+"""
+Cumulative depth curves for the get_depth_chart tool.
+
+Builds step curves from a report's top-20 book: for each side, levels
+ordered outward from mid with the running quantity and quote notional
+(USDT at par) up to that level. A client plots each side as a step
+function of price; the cumulative notional at a distance is the cost of
+sweeping the book that far, which agents can read as slippage.
+"""
+import os
+from typing import Any
+
+# Default distance from mid when the caller doesn't pass max_distance_bps
+DEFAULT_DISTANCE_BPS = 50.0
+
+
+def max_distance_bps() -> float:
+    """Largest distance from mid a caller may request (DEPTH_CHART_MAX_BPS)."""
+    return float(os.getenv("DEPTH_CHART_MAX_BPS", "500"))
+
+
+def _curve(levels: list[dict], mid: float, distance_bps: float) -> tuple[list[dict], float]:
+    """Cumulative step curve for one side, and the distance the book covers.
+
+    Returns:
+        (points out to distance_bps, distance in bps of the farthest level)
+    """
+    points = []
+    levels = sorted(levels, key=lambda level: abs(level["price"] - mid))
+    cum_qty = cum_notional = 0.0
+    covered_bps = 0.0
+    for level in levels:
+        price, qty = level["price"], level["qty"]
+        level_bps = abs(price - mid) / mid * 10_000
+        covered_bps = max(covered_bps, level_bps)
+        if level_bps > distance_bps:
+            continue
+        cum_qty += qty
+        cum_notional += price * qty
+        points.append({
+            "price": price,
+            "distance_bps": round(level_bps, 2),
+            "cum_qty": round(cum_qty, 8),
+            "cum_notional_usd": round(cum_notional, 2),
+        })
+    return points, round(covered_bps, 2)
+
+
+def build_depth_chart(report: dict[str, Any], distance_bps: float) -> dict[str, Any]:
+    """Cumulative bid/ask depth out to distance_bps from mid.
+
+    `book_covers_bps` is how far the published top-20 levels reach on each
+    side; when it is below distance_bps the curve stops short of the
+    requested distance rather than implying the book is empty beyond it.
+    """
+    depth = report.get("depth") or {}
+    mid = report.get("mid_price")
+    bids, bid_covers = _curve(depth.get("top20_bid") or [], mid, distance_bps)
+    asks, ask_covers = _curve(depth.get("top20_ask") or [], mid, distance_bps)
+    return {
+        "symbol": report.get("symbol"),
+        "mid_price": mid,
+        "max_distance_bps": distance_bps,
+        "bids": bids,
+        "asks": asks,
+        "book_covers_bps": {"bid": bid_covers, "ask": ask_covers},
+        "generated_at": report.get("generated_at"),
+    }
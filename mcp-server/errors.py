@@ -0,0 +1,216 @@
+"""
+Error contract shared by the REST API and MCP tool results.
+
+Every error is rendered as:
+    {"code", "message", "suggestion", "correlation_id", "retry_after"}
+"""
+from dataclasses import dataclass, field
+from typing import Any
+
+from correlation import current_correlation_id
+
+
+@dataclass(frozen=True)
+class ErrorCode:
+    """Registered error code with its HTTP status and client guidance."""
+
+    code: str
+    http_status: int
+    description: str
+    suggestion: str
+    retry_after: int | None = None  # Seconds, for transient errors
+
+
+TOOL_NOT_FOUND = ErrorCode(
+    code="TOOL_NOT_FOUND",
+    http_status=404,
+    description="The requested tool does not exist",
+    suggestion="Call tools/list to see the available tools",
+)
+
+MISSING_PARAMETER = ErrorCode(
+    code="MISSING_PARAMETER",
+    http_status=400,
+    description="A required parameter was not provided",
+    suggestion="Provide the parameter named in the message",
+)
+
+INVALID_SYMBOL = ErrorCode(
+    code="INVALID_SYMBOL",
+    http_status=400,
+    description="The symbol does not match ^[A-Z0-9]+USDT$",
+    suggestion="Use an uppercase USDT pair such as BTCUSDT or 1000SHIBUSDT",
+)
+
+INVALID_PARAMETER = ErrorCode(
+    code="INVALID_PARAMETER",
+    http_status=400,
+    description="A parameter has an invalid value",
+    suggestion="Check the parameter types against the API documentation",
+)
+
+UNAUTHORIZED = ErrorCode(
+    code="UNAUTHORIZED",
+    http_status=401,
+    description="The request lacks valid credentials for this endpoint",
+    suggestion="Send 'Authorization: Bearer <ADMIN_TOKEN>' for admin endpoints",
+)
+
+FORBIDDEN = ErrorCode(
+    code="FORBIDDEN",
+    http_status=403,
+    description="The API key is not allowed to perform this operation",
+    suggestion="Use an API key listed in ADMIN_API_KEYS for admin tools",
+)
+
+NOT_ENTITLED = ErrorCode(
+    code="NOT_ENTITLED",
+    http_status=403,
+    description="The API key's tenant is not entitled to the symbol or venue",
+    suggestion="Request only symbols listed for your tenant (see /v1/symbols)",
+)
+
+QUOTA_EXCEEDED = ErrorCode(
+    code="QUOTA_EXCEEDED",
+    http_status=429,
+    description="The API key exceeded its daily or monthly request quota",
+    suggestion="Wait until the quota resets (see retry_after) or request a higher quota",
+)
+
+UNKNOWN_API_KEY = ErrorCode(
+    code="UNKNOWN_API_KEY",
+    http_status=401,
+    description="Quotas are on and the API key is not one issued for this deployment",
+    suggestion="Send a key from QUOTA_API_KEYS, QUOTA_OVERRIDES or a tenant in X-API-Key, or omit it to use the anonymous quota",
+)
+
+SYMBOL_NOT_FOUND = ErrorCode(
+    code="SYMBOL_NOT_FOUND",
+    http_status=404,
+    description="No report is cached for the symbol",
+    suggestion="Check the symbol is tracked by the producer, or retry after warm-up",
+    retry_after=5,
+)
+
+DATA_NOT_FOUND = ErrorCode(
+    code="DATA_NOT_FOUND",
+    http_status=404,
+    description="Nothing of the requested kind (trades, footprint, digests, history, venue status) is stored for the symbol or venue in the range",
+    suggestion="Widen the range, or check the producer publishes this data for the symbol or venue",
+)
+
+DUPLICATE_REQUEST_ID = ErrorCode(
+    code="DUPLICATE_REQUEST_ID",
+    http_status=400,
+    description="A JSON-RPC request reused an id already used in its session",
+    suggestion="Use a new id for every request in a session",
+)
+
+REPORT_WARMING_UP = ErrorCode(
+    code="REPORT_WARMING_UP",
+    http_status=503,
+    description="The report is below the requested min_completeness (sections still warming up)",
+    suggestion="Retry after a few seconds, or lower min_completeness to accept a partial report",
+    retry_after=2,
+)
+
+OVERLOADED = ErrorCode(
+    code="OVERLOADED",
+    http_status=503,
+    description="The server is at its concurrency limit and could not take the request in time",
+    suggestion="Retry after retry_after seconds with backoff, or send fewer requests at once",
+    retry_after=1,
+)
+
+INTERNAL_ERROR = ErrorCode(
+    code="INTERNAL_ERROR",
+    http_status=500,
+    description="The server failed to read or decode the report",
+    suggestion="Retry shortly; if the problem persists check Redis connectivity",
+    retry_after=1,
+)
+
+ERROR_CODES: dict[str, ErrorCode] = {
+    e.code: e
+    for e in (
+        TOOL_NOT_FOUND,
+        MISSING_PARAMETER,
+        INVALID_SYMBOL,
+        INVALID_PARAMETER,
+        UNAUTHORIZED,
+        FORBIDDEN,
+        NOT_ENTITLED,
+        QUOTA_EXCEEDED,
+        UNKNOWN_API_KEY,
+        SYMBOL_NOT_FOUND,
+        DATA_NOT_FOUND,
+        DUPLICATE_REQUEST_ID,
+        REPORT_WARMING_UP,
+        OVERLOADED,
+        INTERNAL_ERROR,
+    )
+}
+
+
+@dataclass
+class ErrorResponse:
+    """Error body returned to REST and MCP clients."""
+
+    error: ErrorCode
+    message: str
+    correlation_id: str = field(default_factory=current_correlation_id)
+    retry_after: int | None = None  # Overrides the code's default
+    details: dict[str, Any] | None = None
+    suggestion: str | None = None  # Overrides the code's default
+
+    @property
+    def code(self) -> str:
+        return self.error.code
+
+    @property
+    def http_status(self) -> int:
+        return self.error.http_status
+
+    @property
+    def effective_retry_after(self) -> int | None:
+        if self.retry_after is not None:
+            return self.retry_after
+        return self.error.retry_after
+
+    def to_dict(self) -> dict[str, Any]:
+        """Render the error contract."""
+        body = {
+            "code": self.error.code,
+            "message": self.message,
+            "suggestion": self.suggestion or self.error.suggestion,
+            "correlation_id": self.correlation_id,
+            "retry_after": self.effective_retry_after,
+        }
+        if self.details:
+            body["details"] = self.details
+        return body
+
+
+def missing_parameter(name: str, example: str | None = None) -> ErrorResponse:
+    """MISSING_PARAMETER for a named parameter, with a suggestion naming it."""
+    suggestion = f"Provide the '{name}' parameter"
+    if example:
+        suggestion += f", e.g. {example}"
+    return ErrorResponse(MISSING_PARAMETER, f"Missing required parameter: {name}", suggestion=suggestion)
+
+
+def error_catalog() -> dict[str, Any]:
+    """Machine-readable description of all error codes."""
+    return {
+        "fields": ["code", "message", "suggestion", "correlation_id", "retry_after"],
+        "codes": [
+            {
+                "code": e.code,
+                "http_status": e.http_status,
+                "description": e.description,
+                "suggestion": e.suggestion,
+                "retry_after": e.retry_after,
+            }
+            for e in ERROR_CODES.values()
+        ],
+    }
@@ -0,0 +1,96 @@
+"""
+Field naming of tool responses.
+
+Reports mix snake_case fields with a few camelCase ones (schemaVersion,
+updatedAt, writer.nodeId) and are cached that way. Client SDKs that map
+JSON onto typed models usually want one convention, so tools rename keys
+at the edge, leaving the cache as it is:
+
+    original    keys as published (default)
+    snake_case  schemaVersion -> schema_version, updatedAt -> updated_at
+    camelCase   spread_bps -> spreadBps, uptime_pct_1h -> uptimePct1h
+
+Only object keys that name fields are renamed. Values are never touched,
+nor are keys that are data (symbols, flow window labels, anomaly types
+and statuses under DATA_KEYED_FIELDS), nor all-caps keys such as POC and
+VAH. The default comes from FIELD_NAMING; tools accept a field_naming
+argument per call, so the declared output schemas accept every naming.
+"""
+import os
+import re
+from typing import Any
+
+FIELD_NAMINGS = ("original", "snake_case", "camelCase")
+
+# Objects whose keys are values rather than field names
+DATA_KEYED_FIELDS = frozenset({"windows", "anomaly_counts", "anomaly_totals", "last_transitions"})
+
+_CAMEL_BOUNDARY = re.compile(r"(?<=[a-z])([A-Z])")
+_SNAKE_BOUNDARY = re.compile(r"_([a-z0-9])")
+
+FIELD_NAMING_PROPERTY = {
+    "type": "string",
+    "enum": list(FIELD_NAMINGS),
+    "description": (
+        "Key naming of the response: original as published (mostly snake_case), "
+        "snake_case or camelCase throughout (default FIELD_NAMING, original)"
+    ),
+}
+
+
+def default_naming() -> str:
+    """Field naming used when a tool call doesn't pass field_naming (FIELD_NAMING)."""
+    return os.getenv("FIELD_NAMING", "original")
+
+
+def validate(naming: Any) -> str | None:
+    """Error message for an invalid field_naming argument, or None."""
+    if naming not in FIELD_NAMINGS:
+        return f"field_naming must be one of {', '.join(FIELD_NAMINGS)}, got {naming!r}"
+    return None
+
+
+def rename_key(key: str, naming: str) -> str:
+    """One field name in the requested naming."""
+    if naming == "snake_case":
+        return _CAMEL_BOUNDARY.sub(lambda m: "_" + m.group(1).lower(), key)
+    if naming == "camelCase" and not key.startswith("_"):
+        return _SNAKE_BOUNDARY.sub(lambda m: m.group(1).upper(), key)
+    return key
+
+
+def rename(value: Any, naming: str) -> Any:
+    """Copy of a response with its field names in the requested naming."""
+    if naming == "original":
+        return value
+    return _rename(value, naming, data_keyed=False)
+
+
+def _rename(value: Any, naming: str, data_keyed: bool) -> Any:
+    if isinstance(value, dict):
+        return {
+            (key if data_keyed else rename_key(key, naming)): _rename(item, naming, key in DATA_KEYED_FIELDS)
+            for key, item in value.items()
+        }
+    if isinstance(value, list):
+        return [_rename(item, naming, False) for item in value]
+    return value
+
+
+def rename_schema(schema: Any, naming: str) -> Any:
+    """Copy of a JSON schema whose properties and required fields follow the naming."""
+    if naming == "original":
+        return schema
+    if isinstance(schema, dict):
+        renamed = {}
+        for keyword, item in schema.items():
+            if keyword == "properties":
+                renamed[keyword] = {rename_key(k, naming): rename_schema(v, naming) for k, v in item.items()}
+            elif keyword == "required":
+                renamed[keyword] = [rename_key(k, naming) for k in item]
+            else:
+                renamed[keyword] = rename_schema(item, naming)
+        return renamed
+    if isinstance(schema, list):
+        return [rename_schema(item, naming) for item in schema]
+    return schema
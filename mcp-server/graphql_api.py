@@ -0,0 +1,512 @@
+"""
+GraphQL endpoint for selective report querying.
+
+Clients select exactly the report fields they need for one or many symbols:
+
+    { report(symbol: "BTCUSDT") { spreadBps depth { imbalance } anomalies { type severity } } }
+
+Fields are camelCase views over the cached snake_case report and are
+resolved from the same RedisCache as /v1/report. A query may resolve at
+most MAX_QUERY_SYMBOLS symbols, counting every `report` field (aliases
+included) and every entry of `reports`, and is charged one quota unit per
+symbol (at least one per request).
+"""
+import logging
+import re
+from typing import Any
+
+from graphql import GraphQLError, build_schema, default_field_resolver, graphql
+from starlette.responses import JSONResponse
+
+import errors
+import metrics
+from audit import api_key_var
+from cache import CacheStatus, RedisCache
+from correlation import current_correlation_id
+from quota import QuotaManager
+from tenancy import TenantRegistry
+from watch import MAX_WATCH_SYMBOLS
+
+logger = logging.getLogger(__name__)
+
+# Symbols one query may resolve, across all report fields and aliases
+MAX_QUERY_SYMBOLS = MAX_WATCH_SYMBOLS
+
+SCHEMA_SDL = """
+type Query {
+  "Report for one symbol, null when not cached"
+  report(symbol: String!): Report
+  "Reports for several symbols; uncached symbols are omitted"
+  reports(symbols: [String!]!): [Report!]!
+  "Symbols with a cached report"
+  symbols: [String!]!
+}
+
+type Report {
+  schemaVersion: String
+  symbol: String!
+  venue: String
+  meta: SymbolMeta
+  generatedAt: String
+  updatedAt: Float
+  dataAgeMs: Int
+  ingestion: Ingestion
+  "Venue trading status; anomalies and freshness penalties are suspended while not open"
+  marketStatus: MarketStatus
+  lastPrice: Float
+  change24hPct: Float
+  high24h: Float
+  low24h: Float
+  volume24h: Float
+  volume24hUsd: Float
+  bestBid: PriceLevel
+  bestAsk: PriceLevel
+  spreadBps: Float
+  midPrice: Float
+  microPrice: Float
+  depth: Depth
+  flow: Flow
+  microstructure: Microstructure
+  liquidity: Liquidity
+  analytics: Analytics
+  anomalies: [Anomaly!]
+  recentTrades: [Trade!]
+  health: Health
+  timings: Timings
+  "24h performance against the benchmark and the tracked universe"
+  relativeStrength: RelativeStrength
+  "Inputs behind each report section"
+  provenance: [Provenance!]
+}
+
+type Provenance {
+  "Report section, dotted for nested sections (e.g. analytics.footprint)"
+  section: String
+  "NautilusTrader event types, or report.<field> for values derived from other fields"
+  sources: [String!]
+  "Latest source event time (ISO 8601)"
+  sourceTs: String
+  windowSec: Int
+  "fast or slow cycle"
+  cycle: String
+}
+
+type Timings {
+  consumeLagMs: Float
+  venueLagMs: Float
+  clockSkewMs: Float
+  processingMs: Float
+  queueMs: Float
+  publishMs: Float
+}
+
+type RelativeStrength {
+  windowSec: Int
+  performancePct: Float
+  benchmark: String
+  benchmarkPerformancePct: Float
+  "Percentage points above (+) or below (-) the benchmark"
+  vsBenchmarkPct: Float
+  universeMedianPct: Float
+  vsMedianPct: Float
+  "1 for the strongest symbol by 24h change"
+  rank: Int
+  universeSize: Int
+  updatedAt: Float
+}
+
+type Trade {
+  price: Float
+  size: Float
+  side: String
+  time: String
+}
+
+type SymbolMeta {
+  baseAsset: String
+  quoteAsset: String
+  pricePrecision: Int
+  sizePrecision: Int
+  contractType: String
+  venueName: String
+}
+
+type Ingestion {
+  status: String
+  fresh: Boolean
+  lastUpdate: String
+  since: String
+  lastTransitions: IngestionTransitions
+  uptimePct1h: Float
+}
+
+type MarketStatus {
+  "open, halted, auction, maintenance or closed"
+  state: String
+  "When the current state began (ISO 8601); null if open since startup"
+  since: String
+  reason: String
+}
+
+type IngestionTransitions {
+  ok: String
+  degraded: String
+  down: String
+}
+
+type PriceLevel {
+  price: Float
+  qty: Float
+}
+
+type Depth {
+  top20Bid: [PriceLevel!]
+  top20Ask: [PriceLevel!]
+  sumBid: Float
+  sumAsk: Float
+  sumBidUsd: Float
+  sumAskUsd: Float
+  imbalance: Float
+}
+
+type Flow {
+  ordersPerSec: Float
+  netFlow: Float
+  netFlowUsd: Float
+  windows: [FlowWindow!]
+}
+
+type FlowWindow {
+  window: String
+  ordersPerSec: Float
+  tradesPerSec: Float
+  buyVolume: Float
+  sellVolume: Float
+  buyTradeCount: Int
+  sellTradeCount: Int
+  netFlow: Float
+  netFlowUsd: Float
+  avgTradeSize: Float
+}
+
+type Liquidity {
+  walls: [Wall!]
+  vacuums: [Vacuum!]
+}
+
+type Wall {
+  side: String
+  price: Float
+  quantity: Float
+  severity: String
+  distanceBps: Float
+  distanceTicks: Float
+  inValueArea: Boolean
+  notionalUsd: Float
+}
+
+type Vacuum {
+  side: String
+  priceStart: Float
+  priceEnd: Float
+  levelCount: Int
+  severity: String
+}
+
+type Analytics {
+  volumeProfile: VolumeProfile
+  pocTrend: String
+  pocDriftBps: Float
+  developingValueArea: DevelopingValueArea
+  footprint: Footprint
+}
+
+type Footprint {
+  windowSec: Int
+  bucketSize: Float
+  topImbalances: [FootprintLevel!]
+}
+
+type FootprintLevel {
+  price: Float
+  buyVolume: Float
+  sellVolume: Float
+  delta: Float
+  imbalance: Float
+  tradeCount: Int
+}
+
+type DevelopingValueArea {
+  poc: Float
+  vah: Float
+  val: Float
+  sessionStart: String
+  tradeCount: Int
+}
+
+type VolumeProfile {
+  poc: Float
+  vah: Float
+  val: Float
+  windowSec: Int
+  tradeCount: Int
+}
+
+type Microstructure {
+  windowSec: Int
+  quoteUpdatesPerSec: Float
+  baselineUpdatesPerSec: Float
+  addedQtyPerSec: Float
+  removedQtyPerSec: Float
+  churnPerSec: Float
+}
+
+type Anomaly {
+  type: String
+  side: String
+  price: Float
+  quantity: Float
+  distanceBps: Float
+  fillCount: Int
+  totalVolume: Float
+  absorbedVolume: Float
+  visibleQty: Float
+  priceMoveBps: Float
+  updatesPerSec: Float
+  baselineUpdatesPerSec: Float
+  churnPerSec: Float
+  "Shortest rest before a spoofed level was pulled"
+  lifetimeMs: Int
+  pulls: Int
+  notionalUsd: Float
+  skewMs: Float
+  triggeredSignals: [String!]
+  severity: String
+  note: String
+  synthetic: Boolean
+  details: AnomalyDetails
+}
+
+type AnomalyDetails {
+  spreadBps: Float
+  depthImbalance: Float
+  flowAcceleration: Float
+}
+
+type Health {
+  score: Int
+  components: HealthComponents
+}
+
+type HealthComponents {
+  spread: Float
+  depth: Float
+  balance: Float
+  flow: Float
+  anomalies: Float
+  freshness: Float
+}
+"""
+
+schema = build_schema(SCHEMA_SDL)
+
+# GraphQL field names that don't map to report keys by camelCase -> snake_case
+FIELD_ALIASES = {
+    "schemaVersion": "schemaVersion",
+    "updatedAt": "updatedAt",
+    "change24hPct": "change_24h_pct",
+    "high24h": "high_24h",
+    "low24h": "low_24h",
+    "volume24h": "volume_24h",
+    "volume24hUsd": "volume_24h_usd",
+    "top20Bid": "top20_bid",
+    "top20Ask": "top20_ask",
+    "poc": "POC",
+    "vah": "VAH",
+    "val": "VAL",
+    "uptimePct1h": "uptime_pct_1h",
+}
+
+_CAMEL_BOUNDARY = re.compile(r"(?<!^)(?=[A-Z])")
+_SYMBOL_PATTERN = re.compile(r"^[A-Z0-9]+USDT$")
+
+
+def _report_key(field_name: str) -> str:
+    if field_name in FIELD_ALIASES:
+        return FIELD_ALIASES[field_name]
+    return _CAMEL_BOUNDARY.sub("_", field_name).lower()
+
+
+def resolve_field(source: Any, info, **args):
+    """Resolve camelCase fields against snake_case report dictionaries."""
+    if isinstance(source, dict):
+        value = source.get(_report_key(info.field_name))
+        if info.field_name == "windows" and isinstance(value, dict):
+            # flow.windows is a map keyed by window label
+            return [{"window": label, **metrics} for label, metrics in value.items()]
+        return value
+    return default_field_resolver(source, info, **args)
+
+
+class QueryRoot:
+    """Root resolvers backed by the report cache."""
+
+    def __init__(
+        self,
+        cache: RedisCache,
+        tenants: TenantRegistry,
+        api_key: str | None,
+        quota: QuotaManager | None = None,
+        prepaid: int = 1,
+    ):
+        """
+        Args:
+            quota: Charged one unit per symbol resolved beyond those prepaid
+            prepaid: Units the request was already charged before execution
+        """
+        self.cache = cache
+        self.tenants = tenants
+        self.api_key = api_key
+        self.quota = quota
+        self.prepaid = prepaid
+        self.resolved = 0  # Symbols requested so far by this query
+
+    async def _reserve(self, count: int) -> None:
+        """Count symbols against the per-query cap and charge their quota."""
+        # Counted before awaiting so concurrently resolved root fields see each other
+        paid = max(self.resolved, self.prepaid)
+        self.resolved += count
+        if self.resolved > MAX_QUERY_SYMBOLS:
+            raise GraphQLError(
+                f"Query requests more than {MAX_QUERY_SYMBOLS} symbols; split it into several requests",
+                extensions={"code": errors.INVALID_PARAMETER.code},
+            )
+        units = max(self.resolved, self.prepaid) - paid
+        if self.quota is None or units <= 0:
+            return
+        error = await self.quota.check(self.api_key, units)
+        if error:
+            raise GraphQLError(
+                error.message, extensions={"code": error.code, "retryAfter": error.effective_retry_after}
+            )
+
+    async def _load(self, symbol: str) -> dict[str, Any] | None:
+        symbol = symbol.upper()
+        if not _SYMBOL_PATTERN.match(symbol):
+            raise GraphQLError(
+                f"Invalid symbol format: {symbol}. Must match pattern: ^[A-Z0-9]+USDT$",
+                extensions={"code": errors.INVALID_SYMBOL.code},
+            )
+
+        denied = await self.tenants.check_symbol(self.api_key, symbol)
+        if denied:
+            raise GraphQLError(denied, extensions={"code": errors.NOT_ENTITLED.code})
+
+        result = await self.cache.get_report(symbol)
+        metrics.record_cache_lookup(result)
+        await self.cache.log_request(current_correlation_id(), "rest:graphql", symbol, result)
+        if result.status == CacheStatus.MISS:
+            return None
+
+        denied = await self.tenants.check_report(self.api_key, result.report)
+        if denied:
+            raise GraphQLError(denied, extensions={"code": errors.NOT_ENTITLED.code})
+        return result.report
+
+    async def report(self, info, symbol: str) -> dict[str, Any] | None:
+        await self._reserve(1)
+        return await self._load(symbol)
+
+    async def reports(self, info, symbols: list[str]) -> list[dict[str, Any]]:
+        await self._reserve(len(symbols))
+        reports = []
+        for symbol in symbols:
+            report = await self._load(symbol)
+            if report is not None:
+                reports.append(report)
+        return reports
+
+    async def symbols(self, info) -> list[str]:
+        return await self.tenants.visible_symbols(self.api_key, await self.cache.list_symbols())
+
+
+async def execute(
+    cache: RedisCache,
+    tenants: TenantRegistry,
+    query: str,
+    variables: dict[str, Any] | None = None,
+    operation_name: str | None = None,
+    quota: QuotaManager | None = None,
+) -> dict[str, Any]:
+    """Execute a GraphQL query and return the response body.
+
+    The caller has charged the request one quota unit; symbols resolved
+    beyond the first are charged to quota as they are resolved.
+    """
+    result = await graphql(
+        schema,
+        query,
+        root_value=QueryRoot(cache, tenants, api_key_var.get(), quota),
+        variable_values=variables,
+        operation_name=operation_name,
+        field_resolver=resolve_field,
+    )
+
+    body: dict[str, Any] = {"data": result.data}
+    if result.errors:
+        body["errors"] = [e.formatted for e in result.errors]
+        for error in result.errors:
+            if not error.extensions:
+                logger.error(f"GraphQL execution error: {error.message}")
+    return body
+
+
+async def handle_graphql(
+    request, cache: RedisCache, tenants: TenantRegistry, quota: QuotaManager | None = None
+) -> tuple[JSONResponse, str]:
+    """Handle a GraphQL request (POST JSON body or GET ?query=), returning response and outcome.
+
+    With a quota manager, a well-formed request is charged one unit before
+    execution and its further symbols as they resolve.
+    """
+    if request.method == "GET":
+        query = request.query_params.get("query", "")
+        variables = None
+        operation_name = request.query_params.get("operationName")
+    else:
+        try:
+            payload = await request.json()
+        except ValueError:
+            return JSONResponse(
+                {"errors": [{"message": "Request body must be JSON"}]}, status_code=400
+            ), errors.INVALID_PARAMETER.code
+        if not isinstance(payload, dict):
+            return JSONResponse(
+                {"errors": [{"message": "Request body must be a JSON object"}]}, status_code=400
+            ), errors.INVALID_PARAMETER.code
+        query = payload.get("query", "")
+        variables = payload.get("variables")
+        operation_name = payload.get("operationName")
+        if not isinstance(query, str) or not isinstance(variables, (dict, type(None))):
+            return JSONResponse(
+                {"errors": [{"message": "query must be a string and variables an object"}]}, status_code=400
+            ), errors.INVALID_PARAMETER.code
+
+    if not query:
+        return JSONResponse(
+            {"errors": [{"message": "Missing required parameter: query"}]}, status_code=400
+        ), errors.MISSING_PARAMETER.code
+
+    # Charged only once the request is well-formed
+    if quota is not None:
+        quota_error = await quota.check(api_key_var.get())
+        if quota_error:
+            headers = {"Retry-After": str(quota_error.effective_retry_after)} if quota_error.effective_retry_after else {}
+            return JSONResponse(
+                quota_error.to_dict(), status_code=quota_error.http_status, headers=headers
+            ), quota_error.code
+
+    body = await execute(cache, tenants, query, variables, operation_name, quota)
+    if body.get("data") is None:
+        return JSONResponse(body, status_code=400), "graphql_error"
+    return JSONResponse(body), "ok" if "errors" not in body else "partial"
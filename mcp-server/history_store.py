@@ -0,0 +1,187 @@
+"""
+Report and daily history from the producer's Parquet files, queried with DuckDB.
+
+Redis keeps a day of digests and a month of daily statistics. A single-node
+deployment that needs more can point HISTORY_DIR at the producer's
+NT_PARQUET_DIR (a shared volume) instead of running a database:
+
+- get_report_history aggregates reports/symbol={symbol}/{hour}.parquet into
+  fixed-interval points (last mid and last price, mean spread and imbalance,
+  lowest health score, most anomalies, report count)
+- get_daily_stats fills days missing from Redis (expired after
+  NT_DAILY_TTL_SEC) with statistics recomputed from the day's report and
+  trade files, marked "source": "history"
+
+Only finished hours are readable (the producer renames a file to
+*.parquet once its hour ends), so history lags by up to an hour; get_digest
+covers the recent minutes. Queries run on a worker thread with an in-process
+DuckDB connection; duckdb is only needed when HISTORY_DIR is set.
+"""
+import asyncio
+import glob
+import os
+from datetime import datetime, timezone
+from typing import Any
+
+# Most points a get_report_history call may return
+MAX_HISTORY_POINTS = 2000
+
+_REPORT_POINTS_SQL = """
+SELECT
+    (epoch_ms(ts) // $interval_ms) * $interval_ms AS bucket_ms,
+    arg_max(mid_price, ts) AS mid_price,
+    arg_max(last_price, ts) AS last_price,
+    avg(spread_bps) AS avg_spread_bps,
+    avg(imbalance) AS avg_imbalance,
+    min(health_score) AS min_health_score,
+    max(anomaly_count) FILTER (WHERE cycle = 'slow') AS max_anomaly_count,
+    count(*) AS samples
+FROM read_parquet($files)
+WHERE epoch_ms(ts) >= $start_ms AND epoch_ms(ts) < $end_ms
+GROUP BY bucket_ms
+ORDER BY bucket_ms
+"""
+
+_DAY_TRADES_SQL = """
+SELECT arg_min(price, ts), max(price), min(price), arg_max(price, ts),
+       sum(size), sum(price * size), count(*)
+FROM read_parquet($files)
+"""
+
+_DAY_REPORTS_SQL = """
+SELECT
+    avg(spread_bps) FILTER (WHERE cycle = 'fast'),
+    count(*) FILTER (WHERE cycle = 'fast'),
+    count(DISTINCT epoch_ms(ts) // 60000),
+    count(DISTINCT epoch_ms(ts) // 60000) FILTER (WHERE ingestion_status = 'ok'),
+    max(epoch_ms(ts))
+FROM read_parquet($files)
+"""
+
+_DAY_ANOMALIES_SQL = """
+SELECT anomaly_type, count(*)
+FROM (
+    SELECT unnest(json_extract_string(report, '$.anomalies[*].type')) AS anomaly_type
+    FROM read_parquet($files)
+    WHERE cycle = 'slow'
+)
+GROUP BY anomaly_type
+ORDER BY anomaly_type
+"""
+
+
+def _hour(ts_ms: int) -> str:
+    return datetime.fromtimestamp(ts_ms / 1000, tz=timezone.utc).strftime("%Y-%m-%dT%H")
+
+
+class HistoryStore:
+    """Read-only DuckDB queries over the producer's hourly Parquet files."""
+
+    def __init__(self, directory: str):
+        import duckdb
+
+        self.directory = directory
+        self._conn = duckdb.connect(database=":memory:")
+
+    def _files(self, kind: str, symbol: str, first_hour: str, last_hour: str) -> list[str]:
+        """Finished files of a symbol whose hour is within [first_hour, last_hour]."""
+        files = glob.glob(os.path.join(self.directory, kind, f"symbol={symbol}", "*.parquet"))
+        return sorted(f for f in files if first_hour <= os.path.basename(f)[:13] <= last_hour)
+
+    def _query(self, sql: str, params: dict[str, Any]) -> list[tuple]:
+        # One cursor per query: a DuckDB connection is not shared across threads
+        cursor = self._conn.cursor()
+        try:
+            return cursor.execute(sql, params).fetchall()
+        finally:
+            cursor.close()
+
+    def report_points(self, symbol: str, start_ms: int, end_ms: int, interval_sec: int) -> list[dict[str, Any]]:
+        """Reports in [start_ms, end_ms) aggregated into interval_sec points, oldest first."""
+        files = self._files("reports", symbol, _hour(start_ms), _hour(end_ms - 1))
+        if not files:
+            return []
+        rows = self._query(_REPORT_POINTS_SQL, {
+            "files": files, "interval_ms": interval_sec * 1000, "start_ms": start_ms, "end_ms": end_ms,
+        })
+        return [
+            {
+                "ts": bucket_ms,
+                "mid_price": mid_price,
+                "last_price": last_price,
+                "avg_spread_bps": round(spread, 4) if spread is not None else None,
+                "avg_imbalance": round(imbalance, 4) if imbalance is not None else None,
+                "min_health_score": health,
+                "max_anomaly_count": anomalies,
+                "samples": samples,
+            }
+            for bucket_ms, mid_price, last_price, spread, imbalance, health, anomalies, samples in rows
+        ]
+
+    def daily_stats(self, symbol: str, date: str) -> dict[str, Any] | None:
+        """A day's statistics recomputed from its files (get_daily_stats shape), or None without data."""
+        first_hour, last_hour = f"{date}T00", f"{date}T23"
+        report_files = self._files("reports", symbol, first_hour, last_hour)
+        trade_files = self._files("trades", symbol, first_hour, last_hour)
+        if not report_files and not trade_files:
+            return None
+
+        stats = {
+            "symbol": symbol,
+            "date": date,
+            "ohlc": None,
+            "volume": 0.0,
+            "notional": 0.0,
+            "vwap": None,
+            "trade_count": 0,
+            "avg_spread_bps": None,
+            "report_samples": 0,
+            "anomaly_totals": {},
+            "minutes": 0,
+            "uptime_minutes": 0.0,
+            "uptime_pct": None,
+            "last_minute": None,
+            "source": "history",
+        }
+        if trade_files:
+            open_, high, low, close, volume, notional, count = self._query(_DAY_TRADES_SQL, {"files": trade_files})[0]
+            if count:
+                stats.update({
+                    "ohlc": {"open": open_, "high": high, "low": low, "close": close},
+                    "volume": volume,
+                    "notional": notional,
+                    "vwap": notional / volume if volume else None,
+                    "trade_count": count,
+                })
+        if report_files:
+            spread, samples, minutes, ok_minutes, last_ms = self._query(_DAY_REPORTS_SQL, {"files": report_files})[0]
+            stats.update({
+                "avg_spread_bps": round(spread, 4) if spread is not None else None,
+                "report_samples": samples,
+                "minutes": minutes,
+                "uptime_minutes": float(ok_minutes),
+                "anomaly_totals": dict(self._query(_DAY_ANOMALIES_SQL, {"files": report_files})),
+            })
+            if last_ms is not None:
+                last = datetime.fromtimestamp(last_ms / 1000, tz=timezone.utc)
+                # Share of the day up to the end of last_minute, as in the producer's rollup
+                elapsed = last.hour * 60 + last.minute + 1
+                stats["last_minute"] = last.strftime("%Y-%m-%dT%H:%M")
+                stats["uptime_pct"] = round(ok_minutes / elapsed * 100, 2)
+        return stats
+
+    async def get_report_points(self, *args) -> list[dict[str, Any]]:
+        return await asyncio.to_thread(self.report_points, *args)
+
+    async def get_daily_stats(self, symbol: str, dates: list[str]) -> list[dict[str, Any]]:
+        """Statistics of the dates that have history, in the order of dates."""
+        def read() -> list[dict[str, Any]]:
+            return [s for s in (self.daily_stats(symbol, date) for date in dates) if s]
+
+        return await asyncio.to_thread(read)
+
+
+def from_env() -> HistoryStore | None:
+    """Store over HISTORY_DIR, or None when it is not set."""
+    directory = os.getenv("HISTORY_DIR", "")
+    return HistoryStore(directory) if directory else None
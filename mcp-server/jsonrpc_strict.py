@@ -0,0 +1,103 @@
+"""
+Strict JSON-RPC checks on MCP sessions.
+
+The MCP SDK answers requests (including ping) and silently accepts
+notifications such as notifications/initialized, which never get a
+response. What it doesn't check is that request ids are unique: JSON-RPC
+clients match responses to requests by id, so a client reusing an id
+that is still (or was) in use gets responses it can't tell apart.
+
+strict_streams() sits between a transport and Server.run(). Requests
+whose id was already used on the session are answered with an Invalid
+Request error (-32600, with DUPLICATE_REQUEST_ID in the error contract as
+its data) and never reach the server; everything else is
+passed through untouched. The last MAX_TRACKED_IDS ids of a session are
+remembered. MCP_STRICT_JSONRPC=false turns the check off; rejections are
+counted in mcp_jsonrpc_rejected_total{reason}.
+"""
+import logging
+import os
+from contextlib import asynccontextmanager
+from typing import AsyncIterator
+
+import anyio
+from anyio.streams.memory import MemoryObjectReceiveStream, MemoryObjectSendStream
+from mcp.shared.message import SessionMessage
+from mcp.types import INVALID_REQUEST, ErrorData, JSONRPCError, JSONRPCMessage, JSONRPCRequest
+
+import errors
+import metrics
+from errors import ErrorResponse
+
+logger = logging.getLogger(__name__)
+
+# Request ids remembered per session (oldest forgotten first)
+MAX_TRACKED_IDS = 10000
+
+
+def strict_enabled() -> bool:
+    return os.getenv("MCP_STRICT_JSONRPC", "true").lower() in ("1", "true", "yes")
+
+
+class RequestIDTracker:
+    """Request ids seen on one session."""
+
+    def __init__(self, max_ids: int = MAX_TRACKED_IDS):
+        self.max_ids = max_ids
+        self._ids: dict[str | int, None] = {}
+
+    def claim(self, request_id: str | int) -> bool:
+        """Record a request id; False if the session already used it."""
+        if request_id in self._ids:
+            return False
+        self._ids[request_id] = None
+        if len(self._ids) > self.max_ids:
+            del self._ids[next(iter(self._ids))]
+        return True
+
+
+def duplicate_id_error(request_id: str | int) -> SessionMessage:
+    """Invalid Request response to a request reusing an id, with the error contract as data."""
+    message = f"Request id {request_id!r} was already used in this session"
+    return SessionMessage(JSONRPCMessage(JSONRPCError(
+        jsonrpc="2.0",
+        id=request_id,
+        error=ErrorData(
+            code=INVALID_REQUEST,
+            message=message,
+            data=ErrorResponse(errors.DUPLICATE_REQUEST_ID, message).to_dict(),
+        ),
+    )))
+
+
+@asynccontextmanager
+async def strict_streams(
+    read_stream: MemoryObjectReceiveStream,
+    write_stream: MemoryObjectSendStream,
+) -> AsyncIterator[tuple[MemoryObjectReceiveStream, MemoryObjectSendStream]]:
+    """Transport streams with duplicate request ids rejected before they reach the server."""
+    if not strict_enabled():
+        yield read_stream, write_stream
+        return
+
+    checked_send, checked_receive = anyio.create_memory_object_stream[SessionMessage | Exception](0)
+    tracker = RequestIDTracker()
+
+    async def forward() -> None:
+        async with checked_send:
+            async for message in read_stream:
+                if isinstance(message, SessionMessage):
+                    request = message.message.root
+                    if isinstance(request, JSONRPCRequest) and not tracker.claim(request.id):
+                        logger.warning(f"Rejected JSON-RPC request reusing id={request.id!r} method={request.method}")
+                        metrics.jsonrpc_rejected.labels(reason="duplicate_id").inc()
+                        await write_stream.send(duplicate_id_error(request.id))
+                        continue
+                await checked_send.send(message)
+
+    async with anyio.create_task_group() as tg:
+        tg.start_soon(forward)
+        try:
+            yield checked_receive, write_stream
+        finally:
+            tg.cancel_scope.cancel()
@@ -0,0 +1,159 @@
+"""
+Concurrency limits for MCP tool calls and SSE connections.
+
+A traffic spike should slow a few requests down, not every request: past
+a point, queueing only pushes all latencies beyond the SLO. Tool calls
+therefore run under a ConcurrencyLimiter:
+
+- at most MCP_MAX_CONCURRENT_REQUESTS calls execute at once (0 = unlimited)
+- further calls queue, up to MCP_MAX_QUEUED_REQUESTS of them, and wait at
+  most MCP_QUEUE_WAIT_MS for a slot
+- one connection (MCP session) runs at most MCP_MAX_REQUESTS_PER_SESSION
+  calls at once (0 = unlimited), so a single busy agent can't take every slot
+
+A call that can't get a slot is rejected right away with OVERLOADED (and a
+retry_after) instead of waiting out its client's timeout. The SSE server
+also caps open SSE streams at MCP_MAX_SSE_CONNECTIONS (0 = unlimited).
+"""
+import asyncio
+import logging
+import os
+import time
+from dataclasses import dataclass
+
+import metrics
+
+logger = logging.getLogger(__name__)
+
+DEFAULT_MAX_CONCURRENT = 64
+DEFAULT_MAX_QUEUED = 256
+DEFAULT_QUEUE_WAIT_MS = 100
+DEFAULT_MAX_PER_SESSION = 8
+DEFAULT_MAX_SSE_CONNECTIONS = 1000
+
+
+class Overloaded(Exception):
+    """A request was rejected by a concurrency limit."""
+
+    def __init__(self, reason: str, message: str):
+        super().__init__(message)
+        self.reason = reason  # queue_full, queue_timeout, session_limit or sse_connections
+
+
+def rejected(reason: str, message: str) -> Overloaded:
+    """Count a rejection and build its exception."""
+    metrics.requests_rejected.labels(reason=reason).inc()
+    logger.warning(f"Request rejected reason={reason}: {message}")
+    return Overloaded(reason, message)
+
+
+def max_sse_connections() -> int:
+    """Open SSE streams allowed at once (MCP_MAX_SSE_CONNECTIONS, 0 = unlimited)."""
+    return int(os.getenv("MCP_MAX_SSE_CONNECTIONS", str(DEFAULT_MAX_SSE_CONNECTIONS)))
+
+
+@dataclass
+class Slot:
+    """A granted execution slot, returned to the limiter when the call ends."""
+
+    session_id: str | None
+    limited: bool
+    released: bool = False
+
+
+class ConcurrencyLimiter:
+    """Bounds concurrent tool calls, globally and per session, with a bounded queue."""
+
+    def __init__(
+        self,
+        max_concurrent: int | None = None,
+        max_queued: int | None = None,
+        queue_wait_ms: float | None = None,
+        max_per_session: int | None = None,
+    ):
+        if max_concurrent is None:
+            max_concurrent = int(os.getenv("MCP_MAX_CONCURRENT_REQUESTS", str(DEFAULT_MAX_CONCURRENT)))
+        if max_queued is None:
+            max_queued = int(os.getenv("MCP_MAX_QUEUED_REQUESTS", str(DEFAULT_MAX_QUEUED)))
+        if queue_wait_ms is None:
+            queue_wait_ms = float(os.getenv("MCP_QUEUE_WAIT_MS", str(DEFAULT_QUEUE_WAIT_MS)))
+        if max_per_session is None:
+            max_per_session = int(os.getenv("MCP_MAX_REQUESTS_PER_SESSION", str(DEFAULT_MAX_PER_SESSION)))
+        self.max_concurrent = max_concurrent
+        self.max_queued = max_queued
+        self.queue_wait_ms = queue_wait_ms
+        self.max_per_session = max_per_session
+        self._semaphore = asyncio.Semaphore(max_concurrent) if max_concurrent else None
+        self.queued = 0
+        self.in_flight = 0
+        self._per_session: dict[str, int] = {}
+
+    async def acquire(self, session_id: str | None = None) -> Slot:
+        """Wait for an execution slot.
+
+        Raises:
+            Overloaded: the session is at its limit, the queue is full, or
+                no slot freed up within queue_wait_ms
+        """
+        if session_id and self.max_per_session and self._per_session.get(session_id, 0) >= self.max_per_session:
+            raise rejected(
+                "session_limit",
+                f"Connection already has {self.max_per_session} requests in flight; wait for one to finish",
+            )
+
+        if self._semaphore is not None:
+            if self._semaphore.locked():
+                await self._wait_for_slot()
+            else:
+                await self._semaphore.acquire()
+
+        self.in_flight += 1
+        metrics.requests_in_flight.set(self.in_flight)
+        if session_id:
+            self._per_session[session_id] = self._per_session.get(session_id, 0) + 1
+        return Slot(session_id=session_id, limited=self._semaphore is not None)
+
+    async def _wait_for_slot(self) -> None:
+        if self.queued >= self.max_queued:
+            raise rejected("queue_full", f"Server is at capacity with {self.queued} requests queued")
+
+        self.queued += 1
+        metrics.requests_queued.set(self.queued)
+        start = time.perf_counter()
+        try:
+            await asyncio.wait_for(self._semaphore.acquire(), self.queue_wait_ms / 1000)
+        except asyncio.TimeoutError:
+            raise rejected(
+                "queue_timeout", f"No capacity freed up within {self.queue_wait_ms:g}ms"
+            ) from None
+        finally:
+            self.queued -= 1
+            metrics.requests_queued.set(self.queued)
+            metrics.request_queue_wait.observe((time.perf_counter() - start) * 1000)
+
+    def release(self, slot: Slot) -> None:
+        """Return a slot granted by acquire(); releasing it again is a no-op."""
+        if slot.released:
+            return
+        slot.released = True
+        self.in_flight -= 1
+        metrics.requests_in_flight.set(self.in_flight)
+        if slot.session_id:
+            remaining = self._per_session.get(slot.session_id, 1) - 1
+            if remaining:
+                self._per_session[slot.session_id] = remaining
+            else:
+                self._per_session.pop(slot.session_id, None)
+        if slot.limited:
+            self._semaphore.release()
+
+    def summary(self) -> dict:
+        """Current load and limits, for the admin listing."""
+        return {
+            "in_flight": self.in_flight,
+            "queued": self.queued,
+            "max_concurrent": self.max_concurrent,
+            "max_queued": self.max_queued,
+            "queue_wait_ms": self.queue_wait_ms,
+            "max_per_session": self.max_per_session,
+        }
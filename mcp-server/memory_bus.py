@@ -0,0 +1,118 @@
+"""
+Async adapter over the producer's in-memory event bus.
+
+With REDIS_URL=memory://name the cache talks to an in-process bus instead
+of Redis, so the producer and MCP server can run in one process for
+integration tests and local development. The bus itself lives with the
+producer (src/event_bus.py); whoever starts both registers it here with
+register_bus() before the cache connects.
+"""
+import asyncio
+from typing import Any
+
+MEMORY_SCHEME = "memory://"
+
+_buses: dict[str, Any] = {}
+
+
+def is_memory_url(url: str) -> bool:
+    """Whether the URL selects an in-memory bus."""
+    return url.startswith(MEMORY_SCHEME)
+
+
+def bus_name(url: str) -> str:
+    """Bus name of a memory:// URL (default when empty)."""
+    return url[len(MEMORY_SCHEME):] or "default"
+
+
+def register_bus(name: str, bus: Any) -> None:
+    """Make an in-memory bus available to memory://name URLs."""
+    _buses[name] = bus
+
+
+def connect(url: str) -> "AsyncMemoryClient":
+    """Async client for a memory:// URL; the bus must already be registered."""
+    name = bus_name(url)
+    if name not in _buses:
+        raise RuntimeError(
+            f"No in-memory bus registered as '{name}'; memory:// needs the producer in the same process"
+        )
+    return AsyncMemoryClient(_buses[name])
+
+
+class AsyncMemoryClient:
+    """redis.asyncio-style client: each command runs directly on the in-memory bus."""
+
+    def __init__(self, bus: Any):
+        self.bus = bus
+
+    def __getattr__(self, name: str):
+        command = getattr(self.bus, name)
+
+        async def call(*args, **kwargs):
+            return command(*args, **kwargs)
+        return call
+
+    async def scan_iter(self, match: str | None = None, count: int | None = None):
+        for key in self.bus.scan_iter(match, count):
+            yield key
+
+    def pipeline(self, transaction: bool = True) -> "AsyncMemoryPipeline":
+        return AsyncMemoryPipeline(self.bus.pipeline(transaction))
+
+    def pubsub(self) -> "AsyncMemoryPubSub":
+        return AsyncMemoryPubSub(self.bus)
+
+    async def aclose(self) -> None:
+        """No-op: the bus outlives the client."""
+
+
+class AsyncMemoryPipeline:
+    """Queues commands synchronously and runs them on await execute()."""
+
+    def __init__(self, pipeline: Any):
+        self._pipeline = pipeline
+
+    def __getattr__(self, name: str):
+        return getattr(self._pipeline, name)
+
+    async def execute(self, raise_on_error: bool = True) -> list:
+        return self._pipeline.execute(raise_on_error)
+
+
+class AsyncMemoryPubSub:
+    """Subscriber delivering published messages to the event loop that subscribed."""
+
+    def __init__(self, bus: Any):
+        self.bus = bus
+        self._messages: asyncio.Queue = asyncio.Queue()
+        self._removers: list = []
+
+    def _deliver(self, message: dict) -> None:
+        # Publishers may run on producer threads
+        self._loop.call_soon_threadsafe(self._messages.put_nowait, message)
+
+    async def subscribe(self, *channels: str) -> None:
+        self._loop = asyncio.get_running_loop()
+        for channel in channels:
+            self._removers.append(self.bus.add_listener(channel, self._deliver))
+
+    async def psubscribe(self, *patterns: str) -> None:
+        self._loop = asyncio.get_running_loop()
+        for pattern in patterns:
+            self._removers.append(self.bus.add_listener(pattern, self._deliver, pattern=True))
+
+    async def get_message(self, ignore_subscribe_messages: bool = False, timeout: float = 0.0) -> dict | None:
+        try:
+            return await asyncio.wait_for(self._messages.get(), timeout) if timeout else self._messages.get_nowait()
+        except (asyncio.TimeoutError, asyncio.QueueEmpty):
+            return None
+
+    async def listen(self):
+        while self._removers:
+            yield await self._messages.get()
+
+    async def aclose(self) -> None:
+        for remove in self._removers:
+            remove()
+        self._removers = []
@@ -0,0 +1,603 @@
+"""
+Metric catalog: definitions of the report fields (explain_metric tool).
+
+Agents reading a report otherwise guess what imbalance or micro_price
+means, and guess wrong. Each entry documents one field as the producer
+computes it (producer/src/calculators): what it is, the formula, the
+window it covers, its units and the caveats that matter when reading it.
+
+Paths are dotted from the report root as in the schema changelog. Flow
+windows are written {window} (flow.windows.{window}.net_flow stands for
+flow.windows.1m.net_flow), list items need no [] marker, and lookups
+accept snake_case, camelCase or lowercase spellings (see field_naming.py). Keep an
+entry in step with its calculator when a formula changes.
+
+catalog() lists every report field for the context8://metrics/catalog
+resource and /metrics_catalog. Its fields and types are read from
+report_sample.json, the report contract the producer's output is tested
+against, and the version that introduced each field from the schema
+changelog (report_versions.py), so only the prose lives here. The REST
+server refuses to start if an entry documents a field the sample lacks.
+"""
+import difflib
+import functools
+import json
+import os
+import re
+from typing import Any
+
+import field_naming
+import report_versions
+
+CATALOG_URI = "context8://metrics/catalog"
+
+SAMPLE_FILE = os.path.join(os.path.dirname(os.path.abspath(__file__)), "report_sample.json")
+
+# Labels of flow windows (10s, 1m, 5m, 1h) in paths, replaced by {window}
+_WINDOW_LABEL = re.compile(r"^\d+[smh]$")
+
+# Placeholders for the keys of data-keyed objects (field_naming.DATA_KEYED_FIELDS) in catalog paths
+_KEY_PLACEHOLDERS = {"windows": "{window}", "last_transitions": "{status}"}
+
+# Types of fields the sample report leaves null
+_NULL_SAMPLE_TYPES = {
+    "ingestion.last_transitions.{status}": "string",
+    "market_status.since": "string",
+    "market_status.reason": "string",
+    "microstructure.baseline_updates_per_sec": "number",
+}
+
+METRICS: dict[str, dict[str, Any]] = {
+    "last_price": {
+        "description": "Price of the latest trade",
+        "formula": "price of the most recent trade tick",
+        "window": "latest trade",
+        "units": "quote currency",
+        "caveats": ["Stale in quiet markets; compare with mid_price and data_age_ms"],
+    },
+    "change_24h_pct": {
+        "description": "Price change over the last 24 hours",
+        "formula": "(last_price - price_24h_ago) / price_24h_ago * 100, from the venue's 24h ticker",
+        "window": "rolling 24h (venue ticker)",
+        "units": "percent",
+        "caveats": ["Computed by the venue, not the producer; updates at the ticker's cadence"],
+    },
+    "high_24h": {
+        "description": "Highest trade price in the last 24 hours",
+        "formula": "max(trade price) over 24h, from the venue's 24h ticker",
+        "window": "rolling 24h (venue ticker)",
+        "units": "quote currency",
+        "caveats": [],
+    },
+    "low_24h": {
+        "description": "Lowest trade price in the last 24 hours",
+        "formula": "min(trade price) over 24h, from the venue's 24h ticker",
+        "window": "rolling 24h (venue ticker)",
+        "units": "quote currency",
+        "caveats": [],
+    },
+    "volume_24h": {
+        "description": "Traded base-asset volume in the last 24 hours",
+        "formula": "sum(trade quantity) over 24h, from the venue's 24h ticker",
+        "window": "rolling 24h (venue ticker)",
+        "units": "base asset",
+        "caveats": [],
+    },
+    "volume_24h_usd": {
+        "description": "Traded volume in the last 24 hours in USD",
+        "formula": "quote volume over 24h * quote_usd_rate",
+        "window": "rolling 24h (venue ticker)",
+        "units": "USD",
+        "caveats": ["null when the quote asset's USD rate is unknown (not in NT_STABLE_QUOTES)"],
+    },
+    "best_bid": {
+        "description": "Highest bid price and its quantity",
+        "formula": "top level of the bid side of the L2 book",
+        "window": "instantaneous (book at publish time)",
+        "units": "price in quote currency, qty in base asset",
+        "caveats": [],
+    },
+    "best_ask": {
+        "description": "Lowest ask price and its quantity",
+        "formula": "top level of the ask side of the L2 book",
+        "window": "instantaneous (book at publish time)",
+        "units": "price in quote currency, qty in base asset",
+        "caveats": [],
+    },
+    "spread_bps": {
+        "description": "Bid-ask spread relative to the mid price",
+        "formula": "(best_ask - best_bid) / mid_price * 10000",
+        "window": "instantaneous (book at publish time)",
+        "units": "basis points",
+        "caveats": [
+            "Relative to the mid price, not the best bid",
+            "0 when either side of the book is missing or non-positive",
+        ],
+    },
+    "mid_price": {
+        "description": "Midpoint between the best bid and best ask",
+        "formula": "(best_bid + best_ask) / 2",
+        "window": "instantaneous (book at publish time)",
+        "units": "quote currency",
+        "caveats": [],
+    },
+    "micro_price": {
+        "description": "Top-of-book price weighted by the opposite side's size, a short-term fair value estimate",
+        "formula": "(best_ask.qty * best_bid.price + best_bid.qty * best_ask.price) / (best_bid.qty + best_ask.qty)",
+        "window": "instantaneous (book at publish time)",
+        "units": "quote currency",
+        "caveats": [
+            "Leans toward the ask when bids are larger (buying pressure) and toward the bid otherwise",
+            "Falls back to mid_price when both top quantities are zero",
+            "Uses only the top level; see depth.imbalance for deeper pressure",
+        ],
+    },
+    "depth.sum_bid": {
+        "description": "Total bid quantity in the top levels of the book",
+        "formula": "sum(qty) over the top 20 bid levels",
+        "window": "instantaneous (book at publish time)",
+        "units": "base asset",
+        "caveats": ["Counts displayed size only; iceberg and hidden orders are not included"],
+    },
+    "depth.sum_ask": {
+        "description": "Total ask quantity in the top levels of the book",
+        "formula": "sum(qty) over the top 20 ask levels",
+        "window": "instantaneous (book at publish time)",
+        "units": "base asset",
+        "caveats": ["Counts displayed size only; iceberg and hidden orders are not included"],
+    },
+    "depth.sum_bid_usd": {
+        "description": "USD notional of the top bid levels",
+        "formula": "sum(price * qty) over the top 20 bid levels * quote_usd_rate",
+        "window": "instantaneous (book at publish time)",
+        "units": "USD",
+        "caveats": ["null when the quote asset's USD rate is unknown"],
+    },
+    "depth.sum_ask_usd": {
+        "description": "USD notional of the top ask levels",
+        "formula": "sum(price * qty) over the top 20 ask levels * quote_usd_rate",
+        "window": "instantaneous (book at publish time)",
+        "units": "USD",
+        "caveats": ["null when the quote asset's USD rate is unknown"],
+    },
+    "depth.imbalance": {
+        "description": "Order book imbalance between bid and ask quantity in the top levels",
+        "formula": "(sum_bid - sum_ask) / (sum_bid + sum_ask)",
+        "window": "instantaneous (top 20 levels at publish time)",
+        "units": "ratio in [-1, 1]; positive means more bid size",
+        "caveats": [
+            "Quantity-weighted regardless of distance from mid, so far levels count as much as near ones",
+            "0 when both sides are empty",
+            "Can flip quickly when large orders are placed and pulled (see spoofing anomalies)",
+        ],
+    },
+    "depth.top20_bid": {
+        "description": "The top 20 bid levels, best first",
+        "formula": "price and qty of each level of the L2 book",
+        "window": "instantaneous (book at publish time)",
+        "units": "price in quote currency, qty in base asset",
+        "caveats": [],
+    },
+    "depth.top20_ask": {
+        "description": "The top 20 ask levels, best first",
+        "formula": "price and qty of each level of the L2 book",
+        "window": "instantaneous (book at publish time)",
+        "units": "price in quote currency, qty in base asset",
+        "caveats": [],
+    },
+    "flow.orders_per_sec": {
+        "description": "Trade rate",
+        "formula": "trade count / window length",
+        "window": "10s",
+        "units": "trades per second",
+        "caveats": ["Counts trades (executions), not order submissions"],
+    },
+    "flow.net_flow": {
+        "description": "Aggressor-signed traded volume: buyer-initiated minus seller-initiated",
+        "formula": "sum(qty of buy-aggressor trades) - sum(qty of sell-aggressor trades)",
+        "window": "FLOW_WINDOW_SEC (default 30s)",
+        "units": "base asset; positive means net buying",
+        "caveats": ["Aggressor side as reported by the venue", "0 without trades in the window"],
+    },
+    "flow.net_flow_usd": {
+        "description": "net_flow in USD",
+        "formula": "net_flow * last_price * quote_usd_rate",
+        "window": "FLOW_WINDOW_SEC (default 30s)",
+        "units": "USD",
+        "caveats": ["null when the quote asset's USD rate is unknown"],
+    },
+    "flow.windows.{window}.trades_per_sec": {
+        "description": "Trade rate over one flow window",
+        "formula": "trade count / window length",
+        "window": "the {window} label (NT_FLOW_WINDOWS, default 10s, 1m, 5m)",
+        "units": "trades per second",
+        "caveats": ["Averaged over the whole window, including the part before the producer started"],
+    },
+    "flow.windows.{window}.orders_per_sec": {
+        "description": "Trade rate over one flow window (same as trades_per_sec)",
+        "formula": "trade count / window length",
+        "window": "the {window} label (NT_FLOW_WINDOWS, default 10s, 1m, 5m)",
+        "units": "trades per second",
+        "caveats": [],
+    },
+    "flow.windows.{window}.buy_volume": {
+        "description": "Buyer-initiated traded volume over one flow window",
+        "formula": "sum(qty of buy-aggressor trades)",
+        "window": "the {window} label (NT_FLOW_WINDOWS, default 10s, 1m, 5m)",
+        "units": "base asset",
+        "caveats": [],
+    },
+    "flow.windows.{window}.sell_volume": {
+        "description": "Seller-initiated traded volume over one flow window",
+        "formula": "sum(qty of sell-aggressor trades)",
+        "window": "the {window} label (NT_FLOW_WINDOWS, default 10s, 1m, 5m)",
+        "units": "base asset",
+        "caveats": [],
+    },
+    "flow.windows.{window}.net_flow": {
+        "description": "Aggressor-signed traded volume over one flow window",
+        "formula": "buy_volume - sell_volume",
+        "window": "the {window} label (NT_FLOW_WINDOWS, default 10s, 1m, 5m)",
+        "units": "base asset; positive means net buying",
+        "caveats": [],
+    },
+    "flow.windows.{window}.net_flow_usd": {
+        "description": "net_flow over one flow window in USD",
+        "formula": "net_flow * last_price * quote_usd_rate",
+        "window": "the {window} label (NT_FLOW_WINDOWS, default 10s, 1m, 5m)",
+        "units": "USD",
+        "caveats": ["null when the quote asset's USD rate is unknown"],
+    },
+    "flow.windows.{window}.avg_trade_size": {
+        "description": "Mean trade size over one flow window",
+        "formula": "(buy_volume + sell_volume) / trade count",
+        "window": "the {window} label (NT_FLOW_WINDOWS, default 10s, 1m, 5m)",
+        "units": "base asset",
+        "caveats": ["0 without trades in the window"],
+    },
+    "flow.windows.{window}.buy_trade_count": {
+        "description": "Number of buyer-initiated trades over one flow window",
+        "formula": "count(buy-aggressor trades)",
+        "window": "the {window} label (NT_FLOW_WINDOWS, default 10s, 1m, 5m)",
+        "units": "trades",
+        "caveats": [],
+    },
+    "flow.windows.{window}.sell_trade_count": {
+        "description": "Number of seller-initiated trades over one flow window",
+        "formula": "count(sell-aggressor trades)",
+        "window": "the {window} label (NT_FLOW_WINDOWS, default 10s, 1m, 5m)",
+        "units": "trades",
+        "caveats": [],
+    },
+    "health.score": {
+        "description": "Overall market health: freshness, spread, book balance and anomalies combined",
+        "formula": (
+            "100 - 40 if data_age_ms > 2000 (20 if > 1000) - 30 if spread_bps > 100 (15 if > 50) "
+            "- 20 if abs(imbalance) >= 0.6 (10 if >= 0.3) - 10 with anomalies, clamped to 0-100"
+        ),
+        "window": "instantaneous (report at publish time)",
+        "units": "score 0-100; higher is healthier",
+        "caveats": [
+            "Moves in fixed steps, not continuously",
+            "Freshness and anomalies are not penalized while the market is not open (see market_status)",
+            "A heuristic for triage, not a tradability guarantee",
+        ],
+    },
+    "health.components": {
+        "description": "Per-component health scores (spread, depth, balance, flow, anomalies, freshness)",
+        "formula": "freshness repeats health.score; the other components are not computed yet and read 0",
+        "window": "instantaneous (report at publish time)",
+        "units": "score 0-100",
+        "caveats": ["Use health.score; the components are placeholders"],
+    },
+    "data_age_ms": {
+        "description": "Time since the last market data event for the symbol",
+        "formula": "publish time - timestamp of the latest book or trade event",
+        "window": "instantaneous",
+        "units": "milliseconds",
+        "caveats": ["Large in quiet or halted markets without the feed being down; check market_status"],
+    },
+    "ingestion.status": {
+        "description": "Data pipeline status of the symbol",
+        "formula": "ok below NT_INGESTION_DEGRADED_MS of data age, degraded below NT_INGESTION_DOWN_MS, down above",
+        "window": "instantaneous, with NT_INGESTION_MIN_DWELL_MS hysteresis on recovery",
+        "units": "ok | degraded | down",
+        "caveats": ["Thresholds default to 1000ms and 2000ms and may be overridden per symbol"],
+    },
+    "ingestion.uptime_pct_1h": {
+        "description": "Share of the last hour the symbol's ingestion status was ok",
+        "formula": "time in ok / time observed * 100",
+        "window": "rolling 1h",
+        "units": "percent",
+        "caveats": ["Covers only the time since the producer started when that is under an hour"],
+    },
+    "market_status.state": {
+        "description": "Venue trading status of the symbol",
+        "formula": "as reported by the venue",
+        "window": "instantaneous",
+        "units": "open | halted | auction | maintenance | closed",
+        "caveats": ["While not open, quiet data is expected and anomalies are suppressed"],
+    },
+    "microstructure.quote_updates_per_sec": {
+        "description": "Rate of order book updates",
+        "formula": "book updates / observed window length",
+        "window": "NT_CHURN_WINDOW_SEC (default 10s)",
+        "units": "updates per second",
+        "caveats": ["Measured over the part of the window since the first book update"],
+    },
+    "microstructure.baseline_updates_per_sec": {
+        "description": "Longer-run rate of order book updates, the baseline for quote stuffing detection",
+        "formula": "book updates / window length",
+        "window": "300s",
+        "units": "updates per second",
+        "caveats": ["null until the producer has observed the full 300s"],
+    },
+    "microstructure.churn_per_sec": {
+        "description": "Order book churn: quantity added and removed relative to resting depth",
+        "formula": "(added qty + removed qty) / resting depth / observed window length",
+        "window": "NT_CHURN_WINDOW_SEC (default 10s)",
+        "units": "book turnovers per second",
+        "caveats": ["High churn with little trading suggests quote flickering or spoofing"],
+    },
+    "microstructure.added_qty_per_sec": {
+        "description": "Quantity added to the book",
+        "formula": "sum(qty increases across levels) / observed window length",
+        "window": "NT_CHURN_WINDOW_SEC (default 10s)",
+        "units": "base asset per second",
+        "caveats": [],
+    },
+    "microstructure.removed_qty_per_sec": {
+        "description": "Quantity removed from the book, by cancels or fills",
+        "formula": "sum(qty decreases across levels) / observed window length",
+        "window": "NT_CHURN_WINDOW_SEC (default 10s)",
+        "units": "base asset per second",
+        "caveats": ["Does not separate cancels from executions"],
+    },
+    "analytics.volume_profile.POC": {
+        "description": "Point of control: the price with the most traded volume",
+        "formula": "center of the price bin with the largest traded volume (bins of tick_size / 5)",
+        "window": "rolling 30 minutes of trades (see analytics.volume_profile.window_sec)",
+        "units": "quote currency",
+        "caveats": ["Omitted with fewer than 10 trades"],
+    },
+    "analytics.volume_profile.VAH": {
+        "description": "Value area high: upper bound of the range holding 70% of traded volume around the POC",
+        "formula": "grow the range from the POC toward the larger neighbouring bin until it holds 70% of volume",
+        "window": "rolling 30 minutes of trades (see analytics.volume_profile.window_sec)",
+        "units": "quote currency",
+        "caveats": ["Omitted with fewer than 10 trades"],
+    },
+    "analytics.volume_profile.VAL": {
+        "description": "Value area low: lower bound of the range holding 70% of traded volume around the POC",
+        "formula": "grow the range from the POC toward the larger neighbouring bin until it holds 70% of volume",
+        "window": "rolling 30 minutes of trades (see analytics.volume_profile.window_sec)",
+        "units": "quote currency",
+        "caveats": ["Omitted with fewer than 10 trades"],
+    },
+    "analytics.poc_drift_bps": {
+        "description": "How far the point of control moved",
+        "formula": "(POC now - POC at the start of the window) / POC at the start * 10000",
+        "window": "NT_POC_TREND_WINDOW_SEC (default 900s)",
+        "units": "basis points",
+        "caveats": [],
+    },
+    "analytics.poc_trend": {
+        "description": "Direction of the point of control",
+        "formula": "up or down when abs(poc_drift_bps) exceeds NT_POC_TREND_THRESHOLD_BPS (default 5), else stable",
+        "window": "NT_POC_TREND_WINDOW_SEC (default 900s)",
+        "units": "up | down | stable",
+        "caveats": ["Omitted until three POCs are recorded"],
+    },
+    "analytics.developing_value_area": {
+        "description": "Volume profile (POC, VAH, VAL) of the current UTC session so far",
+        "formula": "as analytics.volume_profile, over trades since session_start in 1bps bins",
+        "window": "since the start of the UTC day",
+        "units": "quote currency",
+        "caveats": ["Unstable early in the session with few trades"],
+    },
+    "analytics.footprint": {
+        "description": "Buy vs sell executed volume per price bucket, with the most imbalanced buckets",
+        "formula": "imbalance = (buy_volume - sell_volume) / (buy_volume + sell_volume) per bucket",
+        "window": "NT_FOOTPRINT_WINDOW_SEC (default 300s)",
+        "units": "volumes in base asset; imbalance in [-1, 1]",
+        "caveats": ["Bucket size is NT_FOOTPRINT_BUCKET_BPS of price rounded to 1, 2 or 5 x 10^k"],
+    },
+    "liquidity.walls": {
+        "description": "Unusually large resting orders in the book, nearest to mid first",
+        "formula": "levels with qty above the rolling P95 of level quantities",
+        "window": "book at the latest slow cycle; P95 over recent book samples",
+        "units": "quantity in base asset, notional_usd in USD, distance_bps in basis points",
+        "caveats": [
+            "Severity is capped by the USD notional tier of the symbol",
+            "Walls can be pulled at any time (see spoofing anomalies)",
+        ],
+    },
+    "liquidity.vacuums": {
+        "description": "Runs of consecutive thin book levels, where price can move quickly",
+        "formula": "consecutive levels with qty below the rolling P10 of level quantities",
+        "window": "book at the latest slow cycle; P10 over recent book samples",
+        "units": "prices in quote currency",
+        "caveats": [],
+    },
+    "anomalies": {
+        "description": (
+            "Detected market anomalies: spoofing, iceberg, absorption, flash_crash_risk, "
+            "quote_stuffing and clock_skew, each with a severity and a note"
+        ),
+        "formula": "one detector per type (producer/src/calculators/anomalies.py)",
+        "window": "latest slow cycle; detectors look back over their own windows (e.g. 30s of trades)",
+        "units": "severity low | medium | high",
+        "caveats": [
+            "Heuristic signals labelled 'potential', not evidence of manipulation",
+            "Suppressed while the market is not open",
+            "Entries with synthetic=true were injected for testing",
+        ],
+    },
+    "relative_strength.performance_pct": {
+        "description": "The symbol's price change over the last 24 hours",
+        "formula": "(last_price - close of the digest from 24h ago) / that close * 100",
+        "window": "24h",
+        "units": "percent",
+        "caveats": ["null when no digest from 24h ago exists (producer or symbol younger than a day)"],
+    },
+    "relative_strength.vs_benchmark_pct": {
+        "description": "Performance relative to the benchmark symbol (BTCUSDT by default)",
+        "formula": "performance_pct - benchmark_performance_pct",
+        "window": "24h",
+        "units": "percentage points",
+        "caveats": [],
+    },
+    "relative_strength.vs_median_pct": {
+        "description": "Performance relative to the median of the tracked symbols",
+        "formula": "performance_pct - universe_median_pct",
+        "window": "24h",
+        "units": "percentage points",
+        "caveats": ["Universe is the symbols published in the last minute, not the whole venue"],
+    },
+    "relative_strength.rank": {
+        "description": "Rank of the symbol's 24h performance in the tracked universe",
+        "formula": "position in the universe sorted by performance_pct, descending",
+        "window": "24h",
+        "units": "1 = strongest, up to universe_size",
+        "caveats": [],
+    },
+    "timings.venue_lag_ms": {
+        "description": "Delay from the venue's event timestamp to the producer receiving it",
+        "formula": "receive time - venue event time, compensated for clock skew",
+        "window": "latest event",
+        "units": "milliseconds",
+        "caveats": ["Includes network latency to the venue"],
+    },
+    "timings.clock_skew_ms": {
+        "description": "Offset of the producer's clock from the venue's",
+        "formula": "min(receipt time - venue event time) over the window",
+        "window": "rolling 60s",
+        "units": "milliseconds; positive means the local clock is ahead",
+        "caveats": ["Above 1000ms a clock_skew anomaly is raised; check NTP"],
+    },
+    "timings.processing_ms": {
+        "description": "Time the producer spent computing the report",
+        "formula": "end - start of report assembly",
+        "window": "latest report",
+        "units": "milliseconds",
+        "caveats": [],
+    },
+}
+
+# Lowercase snake_case path -> catalog path, so any spelling of a field resolves
+_INDEX: dict[str, str] = {}
+
+
+def _normalize(path: str) -> str:
+    """Catalog spelling of a field path: {window} labels, no list markers, snake_case segments."""
+    segments = []
+    for segment in path.strip().replace("[]", "").split("."):
+        if segment.isdigit():
+            continue
+        if _WINDOW_LABEL.match(segment):
+            segment = "{window}"
+        elif not segment.isupper():
+            segment = field_naming.rename_key(segment, "snake_case")
+        segments.append(segment)
+    return ".".join(segments)
+
+
+def lookup(path: str) -> dict[str, Any] | None:
+    """Catalog entry of a field path, or None if the field isn't documented."""
+    if not _INDEX:
+        _INDEX.update({_normalize(key).lower(): key for key in METRICS})
+    key = path if path in METRICS else _INDEX.get(_normalize(path).lower())
+    if key is None:
+        return None
+    return {"field": key, **METRICS[key]}
+
+
+def children(path: str) -> list[str]:
+    """Documented fields under a section path (e.g. depth -> depth.imbalance, ...)."""
+    prefix = _normalize(path).lower() + "."
+    return [key for key in METRICS if _normalize(key).lower().startswith(prefix)]
+
+
+def suggestions(path: str, limit: int = 5) -> list[str]:
+    """Documented fields closest to an unknown path."""
+    return difflib.get_close_matches(_normalize(path), list(METRICS), n=limit, cutoff=0.5)
+
+
+def _json_type(value: Any) -> str:
+    if isinstance(value, bool):
+        return "boolean"
+    if isinstance(value, int):
+        return "integer"
+    if isinstance(value, float):
+        return "number"
+    if isinstance(value, str):
+        return "string"
+    if isinstance(value, list):
+        return "array"
+    if isinstance(value, dict):
+        return "object"
+    return "null"
+
+
+def sample_fields(report: dict[str, Any]) -> dict[str, str]:
+    """Field path -> JSON type of every field in a report, [] marking list items."""
+    fields: dict[str, set[str]] = {}
+
+    def walk(value: dict[str, Any], path: str, data_keyed: bool) -> None:
+        for key, item in value.items():
+            name = _KEY_PLACEHOLDERS.get(path.rsplit(".", 1)[-1], "{key}") if data_keyed else key
+            child = f"{path}.{name}" if path else name
+            fields.setdefault(child, set()).add(_json_type(item))
+            if isinstance(item, dict):
+                walk(item, child, key in field_naming.DATA_KEYED_FIELDS)
+            elif isinstance(item, list):
+                for element in item:
+                    if isinstance(element, dict):
+                        walk(element, child + "[]", False)
+
+    walk(report, "", False)
+    types = {}
+    for path, seen in fields.items():
+        seen.discard("null")
+        if "integer" in seen and "number" in seen:
+            seen.discard("integer")
+        types[path] = " | ".join(sorted(seen)) or _NULL_SAMPLE_TYPES.get(path, "null")
+    return types
+
+
+def _since(path: str) -> str:
+    """Schema version that added a field (or its section), 1.0 if it predates the changelog."""
+    for entry in report_versions.VERSIONS:
+        for added in [*entry["added"], *entry["renamed"].values()]:
+            if path == added or path.startswith(added + ".") or path.startswith(added + "[]"):
+                return entry["version"]
+    return report_versions.VERSIONS[0]["version"]
+
+
+@functools.cache
+def catalog() -> dict[str, Any]:
+    """Every report field with its type, the version that added it, and its definition when documented."""
+    with open(SAMPLE_FILE) as f:
+        types = sample_fields(json.load(f))
+
+    fields = []
+    for path, json_type in types.items():
+        entry = {"field": path, "type": json_type, "since": _since(path)}
+        definition = lookup(path)
+        if definition and _normalize(definition["field"]).lower() == _normalize(path).lower():
+            entry.update({key: value for key, value in definition.items() if key != "field"})
+        fields.append(entry)
+    return {
+        "schema_version": report_versions.CURRENT_VERSION,
+        "documented": sum(1 for entry in fields if "formula" in entry),
+        "fields": fields,
+    }
+
+
+def check_catalog(report: dict[str, Any]) -> list[str]:
+    """Catalog entries documenting fields the report doesn't have."""
+    present = {_normalize(path).lower() for path in sample_fields(report)}
+    return [
+        f"metric catalog: '{key}' is not a report field"
+        for key in METRICS if _normalize(key).lower() not in present
+    ]
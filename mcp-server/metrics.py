@@ -0,0 +1,197 @@
+"""
+Prometheus metrics for the Context8 MCP transports.
+
+Metrics are served on a dedicated port (METRICS_PORT, 0 = disabled),
+separate from the public API, optionally protected by Basic auth
+(METRICS_BASIC_AUTH="user:password").
+"""
+import base64
+import hmac
+import logging
+import os
+import threading
+from typing import TYPE_CHECKING
+from wsgiref.simple_server import WSGIRequestHandler, WSGIServer, make_server
+
+from prometheus_client import Counter, Gauge, Histogram, make_wsgi_app
+
+if TYPE_CHECKING:
+    from cache import CacheResult  # cache imports metrics
+
+logger = logging.getLogger(__name__)
+
+tool_calls = Counter(
+    "mcp_tool_calls_total",
+    "Total tool invocations by outcome",
+    ["tool", "outcome"],
+)
+
+tool_latency = Histogram(
+    "mcp_tool_latency_ms",
+    "Tool execution latency in milliseconds",
+    ["tool"],
+    buckets=[1, 5, 10, 25, 50, 100, 150, 250, 500, 1000],
+)
+
+cache_lookups = Counter(
+    "mcp_cache_lookups_total",
+    "Report cache lookups by result (hit, miss, stale)",
+    ["result"],
+)
+
+cache_coalesced_reads = Counter(
+    "mcp_cache_coalesced_reads_total",
+    "Cache reads that joined an identical read already in flight instead of querying Redis",
+    ["family"],
+)
+
+cache_replica_failovers = Counter(
+    "mcp_cache_replica_failovers_total",
+    "Reads that failed on a Redis replica and moved on to the next replica or the primary",
+    ["replica"],
+)
+
+symbol_requests = Counter(
+    "mcp_symbol_requests_total",
+    "MCP tool requests per symbol, tracked or not (see get_popular_symbols)",
+    ["symbol"],
+)
+
+sessions_active = Gauge(
+    "mcp_sessions_active",
+    "Open MCP sessions by transport",
+    ["transport"],
+)
+
+requests_in_flight = Gauge(
+    "mcp_requests_in_flight",
+    "Tool calls executing (holding a concurrency slot)",
+)
+
+requests_queued = Gauge(
+    "mcp_requests_queued",
+    "Tool calls waiting for a concurrency slot",
+)
+
+request_queue_wait = Histogram(
+    "mcp_request_queue_wait_ms",
+    "Time tool calls waited for a concurrency slot in milliseconds",
+    buckets=[1, 5, 10, 25, 50, 100, 250, 500, 1000],
+)
+
+requests_rejected = Counter(
+    "mcp_requests_rejected_total",
+    "Requests rejected with OVERLOADED by a concurrency limit",
+    ["reason"],
+)
+
+sessions_closed = Counter(
+    "mcp_sessions_closed_total",
+    "MCP sessions ended, by transport and reason (closed, expired)",
+    ["transport", "reason"],
+)
+
+jsonrpc_rejected = Counter(
+    "mcp_jsonrpc_rejected_total",
+    "JSON-RPC messages rejected by the strict checks (MCP_STRICT_JSONRPC), by reason",
+    ["reason"],
+)
+
+pipeline_status = Gauge(
+    "mcp_pipeline_status",
+    "Producer health from the pipeline probe (1 for the current status)",
+    ["status"],
+)
+
+pipeline_stream_age = Gauge(
+    "mcp_pipeline_stream_age_ms",
+    "Age of the newest entry of the producer's event stream in milliseconds",
+)
+
+tool_output_violations = Counter(
+    "mcp_tool_output_violations_total",
+    "Tool results not matching the strict form of their output schema (MCP_VALIDATE_OUTPUT)",
+    ["tool"],
+)
+
+rest_deprecated_requests = Counter(
+    "mcp_rest_deprecated_requests_total",
+    "REST requests to deprecated path aliases",
+    ["path"],
+)
+
+watch_changes = Counter(
+    "mcp_watch_changes_total",
+    "Changes emitted by watches (watch tool and /v1/stream/watch) by report field",
+    ["field"],
+)
+
+watch_streams = Gauge(
+    "mcp_watch_streams",
+    "Open /v1/stream/watch SSE connections",
+)
+
+
+def record_cache_lookup(result: "CacheResult") -> None:
+    """Record the status of a report cache lookup."""
+    cache_lookups.labels(result=result.status.value).inc()
+
+
+def record_tool_call(tool: str, outcome: str, latency_ms: float) -> None:
+    """Record a tool invocation and its latency."""
+    tool_calls.labels(tool=tool, outcome=outcome).inc()
+    tool_latency.labels(tool=tool).observe(latency_ms)
+
+
+class _QuietHandler(WSGIRequestHandler):
+    """Request handler that doesn't log every scrape."""
+
+    def log_message(self, format, *args):
+        pass
+
+
+def create_metrics_app(basic_auth: str = ""):
+    """WSGI app serving /metrics, requiring Basic credentials when configured."""
+    metrics_app = make_wsgi_app()
+    expected = "Basic " + base64.b64encode(basic_auth.encode("utf-8")).decode("ascii")
+
+    def app(environ, start_response):
+        if environ.get("PATH_INFO", "/") not in ("/metrics", "/"):
+            start_response("404 Not Found", [("Content-Type", "text/plain")])
+            return [b"Not Found"]
+        if basic_auth and not hmac.compare_digest(environ.get("HTTP_AUTHORIZATION", ""), expected):
+            start_response("401 Unauthorized", [
+                ("Content-Type", "text/plain"),
+                ("WWW-Authenticate", 'Basic realm="metrics"'),
+            ])
+            return [b"Unauthorized"]
+        return metrics_app(environ, start_response)
+
+    return app
+
+
+class MetricsServer:
+    """Background HTTP server exposing /metrics."""
+
+    def __init__(self, port: int | None = None, basic_auth: str | None = None):
+        self.port = port if port is not None else int(os.getenv("METRICS_PORT", "0"))
+        self.basic_auth = basic_auth if basic_auth is not None else os.getenv("METRICS_BASIC_AUTH", "")
+        self._httpd: WSGIServer | None = None
+
+    def start(self) -> None:
+        """Start serving metrics, if a port is configured."""
+        if not self.port:
+            return
+        self._httpd = make_server(
+            "", self.port, create_metrics_app(self.basic_auth), handler_class=_QuietHandler
+        )
+        threading.Thread(target=self._httpd.serve_forever, daemon=True).start()
+        logger.info(f"Serving metrics on :{self.port}/metrics (basic_auth={bool(self.basic_auth)})")
+
+    def stop(self) -> None:
+        """Stop the server, finishing any in-flight scrape."""
+        if self._httpd:
+            self._httpd.shutdown()
+            self._httpd.server_close()
+            self._httpd = None
+            logger.info("Metrics server stopped")
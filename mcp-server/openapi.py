@@ -0,0 +1,27 @@
+"""
+OpenAPI document for the REST server.
+
+Paths are generated from the YAML block after `---` in each route
+handler's docstring, so the document always matches the registered
+routes. Info, servers and component schemas come from openapi.yaml.
+"""
+import os
+from typing import Any
+
+import yaml
+from starlette.schemas import SchemaGenerator
+
+SPEC_FILE = os.path.join(os.path.dirname(os.path.abspath(__file__)), "openapi.yaml")
+
+
+def load_base_schema(path: str = SPEC_FILE) -> dict[str, Any]:
+    """Load the shared parts of the document (everything except paths)."""
+    with open(path) as f:
+        base = yaml.safe_load(f)
+    base.pop("paths", None)
+    return base
+
+
+def generate(routes) -> dict[str, Any]:
+    """Build the OpenAPI document for the given Starlette routes."""
+    return SchemaGenerator(load_base_schema()).get_schema(routes=routes)
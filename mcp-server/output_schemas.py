@@ -0,0 +1,586 @@
+"""
+Output schemas of the MCP tools.
+
+Tools with an output schema return their result twice in CallToolResult:
+as structuredContent (validated by the MCP SDK against the schema) and as
+JSON text for clients that predate structured content. Errors are
+returned as the shared error contract, so every output schema also
+accepts ERROR_SCHEMA.
+
+The schemas describe the fields clients can rely on and stay open
+(additional properties allowed), so older reports migrated on read and
+newer optional sections both validate. With MCP_VALIDATE_OUTPUT=true the
+server also checks every result against the strict form of its schema
+(no undeclared fields) and logs and counts mismatches in
+mcp_tool_output_violations_total, so drift between what the producer
+publishes and what the tools advertise shows up before clients notice.
+Schemas are declared in the original field naming; output_schema()
+renames them for responses in snake_case or camelCase (field_naming.py),
+and the outputSchema tools advertise (advertised_schema()) accepts every
+naming, since clients pick one per call.
+"""
+import copy
+import logging
+import os
+from typing import Any
+
+import jsonschema
+
+import field_naming
+import metrics
+
+logger = logging.getLogger(__name__)
+
+NUMBER = {"type": ["number", "null"]}
+INTEGER = {"type": ["integer", "null"]}
+STRING = {"type": ["string", "null"]}
+OBJECT = {"type": ["object", "null"]}
+ARRAY = {"type": ["array", "null"]}
+# RFC3339 string, or epoch milliseconds with time_format=epoch_ms (see time_format.py)
+TIMESTAMP = {"type": ["string", "integer", "null"]}
+
+ERROR_SCHEMA: dict[str, Any] = {
+    "type": "object",
+    "required": ["code", "message"],
+    "properties": {
+        "code": {"type": "string"},
+        "message": {"type": "string"},
+        "suggestion": STRING,
+        "correlation_id": STRING,
+        "retry_after": INTEGER,
+        "details": OBJECT,
+    },
+}
+
+PRICE_LEVEL = {
+    "type": ["object", "null"],
+    "properties": {"price": NUMBER, "qty": NUMBER},
+}
+
+REPORT_SCHEMA: dict[str, Any] = {
+    "type": "object",
+    "required": ["symbol", "schemaVersion"],
+    "properties": {
+        "schemaVersion": {"type": "string"},
+        "symbol": {"type": "string"},
+        "venue": STRING,
+        "meta": OBJECT,
+        "generated_at": TIMESTAMP,
+        "updatedAt": INTEGER,
+        "data_age_ms": INTEGER,
+        "ingestion": OBJECT,
+        "market_status": OBJECT,
+        "last_price": NUMBER,
+        "change_24h_pct": NUMBER,
+        "high_24h": NUMBER,
+        "low_24h": NUMBER,
+        "volume_24h": NUMBER,
+        "volume_24h_usd": NUMBER,
+        "best_bid": PRICE_LEVEL,
+        "best_ask": PRICE_LEVEL,
+        "spread_bps": NUMBER,
+        "mid_price": NUMBER,
+        "micro_price": NUMBER,
+        "depth": OBJECT,
+        "flow": OBJECT,
+        "microstructure": OBJECT,
+        "liquidity": OBJECT,
+        "analytics": OBJECT,
+        "anomalies": ARRAY,
+        "recent_trades": ARRAY,
+        "health": OBJECT,
+        "timings": OBJECT,
+        "relative_strength": OBJECT,
+        "provenance": ARRAY,
+        "slow_cycle_updated_at": INTEGER,
+        "writer": OBJECT,
+        "pipeline": OBJECT,
+    },
+}
+
+CACHE_META = {
+    "type": "object",
+    "properties": {
+        "cache_status": {"type": "string"},
+        "cache_age_ms": INTEGER,
+        "cache_latency_ms": NUMBER,
+    },
+}
+
+INGESTION_STATUS_SCHEMA: dict[str, Any] = {
+    "type": "object",
+    "required": ["symbol", "status", "cache"],
+    "properties": {
+        "symbol": {"type": "string"},
+        "status": STRING,
+        "data_age_ms": INTEGER,
+        "last_update": TIMESTAMP,
+        "since": TIMESTAMP,
+        "last_transitions": OBJECT,
+        "uptime_pct_1h": NUMBER,
+        "market_status": OBJECT,
+        "completeness": {"type": "number", "minimum": 0, "maximum": 1},
+        "missing_sections": {"type": "array", "items": {"type": "string"}},
+        "cache": CACHE_META,
+        "pipeline": OBJECT,
+        "provenance": OBJECT,
+    },
+}
+
+DEPTH_POINT = {
+    "type": "object",
+    "properties": {
+        "price": {"type": "number"},
+        "distance_bps": {"type": "number"},
+        "cum_qty": {"type": "number"},
+        "cum_notional_usd": {"type": "number"},
+    },
+}
+
+DEPTH_CHART_SCHEMA: dict[str, Any] = {
+    "type": "object",
+    "required": ["symbol", "mid_price", "bids", "asks"],
+    "properties": {
+        "symbol": {"type": "string"},
+        "mid_price": {"type": "number"},
+        "max_distance_bps": {"type": "number"},
+        "bids": {"type": "array", "items": DEPTH_POINT},
+        "asks": {"type": "array", "items": DEPTH_POINT},
+        "book_covers_bps": {
+            "type": "object",
+            "properties": {"bid": {"type": "number"}, "ask": {"type": "number"}},
+        },
+        "generated_at": TIMESTAMP,
+        "cache": CACHE_META,
+    },
+}
+
+QTY_GRID = {"type": "array", "items": {"type": "array", "items": {"type": "number"}}}
+
+BOOK_HISTORY_SCHEMA: dict[str, Any] = {
+    "type": "object",
+    "required": ["symbol", "snapshot_count", "times", "price_buckets"],
+    "properties": {
+        "symbol": {"type": "string"},
+        "lookback_sec": {"type": "integer"},
+        "snapshot_count": {"type": "integer"},
+        "bucket_bps": {"type": "number"},
+        "times": {"type": "array", "items": TIMESTAMP},
+        "mids": {"type": "array", "items": {"type": "number"}},
+        "price_buckets": {"type": "array", "items": {"type": "number"}},
+        "bid_qty": QTY_GRID,
+        "ask_qty": QTY_GRID,
+        "cache": CACHE_META,
+    },
+}
+
+FOOTPRINT_LEVEL = {
+    "type": "object",
+    "properties": {
+        "price": {"type": "number"},
+        "buy_volume": {"type": "number"},
+        "sell_volume": {"type": "number"},
+        "delta": {"type": "number"},
+        "imbalance": {"type": "number"},
+        "trade_count": {"type": "integer"},
+    },
+}
+
+FOOTPRINT_SCHEMA: dict[str, Any] = {
+    "type": "object",
+    "required": ["levels"],
+    "properties": {
+        "symbol": {"type": "string"},
+        "updated_at": INTEGER,
+        "window_sec": {"type": "integer"},
+        "bucket_size": {"type": "number"},
+        "levels": {"type": "array", "items": FOOTPRINT_LEVEL},
+        "top_imbalances": {"type": "array", "items": FOOTPRINT_LEVEL},
+        "cache": CACHE_META,
+    },
+}
+
+TRADE = {
+    "type": "object",
+    "properties": {
+        "price": {"type": "number"},
+        "size": {"type": "number"},
+        "side": {"type": "string", "enum": ["buy", "sell"]},
+        "time": {"type": ["string", "integer"]},
+    },
+}
+
+TRADES_SCHEMA: dict[str, Any] = {
+    "type": "object",
+    "required": ["symbol", "count", "trades"],
+    "properties": {
+        "symbol": {"type": "string"},
+        "updated_at": INTEGER,
+        "count": {"type": "integer"},
+        "trades": {"type": "array", "items": TRADE},
+        "cache": CACHE_META,
+    },
+}
+
+DIGEST = {
+    "type": "object",
+    "properties": {
+        "symbol": {"type": "string"},
+        "minute": {"type": "string"},
+        "start_ms": {"type": "integer"},
+        "ohlc": {
+            "type": ["object", "null"],
+            "properties": {
+                "open": {"type": "number"},
+                "high": {"type": "number"},
+                "low": {"type": "number"},
+                "close": {"type": "number"},
+            },
+        },
+        "volume": NUMBER,
+        "vwap": NUMBER,
+        "trade_count": {"type": "integer"},
+        "avg_spread_bps": NUMBER,
+        "avg_imbalance": NUMBER,
+        "report_samples": {"type": "integer"},
+        "ingestion_ok_samples": {"type": "integer"},
+        "anomaly_counts": {"type": "object", "additionalProperties": {"type": "integer"}},
+    },
+}
+
+DIGESTS_SCHEMA: dict[str, Any] = {
+    "type": "object",
+    "required": ["symbol", "count", "digests"],
+    "properties": {
+        "symbol": {"type": "string"},
+        "minutes": {"type": "integer"},
+        "count": {"type": "integer"},
+        "digests": {"type": "array", "items": DIGEST},
+    },
+}
+
+DAILY_STATS = {
+    "type": "object",
+    "properties": {
+        "symbol": {"type": "string"},
+        "date": {"type": "string"},
+        "ohlc": DIGEST["properties"]["ohlc"],
+        "volume": NUMBER,
+        "notional": NUMBER,
+        "vwap": NUMBER,
+        "trade_count": {"type": "integer"},
+        "avg_spread_bps": NUMBER,
+        "report_samples": {"type": "integer"},
+        "anomaly_totals": {"type": "object", "additionalProperties": {"type": "integer"}},
+        "minutes": {"type": "integer"},
+        "uptime_minutes": NUMBER,
+        "uptime_pct": NUMBER,
+        "last_minute": {"type": ["string", "null"]},
+        "source": {"type": "string", "enum": ["history"]},
+    },
+}
+
+DAILY_STATS_SCHEMA: dict[str, Any] = {
+    "type": "object",
+    "required": ["symbol", "count", "stats"],
+    "properties": {
+        "symbol": {"type": "string"},
+        "days": {"type": "integer"},
+        "count": {"type": "integer"},
+        "stats": {"type": "array", "items": DAILY_STATS},
+    },
+}
+
+REPORT_HISTORY_SCHEMA: dict[str, Any] = {
+    "type": "object",
+    "required": ["symbol", "count", "points"],
+    "properties": {
+        "symbol": {"type": "string"},
+        "lookback_minutes": {"type": "integer"},
+        "interval_sec": {"type": "integer"},
+        "count": {"type": "integer"},
+        "points": {
+            "type": "array",
+            "items": {
+                "type": "object",
+                "properties": {
+                    "ts": {"type": "integer"},
+                    "mid_price": NUMBER,
+                    "last_price": NUMBER,
+                    "avg_spread_bps": NUMBER,
+                    "avg_imbalance": NUMBER,
+                    "min_health_score": NUMBER,
+                    "max_anomaly_count": {"type": ["integer", "null"]},
+                    "samples": {"type": "integer"},
+                },
+            },
+        },
+    },
+}
+
+VENUE_STATUS_SCHEMA: dict[str, Any] = {
+    "type": "object",
+    "required": ["venue", "stress_index", "level"],
+    "properties": {
+        "venue": {"type": "string"},
+        "updatedAt": {"type": "integer"},
+        "symbols": {"type": "integer"},
+        "stress_index": NUMBER,
+        "level": {"type": "string", "enum": ["calm", "elevated", "stressed"]},
+        "components": {
+            "type": "object",
+            "properties": {
+                "wide_spread_pct": NUMBER,
+                "degraded_ingestion_pct": NUMBER,
+                "flash_crash_risk_pct": NUMBER,
+            },
+        },
+        "stressed_symbols": {
+            "type": "object",
+            "properties": {
+                "wide_spread": {"type": "array", "items": {"type": "string"}},
+                "degraded_ingestion": {"type": "array", "items": {"type": "string"}},
+                "flash_crash_risk": {"type": "array", "items": {"type": "string"}},
+            },
+        },
+    },
+}
+
+CHANGELOG_SCHEMA: dict[str, Any] = {
+    "type": "object",
+    "required": ["current_version", "versions"],
+    "properties": {
+        "current_version": {"type": "string"},
+        "versions": {"type": "array", "items": {"type": "object"}},
+    },
+}
+
+METRIC_DEFINITION = {
+    "type": "object",
+    "properties": {
+        "field": {"type": "string"},
+        "description": {"type": "string"},
+        "formula": {"type": "string"},
+        "window": {"type": "string"},
+        "units": {"type": "string"},
+        "caveats": {"type": "array", "items": {"type": "string"}},
+    },
+}
+
+# A field's definition, or a section's documented fields
+METRIC_EXPLANATION_SCHEMA: dict[str, Any] = {
+    **METRIC_DEFINITION,
+    "required": ["field"],
+    "properties": {
+        **METRIC_DEFINITION["properties"],
+        "fields": {"type": "array", "items": METRIC_DEFINITION},
+    },
+}
+
+QUOTA_PERIOD = {
+    "type": "object",
+    "properties": {
+        "used": {"type": "integer"},
+        "limit": INTEGER,
+        "reset_at": {"type": "string"},
+    },
+}
+
+USAGE_SCHEMA: dict[str, Any] = {
+    "type": "object",
+    "required": ["api_key", "daily", "monthly"],
+    "properties": {
+        "api_key": {"type": "string"},
+        "daily": QUOTA_PERIOD,
+        "monthly": QUOTA_PERIOD,
+    },
+}
+
+POPULAR_SYMBOLS_SCHEMA: dict[str, Any] = {
+    "type": "object",
+    "required": ["days", "symbols", "unrequested"],
+    "properties": {
+        "days": {"type": "integer"},
+        "total_requests": {"type": "integer"},
+        "symbols": {
+            "type": "array",
+            "items": {
+                "type": "object",
+                "properties": {
+                    "symbol": {"type": "string"},
+                    "requests": {"type": "integer"},
+                    "tracked": {"type": "boolean"},
+                },
+            },
+        },
+        "unrequested": {"type": "array", "items": {"type": "string"}},
+    },
+}
+
+WATCH_SCHEMA: dict[str, Any] = {
+    "type": "object",
+    "required": ["watch_id", "symbols", "events"],
+    "properties": {
+        "watch_id": {"type": "string"},
+        "symbols": {"type": "array", "items": {"type": "string"}},
+        "criteria": {
+            "type": "object",
+            "properties": {
+                "spread_change_pct": NUMBER,
+                "mid_change_bps": NUMBER,
+                "health_change": NUMBER,
+                "imbalance_change": NUMBER,
+                "new_anomaly": {"type": "boolean"},
+                "status_change": {"type": "boolean"},
+            },
+        },
+        "rules": {"type": "array", "items": {"type": "object"}},
+        "events": {
+            "type": "array",
+            "items": {
+                "type": "object",
+                "required": ["symbol", "changes"],
+                "properties": {
+                    "symbol": {"type": "string"},
+                    "updatedAt": INTEGER,
+                    "changes": {
+                        "type": "array",
+                        "items": {
+                            "type": "object",
+                            "required": ["field"],
+                            "properties": {
+                                "field": {"type": "string"},
+                                "from": {},
+                                "to": {},
+                                "change_pct": NUMBER,
+                                "change_bps": NUMBER,
+                                "anomaly": {"type": "object"},
+                                "rule": {"type": "object"},
+                                "direction": {"type": "string", "enum": ["up", "down"]},
+                            },
+                        },
+                    },
+                },
+            },
+        },
+        "expires_in_sec": {"type": "integer"},
+    },
+}
+
+
+def with_errors(schema: dict[str, Any]) -> dict[str, Any]:
+    """Output schema accepting the tool's result or the error contract."""
+    return {"type": "object", "anyOf": [schema, ERROR_SCHEMA]}
+
+
+# Tool name -> declared outputSchema
+OUTPUT_SCHEMAS: dict[str, dict[str, Any]] = {
+    "get_report": with_errors(REPORT_SCHEMA),
+    "get_ingestion_status": with_errors(INGESTION_STATUS_SCHEMA),
+    "get_depth_chart": with_errors(DEPTH_CHART_SCHEMA),
+    "get_book_history": with_errors(BOOK_HISTORY_SCHEMA),
+    "get_footprint": with_errors(FOOTPRINT_SCHEMA),
+    "get_trades": with_errors(TRADES_SCHEMA),
+    "get_digest": with_errors(DIGESTS_SCHEMA),
+    "get_daily_stats": with_errors(DAILY_STATS_SCHEMA),
+    "get_report_history": with_errors(REPORT_HISTORY_SCHEMA),
+    "get_venue_status": with_errors(VENUE_STATUS_SCHEMA),
+    "get_schema_changelog": with_errors(CHANGELOG_SCHEMA),
+    "explain_metric": with_errors(METRIC_EXPLANATION_SCHEMA),
+    "get_usage": with_errors(USAGE_SCHEMA),
+    "get_popular_symbols": with_errors(POPULAR_SYMBOLS_SCHEMA),
+    "watch": with_errors(WATCH_SCHEMA),
+}
+
+# (tool, naming) -> renamed outputSchema, kept so strict validators stay cached
+_named_schemas: dict[tuple[str, str], dict[str, Any]] = {}
+
+
+def output_schema(tool: str, naming: str = "original") -> dict[str, Any] | None:
+    """outputSchema of a tool's results rendered in one field naming."""
+    schema = OUTPUT_SCHEMAS.get(tool)
+    if schema is None or naming == "original":
+        return schema
+    if (tool, naming) not in _named_schemas:
+        _named_schemas[(tool, naming)] = field_naming.rename_schema(schema, naming)
+    return _named_schemas[(tool, naming)]
+
+
+def advertised_schema(tool: str) -> dict[str, Any] | None:
+    """outputSchema a tool declares: its result and error in every field naming.
+
+    field_naming is chosen per call, so the declared schema accepts each
+    naming's branches, the default naming's first.
+    """
+    if tool not in OUTPUT_SCHEMAS:
+        return None
+    default = field_naming.default_naming()
+    namings = [default, *(n for n in field_naming.FIELD_NAMINGS if n != default)]
+    branches: list[dict[str, Any]] = []
+    for naming in namings:
+        for branch in output_schema(tool, naming)["anyOf"]:
+            if branch not in branches:
+                branches.append(branch)
+    return {"type": "object", "anyOf": branches}
+
+
+def strict(schema: Any) -> Any:
+    """Copy of a schema that rejects properties it doesn't declare."""
+    schema = copy.deepcopy(schema)
+    _close(schema)
+    return schema
+
+
+def _close(node: Any) -> None:
+    if isinstance(node, list):
+        for item in node:
+            _close(item)
+    elif isinstance(node, dict):
+        if "properties" in node:
+            node.setdefault("additionalProperties", False)
+        for value in node.values():
+            _close(value)
+
+
+def validation_enabled() -> bool:
+    return os.getenv("MCP_VALIDATE_OUTPUT", "false").lower() in ("1", "true", "yes")
+
+
+_strict_validators: dict[int, jsonschema.Draft202012Validator] = {}
+
+
+def _strict_validator(schema: dict[str, Any]) -> jsonschema.Draft202012Validator:
+    validator = _strict_validators.get(id(schema))
+    if validator is None:
+        validator = _strict_validators[id(schema)] = jsonschema.Draft202012Validator(strict(schema))
+    return validator
+
+
+def check_output(tool: str, result: Any, naming: str = "original") -> list[str]:
+    """Strict-schema violations of a tool result (debug mode); logged and counted.
+
+    Args:
+        naming: Field naming the result was rendered in
+
+    Returns:
+        Violation messages, empty when the result matches or the tool has no schema
+    """
+    schema = output_schema(tool, naming)
+    if schema is None:
+        return []
+
+    # Validate against one branch of the anyOf (result, error) so messages point at the offending field
+    branch = schema["anyOf"][1] if _is_error(result) else schema["anyOf"][0]
+    problems = [
+        f"{'/'.join(str(p) for p in error.absolute_path) or '<root>'}: {error.message}"
+        for error in _strict_validator(branch).iter_errors(result)
+    ]
+    for problem in problems:
+        logger.warning(f"Output schema drift tool={tool} {problem}")
+    if problems:
+        metrics.tool_output_violations.labels(tool=tool).inc(len(problems))
+    return problems
+
+
+def _is_error(result: Any) -> bool:
+    return isinstance(result, dict) and "code" in result and "message" in result and "symbol" not in result
@@ -0,0 +1,143 @@
+"""
+Producer health probe (the pipeline block and /ready).
+
+A report's ingestion status says whether one symbol's data is fresh. It
+can't tell a quiet symbol from a producer that died or lost the venue:
+either way reports stop changing. The probe watches the producer itself:
+
+- stream: age of the newest entry of the producer's event stream
+  (PIPELINE_STREAM_KEY, default nt:binance), which every venue event
+  appends to, whatever the symbol;
+- heartbeat: optional key the producer keeps alive (PIPELINE_HEARTBEAT_KEY,
+  e.g. nt:node:producer-1). A missing key means the producer is gone; a
+  JSON value with last_heartbeat (ISO UTC) or an epoch-ms value also gives
+  its age.
+
+Status is ok, degraded once an age exceeds PIPELINE_DEGRADED_MS (default
+5000) and down once it exceeds PIPELINE_DOWN_MS (default 30000) or the
+stream or heartbeat key is missing; unknown if Redis can't be read.
+Results are cached for PIPELINE_PROBE_INTERVAL_MS (default 1000) so busy
+servers probe Redis at most once a second. Leave PIPELINE_STREAM_KEY empty
+when the stream lives on a Redis the server doesn't read (REDIS_STREAM_URL
+on the producer).
+
+    "pipeline": {"status": "ok", "checked_at": 1760601600000,
+        "stream": {"key": "nt:binance", "last_entry_age_ms": 42},
+        "heartbeat": {"key": "nt:node:producer-1", "present": true, "age_ms": 610}}
+"""
+import json
+import logging
+import os
+import time
+from datetime import datetime, timezone
+from typing import Any
+
+import metrics
+from cache import RedisCache
+
+logger = logging.getLogger(__name__)
+
+DEFAULT_STREAM_KEY = "nt:binance"
+DEFAULT_DEGRADED_MS = 5000
+DEFAULT_DOWN_MS = 30000
+DEFAULT_PROBE_INTERVAL_MS = 1000
+
+PIPELINE_STATUSES = ("ok", "degraded", "down", "unknown")
+
+
+def _heartbeat_age_ms(value: str, now_ms: int) -> int | None:
+    """Age of a heartbeat value (epoch ms, or JSON with last_heartbeat), None if it has no time."""
+    try:
+        parsed = json.loads(value)
+    except ValueError:
+        return None
+    if isinstance(parsed, (int, float)) and not isinstance(parsed, bool):
+        return max(0, now_ms - int(parsed))
+    if isinstance(parsed, dict) and isinstance(parsed.get("last_heartbeat"), str):
+        try:
+            beat = datetime.fromisoformat(parsed["last_heartbeat"])
+        except ValueError:
+            return None
+        if beat.tzinfo is None:
+            beat = beat.replace(tzinfo=timezone.utc)
+        return max(0, now_ms - int(beat.timestamp() * 1000))
+    return None
+
+
+class PipelineProbe:
+    """Probes the producer's event stream and heartbeat key."""
+
+    def __init__(
+        self,
+        cache: RedisCache,
+        stream_key: str | None = None,
+        heartbeat_key: str | None = None,
+    ):
+        self.cache = cache
+        self.stream_key = os.getenv("PIPELINE_STREAM_KEY", DEFAULT_STREAM_KEY) if stream_key is None else stream_key
+        self.heartbeat_key = os.getenv("PIPELINE_HEARTBEAT_KEY", "") if heartbeat_key is None else heartbeat_key
+        self.degraded_ms = int(os.getenv("PIPELINE_DEGRADED_MS", str(DEFAULT_DEGRADED_MS)))
+        self.down_ms = int(os.getenv("PIPELINE_DOWN_MS", str(DEFAULT_DOWN_MS)))
+        self.interval_ms = int(os.getenv("PIPELINE_PROBE_INTERVAL_MS", str(DEFAULT_PROBE_INTERVAL_MS)))
+        self._last: dict[str, Any] | None = None
+
+    @property
+    def enabled(self) -> bool:
+        return bool(self.stream_key or self.heartbeat_key)
+
+    def _grade(self, age_ms: int | None) -> str:
+        if age_ms is None or age_ms > self.down_ms:
+            return "down"
+        return "degraded" if age_ms > self.degraded_ms else "ok"
+
+    async def status(self) -> dict[str, Any] | None:
+        """Producer health, probed at most once per interval; None when nothing is configured."""
+        if not self.enabled:
+            return None
+        now_ms = int(time.time() * 1000)
+        if self._last and now_ms - self._last["checked_at"] < self.interval_ms:
+            return self._last
+        self._last = await self.probe(now_ms)
+        return self._last
+
+    async def probe(self, now_ms: int) -> dict[str, Any]:
+        """Read the stream's newest entry and the heartbeat key and grade them."""
+        result: dict[str, Any] = {"status": "ok", "checked_at": now_ms, "stream": None, "heartbeat": None}
+        grades = []
+        try:
+            if self.stream_key:
+                entry_id = await self.cache.get_stream_last_id(self.stream_key)
+                age_ms = max(0, now_ms - int(entry_id.split("-")[0])) if entry_id else None
+                result["stream"] = {"key": self.stream_key, "last_entry_age_ms": age_ms}
+                grades.append(self._grade(age_ms))
+                if age_ms is not None:
+                    metrics.pipeline_stream_age.set(age_ms)
+            if self.heartbeat_key:
+                value = await self.cache.get_value(self.heartbeat_key)
+                age_ms = _heartbeat_age_ms(value, now_ms) if value is not None else None
+                result["heartbeat"] = {"key": self.heartbeat_key, "present": value is not None, "age_ms": age_ms}
+                # A live key without a timestamp is trusted to expire (TTL) when the producer stops
+                grades.append("down" if value is None else self._grade(age_ms) if age_ms is not None else "ok")
+        except Exception as e:
+            logger.warning(f"Pipeline probe failed: {e}")
+            result["status"] = "unknown"
+            result["error"] = str(e)
+        else:
+            result["status"] = max(grades, key=PIPELINE_STATUSES.index, default="ok")
+
+        for status in PIPELINE_STATUSES:
+            metrics.pipeline_status.labels(status=status).set(1 if status == result["status"] else 0)
+        return result
+
+
+async def readiness(cache: RedisCache, probe: PipelineProbe) -> tuple[dict[str, Any], int]:
+    """/ready body and HTTP status: 503 when Redis is unreachable or the producer is down."""
+    try:
+        redis_ok = bool(cache.client and await cache.client.ping())
+    except Exception as e:
+        logger.warning(f"Readiness Redis ping failed: {e}")
+        redis_ok = False
+    producer = await probe.status() if redis_ok else None
+    ready = redis_ok and (producer is None or producer["status"] != "down")
+    body = {"status": "ready" if ready else "not_ready", "redis": redis_ok, "pipeline": producer}
+    return body, 200 if ready else 503
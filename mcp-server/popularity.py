@@ -0,0 +1,92 @@
+"""
+Per-symbol report consumption.
+
+Every MCP tool call for a valid symbol increments a daily counter in Redis:
+    mcp:popularity:{YYYYMMDD}:{SYMBOL}
+kept for POPULARITY_RETENTION_DAYS (default 30). Symbols requested but not
+tracked by the producer are counted too, so operators can see demand for
+symbols to add as well as tracked symbols nobody reads (candidates for
+pruning). The get_popular_symbols admin tool ranks symbols over a window
+of days; mcp_symbol_requests_total{symbol} counts the same requests since
+the process started.
+"""
+import logging
+import os
+from datetime import datetime, timedelta, timezone
+from typing import Any
+
+import metrics
+from cache import RedisCache
+
+logger = logging.getLogger(__name__)
+
+KEY_PREFIX = "mcp:popularity:"
+DEFAULT_RETENTION_DAYS = 30
+
+
+def _day(ts: datetime) -> str:
+    return f"{ts:%Y%m%d}"
+
+
+class SymbolPopularity:
+    """Daily per-symbol request counters."""
+
+    def __init__(self, cache: RedisCache, retention_days: int | None = None):
+        self.cache = cache
+        if retention_days is None:
+            retention_days = int(os.getenv("POPULARITY_RETENTION_DAYS", str(DEFAULT_RETENTION_DAYS)))
+        self.retention_days = retention_days
+
+    async def record(self, symbol: str) -> None:
+        """Count one request for symbol. Failures never fail the request."""
+        metrics.symbol_requests.labels(symbol=symbol).inc()
+        if not self.cache.client:
+            return
+
+        now = datetime.now(timezone.utc)
+        key = f"{KEY_PREFIX}{_day(now)}:{symbol}"
+        expires_at = now.replace(hour=0, minute=0, second=0, microsecond=0) + timedelta(days=self.retention_days + 1)
+        try:
+            pipe = self.cache.client.pipeline(transaction=True)
+            pipe.incr(key)
+            pipe.expireat(key, expires_at)
+            await pipe.execute()
+        except Exception as e:
+            logger.warning(f"Failed to count request for {symbol}: {e}")
+
+    async def counts(self, days: int) -> dict[str, int]:
+        """Requests per symbol over the last `days` days (today included)."""
+        if not self.cache.client:
+            raise RuntimeError("Redis client not connected")
+
+        today = datetime.now(timezone.utc)
+        wanted = {_day(today - timedelta(days=offset)) for offset in range(days)}
+        keys = []
+        async for key in self.cache.client.scan_iter(f"{KEY_PREFIX}*"):
+            day, _, symbol = key[len(KEY_PREFIX):].partition(":")
+            if day in wanted and symbol:
+                keys.append((symbol, key))
+
+        totals: dict[str, int] = {}
+        if keys:
+            pipe = self.cache.client.pipeline(transaction=False)
+            for _, key in keys:
+                pipe.get(key)
+            for (symbol, _), value in zip(keys, await pipe.execute()):
+                totals[symbol] = totals.get(symbol, 0) + int(value or 0)
+        return totals
+
+    async def ranking(self, days: int, limit: int) -> dict[str, Any]:
+        """Most requested symbols, plus tracked symbols nobody requested."""
+        totals = await self.counts(days)
+        tracked = set(await self.cache.list_symbols())
+        ranked = sorted(totals.items(), key=lambda item: (-item[1], item[0]))
+        return {
+            "days": days,
+            "total_requests": sum(totals.values()),
+            "symbols": [
+                {"symbol": symbol, "requests": count, "tracked": symbol in tracked}
+                for symbol, count in ranked[:limit]
+            ],
+            "unrequested": sorted(tracked - totals.keys()),
+        }
@@ -0,0 +1,82 @@
+"""
+Numeric precision of tool responses.
+
+Reports are published with canonical numbers at full precision: derived
+prices such as micro_price keep two decimals beyond the tick and flow
+quantities are sums of many trades. Quant consumers want that precision;
+LLM consumers pay for every digit in their context window. Tools take a
+precision argument:
+
+    full     numbers as published (default)
+    compact  prices rounded to the tick size (meta.price_precision) and
+             quantities to the lot size (meta.size_precision)
+
+Only fields known to hold prices or base-asset quantities are rounded;
+USD notionals, ratios, basis points and timings are left as they are, as
+is everything in a report whose precision isn't known yet (instruments
+not loaded, or a report older than schema 1.6).
+"""
+from typing import Any
+
+PRECISIONS = ("full", "compact")
+
+# Fields (or lists and grids of them) holding prices
+PRICE_KEYS = frozenset({
+    "price", "last_price", "high_24h", "low_24h", "price_start", "price_end",
+    "mid_price", "micro_price", "POC", "VAH", "VAL", "mids", "price_buckets",
+})
+
+# Fields (or lists and grids of them) holding base-asset quantities
+QUANTITY_KEYS = frozenset({
+    "qty", "size", "quantity", "visible_qty", "volume_24h", "sum_bid", "sum_ask",
+    "net_flow", "buy_volume", "sell_volume", "delta", "avg_trade_size", "cum_qty",
+    "bid_qty", "ask_qty",
+})
+
+PRECISION_PROPERTY = {
+    "type": "string",
+    "enum": list(PRECISIONS),
+    "description": (
+        "Number precision: full as published, or compact with prices rounded to the "
+        "tick size and quantities to the lot size, for smaller payloads (default full)"
+    ),
+}
+
+
+def validate(precision: Any) -> str | None:
+    """Error message for an invalid precision argument, or None."""
+    if precision not in PRECISIONS:
+        return f"precision must be one of {', '.join(PRECISIONS)}, got {precision!r}"
+    return None
+
+
+def apply(value: Any, precision: str, meta: dict[str, Any] | None) -> Any:
+    """Copy of a response at the requested precision.
+
+    Args:
+        value: Response (or report) to round
+        precision: "full" or "compact"
+        meta: Report meta section carrying price_precision and size_precision
+    """
+    if precision == "full":
+        return value
+    meta = meta or {}
+    digits = {
+        key: places
+        for keys, places in ((PRICE_KEYS, meta.get("price_precision")), (QUANTITY_KEYS, meta.get("size_precision")))
+        if isinstance(places, int)
+        for key in keys
+    }
+    if not digits:
+        return value
+    return _round(value, None, digits)
+
+
+def _round(value: Any, key: str | None, digits: dict[str, int]) -> Any:
+    if isinstance(value, dict):
+        return {k: _round(v, k, digits) for k, v in value.items()}
+    if isinstance(value, list):
+        return [_round(item, key, digits) for item in value]
+    if isinstance(value, float) and key in digits:
+        return round(value, digits[key])
+    return value
@@ -0,0 +1,283 @@
+"""
+Per-API-key request quotas.
+
+Daily and monthly request counts are tracked in Redis with atomic INCR
+counters that expire after their period:
+    quota:{key_id}:day:{YYYYMMDD}
+    quota:{key_id}:month:{YYYYMM}
+
+Limits come from QUOTA_DAILY / QUOTA_MONTHLY (0 = unlimited) and can be
+overridden per key with QUOTA_OVERRIDES="key:daily:monthly,key2:daily:monthly".
+
+Keys aren't authenticated anywhere else, so once any quota is configured
+only known keys are accepted: those in QUOTA_API_KEYS, QUOTA_OVERRIDES,
+ADMIN_API_KEYS and the tenant configuration (tenancy.py). Other keys get
+UNKNOWN_API_KEY. Requests without a key share one "anonymous" bucket
+limited by QUOTA_ANONYMOUS_DAILY / QUOTA_ANONYMOUS_MONTHLY (default: the
+QUOTA_DAILY / QUOTA_MONTHLY limits), so leaving out the header or making
+up keys doesn't escape the quotas.
+"""
+import hashlib
+import logging
+import os
+from dataclasses import dataclass
+from datetime import datetime, timedelta, timezone
+from typing import Any
+
+import errors
+from audit import mask_api_key
+from cache import RedisCache
+from errors import ErrorResponse
+from tenancy import TenantRegistry
+
+logger = logging.getLogger(__name__)
+
+# Quota bucket shared by requests without an API key
+ANONYMOUS = "anonymous"
+
+
+@dataclass(frozen=True)
+class QuotaLimits:
+    """Request limits for an API key (0 = unlimited)."""
+
+    daily: int = 0
+    monthly: int = 0
+
+
+@dataclass
+class QuotaStatus:
+    """Usage of an API key in the current day and month."""
+
+    api_key: str | None  # None for the anonymous bucket
+    daily_used: int
+    monthly_used: int
+    limits: QuotaLimits
+    daily_reset_at: datetime
+    monthly_reset_at: datetime
+
+    @property
+    def exceeded_period(self) -> str | None:
+        """The period whose limit is exceeded, if any."""
+        if self.limits.monthly and self.monthly_used > self.limits.monthly:
+            return "monthly"
+        if self.limits.daily and self.daily_used > self.limits.daily:
+            return "daily"
+        return None
+
+    @property
+    def reset_at(self) -> datetime:
+        """When the exceeded quota resets."""
+        if self.exceeded_period == "monthly":
+            return self.monthly_reset_at
+        return self.daily_reset_at
+
+    def to_dict(self) -> dict[str, Any]:
+        return {
+            "api_key": mask_api_key(self.api_key),
+            "daily": {
+                "used": self.daily_used,
+                "limit": self.limits.daily or None,
+                "reset_at": _isoformat(self.daily_reset_at),
+            },
+            "monthly": {
+                "used": self.monthly_used,
+                "limit": self.limits.monthly or None,
+                "reset_at": _isoformat(self.monthly_reset_at),
+            },
+        }
+
+
+def _isoformat(ts: datetime) -> str:
+    return ts.isoformat().replace("+00:00", "Z")
+
+
+def key_id(api_key: str) -> str:
+    """Stable identifier for an API key that avoids storing it in Redis."""
+    return hashlib.sha256(api_key.encode("utf-8")).hexdigest()[:16]
+
+
+def _env_keys(name: str) -> set[str]:
+    return {k.strip() for k in os.getenv(name, "").split(",") if k.strip()}
+
+
+class UnknownApiKey(Exception):
+    """An API key outside the configured key set while quotas are on."""
+
+
+def _parse_overrides(raw: str) -> dict[str, QuotaLimits]:
+    overrides = {}
+    for entry in filter(None, (e.strip() for e in raw.split(","))):
+        try:
+            api_key, daily, monthly = entry.rsplit(":", 2)
+            overrides[api_key] = QuotaLimits(daily=int(daily), monthly=int(monthly))
+        except ValueError:
+            logger.warning(f"Ignoring malformed QUOTA_OVERRIDES entry: {mask_api_key(entry)}")
+    return overrides
+
+
+class QuotaManager:
+    """Tracks and enforces per-key request quotas."""
+
+    def __init__(
+        self,
+        cache: RedisCache,
+        default_limits: QuotaLimits | None = None,
+        overrides: dict[str, QuotaLimits] | None = None,
+        anonymous_limits: QuotaLimits | None = None,
+        api_keys: set[str] | None = None,
+        tenants: TenantRegistry | None = None,
+    ):
+        """
+        Args:
+            anonymous_limits: Limits of the bucket shared by requests without a key
+            api_keys: Keys accepted besides those in overrides and tenants
+                (default QUOTA_API_KEYS and ADMIN_API_KEYS)
+            tenants: Registry whose tenants' keys are accepted too
+        """
+        self.cache = cache
+        if default_limits is None:
+            default_limits = QuotaLimits(
+                daily=int(os.getenv("QUOTA_DAILY", "0")),
+                monthly=int(os.getenv("QUOTA_MONTHLY", "0")),
+            )
+        if overrides is None:
+            overrides = _parse_overrides(os.getenv("QUOTA_OVERRIDES", ""))
+        if anonymous_limits is None:
+            anonymous_limits = QuotaLimits(
+                daily=int(os.getenv("QUOTA_ANONYMOUS_DAILY", str(default_limits.daily))),
+                monthly=int(os.getenv("QUOTA_ANONYMOUS_MONTHLY", str(default_limits.monthly))),
+            )
+        if api_keys is None:
+            api_keys = _env_keys("QUOTA_API_KEYS") | _env_keys("ADMIN_API_KEYS")
+        self.default_limits = default_limits
+        self.overrides = overrides
+        self.anonymous_limits = anonymous_limits
+        self.api_keys = api_keys
+        self.tenants = tenants
+
+    @property
+    def enabled(self) -> bool:
+        """Whether any quota is configured."""
+        return any(
+            limits.daily or limits.monthly
+            for limits in (self.default_limits, self.anonymous_limits, *self.overrides.values())
+        )
+
+    def limits_for(self, api_key: str) -> QuotaLimits:
+        if api_key == ANONYMOUS:
+            return self.anonymous_limits
+        return self.overrides.get(api_key, self.default_limits)
+
+    async def is_known(self, api_key: str) -> bool:
+        """Whether a key belongs to the configured key set."""
+        if api_key in self.api_keys or api_key in self.overrides:
+            return True
+        return self.tenants is not None and await self.tenants.tenant_for(api_key) is not None
+
+    async def consume(self, api_key: str | None, units: int = 1) -> QuotaStatus | None:
+        """
+        Count units (one per request by default) against the key's quota
+        (the anonymous bucket without a key).
+
+        Returns:
+            QuotaStatus after counting, or None when the request is not
+            subject to quotas (no limits configured for its bucket)
+
+        Raises:
+            UnknownApiKey: quotas are on and the key isn't a known one
+        """
+        if not self.enabled:
+            return None
+        if not api_key:
+            api_key = ANONYMOUS
+        elif not await self.is_known(api_key):
+            raise UnknownApiKey(f"API key {mask_api_key(api_key)} is not known to this deployment")
+        limits = self.limits_for(api_key)
+        if not limits.daily and not limits.monthly:
+            return None
+        return await self._read(api_key, increment=units)
+
+    async def check(self, api_key: str | None, units: int = 1) -> ErrorResponse | None:
+        """Count a request (or units of one) and return the error refusing it, if any.
+
+        Failures reading the counters are logged and let the request through.
+        """
+        try:
+            status = await self.consume(api_key, units)
+        except UnknownApiKey as e:
+            return ErrorResponse(errors.UNKNOWN_API_KEY, str(e))
+        except Exception as e:
+            logger.error(f"Failed to check quota: {e}", exc_info=True)
+            return None
+        if status and status.exceeded_period:
+            return quota_exceeded_error(status)
+        return None
+
+    async def refund(self, api_key: str | None, units: int = 1) -> None:
+        """Give back units counted by check() for a request rejected as invalid.
+
+        Failures are logged; the units then stay counted.
+        """
+        if not self.enabled:
+            return
+        if not api_key:
+            api_key = ANONYMOUS
+        limits = self.limits_for(api_key)
+        if not limits.daily and not limits.monthly:
+            return
+        try:
+            await self._read(api_key, increment=-units)
+        except Exception as e:
+            logger.error(f"Failed to refund quota: {e}", exc_info=True)
+
+    async def usage(self, api_key: str) -> QuotaStatus:
+        """Current usage of a key without counting a request."""
+        return await self._read(api_key, increment=0)
+
+    async def _read(self, api_key: str, increment: int) -> QuotaStatus:
+        if not self.cache.client:
+            raise RuntimeError("Redis client not connected")
+
+        now = datetime.now(timezone.utc)
+        day_start = now.replace(hour=0, minute=0, second=0, microsecond=0)
+        daily_reset_at = day_start + timedelta(days=1)
+        monthly_reset_at = (day_start.replace(day=1) + timedelta(days=32)).replace(day=1)
+
+        kid = key_id(api_key)
+        day_key = f"quota:{kid}:day:{now:%Y%m%d}"
+        month_key = f"quota:{kid}:month:{now:%Y%m}"
+
+        pipe = self.cache.client.pipeline(transaction=True)
+        if increment:
+            pipe.incrby(day_key, increment)
+            pipe.expireat(day_key, daily_reset_at + timedelta(hours=1))
+            pipe.incrby(month_key, increment)
+            pipe.expireat(month_key, monthly_reset_at + timedelta(hours=1))
+            daily_used, _, monthly_used, _ = await pipe.execute()
+        else:
+            pipe.get(day_key)
+            pipe.get(month_key)
+            daily_used, monthly_used = await pipe.execute()
+
+        return QuotaStatus(
+            api_key=None if api_key == ANONYMOUS else api_key,
+            daily_used=int(daily_used or 0),
+            monthly_used=int(monthly_used or 0),
+            limits=self.limits_for(api_key),
+            daily_reset_at=daily_reset_at,
+            monthly_reset_at=monthly_reset_at,
+        )
+
+
+def quota_exceeded_error(status: QuotaStatus) -> ErrorResponse:
+    """Build the QUOTA_EXCEEDED error for an exhausted quota."""
+    reset_at = status.reset_at
+    retry_after = max(1, int((reset_at - datetime.now(timezone.utc)).total_seconds()))
+    period = status.exceeded_period
+    return ErrorResponse(
+        errors.QUOTA_EXCEEDED,
+        f"{period.capitalize()} quota exceeded for API key {mask_api_key(status.api_key)}; "
+        f"resets at {_isoformat(reset_at)}",
+        retry_after=retry_after,
+        details={"period": period, "reset_at": _isoformat(reset_at), **status.to_dict()},
+    )
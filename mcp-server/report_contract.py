@@ -0,0 +1,136 @@
+"""
+Report contract check between the producer and the API schemas.
+
+report_sample.json is a fully populated producer report (fast cycle plus
+slow-cycle enrichment). The producer's contract tests assert that the
+reports it emits have exactly this shape; this module asserts that the
+same report unmarshals cleanly into what the servers expose:
+
+- every GraphQL Report field resolves to a key in the report
+- every report key is exposed through GraphQL (or listed as internal)
+- every OpenAPI MarketReport property exists in the report
+- schemaVersion has a supported major version
+- every metric catalog entry documents a field of the sample
+
+The REST server runs the check on startup and refuses to start on drift;
+`python cli.py check-contract` runs it against the sample, a file, or a
+live cached report.
+"""
+import json
+import os
+from typing import Any
+
+from graphql import GraphQLList, GraphQLNonNull, GraphQLObjectType
+
+import metric_catalog
+import report_versions
+from graphql_api import _report_key, schema
+from openapi import load_base_schema
+
+SAMPLE_FILE = os.path.join(os.path.dirname(os.path.abspath(__file__)), "report_sample.json")
+
+# Major schema versions this server can serve (those in the version registry)
+SUPPORTED_SCHEMA_MAJORS = tuple(sorted({v.split(".")[0] for v in report_versions.KNOWN_VERSIONS}))
+
+# Report keys intentionally not exposed through GraphQL
+INTERNAL_KEYS = {
+    "writer",  # Fencing metadata for producer instances
+    "slow_cycle_updated_at",
+    "invariant_violations",  # Only present with NT_INVARIANT_MODE=tag
+    "non_finite_fields",  # Only present when a NaN/Infinity was replaced with null
+}
+
+
+def _unwrap(graphql_type):
+    while isinstance(graphql_type, (GraphQLNonNull, GraphQLList)):
+        graphql_type = graphql_type.of_type
+    return graphql_type
+
+
+def _samples(value: Any) -> list[dict]:
+    """Dicts to check for a report value (each element of a list of objects)."""
+    if isinstance(value, list):
+        return [item for item in value if isinstance(item, dict)]
+    return [value] if isinstance(value, dict) else []
+
+
+def check_graphql(report: dict[str, Any]) -> list[str]:
+    """Compare a report against the GraphQL Report type in both directions."""
+    problems = []
+
+    def walk(object_type: GraphQLObjectType, values: list[dict], path: str) -> None:
+        if not values:
+            return
+
+        exposed = set()
+        for field_name, field in object_type.fields.items():
+            key = _report_key(field_name)
+            exposed.add(key)
+            present = [v[key] for v in values if key in v]
+
+            if field_name == "window" and object_type.name == "FlowWindow":
+                continue  # Synthesized from the flow.windows map key
+            if not present:
+                problems.append(f"{path}.{field_name}: GraphQL field has no report key '{key}'")
+                continue
+
+            field_type = _unwrap(field.type)
+            if isinstance(field_type, GraphQLObjectType):
+                nested = []
+                for value in present:
+                    if field_name == "windows" and isinstance(value, dict):
+                        nested.extend({"window": label, **m} for label, m in value.items())
+                    else:
+                        nested.extend(_samples(value))
+                walk(field_type, nested, f"{path}.{field_name}")
+
+        for key in sorted({k for v in values for k in v} - exposed):
+            if path == "Report" and key in INTERNAL_KEYS:
+                continue
+            problems.append(f"{path}: report key '{key}' is not exposed in GraphQL")
+
+    walk(schema.type_map["Report"], [report], "Report")
+    return problems
+
+
+def check_openapi(report: dict[str, Any]) -> list[str]:
+    """Check every OpenAPI MarketReport property exists in the report."""
+    spec = load_base_schema()
+    problems = []
+
+    def walk(node: dict, value: Any, path: str) -> None:
+        for name, prop in (node.get("properties") or {}).items():
+            if not isinstance(value, dict) or name not in value:
+                problems.append(f"{path}.{name}: OpenAPI property missing from report")
+            elif prop.get("type") == "object":
+                walk(prop, value[name], f"{path}.{name}")
+
+    walk(spec["components"]["schemas"]["MarketReport"], report, "MarketReport")
+    return problems
+
+
+def check_schema_version(report: dict[str, Any]) -> list[str]:
+    version = report.get("schemaVersion")
+    if not isinstance(version, str):
+        return [f"schemaVersion missing or not a string: {version!r}"]
+    if version.split(".")[0] not in SUPPORTED_SCHEMA_MAJORS:
+        return [f"schemaVersion {version} not supported (majors: {', '.join(SUPPORTED_SCHEMA_MAJORS)})"]
+    return []
+
+
+def check_report(report: dict[str, Any]) -> list[str]:
+    """Run all contract checks; returns a list of drift problems (empty when compatible)."""
+    return check_schema_version(report) + check_graphql(report) + check_openapi(report)
+
+
+def load_sample() -> dict[str, Any]:
+    with open(SAMPLE_FILE) as f:
+        return json.load(f)
+
+
+def verify_sample() -> None:
+    """Fail loudly if the bundled producer sample no longer fits the server schemas."""
+    sample = load_sample()
+    problems = check_report(sample) + metric_catalog.check_catalog(sample)
+    if problems:
+        raise RuntimeError("Report contract drift:\n  " + "\n  ".join(problems))
@@ -0,0 +1,258 @@
+"""
+Report schema version registry, migrations and changelog.
+
+The producer stamps every report with schemaVersion (major.minor). Each
+version is listed here with the report fields it added, removed or
+renamed, so clients can adapt programmatically (get_schema_changelog
+tool, /v1/changelog). Reports cached by an older producer are migrated
+to CURRENT_VERSION on read, so every transport serves one shape.
+
+Adding a version: append it to VERSIONS with its field changes and
+register a migration from the previous version in MIGRATIONS (additive
+versions only need to stamp the new version).
+"""
+from typing import Any, Callable
+
+CURRENT_VERSION = "1.8"
+
+# Oldest first. Field paths are dotted from the report root; [] marks list items
+VERSIONS: list[dict[str, Any]] = [
+    {
+        "version": "1.0",
+        "summary": "Initial market report (Go analytics service)",
+        "added": [],
+        "removed": [],
+        "renamed": {},
+    },
+    {
+        "version": "1.1",
+        "summary": "Embedded analytics in the producer; writer fencing metadata",
+        "added": ["writer", "writer.nodeId", "writer.writerToken", "updatedAt"],
+        "removed": [],
+        "renamed": {"report_version": "schemaVersion"},
+    },
+    {
+        "version": "1.2",
+        "summary": "Ingestion timeline, multi-window and USD flow, symbol meta, slow-cycle analytics, "
+                   "microstructure, trade tape and latency timings",
+        "added": [
+            "ingestion.since",
+            "ingestion.last_update",
+            "ingestion.uptime_pct_1h",
+            "ingestion.last_transitions",
+            "meta",
+            "volume_24h_usd",
+            "depth.sum_bid_usd",
+            "depth.sum_ask_usd",
+            "flow.net_flow_usd",
+            "flow.windows",
+            "liquidity.walls[].distance_bps",
+            "liquidity.walls[].distance_ticks",
+            "liquidity.walls[].in_value_area",
+            "liquidity.walls[].notional_usd",
+            "analytics",
+            "analytics.volume_profile",
+            "analytics.poc_trend",
+            "analytics.poc_drift_bps",
+            "analytics.developing_value_area",
+            "analytics.footprint",
+            "anomalies[].notional_usd",
+            "anomalies[].visible_qty",
+            "anomalies[].absorbed_volume",
+            "anomalies[].price_move_bps",
+            "anomalies[].updates_per_sec",
+            "anomalies[].baseline_updates_per_sec",
+            "anomalies[].churn_per_sec",
+            "anomalies[].synthetic",
+            "microstructure",
+            "recent_trades",
+            "timings",
+        ],
+        "removed": [],
+        "renamed": {},
+    },
+    {
+        "version": "1.3",
+        "summary": "Per-section provenance (returned with verbose=true)",
+        "added": [
+            "provenance",
+            "provenance[].section",
+            "provenance[].sources",
+            "provenance[].source_ts",
+            "provenance[].window_sec",
+            "provenance[].cycle",
+        ],
+        "removed": [],
+        "renamed": {},
+    },
+    {
+        "version": "1.4",
+        "summary": "Venue clock skew: skew-compensated venue lag timings and clock_skew anomalies",
+        "added": [
+            "timings.venue_lag_ms",
+            "timings.clock_skew_ms",
+            "anomalies[].skew_ms",
+        ],
+        "removed": [],
+        "renamed": {},
+    },
+    {
+        "version": "1.5",
+        "summary": "Venue trading status (halts, auctions, maintenance) with anomaly and health suppression",
+        "added": [
+            "market_status",
+            "market_status.state",
+            "market_status.since",
+            "market_status.reason",
+        ],
+        "removed": [],
+        "renamed": {},
+    },
+    {
+        "version": "1.6",
+        "summary": "Lot size precision in symbol meta (compact precision rounding)",
+        "added": ["meta.size_precision"],
+        "removed": [],
+        "renamed": {},
+    },
+    {
+        "version": "1.7",
+        "summary": "Cross-market relative strength (24h performance vs benchmark and universe median)",
+        "added": [
+            "relative_strength",
+            "relative_strength.window_sec",
+            "relative_strength.performance_pct",
+            "relative_strength.benchmark",
+            "relative_strength.benchmark_performance_pct",
+            "relative_strength.vs_benchmark_pct",
+            "relative_strength.universe_median_pct",
+            "relative_strength.vs_median_pct",
+            "relative_strength.rank",
+            "relative_strength.universe_size",
+            "relative_strength.updatedAt",
+        ],
+        "removed": [],
+        "renamed": {},
+    },
+    {
+        "version": "1.8",
+        "summary": "Spoofing flagged from short-lived large levels pulled without trading",
+        "added": ["anomalies[].lifetime_ms", "anomalies[].pulls"],
+        "removed": [],
+        "renamed": {},
+    },
+]
+
+# Debugging sections served only when a client asks for them (verbose=true)
+VERBOSE_KEYS = ("provenance",)
+
+
+def _migrate_1_0(report: dict[str, Any]) -> dict[str, Any]:
+    """1.0 -> 1.1: report_version (semver) became schemaVersion; writer is unknown."""
+    report.pop("report_version", None)
+    report.setdefault("writer", None)
+    report["schemaVersion"] = "1.1"
+    return report
+
+
+def _migrate_1_1(report: dict[str, Any]) -> dict[str, Any]:
+    """1.1 -> 1.2: additive; new sections stay absent."""
+    report["schemaVersion"] = "1.2"
+    return report
+
+
+def _migrate_1_2(report: dict[str, Any]) -> dict[str, Any]:
+    """1.2 -> 1.3: additive; provenance stays absent."""
+    report["schemaVersion"] = "1.3"
+    return report
+
+
+def _migrate_1_3(report: dict[str, Any]) -> dict[str, Any]:
+    """1.3 -> 1.4: additive; skew timings stay absent."""
+    report["schemaVersion"] = "1.4"
+    return report
+
+
+def _migrate_1_4(report: dict[str, Any]) -> dict[str, Any]:
+    """1.4 -> 1.5: additive; market_status stays absent (state unknown)."""
+    report["schemaVersion"] = "1.5"
+    return report
+
+
+def _migrate_1_5(report: dict[str, Any]) -> dict[str, Any]:
+    """1.5 -> 1.6: additive; size_precision stays absent."""
+    report["schemaVersion"] = "1.6"
+    return report
+
+
+def _migrate_1_6(report: dict[str, Any]) -> dict[str, Any]:
+    """1.6 -> 1.7: additive; relative_strength stays absent."""
+    report["schemaVersion"] = "1.7"
+    return report
+
+
+def _migrate_1_7(report: dict[str, Any]) -> dict[str, Any]:
+    """1.7 -> 1.8: additive; older spoofing anomalies carry no lifetime_ms or pulls."""
+    report["schemaVersion"] = "1.8"
+    return report
+
+
+# Version -> migration to the next version
+MIGRATIONS: dict[str, Callable[[dict[str, Any]], dict[str, Any]]] = {
+    "1.0": _migrate_1_0,
+    "1.1": _migrate_1_1,
+    "1.2": _migrate_1_2,
+    "1.3": _migrate_1_3,
+    "1.4": _migrate_1_4,
+    "1.5": _migrate_1_5,
+    "1.6": _migrate_1_6,
+    "1.7": _migrate_1_7,
+}
+
+KNOWN_VERSIONS = [entry["version"] for entry in VERSIONS]
+
+
+def report_version(report: dict[str, Any]) -> str | None:
+    """major.minor version of a report (1.0 reports carry a semver report_version)."""
+    version = report.get("schemaVersion") or report.get("report_version")
+    if not isinstance(version, str):
+        return None
+    return ".".join(version.split(".")[:2])
+
+
+def migrate(report: dict[str, Any]) -> dict[str, Any]:
+    """Upgrade a report in place to CURRENT_VERSION.
+
+    Reports of unknown or newer versions are returned unchanged (the
+    contract check reports unsupported majors).
+    """
+    version = report_version(report)
+    while version in MIGRATIONS:
+        report = MIGRATIONS[version](report)
+        version = report_version(report)
+    return report
+
+
+def strip_verbose(report: dict[str, Any]) -> dict[str, Any]:
+    """Copy of a report without the VERBOSE_KEYS sections."""
+    return {key: value for key, value in report.items() if key not in VERBOSE_KEYS}
+
+
+def _key(version: str) -> tuple[int, ...]:
+    return tuple(int(part) for part in version.split("."))
+
+
+def changelog(since: str | None = None) -> dict[str, Any]:
+    """Machine-readable changelog, optionally limited to versions newer than since.
+
+    Raises:
+        ValueError: since is not a major.minor version
+    """
+    entries = VERSIONS
+    if since:
+        try:
+            floor = _key(since)
+        except ValueError:
+            raise ValueError(f"Invalid version '{since}' (expected major.minor, e.g. 1.1)")
+        entries = [entry for entry in VERSIONS if _key(entry["version"]) > floor]
+    return {"current_version": CURRENT_VERSION, "versions": entries}
@@ -2,167 +2,358 @@
 Simple REST API server for ChatGPT Custom Actions.
 Provides get_report endpoint for market data.
 """
-import asyncio
-import json
+import hmac
 import logging
 import os
-from typing import Any
+import re
+import time
 
-import redis.asyncio as aioredis
 from starlette.applications import Starlette
-from starlette.responses import JSONResponse
-from starlette.routing import Route
+from starlette.responses import FileResponse, JSONResponse
+from starlette.routing import Route, WebSocketRoute
 from starlette.middleware.cors import CORSMiddleware
 import uvicorn
 
+import api_versions
+import completeness
+import config
+import errors
+import graphql_api
+import metric_catalog
+import metrics
+import openapi
+import pipeline
+import report_contract
+import report_versions
+import slo
+import watch
+from audit import APIKeyContextMiddleware, AuditLog, api_key_var
+from cache import CacheStatus, RedisCache
+from correlation import CorrelationIDMiddleware, current_correlation_id, install_log_filter
+from errors import ErrorResponse
+from metrics import MetricsServer
+from quota import QuotaManager
+from streaming import ReportBroadcaster, handle_sse, handle_websocket
+from tenancy import TenantRegistry
+from warmup import run_warmup
+
 # Configure logging
 logging.basicConfig(
     level=logging.INFO,
-    format='%(asctime)s - %(name)s - %(levelname)s - %(message)s'
+    format='%(asctime)s - %(name)s - %(levelname)s - [%(correlation_id)s] %(message)s'
 )
+install_log_filter()
 logger = logging.getLogger(__name__)
 
-
-class RedisCache:
-    """Redis cache reader for market reports."""
-
-    def __init__(self, redis_url: str):
-        """Initialize Redis connection."""
-        self.redis_url = redis_url
-        self.client: aioredis.Redis | None = None
-
-    async def connect(self):
-        """Connect to Redis."""
-        try:
-            self.client = await aioredis.from_url(
-                self.redis_url,
-                encoding="utf-8",
-                decode_responses=True
-            )
-            await self.client.ping()
-            logger.info(f"Connected to Redis at {self.redis_url}")
-        except Exception as e:
-            logger.error(f"Failed to connect to Redis: {e}")
-            raise
-
-    async def close(self):
-        """Close Redis connection."""
-        if self.client:
-            await self.client.aclose()
-            logger.info("Redis connection closed")
-
-    async def get_report(self, symbol: str) -> dict[str, Any] | None:
-        """
-        Fetch market report from Redis cache.
-
-        Args:
-            symbol: Trading symbol (e.g., BTCUSDT)
-
-        Returns:
-            Market report as dict or None if not found
-        """
-        if not self.client:
-            raise RuntimeError("Redis client not connected")
-
-        cache_key = f"report:{symbol}"
-
-        try:
-            json_str = await self.client.get(cache_key)
-            if json_str is None:
-                return None
-
-            report = json.loads(json_str)
-            return report
-
-        except json.JSONDecodeError as e:
-            logger.error(f"Failed to parse JSON for {symbol}: {e}")
-            raise
-        except Exception as e:
-            logger.error(f"Failed to get report for {symbol}: {e}")
-            raise
+DASHBOARD_FILE = os.path.join(os.path.dirname(os.path.abspath(__file__)), "dashboard.html")
 
 
 # Global cache instance
 cache: RedisCache | None = None
+audit_log: AuditLog | None = None
+quota: QuotaManager | None = None
+tenants: TenantRegistry | None = None
+broadcaster: ReportBroadcaster | None = None
+metrics_server: MetricsServer | None = None
+pipeline_probe: pipeline.PipelineProbe | None = None
 
 
 async def startup():
     """Startup event handler."""
-    global cache
+    global cache, audit_log, quota, tenants, broadcaster, metrics_server, pipeline_probe
+    # Refuse to serve if the producer report contract drifted from our schemas
+    report_contract.verify_sample()
     redis_url = os.getenv("REDIS_URL", "redis://localhost:6379")
     cache = RedisCache(redis_url)
     await cache.connect()
+    audit_log = AuditLog(cache)
+    tenants = TenantRegistry(cache)
+    quota = QuotaManager(cache, tenants=tenants)
+    pipeline_probe = pipeline.PipelineProbe(cache)
+    await tenants.load()
+    await run_warmup(cache)
+    broadcaster = ReportBroadcaster(cache)
+    await broadcaster.start()
+    metrics_server = MetricsServer()
+    metrics_server.start()
     logger.info("REST API server initialized")
 
 
 async def shutdown():
     """Shutdown event handler."""
     global cache
+    if metrics_server:
+        metrics_server.stop()
+    if broadcaster:
+        await broadcaster.stop()
     if cache:
         await cache.close()
     logger.info("REST API server shutdown")
 
 
+def error_response(
+    code: errors.ErrorCode,
+    message: str,
+    retry_after: int | None = None,
+    details: dict | None = None,
+    suggestion: str | None = None,
+) -> JSONResponse:
+    """Render an error using the shared error contract."""
+    error = ErrorResponse(code, message, retry_after=retry_after, details=details, suggestion=suggestion)
+
+    headers = {}
+    if error.effective_retry_after is not None:
+        headers["Retry-After"] = str(error.effective_retry_after)
+
+    return JSONResponse(error.to_dict(), status_code=error.http_status, headers=headers)
+
+
 async def health(request):
-    """Health check endpoint."""
+    """
+    Health check endpoint.
+    ---
+    operationId: getHealth
+    summary: Service health check
+    responses:
+      '200':
+        description: Service is running
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                status:
+                  type: string
+                  example: healthy
+                service:
+                  type: string
+                  example: context8-rest-api
+    """
     return JSONResponse({
         "status": "healthy",
         "service": "context8-rest-api"
     })
 
 
+async def ready(request):
+    """
+    Readiness check: Redis reachable and the producer not down.
+    ---
+    operationId: getReadiness
+    summary: Service readiness, including producer health
+    responses:
+      '200':
+        description: Ready to serve fresh reports
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                status:
+                  type: string
+                  example: ready
+                redis:
+                  type: boolean
+                pipeline:
+                  type: object
+                  nullable: true
+                  description: Producer probe (status ok, degraded, down or unknown; see README)
+      '503':
+        description: Redis unreachable or producer down
+    """
+    body, status = await pipeline.readiness(cache, pipeline_probe)
+    return JSONResponse(body, status_code=status)
+
+
 async def get_report(request):
     """
     Get market report for a symbol.
-
-    Query params:
-        symbol: Trading symbol (e.g., BTCUSDT)
+    ---
+    operationId: getMarketReport
+    summary: Get real-time market report for a symbol
+    description: Returns comprehensive market data including price, orderbook, liquidity, flow, and health metrics
+    parameters:
+      - name: symbol
+        in: query
+        required: true
+        schema:
+          type: string
+          pattern: '^[A-Z0-9]+USDT$'
+          example: BTCUSDT
+        description: Trading pair symbol (e.g., BTCUSDT, ETHUSDT)
+      - name: verbose
+        in: query
+        required: false
+        schema:
+          type: boolean
+          default: false
+        description: Include the provenance section (source events, timestamps and windows per section)
+      - name: min_completeness
+        in: query
+        required: false
+        schema:
+          type: number
+          minimum: 0
+          maximum: 1
+          default: 0
+        description: Return 503 REPORT_WARMING_UP instead of a report less complete than this
+    responses:
+      '200':
+        description: Market report retrieved successfully
+        headers:
+          X-Cache-Status:
+            description: Cache lookup result (hit or stale)
+            schema:
+              type: string
+          X-Report-Completeness:
+            description: Share of report sections populated, 0-1
+            schema:
+              type: number
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/MarketReport'
+      '400':
+        description: Invalid request parameters
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Error'
+      '403':
+        description: Symbol or venue not entitled for the API key
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Error'
+      '404':
+        description: Symbol not found
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Error'
+      '429':
+        description: Request quota exceeded
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Error'
+      '503':
+        description: Report below min_completeness (still warming up)
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Error'
     """
     symbol = request.query_params.get("symbol", "").upper()
-
+    verbose = request.query_params.get("verbose", "false").lower() in ("1", "true", "yes")
+    try:
+        min_completeness = float(request.query_params.get("min_completeness", "0"))
+    except ValueError:
+        min_completeness = -1.0
+    if not 0 <= min_completeness <= 1:
+        return error_response(
+            errors.INVALID_PARAMETER,
+            f"min_completeness must be a number 0-1, got {request.query_params.get('min_completeness')!r}",
+        )
+
+    start = time.perf_counter()
+    response, outcome = await _report_response(symbol, verbose, min_completeness)
+    latency_ms = (time.perf_counter() - start) * 1000
+    slo.record_latency(latency_ms)
+    await audit_log.record("rest:get_report", symbol, latency_ms, outcome.lower())
+
+    return response
+
+
+async def _report_response(
+    symbol: str, verbose: bool = False, min_completeness: float = 0
+) -> tuple[JSONResponse, str]:
+    """Build the get_report response, returning response and outcome."""
     if not symbol:
-        return JSONResponse({
-            "error": "Missing required parameter: symbol",
-            "error_code": "MISSING_PARAMETER"
-        }, status_code=400)
+        missing = errors.missing_parameter("symbol", "BTCUSDT")
+        return error_response(
+            missing.error, missing.message, suggestion=missing.suggestion
+        ), missing.code
 
     # Validate symbol pattern
-    import re
     if not re.match(r"^[A-Z0-9]+USDT$", symbol):
-        return JSONResponse({
-            "error": f"Invalid symbol format: {symbol}. Must match pattern: ^[A-Z0-9]+USDT$",
-            "error_code": "INVALID_SYMBOL"
-        }, status_code=400)
+        return error_response(
+            errors.INVALID_SYMBOL,
+            f"Invalid symbol format: {symbol}. Must match pattern: ^[A-Z0-9]+USDT$",
+        ), errors.INVALID_SYMBOL.code
+
+    # Charged only once the parameters are valid
+    quota_error = await quota.check(api_key_var.get())
+    if quota_error:
+        return error_response(
+            quota_error.error, quota_error.message, quota_error.retry_after, quota_error.details
+        ), quota_error.code
 
     try:
-        report = await cache.get_report(symbol)
-
-        if report is None:
-            return JSONResponse({
-                "error": f"Symbol '{symbol}' not found in cache",
-                "error_code": "SYMBOL_NOT_FOUND"
-            }, status_code=404)
-
-        return JSONResponse(report)
+        denied = await tenants.check_symbol(api_key_var.get(), symbol)
+        if denied:
+            return error_response(errors.NOT_ENTITLED, denied), errors.NOT_ENTITLED.code
+
+        result = await cache.get_report(symbol)
+        metrics.record_cache_lookup(result)
+        await cache.log_request(current_correlation_id(), "rest:get_report", symbol, result)
+
+        if result.status == CacheStatus.MISS:
+            return error_response(
+                errors.SYMBOL_NOT_FOUND, f"Symbol '{symbol}' not found in cache"
+            ), errors.SYMBOL_NOT_FOUND.code
+
+        denied = await tenants.check_report(api_key_var.get(), result.report)
+        if denied:
+            return error_response(errors.NOT_ENTITLED, denied), errors.NOT_ENTITLED.code
+
+        warming_up = completeness.warming_up_error(result.report, min_completeness)
+        if warming_up:
+            return error_response(
+                errors.REPORT_WARMING_UP, warming_up.message, details=warming_up.details
+            ), errors.REPORT_WARMING_UP.code
+
+        slo.record_report_served(result.report)
+        report = result.report if verbose else report_versions.strip_verbose(result.report)
+        report = {**report, "pipeline": await pipeline_probe.status()}
+        return JSONResponse(report, headers={
+            "X-Cache-Status": result.status.value,
+            "X-Report-Completeness": f"{completeness.completeness(result.report):g}",
+        }), result.status.value
 
     except Exception as e:
         logger.error(f"Failed to retrieve report for {symbol}: {e}", exc_info=True)
-        return JSONResponse({
-            "error": f"Failed to retrieve report: {str(e)}",
-            "error_code": "INTERNAL_ERROR"
-        }, status_code=500)
+        return error_response(
+            errors.INTERNAL_ERROR, f"Failed to retrieve report: {str(e)}"
+        ), errors.INTERNAL_ERROR.code
 
 
 async def list_symbols(request):
     """
     List available symbols.
+    ---
+    operationId: listSymbols
+    summary: List all available symbols
+    description: Returns a list of all tracked trading symbols
+    responses:
+      '200':
+        description: List of symbols
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                symbols:
+                  type: array
+                  items:
+                    type: string
+                  example: ["BTCUSDT", "ETHUSDT", "BNBUSDT"]
+                count:
+                  type: integer
+                  example: 3
     """
-    # Get all report:* keys from Redis
     try:
-        keys = []
-        async for key in cache.client.scan_iter("report:*"):
-            symbol = key.replace("report:", "")
-            keys.append(symbol)
+        keys = await tenants.visible_symbols(api_key_var.get(), await cache.list_symbols())
 
         return JSONResponse({
             "symbols": sorted(keys),
@@ -171,23 +362,444 @@ async def list_symbols(request):
 
     except Exception as e:
         logger.error(f"Failed to list symbols: {e}", exc_info=True)
-        return JSONResponse({
-            "error": f"Failed to list symbols: {str(e)}",
-            "error_code": "INTERNAL_ERROR"
-        }, status_code=500)
+        return error_response(
+            errors.INTERNAL_ERROR, f"Failed to list symbols: {str(e)}"
+        )
+
+
+async def graphql(request):
+    """
+    Query selected report fields for one or many symbols.
+
+    Accepts POST {"query", "variables", "operationName"} or GET ?query=.
+    """
+    start = time.perf_counter()
+    response, outcome = await graphql_api.handle_graphql(request, cache, tenants, quota)
+
+    latency_ms = (time.perf_counter() - start) * 1000
+    await audit_log.record("rest:graphql", None, latency_ms, outcome.lower())
+    return response
+
+
+async def ws_reports(websocket):
+    """
+    Stream report updates for ?symbols=... over WebSocket.
+    """
+    await handle_websocket(websocket, broadcaster, tenants)
+
+
+async def stream_reports(request):
+    """
+    Stream report updates as Server-Sent Events.
+    ---
+    operationId: streamReports
+    summary: Subscribe to live report updates (SSE)
+    description: >
+      Sends a `report` event for every report published for the subscribed symbols. Event ids
+      are resumable cursors; reconnect with Last-Event-ID to replay missed updates from a
+      per-symbol buffer. A `gap` event lists symbols whose missed updates were no longer
+      buffered; refetch them from /v1/report.
+    parameters:
+      - name: symbols
+        in: query
+        required: true
+        schema:
+          type: string
+          example: BTCUSDT,ETHUSDT
+        description: Comma-separated symbols
+      - name: Last-Event-ID
+        in: header
+        required: false
+        schema:
+          type: string
+        description: Id of the last event received (sent automatically by EventSource on reconnect)
+      - name: last_event_id
+        in: query
+        required: false
+        schema:
+          type: string
+        description: Same as Last-Event-ID, for clients that cannot set headers
+    responses:
+      '200':
+        description: Event stream
+        content:
+          text/event-stream:
+            schema:
+              type: string
+    """
+    return await handle_sse(request, broadcaster, tenants)
+
+
+async def stream_watch(request):
+    """
+    Stream meaningful report changes as Server-Sent Events.
+    ---
+    operationId: streamWatch
+    summary: Watch symbols for report changes (SSE)
+    description: >
+      Sends a `change` event only when a report moves past one of the criteria, compared with
+      the report in effect when the stream opened (or at the last change of that field), instead
+      of every report. Each event is {"symbol", "updatedAt", "changes": [{"field", "from", "to",
+      ...}]}; a new anomaly is {"field": "anomalies", "anomaly": {...}}. Numeric criteria of 0
+      turn a check off. Threshold rules (price_cross, imbalance_flip, health_below) are checked by
+      the producer on every report while the stream is open; their changes carry the rule and a
+      direction.
+    parameters:
+      - name: symbols
+        in: query
+        required: true
+        schema:
+          type: string
+          example: BTCUSDT,ETHUSDT
+        description: Comma-separated symbols (at most 20)
+      - name: spread_change_pct
+        in: query
+        required: false
+        schema:
+          type: number
+          minimum: 0
+          default: 20
+        description: Relative change of spread_bps in percent
+      - name: mid_change_bps
+        in: query
+        required: false
+        schema:
+          type: number
+          minimum: 0
+        description: Move of mid_price in basis points (off by default)
+      - name: health_change
+        in: query
+        required: false
+        schema:
+          type: number
+          minimum: 0
+        description: Move of health.score in points (off by default)
+      - name: imbalance_change
+        in: query
+        required: false
+        schema:
+          type: number
+          minimum: 0
+        description: Absolute move of depth.imbalance (off by default)
+      - name: new_anomaly
+        in: query
+        required: false
+        schema:
+          type: boolean
+          default: true
+        description: Report anomaly types/sides not in the previous report
+      - name: status_change
+        in: query
+        required: false
+        schema:
+          type: boolean
+          default: true
+        description: Report ingestion status changes
+      - name: price_cross
+        in: query
+        required: false
+        schema:
+          type: string
+          example: 50000,52000
+        description: Comma-separated mid price levels; the producer reports each crossing
+      - name: imbalance_flip
+        in: query
+        required: false
+        schema:
+          type: string
+          example: 'true'
+        description: >
+          true, or a min_abs (default 0.1), to have the producer report depth.imbalance changing
+          sign; values within min_abs of zero keep their sign
+      - name: health_below
+        in: query
+        required: false
+        schema:
+          type: number
+          minimum: 0
+          maximum: 100
+        description: The producer reports health.score dropping below this
+    responses:
+      '200':
+        description: Event stream
+        content:
+          text/event-stream:
+            schema:
+              type: string
+      '400':
+        description: Invalid criteria
+    """
+    try:
+        criteria = watch.criteria_from_query(request.query_params)
+        rules = watch.rules_from_query(request.query_params)
+    except ValueError as e:
+        return error_response(errors.INVALID_PARAMETER, str(e))
+    if not criteria.active() and not rules:
+        return error_response(errors.INVALID_PARAMETER, "criteria turn off every change and there are no rules")
+    return await watch.handle_watch_sse(request, broadcaster, tenants, cache, criteria, rules)
+
+
+async def dashboard(request):
+    """
+    Operator status page showing tracked symbols, freshness, health and anomalies.
+    """
+    return FileResponse(DASHBOARD_FILE, media_type="text/html")
+
+
+def require_admin(request) -> JSONResponse | None:
+    """Reject requests without the ADMIN_TOKEN bearer token."""
+    admin_token = os.getenv("ADMIN_TOKEN", "")
+    authorization = request.headers.get("Authorization", "")
+    if not admin_token or not hmac.compare_digest(authorization.encode(), f"Bearer {admin_token}".encode()):
+        return error_response(
+            errors.UNAUTHORIZED, "Admin endpoints require a valid ADMIN_TOKEN bearer token"
+        )
+    return None
+
+
+async def admin_audit(request):
+    """
+    Query recent tool invocations from the audit log.
+
+    Query params:
+        count: Number of entries to scan (default 100, max 1000)
+        tool, symbol, api_key: Optional filters
+    """
+    denied = require_admin(request)
+    if denied:
+        return denied
+
+    try:
+        count = min(int(request.query_params.get("count", "100")), 1000)
+    except ValueError:
+        return error_response(errors.INVALID_PARAMETER, "count must be an integer")
+
+    try:
+        records = await audit_log.recent(
+            count=count,
+            tool=request.query_params.get("tool"),
+            symbol=request.query_params.get("symbol", "").upper() or None,
+            api_key=request.query_params.get("api_key"),
+        )
+        return JSONResponse({"invocations": records, "count": len(records)})
+
+    except Exception as e:
+        logger.error(f"Failed to read audit log: {e}", exc_info=True)
+        return error_response(errors.INTERNAL_ERROR, f"Failed to read audit log: {str(e)}")
+
+
+async def admin_usage(request):
+    """
+    Show quota usage for an API key.
+
+    Query params:
+        api_key: API key to inspect
+    """
+    denied = require_admin(request)
+    if denied:
+        return denied
+
+    api_key = request.query_params.get("api_key")
+    if not api_key:
+        missing = errors.missing_parameter("api_key")
+        return error_response(missing.error, missing.message, suggestion=missing.suggestion)
+
+    try:
+        status = await quota.usage(api_key)
+        return JSONResponse(status.to_dict())
+
+    except Exception as e:
+        logger.error(f"Failed to read usage: {e}", exc_info=True)
+        return error_response(errors.INTERNAL_ERROR, f"Failed to read usage: {str(e)}")
+
+
+async def list_errors(request):
+    """
+    List error codes returned by the API.
+    ---
+    operationId: listErrorCodes
+    summary: List error codes
+    description: Returns the machine-readable catalog of error codes returned by this API
+    responses:
+      '200':
+        description: Error code catalog
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                fields:
+                  type: array
+                  items:
+                    type: string
+                codes:
+                  type: array
+                  items:
+                    type: object
+                    properties:
+                      code:
+                        type: string
+                      http_status:
+                        type: integer
+                      description:
+                        type: string
+                      suggestion:
+                        type: string
+                      retry_after:
+                        type: integer
+                        nullable: true
+    """
+    return JSONResponse(errors.error_catalog())
+
+
+async def schema_changelog(request):
+    """
+    Machine-readable changelog of report fields by schema version.
+    ---
+    operationId: getSchemaChangelog
+    summary: Get report schema changelog
+    description: Returns report fields added, removed and renamed in each schema version
+    parameters:
+      - name: since
+        in: query
+        required: false
+        description: Only versions newer than this major.minor version (e.g. 1.1)
+        schema:
+          type: string
+    responses:
+      '200':
+        description: Schema changelog
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                current_version:
+                  type: string
+                versions:
+                  type: array
+                  items:
+                    type: object
+                    properties:
+                      version:
+                        type: string
+                      summary:
+                        type: string
+                      added:
+                        type: array
+                        items:
+                          type: string
+                      removed:
+                        type: array
+                        items:
+                          type: string
+                      renamed:
+                        type: object
+                        additionalProperties:
+                          type: string
+      '400':
+        description: Invalid version
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Error'
+    """
+    try:
+        return JSONResponse(report_versions.changelog(request.query_params.get("since")))
+    except ValueError as e:
+        return error_response(errors.INVALID_PARAMETER, str(e))
+
+
+async def metrics_catalog(request):
+    """
+    Catalog of report fields with types, versions and definitions.
+    ---
+    operationId: getMetricsCatalog
+    summary: Get the metric catalog
+    description: >
+      Returns every report field with its JSON type and the schema version that added it,
+      plus the description, formula, window, units and caveats of documented fields
+    responses:
+      '200':
+        description: Metric catalog
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                schema_version:
+                  type: string
+                documented:
+                  type: integer
+                fields:
+                  type: array
+                  items:
+                    type: object
+                    properties:
+                      field:
+                        type: string
+                      type:
+                        type: string
+                      since:
+                        type: string
+                      description:
+                        type: string
+                      formula:
+                        type: string
+                      window:
+                        type: string
+                      units:
+                        type: string
+                      caveats:
+                        type: array
+                        items:
+                          type: string
+    """
+    return JSONResponse(metric_catalog.catalog())
+
+
+async def slo_status(request):
+    """
+    Summarize SLO compliance, error budget and burn rate per window.
+    """
+    return JSONResponse(slo.summary())
+
+
+async def openapi_schema(request):
+    """
+    Serve the OpenAPI document generated from the route handlers.
+    """
+    return JSONResponse(openapi.generate(app.routes))
 
 
 # Create Starlette app
 app = Starlette(
     routes=[
         Route("/health", health, methods=["GET"]),
-        Route("/api/report", get_report, methods=["GET"]),
-        Route("/api/symbols", list_symbols, methods=["GET"]),
+        Route("/ready", ready, methods=["GET"]),
+        # Versioned public API; the pre-versioning /api/... paths are deprecated aliases
+        *api_versions.versioned_routes("/report", get_report, ["GET"], legacy=("/api/report",)),
+        *api_versions.versioned_routes("/symbols", list_symbols, ["GET"], legacy=("/api/symbols",)),
+        *api_versions.versioned_routes("/errors", list_errors, ["GET"], legacy=("/api/errors",)),
+        *api_versions.versioned_routes("/changelog", schema_changelog, ["GET"], legacy=("/api/changelog",)),
+        *api_versions.versioned_routes("/metrics_catalog", metrics_catalog, ["GET"]),
+        *api_versions.versioned_routes("/stream/reports", stream_reports, ["GET"]),
+        *api_versions.versioned_routes("/stream/watch", stream_watch, ["GET"]),
+        Route("/slo", slo_status, methods=["GET"]),
+        Route("/graphql", graphql, methods=["GET", "POST"]),
+        WebSocketRoute("/ws/reports", ws_reports),
+        Route("/dashboard", dashboard, methods=["GET"]),
+        Route("/admin/audit", admin_audit, methods=["GET"]),
+        Route("/admin/usage", admin_usage, methods=["GET"]),
+        Route("/openapi.json", openapi_schema, methods=["GET"], include_in_schema=False),
     ],
     on_startup=[startup],
     on_shutdown=[shutdown]
 )
 
+app.add_middleware(APIKeyContextMiddleware)
+app.add_middleware(CorrelationIDMiddleware)
+
 # Add CORS middleware for ChatGPT
 app.add_middleware(
     CORSMiddleware,
@@ -199,5 +811,6 @@ app.add_middleware(
 
 
 if __name__ == "__main__":
+    config.load()
     port = int(os.getenv("PORT", "8080"))
     uvicorn.run(app, host="0.0.0.0", port=port, log_level="info")
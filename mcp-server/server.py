@@ -8,99 +8,58 @@ import logging
 import os
 from typing import Any
 
-import redis.asyncio as aioredis
-from mcp.server import Server
+from mcp.server import NotificationOptions, Server
+from mcp.server.lowlevel.helper_types import ReadResourceContents
 from mcp.server.stdio import stdio_server
-from mcp.types import Tool, TextContent
+from mcp.types import CallToolResult, Resource, Tool, TextContent
+from pydantic import AnyUrl
+
+import config
+import metric_catalog
+from audit import api_key_var
+from cache import RedisCache
+from correlation import install_log_filter, request_correlation_id
+from jsonrpc_strict import strict_streams
+from metrics import MetricsServer
+from sessions import SessionManager
+from tools import ToolExecutor, tool_definitions
+from warmup import run_warmup
 
 # Configure logging
 logging.basicConfig(
     level=logging.INFO,
-    format='%(asctime)s - %(name)s - %(levelname)s - %(message)s'
+    format='%(asctime)s - %(name)s - %(levelname)s - [%(correlation_id)s] %(message)s'
 )
+install_log_filter()
 logger = logging.getLogger(__name__)
 
 
-class RedisCache:
-    """Redis cache reader for market reports."""
-
-    def __init__(self, redis_url: str):
-        """Initialize Redis connection."""
-        self.redis_url = redis_url
-        self.client: aioredis.Redis | None = None
-
-    async def connect(self):
-        """Connect to Redis."""
-        try:
-            self.client = await aioredis.from_url(
-                self.redis_url,
-                encoding="utf-8",
-                decode_responses=True
-            )
-            # Test connection
-            await self.client.ping()
-            logger.info(f"Connected to Redis at {self.redis_url}")
-        except Exception as e:
-            logger.error(f"Failed to connect to Redis: {e}")
-            raise
-
-    async def close(self):
-        """Close Redis connection."""
-        if self.client:
-            await self.client.aclose()
-            logger.info("Redis connection closed")
-
-    async def get_report(self, symbol: str) -> dict[str, Any] | None:
-        """
-        Fetch market report from Redis cache.
-
-        Args:
-            symbol: Trading symbol (e.g., BTCUSDT)
-
-        Returns:
-            Market report as dict or None if not found
-        """
-        if not self.client:
-            raise RuntimeError("Redis client not connected")
-
-        cache_key = f"report:{symbol}"
-
-        try:
-            # Get from Redis
-            json_str = await self.client.get(cache_key)
-
-            if json_str is None:
-                logger.debug(f"Symbol {symbol} not found in cache")
-                return None
-
-            # Parse JSON
-            report = json.loads(json_str)
-            logger.debug(f"Retrieved report for {symbol}")
-            return report
-
-        except json.JSONDecodeError as e:
-            logger.error(f"Failed to parse JSON for {symbol}: {e}")
-            raise
-        except Exception as e:
-            logger.error(f"Failed to get report for {symbol}: {e}")
-            raise
-
-
 class Context8MCPServer:
     """MCP Server for Context8 market data."""
 
     def __init__(self, redis_url: str):
         """Initialize MCP server."""
         self.cache = RedisCache(redis_url)
+        self.executor = ToolExecutor(self.cache)
+        self.metrics_server = MetricsServer()
+        self.sessions = SessionManager()
         self.server = Server("context8-mcp")
+        self._expiry_task: asyncio.Task | None = None
 
     async def initialize(self):
         """Initialize server and connect to Redis."""
         await self.cache.connect()
+        await self.executor.tenants.load()
+        await run_warmup(self.cache)
+        self.metrics_server.start()
+        self._expiry_task = asyncio.create_task(self.sessions.run_expiry())
         logger.info("Context8 MCP Server initialized")
 
     async def shutdown(self):
         """Shutdown server and close connections."""
+        if self._expiry_task:
+            self._expiry_task.cancel()
+        self.metrics_server.stop()
         await self.cache.close()
         logger.info("Context8 MCP Server shutdown")
 
@@ -110,108 +69,71 @@ class Context8MCPServer:
         @self.server.list_tools()
         async def list_tools() -> list[Tool]:
             """List available tools."""
-            return [
-                Tool(
-                    name="get_report",
-                    description=(
-                        "Retrieve real-time market data report for a tracked symbol "
-                        "including orderbook metrics, volume profile, and flow analysis"
-                    ),
-                    inputSchema={
-                        "type": "object",
-                        "properties": {
-                            "symbol": {
-                                "type": "string",
-                                "description": (
-                                    "Trading symbol (e.g., BTCUSDT, ETHUSDT, "
-                                    "1INCHUSDT, 1000SHIBUSDT)"
-                                ),
-                                "pattern": "^[A-Z0-9]+USDT$",
-                            }
-                        },
-                        "required": ["symbol"],
-                    }
-                )
-            ]
+            self.sessions.touch(self.server.request_context, "stdio")
+            return tool_definitions()
 
         @self.server.call_tool()
-        async def call_tool(name: str, arguments: dict) -> list[TextContent]:
+        async def call_tool(
+            name: str, arguments: dict
+        ) -> list[TextContent] | tuple[list[TextContent], dict[str, Any]] | CallToolResult:
             """Call a tool."""
-            if name != "get_report":
-                error_msg = f"Tool '{name}' not found. Available tools: get_report"
-                logger.warning(error_msg)
-                return [TextContent(
-                    type="text",
-                    text=json.dumps({
-                        "error": error_msg,
-                        "error_code": "TOOL_NOT_FOUND"
-                    }, indent=2)
-                )]
-
-            # Get symbol from arguments
-            symbol = arguments.get("symbol")
-            if not symbol:
-                error_msg = "Missing required parameter: symbol"
-                logger.warning(error_msg)
-                return [TextContent(
-                    type="text",
-                    text=json.dumps({
-                        "error": error_msg,
-                        "error_code": "MISSING_PARAMETER"
-                    }, indent=2)
-                )]
-
-            # Validate symbol pattern
-            import re
-            if not re.match(r"^[A-Z0-9]+USDT$", symbol):
-                error_msg = f"Invalid symbol format: {symbol}. Must match pattern: ^[A-Z0-9]+USDT$"
-                logger.warning(error_msg)
-                return [TextContent(
-                    type="text",
-                    text=json.dumps({
-                        "error": error_msg,
-                        "error_code": "INVALID_SYMBOL"
-                    }, indent=2)
-                )]
-
-            # Get report from cache
-            try:
-                report = await self.cache.get_report(symbol)
-
-                if report is None:
-                    error_msg = f"Symbol '{symbol}' not found in cache"
-                    logger.info(error_msg)
-                    return [TextContent(
-                        type="text",
-                        text=json.dumps({
-                            "error": error_msg,
-                            "error_code": "SYMBOL_NOT_FOUND"
-                        }, indent=2)
-                    )]
-
-                # Return report as formatted JSON
-                return [TextContent(
-                    type="text",
-                    text=json.dumps(report, indent=2)
-                )]
-
-            except Exception as e:
-                error_msg = f"Failed to retrieve report: {str(e)}"
-                logger.error(error_msg, exc_info=True)
-                return [TextContent(
-                    type="text",
-                    text=json.dumps({
-                        "error": error_msg,
-                        "error_code": "INTERNAL_ERROR"
-                    }, indent=2)
-                )]
-
-
-async def main():
-    """Main entry point for MCP server."""
+            self.sessions.touch(self.server.request_context, "stdio")
+            return await self.executor.call(
+                name, arguments, correlation_id=request_correlation_id(self.server.request_context)
+            )
+
+        @self.server.list_resources()
+        async def list_resources() -> list[Resource]:
+            """List available resources."""
+            self.sessions.touch(self.server.request_context, "stdio")
+            return [Resource(
+                uri=AnyUrl(metric_catalog.CATALOG_URI),
+                name="metric_catalog",
+                description=(
+                    "Every report field with its type, the schema version that added it "
+                    "and its definition (formula, window, units, caveats)"
+                ),
+                mimeType="application/json",
+            )]
+
+        @self.server.read_resource()
+        async def read_resource(uri: AnyUrl) -> list[ReadResourceContents]:
+            """Read a resource."""
+            self.sessions.touch(self.server.request_context, "stdio")
+            if str(uri) != metric_catalog.CATALOG_URI:
+                raise ValueError(f"Unknown resource: {uri}")
+            return [ReadResourceContents(
+                content=json.dumps(metric_catalog.catalog()), mime_type="application/json"
+            )]
+
+        @self.server.subscribe_resource()
+        async def subscribe_resource(uri: AnyUrl) -> None:
+            """Record a resource subscription on the session."""
+            self.sessions.subscribe(self.server.request_context, "stdio", str(uri))
+
+        @self.server.unsubscribe_resource()
+        async def unsubscribe_resource(uri: AnyUrl) -> None:
+            self.sessions.unsubscribe(self.server.request_context, "stdio", str(uri))
+
+        self.sessions.server_capabilities = self.server.get_capabilities(
+            NotificationOptions(), {}
+        ).model_dump(exclude_none=True)
+
+
+async def main(stdout=None):
+    """Main entry point for MCP server.
+
+    Args:
+        stdout: Async text stream for MCP responses (default: process stdout)
+    """
+    config.load()
+
     # Get Redis URL from environment
     redis_url = os.getenv("REDIS_URL", "redis://localhost:6379")
 
+    # stdio has no request headers; identify the client by MCP_API_KEY
+    api_key_var.set(os.getenv("MCP_API_KEY") or None)
+
     # Create and initialize server
     mcp_server = Context8MCPServer(redis_url)
     await mcp_server.initialize()
@@ -219,8 +141,12 @@ async def main():
     # Register handlers
     mcp_server.register_handlers()
 
-    # Run server with stdio transport
-    async with stdio_server() as (read_stream, write_stream):
+    # Run server with stdio transport; the process serves a single session
+    session = mcp_server.sessions.open("stdio")
+    async with (
+        stdio_server(stdout=stdout) as (transport_read, transport_write),
+        strict_streams(transport_read, transport_write) as (read_stream, write_stream),
+    ):
         logger.info("Context8 MCP Server started on stdio")
         try:
             await mcp_server.server.run(
@@ -229,6 +155,7 @@ async def main():
                 mcp_server.server.create_initialization_options()
             )
         finally:
+            mcp_server.sessions.close(session.id)
             await mcp_server.shutdown()
 
 
@@ -0,0 +1,160 @@
+"""
+MCP session tracking.
+
+A session is one MCP connection: the stdio process, or one SSE stream.
+The server opens a session when a connection starts and closes it when
+the connection ends; MCP handlers record what the client sent in
+initialize (client name and version, negotiated protocol version,
+capabilities) and its resource subscriptions. Sessions idle for longer
+than MCP_SESSION_IDLE_SEC are expired (clients that went away without
+closing the stream) and reopened if the client comes back.
+
+Active sessions are exported as mcp_sessions_active{transport}, and the
+SSE server lists them at /admin/sessions.
+"""
+import asyncio
+import logging
+import os
+import time
+import uuid
+from contextvars import ContextVar
+from dataclasses import dataclass, field
+from typing import Any
+
+import metrics
+
+logger = logging.getLogger(__name__)
+
+DEFAULT_IDLE_SEC = 1800
+
+# Session of the connection handling the current request
+session_id_var: ContextVar[str | None] = ContextVar("mcp_session_id", default=None)
+
+
+@dataclass
+class Session:
+    """One MCP connection and what its client negotiated."""
+
+    id: str
+    transport: str
+    created_at: float
+    last_seen: float
+    client_name: str | None = None
+    client_version: str | None = None
+    protocol_version: str | None = None
+    client_capabilities: dict[str, Any] = field(default_factory=dict)
+    subscriptions: set[str] = field(default_factory=set)
+    request_count: int = 0
+
+    def to_dict(self, now: float) -> dict[str, Any]:
+        return {
+            "id": self.id,
+            "transport": self.transport,
+            "client": {"name": self.client_name, "version": self.client_version},
+            "protocol_version": self.protocol_version,
+            "client_capabilities": self.client_capabilities,
+            "subscriptions": sorted(self.subscriptions),
+            "request_count": self.request_count,
+            "created_at": int(self.created_at * 1000),
+            "idle_sec": round(now - self.last_seen, 1),
+        }
+
+
+class SessionManager:
+    """Registry of open MCP sessions with idle expiry."""
+
+    def __init__(self, idle_timeout_sec: float | None = None):
+        if idle_timeout_sec is None:
+            idle_timeout_sec = float(os.getenv("MCP_SESSION_IDLE_SEC", str(DEFAULT_IDLE_SEC)))
+        self.idle_timeout_sec = idle_timeout_sec
+        self.sessions: dict[str, Session] = {}
+        # Advertised to clients in initialize; set by the server once handlers are registered
+        self.server_capabilities: dict[str, Any] = {}
+
+    def open(self, transport: str) -> Session:
+        """Start a session for the current connection (sets session_id_var)."""
+        now = time.time()
+        session = Session(id=uuid.uuid4().hex, transport=transport, created_at=now, last_seen=now)
+        self.sessions[session.id] = session
+        session_id_var.set(session.id)
+        metrics.sessions_active.labels(transport=transport).inc()
+        logger.info(f"MCP session opened id={session.id} transport={transport}")
+        return session
+
+    def close(self, session_id: str, reason: str = "closed") -> None:
+        session = self.sessions.pop(session_id, None)
+        if session is None:
+            return
+        metrics.sessions_active.labels(transport=session.transport).dec()
+        metrics.sessions_closed.labels(transport=session.transport, reason=reason).inc()
+        logger.info(
+            f"MCP session {reason} id={session.id} client={session.client_name} "
+            f"requests={session.request_count}"
+        )
+
+    def touch(self, request_context: Any, transport: str) -> Session | None:
+        """Record a request on the current session, capturing initialize parameters once known."""
+        session_id = session_id_var.get()
+        if session_id is None:
+            return None
+        session = self.sessions.get(session_id)
+        if session is None:
+            # Expired while idle; the connection is still open
+            now = time.time()
+            session = self.sessions[session_id] = Session(
+                id=session_id, transport=transport, created_at=now, last_seen=now
+            )
+            metrics.sessions_active.labels(transport=transport).inc()
+
+        session.last_seen = time.time()
+        session.request_count += 1
+        if session.protocol_version is None:
+            params = getattr(getattr(request_context, "session", None), "client_params", None)
+            if params is not None:
+                session.protocol_version = str(params.protocolVersion)
+                session.client_name = params.clientInfo.name
+                session.client_version = params.clientInfo.version
+                session.client_capabilities = params.capabilities.model_dump(exclude_none=True)
+        return session
+
+    def subscribe(self, request_context: Any, transport: str, uri: str) -> None:
+        session = self.touch(request_context, transport)
+        if session:
+            session.subscriptions.add(uri)
+
+    def unsubscribe(self, request_context: Any, transport: str, uri: str) -> None:
+        session = self.touch(request_context, transport)
+        if session:
+            session.subscriptions.discard(uri)
+
+    def expire(self) -> list[str]:
+        """Close sessions idle for longer than the timeout; returns their ids."""
+        cutoff = time.time() - self.idle_timeout_sec
+        expired = [s.id for s in self.sessions.values() if s.last_seen < cutoff]
+        for session_id in expired:
+            self.close(session_id, reason="expired")
+        return expired
+
+    async def run_expiry(self, interval_sec: float = 60) -> None:
+        """Expire idle sessions periodically (run as a background task)."""
+        while True:
+            await asyncio.sleep(interval_sec)
+            self.expire()
+
+    def count(self, transport: str) -> int:
+        """Open sessions of a transport."""
+        return sum(1 for session in self.sessions.values() if session.transport == transport)
+
+    def summary(self) -> dict[str, Any]:
+        """Sessions with counts per transport, for the admin listing."""
+        now = time.time()
+        counts: dict[str, int] = {}
+        for session in self.sessions.values():
+            counts[session.transport] = counts.get(session.transport, 0) + 1
+        return {
+            "count": len(self.sessions),
+            "by_transport": counts,
+            "idle_timeout_sec": self.idle_timeout_sec,
+            "server_capabilities": self.server_capabilities,
+            "sessions": [s.to_dict(now) for s in sorted(self.sessions.values(), key=lambda s: s.created_at)],
+        }
@@ -0,0 +1,148 @@
+"""
+Service level indicators for served reports.
+
+Two SLIs are tracked per process:
+    freshness - served reports with data_age_ms <= SLO_FRESHNESS_MS (default 1000)
+    latency   - tool calls and REST report requests answered within
+                SLO_LATENCY_MS (default 150)
+
+Every event is counted in `mcp_slo_events_total{slo,result}` (result is
+good or bad) for burn-rate alerting in Prometheus, and kept in per-minute
+buckets so /slo can summarize compliance over the standard windows.
+"""
+import os
+import time
+from collections import deque
+from dataclasses import dataclass
+from typing import Any
+
+from prometheus_client import Counter
+
+slo_events = Counter(
+    "mcp_slo_events_total",
+    "SLI events by SLO and result (good, bad)",
+    ["slo", "result"],
+)
+
+# Compliance windows reported by /slo
+WINDOWS = {
+    "5m": 5 * 60,
+    "1h": 60 * 60,
+    "6h": 6 * 60 * 60,
+    "1d": 24 * 60 * 60,
+    "30d": 30 * 24 * 60 * 60,
+}
+
+BUCKET_SEC = 60
+MAX_BUCKETS = WINDOWS["30d"] // BUCKET_SEC
+
+
+@dataclass
+class Objective:
+    """An SLO: the SLI threshold and the target fraction of good events.
+
+    Threshold and target are read from the environment on use so they
+    follow settings loaded from a config file after import.
+    """
+
+    name: str
+    description: str
+    threshold_env: str
+    default_threshold: float
+    target_env: str
+    default_target: float
+
+    @property
+    def threshold(self) -> float:
+        return float(os.getenv(self.threshold_env, str(self.default_threshold)))
+
+    @property
+    def target(self) -> float:
+        return float(os.getenv(self.target_env, str(self.default_target)))
+
+
+class SLOTracker:
+    """Per-minute good/total counts for one objective."""
+
+    def __init__(self, objective: Objective):
+        self.objective = objective
+        # (minute, good, total), oldest first
+        self._buckets: deque[list[int]] = deque(maxlen=MAX_BUCKETS)
+
+    def record(self, good: bool, now: float | None = None) -> None:
+        slo_events.labels(slo=self.objective.name, result="good" if good else "bad").inc()
+
+        minute = int((now or time.time()) // BUCKET_SEC)
+        if not self._buckets or self._buckets[-1][0] != minute:
+            self._buckets.append([minute, 0, 0])
+        bucket = self._buckets[-1]
+        bucket[1] += int(good)
+        bucket[2] += 1
+
+    def window(self, seconds: int, now: float | None = None) -> dict[str, Any]:
+        """Compliance, remaining error budget and burn rate over a window."""
+        oldest = int((now or time.time()) // BUCKET_SEC) - seconds // BUCKET_SEC
+        good = total = 0
+        for minute, bucket_good, bucket_total in reversed(self._buckets):
+            if minute <= oldest:
+                break
+            good += bucket_good
+            total += bucket_total
+
+        if not total:
+            return {"good": 0, "total": 0, "compliance": None, "error_budget_remaining": None, "burn_rate": None}
+
+        compliance = good / total
+        allowed = 1 - self.objective.target
+        burn_rate = (1 - compliance) / allowed if allowed else None
+        return {
+            "good": good,
+            "total": total,
+            "compliance": round(compliance, 6),
+            "error_budget_remaining": round(1 - burn_rate, 4) if burn_rate is not None else None,
+            "burn_rate": round(burn_rate, 4) if burn_rate is not None else None,
+        }
+
+    def summary(self, now: float | None = None) -> dict[str, Any]:
+        return {
+            "description": self.objective.description,
+            "threshold": self.objective.threshold,
+            "target": self.objective.target,
+            "windows": {name: self.window(seconds, now) for name, seconds in WINDOWS.items()},
+        }
+
+
+freshness = SLOTracker(Objective(
+    name="freshness",
+    description="Served reports with data_age_ms within threshold",
+    threshold_env="SLO_FRESHNESS_MS",
+    default_threshold=1000,
+    target_env="SLO_FRESHNESS_TARGET",
+    default_target=0.99,
+))
+
+latency = SLOTracker(Objective(
+    name="latency",
+    description="Report requests answered within threshold milliseconds",
+    threshold_env="SLO_LATENCY_MS",
+    default_threshold=150,
+    target_env="SLO_LATENCY_TARGET",
+    default_target=0.99,
+))
+
+
+def record_report_served(report: dict[str, Any]) -> None:
+    """Record the freshness SLI for a report returned to a client."""
+    data_age_ms = report.get("data_age_ms")
+    if isinstance(data_age_ms, (int, float)):
+        freshness.record(data_age_ms <= freshness.objective.threshold)
+
+
+def record_latency(latency_ms: float) -> None:
+    """Record the latency SLI for an answered request."""
+    latency.record(latency_ms <= latency.objective.threshold)
+
+
+def summary() -> dict[str, Any]:
+    """Current compliance for every SLO, as served at /slo."""
+    return {"slos": {"freshness": freshness.summary(), "latency": latency.summary()}}
@@ -3,88 +3,61 @@ SSE (Server-Sent Events) server for ChatGPT MCP integration.
 Provides get_report tool via official MCP SSE transport.
 """
 import asyncio
+import hmac
 import json
 import logging
 import os
+from dataclasses import dataclass
 from typing import Any
+from urllib.parse import parse_qs
 
-import redis.asyncio as aioredis
-from mcp.server import Server
+import anyio
+from anyio.streams.memory import MemoryObjectSendStream
+from mcp.server import NotificationOptions, Server
+from mcp.server.lowlevel.helper_types import ReadResourceContents
 from mcp.server.sse import SseServerTransport
-from mcp.types import Tool, TextContent
+from mcp.shared.message import SessionMessage
+from mcp.types import CallToolResult, JSONRPCMessage, JSONRPCNotification, Resource, Tool, TextContent
+from pydantic import AnyUrl
 from starlette.responses import Response
 
+import config
+import errors
+import limits
+import metric_catalog
+import pipeline
+import report_versions
+import slo
+from audit import APIKeyContextMiddleware
+from cache import RedisCache
+from correlation import CorrelationIDMiddleware, install_log_filter, request_correlation_id
+from jsonrpc_strict import strict_streams
+from metrics import MetricsServer
+from sessions import SessionManager
+from tools import ToolExecutor, tool_definitions
+from warmup import run_warmup
+
 # Configure logging
 logging.basicConfig(
     level=logging.INFO,
-    format='%(asctime)s - %(name)s - %(levelname)s - %(message)s'
+    format='%(asctime)s - %(name)s - %(levelname)s - [%(correlation_id)s] %(message)s'
 )
+install_log_filter()
 logger = logging.getLogger(__name__)
 
+# Seconds uvicorn waits for open connections to finish on shutdown
+SHUTDOWN_GRACE_SEC = 5
+# Seconds allowed for notifying one SSE stream, well inside the grace window
+SHUTDOWN_NOTIFY_SEC = 1
+SHUTDOWN_NOTIFICATION = "server/shutdown"
 
-class RedisCache:
-    """Redis cache reader for market reports."""
-
-    def __init__(self, redis_url: str):
-        """Initialize Redis connection."""
-        self.redis_url = redis_url
-        self.client: aioredis.Redis | None = None
-
-    async def connect(self):
-        """Connect to Redis."""
-        try:
-            self.client = await aioredis.from_url(
-                self.redis_url,
-                encoding="utf-8",
-                decode_responses=True
-            )
-            # Test connection
-            await self.client.ping()
-            logger.info(f"Connected to Redis at {self.redis_url}")
-        except Exception as e:
-            logger.error(f"Failed to connect to Redis: {e}")
-            raise
-
-    async def close(self):
-        """Close Redis connection."""
-        if self.client:
-            await self.client.aclose()
-            logger.info("Redis connection closed")
-
-    async def get_report(self, symbol: str) -> dict[str, Any] | None:
-        """
-        Fetch market report from Redis cache.
-
-        Args:
-            symbol: Trading symbol (e.g., BTCUSDT)
-
-        Returns:
-            Market report as dict or None if not found
-        """
-        if not self.client:
-            raise RuntimeError("Redis client not connected")
-
-        cache_key = f"report:{symbol}"
-
-        try:
-            # Get from Redis
-            json_str = await self.client.get(cache_key)
-
-            if json_str is None:
-                logger.debug(f"Symbol {symbol} not found in cache")
-                return None
 
-            # Parse JSON
-            report = json.loads(json_str)
-            logger.debug(f"Retrieved report for {symbol}")
-            return report
+@dataclass
+class SSEConnection:
+    """An open SSE stream, closable from outside its request."""
 
-        except json.JSONDecodeError as e:
-            logger.error(f"Failed to parse JSON for {symbol}: {e}")
-            raise
-        except Exception as e:
-            logger.error(f"Failed to get report for {symbol}: {e}")
-            raise
+    write_stream: MemoryObjectSendStream
+    cancel_scope: anyio.CancelScope
 
 
 class Context8MCPServer:
@@ -93,119 +66,109 @@ class Context8MCPServer:
     def __init__(self, redis_url: str):
         """Initialize MCP server."""
         self.cache = RedisCache(redis_url)
+        self.executor = ToolExecutor(self.cache)
+        self.metrics_server = MetricsServer()
+        self.sessions = SessionManager()
         self.server = Server("context8-mcp")
+        self._expiry_task: asyncio.Task | None = None
+        self._sse_connections: dict[str, SSEConnection] = {}
 
     async def initialize(self):
         """Initialize server and connect to Redis."""
         await self.cache.connect()
+        await self.executor.tenants.load()
+        await run_warmup(self.cache)
+        self.metrics_server.start()
+        self._expiry_task = asyncio.create_task(self.sessions.run_expiry())
         logger.info("Context8 MCP Server initialized")
 
     async def shutdown(self):
         """Shutdown server and close connections."""
+        if self._expiry_task:
+            self._expiry_task.cancel()
+        self.metrics_server.stop()
         await self.cache.close()
         logger.info("Context8 MCP Server shutdown")
 
+    async def close_sse_connections(self) -> None:
+        """Tell every open SSE stream the server is going away, then end it.
+
+        Each client gets a final server/shutdown notification so it can
+        reconnect (to another replica) right away rather than timing out
+        mid-read when the process exits.
+        """
+        if not self._sse_connections:
+            return
+        logger.info(f"Closing {len(self._sse_connections)} SSE connections for shutdown")
+        notification = SessionMessage(JSONRPCMessage(JSONRPCNotification(
+            jsonrpc="2.0",
+            method=SHUTDOWN_NOTIFICATION,
+            params={"message": "Server is shutting down; reconnect to continue", "retry_after": 1},
+        )))
+        for session_id, connection in list(self._sse_connections.items()):
+            try:
+                with anyio.fail_after(SHUTDOWN_NOTIFY_SEC):
+                    await connection.write_stream.send(notification)
+            except (TimeoutError, anyio.ClosedResourceError, anyio.BrokenResourceError) as e:
+                logger.warning(f"Shutdown notification not delivered to session {session_id}: {e!r}")
+            connection.cancel_scope.cancel()
+            self.sessions.close(session_id, reason="shutdown")
+
     def register_handlers(self):
         """Register MCP handlers."""
 
         @self.server.list_tools()
         async def list_tools() -> list[Tool]:
             """List available tools."""
-            return [
-                Tool(
-                    name="get_report",
-                    description=(
-                        "Retrieve real-time market data report for a tracked symbol "
-                        "including orderbook metrics, volume profile, and flow analysis"
-                    ),
-                    inputSchema={
-                        "type": "object",
-                        "properties": {
-                            "symbol": {
-                                "type": "string",
-                                "description": (
-                                    "Trading symbol (e.g., BTCUSDT, ETHUSDT, "
-                                    "1INCHUSDT, 1000SHIBUSDT)"
-                                ),
-                                "pattern": "^[A-Z0-9]+USDT$",
-                            }
-                        },
-                        "required": ["symbol"],
-                    }
-                )
-            ]
+            self.sessions.touch(self.server.request_context, "sse")
+            return tool_definitions()
 
         @self.server.call_tool()
-        async def call_tool(name: str, arguments: dict) -> list[TextContent]:
+        async def call_tool(
+            name: str, arguments: dict
+        ) -> list[TextContent] | tuple[list[TextContent], dict[str, Any]] | CallToolResult:
             """Call a tool."""
-            if name != "get_report":
-                error_msg = f"Tool '{name}' not found. Available tools: get_report"
-                logger.warning(error_msg)
-                return [TextContent(
-                    type="text",
-                    text=json.dumps({
-                        "error": error_msg,
-                        "error_code": "TOOL_NOT_FOUND"
-                    }, indent=2)
-                )]
-
-            # Get symbol from arguments
-            symbol = arguments.get("symbol")
-            if not symbol:
-                error_msg = "Missing required parameter: symbol"
-                logger.warning(error_msg)
-                return [TextContent(
-                    type="text",
-                    text=json.dumps({
-                        "error": error_msg,
-                        "error_code": "MISSING_PARAMETER"
-                    }, indent=2)
-                )]
-
-            # Validate symbol pattern
-            import re
-            if not re.match(r"^[A-Z0-9]+USDT$", symbol):
-                error_msg = f"Invalid symbol format: {symbol}. Must match pattern: ^[A-Z0-9]+USDT$"
-                logger.warning(error_msg)
-                return [TextContent(
-                    type="text",
-                    text=json.dumps({
-                        "error": error_msg,
-                        "error_code": "INVALID_SYMBOL"
-                    }, indent=2)
-                )]
-
-            # Get report from cache
-            try:
-                report = await self.cache.get_report(symbol)
-
-                if report is None:
-                    error_msg = f"Symbol '{symbol}' not found in cache"
-                    logger.info(error_msg)
-                    return [TextContent(
-                        type="text",
-                        text=json.dumps({
-                            "error": error_msg,
-                            "error_code": "SYMBOL_NOT_FOUND"
-                        }, indent=2)
-                    )]
-
-                # Return report as formatted JSON
-                return [TextContent(
-                    type="text",
-                    text=json.dumps(report, indent=2)
-                )]
-
-            except Exception as e:
-                error_msg = f"Failed to retrieve report: {str(e)}"
-                logger.error(error_msg, exc_info=True)
-                return [TextContent(
-                    type="text",
-                    text=json.dumps({
-                        "error": error_msg,
-                        "error_code": "INTERNAL_ERROR"
-                    }, indent=2)
-                )]
+            self.sessions.touch(self.server.request_context, "sse")
+            return await self.executor.call(
+                name, arguments, correlation_id=request_correlation_id(self.server.request_context)
+            )
+
+        @self.server.list_resources()
+        async def list_resources() -> list[Resource]:
+            """List available resources."""
+            self.sessions.touch(self.server.request_context, "sse")
+            return [Resource(
+                uri=AnyUrl(metric_catalog.CATALOG_URI),
+                name="metric_catalog",
+                description=(
+                    "Every report field with its type, the schema version that added it "
+                    "and its definition (formula, window, units, caveats)"
+                ),
+                mimeType="application/json",
+            )]
+
+        @self.server.read_resource()
+        async def read_resource(uri: AnyUrl) -> list[ReadResourceContents]:
+            """Read a resource."""
+            self.sessions.touch(self.server.request_context, "sse")
+            if str(uri) != metric_catalog.CATALOG_URI:
+                raise ValueError(f"Unknown resource: {uri}")
+            return [ReadResourceContents(
+                content=json.dumps(metric_catalog.catalog()), mime_type="application/json"
+            )]
+
+        @self.server.subscribe_resource()
+        async def subscribe_resource(uri: AnyUrl) -> None:
+            """Record a resource subscription on the session."""
+            self.sessions.subscribe(self.server.request_context, "sse", str(uri))
+
+        @self.server.unsubscribe_resource()
+        async def unsubscribe_resource(uri: AnyUrl) -> None:
+            self.sessions.unsubscribe(self.server.request_context, "sse", str(uri))
+
+        self.sessions.server_capabilities = self.server.get_capabilities(
+            NotificationOptions(), {}
+        ).model_dump(exclude_none=True)
 
     def get_sse_app(self):
         """
@@ -235,12 +198,94 @@ class Context8MCPServer:
                 )
                 await response(scope, receive, send)
 
+            # Readiness: Redis reachable and the producer not down (see pipeline.py)
+            elif path == "/ready":
+                body, status = await pipeline.readiness(self.cache, self.executor.pipeline)
+                response = Response(json.dumps(body), status_code=status, media_type="application/json")
+                await response(scope, receive, send)
+
+            # Machine-readable error code catalog
+            elif path == "/errors":
+                response = Response(
+                    json.dumps(errors.error_catalog()),
+                    media_type="application/json"
+                )
+                await response(scope, receive, send)
+
+            # Report schema changelog (?since=major.minor)
+            elif path == "/changelog":
+                since = parse_qs(scope.get("query_string", b"").decode()).get("since", [None])[0]
+                try:
+                    body, status = report_versions.changelog(since), 200
+                except ValueError as e:
+                    body, status = errors.ErrorResponse(errors.INVALID_PARAMETER, str(e)).to_dict(), 400
+                response = Response(json.dumps(body), status_code=status, media_type="application/json")
+                await response(scope, receive, send)
+
+            # Report field catalog (also the context8://metrics/catalog resource)
+            elif path == "/metrics_catalog":
+                response = Response(json.dumps(metric_catalog.catalog()), media_type="application/json")
+                await response(scope, receive, send)
+
+            # Open MCP sessions (ADMIN_TOKEN bearer token)
+            elif path == "/admin/sessions":
+                admin_token = os.getenv("ADMIN_TOKEN", "")
+                authorization = dict(scope.get("headers", [])).get(b"authorization", b"").decode()
+                if not admin_token or not hmac.compare_digest(authorization.encode(), f"Bearer {admin_token}".encode()):
+                    error = errors.ErrorResponse(
+                        errors.UNAUTHORIZED, "Admin endpoints require a valid ADMIN_TOKEN bearer token"
+                    )
+                    response = Response(json.dumps(error.to_dict()), status_code=errors.UNAUTHORIZED.http_status, media_type="application/json")
+                else:
+                    body = {**self.sessions.summary(), "limits": self.executor.limiter.summary()}
+                    response = Response(json.dumps(body), media_type="application/json")
+                await response(scope, receive, send)
+
+            # SLO compliance summary
+            elif path == "/slo":
+                response = Response(
+                    json.dumps(slo.summary()),
+                    media_type="application/json"
+                )
+                await response(scope, receive, send)
+
             # SSE connection endpoint (GET only)
             elif (path == "/sse" or path == "/sse/") and method == "GET":
+                max_connections = limits.max_sse_connections()
+                open_connections = self.sessions.count("sse")
+                if max_connections and open_connections >= max_connections:
+                    error = errors.ErrorResponse(
+                        errors.OVERLOADED,
+                        str(limits.rejected(
+                            "sse_connections", f"Server already has {open_connections} open SSE connections"
+                        )),
+                    )
+                    response = Response(
+                        json.dumps(error.to_dict()),
+                        status_code=errors.OVERLOADED.http_status,
+                        headers={"Retry-After": str(error.effective_retry_after)},
+                        media_type="application/json",
+                    )
+                    await response(scope, receive, send)
+                    return
+
                 logger.info(f"New SSE connection from {scope.get('client', ['unknown'])[0]}")
-                async with sse.connect_sse(scope, receive, send) as (read_stream, write_stream):
-                    init_options = self.server.create_initialization_options()
-                    await self.server.run(read_stream, write_stream, init_options)
+                session = self.sessions.open("sse")
+                try:
+                    async with (
+                        sse.connect_sse(scope, receive, send) as (transport_read, transport_write),
+                        strict_streams(transport_read, transport_write) as (read_stream, write_stream),
+                    ):
+                        init_options = self.server.create_initialization_options()
+                        with anyio.CancelScope() as cancel_scope:
+                            self._sse_connections[session.id] = SSEConnection(write_stream, cancel_scope)
+                            await self.server.run(read_stream, write_stream, init_options)
+                        if cancel_scope.cancel_called:
+                            # Closed for shutdown: end the event stream once the notification is flushed
+                            await write_stream.aclose()
+                finally:
+                    self._sse_connections.pop(session.id, None)
+                    self.sessions.close(session.id)
 
             # SSE messages endpoint (POST only)
             elif path.startswith("/sse/messages") and method == "POST":
@@ -263,13 +308,27 @@ class Context8MCPServer:
                 response = Response(f"Not Found: {method} {path}", status_code=404)
                 await response(scope, receive, send)
 
-        return main_app
+        return CorrelationIDMiddleware(APIKeyContextMiddleware(main_app))
+
+
+def graceful_server(uvicorn_config, mcp_server: Context8MCPServer):
+    """uvicorn server that closes SSE streams before waiting out open connections."""
+    import uvicorn
+
+    class GracefulServer(uvicorn.Server):
+        async def shutdown(self, sockets=None):
+            await mcp_server.close_sse_connections()
+            await super().shutdown(sockets=sockets)
+
+    return GracefulServer(uvicorn_config)
 
 
 async def main():
     """Main entry point for SSE server."""
     import uvicorn
 
+    config.load()
+
     # Get Redis URL from environment
     redis_url = os.getenv("REDIS_URL", "redis://localhost:6379")
 
@@ -285,14 +344,15 @@ async def main():
 
     # Run with uvicorn
     port = int(os.getenv("PORT", "8080"))
-    config = uvicorn.Config(
+    uvicorn_config = uvicorn.Config(
         app,
         host="0.0.0.0",
         port=port,
         log_level="info",
         access_log=True,
+        timeout_graceful_shutdown=SHUTDOWN_GRACE_SEC,
     )
-    uvicorn_server = uvicorn.Server(config)
+    uvicorn_server = graceful_server(uvicorn_config, mcp_server)
 
     try:
         logger.info(f"Starting SSE server on http://0.0.0.0:{port}")
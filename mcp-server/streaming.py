@@ -0,0 +1,324 @@
+"""
+Live report streaming over WebSocket and Server-Sent Events.
+
+The producer publishes every report it writes on the `reports:{symbol}`
+Redis pub/sub channel. A single ReportBroadcaster subscribes to all of
+them and fans updates out to connected dashboards, each with its own
+symbol subscriptions and a bounded send queue. Connections that fall
+more than WS_QUEUE_SIZE updates behind are dropped rather than allowed
+to buffer without limit.
+
+Each update gets the next sequence number of its symbol, and the last
+SSE_REPLAY_SIZE updates per symbol are kept for replay. SSE event ids
+are cursors over the connection's symbols ("<epoch>-BTCUSDT:42,ETHUSDT:17"),
+so a client reconnecting with Last-Event-ID receives exactly the updates
+it missed; when the buffer no longer reaches back (or the server
+restarted, changing the epoch) it gets a `gap` event and should refetch
+those symbols from /v1/report.
+"""
+import asyncio
+import json
+import logging
+import os
+import time
+from collections import deque
+from dataclasses import dataclass, field
+from typing import AsyncIterator, NamedTuple
+
+from starlette.requests import Request
+from starlette.responses import StreamingResponse
+from starlette.websockets import WebSocket, WebSocketDisconnect
+
+from audit import api_key_var
+from cache import RedisCache
+from tenancy import TenantRegistry
+
+logger = logging.getLogger(__name__)
+
+REPORT_CHANNEL_PREFIX = "reports:"
+DEFAULT_QUEUE_SIZE = 100
+DEFAULT_REPLAY_SIZE = 50
+
+# Comment line keeping idle SSE connections open through proxies
+SSE_KEEPALIVE_SEC = 15
+
+# Close code sent to connections dropped for falling behind
+SLOW_CONSUMER_CLOSE_CODE = 1008
+
+
+class ReportUpdate(NamedTuple):
+    """A published report and its per-symbol sequence number."""
+
+    symbol: str
+    seq: int
+    payload: str
+
+
+@dataclass(eq=False)
+class Subscriber:
+    """A WebSocket connection and the symbols it is subscribed to."""
+
+    symbols: set[str]
+    queue: asyncio.Queue
+    dropped: asyncio.Event = field(default_factory=asyncio.Event)
+
+
+class ReportBroadcaster:
+    """Fans report updates from Redis pub/sub out to WebSocket subscribers."""
+
+    def __init__(self, cache: RedisCache, queue_size: int | None = None, replay_size: int | None = None):
+        self.cache = cache
+        if queue_size is None:
+            queue_size = int(os.getenv("WS_QUEUE_SIZE", str(DEFAULT_QUEUE_SIZE)))
+        if replay_size is None:
+            replay_size = int(os.getenv("SSE_REPLAY_SIZE", str(DEFAULT_REPLAY_SIZE)))
+        self.queue_size = queue_size
+        self.replay_size = replay_size
+        self.subscribers: set[Subscriber] = set()
+        self._task: asyncio.Task | None = None
+        # Sequence numbers restart with the process; the epoch tells clients their cursor is void
+        self.epoch = format(int(time.time() * 1000), "x")
+        self._seq: dict[str, int] = {}
+        self._history: dict[str, deque[ReportUpdate]] = {}
+
+    async def start(self) -> None:
+        """Start listening for report updates."""
+        self._task = asyncio.create_task(self._listen())
+
+    async def stop(self) -> None:
+        if self._task:
+            self._task.cancel()
+            try:
+                await self._task
+            except asyncio.CancelledError:
+                pass
+
+    def add(self, symbols: set[str]) -> Subscriber:
+        subscriber = Subscriber(symbols=symbols, queue=asyncio.Queue(maxsize=self.queue_size))
+        self.subscribers.add(subscriber)
+        return subscriber
+
+    def remove(self, subscriber: Subscriber) -> None:
+        self.subscribers.discard(subscriber)
+
+    async def _listen(self) -> None:
+        while True:
+            pubsub = self.cache.pubsub_client.pubsub()
+            try:
+                await pubsub.psubscribe(f"{REPORT_CHANNEL_PREFIX}*")
+                logger.info("Subscribed to report updates")
+                async for message in pubsub.listen():
+                    if message["type"] != "pmessage":
+                        continue
+                    symbol = message["channel"][len(REPORT_CHANNEL_PREFIX):]
+                    self._dispatch(symbol, message["data"])
+            except asyncio.CancelledError:
+                raise
+            except Exception as e:
+                logger.error(f"Report subscription failed, retrying: {e}")
+                await asyncio.sleep(1)
+            finally:
+                await pubsub.aclose()
+
+    def last_seq(self, symbol: str) -> int:
+        """Sequence number of the symbol's latest update (0 before any)."""
+        return self._seq.get(symbol, 0)
+
+    def replay(self, symbol: str, after_seq: int) -> tuple[list[ReportUpdate], bool]:
+        """Buffered updates of symbol after a sequence number, and whether none were evicted."""
+        history = self._history.get(symbol, ())
+        updates = [update for update in history if update.seq > after_seq]
+        oldest = history[0].seq if history else self._seq.get(symbol, 0) + 1
+        return updates, after_seq >= oldest - 1
+
+    def _dispatch(self, symbol: str, payload: str) -> None:
+        seq = self._seq[symbol] = self._seq.get(symbol, 0) + 1
+        update = ReportUpdate(symbol, seq, payload)
+        if self.replay_size:
+            self._history.setdefault(symbol, deque(maxlen=self.replay_size)).append(update)
+
+        for subscriber in list(self.subscribers):
+            if symbol not in subscriber.symbols:
+                continue
+            try:
+                subscriber.queue.put_nowait(update)
+            except asyncio.QueueFull:
+                logger.warning(f"Dropping slow stream consumer ({len(subscriber.symbols)} symbols)")
+                self.remove(subscriber)
+                subscriber.dropped.set()
+
+
+async def _entitled(tenants: TenantRegistry, api_key: str | None, symbols) -> tuple[set[str], list[str]]:
+    """Split requested symbols into entitled symbols and denial reasons."""
+    allowed, denied = set(), []
+    for symbol in symbols:
+        symbol = symbol.strip().upper()
+        if not symbol:
+            continue
+        reason = await tenants.check_symbol(api_key, symbol)
+        if reason:
+            denied.append(reason)
+        else:
+            allowed.add(symbol)
+    return allowed, denied
+
+
+async def handle_websocket(
+    websocket: WebSocket,
+    broadcaster: ReportBroadcaster,
+    tenants: TenantRegistry,
+) -> None:
+    """
+    Stream report updates to a WebSocket client.
+
+    Initial symbols come from ?symbols=BTCUSDT,ETHUSDT. Clients manage
+    subscriptions by sending {"action": "subscribe" | "unsubscribe", "symbols": [...]}.
+    Each update is sent as {"type": "report", "symbol": ..., "seq": ..., "report": {...}}.
+    """
+    api_key = api_key_var.get() or websocket.query_params.get("api_key")
+    await websocket.accept()
+
+    requested = websocket.query_params.get("symbols", "").split(",")
+    symbols, denied = await _entitled(tenants, api_key, requested)
+    for reason in denied:
+        await websocket.send_json({"type": "error", "code": "NOT_ENTITLED", "message": reason})
+
+    subscriber = broadcaster.add(symbols)
+    await websocket.send_json({"type": "subscribed", "symbols": sorted(subscriber.symbols)})
+
+    async def receive_commands():
+        while True:
+            try:
+                command = json.loads(await websocket.receive_text())
+                action = command["action"]
+                names = command.get("symbols", [])
+                if action not in ("subscribe", "unsubscribe"):
+                    raise ValueError(action)
+            except (ValueError, KeyError, TypeError):
+                await websocket.send_json({
+                    "type": "error",
+                    "code": "INVALID_PARAMETER",
+                    "message": 'Expected {"action": "subscribe" | "unsubscribe", "symbols": [...]}',
+                })
+                continue
+
+            if action == "subscribe":
+                allowed, denied = await _entitled(tenants, api_key, names)
+                subscriber.symbols |= allowed
+                for reason in denied:
+                    await websocket.send_json({"type": "error", "code": "NOT_ENTITLED", "message": reason})
+            else:
+                subscriber.symbols -= {s.upper() for s in names}
+            await websocket.send_json({"type": "subscribed", "symbols": sorted(subscriber.symbols)})
+
+    async def send_updates():
+        while True:
+            update = await subscriber.queue.get()
+            await websocket.send_json({
+                "type": "report", "symbol": update.symbol, "seq": update.seq, "report": json.loads(update.payload),
+            })
+
+    tasks = [
+        asyncio.create_task(receive_commands()),
+        asyncio.create_task(send_updates()),
+        asyncio.create_task(subscriber.dropped.wait()),
+    ]
+    try:
+        done, _ = await asyncio.wait(tasks, return_when=asyncio.FIRST_COMPLETED)
+        for task in done:
+            if not task.cancelled() and task.exception() and not isinstance(task.exception(), WebSocketDisconnect):
+                logger.warning(f"WebSocket stream ended: {task.exception()}")
+        if subscriber.dropped.is_set():
+            await websocket.close(code=SLOW_CONSUMER_CLOSE_CODE, reason="Slow consumer")
+    finally:
+        for task in tasks:
+            task.cancel()
+        broadcaster.remove(subscriber)
+
+
+def format_event_id(epoch: str, positions: dict[str, int]) -> str:
+    """SSE event id: the broadcaster epoch and the last sequence sent per symbol."""
+    return f"{epoch}-" + ",".join(f"{symbol}:{seq}" for symbol, seq in sorted(positions.items()))
+
+
+def parse_event_id(event_id: str) -> tuple[str, dict[str, int]]:
+    """Epoch and per-symbol positions of an SSE event id (empty for malformed ids)."""
+    epoch, _, cursor = event_id.strip().partition("-")
+    positions = {}
+    for entry in filter(None, cursor.split(",")):
+        symbol, _, seq = entry.partition(":")
+        try:
+            positions[symbol.upper()] = int(seq)
+        except ValueError:
+            return "", {}
+    return epoch, positions
+
+
+def _sse_event(event: str, data: str, event_id: str | None = None) -> str:
+    lines = [f"id: {event_id}"] if event_id is not None else []
+    lines.append(f"event: {event}")
+    lines.extend(f"data: {line}" for line in data.splitlines() or [""])
+    return "\n".join(lines) + "\n\n"
+
+
+async def handle_sse(request: Request, broadcaster: ReportBroadcaster, tenants: TenantRegistry) -> StreamingResponse:
+    """
+    Stream report updates for ?symbols=... as Server-Sent Events.
+
+    Events are `subscribed` (entitled symbols), `report` (one report, with
+    an id), `gap` (symbols whose missed updates are no longer buffered) and
+    `error`. Resume with the Last-Event-ID header (sent automatically by
+    EventSource) or ?last_event_id=. Slow consumers are disconnected and
+    can resume the same way.
+    """
+    api_key = api_key_var.get() or request.query_params.get("api_key")
+    symbols, denied = await _entitled(tenants, api_key, request.query_params.get("symbols", "").split(","))
+
+    last_event_id = request.headers.get("last-event-id") or request.query_params.get("last_event_id")
+    epoch, resumed = parse_event_id(last_event_id) if last_event_id else ("", {})
+    resume = {symbol: seq for symbol, seq in resumed.items() if symbol in symbols}
+    gaps = []
+    if resume and epoch != broadcaster.epoch:
+        gaps, resume = sorted(resume), {}
+
+    # Subscribe before replaying so nothing published in between is lost; symbols
+    # not in the cursor start from their latest update
+    subscriber = broadcaster.add(symbols)
+    positions = {symbol: resume.get(symbol, broadcaster.last_seq(symbol)) for symbol in symbols}
+
+    async def events() -> AsyncIterator[str]:
+        try:
+            for reason in denied:
+                yield _sse_event("error", json.dumps({"code": "NOT_ENTITLED", "message": reason}))
+            yield _sse_event("subscribed", json.dumps({"symbols": sorted(symbols)}))
+
+            replays = {}
+            for symbol in sorted(resume):
+                replays[symbol], complete = broadcaster.replay(symbol, resume[symbol])
+                if not complete:
+                    gaps.append(symbol)
+            if gaps:
+                yield _sse_event("gap", json.dumps({"symbols": sorted(gaps)}))
+            for symbol, updates in replays.items():
+                for update in updates:
+                    positions[symbol] = update.seq
+                    yield _sse_event("report", update.payload, format_event_id(broadcaster.epoch, positions))
+
+            while not subscriber.dropped.is_set():
+                try:
+                    update = await asyncio.wait_for(subscriber.queue.get(), SSE_KEEPALIVE_SEC)
+                except asyncio.TimeoutError:
+                    yield ": keepalive\n\n"
+                    continue
+                if update.seq <= positions.get(update.symbol, 0):
+                    continue  # Already sent during replay
+                positions[update.symbol] = update.seq
+                yield _sse_event("report", update.payload, format_event_id(broadcaster.epoch, positions))
+        finally:
+            broadcaster.remove(subscriber)
+
+    return StreamingResponse(
+        events(),
+        media_type="text/event-stream",
+        headers={"Cache-Control": "no-cache", "X-Accel-Buffering": "no"},
+    )
@@ -0,0 +1,206 @@
+"""
+Per-tenant symbol and venue entitlements.
+
+Tenants are loaded from the JSON file in TENANTS_FILE, or otherwise from
+the `mcp:tenants` Redis key (refreshed every TENANTS_REFRESH_SEC):
+
+    {
+      "tenants": [
+        {"name": "desk-a", "api_keys": ["key1"], "symbols": ["BTCUSDT"], "venues": ["BINANCE"]},
+        {"name": "research", "api_keys": ["key2"], "symbols": ["*"]}
+      ]
+    }
+
+When no tenants are configured every request is allowed. Once tenants
+exist, only API keys belonging to a tenant may read reports, and only for
+that tenant's symbols and venues ("*" or an omitted list means all).
+
+Loading fails closed: a configuration that cannot be read or validated
+keeps the last good tenant set, and until one has loaded every request is
+denied. Failed loads are retried with exponential backoff up to the
+refresh interval.
+"""
+import json
+import logging
+import os
+import time
+from dataclasses import dataclass, field
+from typing import Any
+
+from cache import RedisCache
+
+logger = logging.getLogger(__name__)
+
+TENANTS_KEY = "mcp:tenants"
+DEFAULT_REFRESH_SEC = 60
+INITIAL_RETRY_SEC = 1.0
+UNAVAILABLE_REASON = "Tenant configuration could not be loaded; access is denied until it is fixed"
+
+
+@dataclass
+class Tenant:
+    """A team sharing the deployment, with its entitlements."""
+
+    name: str
+    api_keys: set[str] = field(default_factory=set)
+    symbols: set[str] = field(default_factory=lambda: {"*"})
+    venues: set[str] = field(default_factory=lambda: {"*"})
+
+    @classmethod
+    def from_dict(cls, data: Any) -> "Tenant":
+        """Build a tenant from its JSON entry, raising ValueError when malformed."""
+        if not isinstance(data, dict):
+            raise ValueError(f"tenant entry must be an object, got {type(data).__name__}")
+        name = data.get("name")
+        if not isinstance(name, str) or not name:
+            raise ValueError("tenant entry needs a non-empty 'name'")
+        return cls(
+            name=name,
+            api_keys=set(_string_list(data, "api_keys", [], name)),
+            symbols={s.upper() for s in _string_list(data, "symbols", ["*"], name)},
+            venues={v.upper() for v in _string_list(data, "venues", ["*"], name)},
+        )
+
+    def allows_symbol(self, symbol: str) -> bool:
+        return "*" in self.symbols or symbol.upper() in self.symbols
+
+    def allows_venue(self, venue: str | None) -> bool:
+        return "*" in self.venues or (venue or "").upper() in self.venues
+
+
+def _string_list(data: dict[str, Any], key: str, default: list[str], tenant: str) -> list[str]:
+    values = data.get(key, default)
+    if not isinstance(values, list) or not all(isinstance(v, str) for v in values):
+        raise ValueError(f"tenant '{tenant}': '{key}' must be a list of strings")
+    return values
+
+
+def parse_tenants(config: Any) -> list[Tenant]:
+    """Validate a tenant configuration document, raising ValueError when malformed."""
+    if not isinstance(config, dict):
+        raise ValueError("tenant configuration must be an object")
+    entries = config.get("tenants", [])
+    if not isinstance(entries, list):
+        raise ValueError("'tenants' must be a list")
+    tenants = [Tenant.from_dict(entry) for entry in entries]
+    names = [t.name for t in tenants]
+    if len(set(names)) != len(names):
+        raise ValueError("tenant names must be unique")
+    return tenants
+
+
+class TenantRegistry:
+    """Resolves API keys to tenants and checks their entitlements."""
+
+    def __init__(self, cache: RedisCache, tenants_file: str | None = None):
+        self.cache = cache
+        self.tenants_file = tenants_file if tenants_file is not None else os.getenv("TENANTS_FILE", "")
+        self.refresh_sec = int(os.getenv("TENANTS_REFRESH_SEC", str(DEFAULT_REFRESH_SEC)))
+        self._by_key: dict[str, Tenant] = {}
+        self._tenants: list[Tenant] = []
+        self._loaded = False  # A configuration has loaded successfully
+        self._failed = False  # Loading was attempted and nothing good has loaded yet
+        self._next_load_at = 0.0
+        self._retry_sec = INITIAL_RETRY_SEC
+
+    @property
+    def enabled(self) -> bool:
+        return bool(self._tenants)
+
+    @property
+    def unavailable(self) -> bool:
+        """True while the configuration has never loaded, so every request is denied."""
+        return self._failed and not self._loaded
+
+    async def load(self) -> bool:
+        """
+        Load tenant configuration from the file or Redis.
+
+        Returns:
+            True when a valid configuration was loaded; on failure the last
+            good tenant set is kept and the next attempt is backed off
+        """
+        try:
+            if self.tenants_file:
+                with open(self.tenants_file) as f:
+                    config = json.load(f)
+            elif self.cache.client:
+                raw = await self.cache.client.get(TENANTS_KEY)
+                config = json.loads(raw) if raw else {"tenants": []}
+            else:
+                return False
+            tenants = parse_tenants(config)
+        except Exception as e:
+            self._failed = True
+            self._next_load_at = time.monotonic() + self._retry_sec
+            logger.error(
+                f"Failed to load tenant configuration (retrying in {self._retry_sec:.0f}s, "
+                f"{'keeping last good set' if self._loaded else 'denying all requests'}): {e}"
+            )
+            self._retry_sec = min(self._retry_sec * 2, self.refresh_sec)
+            return False
+
+        self._tenants = tenants
+        self._by_key = {key: t for t in tenants for key in t.api_keys}
+        self._loaded = True
+        self._failed = False
+        self._retry_sec = INITIAL_RETRY_SEC
+        # A file is read once; Redis is polled for changes
+        self._next_load_at = float("inf") if self.tenants_file else time.monotonic() + self.refresh_sec
+        logger.info(f"Loaded {len(tenants)} tenants")
+        return True
+
+    async def _refresh_if_due(self) -> None:
+        if time.monotonic() >= self._next_load_at:
+            await self.load()
+
+    async def tenant_for(self, api_key: str | None) -> Tenant | None:
+        await self._refresh_if_due()
+        if not api_key:
+            return None
+        return self._by_key.get(api_key)
+
+    async def check_symbol(self, api_key: str | None, symbol: str) -> str | None:
+        """
+        Check whether the key may read the symbol.
+
+        Returns:
+            None when allowed, otherwise the reason for denial
+        """
+        await self._refresh_if_due()
+        if self.unavailable:
+            return UNAVAILABLE_REASON
+        if not self.enabled:
+            return None
+        tenant = await self.tenant_for(api_key)
+        if tenant is None:
+            return "API key is not assigned to a tenant"
+        if not tenant.allows_symbol(symbol):
+            return f"Symbol '{symbol}' is not entitled for tenant '{tenant.name}'"
+        return None
+
+    async def check_report(self, api_key: str | None, report: dict[str, Any]) -> str | None:
+        """Check the key's venue entitlement for a fetched report."""
+        if self.unavailable:
+            return UNAVAILABLE_REASON
+        if not self.enabled:
+            return None
+        tenant = await self.tenant_for(api_key)
+        if tenant is None:
+            return "API key is not assigned to a tenant"
+        venue = report.get("venue")
+        if not tenant.allows_venue(venue):
+            return f"Venue '{venue}' is not entitled for tenant '{tenant.name}'"
+        return None
+
+    async def visible_symbols(self, api_key: str | None, symbols: list[str]) -> list[str]:
+        """Filter a symbol list down to the key's entitlements."""
+        await self._refresh_if_due()
+        if self.unavailable:
+            return []
+        if not self.enabled:
+            return symbols
+        tenant = await self.tenant_for(api_key)
+        if tenant is None:
+            return []
+        return [s for s in symbols if tenant.allows_symbol(s)]
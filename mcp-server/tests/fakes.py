@@ -0,0 +1,72 @@
+"""In-process stand-ins for the Redis client used by the server modules."""
+from fnmatch import fnmatchcase
+from typing import Any
+
+
+class FakeRedis:
+    """Async subset of redis.asyncio.Redis over dicts (strings, hashes, streams)."""
+
+    def __init__(self):
+        self.values: dict[str, Any] = {}
+        self.streams: dict[str, list[tuple[str, dict]]] = {}
+
+    async def get(self, key: str) -> Any:
+        return self.values.get(key)
+
+    async def set(self, key: str, value: Any, **kwargs) -> bool:
+        self.values[key] = value
+        return True
+
+    async def incr(self, key: str) -> int:
+        return await self.incrby(key, 1)
+
+    async def incrby(self, key: str, amount: int) -> int:
+        self.values[key] = int(self.values.get(key, 0)) + amount
+        return self.values[key]
+
+    async def expireat(self, key: str, when: Any) -> bool:
+        return key in self.values
+
+    async def scan_iter(self, match: str = "*", count: int | None = None):
+        for key in list(self.values):
+            if fnmatchcase(key, match):
+                yield key
+
+    async def hgetall(self, key: str) -> dict:
+        return dict(self.values.get(key, {}))
+
+    async def hset(self, key: str, field: str | None = None, value: Any = None, mapping: dict | None = None) -> int:
+        fields = self.values.setdefault(key, {})
+        updates = dict(mapping or {})
+        if field is not None:
+            updates[field] = value
+        fields.update(updates)
+        return len(updates)
+
+    async def xadd(self, stream: str, fields: dict, **kwargs) -> str:
+        entries = self.streams.setdefault(stream, [])
+        entry_id = f"{len(entries) + 1}-0"
+        entries.append((entry_id, dict(fields)))
+        return entry_id
+
+    def pipeline(self, transaction: bool = True) -> "FakePipeline":
+        return FakePipeline(self)
+
+
+class FakePipeline:
+    """Queues FakeRedis calls and runs them on execute()."""
+
+    def __init__(self, client: FakeRedis):
+        self.client = client
+        self.calls: list[tuple[str, tuple, dict]] = []
+
+    def __getattr__(self, name: str):
+        def queue(*args, **kwargs):
+            self.calls.append((name, args, kwargs))
+            return self
+        return queue
+
+    async def execute(self, raise_on_error: bool = True) -> list[Any]:
+        results = [await getattr(self.client, name)(*args, **kwargs) for name, args, kwargs in self.calls]
+        self.calls = []
+        return results
@@ -0,0 +1,26 @@
+"""Admin bearer token checks."""
+import os
+from types import SimpleNamespace
+
+from rest_server import require_admin
+
+
+def _request(authorization: str | None) -> SimpleNamespace:
+    return SimpleNamespace(headers={"Authorization": authorization} if authorization is not None else {})
+
+
+def test_require_admin_accepts_only_the_configured_token():
+    previous = os.environ.get("ADMIN_TOKEN")
+    os.environ["ADMIN_TOKEN"] = "s3cret"
+    try:
+        assert require_admin(_request("Bearer s3cret")) is None
+        assert require_admin(_request("Bearer s3cre")) is not None
+        assert require_admin(_request("Bearer s3crët")) is not None
+        assert require_admin(_request(None)) is not None
+        os.environ["ADMIN_TOKEN"] = ""
+        assert require_admin(_request("Bearer ")) is not None
+    finally:
+        if previous is None:
+            os.environ.pop("ADMIN_TOKEN", None)
+        else:
+            os.environ["ADMIN_TOKEN"] = previous
@@ -0,0 +1,62 @@
+"""Correlation IDs of MCP tool calls."""
+from types import SimpleNamespace
+
+from audit import AUDIT_STREAM
+from cache import RedisCache
+from correlation import correlation_id_var, request_correlation_id
+from tests.fakes import FakeRedis
+from tools import ToolExecutor
+
+
+def _audited_ids(executor: ToolExecutor) -> list[str]:
+    return [fields["correlation_id"] for _, fields in executor.cache.client.streams[AUDIT_STREAM]]
+
+
+async def test_calls_on_one_session_get_their_own_ids():
+    cache = RedisCache("redis://unused", replica_urls=[])
+    cache.client = FakeRedis()
+    executor = ToolExecutor(cache)
+    # SSE tool calls run in the context of the GET /sse request
+    token = correlation_id_var.set("connection-id")
+    try:
+        await executor.call("get_schema_changelog", {})
+        await executor.call("get_schema_changelog", {})
+        await executor.call("get_schema_changelog", {}, correlation_id="client-id")
+        assert correlation_id_var.get() == "connection-id"
+    finally:
+        correlation_id_var.reset(token)
+
+    first, second, third = _audited_ids(executor)
+    assert first != second
+    assert "connection-id" not in (first, second)
+    assert third == "client-id"
+
+
+def test_request_correlation_id_prefers_meta_over_header():
+    request = SimpleNamespace(headers={"X-Correlation-ID": "header-id"})
+
+    assert request_correlation_id(SimpleNamespace(meta=SimpleNamespace(correlation_id="meta-id"), request=request)) == "meta-id"
+    assert request_correlation_id(SimpleNamespace(meta=None, request=request)) == "header-id"
+    assert request_correlation_id(SimpleNamespace(meta=None, request=None)) is None
+
+
+def test_malformed_client_ids_are_replaced():
+    for bad in ("", "x" * 129, "id\nforged log line", "id with spaces", "ïd"):
+        request = SimpleNamespace(headers={"X-Correlation-ID": bad})
+        context = SimpleNamespace(meta=SimpleNamespace(correlation_id=bad), request=request)
+        assert request_correlation_id(context) is None
+
+    assert request_correlation_id(
+        SimpleNamespace(meta=SimpleNamespace(correlation_id="a.b_c-1"), request=None)
+    ) == "a.b_c-1"
+
+
+async def test_call_replaces_an_invalid_correlation_id():
+    cache = RedisCache("redis://unused", replica_urls=[])
+    cache.client = FakeRedis()
+    executor = ToolExecutor(cache)
+
+    await executor.call("get_schema_changelog", {}, correlation_id="bad\nid")
+
+    (audited,) = _audited_ids(executor)
+    assert "\n" not in audited and audited != "bad\nid"
@@ -0,0 +1,38 @@
+"""Error contract: suggestions follow the failing parameter and data kind."""
+from cache import RedisCache
+from errors import error_catalog, missing_parameter
+from tests.fakes import FakeRedis
+from tools import ToolExecutor
+
+
+def _executor() -> ToolExecutor:
+    cache = RedisCache("redis://unused", replica_urls=[])
+    cache.client = FakeRedis()
+    return ToolExecutor(cache)
+
+
+def test_missing_parameter_suggestion_names_the_parameter():
+    error = missing_parameter("api_key").to_dict()
+
+    assert error["code"] == "MISSING_PARAMETER"
+    assert error["message"] == "Missing required parameter: api_key"
+    assert error["suggestion"] == "Provide the 'api_key' parameter"
+    assert missing_parameter("symbol", "BTCUSDT").to_dict()["suggestion"] == "Provide the 'symbol' parameter, e.g. BTCUSDT"
+
+
+async def test_tools_report_the_parameter_they_miss():
+    executor = _executor()
+
+    explain = await executor.call("explain_metric", {})
+    watch = await executor.call("watch", {})
+
+    assert "'field'" in explain.structuredContent["suggestion"]
+    assert "'symbols'" in watch.structuredContent["suggestion"]
+
+
+def test_catalog_lists_data_not_found_apart_from_symbol_not_found():
+    codes = {entry["code"]: entry for entry in error_catalog()["codes"]}
+
+    assert codes["SYMBOL_NOT_FOUND"]["retry_after"] == 5
+    assert codes["DATA_NOT_FOUND"]["retry_after"] is None
+    assert "DUPLICATE_REQUEST_ID" in codes
@@ -0,0 +1,66 @@
+"""GraphQL resolvers: the per-query symbol cap and per-symbol quota charges."""
+from graphql import GraphQLError
+
+from cache import RedisCache
+from graphql_api import MAX_QUERY_SYMBOLS, QueryRoot
+from quota import QuotaLimits, QuotaManager
+from tenancy import TenantRegistry
+from tests.fakes import FakeRedis
+
+
+def _root(daily: int = 100) -> QueryRoot:
+    cache = RedisCache("redis://unused", replica_urls=[])
+    cache.client = FakeRedis()
+    quota = QuotaManager(cache, default_limits=QuotaLimits(daily=daily), overrides={}, api_keys={"k1"})
+    return QueryRoot(cache, TenantRegistry(cache, tenants_file=""), "k1", quota)
+
+
+async def _daily_used(root: QueryRoot) -> int:
+    return (await root.quota.usage("k1")).daily_used
+
+
+async def test_each_resolved_symbol_beyond_the_first_is_charged():
+    root = _root()
+
+    await root.report(None, "BTCUSDT")
+    assert await _daily_used(root) == 0  # Covered by the request's own unit
+    await root.report(None, "ETHUSDT")
+    await root.reports(None, ["SOLUSDT", "XRPUSDT"])
+    assert await _daily_used(root) == 3
+
+
+async def test_aliased_report_fields_count_against_the_cap():
+    root = _root()
+    for _ in range(MAX_QUERY_SYMBOLS):
+        await root.report(None, "BTCUSDT")
+
+    try:
+        await root.report(None, "BTCUSDT")
+    except GraphQLError as e:
+        assert e.extensions["code"] == "INVALID_PARAMETER"
+    else:
+        raise AssertionError("expected the symbol cap to be enforced")
+
+
+async def test_oversized_reports_list_is_rejected_before_reading():
+    root = _root()
+
+    try:
+        await root.reports(None, ["BTCUSDT"] * (MAX_QUERY_SYMBOLS + 1))
+    except GraphQLError as e:
+        assert e.extensions["code"] == "INVALID_PARAMETER"
+    else:
+        raise AssertionError("expected the symbol cap to be enforced")
+    assert await _daily_used(root) == 0
+
+
+async def test_exhausted_quota_stops_resolving_symbols():
+    root = _root(daily=2)
+
+    await root.reports(None, ["BTCUSDT", "ETHUSDT"])
+    try:
+        await root.reports(None, ["SOLUSDT", "XRPUSDT"])
+    except GraphQLError as e:
+        assert e.extensions["code"] == "QUOTA_EXCEEDED"
+    else:
+        raise AssertionError("expected the quota to be enforced")
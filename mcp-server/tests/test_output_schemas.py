@@ -0,0 +1,31 @@
+"""Advertised output schemas accept results in every field naming."""
+import json
+
+import jsonschema
+
+import field_naming
+import output_schemas
+from errors import ErrorResponse, SYMBOL_NOT_FOUND
+from metric_catalog import SAMPLE_FILE
+
+
+def _sample_report() -> dict:
+    with open(SAMPLE_FILE) as f:
+        return json.load(f)
+
+
+def test_advertised_schema_accepts_every_naming():
+    schema = output_schemas.advertised_schema("get_report")
+    report = _sample_report()
+    error = ErrorResponse(SYMBOL_NOT_FOUND, "Symbol 'XYZUSDT' not found in cache").to_dict()
+
+    for naming in field_naming.FIELD_NAMINGS:
+        jsonschema.validate(field_naming.rename(report, naming), schema)
+        jsonschema.validate(field_naming.rename(error, naming), schema)
+
+
+def test_renamed_report_fails_the_original_schema():
+    renamed = field_naming.rename(_sample_report(), "snake_case")
+
+    assert "schema_version" in renamed
+    assert list(jsonschema.Draft202012Validator(output_schemas.output_schema("get_report")).iter_errors(renamed))
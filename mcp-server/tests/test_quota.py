@@ -0,0 +1,76 @@
+"""Quotas: the anonymous bucket, rejection of unknown keys and uncharged invalid requests."""
+import rest_server
+from audit import api_key_var
+from cache import RedisCache
+from quota import QuotaLimits, QuotaManager
+from tests.fakes import FakeRedis
+from tools import ToolExecutor
+
+
+def _quota(**kwargs) -> QuotaManager:
+    cache = RedisCache("redis://unused", replica_urls=[])
+    cache.client = FakeRedis()
+    options = {"default_limits": QuotaLimits(daily=2), "overrides": {}, "api_keys": {"known-key"}}
+    return QuotaManager(cache, **{**options, **kwargs})
+
+
+async def test_requests_without_a_key_share_the_anonymous_bucket():
+    quota = _quota()
+
+    assert await quota.check(None) is None
+    assert await quota.check("") is None
+    error = await quota.check(None)
+    assert error.code == "QUOTA_EXCEEDED"
+    assert error.details["api_key"] == "anonymous"
+
+
+async def test_unknown_keys_are_rejected_while_quotas_are_on():
+    quota = _quota()
+
+    assert (await quota.check("made-up-key")).code == "UNKNOWN_API_KEY"
+    assert await quota.check("known-key") is None
+
+
+async def test_anonymous_limits_apply_separately():
+    quota = _quota(anonymous_limits=QuotaLimits())
+
+    for _ in range(3):
+        assert await quota.check(None) is None
+
+
+async def test_no_quotas_accept_any_key():
+    quota = _quota(default_limits=QuotaLimits(), anonymous_limits=QuotaLimits())
+
+    assert await quota.check("made-up-key") is None
+    assert await quota.check(None) is None
+
+
+async def test_tool_calls_rejected_for_their_arguments_are_not_charged():
+    quota = _quota()
+    executor = ToolExecutor(quota.cache)
+    executor.quota = quota
+    token = api_key_var.set("known-key")
+    try:
+        await executor.call("get_report", {})
+        await executor.call("get_report", {"symbol": "not a symbol"})
+        await executor.call("get_report", {"symbol": "BTCUSDT"})
+    finally:
+        api_key_var.reset(token)
+
+    assert (await quota.usage("known-key")).daily_used == 1
+
+
+async def test_rest_reports_with_invalid_parameters_are_not_charged():
+    quota = _quota()
+    rest_server.quota = quota
+    token = api_key_var.set("known-key")
+    try:
+        _, outcome = await rest_server._report_response("")
+        assert outcome == "MISSING_PARAMETER"
+        _, outcome = await rest_server._report_response("btc")
+        assert outcome == "INVALID_SYMBOL"
+    finally:
+        api_key_var.reset(token)
+        rest_server.quota = None
+
+    assert (await quota.usage("known-key")).daily_used == 0
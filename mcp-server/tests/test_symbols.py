@@ -0,0 +1,26 @@
+"""Symbol listings skip the producer's writer lease keys."""
+from cache import RedisCache
+from graphql_api import QueryRoot
+from tenancy import TenantRegistry
+from tests.fakes import FakeRedis
+
+
+async def _cache() -> RedisCache:
+    cache = RedisCache("redis://unused", replica_urls=[])
+    cache.client = FakeRedis()
+    for key in ("report:BTCUSDT", "report:ETHUSDT", "report:writer:BTCUSDT", "report:writer:deep:BTCUSDT"):
+        await cache.client.hset(key, "_sections", "[]")
+    return cache
+
+
+async def test_list_symbols_skips_writer_leases():
+    cache = await _cache()
+
+    assert await cache.list_symbols() == ["BTCUSDT", "ETHUSDT"]
+
+
+async def test_graphql_symbols_skip_writer_leases():
+    cache = await _cache()
+    root = QueryRoot(cache, TenantRegistry(cache, tenants_file=""), None)
+
+    assert await root.symbols(None) == ["BTCUSDT", "ETHUSDT"]
@@ -0,0 +1,63 @@
+"""Tenant loading: validation, fail-closed start and keeping the last good set."""
+import json
+
+from cache import RedisCache
+from tenancy import TENANTS_KEY, TenantRegistry
+from tests.fakes import FakeRedis
+
+GOOD = {"tenants": [{"name": "desk-a", "api_keys": ["key1"], "symbols": ["BTCUSDT"]}]}
+
+
+def _registry() -> TenantRegistry:
+    cache = RedisCache("redis://unused", replica_urls=[])
+    cache.client = FakeRedis()
+    return TenantRegistry(cache, tenants_file="")
+
+
+async def test_unloadable_configuration_denies_every_request():
+    registry = _registry()
+    await registry.cache.client.set(TENANTS_KEY, "{not json")
+
+    assert not await registry.load()
+    assert await registry.check_symbol("key1", "BTCUSDT") is not None
+    assert await registry.check_report("key1", {"venue": "BINANCE"}) is not None
+    assert await registry.visible_symbols("key1", ["BTCUSDT"]) == []
+
+
+async def test_malformed_entries_are_rejected_as_a_whole():
+    registry = _registry()
+    for tenants in ([{"api_keys": ["key1"]}], [{"name": "desk-a", "symbols": "BTCUSDT"}], "desk-a"):
+        await registry.cache.client.set(TENANTS_KEY, json.dumps({"tenants": tenants}))
+        assert not await registry.load()
+        assert await registry.check_symbol("key1", "BTCUSDT") is not None
+
+
+async def test_refresh_failure_keeps_the_last_good_set():
+    registry = _registry()
+    await registry.cache.client.set(TENANTS_KEY, json.dumps(GOOD))
+    assert await registry.load()
+
+    await registry.cache.client.set(TENANTS_KEY, json.dumps({"tenants": [{"symbols": ["*"]}]}))
+    assert not await registry.load()
+
+    assert await registry.check_symbol("key1", "BTCUSDT") is None
+    assert await registry.check_symbol("key1", "ETHUSDT") is not None
+
+
+async def test_failed_loads_back_off_before_retrying():
+    registry = _registry()
+    await registry.cache.client.set(TENANTS_KEY, "{not json")
+    await registry.load()
+    await registry.cache.client.set(TENANTS_KEY, json.dumps(GOOD))
+
+    # The retry is not due yet, so the fixed configuration is not picked up
+    assert await registry.check_symbol("key1", "BTCUSDT") is not None
+    registry._next_load_at = 0.0
+    assert await registry.check_symbol("key1", "BTCUSDT") is None
+
+
+async def test_no_configuration_allows_everything():
+    registry = _registry()
+
+    assert await registry.load()
+    assert await registry.check_symbol(None, "BTCUSDT") is None
@@ -0,0 +1,76 @@
+"""Tool call results: structured content and isError on every exit path."""
+import asyncio
+
+import jsonschema
+from mcp.types import CallToolResult
+
+import limits
+import output_schemas
+from audit import api_key_var
+from cache import RedisCache
+from quota import QuotaLimits, QuotaManager
+from sessions import session_id_var
+from tests.fakes import FakeRedis
+from tools import ToolExecutor
+
+
+def _executor() -> ToolExecutor:
+    cache = RedisCache("redis://unused", replica_urls=[])
+    cache.client = FakeRedis()
+    return ToolExecutor(cache)
+
+
+def _assert_structured_error(result, code: str) -> None:
+    assert result.isError
+    assert result.structuredContent["code"] == code
+    jsonschema.validate(result.structuredContent, output_schemas.output_schema("get_report"))
+
+
+async def test_overloaded_call_returns_structured_error():
+    executor = _executor()
+    executor.limiter = limits.ConcurrencyLimiter(max_concurrent=1, max_queued=0, max_per_session=0)
+    slot = await executor.limiter.acquire()
+    try:
+        result = await executor.call("get_report", {"symbol": "BTCUSDT"})
+    finally:
+        executor.limiter.release(slot)
+
+    _assert_structured_error(result, "OVERLOADED")
+    assert result.structuredContent["retry_after"]
+
+
+async def test_exceeded_quota_returns_structured_error():
+    executor = _executor()
+    executor.quota = QuotaManager(executor.cache, default_limits=QuotaLimits(daily=1), overrides={}, api_keys={"k1"})
+    token = api_key_var.set("k1")
+    try:
+        await executor.call("get_report", {"symbol": "BTCUSDT"})
+        result = await executor.call("get_report", {"symbol": "BTCUSDT"})
+    finally:
+        api_key_var.reset(token)
+
+    _assert_structured_error(result, "QUOTA_EXCEEDED")
+    assert result.structuredContent["details"]["period"] == "daily"
+
+
+async def test_invalid_field_naming_returns_structured_error():
+    result = await _executor().call("get_report", {"symbol": "BTCUSDT", "field_naming": "kebab"})
+
+    _assert_structured_error(result, "INVALID_PARAMETER")
+
+
+async def test_waiting_watch_gives_its_slot_back():
+    executor = _executor()
+    executor.limiter = limits.ConcurrencyLimiter(max_concurrent=1, max_queued=0, max_per_session=1)
+    token = session_id_var.set("session-1")
+    try:
+        waiting = asyncio.create_task(executor.call("watch", {"symbols": ["BTCUSDT"], "wait_ms": 1000}))
+        await asyncio.sleep(0.05)
+        result = await executor.call("get_report", {"symbol": "BTCUSDT"})
+        assert not (getattr(result, "isError", False) and result.structuredContent["code"] == "OVERLOADED")
+        watched = await waiting
+    finally:
+        session_id_var.reset(token)
+
+    assert not isinstance(watched, CallToolResult)
+    assert executor.limiter.in_flight == 0
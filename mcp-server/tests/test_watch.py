@@ -0,0 +1,39 @@
+"""Watch rule and criteria parsing: non-finite numbers are rejected like the producer does."""
+import math
+
+import watch
+
+
+def _rejected(parse, raw) -> bool:
+    try:
+        parse(raw)
+    except ValueError:
+        return True
+    return False
+
+
+def test_tool_rules_reject_non_finite_numbers():
+    for value in (math.nan, math.inf):
+        assert _rejected(watch.parse_rules, [{"type": "price_cross", "level": value}])
+        assert _rejected(watch.parse_rules, [{"type": "imbalance_flip", "min_abs": value}])
+        assert _rejected(watch.parse_rules, [{"type": "health_below", "threshold": value}])
+
+
+def test_query_rules_reject_non_finite_numbers():
+    for value in ("nan", "inf", "-inf"):
+        assert _rejected(watch.rules_from_query, {"price_cross": value})
+        assert _rejected(watch.rules_from_query, {"imbalance_flip": value})
+        assert _rejected(watch.rules_from_query, {"health_below": value})
+
+
+def test_criteria_reject_non_finite_numbers():
+    name = watch.NUMERIC_CRITERIA[0]
+    assert _rejected(watch.criteria_from_query, {name: "nan"})
+    assert _rejected(watch.criteria_from_query, {name: "inf"})
+    assert _rejected(watch.parse_criteria, {name: math.nan})
+
+
+def test_finite_rules_are_kept():
+    rules = watch.rules_from_query({"price_cross": "50000,60000", "health_below": "40"})
+
+    assert [rule["type"] for rule in rules] == ["price_cross", "price_cross", "health_below"]
@@ -0,0 +1,118 @@
+"""
+Timestamp rendering in tool responses.
+
+Reports and tapes are cached with canonical timestamps: RFC3339 strings in
+UTC at microsecond precision (generated_at, ingestion.last_update, trade
+times, ...) and a few epoch-millisecond integers (updatedAt, updated_at).
+Long fractional seconds are easy for agent prompts to mangle, so tools
+render timestamps at the edge, leaving the cache as it is:
+
+    rfc3339   RFC3339 strings; as cached unless a timezone is given, in
+              which case they are re-rendered at millisecond precision
+              with that zone's offset
+    epoch_ms  integer Unix milliseconds
+
+Epoch-millisecond fields are already integers and are left as they are in
+both formats. The defaults come from TIME_FORMAT and TIME_ZONE; tools
+accept time_format and timezone arguments per call.
+"""
+import os
+import re
+from dataclasses import dataclass
+from datetime import datetime, timedelta, timezone as dt_timezone
+from typing import Any
+from zoneinfo import ZoneInfo, ZoneInfoNotFoundError
+
+TIME_FORMATS = ("rfc3339", "epoch_ms")
+
+_EPOCH = datetime(1970, 1, 1, tzinfo=dt_timezone.utc)
+
+_RFC3339 = re.compile(r"^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})$")
+
+TIME_FORMAT_PROPERTY = {
+    "type": "string",
+    "enum": list(TIME_FORMATS),
+    "description": (
+        "Timestamp rendering: rfc3339 strings or epoch_ms integers "
+        "(default TIME_FORMAT, rfc3339)"
+    ),
+}
+
+TIMEZONE_PROPERTY = {
+    "type": "string",
+    "description": (
+        "IANA timezone for rfc3339 timestamps, e.g. Europe/London; rendered at "
+        "millisecond precision with the zone's offset (default TIME_ZONE, UTC as cached)"
+    ),
+}
+
+
+def default_format() -> str:
+    """Timestamp format used when a tool call doesn't pass time_format (TIME_FORMAT)."""
+    return os.getenv("TIME_FORMAT", "rfc3339")
+
+
+def default_timezone() -> str:
+    """Timezone used when a tool call doesn't pass timezone (TIME_ZONE; empty = as cached)."""
+    return os.getenv("TIME_ZONE", "")
+
+
+def parse_timestamp(text: str) -> datetime:
+    """Parse an RFC3339 timestamp, truncating fractional seconds beyond microseconds."""
+    text = text.replace("Z", "+00:00")
+    head, dot, rest = text.partition(".")
+    if dot:
+        digits = len(rest) - len(rest.lstrip("0123456789"))
+        text = f"{head}.{rest[:min(digits, 6)]}{rest[digits:]}"
+    return datetime.fromisoformat(text)
+
+
+@dataclass(frozen=True)
+class TimeRendering:
+    """How a tool response renders RFC3339 timestamps."""
+
+    time_format: str = "rfc3339"
+    zone: ZoneInfo | None = None
+
+    @property
+    def canonical(self) -> bool:
+        """Whether timestamps are returned as cached."""
+        return self.time_format == "rfc3339" and self.zone is None
+
+    def render(self, value: Any) -> Any:
+        """Copy of a response with its RFC3339 timestamps rendered (unchanged if canonical)."""
+        if self.canonical:
+            return value
+        if isinstance(value, dict):
+            return {key: self.render(item) for key, item in value.items()}
+        if isinstance(value, list):
+            return [self.render(item) for item in value]
+        if isinstance(value, str) and _RFC3339.match(value):
+            return self._timestamp(parse_timestamp(value))
+        return value
+
+    def _timestamp(self, moment: datetime) -> str | int:
+        if self.time_format == "epoch_ms":
+            return (moment - _EPOCH) // timedelta(milliseconds=1)
+        local = moment.astimezone(self.zone or dt_timezone.utc)
+        return local.isoformat(timespec="milliseconds").replace("+00:00", "Z")
+
+
+def from_arguments(arguments: dict[str, Any]) -> tuple[TimeRendering | None, str | None]:
+    """Timestamp rendering requested by a tool call.
+
+    Returns:
+        (rendering, None), or (None, error message) for an invalid argument
+    """
+    time_format = arguments.get("time_format", default_format())
+    if time_format not in TIME_FORMATS:
+        return None, f"time_format must be one of {', '.join(TIME_FORMATS)}, got {time_format!r}"
+
+    zone_name = arguments.get("timezone", default_timezone())
+    if not isinstance(zone_name, str):
+        return None, f"timezone must be an IANA timezone name, got {zone_name!r}"
+    try:
+        zone = ZoneInfo(zone_name) if zone_name else None
+    except (ZoneInfoNotFoundError, ValueError):
+        return None, f"Unknown timezone: {zone_name!r}"
+    return TimeRendering(time_format, zone), None
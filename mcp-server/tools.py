@@ -0,0 +1,1368 @@
+"""
+Tool definitions and execution shared by the stdio and SSE MCP servers.
+"""
+import asyncio
+import json
+import logging
+import os
+import re
+import time
+from contextvars import ContextVar
+from typing import Any
+
+from mcp.types import CallToolResult, Tool, TextContent
+
+import book_history
+import completeness
+import depth_chart
+import errors
+import field_naming
+import history_store
+import limits
+import metric_catalog
+import metrics
+import output_schemas
+import pipeline
+import popularity
+import precision
+import report_versions
+import slo
+import time_format
+import watch
+from audit import AuditLog, api_key_var
+from cache import CacheResult, CacheStatus, RedisCache
+from correlation import correlation_id_var, current_correlation_id, new_correlation_id, valid_correlation_id
+from errors import ErrorResponse
+from quota import QuotaManager
+from sessions import session_id_var
+from tenancy import TenantRegistry
+
+logger = logging.getLogger(__name__)
+
+SYMBOL_PATTERN = re.compile(r"^[A-Z0-9]+USDT$")
+VENUE_PATTERN = re.compile(r"^[A-Z0-9_]+$")
+DEFAULT_VENUE = "BINANCE"
+
+# get_trades count: default, and the cap (the producer keeps NT_TRADE_TAPE_SIZE trades, default 500)
+DEFAULT_TRADE_COUNT = 100
+MAX_TRADE_COUNT = 1000
+
+# get_digest minutes: default, and the cap (digests are kept for NT_DIGEST_TTL_SEC, default 24h)
+DEFAULT_DIGEST_MINUTES = 15
+MAX_DIGEST_MINUTES = 1440
+DEFAULT_DAILY_DAYS = 7
+# Redis keeps NT_DAILY_TTL_SEC (default 30 days); older days come from HISTORY_DIR
+MAX_DAILY_DAYS = 366
+
+# get_report_history defaults (HISTORY_DIR)
+DEFAULT_HISTORY_MINUTES = 60
+MAX_HISTORY_MINUTES = 10080
+DEFAULT_HISTORY_INTERVAL_SEC = 60
+
+# get_popular_symbols defaults
+DEFAULT_POPULARITY_DAYS = 7
+DEFAULT_POPULARITY_LIMIT = 50
+
+# watch wait_ms: default, and the cap (a waiting call holds a concurrency slot)
+DEFAULT_WATCH_WAIT_MS = 10000
+MAX_WATCH_WAIT_MS = 30000
+# How often a waiting watch call re-reads the cache
+WATCH_POLL_SEC = 0.25
+
+# Outcomes of calls rejected for their arguments, which are not charged to quota
+INVALID_REQUEST_OUTCOMES = frozenset(
+    code.code for code in (errors.MISSING_PARAMETER, errors.INVALID_PARAMETER, errors.INVALID_SYMBOL)
+)
+
+# Concurrency slot of the tool call running in this context
+call_slot_var: ContextVar[limits.Slot | None] = ContextVar("call_slot", default=None)
+
+# Opt-in to the report's provenance section (source events, timestamps and windows per section)
+VERBOSE_PROPERTY = {
+    "type": "boolean",
+    "description": (
+        "Include provenance: the source event types, latest source timestamp and "
+        "computation window behind each report section (default false)"
+    ),
+}
+
+# Refuse reports still warming up (see completeness.py)
+MIN_COMPLETENESS_PROPERTY = {
+    "type": "number",
+    "minimum": 0,
+    "maximum": 1,
+    "description": (
+        "Return REPORT_WARMING_UP instead of a report whose completeness (share of "
+        "book, depth, ticker, flow and slow-cycle sections populated) is below this (default 0)"
+    ),
+}
+
+
+def tool_definitions() -> list[Tool]:
+    """Tools exposed by the Context8 MCP server."""
+    tools = [
+        Tool(
+            name="get_report",
+            description=(
+                "Retrieve real-time market data report for a tracked symbol "
+                "including orderbook metrics, volume profile, and flow analysis"
+            ),
+            inputSchema={
+                "type": "object",
+                "properties": {
+                    "symbol": {
+                        "type": "string",
+                        "description": (
+                            "Trading symbol (e.g., BTCUSDT, ETHUSDT, "
+                            "1INCHUSDT, 1000SHIBUSDT)"
+                        ),
+                        "pattern": "^[A-Z0-9]+USDT$",
+                    },
+                    "verbose": VERBOSE_PROPERTY,
+                    "min_completeness": MIN_COMPLETENESS_PROPERTY,
+                    "precision": precision.PRECISION_PROPERTY,
+                    "time_format": time_format.TIME_FORMAT_PROPERTY,
+                    "timezone": time_format.TIMEZONE_PROPERTY,
+                    "field_naming": field_naming.FIELD_NAMING_PROPERTY,
+                },
+                "required": ["symbol"],
+            },
+            outputSchema=output_schemas.advertised_schema("get_report"),
+        ),
+        Tool(
+            name="get_ingestion_status",
+            description=(
+                "Check data pipeline health for a symbol: ingestion status "
+                "(ok, degraded, down), data age, when each status was last "
+                "entered, uptime over the last hour, and the venue's trading "
+                "status (a halted or closed market is quiet, not down)"
+            ),
+            inputSchema={
+                "type": "object",
+                "properties": {
+                    "symbol": {
+                        "type": "string",
+                        "description": "Trading symbol (e.g., BTCUSDT)",
+                        "pattern": "^[A-Z0-9]+USDT$",
+                    },
+                    "verbose": VERBOSE_PROPERTY,
+                    "time_format": time_format.TIME_FORMAT_PROPERTY,
+                    "timezone": time_format.TIMEZONE_PROPERTY,
+                    "field_naming": field_naming.FIELD_NAMING_PROPERTY,
+                },
+                "required": ["symbol"],
+            },
+            outputSchema=output_schemas.advertised_schema("get_ingestion_status"),
+        ),
+        Tool(
+            name="get_depth_chart",
+            description=(
+                "Cumulative bid/ask depth as step curves (price -> cumulative "
+                "quantity and USD notional) out to a distance from mid, for "
+                "plotting the book or estimating slippage of a market order"
+            ),
+            inputSchema={
+                "type": "object",
+                "properties": {
+                    "symbol": {
+                        "type": "string",
+                        "description": "Trading symbol (e.g., BTCUSDT)",
+                        "pattern": "^[A-Z0-9]+USDT$",
+                    },
+                    "max_distance_bps": {
+                        "type": "number",
+                        "description": (
+                            "Distance from mid in basis points to include "
+                            f"(default {depth_chart.DEFAULT_DISTANCE_BPS:g})"
+                        ),
+                        "exclusiveMinimum": 0,
+                    },
+                    "precision": precision.PRECISION_PROPERTY,
+                    "time_format": time_format.TIME_FORMAT_PROPERTY,
+                    "timezone": time_format.TIMEZONE_PROPERTY,
+                    "field_naming": field_naming.FIELD_NAMING_PROPERTY,
+                },
+                "required": ["symbol"],
+            },
+            outputSchema=output_schemas.advertised_schema("get_depth_chart"),
+        ),
+        Tool(
+            name="get_book_history",
+            description=(
+                "Order book liquidity history as a price x time matrix (resting "
+                "bid and ask quantity per price bucket per snapshot) for "
+                "heatmap-style analysis of where liquidity sat before a move"
+            ),
+            inputSchema={
+                "type": "object",
+                "properties": {
+                    "symbol": {
+                        "type": "string",
+                        "description": "Trading symbol (e.g., BTCUSDT)",
+                        "pattern": "^[A-Z0-9]+USDT$",
+                    },
+                    "lookback_sec": {
+                        "type": "integer",
+                        "description": (
+                            "How far back to read snapshots "
+                            f"(default {book_history.DEFAULT_LOOKBACK_SEC})"
+                        ),
+                        "minimum": 1,
+                    },
+                    "bucket_bps": {
+                        "type": "number",
+                        "description": (
+                            "Price bucket width in basis points of the latest mid "
+                            f"(default {book_history.DEFAULT_BUCKET_BPS:g}; widened to "
+                            f"keep at most {book_history.MAX_PRICE_BUCKETS} buckets)"
+                        ),
+                        "exclusiveMinimum": 0,
+                    },
+                    "precision": precision.PRECISION_PROPERTY,
+                    "time_format": time_format.TIME_FORMAT_PROPERTY,
+                    "timezone": time_format.TIMEZONE_PROPERTY,
+                    "field_naming": field_naming.FIELD_NAMING_PROPERTY,
+                },
+                "required": ["symbol"],
+            },
+            outputSchema=output_schemas.advertised_schema("get_book_history"),
+        ),
+        Tool(
+            name="get_footprint",
+            description=(
+                "Footprint ladder: aggressive buy vs sell volume per price level "
+                "over a rolling window (default 5 minutes), with delta and "
+                "imbalance per level and the most imbalanced levels"
+            ),
+            inputSchema={
+                "type": "object",
+                "properties": {
+                    "symbol": {
+                        "type": "string",
+                        "description": "Trading symbol (e.g., BTCUSDT)",
+                        "pattern": "^[A-Z0-9]+USDT$",
+                    },
+                    "precision": precision.PRECISION_PROPERTY,
+                    "field_naming": field_naming.FIELD_NAMING_PROPERTY,
+                },
+                "required": ["symbol"],
+            },
+            outputSchema=output_schemas.advertised_schema("get_footprint"),
+        ),
+        Tool(
+            name="get_trades",
+            description=(
+                "Raw trade tape: the most recent trades for a symbol with price, "
+                "size, aggressor side and time, oldest first"
+            ),
+            inputSchema={
+                "type": "object",
+                "properties": {
+                    "symbol": {
+                        "type": "string",
+                        "description": "Trading symbol (e.g., BTCUSDT)",
+                        "pattern": "^[A-Z0-9]+USDT$",
+                    },
+                    "count": {
+                        "type": "integer",
+                        "description": f"Number of most recent trades (default {DEFAULT_TRADE_COUNT})",
+                        "minimum": 1,
+                        "maximum": MAX_TRADE_COUNT,
+                    },
+                    "precision": precision.PRECISION_PROPERTY,
+                    "time_format": time_format.TIME_FORMAT_PROPERTY,
+                    "timezone": time_format.TIMEZONE_PROPERTY,
+                    "field_naming": field_naming.FIELD_NAMING_PROPERTY,
+                },
+                "required": ["symbol"],
+            },
+            outputSchema=output_schemas.advertised_schema("get_trades"),
+        ),
+        Tool(
+            name="get_digest",
+            description=(
+                "Per-minute digests for recent history: OHLC, volume, VWAP, "
+                "average spread and imbalance, and anomaly counts by type for "
+                "each of the last closed minutes, oldest first"
+            ),
+            inputSchema={
+                "type": "object",
+                "properties": {
+                    "symbol": {
+                        "type": "string",
+                        "description": "Trading symbol (e.g., BTCUSDT)",
+                        "pattern": "^[A-Z0-9]+USDT$",
+                    },
+                    "minutes": {
+                        "type": "integer",
+                        "description": f"Closed minutes to return (default {DEFAULT_DIGEST_MINUTES})",
+                        "minimum": 1,
+                        "maximum": MAX_DIGEST_MINUTES,
+                    },
+                    "field_naming": field_naming.FIELD_NAMING_PROPERTY,
+                },
+                "required": ["symbol"],
+            },
+            outputSchema=output_schemas.advertised_schema("get_digest"),
+        ),
+        Tool(
+            name="get_daily_stats",
+            description=(
+                "Daily statistics for multi-day context: OHLC, volume, VWAP, "
+                "average spread, anomaly totals and ingestion uptime for each "
+                "UTC day, oldest first; today's entry covers the day so far"
+            ),
+            inputSchema={
+                "type": "object",
+                "properties": {
+                    "symbol": {
+                        "type": "string",
+                        "description": "Trading symbol (e.g., BTCUSDT)",
+                        "pattern": "^[A-Z0-9]+USDT$",
+                    },
+                    "days": {
+                        "type": "integer",
+                        "description": f"UTC days to return, including today (default {DEFAULT_DAILY_DAYS})",
+                        "minimum": 1,
+                        "maximum": MAX_DAILY_DAYS,
+                    },
+                    "field_naming": field_naming.FIELD_NAMING_PROPERTY,
+                },
+                "required": ["symbol"],
+            },
+            outputSchema=output_schemas.advertised_schema("get_daily_stats"),
+        ),
+        Tool(
+            name="get_venue_status",
+            description=(
+                "Venue-wide market stress: a 0-100 stress index and level (calm, "
+                "elevated, stressed) from the share of tracked symbols with widened "
+                "spreads, degraded ingestion or flash crash risk, with the symbols "
+                "behind each, to tell a venue-wide problem from a single-symbol one"
+            ),
+            inputSchema={
+                "type": "object",
+                "properties": {
+                    "venue": {
+                        "type": "string",
+                        "description": f"Venue (default {DEFAULT_VENUE})",
+                        "pattern": "^[A-Z0-9_]+$",
+                    },
+                    "field_naming": field_naming.FIELD_NAMING_PROPERTY,
+                },
+            },
+            outputSchema=output_schemas.advertised_schema("get_venue_status"),
+        ),
+        Tool(
+            name="get_schema_changelog",
+            description=(
+                "Get the machine-readable changelog of report fields by schema version "
+                "(fields added, removed and renamed), optionally only versions newer than "
+                "a given one, so clients can adapt to report changes"
+            ),
+            inputSchema={
+                "type": "object",
+                "properties": {
+                    "since": {
+                        "type": "string",
+                        "description": "Only versions newer than this major.minor version (e.g. 1.1)",
+                    },
+                    "field_naming": field_naming.FIELD_NAMING_PROPERTY,
+                },
+            },
+            outputSchema=output_schemas.advertised_schema("get_schema_changelog"),
+        ),
+        Tool(
+            name="explain_metric",
+            description=(
+                "Explain a report field: its definition, formula, window, units and "
+                "caveats (e.g. micro_price, depth.imbalance, flow.windows.1m.net_flow). "
+                "A section name (e.g. depth) lists the documented fields under it"
+            ),
+            inputSchema={
+                "type": "object",
+                "properties": {
+                    "field": {
+                        "type": "string",
+                        "description": "Field path dotted from the report root (e.g. depth.imbalance)",
+                    },
+                    "field_naming": field_naming.FIELD_NAMING_PROPERTY,
+                },
+                "required": ["field"],
+            },
+            outputSchema=output_schemas.advertised_schema("explain_metric"),
+        ),
+        Tool(
+            name="get_usage",
+            description=(
+                "Show daily and monthly request usage and quota limits for the "
+                "calling API key, or for another key when called with an admin key"
+            ),
+            inputSchema={
+                "type": "object",
+                "properties": {
+                    "api_key": {
+                        "type": "string",
+                        "description": "API key to inspect (admin keys only)",
+                    },
+                    "field_naming": field_naming.FIELD_NAMING_PROPERTY,
+                },
+            },
+            outputSchema=output_schemas.advertised_schema("get_usage"),
+        ),
+        Tool(
+            name="get_popular_symbols",
+            description=(
+                "Admin: most requested symbols over recent days, and tracked "
+                "symbols no client requested (requires an ADMIN_API_KEYS key)"
+            ),
+            inputSchema={
+                "type": "object",
+                "properties": {
+                    "days": {
+                        "type": "integer",
+                        "description": f"Days to rank over, today included (default {DEFAULT_POPULARITY_DAYS})",
+                        "minimum": 1,
+                    },
+                    "limit": {
+                        "type": "integer",
+                        "description": f"Symbols to return (default {DEFAULT_POPULARITY_LIMIT})",
+                        "minimum": 1,
+                    },
+                    "field_naming": field_naming.FIELD_NAMING_PROPERTY,
+                },
+            },
+            outputSchema=output_schemas.advertised_schema("get_popular_symbols"),
+        ),
+        Tool(
+            name="watch",
+            description=(
+                "Watch symbols for meaningful changes instead of polling full reports: "
+                "returns only change events (spread_bps moved by spread_change_pct, mid "
+                "moved by mid_change_bps, health score or imbalance moved, a new anomaly, "
+                "an ingestion status change), and threshold rules the producer checks on "
+                "every report (mid price crossing a level, imbalance flipping sign, health "
+                "dropping below a threshold). The first call registers the watch and "
+                "returns a watch_id; call again with the watch_id to wait up to wait_ms "
+                "for the next changes. Streaming clients can use /v1/stream/watch instead"
+            ),
+            inputSchema={
+                "type": "object",
+                "properties": {
+                    "symbols": {
+                        "type": "array",
+                        "description": "Symbols to watch (first call only)",
+                        "items": {"type": "string", "pattern": "^[A-Z0-9]+USDT$"},
+                        "minItems": 1,
+                        "maxItems": watch.MAX_WATCH_SYMBOLS,
+                    },
+                    "criteria": {
+                        "type": "object",
+                        "description": (
+                            "Changes to report (first call only); a number of 0 or null turns a "
+                            "criterion off"
+                        ),
+                        "properties": {
+                            "spread_change_pct": {
+                                "type": ["number", "null"],
+                                "description": "Relative change of spread_bps in percent (default 20)",
+                                "minimum": 0,
+                            },
+                            "mid_change_bps": {
+                                "type": ["number", "null"],
+                                "description": "Move of mid_price in basis points (default off)",
+                                "minimum": 0,
+                            },
+                            "health_change": {
+                                "type": ["number", "null"],
+                                "description": "Move of health.score in points (default off)",
+                                "minimum": 0,
+                            },
+                            "imbalance_change": {
+                                "type": ["number", "null"],
+                                "description": "Absolute move of depth.imbalance, -1 to 1 (default off)",
+                                "minimum": 0,
+                            },
+                            "new_anomaly": {
+                                "type": "boolean",
+                                "description": "An anomaly type/side not in the previous report (default true)",
+                            },
+                            "status_change": {
+                                "type": "boolean",
+                                "description": "ingestion.status changed (default true)",
+                            },
+                        },
+                    },
+                    "rules": {
+                        "type": "array",
+                        "description": "Threshold rules checked by the producer on every report (first call only)",
+                        "maxItems": watch.MAX_WATCH_RULES,
+                        "items": {
+                            "type": "object",
+                            "properties": {
+                                "type": {"type": "string", "enum": list(watch.RULE_FIELDS)},
+                                "level": {
+                                    "type": "number",
+                                    "description": "price_cross: mid_price level",
+                                    "exclusiveMinimum": 0,
+                                },
+                                "min_abs": {
+                                    "type": "number",
+                                    "description": "imbalance_flip: values within this of zero keep their sign (default 0.1)",
+                                    "minimum": 0,
+                                    "exclusiveMaximum": 1,
+                                },
+                                "threshold": {
+                                    "type": "number",
+                                    "description": "health_below: health score",
+                                    "exclusiveMinimum": 0,
+                                    "maximum": 100,
+                                },
+                            },
+                            "required": ["type"],
+                        },
+                    },
+                    "watch_id": {
+                        "type": "string",
+                        "description": "Id returned by the first call, to wait for the next changes",
+                    },
+                    "wait_ms": {
+                        "type": "integer",
+                        "description": (
+                            f"Milliseconds to wait for a change before returning none "
+                            f"(default {DEFAULT_WATCH_WAIT_MS})"
+                        ),
+                        "minimum": 0,
+                        "maximum": MAX_WATCH_WAIT_MS,
+                    },
+                    "field_naming": field_naming.FIELD_NAMING_PROPERTY,
+                },
+            },
+            outputSchema=output_schemas.advertised_schema("watch"),
+        ),
+    ]
+    if os.getenv("HISTORY_DIR"):
+        tools.append(Tool(
+            name="get_report_history",
+            description=(
+                "Report history beyond the cached report: mid and last price, mean "
+                "spread and imbalance, lowest health score and most anomalies per "
+                "interval, oldest first, from the producer's Parquet files (finished "
+                "hours only, so the last hour may be missing; use get_digest for it)"
+            ),
+            inputSchema={
+                "type": "object",
+                "properties": {
+                    "symbol": {
+                        "type": "string",
+                        "description": "Trading symbol (e.g., BTCUSDT)",
+                        "pattern": "^[A-Z0-9]+USDT$",
+                    },
+                    "lookback_minutes": {
+                        "type": "integer",
+                        "description": f"Minutes back from now (default {DEFAULT_HISTORY_MINUTES})",
+                        "minimum": 1,
+                        "maximum": MAX_HISTORY_MINUTES,
+                    },
+                    "interval_sec": {
+                        "type": "integer",
+                        "description": (
+                            f"Seconds per point (default {DEFAULT_HISTORY_INTERVAL_SEC}; at most "
+                            f"{history_store.MAX_HISTORY_POINTS} points per call)"
+                        ),
+                        "minimum": 1,
+                        "maximum": 86400,
+                    },
+                    "field_naming": field_naming.FIELD_NAMING_PROPERTY,
+                },
+                "required": ["symbol"],
+            },
+            outputSchema=output_schemas.advertised_schema("get_report_history"),
+        ))
+    return tools
+
+
+def _error_content(error: ErrorResponse) -> list[TextContent]:
+    """Render a tool error as JSON text content."""
+    return [TextContent(
+        type="text",
+        text=json.dumps(error.to_dict(), indent=2)
+    )]
+
+
+def _renamed_content(content: list[TextContent], naming: str) -> list[TextContent]:
+    """Re-render JSON text content with field names in the requested naming, keeping its indentation."""
+    text = content[0].text
+    renamed = field_naming.rename(json.loads(text), naming)
+    return [TextContent(type="text", text=json.dumps(renamed, indent=2 if text.startswith("{\n") else None))]
+
+
+class ToolExecutor:
+    """Executes MCP tool calls against the report cache."""
+
+    def __init__(self, cache: RedisCache):
+        self.cache = cache
+        self.audit = AuditLog(cache)
+        self.tenants = TenantRegistry(cache)
+        self.quota = QuotaManager(cache, tenants=self.tenants)
+        self.popularity = popularity.SymbolPopularity(cache)
+        self.limiter = limits.ConcurrencyLimiter()
+        self.history = history_store.from_env()
+        self.pipeline = pipeline.PipelineProbe(cache)
+        self.watches = watch.WatchRegistry()
+        self.admin_api_keys = {
+            k.strip() for k in os.getenv("ADMIN_API_KEYS", "").split(",") if k.strip()
+        }
+        self.handlers = {
+            "get_report": self._get_report,
+            "get_ingestion_status": self._get_ingestion_status,
+            "get_depth_chart": self._get_depth_chart,
+            "get_book_history": self._get_book_history,
+            "get_footprint": self._get_footprint,
+            "get_trades": self._get_trades,
+            "get_digest": self._get_digest,
+            "get_daily_stats": self._get_daily_stats,
+            "get_report_history": self._get_report_history,
+            "get_venue_status": self._get_venue_status,
+            "get_schema_changelog": self._get_schema_changelog,
+            "explain_metric": self._explain_metric,
+            "get_usage": self._get_usage,
+            "get_popular_symbols": self._get_popular_symbols,
+            "watch": self._watch,
+        }
+
+    async def call(
+        self, name: str, arguments: dict[str, Any], correlation_id: str | None = None
+    ) -> list[TextContent] | tuple[list[TextContent], dict[str, Any]] | CallToolResult:
+        """Execute a tool, recording outcome and latency.
+
+        The call runs under correlation_id (the one the client sent, see
+        correlation.request_correlation_id), or a fresh ID, never the
+        connection's.
+
+        Tools with an output schema return (content, structuredContent); the
+        structured result is the JSON the text content carries. Their errors,
+        including the limiter's and quota's, come back as a CallToolResult with
+        isError set and the error object as structuredContent, since the SDK
+        rejects results of such tools that lack structured content.
+        """
+        start = time.perf_counter()
+        outcome = "ok"
+        token = correlation_id_var.set(valid_correlation_id(correlation_id) or new_correlation_id())
+        slot = None
+        slot_token = None
+
+        try:
+            handler = self.handlers.get(name)
+            if handler is None:
+                content, outcome = self._error(
+                    errors.TOOL_NOT_FOUND,
+                    f"Tool '{name}' not found. Available tools: {', '.join(self.handlers)}",
+                )
+                return content
+
+            naming = arguments.get("field_naming", field_naming.default_naming())
+            naming_error = field_naming.validate(naming)
+            if naming_error:
+                content, outcome = self._error(errors.INVALID_PARAMETER, naming_error)
+                return self._result(name, content, outcome)
+
+            try:
+                slot = await self.limiter.acquire(session_id_var.get())
+            except limits.Overloaded as e:
+                content, outcome = self._error(errors.OVERLOADED, str(e))
+                return self._result(name, content, outcome, naming)
+            slot_token = call_slot_var.set(slot)
+
+            quota_error = await self.quota.check(api_key_var.get())
+            if quota_error:
+                content, outcome = self._error_response(quota_error)
+                return self._result(name, content, outcome, naming)
+
+            content, outcome = await handler(arguments)
+            if outcome in INVALID_REQUEST_OUTCOMES:
+                # Arguments are validated by each handler; rejected calls don't use up quota
+                await self.quota.refund(api_key_var.get())
+            return self._result(name, content, outcome, naming)
+        finally:
+            if slot is not None:
+                self.limiter.release(slot)
+            if slot_token is not None:
+                call_slot_var.reset(slot_token)
+            latency_ms = (time.perf_counter() - start) * 1000
+            metrics.record_tool_call(name, outcome.lower(), latency_ms)
+            slo.record_latency(latency_ms)
+            await self.audit.record(name, arguments.get("symbol"), latency_ms, outcome.lower())
+            correlation_id_var.reset(token)
+
+    @staticmethod
+    def _result(
+        name: str, content: list[TextContent], outcome: str, naming: str = "original"
+    ) -> list[TextContent] | tuple[list[TextContent], dict[str, Any]] | CallToolResult:
+        """Tool call result in the requested naming, with structured content for schema'd tools."""
+        if naming != "original":
+            content = _renamed_content(content, naming)
+        if name not in output_schemas.OUTPUT_SCHEMAS:
+            return content
+        structured = json.loads(content[0].text)
+        if output_schemas.validation_enabled():
+            output_schemas.check_output(name, structured, naming)
+        if outcome != "ok":
+            return CallToolResult(content=content, structuredContent=structured, isError=True)
+        return content, structured
+
+    @classmethod
+    def _error(cls, code: errors.ErrorCode, message: str) -> tuple[list[TextContent], str]:
+        """Build error content for a tool call, returning content and outcome."""
+        return cls._error_response(ErrorResponse(code, message))
+
+    @staticmethod
+    def _error_response(error: ErrorResponse) -> tuple[list[TextContent], str]:
+        """Render an error for a tool call, returning content and outcome."""
+        logger.warning(f"{error.message} code={error.code} correlation_id={error.correlation_id}")
+        return _error_content(error), error.code
+
+    async def _get_schema_changelog(self, arguments: dict[str, Any]) -> tuple[list[TextContent], str]:
+        """Handle get_schema_changelog, returning content and outcome."""
+        try:
+            changelog = report_versions.changelog(arguments.get("since"))
+        except ValueError as e:
+            return self._error(errors.INVALID_PARAMETER, str(e))
+
+        return [TextContent(type="text", text=json.dumps(changelog, indent=2))], "ok"
+
+    async def _explain_metric(self, arguments: dict[str, Any]) -> tuple[list[TextContent], str]:
+        """Handle explain_metric, returning content and outcome."""
+        path = arguments.get("field")
+        if not path:
+            return self._error_response(errors.missing_parameter("field", "depth.imbalance"))
+        if not isinstance(path, str):
+            return self._error(errors.INVALID_PARAMETER, f"field must be a string, got {path!r}")
+
+        entry = metric_catalog.lookup(path)
+        if entry is None:
+            fields = metric_catalog.children(path)
+            if not fields:
+                return self._error_response(ErrorResponse(
+                    errors.INVALID_PARAMETER,
+                    f"No definition for field '{path}'",
+                    details={"suggestions": metric_catalog.suggestions(path)},
+                ))
+            entry = {"field": path, "fields": [metric_catalog.lookup(field) for field in fields]}
+
+        return [TextContent(type="text", text=json.dumps(entry, indent=2))], "ok"
+
+    async def _get_usage(self, arguments: dict[str, Any]) -> tuple[list[TextContent], str]:
+        """Handle get_usage, returning content and outcome."""
+        caller_key = api_key_var.get()
+        api_key = arguments.get("api_key") or caller_key
+
+        if not api_key:
+            return self._error_response(ErrorResponse(
+                errors.MISSING_PARAMETER,
+                "No API key: send X-API-Key or pass the api_key parameter",
+                suggestion="Send the X-API-Key header, or pass the 'api_key' parameter",
+            ))
+        if api_key != caller_key and caller_key not in self.admin_api_keys:
+            return self._error(
+                errors.FORBIDDEN, "Only admin API keys can inspect other keys' usage"
+            )
+
+        try:
+            status = await self.quota.usage(api_key)
+        except Exception as e:
+            error_msg = f"Failed to read usage: {str(e)}"
+            logger.error(error_msg, exc_info=True)
+            return self._error(errors.INTERNAL_ERROR, error_msg)
+
+        return [TextContent(type="text", text=json.dumps(status.to_dict(), indent=2))], "ok"
+
+    async def _get_popular_symbols(self, arguments: dict[str, Any]) -> tuple[list[TextContent], str]:
+        """Handle get_popular_symbols, returning content and outcome."""
+        if api_key_var.get() not in self.admin_api_keys:
+            return self._error(errors.FORBIDDEN, "get_popular_symbols requires an admin API key")
+
+        days = arguments.get("days", DEFAULT_POPULARITY_DAYS)
+        max_days = self.popularity.retention_days
+        if not isinstance(days, int) or isinstance(days, bool) or not 1 <= days <= max_days:
+            return self._error(errors.INVALID_PARAMETER, f"days must be an integer 1-{max_days}, got {days!r}")
+        limit = arguments.get("limit", DEFAULT_POPULARITY_LIMIT)
+        if not isinstance(limit, int) or isinstance(limit, bool) or limit < 1:
+            return self._error(errors.INVALID_PARAMETER, f"limit must be a positive integer, got {limit!r}")
+
+        try:
+            ranking = await self.popularity.ranking(days, limit)
+        except Exception as e:
+            error_msg = f"Failed to read symbol popularity: {str(e)}"
+            logger.error(error_msg, exc_info=True)
+            return self._error(errors.INTERNAL_ERROR, error_msg)
+
+        return [TextContent(type="text", text=json.dumps(ranking, indent=2))], "ok"
+
+    async def _watch(self, arguments: dict[str, Any]) -> tuple[list[TextContent], str]:
+        """Handle watch, returning content and outcome."""
+        wait_ms = arguments.get("wait_ms", DEFAULT_WATCH_WAIT_MS)
+        if not isinstance(wait_ms, int) or isinstance(wait_ms, bool) or not 0 <= wait_ms <= MAX_WATCH_WAIT_MS:
+            return self._error(
+                errors.INVALID_PARAMETER, f"wait_ms must be an integer 0-{MAX_WATCH_WAIT_MS}, got {wait_ms!r}"
+            )
+
+        api_key = api_key_var.get()
+        watch_id = arguments.get("watch_id")
+        if watch_id is not None:
+            current = self.watches.get(watch_id, api_key) if isinstance(watch_id, str) else None
+            if current is None:
+                return self._error(
+                    errors.INVALID_PARAMETER,
+                    f"Unknown or expired watch_id {watch_id!r}; call watch with symbols to start a new watch",
+                )
+            error = await self._register_watch(current)
+            if error:
+                return error
+        else:
+            symbols = arguments.get("symbols")
+            if not symbols:
+                return self._error_response(errors.missing_parameter("symbols", '["BTCUSDT"] (or watch_id)'))
+            if (
+                not isinstance(symbols, list) or len(symbols) > watch.MAX_WATCH_SYMBOLS
+                or not all(isinstance(symbol, str) for symbol in symbols)
+            ):
+                return self._error(
+                    errors.INVALID_PARAMETER,
+                    f"symbols must be a list of at most {watch.MAX_WATCH_SYMBOLS} symbols, got {symbols!r}",
+                )
+            for symbol in symbols:
+                error = await self._check_symbol({"symbol": symbol})
+                if error:
+                    return error
+            try:
+                criteria = watch.parse_criteria(arguments.get("criteria"))
+                rules = watch.parse_rules(arguments.get("rules"))
+            except ValueError as e:
+                return self._error(errors.INVALID_PARAMETER, str(e))
+            if not criteria.active() and not rules:
+                return self._error(errors.INVALID_PARAMETER, "criteria turn off every change and there are no rules")
+
+            current = self.watches.create(sorted(set(symbols)), criteria, api_key, rules)
+            if current is None:
+                return self._error(errors.OVERLOADED, f"{self.watches.max_watches} watches are open; retry later")
+            error = await self._register_watch(current)
+            if error:
+                return error
+
+        try:
+            events = await self._poll_watch(current, wait_ms)
+        except Exception as e:
+            error_msg = f"Failed to read reports: {str(e)}"
+            logger.error(error_msg, exc_info=True)
+            return self._error(errors.INTERNAL_ERROR, error_msg)
+
+        result = {
+            "watch_id": current.id,
+            "symbols": current.symbols,
+            "criteria": current.watcher.criteria.to_dict(),
+            "rules": current.rules,
+            "events": events,
+            "expires_in_sec": self.watches.ttl_sec,
+        }
+        return [TextContent(type="text", text=json.dumps(result, indent=2))], "ok"
+
+    async def _register_watch(self, current: watch.Watch) -> tuple[list[TextContent], str] | None:
+        """Write or refresh a watch's threshold rules for the producer (no-op without rules).
+
+        Returns:
+            None on success, or error content and outcome
+        """
+        if not current.rules:
+            return None
+        try:
+            await self.cache.register_watch(
+                current.id, watch.registration(current.symbols, current.rules, self.watches.ttl_sec)
+            )
+        except Exception as e:
+            error_msg = f"Failed to register watch rules: {str(e)}"
+            logger.error(error_msg, exc_info=True)
+            return self._error(errors.INTERNAL_ERROR, error_msg)
+        return None
+
+    async def _poll_watch(self, current: watch.Watch, wait_ms: int) -> list[dict[str, Any]]:
+        """Change events of a watch's symbols, re-reading the cache until some appear or wait_ms passes.
+
+        Threshold crossings the producer recorded since the last call come first.
+        The call's concurrency slot is given back before the first sleep, so
+        waiting watches don't hold slots other calls need.
+        """
+        api_key = api_key_var.get()
+        deadline = time.monotonic() + wait_ms / 1000
+        while True:
+            events = []
+            if current.rules:
+                for entry_id, crossing in await self.cache.read_watch_events(current.id, current.cursor):
+                    current.cursor = entry_id
+                    events.append(watch.crossing_event(crossing))
+            results = await self.cache.get_reports(current.symbols)
+            for symbol in current.symbols:
+                result = results.get(symbol)
+                if result is None or result.report is None:
+                    continue
+                if await self.tenants.check_report(api_key, result.report):
+                    continue
+                event = current.watcher.observe(symbol, result.report)
+                if event:
+                    events.append(event)
+            if events or time.monotonic() >= deadline:
+                return events
+            self._release_call_slot()
+            await asyncio.sleep(min(WATCH_POLL_SEC, max(deadline - time.monotonic(), 0)))
+
+    def _release_call_slot(self) -> None:
+        """Return the running call's concurrency slot early (call() then skips releasing it)."""
+        slot = call_slot_var.get()
+        if slot is not None:
+            self.limiter.release(slot)
+
+    async def _get_venue_status(self, arguments: dict[str, Any]) -> tuple[list[TextContent], str]:
+        """Handle get_venue_status, returning content and outcome."""
+        venue = arguments.get("venue", DEFAULT_VENUE)
+        if not isinstance(venue, str) or not VENUE_PATTERN.match(venue):
+            return self._error(errors.INVALID_PARAMETER, f"venue must match ^[A-Z0-9_]+$, got {venue!r}")
+
+        api_key = api_key_var.get()
+        denied = await self.tenants.check_report(api_key, {"venue": venue})
+        if denied:
+            return self._error(errors.NOT_ENTITLED, denied)
+
+        try:
+            status = await self.cache.get_venue_report(venue)
+        except Exception as e:
+            error_msg = f"Failed to read venue status: {str(e)}"
+            logger.error(error_msg, exc_info=True)
+            return self._error(errors.INTERNAL_ERROR, error_msg)
+
+        if status is None:
+            return self._error(errors.DATA_NOT_FOUND, f"No venue status published for '{venue}'")
+
+        # Only name symbols the caller is entitled to
+        stressed = status.get("stressed_symbols") or {}
+        for component, symbols in stressed.items():
+            stressed[component] = await self.tenants.visible_symbols(api_key, symbols)
+
+        return [TextContent(type="text", text=json.dumps(status))], "ok"
+
+    async def _check_symbol(self, arguments: dict[str, Any]) -> tuple[list[TextContent], str] | None:
+        """Validate the symbol argument and the caller's entitlement to it.
+
+        Returns:
+            None if valid, or error content and outcome
+        """
+        # Get symbol from arguments
+        symbol = arguments.get("symbol")
+        if not symbol:
+            return self._error_response(errors.missing_parameter("symbol", "BTCUSDT"))
+
+        # Validate symbol pattern
+        if not SYMBOL_PATTERN.match(symbol):
+            error_msg = f"Invalid symbol format: {symbol}. Must match pattern: ^[A-Z0-9]+USDT$"
+            return self._error(errors.INVALID_SYMBOL, error_msg)
+
+        denied = await self.tenants.check_symbol(api_key_var.get(), symbol)
+        if denied:
+            return self._error(errors.NOT_ENTITLED, denied)
+        return None
+
+    async def _lookup_report(
+        self, arguments: dict[str, Any], source: str
+    ) -> tuple[CacheResult | None, tuple[list[TextContent], str] | None]:
+        """Validate the symbol argument and read its report from the cache.
+
+        Returns:
+            (result, None) on success, or (None, error content and outcome)
+        """
+        error = await self._check_symbol(arguments)
+        if error:
+            return None, error
+
+        symbol = arguments["symbol"]
+        await self.popularity.record(symbol)
+
+        # Get report from cache
+        try:
+            result = await self.cache.get_report(symbol)
+        except Exception as e:
+            error_msg = f"Failed to retrieve report: {str(e)}"
+            logger.error(error_msg, exc_info=True)
+            return None, self._error(errors.INTERNAL_ERROR, error_msg)
+
+        correlation_id = current_correlation_id()
+        metrics.record_cache_lookup(result)
+        await self.cache.log_request(correlation_id, source, symbol, result)
+        logger.info(
+            f"{source.split(':')[-1]} symbol={symbol} cache_status={result.status.value} "
+            f"age_ms={result.age_ms} latency_ms={result.latency_ms:.2f} "
+            f"correlation_id={correlation_id}"
+        )
+
+        if result.status == CacheStatus.MISS:
+            return None, self._error(errors.SYMBOL_NOT_FOUND, f"Symbol '{symbol}' not found in cache")
+
+        denied = await self.tenants.check_report(api_key_var.get(), result.report)
+        if denied:
+            return None, self._error(errors.NOT_ENTITLED, denied)
+
+        return result, None
+
+    async def _get_report(self, arguments: dict[str, Any]) -> tuple[list[TextContent], str]:
+        """Handle get_report, returning content and outcome."""
+        verbose = arguments.get("verbose", False)
+        if not isinstance(verbose, bool):
+            return self._error(errors.INVALID_PARAMETER, f"verbose must be a boolean, got {verbose!r}")
+
+        min_completeness = arguments.get("min_completeness", 0)
+        if (
+            not isinstance(min_completeness, (int, float)) or isinstance(min_completeness, bool)
+            or not 0 <= min_completeness <= 1
+        ):
+            return self._error(
+                errors.INVALID_PARAMETER, f"min_completeness must be a number 0-1, got {min_completeness!r}"
+            )
+        rounding = arguments.get("precision", "full")
+        problem = precision.validate(rounding)
+        if problem:
+            return self._error(errors.INVALID_PARAMETER, problem)
+        times, problem = time_format.from_arguments(arguments)
+        if problem:
+            return self._error(errors.INVALID_PARAMETER, problem)
+
+        result, error = await self._lookup_report(arguments, "tool:get_report")
+        if error:
+            return error
+
+        warming_up = completeness.warming_up_error(result.report, min_completeness)
+        if warming_up:
+            return self._error_response(warming_up)
+
+        slo.record_report_served(result.report)
+        report = result.report if verbose else report_versions.strip_verbose(result.report)
+        report = times.render(precision.apply(report, rounding, report.get("meta")))
+        # Producer health, separate from this symbol's ingestion status (see pipeline.py)
+        report = {**report, "pipeline": await self.pipeline.status()}
+
+        # Return report as formatted JSON with cache status and completeness as metadata
+        content = [TextContent(
+            type="text",
+            text=json.dumps(report, indent=2),
+            _meta={**result.to_meta(), "completeness": completeness.completeness(result.report)},
+        )]
+        return content, result.status.value
+
+    async def _get_ingestion_status(self, arguments: dict[str, Any]) -> tuple[list[TextContent], str]:
+        """Handle get_ingestion_status, returning content and outcome."""
+        verbose = arguments.get("verbose", False)
+        if not isinstance(verbose, bool):
+            return self._error(errors.INVALID_PARAMETER, f"verbose must be a boolean, got {verbose!r}")
+        times, problem = time_format.from_arguments(arguments)
+        if problem:
+            return self._error(errors.INVALID_PARAMETER, problem)
+
+        result, error = await self._lookup_report(arguments, "tool:get_ingestion_status")
+        if error:
+            return error
+
+        report = result.report
+        ingestion = report.get("ingestion") or {}
+        status = {
+            "symbol": report.get("symbol"),
+            "status": ingestion.get("status"),
+            "data_age_ms": report.get("data_age_ms"),
+            "last_update": ingestion.get("last_update"),
+            "since": ingestion.get("since"),
+            "last_transitions": ingestion.get("last_transitions"),
+            "uptime_pct_1h": ingestion.get("uptime_pct_1h"),
+            "market_status": report.get("market_status"),
+            "completeness": completeness.completeness(report),
+            "missing_sections": completeness.missing_sections(report),
+            "cache": result.to_meta(),
+            "pipeline": await self.pipeline.status(),
+        }
+        if verbose:
+            status["provenance"] = next(
+                (entry for entry in report.get("provenance") or [] if entry.get("section") == "ingestion"), None
+            )
+        status = times.render(status)
+        return [TextContent(type="text", text=json.dumps(status, indent=2))], result.status.value
+
+    async def _get_depth_chart(self, arguments: dict[str, Any]) -> tuple[list[TextContent], str]:
+        """Handle get_depth_chart, returning content and outcome."""
+        distance = arguments.get("max_distance_bps", depth_chart.DEFAULT_DISTANCE_BPS)
+        limit = depth_chart.max_distance_bps()
+        if not isinstance(distance, (int, float)) or isinstance(distance, bool) or not 0 < distance <= limit:
+            return self._error(
+                errors.INVALID_PARAMETER,
+                f"max_distance_bps must be a number in (0, {limit:g}], got {distance!r}",
+            )
+        rounding = arguments.get("precision", "full")
+        problem = precision.validate(rounding)
+        if problem:
+            return self._error(errors.INVALID_PARAMETER, problem)
+        times, problem = time_format.from_arguments(arguments)
+        if problem:
+            return self._error(errors.INVALID_PARAMETER, problem)
+
+        result, error = await self._lookup_report(arguments, "tool:get_depth_chart")
+        if error:
+            return error
+
+        if not result.report.get("mid_price"):
+            return self._error(
+                errors.INTERNAL_ERROR, f"Report for {result.report.get('symbol')} has no mid price"
+            )
+
+        chart = depth_chart.build_depth_chart(result.report, distance)
+        chart = times.render(precision.apply(chart, rounding, result.report.get("meta")))
+        chart["cache"] = result.to_meta()
+        return [TextContent(type="text", text=json.dumps(chart, indent=2))], result.status.value
+
+    async def _get_book_history(self, arguments: dict[str, Any]) -> tuple[list[TextContent], str]:
+        """Handle get_book_history, returning content and outcome."""
+        lookback_sec = arguments.get("lookback_sec", book_history.DEFAULT_LOOKBACK_SEC)
+        if not isinstance(lookback_sec, int) or isinstance(lookback_sec, bool) or lookback_sec < 1:
+            return self._error(
+                errors.INVALID_PARAMETER, f"lookback_sec must be a positive integer, got {lookback_sec!r}"
+            )
+        bucket_bps = arguments.get("bucket_bps", book_history.DEFAULT_BUCKET_BPS)
+        if not isinstance(bucket_bps, (int, float)) or isinstance(bucket_bps, bool) or bucket_bps <= 0:
+            return self._error(
+                errors.INVALID_PARAMETER, f"bucket_bps must be a positive number, got {bucket_bps!r}"
+            )
+        rounding = arguments.get("precision", "full")
+        problem = precision.validate(rounding)
+        if problem:
+            return self._error(errors.INVALID_PARAMETER, problem)
+        times, problem = time_format.from_arguments(arguments)
+        if problem:
+            return self._error(errors.INVALID_PARAMETER, problem)
+
+        result, error = await self._lookup_report(arguments, "tool:get_book_history")
+        if error:
+            return error
+
+        symbol = arguments["symbol"]
+        since_ms = int(time.time() * 1000) - lookback_sec * 1000
+        try:
+            members = await self.cache.get_book_history(symbol, since_ms)
+            snapshots = [book_history.decode_snapshot(member) for member in members]
+        except Exception as e:
+            error_msg = f"Failed to read book history: {str(e)}"
+            logger.error(error_msg, exc_info=True)
+            return self._error(errors.INTERNAL_ERROR, error_msg)
+
+        heatmap = {
+            "symbol": symbol,
+            "lookback_sec": lookback_sec,
+            **times.render(precision.apply(
+                book_history.build_heatmap(snapshots, float(bucket_bps)), rounding, result.report.get("meta")
+            )),
+            "cache": result.to_meta(),
+        }
+        return [TextContent(type="text", text=json.dumps(heatmap))], result.status.value
+
+    async def _get_footprint(self, arguments: dict[str, Any]) -> tuple[list[TextContent], str]:
+        """Handle get_footprint, returning content and outcome."""
+        rounding = arguments.get("precision", "full")
+        problem = precision.validate(rounding)
+        if problem:
+            return self._error(errors.INVALID_PARAMETER, problem)
+
+        result, error = await self._lookup_report(arguments, "tool:get_footprint")
+        if error:
+            return error
+
+        symbol = arguments["symbol"]
+        try:
+            footprint = await self.cache.get_footprint(symbol)
+        except Exception as e:
+            error_msg = f"Failed to read footprint: {str(e)}"
+            logger.error(error_msg, exc_info=True)
+            return self._error(errors.INTERNAL_ERROR, error_msg)
+
+        if footprint is None:
+            return self._error(
+                errors.DATA_NOT_FOUND,
+                f"No footprint for '{symbol}' yet (needs trades in the footprint window)",
+            )
+
+        footprint = precision.apply(footprint, rounding, result.report.get("meta"))
+        footprint["cache"] = result.to_meta()
+        return [TextContent(type="text", text=json.dumps(footprint, indent=2))], result.status.value
+
+    async def _get_trades(self, arguments: dict[str, Any]) -> tuple[list[TextContent], str]:
+        """Handle get_trades, returning content and outcome."""
+        count = arguments.get("count", DEFAULT_TRADE_COUNT)
+        if not isinstance(count, int) or isinstance(count, bool) or not 1 <= count <= MAX_TRADE_COUNT:
+            return self._error(
+                errors.INVALID_PARAMETER, f"count must be an integer 1-{MAX_TRADE_COUNT}, got {count!r}"
+            )
+        rounding = arguments.get("precision", "full")
+        problem = precision.validate(rounding)
+        if problem:
+            return self._error(errors.INVALID_PARAMETER, problem)
+        times, problem = time_format.from_arguments(arguments)
+        if problem:
+            return self._error(errors.INVALID_PARAMETER, problem)
+
+        result, error = await self._lookup_report(arguments, "tool:get_trades")
+        if error:
+            return error
+
+        symbol = arguments["symbol"]
+        try:
+            tape = await self.cache.get_trades(symbol)
+        except Exception as e:
+            error_msg = f"Failed to read trades: {str(e)}"
+            logger.error(error_msg, exc_info=True)
+            return self._error(errors.INTERNAL_ERROR, error_msg)
+
+        if tape is None:
+            return self._error(errors.DATA_NOT_FOUND, f"No trades for '{symbol}' yet")
+
+        trades = times.render(precision.apply(tape["trades"][-count:], rounding, result.report.get("meta")))
+        response = {
+            "symbol": symbol,
+            "updated_at": tape["updated_at"],
+            "count": len(trades),
+            "trades": trades,
+            "cache": result.to_meta(),
+        }
+        return [TextContent(type="text", text=json.dumps(response))], result.status.value
+
+    async def _get_digest(self, arguments: dict[str, Any]) -> tuple[list[TextContent], str]:
+        """Handle get_digest, returning content and outcome."""
+        minutes = arguments.get("minutes", DEFAULT_DIGEST_MINUTES)
+        if not isinstance(minutes, int) or isinstance(minutes, bool) or not 1 <= minutes <= MAX_DIGEST_MINUTES:
+            return self._error(
+                errors.INVALID_PARAMETER, f"minutes must be an integer 1-{MAX_DIGEST_MINUTES}, got {minutes!r}"
+            )
+
+        # Digests outlive the report, so history is served for symbols no longer published
+        error = await self._check_symbol(arguments)
+        if error:
+            return error
+
+        symbol = arguments["symbol"]
+        current_minute = int(time.time()) // 60
+        labels = [
+            time.strftime("%Y-%m-%dT%H:%M", time.gmtime(minute * 60))
+            for minute in range(current_minute - minutes, current_minute)
+        ]
+        try:
+            digests = await self.cache.get_digests(symbol, labels)
+        except Exception as e:
+            error_msg = f"Failed to read digests: {str(e)}"
+            logger.error(error_msg, exc_info=True)
+            return self._error(errors.INTERNAL_ERROR, error_msg)
+
+        if not digests:
+            return self._error(errors.DATA_NOT_FOUND, f"No digests for '{symbol}' in the last {minutes} minutes")
+
+        response = {
+            "symbol": symbol,
+            "minutes": minutes,
+            "count": len(digests),
+            "digests": digests,
+        }
+        return [TextContent(type="text", text=json.dumps(response))], "ok"
+
+    async def _get_report_history(self, arguments: dict[str, Any]) -> tuple[list[TextContent], str]:
+        """Handle get_report_history, returning content and outcome."""
+        if not self.history:
+            return self._error(errors.TOOL_NOT_FOUND, "get_report_history requires HISTORY_DIR")
+
+        lookback_minutes = arguments.get("lookback_minutes", DEFAULT_HISTORY_MINUTES)
+        if (
+            not isinstance(lookback_minutes, int) or isinstance(lookback_minutes, bool)
+            or not 1 <= lookback_minutes <= MAX_HISTORY_MINUTES
+        ):
+            return self._error(
+                errors.INVALID_PARAMETER,
+                f"lookback_minutes must be an integer 1-{MAX_HISTORY_MINUTES}, got {lookback_minutes!r}",
+            )
+        interval_sec = arguments.get("interval_sec", DEFAULT_HISTORY_INTERVAL_SEC)
+        if not isinstance(interval_sec, int) or isinstance(interval_sec, bool) or not 1 <= interval_sec <= 86400:
+            return self._error(
+                errors.INVALID_PARAMETER, f"interval_sec must be an integer 1-86400, got {interval_sec!r}"
+            )
+        if lookback_minutes * 60 / interval_sec > history_store.MAX_HISTORY_POINTS:
+            return self._error(
+                errors.INVALID_PARAMETER,
+                f"lookback_minutes / interval_sec gives more than {history_store.MAX_HISTORY_POINTS} points; "
+                "use a longer interval_sec",
+            )
+
+        # History outlives the report, so it is served for symbols no longer published
+        error = await self._check_symbol(arguments)
+        if error:
+            return error
+
+        symbol = arguments["symbol"]
+        end_ms = int(time.time() * 1000)
+        try:
+            points = await self.history.get_report_points(
+                symbol, end_ms - lookback_minutes * 60000, end_ms, interval_sec
+            )
+        except Exception as e:
+            error_msg = f"Failed to read report history: {str(e)}"
+            logger.error(error_msg, exc_info=True)
+            return self._error(errors.INTERNAL_ERROR, error_msg)
+
+        if not points:
+            return self._error(
+                errors.DATA_NOT_FOUND, f"No report history for '{symbol}' in the last {lookback_minutes} minutes"
+            )
+
+        response = {
+            "symbol": symbol,
+            "lookback_minutes": lookback_minutes,
+            "interval_sec": interval_sec,
+            "count": len(points),
+            "points": points,
+        }
+        return [TextContent(type="text", text=json.dumps(response))], "ok"
+
+    async def _get_daily_stats(self, arguments: dict[str, Any]) -> tuple[list[TextContent], str]:
+        """Handle get_daily_stats, returning content and outcome."""
+        days = arguments.get("days", DEFAULT_DAILY_DAYS)
+        if not isinstance(days, int) or isinstance(days, bool) or not 1 <= days <= MAX_DAILY_DAYS:
+            return self._error(errors.INVALID_PARAMETER, f"days must be an integer 1-{MAX_DAILY_DAYS}, got {days!r}")
+
+        error = await self._check_symbol(arguments)
+        if error:
+            return error
+
+        symbol = arguments["symbol"]
+        today = int(time.time()) // 86400
+        dates = [time.strftime("%Y-%m-%d", time.gmtime(day * 86400)) for day in range(today - days + 1, today + 1)]
+        try:
+            stats = await self.cache.get_daily_stats(symbol, dates)
+            if self.history:
+                # Days expired from Redis, recomputed from the Parquet history
+                cached = {s["date"] for s in stats}
+                missing = [date for date in dates if date not in cached]
+                if missing:
+                    stats = sorted(
+                        stats + await self.history.get_daily_stats(symbol, missing), key=lambda s: s["date"]
+                    )
+        except Exception as e:
+            error_msg = f"Failed to read daily statistics: {str(e)}"
+            logger.error(error_msg, exc_info=True)
+            return self._error(errors.INTERNAL_ERROR, error_msg)
+
+        if not stats:
+            return self._error(errors.DATA_NOT_FOUND, f"No daily statistics for '{symbol}' in the last {days} days")
+
+        response = {
+            "symbol": symbol,
+            "days": days,
+            "count": len(stats),
+            "stats": stats,
+        }
+        return [TextContent(type="text", text=json.dumps(response))], "ok"
@@ -0,0 +1,120 @@
+"""
+Startup cache warming.
+
+Before serving, the servers (stdio, SSE and REST) load the symbol
+registry (report:* keys) and read every report in one pipelined round
+trip, so the first requests after a deploy don't race cold Redis
+connections and migration paths.
+The freshest report is checked against the schema the server speaks
+(report_versions.CURRENT_VERSION and the get_report output schema), and
+a one-line startup report is logged:
+
+    Startup warm-up: 24 symbols, 24 live, 23 fresh, 1 stale (ETHUSDT), 0 unreadable;
+    schema 1.3 compatible (sample BTCUSDT) in 6.1ms
+
+Warm-up never blocks startup: an empty Redis, a timeout
+(WARMUP_TIMEOUT_SEC) or an incompatible sample is logged and the server
+starts anyway.
+"""
+import asyncio
+import logging
+import os
+import time
+from typing import Any
+
+import jsonschema
+
+import output_schemas
+import report_versions
+from cache import CacheStatus, RedisCache
+
+logger = logging.getLogger(__name__)
+
+DEFAULT_TIMEOUT_SEC = 10.0
+
+
+def check_compatibility(report: dict[str, Any]) -> list[str]:
+    """Reasons a report can't be served as CURRENT_VERSION (empty when compatible)."""
+    version = report_versions.report_version(report)
+    if version is None:
+        return ["report has no schemaVersion"]
+    if version.split(".")[0] != report_versions.CURRENT_VERSION.split(".")[0]:
+        return [f"schema {version} has an unsupported major version (server speaks {report_versions.CURRENT_VERSION})"]
+
+    validator = jsonschema.Draft202012Validator(output_schemas.REPORT_SCHEMA)
+    return [
+        f"{'/'.join(str(p) for p in error.absolute_path) or '<root>'}: {error.message}"
+        for error in validator.iter_errors(report)
+    ]
+
+
+async def warm_cache(cache: RedisCache) -> dict[str, Any]:
+    """Read every tracked symbol's report and summarize what is live and fresh."""
+    start = time.perf_counter()
+    symbols = await cache.list_symbols()
+    results = await cache.get_reports(symbols)
+
+    live = {symbol: result for symbol, result in results.items() if result.found}
+    stale = sorted(symbol for symbol, result in live.items() if result.status == CacheStatus.STALE)
+    versions: dict[str, int] = {}
+    for result in live.values():
+        version = report_versions.report_version(result.report) or "unknown"
+        versions[version] = versions.get(version, 0) + 1
+
+    summary: dict[str, Any] = {
+        "symbols": len(symbols),
+        "live": len(live),
+        "fresh": len(live) - len(stale),
+        "stale": stale,
+        "unreadable": len(symbols) - len(results),
+        "schema_versions": versions,
+        "sample": None,
+        "problems": [],
+    }
+    if live:
+        sample = min(live, key=lambda symbol: live[symbol].age_ms if live[symbol].age_ms is not None else float("inf"))
+        summary["sample"] = sample
+        summary["problems"] = check_compatibility(live[sample].report)
+    summary["duration_ms"] = round((time.perf_counter() - start) * 1000, 1)
+    return summary
+
+
+def _log_summary(summary: dict[str, Any]) -> None:
+    stale = summary["stale"]
+    stale_list = f" ({', '.join(stale[:10])})" if stale else ""
+    counts = (
+        f"Startup warm-up: {summary['symbols']} symbols, {summary['live']} live, "
+        f"{summary['fresh']} fresh, {len(stale)} stale{stale_list}, "
+        f"{summary['unreadable']} unreadable"
+    )
+    if summary["sample"] is None:
+        logger.warning(f"{counts}; no reports to check the schema against in {summary['duration_ms']}ms")
+    elif summary["problems"]:
+        logger.error(
+            f"{counts}; sample {summary['sample']} is incompatible with schema "
+            f"{report_versions.CURRENT_VERSION}: {'; '.join(summary['problems'][:5])}"
+        )
+    else:
+        versions = ", ".join(sorted(summary["schema_versions"]))
+        logger.info(
+            f"{counts}; schema {versions} compatible (sample {summary['sample']}) "
+            f"in {summary['duration_ms']}ms"
+        )
+
+
+async def run_warmup(cache: RedisCache, timeout_sec: float | None = None) -> dict[str, Any] | None:
+    """Warm the cache and log the startup report; returns the summary, or None if warm-up failed."""
+    if timeout_sec is None:
+        timeout_sec = float(os.getenv("WARMUP_TIMEOUT_SEC", str(DEFAULT_TIMEOUT_SEC)))
+    if timeout_sec <= 0:
+        return None
+    try:
+        summary = await asyncio.wait_for(warm_cache(cache), timeout_sec)
+    except asyncio.TimeoutError:
+        logger.warning(f"Startup warm-up timed out after {timeout_sec:g}s; serving cold")
+        return None
+    except Exception as e:
+        logger.warning(f"Startup warm-up failed: {e}; serving cold")
+        return None
+    _log_summary(summary)
+    return summary
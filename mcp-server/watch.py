@@ -0,0 +1,491 @@
+"""
+Change watches: notify on meaningful report changes instead of every report.
+
+A watch covers a list of symbols and a set of delta criteria. The first
+report seen for a symbol is its baseline; later reports are compared
+with it and only changes crossing a criterion are emitted. A field's
+baseline moves when a change of it is emitted, so a slow drift is
+reported once per threshold crossed rather than on every report.
+
+Criteria (numbers must be > 0; null or 0 turns a criterion off):
+
+- spread_change_pct: relative change of spread_bps (default 20)
+- mid_change_bps: move of mid_price in basis points
+- health_change: move of health.score in points
+- imbalance_change: absolute move of depth.imbalance (-1..1)
+- new_anomaly: an anomaly type/side not in the previous report (default true)
+- status_change: ingestion.status changed (default true)
+
+Anomalies are only compared on reports carrying the slow cycle's sections
+(slow_cycle_updated_at): the fast-cycle reports announced between slow
+cycles don't carry detections and would read as every anomaly clearing.
+
+A watch may also carry threshold rules, which the producer checks on every
+fast report rather than the reports this server happens to see:
+
+- {"type": "price_cross", "level": 50000}: mid_price crossed the level
+- {"type": "imbalance_flip", "min_abs": 0.1}: depth.imbalance changed sign
+  (values within min_abs of zero don't count, default 0.1)
+- {"type": "health_below", "threshold": 50}: health.score dropped below
+
+Such watches are registered in the watch:registrations Redis hash while in
+use; the producer appends each crossing to the signals stream and to
+watch:events:{watch_id}, which is read back here and emitted as a change
+with the rule and its direction (see producer/src/reporters/thresholds.py).
+
+Watches are served two ways: the `watch` tool long-polls the cache under a
+watch_id kept in this process for WATCH_TTL_SEC after its last use, and
+/v1/stream/watch pushes `change` events over SSE for as long as the
+connection stays open.
+"""
+import asyncio
+import json
+import logging
+import math
+import os
+import secrets
+import time
+from dataclasses import asdict, dataclass, field
+from typing import Any, AsyncIterator, Mapping
+
+from starlette.requests import Request
+from starlette.responses import StreamingResponse
+
+import metrics
+from audit import api_key_var
+from cache import RedisCache
+from streaming import SSE_KEEPALIVE_SEC, ReportBroadcaster, _entitled, _sse_event
+from tenancy import TenantRegistry
+
+logger = logging.getLogger(__name__)
+
+DEFAULT_WATCH_TTL_SEC = 600
+DEFAULT_MAX_WATCHES = 1000
+MAX_WATCH_SYMBOLS = 20
+MAX_WATCH_RULES = 10
+
+# Rule type -> report field it watches (producer/src/reporters/thresholds.py)
+RULE_FIELDS = {
+    "price_cross": "mid_price",
+    "imbalance_flip": "depth.imbalance",
+    "health_below": "health.score",
+}
+
+NUMERIC_CRITERIA = ("spread_change_pct", "mid_change_bps", "health_change", "imbalance_change")
+FLAG_CRITERIA = ("new_anomaly", "status_change")
+
+
+@dataclass
+class WatchCriteria:
+    """Thresholds a report change must cross to be emitted (None = not watched)."""
+
+    spread_change_pct: float | None = 20.0
+    mid_change_bps: float | None = None
+    health_change: float | None = None
+    imbalance_change: float | None = None
+    new_anomaly: bool = True
+    status_change: bool = True
+
+    def to_dict(self) -> dict[str, Any]:
+        return asdict(self)
+
+    def active(self) -> bool:
+        """Whether any criterion is on."""
+        return any(getattr(self, name) for name in NUMERIC_CRITERIA + FLAG_CRITERIA)
+
+
+def parse_criteria(raw: Any) -> WatchCriteria:
+    """Criteria from a tool argument object; raises ValueError on bad input."""
+    if raw is None:
+        return WatchCriteria()
+    if not isinstance(raw, dict):
+        raise ValueError(f"criteria must be an object, got {raw!r}")
+    unknown = sorted(set(raw) - set(NUMERIC_CRITERIA) - set(FLAG_CRITERIA))
+    if unknown:
+        raise ValueError(f"Unknown criteria: {', '.join(unknown)} (expected {', '.join(NUMERIC_CRITERIA + FLAG_CRITERIA)})")
+
+    criteria = WatchCriteria()
+    for name in NUMERIC_CRITERIA:
+        if name not in raw:
+            continue
+        value = raw[name]
+        if value is not None and (not _finite(value) or value < 0):
+            raise ValueError(f"{name} must be a finite non-negative number or null, got {value!r}")
+        setattr(criteria, name, float(value) if value else None)
+    for name in FLAG_CRITERIA:
+        if name not in raw:
+            continue
+        if not isinstance(raw[name], bool):
+            raise ValueError(f"{name} must be a boolean, got {raw[name]!r}")
+        setattr(criteria, name, raw[name])
+    return criteria
+
+
+def criteria_from_query(params: Mapping[str, str]) -> WatchCriteria:
+    """Criteria from query parameters (numbers, true/false); raises ValueError on bad input."""
+    raw: dict[str, Any] = {}
+    for name in NUMERIC_CRITERIA:
+        if name in params:
+            try:
+                raw[name] = float(params[name]) if params[name] not in ("", "null") else None
+            except ValueError:
+                raise ValueError(f"{name} must be a number, got {params[name]!r}") from None
+    for name in FLAG_CRITERIA:
+        if name in params:
+            value = params[name].lower()
+            if value not in ("true", "false"):
+                raise ValueError(f"{name} must be true or false, got {params[name]!r}")
+            raw[name] = value == "true"
+    return parse_criteria(raw)
+
+
+def _finite(value: Any) -> bool:
+    # Same check as the producer's rule parser, which drops registrations with NaN or infinite values
+    return isinstance(value, (int, float)) and not isinstance(value, bool) and math.isfinite(value)
+
+
+def parse_rules(raw: Any) -> list[dict[str, Any]]:
+    """Threshold rules from a tool argument list; raises ValueError on bad input."""
+    if raw is None:
+        return []
+    if not isinstance(raw, list) or len(raw) > MAX_WATCH_RULES:
+        raise ValueError(f"rules must be a list of at most {MAX_WATCH_RULES} rules, got {raw!r}")
+    rules = []
+    for rule in raw:
+        kind = rule.get("type") if isinstance(rule, dict) else None
+        if kind == "price_cross":
+            level = rule.get("level")
+            if not _finite(level) or level <= 0:
+                raise ValueError(f"price_cross needs a finite positive level, got {rule!r}")
+            rules.append({"type": kind, "level": float(level)})
+        elif kind == "imbalance_flip":
+            min_abs = rule.get("min_abs", 0.1)
+            if not _finite(min_abs) or not 0 <= min_abs < 1:
+                raise ValueError(f"imbalance_flip min_abs must be in [0, 1), got {rule!r}")
+            rules.append({"type": kind, "min_abs": float(min_abs)})
+        elif kind == "health_below":
+            threshold = rule.get("threshold")
+            if not _finite(threshold) or not 0 < threshold <= 100:
+                raise ValueError(f"health_below needs a threshold in (0, 100], got {rule!r}")
+            rules.append({"type": kind, "threshold": float(threshold)})
+        else:
+            raise ValueError(f"Unknown rule {rule!r} (expected type {', '.join(RULE_FIELDS)})")
+    return rules
+
+
+def rules_from_query(params: Mapping[str, str]) -> list[dict[str, Any]]:
+    """Threshold rules from query parameters; raises ValueError on bad input.
+
+    price_cross takes comma-separated levels, imbalance_flip true or a
+    min_abs, health_below a threshold.
+    """
+    raw: list[dict[str, Any]] = []
+    try:
+        for level in filter(None, params.get("price_cross", "").split(",")):
+            raw.append({"type": "price_cross", "level": float(level)})
+        flip = params.get("imbalance_flip", "false").lower()
+        if flip == "true":
+            raw.append({"type": "imbalance_flip"})
+        elif flip != "false":
+            raw.append({"type": "imbalance_flip", "min_abs": float(flip)})
+        if params.get("health_below"):
+            raw.append({"type": "health_below", "threshold": float(params["health_below"])})
+    except ValueError:
+        raise ValueError("price_cross, imbalance_flip and health_below must be numbers") from None
+    return parse_rules(raw)
+
+
+def crossing_event(event: dict[str, Any]) -> dict[str, Any]:
+    """A producer threshold crossing as a watch change event."""
+    return {
+        "symbol": event.get("symbol"),
+        "updatedAt": event.get("ts"),
+        "changes": [{
+            "field": event.get("field"),
+            "rule": event.get("rule"),
+            "from": event.get("from"),
+            "to": event.get("to"),
+            "direction": event.get("direction"),
+        }],
+    }
+
+
+def registration(symbols: list[str], rules: list[dict[str, Any]], ttl_sec: float) -> dict[str, Any]:
+    """watch:registrations entry for a watch, live for ttl_sec from now."""
+    return {"symbols": symbols, "rules": rules, "expires_at": int((time.time() + ttl_sec) * 1000)}
+
+
+def _number(report: dict[str, Any], *path: str) -> float | None:
+    value: Any = report
+    for key in path:
+        if not isinstance(value, dict):
+            return None
+        value = value.get(key)
+    return value if isinstance(value, (int, float)) and not isinstance(value, bool) else None
+
+
+def _anomaly_key(anomaly: dict[str, Any]) -> tuple[str, str]:
+    return str(anomaly.get("type")), str(anomaly.get("side"))
+
+
+@dataclass
+class _Baseline:
+    values: dict[str, float] = field(default_factory=dict)
+    anomalies: set[tuple[str, str]] | None = None
+    status: str | None = None
+    version: tuple | None = None
+
+
+class Watcher:
+    """Per-symbol baselines of a watch, turning reports into change events."""
+
+    def __init__(self, criteria: WatchCriteria):
+        self.criteria = criteria
+        self.baselines: dict[str, _Baseline] = {}
+
+    def observe(self, symbol: str, report: dict[str, Any]) -> dict[str, Any] | None:
+        """Change event for a report, or None if nothing crossed a criterion.
+
+        The first report of a symbol only sets its baseline. A report seen
+        before (same updatedAt and slow cycle) is ignored.
+        """
+        version = (report.get("updatedAt"), report.get("slow_cycle_updated_at"))
+        baseline = self.baselines.get(symbol)
+        if baseline is None:
+            baseline = self.baselines[symbol] = _Baseline()
+            self._compare(baseline, report)
+            baseline.version = version
+            return None
+        if version == baseline.version:
+            return None
+        baseline.version = version
+
+        changes = self._compare(baseline, report)
+        if not changes:
+            return None
+        for change in changes:
+            metrics.watch_changes.labels(field=change["field"]).inc()
+        return {"symbol": symbol, "updatedAt": report.get("updatedAt"), "changes": changes}
+
+    def _compare(self, baseline: _Baseline, report: dict[str, Any]) -> list[dict[str, Any]]:
+        """Changes of report against the baseline, moving the baseline of changed fields."""
+        criteria = self.criteria
+        changes = []
+
+        for name, path, threshold in (
+            ("spread_bps", ("spread_bps",), criteria.spread_change_pct),
+            ("mid_price", ("mid_price",), criteria.mid_change_bps),
+            ("health.score", ("health", "score"), criteria.health_change),
+            ("depth.imbalance", ("depth", "imbalance"), criteria.imbalance_change),
+        ):
+            if threshold is None:
+                continue
+            value = _number(report, *path)
+            if value is None:
+                continue
+            previous = baseline.values.get(name)
+            if previous is None:
+                baseline.values[name] = value
+                continue
+            change = _delta(name, previous, value, threshold)
+            if change is not None:
+                changes.append(change)
+                baseline.values[name] = value
+
+        if criteria.new_anomaly and "slow_cycle_updated_at" in report:
+            anomalies = [a for a in report.get("anomalies") or [] if isinstance(a, dict)]
+            current = {_anomaly_key(a) for a in anomalies}
+            if baseline.anomalies is not None:
+                for anomaly in anomalies:
+                    if _anomaly_key(anomaly) not in baseline.anomalies:
+                        changes.append({"field": "anomalies", "anomaly": anomaly})
+            baseline.anomalies = current
+
+        if criteria.status_change:
+            status = (report.get("ingestion") or {}).get("status")
+            if status is not None:
+                if baseline.status is not None and status != baseline.status:
+                    changes.append({"field": "ingestion.status", "from": baseline.status, "to": status})
+                baseline.status = status
+
+        return changes
+
+
+def _delta(name: str, previous: float, value: float, threshold: float) -> dict[str, Any] | None:
+    """Change entry if value moved past threshold from previous, else None."""
+    change: dict[str, Any] = {"field": name, "from": previous, "to": value}
+    if name == "spread_bps":
+        if previous == 0:
+            return change if value != 0 else None
+        pct = (value - previous) / previous * 100
+        change["change_pct"] = round(pct, 2)
+        return change if abs(pct) >= threshold else None
+    if name == "mid_price":
+        if previous <= 0:
+            return None
+        bps = (value - previous) / previous * 10000
+        change["change_bps"] = round(bps, 2)
+        return change if abs(bps) >= threshold else None
+    return change if abs(value - previous) >= threshold else None
+
+
+@dataclass(eq=False)
+class Watch:
+    """A watch registered through the watch tool."""
+
+    id: str
+    symbols: list[str]
+    watcher: Watcher
+    owner: str | None
+    rules: list[dict[str, Any]] = field(default_factory=list)
+    # Last watch:events entry read (threshold crossings; the stream is new with the watch)
+    cursor: str = "0-0"
+    expires_at: float = 0.0
+
+
+class WatchRegistry:
+    """Watches of the watch tool by id, expiring WATCH_TTL_SEC after their last use."""
+
+    def __init__(self, ttl_sec: int | None = None, max_watches: int | None = None):
+        if ttl_sec is None:
+            ttl_sec = int(os.getenv("WATCH_TTL_SEC", str(DEFAULT_WATCH_TTL_SEC)))
+        if max_watches is None:
+            max_watches = int(os.getenv("MAX_WATCHES", str(DEFAULT_MAX_WATCHES)))
+        self.ttl_sec = ttl_sec
+        self.max_watches = max_watches
+        self._watches: dict[str, Watch] = {}
+
+    def create(
+        self, symbols: list[str], criteria: WatchCriteria, owner: str | None, rules: list[dict[str, Any]] | None = None
+    ) -> Watch | None:
+        """Register a watch, or None when MAX_WATCHES are live."""
+        self._expire()
+        if self.max_watches and len(self._watches) >= self.max_watches:
+            return None
+        watch = Watch(secrets.token_urlsafe(12), symbols, Watcher(criteria), owner, rules=rules or [])
+        self._watches[watch.id] = watch
+        self.touch(watch)
+        return watch
+
+    def get(self, watch_id: str, owner: str | None) -> Watch | None:
+        """The caller's live watch with this id (None for other callers' watches)."""
+        self._expire()
+        watch = self._watches.get(watch_id)
+        if watch is None or watch.owner != owner:
+            return None
+        self.touch(watch)
+        return watch
+
+    def touch(self, watch: Watch) -> None:
+        watch.expires_at = time.monotonic() + self.ttl_sec
+
+    def _expire(self) -> None:
+        now = time.monotonic()
+        for watch_id in [w.id for w in self._watches.values() if w.expires_at <= now]:
+            del self._watches[watch_id]
+
+
+# How often an SSE watch with threshold rules reads its crossings
+WATCH_EVENTS_POLL_SEC = 1.0
+
+
+async def handle_watch_sse(
+    request: Request,
+    broadcaster: ReportBroadcaster,
+    tenants: TenantRegistry,
+    cache: RedisCache,
+    criteria: WatchCriteria,
+    rules: list[dict[str, Any]] | None = None,
+    ttl_sec: int | None = None,
+) -> StreamingResponse:
+    """
+    Stream change events for ?symbols=... as Server-Sent Events.
+
+    Events are `subscribed` (entitled symbols, the criteria and rules in
+    effect, and the watch_id rules are registered under), `change`
+    ({"symbol", "updatedAt", "changes": [...]}) and `error`. Baselines are
+    the cached reports when the stream opens; a reconnecting client starts
+    from fresh baselines, so there is nothing to resume. Threshold rules are
+    registered while the stream is open (refreshed at half their TTL) and
+    unregistered when it closes.
+    """
+    api_key = api_key_var.get() or request.query_params.get("api_key")
+    requested = request.query_params.get("symbols", "").split(",")
+    symbols, denied = await _entitled(tenants, api_key, requested[:MAX_WATCH_SYMBOLS])
+    if len(requested) > MAX_WATCH_SYMBOLS:
+        denied.append(f"At most {MAX_WATCH_SYMBOLS} symbols per watch; ignored {', '.join(requested[MAX_WATCH_SYMBOLS:])}")
+
+    if ttl_sec is None:
+        ttl_sec = int(os.getenv("WATCH_TTL_SEC", str(DEFAULT_WATCH_TTL_SEC)))
+    watcher = Watcher(criteria)
+    rules = rules or []
+    watch_id = secrets.token_urlsafe(12) if rules and symbols else None
+    subscriber = broadcaster.add(symbols)
+
+    async def events() -> AsyncIterator[str]:
+        metrics.watch_streams.inc()
+        cursor = "0-0"  # The watch's own stream, new with the watch
+        registered_at = polled_at = 0.0
+        last_sent = time.monotonic()
+        try:
+            for reason in denied:
+                yield _sse_event("error", json.dumps({"code": "NOT_ENTITLED", "message": reason}))
+            yield _sse_event("subscribed", json.dumps({
+                "symbols": sorted(symbols), "criteria": criteria.to_dict(), "rules": rules, "watch_id": watch_id,
+            }))
+
+            try:
+                seeds = await cache.get_reports(sorted(symbols))
+            except Exception as e:
+                logger.warning(f"Failed to read watch baselines, starting from the next reports: {e}")
+                seeds = {}
+            for symbol, result in seeds.items():
+                if result.report:
+                    watcher.observe(symbol, result.report)
+
+            while not subscriber.dropped.is_set():
+                if watch_id and time.monotonic() - polled_at >= WATCH_EVENTS_POLL_SEC:
+                    polled_at = time.monotonic()
+                    try:
+                        if time.monotonic() - registered_at >= ttl_sec / 2:
+                            await cache.register_watch(watch_id, registration(sorted(symbols), rules, ttl_sec))
+                            registered_at = time.monotonic()
+                        crossings = await cache.read_watch_events(watch_id, cursor)
+                    except Exception as e:
+                        logger.warning(f"Failed to sync watch {watch_id} threshold rules: {e}")
+                        crossings = []
+                    for entry_id, crossing in crossings:
+                        cursor = entry_id
+                        last_sent = time.monotonic()
+                        yield _sse_event("change", json.dumps(crossing_event(crossing)))
+
+                timeout = WATCH_EVENTS_POLL_SEC if watch_id else SSE_KEEPALIVE_SEC
+                try:
+                    update = await asyncio.wait_for(subscriber.queue.get(), timeout)
+                except asyncio.TimeoutError:
+                    if time.monotonic() - last_sent >= SSE_KEEPALIVE_SEC:
+                        last_sent = time.monotonic()
+                        yield ": keepalive\n\n"
+                    continue
+                try:
+                    report = json.loads(update.payload)
+                except ValueError:
+                    continue
+                event = watcher.observe(update.symbol, report)
+                if event:
+                    last_sent = time.monotonic()
+                    yield _sse_event("change", json.dumps(event))
+        finally:
+            broadcaster.remove(subscriber)
+            metrics.watch_streams.dec()
+            if watch_id:
+                try:
+                    await cache.unregister_watch(watch_id)
+                except Exception as e:
+                    logger.warning(f"Failed to unregister watch {watch_id}; it expires with its TTL: {e}")
+
+    return StreamingResponse(
+        events(),
+        media_type="text/event-stream",
+        headers={"Cache-Control": "no-cache", "X-Accel-Buffering": "no"},
+    )
@@ -0,0 +1,78 @@
+"""Memory and query time of FlowBuckets under a sustained trade rate.
+
+Feeds one symbol's counters at --rate trades per (simulated) second for
+--seconds seconds and prints, per simulated minute, the memory traced
+since the counters were created and the time to compute the default flow
+windows. Memory is sized from the horizon, so it should stay flat once
+every slot has been written (after the first horizon_sec of trades):
+
+    cd producer && python -m benchmarks.flow_buckets --rate 10000 --seconds 600
+
+Exits with status 1 if traced memory at the end exceeds the level reached
+after the first horizon by more than --tolerance.
+"""
+import argparse
+import random
+import sys
+import time
+import tracemalloc
+from datetime import datetime, timedelta, timezone
+
+from src.calculators.flow import DEFAULT_FLOW_WINDOWS
+from src.state.flow_buckets import FlowBuckets
+
+START = datetime(2026, 1, 1, tzinfo=timezone.utc)
+
+
+def run(rate: int, seconds: int, horizon_sec: int, tolerance: float) -> bool:
+    """Run the benchmark, printing one line per simulated minute; True if memory stayed flat."""
+    rng = random.Random(7)
+    tracemalloc.start()
+    buckets = FlowBuckets(horizon_sec)
+    baseline = None
+    current = 0
+
+    print(f"{'sim_sec':>8} {'trades':>10} {'traced_kb':>10} {'peak_kb':>10} {'windows_ms':>11} {'feed_us':>8}")
+    for second in range(seconds):
+        start = time.perf_counter()
+        base = START + timedelta(seconds=second)
+        for i in range(rate):
+            buckets.add(base + timedelta(microseconds=i * 1_000_000 // rate), rng.random(), "BUY" if i % 2 else "SELL")
+        add_sec = time.perf_counter() - start
+
+        if (second + 1) % 60 == 0 or second + 1 == seconds:
+            now = base + timedelta(milliseconds=999)
+            start = time.perf_counter()
+            for window in DEFAULT_FLOW_WINDOWS:
+                buckets.window(window, now=now)
+            windows_ms = (time.perf_counter() - start) * 1000
+            current, peak = tracemalloc.get_traced_memory()
+            print(
+                f"{second + 1:>8} {(second + 1) * rate:>10} {current / 1024:>10.1f} {peak / 1024:>10.1f} "
+                f"{windows_ms:>11.3f} {add_sec / rate * 1e6:>8.2f}"
+            )
+        if second + 1 == horizon_sec:
+            baseline = tracemalloc.get_traced_memory()[0]
+
+    tracemalloc.stop()
+    if baseline is None:
+        print(f"Run for more than horizon_sec ({horizon_sec}s) to compare memory against the filled counters")
+        return True
+    growth = (current - baseline) / baseline
+    print(f"Memory after the first {horizon_sec}s: {baseline / 1024:.1f} KB, at the end: "
+          f"{current / 1024:.1f} KB ({growth:+.1%})")
+    return growth <= tolerance
+
+
+def main() -> None:
+    parser = argparse.ArgumentParser(description=__doc__.splitlines()[0])
+    parser.add_argument("--rate", type=int, default=10000, help="Trades per second (default 10000)")
+    parser.add_argument("--seconds", type=int, default=600, help="Simulated seconds (default 600)")
+    parser.add_argument("--horizon", type=int, default=max(DEFAULT_FLOW_WINDOWS), help="Counter horizon in seconds")
+    parser.add_argument("--tolerance", type=float, default=0.1, help="Allowed memory growth after the first horizon")
+    args = parser.parse_args()
+    sys.exit(0 if run(args.rate, args.seconds, args.horizon, args.tolerance) else 1)
+
+
+if __name__ == "__main__":
+    main()
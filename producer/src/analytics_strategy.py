@@ -6,35 +6,124 @@ Generates reports every 250ms (configurable) and publishes to Redis KV store.
 import time
 import random
 import asyncio
-from typing import Any, Dict, Set
+from typing import Any, Dict, List, Optional, Set
 import pandas as pd
 from nautilus_trader.trading import Strategy
 from nautilus_trader.trading.config import StrategyConfig
-from nautilus_trader.model.data import TradeTick, OrderBookDeltas
+from nautilus_trader.model.data import InstrumentStatus, TradeTick, OrderBookDeltas
 from nautilus_trader.model.identifiers import InstrumentId
 import structlog
 
 from datetime import datetime, timezone
+from src.state.ingestion import IngestionThresholds
+from src.calculators.notional import DEFAULT_NOTIONAL_TIERS, NotionalTier
+from src.state.symbol_meta import SymbolMeta
 from src.state.symbol_state import SymbolState, TradeTick as StateTradeTick, PriceQty
 from src.reporters.fast_cycle import generate_fast_report
-from src.reporters.redis_cache import publish_report
+from src.reporters.publish_queue import ReportPublishQueue
+from src.reporters.redis_cache import drop_legacy_report, get_report
+from src.reporters.secondary_publish import SecondaryPublisher
+from src.reporters.metrics_sink import MetricsSink, build_metrics_record
+from src.reporters.parquet_sink import ParquetHistoryWriter
 from src.reporters.slow_cycle import calculate_slow_metrics, enrich_report  # US3
+from src.reporters.staleness import mark_report_stale
+from src.reporters.injection import apply_injection, read_injection
+from src.reporters.invariants import check_report
+from src.reporters.finite import WARNING_FIELD as NON_FINITE_FIELD, scrub_non_finite
+from src.reporters.book_history import build_snapshot, record_snapshot
+from src.reporters.canary import CanaryPublisher
+from src.reporters.feature_flags import REPORT_SECTIONS, FeatureFlags
+from src.reporters.daily_stats import rollup_digest
+from src.reporters.digest import build_digest, record_digest
+from src.reporters.relative_strength import RelativeStrengthTracker
+from src.reporters.signals import SignalPublisher
+from src.reporters.thresholds import ThresholdDetector
+from src.reporters.timings import build_timings
+from src.reporters.venue_report import VenueStressMonitor
+from src.reporters.trade_tape import KEY_PREFIX as TRADE_TAPE_KEY_PREFIX, serialize_trades
+from src.sanitize import DEFAULT_DLQ_MAXLEN, DEFAULT_DLQ_STREAM, Quarantine, level_problem
+from src.reporters.detectors import DETECTORS, DeferredDetectorRunner, DetectorInputs, DetectorRun
+from src.state.event_ordering import DEPTH, REORDERED, STALE, TRADE
+from src.state.market_status import MARKET_STATES
 from src.metrics.prometheus import PrometheusMetrics
 from src.coordinator.membership import NodeMembership
 from src.coordinator.lease_manager import LeaseManager
 from src.coordinator.assignment import SymbolAssignmentController
+from src.coordinator.profiles import PROCESSING_PROFILES
 
 logger = structlog.get_logger()
 
+# How often closed minutes are published as digests
+DIGEST_FLUSH_MS = 5000
+
 
 class AnalyticsStrategyConfig(StrategyConfig, frozen=True):
     """Configuration for market analytics strategy."""
     redis_client: Any = None  # Injected Redis client
+    pubsub_redis_client: Any = None  # Injected client for report pub/sub (None = redis_client)
     symbols: list[str] = []
     node_id: str = ""
     report_period_ms: int = 250
     slow_period_ms: int = 2000  # US3: Slow-cycle period
+    processing_profile: str = "full"  # "full", "fast" or "deep" (see coordinator/profiles.py)
     metrics: Any = None  # Injected PrometheusMetrics
+    metrics_sink: Any = None  # Injected per-report MetricsSink (optional)
+    history_writer: Any = None  # Injected ParquetHistoryWriter (optional)
+    flow_window_sec: int = 30  # Headline net_flow window
+    flow_windows: list[int] = [10, 60, 300]  # Windows published in flow.windows
+    publish_workers: int = 4  # Background Redis publish threads
+    publish_flush_ms: float = 5.0  # Batch collection window for pipelined publishes
+    publish_batch_max: int = 100
+    secondary_redis_client: Any = None  # Injected client for the report mirror (None = off)
+    secondary_publish_workers: int = 2
+    secondary_alarm_failures: int = 5
+    # Ingestion status thresholds and hysteresis
+    ingestion_degraded_ms: int = 1000
+    ingestion_down_ms: int = 2000
+    ingestion_min_dwell_ms: int = 2000
+    ingestion_overrides: dict[str, list[int]] = {}  # symbol -> [degraded_ms, down_ms]
+    staleness_sweep_ms: int = 1000  # Republish unrefreshed reports with current status (0 = off)
+    allow_anomaly_injection: bool = False  # Honor control:inject:{symbol} test injections
+    invariant_mode: str = "off"  # "off", "tag" (publish with violations) or "block"
+    # Anomaly/wall severity notional tiers: name -> [medium_usd, high_usd], symbol -> tier
+    notional_tiers: dict[str, list[float]] = {}
+    symbol_tiers: dict[str, str] = {}
+    stable_quotes: list[str] = ["USDT", "USDC", "FDUSD", "TUSD", "BUSD", "USD"]  # Valued at 1 USD
+    book_history_interval_ms: int = 5000  # Top-N book snapshots for heatmaps (0 = off)
+    book_history_retention_sec: int = 3600
+    book_history_levels: int = 20
+    digest_ttl_sec: int = 86400  # Per-minute digests (0 = off)
+    daily_ttl_sec: int = 2592000  # Daily statistics rolled up from digests (0 = off)
+    venue_report_interval_ms: int = 5000  # Venue stress index (0 = off)
+    venue_spread_widen_factor: float = 3.0
+    relative_strength_interval_sec: int = 60  # relative_strength refresh (0 = off)
+    relative_strength_benchmark: str = "BTCUSDT"
+    signals_maxlen: int = 100000  # signals:{venue} stream cap (0 = off)
+    anomaly_budget_per_hour: int = 60  # anomaly_started signals per symbol and type per hour (0 = no limit)
+    watch_refresh_ms: int = 2000  # Reload of watch registrations' threshold rules (0 = off)
+    dlq_stream: str = DEFAULT_DLQ_STREAM  # Trades and book levels failing sanitation
+    dlq_maxlen: int = DEFAULT_DLQ_MAXLEN
+    canary_modules: list[str] = []  # Candidate modules published to report_canary:{symbol} (empty = off)
+    canary_ttl_sec: int = 300
+    canary_compare_sec: int = 60
+    poc_trend_window_sec: int = 900  # POC migration window
+    poc_trend_threshold_bps: float = 5.0  # Drift reported as "up"/"down"
+    footprint_window_sec: int = 300  # Footprint ladder published to footprint:{symbol}
+    footprint_bucket_bps: float = 1.0
+    footprint_top_n: int = 5
+    # Report sections off for every symbol, and per-symbol {section: enabled} overrides
+    disabled_sections: list[str] = []
+    symbol_sections: dict[str, dict[str, bool]] = {}
+    # Anomaly detectors off for every symbol, and per-symbol {detector: enabled} overrides
+    disabled_detectors: list[str] = []
+    symbol_detectors: dict[str, dict[str, bool]] = {}
+    async_detectors: list[str] = ["iceberg", "absorption"]  # Run off-thread, attached to the next report
+    detector_budget_ms: float = 50.0
+    trade_tape_size: int = 500  # Recent trades published to trades:{symbol}
+    report_recent_trades: int = 0  # Tape trades embedded in reports (0 = off)
+    churn_window_sec: int = 10  # Microstructure update rate and churn window
+    clock_skew_warn_ms: int = 1000  # Venue clock skew raising a clock_skew anomaly
+    reorder_window_ms: float = 0.0  # Out-of-order event tolerance (0 = drop any older event)
     # US2: Coordination parameters
     enable_coordination: bool = False
     heartbeat_interval_sec: float = 1.0
@@ -63,7 +152,95 @@ class MarketAnalyticsStrategy(Strategy):
         self.node_id = config.node_id
         self.report_period_ms = config.report_period_ms
         self.slow_period_ms = config.slow_period_ms  # US3: Slow-cycle period
+        self.profile = PROCESSING_PROFILES[config.processing_profile]
         self.metrics: PrometheusMetrics = config.metrics
+        self.metrics_sink: MetricsSink | None = config.metrics_sink
+        self.history_writer: ParquetHistoryWriter | None = config.history_writer
+        self.flow_window_sec = config.flow_window_sec
+        self.flow_windows = config.flow_windows
+        self.publish_workers = config.publish_workers
+        self.publish_flush_ms = config.publish_flush_ms
+        self.publish_batch_max = config.publish_batch_max
+        self.publish_queue: ReportPublishQueue | None = None
+        self.secondary_redis_client = config.secondary_redis_client
+        self.pubsub_redis_client = config.pubsub_redis_client
+        self.secondary_publish_workers = config.secondary_publish_workers
+        self.secondary_alarm_failures = config.secondary_alarm_failures
+        self.secondary_publisher: SecondaryPublisher | None = None
+        self.ingestion_thresholds = IngestionThresholds(config.ingestion_degraded_ms, config.ingestion_down_ms)
+        self.ingestion_overrides = {
+            symbol: IngestionThresholds(degraded_ms, down_ms)
+            for symbol, (degraded_ms, down_ms) in config.ingestion_overrides.items()
+        }
+        self.ingestion_min_dwell_ms = config.ingestion_min_dwell_ms
+        self.staleness_sweep_ms = config.staleness_sweep_ms
+        self.allow_anomaly_injection = config.allow_anomaly_injection
+        self.invariant_mode = config.invariant_mode
+        self.notional_tiers = {
+            **DEFAULT_NOTIONAL_TIERS,
+            **{name: NotionalTier(medium_usd, high_usd) for name, (medium_usd, high_usd) in config.notional_tiers.items()},
+        }
+        self.symbol_tiers = config.symbol_tiers
+        self.stable_quotes = config.stable_quotes
+        self.book_history_interval_ms = config.book_history_interval_ms
+        self.book_history_retention_ms = config.book_history_retention_sec * 1000
+        self.book_history_levels = config.book_history_levels
+        self.digest_ttl_sec = config.digest_ttl_sec
+        self.daily_ttl_sec = config.daily_ttl_sec
+        self.venue_monitor: VenueStressMonitor | None = None
+        if config.venue_report_interval_ms:
+            self.venue_monitor = VenueStressMonitor(
+                redis_client=self.redis_client,
+                interval_ms=config.venue_report_interval_ms,
+                widen_factor=config.venue_spread_widen_factor,
+                metrics=self.metrics,
+            )
+        self.relative_strength_interval_sec = config.relative_strength_interval_sec
+        self.relative_strength: RelativeStrengthTracker | None = None
+        if config.relative_strength_interval_sec:
+            self.relative_strength = RelativeStrengthTracker(
+                redis_client=self.redis_client,
+                benchmark=config.relative_strength_benchmark,
+            )
+        self.quarantine = Quarantine(
+            self.redis_client, "analytics", stream=config.dlq_stream, maxlen=config.dlq_maxlen, metrics=self.metrics
+        )
+        self.signal_publisher: SignalPublisher | None = None
+        if config.signals_maxlen:
+            self.signal_publisher = SignalPublisher(
+                redis_client=self.redis_client,
+                maxlen=config.signals_maxlen,
+                metrics=self.metrics,
+                anomaly_budget_per_hour=config.anomaly_budget_per_hour,
+            )
+        self.watch_refresh_ms = config.watch_refresh_ms
+        self.threshold_detector: ThresholdDetector | None = None
+        if config.watch_refresh_ms:
+            self.threshold_detector = ThresholdDetector(
+                redis_client=self.redis_client,
+                signals_maxlen=config.signals_maxlen,
+                metrics=self.metrics,
+            )
+        self.canary_modules = config.canary_modules
+        self.canary_ttl_sec = config.canary_ttl_sec
+        self.canary_compare_sec = config.canary_compare_sec
+        self.canary_publisher: CanaryPublisher | None = None
+        self.poc_trend_window_sec = config.poc_trend_window_sec
+        self.poc_trend_threshold_bps = config.poc_trend_threshold_bps
+        self.footprint_window_sec = config.footprint_window_sec
+        self.footprint_bucket_bps = config.footprint_bucket_bps
+        self.footprint_top_n = config.footprint_top_n
+        self.feature_flags = FeatureFlags(config.disabled_sections, config.symbol_sections)
+        self.disabled_detectors = config.disabled_detectors
+        self.symbol_detectors = config.symbol_detectors
+        self.async_detectors = frozenset(config.async_detectors)
+        self.detector_budget_ms = config.detector_budget_ms
+        self.deferred_detectors: DeferredDetectorRunner | None = None
+        self.trade_tape_size = config.trade_tape_size
+        self.report_recent_trades = config.report_recent_trades
+        self.churn_window_sec = config.churn_window_sec
+        self.clock_skew_warn_ms = config.clock_skew_warn_ms
+        self.reorder_window_ms = config.reorder_window_ms
 
         # US2: Coordination parameters
         self.enable_coordination = config.enable_coordination
@@ -93,6 +270,12 @@ class MarketAnalyticsStrategy(Strategy):
         self._rebalance_task = None
         self._lease_renewal_task = None
 
+        # Clock timers set by on_start (they depend on profile and config), cancelled by on_stop
+        self._timers: List[str] = []
+
+        # Active test injections per symbol, refreshed on the slow cycle
+        self.injections: Dict[str, dict] = {}
+
         # US3: Slow-cycle state tracking
         self._slow_cycle_running = False  # T074: Lag detection flag
         self._slow_cycle_skip_count = 0
@@ -115,12 +298,15 @@ class MarketAnalyticsStrategy(Strategy):
                 pid=os.getpid(),
                 metrics_url=f"http://{socket.gethostname()}:9101/metrics",
                 heartbeat_interval_sec=self.heartbeat_interval_sec,
-                ttl_sec=int(self.heartbeat_interval_sec * 5)
+                ttl_sec=int(self.heartbeat_interval_sec * 5),
+                key_prefix=self.profile.membership_prefix
             )
 
+            # Each processing profile assigns symbols within its own group
             self.lease_manager = LeaseManager(
                 redis_client=self.redis_client,
-                node_id=self.node_id
+                node_id=self.node_id,
+                key_prefix=self.profile.lease_prefix
             )
 
             self.assignment_controller = SymbolAssignmentController(
@@ -150,6 +336,41 @@ class MarketAnalyticsStrategy(Strategy):
         """Called when strategy starts. Subscribe to market data and setup timer."""
         self.log.info(f"Analytics strategy starting for symbols: {self.symbols}")
 
+        # Publish off the strategy thread so slow Redis writes don't stall market data
+        self.publish_queue = ReportPublishQueue(
+            redis_client=self.redis_client,
+            workers=self.publish_workers,
+            flush_ms=self.publish_flush_ms,
+            max_batch=self.publish_batch_max,
+            metrics=self.metrics,
+            pubsub_client=self.pubsub_redis_client,
+        )
+
+        # Mirror published reports to the secondary Redis (another region)
+        if self.secondary_redis_client is not None:
+            self.secondary_publisher = SecondaryPublisher(
+                redis_client=self.secondary_redis_client,
+                workers=self.secondary_publish_workers,
+                alarm_after=self.secondary_alarm_failures,
+                metrics=self.metrics,
+            )
+
+        # Shadow-publish candidate metric modules next to the stable reports
+        if self.canary_modules:
+            self.canary_publisher = CanaryPublisher(
+                redis_client=self.redis_client,
+                modules=self.canary_modules,
+                ttl_sec=self.canary_ttl_sec,
+                metrics=self.metrics,
+            )
+
+        # Heavy anomaly detectors run off the strategy thread
+        if self.async_detectors:
+            self.deferred_detectors = DeferredDetectorRunner(
+                budget_ms=self.detector_budget_ms,
+                max_age_ms=self.slow_period_ms * 5,
+            )
+
         if self.enable_coordination:
             # US2: Start coordination background tasks
             self.log.info("Starting coordination tasks (heartbeat, rebalance, lease renewal)")
@@ -175,19 +396,77 @@ class MarketAnalyticsStrategy(Strategy):
             # T086: Update health status with owned symbols in single-instance mode
             self.metrics.update_health_status(owned_symbols=list(self.owned_symbols))
 
-        # Setup fast-cycle timer (e.g., every 250ms)
-        self.clock.set_timer(
-            name="fast_cycle",
-            interval=pd.Timedelta(milliseconds=self.report_period_ms),
-            callback=self.on_fast_cycle,
-        )
-
         # T072: Setup slow-cycle timer (US3: e.g., every 2000ms)
-        self.clock.set_timer(
-            name="slow_cycle",
-            interval=pd.Timedelta(milliseconds=self.slow_period_ms),
-            callback=self.on_slow_cycle,
-        )
+        if self.profile.slow_cycle:
+            self._set_timer(
+                name="slow_cycle",
+                interval=pd.Timedelta(milliseconds=self.slow_period_ms),
+                callback=self.on_slow_cycle,
+            )
+
+        # Setup fast-cycle timer (e.g., every 250ms) and the tasks built on fast reports
+        if self.profile.fast_cycle:
+            self._set_timer(
+                name="fast_cycle",
+                interval=pd.Timedelta(milliseconds=self.report_period_ms),
+                callback=self.on_fast_cycle,
+            )
+
+            # Degrade cached reports the fast cycle has stopped refreshing
+            if self.staleness_sweep_ms:
+                self._set_timer(
+                    name="staleness_sweep",
+                    interval=pd.Timedelta(milliseconds=self.staleness_sweep_ms),
+                    callback=self.on_staleness_sweep,
+                )
+
+            # Snapshot top-N books for heatmap history
+            if self.book_history_interval_ms:
+                self._set_timer(
+                    name="book_history",
+                    interval=pd.Timedelta(milliseconds=self.book_history_interval_ms),
+                    callback=self.on_book_history,
+                )
+
+            # Publish closed minutes as digests
+            if self.digest_ttl_sec:
+                self._set_timer(
+                    name="digest",
+                    interval=pd.Timedelta(milliseconds=DIGEST_FLUSH_MS),
+                    callback=self.on_digest,
+                )
+
+            # Score venue-wide stress across every published symbol
+            if self.venue_monitor:
+                self._set_timer(
+                    name="venue_report",
+                    interval=pd.Timedelta(milliseconds=self.venue_monitor.interval_ms),
+                    callback=self.on_venue_report,
+                )
+
+            # Threshold rules of MCP watch registrations, checked on every fast report
+            if self.threshold_detector:
+                self._set_timer(
+                    name="watch_refresh",
+                    interval=pd.Timedelta(milliseconds=self.watch_refresh_ms),
+                    callback=self.on_watch_refresh,
+                )
+
+            # Cross-market context attached to every report
+            if self.relative_strength:
+                self._set_timer(
+                    name="relative_strength",
+                    interval=pd.Timedelta(seconds=self.relative_strength_interval_sec),
+                    callback=self.on_relative_strength,
+                )
+
+        # Summarize canary/stable divergence
+        if self.canary_publisher:
+            self._set_timer(
+                name="canary_compare",
+                interval=pd.Timedelta(seconds=self.canary_compare_sec),
+                callback=self.on_canary_compare,
+            )
 
         # US2: Update metrics
         if self.metrics and self.enable_coordination:
@@ -195,11 +474,16 @@ class MarketAnalyticsStrategy(Strategy):
             self.metrics.symbols_assigned.labels(node=self.node_id).set(len(self.owned_symbols))
 
         self.log.info(
-            f"Analytics strategy started: fast_cycle={self.report_period_ms}ms, "
-            f"slow_cycle={self.slow_period_ms}ms, "
+            f"Analytics strategy started: profile={self.profile.name}, "
+            f"fast_cycle={self.report_period_ms}ms, slow_cycle={self.slow_period_ms}ms, "
             f"owned_symbols={len(self.owned_symbols)}, coordination={self.enable_coordination}"
         )
 
+    def _set_timer(self, name: str, interval: pd.Timedelta, callback) -> None:
+        """Set a clock timer and record it for cancellation in on_stop."""
+        self.clock.set_timer(name=name, interval=interval, callback=callback)
+        self._timers.append(name)
+
     def on_fast_cycle(self, event) -> None:
         """Fast-cycle callback: generate and publish reports.
 
@@ -220,6 +504,9 @@ class MarketAnalyticsStrategy(Strategy):
                 # Calculate report generation time
                 report_start = time.perf_counter()
 
+                # Trades held for reordering longer than the window
+                self._apply_trades(symbol, state, state.event_order.release(self.clock.timestamp_ns()))
+
                 # US2: Validate fencing token before generating report
                 if self.enable_coordination and self.lease_manager:
                     current_token = self.writer_tokens.get(symbol)
@@ -243,13 +530,21 @@ class MarketAnalyticsStrategy(Strategy):
 
                 # Generate report (use per-symbol token in US2 mode, default token otherwise)
                 writer_token = self.writer_tokens.get(symbol, self.default_writer_token) if self.enable_coordination else self.default_writer_token
+                previous_status = state.ingestion.status
+                state.quote_usd_rate = self._quote_usd_rate(state)
                 report = generate_fast_report(
                     state=state,
                     node_id=self.node_id,
                     writer_token=writer_token,
-                    ticker_data=None  # TODO: Add ticker data integration
+                    ticker_data=None,  # TODO: Add ticker data integration
+                    flow_window_sec=self.flow_window_sec,
+                    flow_windows=self.flow_windows,
+                    recent_trades=self.report_recent_trades,
+                    churn_window_sec=self.churn_window_sec,
                 )
 
+                self._record_ingestion_transition(symbol, previous_status, state.ingestion.status)
+
                 if report is None:
                     self.log.info(
                         f"report_skipped_insufficient_data for {symbol}: "
@@ -260,51 +555,28 @@ class MarketAnalyticsStrategy(Strategy):
                     continue
 
                 report_gen_time_ms = (time.perf_counter() - report_start) * 1000
-
-                # Publish to Redis
-                publish_start = time.perf_counter()
-                success = publish_report(
-                    redis_client=self.redis_client,
-                    symbol=symbol,
-                    report=report
+                report["timings"] = build_timings(state, report_gen_time_ms)
+                relative_strength = self.relative_strength.get(symbol) if self.relative_strength else None
+                if relative_strength:
+                    report["relative_strength"] = relative_strength
+                state.digest.add_sample(
+                    report["updatedAt"],
+                    report["spread_bps"],
+                    report["depth"]["imbalance"],
+                    ingestion_ok=report["ingestion"]["status"] == "ok",
                 )
-                publish_time_ms = (time.perf_counter() - publish_start) * 1000
-
-                if success:
-                    # Record metrics
-                    if self.metrics:
-                        self.metrics.report_publish_rate.labels(
-                            symbol=symbol
-                        ).inc()
+                if self.metrics and state.clock_skew.skew_ms is not None:
+                    self.metrics.clock_skew.labels(symbol=symbol).set(state.clock_skew.skew_ms)
 
-                        self.metrics.data_age.labels(
-                            symbol=symbol
-                        ).observe(report["data_age_ms"])
+                if symbol in self.injections:
+                    report = apply_injection(report, self.injections[symbol])
 
-                        self.metrics.calc_latency.labels(
-                            metric="report_generation",
-                            cycle="fast"
-                        ).observe(report_gen_time_ms)
-
-                        self.metrics.calc_latency.labels(
-                            metric="redis_publish",
-                            cycle="fast"
-                        ).observe(publish_time_ms)
-
-                    # T082: Structured log for report publication with lag_ms
-                    self._structured_logger.bind(
-                        symbol=symbol,
-                        lag_ms=report['data_age_ms']
-                    ).debug(
-                        "report_published",
-                        report_gen_ms=round(report_gen_time_ms, 2),
-                        publish_ms=round(publish_time_ms, 2),
-                        writer_token=writer_token
-                    )
-                else:
-                    self.log.warning(
-                        f"report_publish_failed for {symbol}"
-                    )
+                # Queue for publishing; a newer report supersedes one still pending
+                self._submit_report(
+                    symbol,
+                    report,
+                    on_done=self._fast_report_callback(state, report, report_gen_time_ms, writer_token),
+                )
 
             except Exception as e:
                 # T083: Structured log for calculation errors
@@ -318,6 +590,10 @@ class MarketAnalyticsStrategy(Strategy):
         # Record total cycle time
         cycle_time_ms = (time.perf_counter() - cycle_start) * 1000
         if self.metrics:
+            self.metrics.health_status.record_cycle({
+                symbol: {"data_age_ms": state.get_data_age_ms(), "ingestion": state.ingestion.status}
+                for symbol, state in owned_states.items()
+            })
             self.metrics.calc_latency.labels(
                 metric="fast_cycle_total",
                 cycle="fast"
@@ -350,6 +626,8 @@ class MarketAnalyticsStrategy(Strategy):
         cycle_start = time.perf_counter()
 
         try:
+            self._refresh_feature_flags()
+
             # Process each owned symbol
             for symbol in list(self.owned_symbols):
                 if symbol not in self.symbol_states:
@@ -357,11 +635,45 @@ class MarketAnalyticsStrategy(Strategy):
 
                 state = self.symbol_states[symbol]
 
+                # Without a fast cycle (deep profile), held trades and the quote rate are updated here
+                if not self.profile.fast_cycle:
+                    self._apply_trades(symbol, state, state.event_order.release(self.clock.timestamp_ns()))
+                    state.quote_usd_rate = self._quote_usd_rate(state)
+
+                if self.allow_anomaly_injection:
+                    self._refresh_injection(symbol)
+
                 try:
                     # T073: Calculate slow-cycle metrics
                     start_time = time.perf_counter()
-                    slow_metrics = calculate_slow_metrics(state, tick_size=0.01)
+                    slow_metrics = calculate_slow_metrics(
+                        state,
+                        tick_size=0.01,
+                        footprint_window_sec=self.footprint_window_sec,
+                        footprint_bucket_bps=self.footprint_bucket_bps,
+                        footprint_top_n=self.footprint_top_n,
+                        deferred_detectors=self.async_detectors if self.deferred_detectors else frozenset(),
+                        detector_budget_ms=self.detector_budget_ms,
+                        churn_window_sec=self.churn_window_sec,
+                        clock_skew_warn_ms=self.clock_skew_warn_ms,
+                    )
+                    self._record_detector_run(symbol, slow_metrics.get("detector_run"), "slow")
+
+                    # Attach the previous deferred run and start the next from this cycle's state
+                    if self.deferred_detectors:
+                        deferred_run = self.deferred_detectors.collect(symbol)
+                        if deferred_run and state.market_status.is_open:
+                            slow_metrics["anomalies"].extend(deferred_run.anomalies)
+                            self._record_detector_run(symbol, deferred_run, "deferred")
+                        deferred = [name for name in DETECTORS
+                                    if name in self.async_detectors and name not in state.disabled_detectors
+                                    and state.market_status.is_open]
+                        if deferred and not self.deferred_detectors.submit(
+                            symbol, deferred, DetectorInputs.from_state(state, copy_book=True, churn_window_sec=self.churn_window_sec)
+                        ):
+                            self._record_skipped_detectors(symbol, deferred, "backlog")
                     calc_time_ms = (time.perf_counter() - start_time) * 1000
+                    state.digest.add_anomalies(int(time.time() * 1000), slow_metrics["anomalies"])
 
                     # Record metrics for slow calculations (T075-T077)
                     if self.metrics:
@@ -386,26 +698,56 @@ class MarketAnalyticsStrategy(Strategy):
                                 cycle="slow"
                             ).observe(calc_time_ms)
 
-                    # Fetch current (fast-cycle) report from Redis
-                    report_json = self.redis_client.get(f"report:{symbol}")
+                    import json
+
+                    # Full footprint ladder for the get_footprint tool; expires if slow cycles stop
+                    if slow_metrics.get("footprint"):
+                        self.redis_client.set(
+                            f"footprint:{symbol}",
+                            json.dumps({
+                                "symbol": symbol,
+                                "updated_at": int(time.time() * 1000),
+                                **slow_metrics["footprint"],
+                            }),
+                            px=self.slow_period_ms * 5,
+                        )
 
-                    if report_json:
-                        import json
-                        base_report = json.loads(report_json)
+                    # Raw tape for the get_trades tool
+                    if state.last_trade:
+                        self.redis_client.set(
+                            f"{TRADE_TAPE_KEY_PREFIX}{symbol}",
+                            json.dumps({
+                                "symbol": symbol,
+                                "updated_at": int(time.time() * 1000),
+                                "trades": serialize_trades(state.trade_tape.get_all()),
+                            }),
+                            px=self.slow_period_ms * 5,
+                        )
+
+                    # Fetch current (fast-cycle) report from Redis, without the previous slow sections
+                    base_report = get_report(self.redis_client, symbol, slow=False)
+
+                    if base_report:
 
                         # T071: Enrich report with slow-cycle data
                         enriched_report = enrich_report(base_report, slow_metrics)
-
-                        # Publish enriched report
-                        publish_report(
-                            redis_client=self.redis_client,
-                            symbol=symbol,
-                            report=enriched_report
+                        enriched_report["timings"] = build_timings(state, calc_time_ms)
+                        if self.signal_publisher:
+                            self.signal_publisher.process(enriched_report, state.trade_buffer_30s.get_all())
+                        if symbol in self.injections:
+                            enriched_report = apply_injection(enriched_report, self.injections[symbol])
+
+                        # Queue enriched report for publishing
+                        self._submit_report(
+                            symbol,
+                            enriched_report,
+                            on_done=self._slow_report_callback(state, enriched_report, calc_time_ms),
                         )
 
                         self._structured_logger.bind(symbol=symbol).debug(
                             "slow_cycle_enriched",
-                            calc_time_ms=round(calc_time_ms, 2)
+                            calc_time_ms=round(calc_time_ms, 2),
+                            updated_at=enriched_report.get("updatedAt")
                         )
 
                 except Exception as e:
@@ -421,6 +763,12 @@ class MarketAnalyticsStrategy(Strategy):
 
         # Record total cycle time
         cycle_time_ms = (time.perf_counter() - cycle_start) * 1000
+        if self.metrics and not self.profile.fast_cycle:
+            # /health stall detection follows the slow cycle on deep nodes
+            self.metrics.health_status.record_cycle({
+                symbol: {"data_age_ms": state.get_data_age_ms(), "ingestion": state.ingestion.status}
+                for symbol, state in self.symbol_states.items() if symbol in self.owned_symbols
+            })
 
         if cycle_time_ms > self.slow_period_ms * 0.8:
             # Warn if cycle takes >80% of period
@@ -430,6 +778,347 @@ class MarketAnalyticsStrategy(Strategy):
                 f"period_ms={self.slow_period_ms}, utilization_pct={utilization_pct}"
             )
 
+    def _record_detector_run(self, symbol: str, run: Optional[DetectorRun], path: str) -> None:
+        """Record per-detector latency and budget overruns of a detector run."""
+        if run is None:
+            return
+        if run.over_budget or run.skipped:
+            self._structured_logger.bind(symbol=symbol).warning(
+                "detector_budget_exceeded",
+                path=path,
+                over_budget=run.over_budget,
+                skipped=run.skipped,
+                timings_ms=run.timings_ms,
+            )
+        if not self.metrics:
+            return
+        for name, elapsed_ms in run.timings_ms.items():
+            self.metrics.calc_latency.labels(metric=f"detector_{name}", cycle=path).observe(elapsed_ms)
+        for name in run.over_budget:
+            self.metrics.detector_over_budget.labels(symbol=symbol, detector=name).inc()
+        self._record_skipped_detectors(symbol, run.skipped, "budget")
+
+    def _record_skipped_detectors(self, symbol: str, names: list[str], reason: str) -> None:
+        if self.metrics:
+            for name in names:
+                self.metrics.detectors_skipped.labels(symbol=symbol, detector=name, reason=reason).inc()
+
+    def on_staleness_sweep(self, event) -> None:
+        """Republish reports the fast cycle has not refreshed with current ingestion status.
+
+        Called every staleness_sweep_ms (default 1000ms). A report whose
+        updatedAt is older than the sweep interval means the fast cycle could
+        not build one (e.g. the book was cleared after the feed died), so the
+        cached report is re-evaluated and republished as degraded or down
+        instead of staying "ok" until its TTL expires.
+        """
+        now_ms = int(time.time() * 1000)
+
+        for symbol in list(self.owned_symbols):
+            state = self.symbol_states.get(symbol)
+            if state is None:
+                continue
+            if self.enable_coordination and self.writer_tokens.get(symbol) is None:
+                continue
+
+            try:
+                # Republished as a fast report; the slow sections stay in place
+                report = get_report(self.redis_client, symbol, slow=False)
+                if not report:
+                    continue
+
+                if now_ms - report.get("updatedAt", 0) < self.staleness_sweep_ms:
+                    continue  # Fast cycle is still publishing
+
+                previous_status = state.ingestion.status
+                stale_report = mark_report_stale(report, state)
+                self._record_ingestion_transition(symbol, previous_status, state.ingestion.status)
+
+                if not self._submit_report(symbol, stale_report):
+                    continue
+                if self.metrics:
+                    self.metrics.stale_reports_republished.labels(
+                        symbol=symbol,
+                        status=state.ingestion.status
+                    ).inc()
+
+                self._structured_logger.bind(symbol=symbol).debug(
+                    "stale_report_republished",
+                    status=state.ingestion.status,
+                    data_age_ms=stale_report["data_age_ms"]
+                )
+
+            except Exception as e:
+                self._structured_logger.bind(symbol=symbol).error(
+                    "calculation_error",
+                    error_type=type(e).__name__,
+                    error_message=str(e),
+                    phase="staleness_sweep"
+                )
+
+    def on_book_history(self, event) -> None:
+        """Snapshot the top-N book of each owned symbol into book_history:{symbol}.
+
+        Called every book_history_interval_ms (default 5000ms). Snapshots are
+        kept for book_history_retention_sec and read back by the MCP
+        get_book_history tool as a price x time liquidity matrix.
+        """
+        now_ms = int(time.time() * 1000)
+
+        for symbol in list(self.owned_symbols):
+            state = self.symbol_states.get(symbol)
+            if state is None:
+                continue
+            if self.enable_coordination and self.writer_tokens.get(symbol) is None:
+                continue
+
+            snapshot = build_snapshot(state, now_ms, self.book_history_levels)
+            if snapshot is None:
+                continue
+            if record_snapshot(self.redis_client, symbol, snapshot, self.book_history_retention_ms):
+                if self.metrics:
+                    self.metrics.book_history_snapshots.labels(symbol=symbol).inc()
+
+    def on_digest(self, event) -> None:
+        """Publish each owned symbol's closed minutes as digest:{symbol}:{minute}.
+
+        Called every DIGEST_FLUSH_MS, so a minute is published within a few
+        seconds of closing. Digests expire after digest_ttl_sec and are read
+        back by the MCP get_digest tool. Each published digest is also rolled
+        up into its day's daily:{symbol}:{date} statistics (get_daily_stats).
+        """
+        now_ms = int(time.time() * 1000)
+
+        for symbol in list(self.owned_symbols):
+            state = self.symbol_states.get(symbol)
+            if state is None:
+                continue
+            if self.enable_coordination and self.writer_tokens.get(symbol) is None:
+                continue
+
+            for summary in state.digest.drain(now_ms):
+                digest = build_digest(symbol, summary)
+                if not record_digest(self.redis_client, digest, self.digest_ttl_sec):
+                    continue
+                if self.metrics:
+                    self.metrics.digests_published.labels(symbol=symbol).inc()
+                if self.daily_ttl_sec and rollup_digest(self.redis_client, digest, self.daily_ttl_sec):
+                    if self.metrics:
+                        self.metrics.daily_rollups.labels(symbol=symbol).inc()
+
+    def on_venue_report(self, event) -> None:
+        """Publish venue_report:{venue} stress indexes from every symbol's cached report."""
+        if self.venue_monitor:
+            self.venue_monitor.run_once()
+
+    def on_relative_strength(self, event) -> None:
+        """Recompute each symbol's 24h performance against the benchmark and universe."""
+        if self.relative_strength:
+            self.relative_strength.refresh()
+
+    def on_watch_refresh(self, event) -> None:
+        """Reload the threshold rules of watch registrations."""
+        if not self.threshold_detector:
+            return
+        try:
+            self.threshold_detector.refresh()
+        except Exception as e:
+            self._structured_logger.warning("watch_registrations_read_failed", error=str(e))
+
+    def on_canary_compare(self, event) -> None:
+        """Summarize how far canary reports diverged from stable ones since the last run."""
+        if self.canary_publisher:
+            self.canary_publisher.compare()
+
+    def _submit_report(self, symbol: str, report: dict, on_done=None) -> bool:
+        """Check report invariants (per invariant_mode) and queue the report for publishing.
+
+        Sections turned off by feature flags are removed first, and NaN or
+        infinite values are replaced with null (listed in non_finite_fields).
+
+        Returns:
+            False if the report was blocked by an invariant violation
+        """
+        report = self.feature_flags.apply(symbol, report)
+
+        report, non_finite = scrub_non_finite({k: v for k, v in report.items() if k != NON_FINITE_FIELD})
+        if non_finite:
+            self._structured_logger.bind(symbol=symbol).warning("report_non_finite", fields=non_finite)
+            if self.metrics:
+                for field in non_finite:
+                    self.metrics.report_non_finite.labels(symbol=symbol, field=field).inc()
+            report[NON_FINITE_FIELD] = non_finite
+
+        if self.invariant_mode != "off":
+            violations = check_report(report)
+            report = {k: v for k, v in report.items() if k != "invariant_violations"}
+
+            if violations:
+                for violation in violations:
+                    self._structured_logger.bind(symbol=symbol).warning(
+                        "invariant_violation",
+                        invariant=violation["invariant"],
+                        message=violation["message"],
+                        action=self.invariant_mode
+                    )
+                    if self.metrics:
+                        self.metrics.invariant_violations.labels(
+                            symbol=symbol,
+                            invariant=violation["invariant"]
+                        ).inc()
+
+                if self.invariant_mode == "block":
+                    if self.metrics:
+                        self.metrics.reports_dropped.labels(symbol=symbol, reason="invariant_violation").inc()
+                    return False
+                report["invariant_violations"] = violations
+
+        # Slow-cycle reports repeat the fast cycle's prices; only fast reports are checked
+        if self.threshold_detector and "slow_cycle_updated_at" not in report:
+            self.threshold_detector.process(report)
+
+        if self.secondary_publisher:
+            on_done = self.secondary_publisher.chain(symbol, report, on_done)
+        if self.canary_publisher:
+            on_done = self.canary_publisher.chain(symbol, report, on_done)
+        self.publish_queue.submit(symbol, report, on_done=on_done)
+        return True
+
+    def _refresh_feature_flags(self) -> None:
+        """Reload the Redis feature flag overrides, logging changes."""
+        try:
+            changes = self.feature_flags.refresh(self.redis_client)
+        except Exception as e:
+            self._structured_logger.warning("feature_flags_read_failed", error=str(e))
+            return
+
+        for scope, section, enabled in changes:
+            self._structured_logger.info("feature_flag_changed", scope=scope, section=section, enabled=enabled)
+
+        if self.metrics:
+            for symbol in self.owned_symbols:
+                for section in REPORT_SECTIONS:
+                    self.metrics.report_section_enabled.labels(symbol=symbol, section=section).set(
+                        1 if self.feature_flags.enabled(symbol, section) else 0
+                    )
+
+    def _refresh_injection(self, symbol: str) -> None:
+        """Load the symbol's active test injection, logging when one starts or ends."""
+        try:
+            injection = read_injection(self.redis_client, symbol)
+        except Exception as e:
+            self._structured_logger.bind(symbol=symbol).warning("injection_read_failed", error=str(e))
+            return
+
+        if injection and symbol not in self.injections:
+            self._structured_logger.bind(symbol=symbol).warning(
+                "anomaly_injection_started",
+                anomalies=[a["type"] for a in injection.get("anomalies", [])],
+                health_score=injection.get("health_score"),
+                ingestion_status=injection.get("ingestion_status"),
+                injected_by=injection.get("injected_by"),
+                expires_at=injection.get("expires_at")
+            )
+        elif not injection and symbol in self.injections:
+            self._structured_logger.bind(symbol=symbol).warning("anomaly_injection_ended")
+
+        if injection:
+            self.injections[symbol] = injection
+        else:
+            self.injections.pop(symbol, None)
+
+        if self.metrics:
+            self.metrics.anomaly_injection_active.labels(symbol=symbol).set(1 if injection else 0)
+
+    def _record_ingestion_transition(self, symbol: str, from_status: str, to_status: str) -> None:
+        """Log and count an ingestion status change (no-op if unchanged)."""
+        if from_status == to_status:
+            return
+
+        self._structured_logger.bind(symbol=symbol).info(
+            "ingestion_status_changed",
+            from_status=from_status,
+            to_status=to_status
+        )
+        if self.metrics:
+            self.metrics.ingestion_transitions.labels(
+                symbol=symbol,
+                from_status=from_status,
+                to_status=to_status
+            ).inc()
+
+    def _fast_report_callback(self, state: SymbolState, report: dict, report_gen_time_ms: float, writer_token: int):
+        """Build the publish callback recording fast-cycle metrics (runs on a publish worker)."""
+        symbol = state.symbol
+
+        def on_done(success: bool, publish_time_ms: float) -> None:
+            if not success:
+                self.log.warning(
+                    f"report_publish_failed for {symbol}"
+                )
+                return
+
+            # Carried into the next report's timings.publish_ms
+            state.last_publish_ms = publish_time_ms
+
+            # Record metrics
+            if self.metrics:
+                self.metrics.health_status.record_publish()
+                self.metrics.report_publish_rate.labels(
+                    symbol=symbol
+                ).inc()
+
+                self.metrics.data_age.labels(
+                    symbol=symbol
+                ).observe(report["data_age_ms"])
+
+                self.metrics.calc_latency.labels(
+                    metric="report_generation",
+                    cycle="fast"
+                ).observe(report_gen_time_ms)
+
+                self.metrics.calc_latency.labels(
+                    metric="redis_publish",
+                    cycle="fast"
+                ).observe(publish_time_ms)
+
+            if self.metrics_sink:
+                self.metrics_sink.write(build_metrics_record(
+                    report, cycle="fast", calc_ms=report_gen_time_ms, publish_ms=publish_time_ms
+                ))
+            if self.history_writer:
+                self.history_writer.write_report(report, cycle="fast")
+
+            # T082: Structured log for report publication with lag_ms
+            self._structured_logger.bind(
+                symbol=symbol,
+                lag_ms=report['data_age_ms']
+            ).debug(
+                "report_published",
+                report_gen_ms=round(report_gen_time_ms, 2),
+                publish_ms=round(publish_time_ms, 2),
+                writer_token=writer_token,
+                updated_at=report["updatedAt"]
+            )
+
+        return on_done
+
+    def _slow_report_callback(self, state: SymbolState, report: dict, calc_time_ms: float):
+        """Build the publish callback for enriched slow-cycle reports."""
+        def on_done(success: bool, publish_time_ms: float) -> None:
+            if success:
+                state.last_publish_ms = publish_time_ms
+            if success and self.metrics and not self.profile.fast_cycle:
+                self.metrics.health_status.record_publish()
+            if success and self.metrics_sink:
+                self.metrics_sink.write(build_metrics_record(
+                    report, cycle="slow", calc_ms=calc_time_ms, publish_ms=publish_time_ms
+                ))
+            if success and self.history_writer:
+                self.history_writer.write_report(report, cycle="slow")
+
+        return on_done
+
     def on_order_book_deltas(self, deltas: OrderBookDeltas) -> None:
         """Handle order book delta updates. Update symbol state from NautilusTrader cache."""
         symbol = deltas.instrument_id.symbol.value
@@ -441,6 +1130,22 @@ class MarketAnalyticsStrategy(Strategy):
             return
 
         state = self.symbol_states[symbol]
+        state.consume_lag_ms = (self.clock.timestamp_ns() - deltas.ts_init) / 1_000_000
+        state.clock_skew.record(deltas.ts_event, deltas.ts_init)
+
+        # Book updates older than the newest (beyond the reorder window) would resync a stale view
+        outcome, _ = state.event_order.admit(DEPTH, deltas.ts_event, deltas.ts_init, deltas)
+        self._record_event_order(symbol, DEPTH, outcome)
+        if outcome == STALE:
+            return
+
+        # The book is copied from NautilusTrader's cache, which already includes
+        # every delta received before the last copy; when callbacks fall behind,
+        # older queued deltas are superseded by that snapshot and dropped
+        if deltas.ts_init <= state.book_synced_ns:
+            if self.metrics:
+                self.metrics.events_dropped.labels(symbol=symbol, reason="superseded_delta").inc()
+            return
 
         try:
             # Use NautilusTrader's built-in order book from cache
@@ -449,6 +1154,8 @@ class MarketAnalyticsStrategy(Strategy):
             if order_book is None:
                 return
 
+            state.book_synced_ns = self.clock.timestamp_ns()
+
             # Extract best bid/ask from NautilusTrader order book
             best_bid_price = order_book.best_bid_price()
             best_ask_price = order_book.best_ask_price()
@@ -456,13 +1163,13 @@ class MarketAnalyticsStrategy(Strategy):
             best_ask_qty = order_book.best_ask_size()
 
             # Only construct PriceQty if both price and qty are positive
-            if best_bid_price and best_bid_qty and float(best_bid_qty) > 0:
+            if best_bid_price and best_bid_qty and float(best_bid_qty) > 0 and not level_problem((best_bid_price, best_bid_qty)):
                 state.best_bid = PriceQty(
                     price=float(best_bid_price),
                     qty=float(best_bid_qty)
                 )
 
-            if best_ask_price and best_ask_qty and float(best_ask_qty) > 0:
+            if best_ask_price and best_ask_qty and float(best_ask_qty) > 0 and not level_problem((best_ask_price, best_ask_qty)):
                 state.best_ask = PriceQty(
                     price=float(best_ask_price),
                     qty=float(best_ask_qty)
@@ -470,9 +1177,10 @@ class MarketAnalyticsStrategy(Strategy):
 
             # Update timestamp if we got any order book data
             if best_bid_price or best_ask_price:
-                state.last_event_ts = datetime.now(timezone.utc)
+                state.last_event_ts = state.last_book_ts = datetime.now(timezone.utc)
 
             # Extract full depth (up to 20 levels) from NautilusTrader order book
+            previous_bids, previous_asks = dict(state.order_book.bids), dict(state.order_book.asks)
             state.order_book.bids.clear()
             state.order_book.asks.clear()
 
@@ -542,8 +1250,11 @@ class MarketAnalyticsStrategy(Strategy):
                 if best_ask_price and best_ask_qty:
                     state.order_book.asks[float(best_ask_price)] = float(best_ask_qty)
 
+            self._drop_bad_levels(symbol, state)
+
             # Recompute top levels
             state.order_book._recompute_top()
+            state.record_book_sync(previous_bids, previous_asks)
 
             # Log successful depth extraction
             self.log.debug(
@@ -557,6 +1268,29 @@ class MarketAnalyticsStrategy(Strategy):
                 f"order_book_update_error for {symbol}: {type(e).__name__} - {e}"
             )
 
+    def _drop_bad_levels(self, symbol: str, state: SymbolState) -> None:
+        """Drop and quarantine levels with an absurd price or size before the calculators see them."""
+        problems = []
+        dropped: dict[str, list[list[float]]] = {}
+        for side, levels in (("bids", state.order_book.bids), ("asks", state.order_book.asks)):
+            for price, qty in list(levels.items()):
+                problem = level_problem((price, qty))
+                if problem:
+                    del levels[price]
+                    problems.append(f"{side}[{price}]:{problem}")
+                    dropped.setdefault(side, []).append([price, qty])
+        if problems:
+            self.quarantine.put(dropped, problems, symbol=symbol, event_type="order_book_deltas")
+
+    def _record_event_order(self, symbol: str, kind: str, outcome: str) -> None:
+        """Count out-of-order events: reordered within the window, or dropped as stale."""
+        if not self.metrics:
+            return
+        if outcome == REORDERED:
+            self.metrics.events_reordered.labels(symbol=symbol, kind=kind).inc()
+        elif outcome == STALE:
+            self.metrics.events_dropped.labels(symbol=symbol, reason=f"out_of_order_{kind}").inc()
+
     def on_trade_tick(self, tick: TradeTick) -> None:
         """Handle trade tick updates. Update symbol state."""
         symbol = tick.instrument_id.symbol.value
@@ -575,6 +1309,16 @@ class MarketAnalyticsStrategy(Strategy):
             from datetime import datetime, timezone
             timestamp = datetime.fromtimestamp(tick.ts_init / 1_000_000_000, tz=timezone.utc)
 
+            problem = level_problem((tick.price, tick.size))
+            if problem:
+                self.quarantine.put(
+                    {"price": str(tick.price), "size": str(tick.size), "trade_id": str(tick.trade_id)},
+                    [f"trade:{problem}"],
+                    symbol=symbol,
+                    event_type="trade_tick",
+                )
+                return
+
             state_tick = StateTradeTick(
                 timestamp=timestamp,
                 price=float(tick.price),
@@ -582,13 +1326,62 @@ class MarketAnalyticsStrategy(Strategy):
                 aggressor_side="BUY" if tick.aggressor_side.name == "BUYER" else "SELL"
             )
 
-            state.add_trade(state_tick)
+            # Late trades within the reorder window are applied in event-time order
+            outcome, ready = state.event_order.admit(TRADE, tick.ts_event, tick.ts_init, state_tick)
+            self._record_event_order(symbol, TRADE, outcome)
+            self._apply_trades(symbol, state, ready)
+            state.consume_lag_ms = (self.clock.timestamp_ns() - tick.ts_init) / 1_000_000
+            state.clock_skew.record(tick.ts_event, tick.ts_init)
 
         except Exception as e:
             self.log.error(
                 f"trade_tick_update_error for {symbol}: {type(e).__name__} - {e}"
             )
 
+    def _apply_trades(self, symbol: str, state: SymbolState, trades) -> None:
+        """Apply trades released by the reorder buffer to state and persist them to history."""
+        for trade in trades:
+            state.add_trade(trade)
+            if self.history_writer:
+                self.history_writer.write_trade(symbol, trade)
+
+    def on_instrument_status(self, data: InstrumentStatus) -> None:
+        """Handle venue trading status changes (halts, auctions, maintenance)."""
+        symbol = data.instrument_id.symbol.value
+        state = self.symbol_states.get(symbol)
+        if state is None:
+            return
+
+        try:
+            market_status = state.market_status
+            from_state = market_status.state
+            ts = datetime.fromtimestamp(data.ts_event / 1_000_000_000, tz=timezone.utc) if data.ts_event else None
+            if not market_status.update(data.action.name, data.reason, ts):
+                return
+
+            self._structured_logger.bind(symbol=symbol).info(
+                "market_status_changed",
+                from_state=from_state,
+                to_state=market_status.state,
+                action=data.action.name,
+                reason=market_status.reason
+            )
+            self._record_market_status(symbol, market_status.state)
+
+        except Exception as e:
+            self.log.error(
+                f"instrument_status_update_error for {symbol}: {type(e).__name__} - {e}"
+            )
+
+    def _record_market_status(self, symbol: str, current: str) -> None:
+        """Set the market status gauge: 1 for the current state, 0 for the others."""
+        if not self.metrics:
+            return
+        for market_state in MARKET_STATES:
+            self.metrics.market_status.labels(symbol=symbol, state=market_state).set(
+                1 if market_state == current else 0
+            )
+
     # ========================================================================
     # US2: Coordination background loops
     # ========================================================================
@@ -815,6 +1608,12 @@ class MarketAnalyticsStrategy(Strategy):
 
             # Remove from owned
             self.owned_symbols.discard(symbol)
+            self.injections.pop(symbol, None)
+            if self.signal_publisher:
+                # The new owner starts from its own first report; ours would end stale anomalies on return
+                self.signal_publisher.forget(symbol)
+            if self.threshold_detector:
+                self.threshold_detector.forget(symbol)
 
             # T086: Update health status with owned symbols
             self.metrics.update_health_status(owned_symbols=list(self.owned_symbols))
@@ -841,9 +1640,47 @@ class MarketAnalyticsStrategy(Strategy):
     def _initialize_symbol(self, symbol: str):
         """Initialize symbol state."""
         if symbol not in self.symbol_states:
-            self.symbol_states[symbol] = SymbolState(symbol=symbol)
+            # Reports are hashes now; a JSON string left by an older producer would block HSET
+            drop_legacy_report(self.redis_client, symbol)
+            self.symbol_states[symbol] = SymbolState(
+                symbol=symbol,
+                flow_horizon_sec=max([10, self.flow_window_sec, *self.flow_windows]),
+                ingestion_thresholds=self.ingestion_overrides.get(symbol, self.ingestion_thresholds),
+                ingestion_min_dwell_ms=self.ingestion_min_dwell_ms,
+                notional_tier=self.notional_tiers[self.symbol_tiers.get(symbol, "default")],
+                poc_trend_window_sec=self.poc_trend_window_sec,
+                poc_trend_threshold_bps=self.poc_trend_threshold_bps,
+                disabled_detectors=self._disabled_detectors(symbol),
+                trade_tape_size=self.trade_tape_size,
+                reorder_window_ms=self.reorder_window_ms,
+            )
             self.log.info(f"symbol_state_initialized: {symbol}")
 
+    def _disabled_detectors(self, symbol: str) -> frozenset[str]:
+        """Deployment-wide disabled detectors with the symbol's overrides applied."""
+        disabled = set(self.disabled_detectors)
+        for detector, enabled in self.symbol_detectors.get(symbol, {}).items():
+            if enabled:
+                disabled.discard(detector)
+            else:
+                disabled.add(detector)
+        return frozenset(disabled)
+
+    def _quote_usd_rate(self, state: SymbolState) -> Optional[float]:
+        """USD value of one unit of the symbol's quote asset.
+
+        Stable quotes are taken at par; other quotes (e.g. BTC in ETHBTC) use
+        the last trade of a tracked "<quote><stable>" symbol, else None.
+        """
+        quote = state.meta.quote_asset
+        if quote in self.stable_quotes:
+            return 1.0
+        for stable in self.stable_quotes:
+            cross = self.symbol_states.get(f"{quote}{stable}")
+            if cross and cross.last_trade:
+                return cross.last_trade.price
+        return None
+
     def _subscribe_symbol(self, symbol: str):
         """Subscribe to market data for symbol."""
         try:
@@ -858,12 +1695,18 @@ class MarketAnalyticsStrategy(Strategy):
                 )
                 return
 
+            if symbol in self.symbol_states:
+                self.symbol_states[symbol].meta = SymbolMeta.from_instrument(instrument)
+
             # Subscribe to order book deltas (depth 20)
             self.subscribe_order_book_deltas(instrument_id, depth=20)
 
             # Subscribe to trade ticks
             self.subscribe_trade_ticks(instrument_id)
 
+            # Subscribe to venue trading status (halts, auctions, maintenance)
+            self.subscribe_instrument_status(instrument_id)
+
             self.log.info(f"subscriptions_created: {symbol} ({instrument_id})")
 
         except Exception as e:
@@ -877,6 +1720,7 @@ class MarketAnalyticsStrategy(Strategy):
             instrument_id = InstrumentId.from_str(f"{symbol}.BINANCE")
             self.unsubscribe_order_book_deltas(instrument_id)
             self.unsubscribe_trade_ticks(instrument_id)
+            self.unsubscribe_instrument_status(instrument_id)
             self.log.info(f"unsubscribed: {symbol}")
 
         except Exception as e:
@@ -892,6 +1736,21 @@ class MarketAnalyticsStrategy(Strategy):
         """Called when strategy stops. Cleanup resources."""
         self.log.info("analytics_strategy_stopping")
 
+        # Flush pending reports while leases are still held
+        if self.publish_queue:
+            self.publish_queue.close()
+            self.publish_queue = None
+        if self.secondary_publisher:
+            self.secondary_publisher.close()
+            self.secondary_publisher = None
+        if self.canary_publisher:
+            self.canary_publisher.compare()
+            self.canary_publisher = None
+
+        if self.deferred_detectors:
+            self.deferred_detectors.close()
+            self.deferred_detectors = None
+
         # US2: Cancel coordination background tasks
         if self.enable_coordination:
             self.log.info("stopping_coordination_tasks")
@@ -927,15 +1786,17 @@ class MarketAnalyticsStrategy(Strategy):
                 self.metrics.node_heartbeat.labels(node=self.node_id).set(0)
                 self.metrics.symbols_assigned.labels(node=self.node_id).set(0)
 
-        # Cancel timers
-        try:
-            self.clock.cancel_timer("fast_cycle")
-        except Exception as e:
-            self.log.error(
-                "timer_cancel_error",
-                timer="fast_cycle",
-                error=str(e)
-            )
+        # Cancel exactly the timers on_start set
+        for timer in self._timers:
+            try:
+                self.clock.cancel_timer(timer)
+            except Exception as e:
+                self.log.error(
+                    "timer_cancel_error",
+                    timer=timer,
+                    error=str(e)
+                )
+        self._timers.clear()
 
         # Unsubscribe from market data (only owned symbols)
         for symbol_str in list(self.owned_symbols):
@@ -943,6 +1804,7 @@ class MarketAnalyticsStrategy(Strategy):
                 instrument_id = InstrumentId.from_str(f"{symbol_str}.BINANCE")
                 self.unsubscribe_order_book_deltas(instrument_id)
                 self.unsubscribe_trade_ticks(instrument_id)
+                self.unsubscribe_instrument_status(instrument_id)
             except Exception as e:
                 self.log.error(
                     f"unsubscribe_failed for {symbol_str}: {e}"
@@ -0,0 +1,269 @@
+"""Export of closed days to object storage (S3, GCS or a local directory).
+
+Digests and daily statistics expire from Redis (NT_DIGEST_TTL_SEC,
+NT_DAILY_TTL_SEC). With NT_ARCHIVE_URL set, each completed UTC day is
+uploaded once per symbol, for long-term retention without a database:
+
+    {prefix}daily/date=2026-10-15/symbol=BTCUSDT.json
+    {prefix}digests/date=2026-10-15/symbol=BTCUSDT.jsonl.gz
+
+daily holds the day's daily:{symbol}:{date} document; digests holds the
+day's minute digests (digest:{symbol}:{minute}), one JSON document per line
+in minute order. Keys start with the kind and then the date, so bucket
+lifecycle rules can match a kind by prefix (e.g. move digests/ to cold
+storage after 30 days) and tools reading date=/symbol= partitions (DuckDB,
+Spark, Athena) can prune by both. Objects are written once and never
+modified; re-exporting a day writes the same keys.
+
+NT_ARCHIVE_URL selects the store:
+
+- s3://bucket/prefix: boto3, with the usual AWS credential chain
+  (AWS_ENDPOINT_URL for S3-compatible stores such as MinIO)
+- gs://bucket/prefix: google-cloud-storage, with application default credentials
+- file:///path: a local or mounted directory
+
+A day is exported after EXPORT_DELAY_SEC past its end, so its last digest
+has been rolled up. Exported days are recorded in archive:exported (field
+{symbol}:{date}); a node exports only while holding archive:lock, so with
+several producer nodes each day is uploaded once. The boto3 and
+google-cloud-storage clients are optional dependencies (poetry extras s3
+and gcs); only the one for the configured scheme is imported.
+"""
+import gzip
+import threading
+import time
+from datetime import datetime, timedelta, timezone
+from pathlib import Path
+from typing import Optional, Protocol
+from urllib.parse import urlsplit
+
+import redis
+import structlog
+
+from src.event_bus import EventBus
+from src.reporters.daily_stats import KEY_PREFIX as DAILY_PREFIX
+from src.reporters.digest import KEY_PREFIX as DIGEST_PREFIX
+
+log = structlog.get_logger()
+
+ARCHIVE_SCHEMES = ("s3", "gs", "file")
+
+EXPORTED_KEY = "archive:exported"
+LOCK_KEY = "archive:lock"
+
+# Wait after a UTC day ends before exporting it (its last minute digest is rolled up by then)
+EXPORT_DELAY_SEC = 600
+
+
+def object_key(prefix: str, kind: str, date: str, symbol: str) -> str:
+    """Object key of an exported day (kind is daily or digests)."""
+    extension = "json" if kind == "daily" else "jsonl.gz"
+    return f"{prefix}{kind}/date={date}/symbol={symbol}.{extension}"
+
+
+class ObjectStore(Protocol):
+    """Destination of exported objects."""
+
+    def put(self, key: str, body: bytes, content_type: str, content_encoding: Optional[str] = None) -> None: ...
+
+
+class S3Store:
+    """Writes to an S3 (or S3-compatible) bucket (boto3)."""
+
+    def __init__(self, bucket: str):
+        import boto3
+
+        self.bucket = bucket
+        self._client = boto3.client("s3")
+
+    def put(self, key: str, body: bytes, content_type: str, content_encoding: Optional[str] = None) -> None:
+        extra = {"ContentEncoding": content_encoding} if content_encoding else {}
+        self._client.put_object(Bucket=self.bucket, Key=key, Body=body, ContentType=content_type, **extra)
+
+
+class GcsStore:
+    """Writes to a Google Cloud Storage bucket (google-cloud-storage)."""
+
+    def __init__(self, bucket: str):
+        from google.cloud import storage
+
+        self._bucket = storage.Client().bucket(bucket)
+
+    def put(self, key: str, body: bytes, content_type: str, content_encoding: Optional[str] = None) -> None:
+        blob = self._bucket.blob(key)
+        blob.content_encoding = content_encoding
+        blob.upload_from_string(body, content_type=content_type)
+
+
+class LocalStore:
+    """Writes under a local directory (atomic rename, so readers never see partial files)."""
+
+    def __init__(self, root: str):
+        self.root = Path(root)
+
+    def put(self, key: str, body: bytes, content_type: str, content_encoding: Optional[str] = None) -> None:
+        path = self.root / key
+        path.parent.mkdir(parents=True, exist_ok=True)
+        tmp = path.with_name(path.name + ".tmp")
+        tmp.write_bytes(body)
+        tmp.replace(path)
+
+
+def create_store(url: str) -> tuple[ObjectStore, str]:
+    """Store for the URL's scheme and the key prefix within it ("" or ending in "/")."""
+    parts = urlsplit(url)
+    if parts.scheme == "file":
+        return LocalStore(parts.path), ""
+    prefix = parts.path.strip("/")
+    prefix = f"{prefix}/" if prefix else ""
+    if parts.scheme == "s3":
+        return S3Store(parts.netloc), prefix
+    if parts.scheme == "gs":
+        return GcsStore(parts.netloc), prefix
+    raise ValueError(f"NT_ARCHIVE_URL must be s3://, gs:// or file://, got {url.split(':')[0]}")
+
+
+class ArchiveExporter:
+    """Uploads closed days' digests and daily statistics to object storage from a background thread."""
+
+    def __init__(
+        self,
+        redis_client: EventBus,
+        store: ObjectStore,
+        prefix: str = "",
+        interval_sec: float = 3600.0,
+        metrics=None,
+    ):
+        """Initialize exporter.
+
+        Args:
+            redis_client: Redis client for the report cache
+            store: Destination (see create_store)
+            prefix: Key prefix within the store ("" or ending in "/")
+            interval_sec: Seconds between export runs
+            metrics: Optional PrometheusMetrics for export counts
+        """
+        self.redis_client = redis_client
+        self.store = store
+        self.prefix = prefix
+        self.interval_sec = interval_sec
+        self.metrics = metrics
+
+        self._stop = threading.Event()
+        self._thread: Optional[threading.Thread] = None
+        self.log = log.bind(component="archive_export")
+
+    def export_once(self, date: Optional[str] = None, dry_run: bool = False, now: Optional[float] = None) -> dict:
+        """Export every closed day not exported yet, or one day again.
+
+        Args:
+            date: Only this day (YYYY-MM-DD), whether exported before or not
+            dry_run: Only list what would be exported
+            now: Current Unix time (default: now)
+
+        Returns:
+            Summary with exported (keys written), failed and latency_ms
+        """
+        start = time.perf_counter()
+        summary = {"dry_run": dry_run, "exported": [], "failed": []}
+        lock_ttl = max(int(self.interval_sec), 60)
+        if not dry_run and not self.redis_client.set(LOCK_KEY, "1", nx=True, ex=lock_ttl):
+            summary["skipped"] = "locked"
+            return summary
+
+        try:
+            exported = self.redis_client.hgetall(EXPORTED_KEY) or {}
+            for symbol, day in self._closed_days(now if now is not None else time.time()):
+                if date is not None and day != date:
+                    continue
+                if date is None and f"{symbol}:{day}" in exported:
+                    continue
+                try:
+                    keys = self._export_day(symbol, day, dry_run)
+                except redis.RedisError:
+                    raise
+                except Exception as e:
+                    # Store client errors (botocore, google.api_core, OSError) share no base class
+                    self.log.warning("archive_export_failed", symbol=symbol, date=day, error=str(e))
+                    summary["failed"].append(f"{symbol}:{day}")
+                    self._count("failed")
+                    continue
+                summary["exported"].extend(keys)
+                if not dry_run:
+                    self.redis_client.hset(EXPORTED_KEY, f"{symbol}:{day}", int(time.time()))
+                    self._count("exported")
+        finally:
+            if not dry_run:
+                self.redis_client.delete(LOCK_KEY)
+
+        summary["latency_ms"] = round((time.perf_counter() - start) * 1000, 2)
+        if self.metrics and not dry_run and not summary["failed"]:
+            self.metrics.archive_last_export.set(time.time())
+        self.log.info(
+            "archive_export",
+            dry_run=dry_run,
+            exported=len(summary["exported"]),
+            failed=len(summary["failed"]),
+            latency_ms=summary["latency_ms"],
+        )
+        return summary
+
+    def _closed_days(self, now: float) -> list[tuple[str, str]]:
+        """(symbol, date) of daily statistics whose day ended at least EXPORT_DELAY_SEC ago."""
+        cutoff = datetime.fromtimestamp(now - EXPORT_DELAY_SEC, tz=timezone.utc).date()
+        days = set()
+        for key in self.redis_client.scan_iter(match=f"{DAILY_PREFIX}*", count=500):
+            symbol, _, day = key[len(DAILY_PREFIX):].rpartition(":")
+            try:
+                ended = datetime.strptime(day, "%Y-%m-%d").date() + timedelta(days=1) <= cutoff
+            except ValueError:
+                continue
+            if symbol and ended:
+                days.add((symbol, day))
+        return sorted(days, key=lambda item: (item[1], item[0]))
+
+    def _export_day(self, symbol: str, day: str, dry_run: bool) -> list[str]:
+        """Upload a symbol's day; returns the keys written."""
+        written = []
+        daily = self.redis_client.get(f"{DAILY_PREFIX}{symbol}:{day}")
+        if daily is not None:
+            key = object_key(self.prefix, "daily", day, symbol)
+            if not dry_run:
+                self.store.put(key, daily.encode() if isinstance(daily, str) else daily, "application/json")
+            written.append(key)
+
+        digest_keys = sorted(self.redis_client.scan_iter(match=f"{DIGEST_PREFIX}{symbol}:{day}T*", count=500))
+        digests = [d for d in self.redis_client.mget(digest_keys) if d is not None] if digest_keys else []
+        if digests:
+            key = object_key(self.prefix, "digests", day, symbol)
+            if not dry_run:
+                lines = "".join(f"{d if isinstance(d, str) else d.decode()}\n" for d in digests)
+                # mtime=0 keeps re-exports byte-identical
+                body = gzip.compress(lines.encode(), mtime=0)
+                self.store.put(key, body, "application/x-ndjson", content_encoding="gzip")
+            written.append(key)
+        return written
+
+    def _count(self, result: str) -> None:
+        if self.metrics:
+            self.metrics.archive_days.labels(result=result).inc()
+
+    def _run(self) -> None:
+        while not self._stop.wait(self.interval_sec):
+            try:
+                self.export_once()
+            except (redis.RedisError, OSError) as e:
+                self.log.warning("archive_export_run_failed", error=str(e))
+
+    def start(self) -> None:
+        """Start exporting in a background thread."""
+        self._thread = threading.Thread(target=self._run, name="archive-export", daemon=True)
+        self._thread.start()
+        self.log.info("archive_export_started", prefix=self.prefix, interval_sec=self.interval_sec)
+
+    def stop(self) -> None:
+        """Stop the background thread."""
+        self._stop.set()
+        if self._thread:
+            self._thread.join(timeout=5)
+            self._thread = None
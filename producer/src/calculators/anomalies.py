@@ -1,97 +1,121 @@
 """Anomaly detection for market microstructure analysis.
 
-Detects spoofing, iceberg orders, and flash crash risk signals.
+Detects spoofing, iceberg orders, absorption, flash crash risk, and quote stuffing signals,
+plus clock skew between the producer and the venue (a data-quality warning).
 """
 import numpy as np
 from typing import Optional
 from datetime import datetime, timezone, timedelta
+from src.state.level_lifetime import PulledLevel
 from src.state.symbol_state import TradeTick, OrderBookL2, PriceQty
+from src.calculators.notional import DEFAULT_NOTIONAL_TIERS, NotionalTier
+
+# Detector names accepted by NT_DISABLED_DETECTORS and NT_SYMBOL_DETECTORS
+ANOMALY_DETECTORS = ("spoofing", "iceberg", "absorption", "flash_crash_risk", "quote_stuffing")
+
+# One severity step up, for signals corroborated by book churn
+_ESCALATED = {"low": "medium", "medium": "high", "high": "high"}
 
 
 def detect_spoofing(
     order_book: OrderBookL2,
     mid_price: float,
-    cancel_rate_threshold: float = 0.70,
-    distance_threshold_bps: int = 50
+    pulled_levels: list[PulledLevel],
+    max_lifetime_ms: int = 2000,
+    notional_tier: NotionalTier = DEFAULT_NOTIONAL_TIERS["default"],
+    quote_usd_rate: float = 1.0,
+    churn_per_sec: Optional[float] = None,
+    churn_threshold: float = 0.5
 ) -> list[dict]:
     """Detect potential spoofing activity.
 
-    Spoofing is characterized by large far-from-mid orders with high cancel rates.
-    Since we don't track individual order lifecycle, we use proxy signals:
-    - Large orders far from mid price (>50 bps)
-    - Sudden appearance/disappearance of large orders
+    Spoofing is a large order shown and pulled before it can trade. A large
+    resting order on its own is usually a genuine wall, so only levels that
+    were pulled count (state.level_lifetimes: removed with no trade at their
+    price):
+    - Short lifetime: the level rested at most max_lifetime_ms
+    - Large size: its largest quantity was over 2x the average visible level
+      on its side
+    Pulls at the same price within the window are reported once, with the
+    number of pulls. Severity is high above 5x the average size, medium above
+    3x, and raised one step when the level was pulled repeatedly or when at
+    least churn_threshold of the visible book turns over per second (before
+    the notional cap).
 
     Args:
         order_book: Current order book state
         mid_price: Current mid price
-        cancel_rate_threshold: Cancel rate threshold (default 70%)
-        distance_threshold_bps: Minimum distance from mid in basis points
+        pulled_levels: Levels pulled without trading over the detection window
+        max_lifetime_ms: Longest lifetime of a pulled level counted as spoofing
+        notional_tier: USD notional thresholds capping severity
+        quote_usd_rate: USD value of one quote-currency unit
+        churn_per_sec: Book churn over the microstructure window (None if unknown)
+        churn_threshold: Churn that corroborates spoofing
 
     Returns:
-        List of detected spoofing signals:
+        List of detected spoofing signals (with churn_per_sec when known):
         [{
             "type": "spoofing",
             "side": "bid" | "ask",
             "price": 43100.0,
             "quantity": 25.5,
             "distance_bps": 75,
+            "lifetime_ms": 850,
+            "pulls": 3,
+            "notional_usd": 1099050.0,
             "severity": "high" | "medium" | "low",
-            "note": "Large order far from mid, potential spoofing"
+            "note": "Large bid 25.50 at 75bps from mid pulled after 850ms without trading (3x), potential spoofing"
         }]
     """
-    anomalies = []
+    high_churn = churn_per_sec is not None and churn_per_sec >= churn_threshold
+    avg_qty = {
+        "bid": np.mean([q for _, q in order_book.top_bids]) if order_book.top_bids else 0,
+        "ask": np.mean([q for _, q in order_book.top_asks]) if order_book.top_asks else 0,
+    }
 
-    # T066: Check for large far-from-mid orders on bid side
-    for price, qty in order_book.top_bids[:10]:  # Check top 10 levels
-        distance_bps = abs((price - mid_price) / mid_price * 10000)
+    # (side, price) -> short-lived large pulls at that level
+    candidates: dict[tuple[str, float], list[PulledLevel]] = {}
+    for pull in pulled_levels:
+        if pull.lifetime_ms <= max_lifetime_ms and avg_qty[pull.side] and pull.qty > avg_qty[pull.side] * 2:
+            candidates.setdefault((pull.side, pull.price), []).append(pull)
 
-        if distance_bps > distance_threshold_bps:
-            # Calculate average quantity for comparison
-            avg_qty = np.mean([q for _, q in order_book.top_bids]) if order_book.top_bids else 0
-
-            if qty > avg_qty * 2:  # 2x average is suspicious
-                # Severity based on size and distance
-                if qty > avg_qty * 5 and distance_bps > 100:
-                    severity = "high"
-                elif qty > avg_qty * 3:
-                    severity = "medium"
-                else:
-                    severity = "low"
-
-                anomalies.append({
-                    "type": "spoofing",
-                    "side": "bid",
-                    "price": float(price),
-                    "quantity": float(qty),
-                    "distance_bps": int(distance_bps),
-                    "severity": severity,
-                    "note": f"Large bid {qty:.2f} at {distance_bps:.0f}bps from mid, potential spoofing"
-                })
-
-    # T066: Check for large far-from-mid orders on ask side
-    for price, qty in order_book.top_asks[:10]:
+    anomalies = []
+    for (side, price), pulls in candidates.items():
+        qty = max(pull.qty for pull in pulls)
+        lifetime_ms = min(pull.lifetime_ms for pull in pulls)
         distance_bps = abs((price - mid_price) / mid_price * 10000)
 
-        if distance_bps > distance_threshold_bps:
-            avg_qty = np.mean([q for _, q in order_book.top_asks]) if order_book.top_asks else 0
-
-            if qty > avg_qty * 2:
-                if qty > avg_qty * 5 and distance_bps > 100:
-                    severity = "high"
-                elif qty > avg_qty * 3:
-                    severity = "medium"
-                else:
-                    severity = "low"
-
-                anomalies.append({
-                    "type": "spoofing",
-                    "side": "ask",
-                    "price": float(price),
-                    "quantity": float(qty),
-                    "distance_bps": int(distance_bps),
-                    "severity": severity,
-                    "note": f"Large ask {qty:.2f} at {distance_bps:.0f}bps from mid, potential spoofing"
-                })
+        if qty > avg_qty[side] * 5:
+            severity = "high"
+        elif qty > avg_qty[side] * 3:
+            severity = "medium"
+        else:
+            severity = "low"
+        if len(pulls) > 1 or high_churn:
+            severity = _ESCALATED[severity]
+        notional_usd = price * qty * quote_usd_rate
+        severity = notional_tier.cap(severity, notional_usd)
+
+        repeated = f" ({len(pulls)}x)" if len(pulls) > 1 else ""
+        anomalies.append({
+            "type": "spoofing",
+            "side": side,
+            "price": float(price),
+            "quantity": float(qty),
+            "distance_bps": int(distance_bps),
+            "lifetime_ms": int(lifetime_ms),
+            "pulls": len(pulls),
+            "notional_usd": round(notional_usd, 2),
+            "severity": severity,
+            "note": (
+                f"Large {side} {qty:.2f} at {distance_bps:.0f}bps from mid pulled after {lifetime_ms}ms "
+                f"without trading{repeated}, potential spoofing"
+            )
+        })
+
+    if churn_per_sec is not None:
+        for anomaly in anomalies:
+            anomaly["churn_per_sec"] = round(churn_per_sec, 4)
 
     return anomalies
 
@@ -99,7 +123,9 @@ def detect_spoofing(
 def detect_iceberg(
     trades: list[TradeTick],
     order_book: OrderBookL2,
-    price_tolerance_pct: float = 0.10
+    price_tolerance_pct: float = 0.10,
+    notional_tier: NotionalTier = DEFAULT_NOTIONAL_TIERS["default"],
+    quote_usd_rate: float = 1.0
 ) -> list[dict]:
     """Detect potential iceberg orders.
 
@@ -109,6 +135,8 @@ def detect_iceberg(
         trades: Recent trade ticks (recommend 30s window)
         order_book: Current order book state
         price_tolerance_pct: Price tolerance for "same price" (default 0.10%)
+        notional_tier: USD notional thresholds capping severity (on total filled notional)
+        quote_usd_rate: USD value of one quote-currency unit
 
     Returns:
         List of detected iceberg signals:
@@ -118,6 +146,7 @@ def detect_iceberg(
             "price": 43250.5,
             "fill_count": 8,
             "total_volume": 45.5,
+            "notional_usd": 1967897.75,
             "severity": "high" | "medium" | "low",
             "note": "8 fills at same price with stable depth, potential iceberg"
         }]
@@ -167,6 +196,8 @@ def detect_iceberg(
                 severity = "medium"
             else:
                 severity = "low"
+            notional_usd = price_key * group["total_volume"] * quote_usd_rate
+            severity = notional_tier.cap(severity, notional_usd)
 
             anomalies.append({
                 "type": "iceberg",
@@ -174,6 +205,7 @@ def detect_iceberg(
                 "price": float(price_key),
                 "fill_count": fill_count,
                 "total_volume": float(group["total_volume"]),
+                "notional_usd": round(float(notional_usd), 2),
                 "severity": severity,
                 "note": f"{fill_count} fills at ~{price_key:.2f} with stable depth, potential iceberg"
             })
@@ -181,6 +213,109 @@ def detect_iceberg(
     return anomalies
 
 
+def detect_absorption(
+    trades: list[TradeTick],
+    order_book: OrderBookL2,
+    min_trades: int = 10,
+    min_dominance: float = 0.70,
+    max_price_move_bps: float = 2.0,
+    notional_tier: NotionalTier = DEFAULT_NOTIONAL_TIERS["default"],
+    quote_usd_rate: float = 1.0
+) -> list[dict]:
+    """Detect aggression absorbed by passive liquidity.
+
+    Absorption: one side dominates aggressive volume (e.g. heavy selling,
+    large negative net flow) but price does not move with it, because the
+    resting level being hit keeps refilling. Signals:
+    - Aggressor share of window volume >= min_dominance
+    - Price moved <= max_price_move_bps in the aggressor's direction
+    - More volume traded at the most-hit level than is visible there now,
+      and the level is still in the book
+
+    Severity by absorbed / visible quantity (>=5x high, >=2x medium),
+    capped by the absorbed notional.
+
+    Args:
+        trades: Recent trade ticks, oldest first (recommend 30s window)
+        order_book: Current order book state
+        min_trades: Minimum trades in the window
+        min_dominance: Minimum aggressor share of volume
+        max_price_move_bps: Largest move in the aggressor's direction still counted as absorbed
+        notional_tier: USD notional thresholds capping severity (on absorbed notional)
+        quote_usd_rate: USD value of one quote-currency unit
+
+    Returns:
+        List with at most one absorption signal:
+        [{
+            "type": "absorption",
+            "side": "bid" | "ask",  # Side absorbing the aggression
+            "price": 43250.5,
+            "absorbed_volume": 38.2,
+            "visible_qty": 4.1,
+            "price_move_bps": 0.4,
+            "notional_usd": 1652169.1,
+            "severity": "high" | "medium" | "low",
+            "note": "38.20 sold into bid at 43250.50 (4.10 visible), price held, potential absorption"
+        }]
+    """
+    if len(trades) < min_trades:
+        return []
+
+    buy_volume = sum(t.volume for t in trades if t.aggressor_side == "BUY")
+    sell_volume = sum(t.volume for t in trades if t.aggressor_side != "BUY")
+    total_volume = buy_volume + sell_volume
+    if total_volume <= 0:
+        return []
+
+    selling = sell_volume >= buy_volume
+    if max(buy_volume, sell_volume) / total_volume < min_dominance:
+        return []
+
+    # Move in the aggressor's direction (positive = price gave way)
+    first, last = trades[0].price, trades[-1].price
+    move_bps = (first - last if selling else last - first) / first * 10000
+    if move_bps > max_price_move_bps:
+        return []
+
+    # Most-hit level by the dominant aggressor
+    volume_by_price: dict[float, float] = {}
+    for trade in trades:
+        if (trade.aggressor_side != "BUY") == selling:
+            volume_by_price[trade.price] = volume_by_price.get(trade.price, 0.0) + trade.volume
+    price, absorbed = max(volume_by_price.items(), key=lambda item: item[1])
+
+    # The level must still be resting, with less shown than was traded into it
+    visible = (order_book.bids if selling else order_book.asks).get(price, 0.0)
+    if visible <= 0 or absorbed < visible:
+        return []
+
+    ratio = absorbed / visible
+    if ratio >= 5.0:
+        severity = "high"
+    elif ratio >= 2.0:
+        severity = "medium"
+    else:
+        severity = "low"
+    notional_usd = price * absorbed * quote_usd_rate
+    severity = notional_tier.cap(severity, notional_usd)
+
+    side = "bid" if selling else "ask"
+    return [{
+        "type": "absorption",
+        "side": side,
+        "price": float(price),
+        "absorbed_volume": round(float(absorbed), 8),
+        "visible_qty": float(visible),
+        "price_move_bps": round(float(move_bps), 2),
+        "notional_usd": round(float(notional_usd), 2),
+        "severity": severity,
+        "note": (
+            f"{absorbed:.2f} {'sold into bid' if selling else 'bought from ask'} at {price:.2f} "
+            f"({visible:.2f} visible), price held, potential absorption"
+        )
+    }]
+
+
 def detect_flash_crash_risk(
     spread_bps: float,
     depth_imbalance: float,
@@ -253,6 +388,100 @@ def detect_flash_crash_risk(
     }
 
 
+def detect_quote_stuffing(
+    updates_per_sec: float,
+    baseline_updates_per_sec: Optional[float],
+    churn_per_sec: float,
+    min_updates_per_sec: float = 20.0,
+    min_ratio: float = 5.0,
+    min_churn_per_sec: float = 0.1
+) -> Optional[dict]:
+    """Detect quote stuffing: a burst of book updates far above the baseline rate.
+
+    Requires the update rate to be at least min_ratio times the 5-minute
+    baseline and above min_updates_per_sec, with churn of at least
+    min_churn_per_sec so bursts of refreshes that leave quantities unchanged
+    (e.g. snapshot resends) are not flagged.
+
+    Args:
+        updates_per_sec: Book update rate over the microstructure window
+        baseline_updates_per_sec: Book update rate over the baseline window
+            (None until the baseline window is covered, which skips detection)
+        churn_per_sec: Book churn over the microstructure window
+        min_updates_per_sec: Absolute update rate floor
+        min_ratio: Update rate multiple of the baseline
+        min_churn_per_sec: Churn floor
+
+    Returns:
+        Quote stuffing signal or None:
+        {
+            "type": "quote_stuffing",
+            "updates_per_sec": 240.0,
+            "baseline_updates_per_sec": 18.5,
+            "churn_per_sec": 1.35,
+            "severity": "high" | "medium" | "low",
+            "note": "Book updates at 13.0x baseline (240.0/s) with 1.35/s churn"
+        }
+        Severity by rate multiple: >= 20x high, >= 10x medium, else low.
+    """
+    if updates_per_sec < min_updates_per_sec or churn_per_sec < min_churn_per_sec:
+        return None
+    if not baseline_updates_per_sec:
+        return None
+
+    ratio = updates_per_sec / baseline_updates_per_sec
+    if ratio < min_ratio:
+        return None
+
+    if ratio >= 20:
+        severity = "high"
+    elif ratio >= 10:
+        severity = "medium"
+    else:
+        severity = "low"
+
+    return {
+        "type": "quote_stuffing",
+        "updates_per_sec": round(updates_per_sec, 2),
+        "baseline_updates_per_sec": round(baseline_updates_per_sec, 2),
+        "churn_per_sec": round(churn_per_sec, 4),
+        "severity": severity,
+        "note": f"Book updates at {ratio:.1f}x baseline ({updates_per_sec:.1f}/s) with {churn_per_sec:.2f}/s churn"
+    }
+
+
+def detect_clock_skew(skew_ms: Optional[float], threshold_ms: float = 1000.0) -> Optional[dict]:
+    """Warn when the local clock is off from the venue's by more than threshold_ms.
+
+    Not a market signal: venue-time lags in the report are compensated
+    for the skew, but timestamps compared across hosts (e.g. updatedAt in
+    the MCP server's cache age) are not, so the host clock needs fixing.
+
+    Args:
+        skew_ms: Estimated local-minus-venue offset (ClockSkewEstimator.skew_ms)
+        threshold_ms: Absolute skew above which the warning is raised
+
+    Returns:
+        Clock skew warning or None:
+        {
+            "type": "clock_skew",
+            "skew_ms": 1520.4,
+            "severity": "medium",
+            "note": "Local clock 1520ms ahead of the venue; check time sync (NTP)"
+        }
+    """
+    if skew_ms is None or abs(skew_ms) <= threshold_ms:
+        return None
+
+    direction = "ahead of" if skew_ms > 0 else "behind"
+    return {
+        "type": "clock_skew",
+        "skew_ms": round(skew_ms, 1),
+        "severity": "medium",
+        "note": f"Local clock {abs(skew_ms):.0f}ms {direction} the venue; check time sync (NTP)"
+    }
+
+
 def calculate_flow_acceleration(
     trades: list[TradeTick],
     window_sec: int = 10
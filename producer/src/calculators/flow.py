@@ -1,26 +1,35 @@
 """Order flow and trade rate calculations."""
-from datetime import datetime, timedelta, timezone
 from typing import Optional
 from ..state.symbol_state import SymbolState
 
+# Multi-window flow defaults (10s, 1m, 5m)
+DEFAULT_FLOW_WINDOWS = (10, 60, 300)
+
+
+def window_label(window_seconds: int) -> str:
+    """Format a window length as a report key (e.g., 10 -> "10s", 300 -> "5m")."""
+    if window_seconds % 3600 == 0:
+        return f"{window_seconds // 3600}h"
+    if window_seconds % 60 == 0:
+        return f"{window_seconds // 60}m"
+    return f"{window_seconds}s"
+
 
 def calculate_orders_per_sec(state: SymbolState, window_seconds: int = 10) -> float:
     """Calculate order flow rate (trades per second) over time window.
 
     Args:
-        state: Symbol state with trade buffers
+        state: Symbol state with flow counters
         window_seconds: Time window in seconds (default 10)
 
     Returns:
         Trades per second over the window
     """
-    cutoff = datetime.now(timezone.utc) - timedelta(seconds=window_seconds)
-    recent_trades = state.trade_buffer_10s.filter_by_time(cutoff)
+    trade_count = state.flow.window(window_seconds).trade_count
 
-    if not recent_trades:
+    if not trade_count:
         return 0.0
 
-    trade_count = len(recent_trades)
     trades_per_sec = trade_count / window_seconds
 
     return round(trades_per_sec, 2)
@@ -33,31 +42,76 @@ def calculate_net_flow(state: SymbolState, window_seconds: int = 30) -> Optional
     Negative net flow indicates selling pressure (bearish).
 
     Args:
-        state: Symbol state with trade buffers
+        state: Symbol state with flow counters
         window_seconds: Time window in seconds (default 30)
 
     Returns:
         Dictionary with buy_volume, sell_volume, net_flow, or None if no trades
     """
-    cutoff = datetime.now(timezone.utc) - timedelta(seconds=window_seconds)
-    recent_trades = state.trade_buffer_30s.filter_by_time(cutoff)
+    totals = state.flow.window(window_seconds)
 
-    if not recent_trades:
+    if not totals.trade_count:
         return None
 
-    buy_volume = 0.0
-    sell_volume = 0.0
+    net_flow = totals.buy_volume - totals.sell_volume
+
+    return {
+        "buy_volume": round(totals.buy_volume, 8),
+        "sell_volume": round(totals.sell_volume, 8),
+        "net_flow": round(net_flow, 8),
+    }
+
+
+def calculate_trade_breakdown(state: SymbolState, window_seconds: int) -> dict:
+    """Calculate trade rate and buy/sell breakdown over time window.
 
-    for trade in recent_trades:
-        if trade.aggressor_side == "BUY":
-            buy_volume += trade.volume
-        elif trade.aggressor_side == "SELL":
-            sell_volume += trade.volume
+    Trade counts and average size distinguish one large order from many
+    small ones behind the same net flow.
 
-    net_flow = buy_volume - sell_volume
+    Args:
+        state: Symbol state with flow counters
+        window_seconds: Time window in seconds
+
+    Returns:
+        Dictionary with trades_per_sec, buy/sell volume and trade counts,
+        net_flow and avg_trade_size (zeros if no trades)
+    """
+    totals = state.flow.window(window_seconds)
+
+    trade_count = totals.trade_count
+    total_volume = totals.buy_volume + totals.sell_volume
+    avg_trade_size = total_volume / trade_count if trade_count else 0.0
 
     return {
-        "buy_volume": round(buy_volume, 8),
-        "sell_volume": round(sell_volume, 8),
-        "net_flow": round(net_flow, 8),
+        "trades_per_sec": round(trade_count / window_seconds, 2),
+        "buy_volume": round(totals.buy_volume, 8),
+        "sell_volume": round(totals.sell_volume, 8),
+        "buy_trade_count": totals.buy_count,
+        "sell_trade_count": totals.sell_count,
+        "net_flow": round(totals.buy_volume - totals.sell_volume, 8),
+        "avg_trade_size": round(avg_trade_size, 8),
     }
+
+
+def calculate_flow_windows(state: SymbolState, windows=DEFAULT_FLOW_WINDOWS) -> dict:
+    """Calculate flow metrics for each configured window.
+
+    Windows are served from the symbol's per-second flow counters, which
+    must cover the longest window (see SymbolState flow_horizon_sec).
+
+    Args:
+        state: Symbol state with flow counters
+        windows: Window lengths in seconds
+
+    Returns:
+        Map of window label (e.g., "1m") to orders_per_sec plus the
+        trade breakdown from calculate_trade_breakdown
+    """
+    result = {}
+    for window_seconds in sorted(windows):
+        breakdown = calculate_trade_breakdown(state, window_seconds)
+        result[window_label(window_seconds)] = {
+            "orders_per_sec": breakdown["trades_per_sec"],
+            **breakdown,
+        }
+    return result
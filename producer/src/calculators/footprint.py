@@ -0,0 +1,87 @@
+"""Footprint (imbalance-at-price) aggregation of executed volume.
+
+Buckets the trades of a rolling window by price and splits each bucket's
+volume by aggressor side, as in a footprint chart. Levels where one side
+dominated show where aggressive buyers or sellers were concentrated.
+"""
+import math
+from datetime import datetime, timedelta
+from typing import Optional
+
+from src.state.symbol_state import TradeTick
+
+
+def _nice_step(raw: float) -> float:
+    """Round a bucket size up to 1, 2 or 5 x 10^k so levels stay put as price drifts."""
+    magnitude = 10 ** math.floor(math.log10(raw))
+    for multiple in (1, 2, 5):
+        if raw <= multiple * magnitude:
+            return float(round(multiple * magnitude, 12))
+    return float(round(10 * magnitude, 12))
+
+
+def calculate_footprint(
+    trades: list[TradeTick],
+    now: datetime,
+    window_sec: int = 300,
+    bucket_bps: float = 1.0,
+    top_n: int = 5
+) -> Optional[dict]:
+    """Aggregate buy vs sell executed volume per price level.
+
+    Args:
+        trades: Recent trade ticks (oldest first)
+        now: End of the window
+        window_sec: Rolling window length
+        bucket_bps: Approximate bucket width in bps of the last price
+            (rounded to a 1/2/5 step)
+        top_n: Number of most imbalanced levels to report
+
+    Returns:
+        None without trades in the window, otherwise:
+        {
+            "window_sec": 300,
+            "bucket_size": 5.0,
+            "levels": [  # Highest price first
+                {"price": 64005.0, "buy_volume": 3.2, "sell_volume": 0.4,
+                 "delta": 2.8, "imbalance": 0.78, "trade_count": 41},
+                ...
+            ],
+            "top_imbalances": [...]  # top_n levels by |delta|
+        }
+        price is the lower bound of the bucket and imbalance is
+        delta / (buy_volume + sell_volume), from -1 (all sells) to 1.
+    """
+    cutoff = now - timedelta(seconds=window_sec)
+    window = [t for t in trades if t.timestamp >= cutoff]
+    if not window:
+        return None
+
+    bucket_size = _nice_step(window[-1].price * bucket_bps / 10_000)
+    buckets: dict[int, dict] = {}
+    for trade in window:
+        index = math.floor(trade.price / bucket_size)
+        level = buckets.setdefault(index, {"buy_volume": 0.0, "sell_volume": 0.0, "trade_count": 0})
+        level["buy_volume" if trade.aggressor_side == "BUY" else "sell_volume"] += trade.volume
+        level["trade_count"] += 1
+
+    levels = []
+    for index in sorted(buckets, reverse=True):
+        level = buckets[index]
+        buy, sell = level["buy_volume"], level["sell_volume"]
+        levels.append({
+            "price": round(index * bucket_size, 10),
+            "buy_volume": round(buy, 8),
+            "sell_volume": round(sell, 8),
+            "delta": round(buy - sell, 8),
+            "imbalance": round((buy - sell) / (buy + sell), 4) if buy + sell > 0 else 0.0,
+            "trade_count": level["trade_count"],
+        })
+
+    top = sorted(levels, key=lambda level: abs(level["delta"]), reverse=True)[:top_n]
+    return {
+        "window_sec": window_sec,
+        "bucket_size": bucket_size,
+        "levels": levels,
+        "top_imbalances": top,
+    }
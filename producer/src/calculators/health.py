@@ -6,7 +6,8 @@ def calculate_health_score(
     data_age_ms: Optional[int],
     spread_bps: Optional[float],
     imbalance: Optional[float],
-    has_anomalies: bool = False
+    has_anomalies: bool = False,
+    market_state: str = "open"
 ) -> dict:
     """Calculate overall market health score.
 
@@ -16,11 +17,15 @@ def calculate_health_score(
     - Depth balance: abs(imbalance)<0.3=balanced, <0.6=moderate, >=0.6=imbalanced
     - Anomalies: presence of detected anomalies degrades score
 
+    While the market is not open (halt, auction, maintenance, close) a quiet
+    feed is expected, so freshness and anomalies are not penalized.
+
     Args:
         data_age_ms: Age of last market data update in milliseconds
         spread_bps: Bid-ask spread in basis points
         imbalance: Order book imbalance [-1, 1]
         has_anomalies: Whether anomalies detected
+        market_state: Venue trading status (see state/market_status.py)
 
     Returns:
         Dictionary with status ("ok"|"degraded"|"down") and numerical score [0-100]
@@ -29,7 +34,11 @@ def calculate_health_score(
     issues = []
 
     # Data freshness scoring (40 points)
-    if data_age_ms is None:
+    market_open = market_state == "open"
+    if not market_open:
+        issues.append(f"market_{market_state}")
+        status = "ok"
+    elif data_age_ms is None:
         score -= 40
         issues.append("no_data")
         status = "down"
@@ -70,7 +79,7 @@ def calculate_health_score(
             issues.append("moderate_imbalance")
 
     # Anomaly detection (10 points)
-    if has_anomalies:
+    if has_anomalies and market_open:
         score -= 10
         issues.append("anomalies_detected")
         if status == "ok":
@@ -84,3 +93,63 @@ def calculate_health_score(
         "score": round(score, 1),
         "issues": issues,
     }
+
+
+# Health v2 component weights (sum to 1)
+HEALTH_V2_WEIGHTS = {"freshness": 0.4, "spread": 0.3, "balance": 0.2, "anomalies": 0.1}
+
+# Anomaly penalty per severity in the v2 anomalies component
+HEALTH_V2_ANOMALY_PENALTY = {"low": 25.0, "medium": 50.0, "high": 100.0}
+
+
+def calculate_health_score_v2(
+    data_age_ms: Optional[int],
+    spread_bps: Optional[float],
+    imbalance: Optional[float],
+    anomaly_severities: list[str],
+    market_state: str = "open"
+) -> dict:
+    """Redesigned health score with continuous, per-component scoring.
+
+    Each component is scored 0-100 and the overall score is their weighted
+    sum (HEALTH_V2_WEIGHTS), so the score moves smoothly instead of in the
+    fixed steps of calculate_health_score:
+    - freshness: 100 up to 1000ms, falling linearly to 0 at 2000ms
+    - spread: 100 up to 10bps, falling linearly to 0 at 100bps
+    - balance: 100 * (1 - abs(imbalance))
+    - anomalies: 100 less a per-severity penalty for each detected anomaly
+
+    As in v1, freshness and anomalies are not penalized while the market is
+    not open.
+
+    Returns:
+        Dictionary with the score [0-100] and its components
+    """
+    market_open = market_state == "open"
+
+    if not market_open:
+        freshness = 100.0
+    elif data_age_ms is None:
+        freshness = 0.0
+    else:
+        freshness = 100.0 - min(100.0, max(0.0, (data_age_ms - 1000) / 10))
+
+    if spread_bps is None:
+        spread = 0.0
+    else:
+        spread = 100.0 - min(100.0, max(0.0, (spread_bps - 10) / 0.9))
+
+    balance = 50.0 if imbalance is None else 100.0 * (1 - min(1.0, abs(imbalance)))
+
+    anomalies = 100.0
+    if market_open:
+        penalty = sum(HEALTH_V2_ANOMALY_PENALTY.get(s, 0.0) for s in anomaly_severities)
+        anomalies = max(0.0, 100.0 - penalty)
+
+    components = {"freshness": freshness, "spread": spread, "balance": balance, "anomalies": anomalies}
+    score = sum(components[name] * weight for name, weight in HEALTH_V2_WEIGHTS.items())
+
+    return {
+        "score": round(score, 1),
+        "components": {name: round(value, 1) for name, value in components.items()},
+    }
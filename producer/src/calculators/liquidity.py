@@ -4,7 +4,9 @@ Includes volume profile (POC/VAH/VAL), liquidity walls, and vacuum detection.
 """
 import numpy as np
 from typing import Optional
+from src.state.quantile_sketch import QuantileSketch
 from src.state.symbol_state import TradeTick, OrderBookL2
+from src.calculators.notional import DEFAULT_NOTIONAL_TIERS, NotionalTier
 
 
 def calculate_volume_profile(
@@ -103,37 +105,47 @@ def calculate_volume_profile(
 
 def detect_liquidity_walls(
     order_book: OrderBookL2,
-    quantity_history: list[float],
-    side: str = "both"
+    quantity_sketch: QuantileSketch,
+    side: str = "both",
+    notional_tier: NotionalTier = DEFAULT_NOTIONAL_TIERS["default"],
+    quote_usd_rate: float = 1.0,
+    tick_size: Optional[float] = None,
+    value_area: Optional[tuple[float, float]] = None
 ) -> list[dict]:
     """Detect liquidity walls in the order book.
 
     Liquidity walls are large concentrated orders significantly above normal size.
-    Uses P95 percentile as baseline threshold.
+    Uses rolling P95 quantity as baseline threshold.
 
     Args:
         order_book: OrderBookL2 with current bid/ask levels
-        quantity_history: Historical quantities for percentile calculation
+        quantity_sketch: Rolling quantity distribution for percentile calculation
         side: "bid", "ask", or "both"
+        notional_tier: USD notional thresholds capping severity
+        quote_usd_rate: USD value of one quote-currency unit
+        tick_size: Price increment for distance_ticks (None until instruments load)
+        value_area: (VAL, VAH) of the volume profile for in_value_area
 
     Returns:
-        List of detected walls with structure:
+        List of detected walls, nearest to mid first, with structure:
         [{
             "side": "bid" | "ask",
             "price": 43250.5,
             "quantity": 15.5,
+            "notional_usd": 670382.75,
             "severity": "high" | "medium" | "low",
-            "distance_bps": 25  # Distance from mid price in basis points
+            "distance_bps": 25,  # Distance from mid price in basis points
+            "distance_ticks": 1081.5,  # Distance from mid price in ticks (None without tick_size)
+            "in_value_area": True  # Between VAL and VAH (None without a volume profile)
         }]
     """
     walls = []
 
     # T062: Calculate P95 threshold
-    if not quantity_history or len(quantity_history) < 10:
+    if quantity_sketch.sample_count < 10:
         return walls
 
-    quantities = np.array(quantity_history)
-    p95_threshold = np.percentile(quantities, 95, method='linear')
+    p95_threshold = quantity_sketch.quantile(0.95)
 
     # Calculate mid price for distance calculation
     best_bid = order_book.get_best_bid()
@@ -155,6 +167,8 @@ def detect_liquidity_walls(
                     severity = "medium"
                 else:
                     severity = "low"
+                notional_usd = price * qty * quote_usd_rate
+                severity = notional_tier.cap(severity, notional_usd)
 
                 # Calculate distance in basis points
                 distance_bps = abs((price - mid_price) / mid_price * 10000)
@@ -163,6 +177,7 @@ def detect_liquidity_walls(
                     "side": "bid",
                     "price": float(price),
                     "quantity": float(qty),
+                    "notional_usd": round(float(notional_usd), 2),
                     "severity": severity,
                     "distance_bps": int(distance_bps)
                 })
@@ -178,6 +193,8 @@ def detect_liquidity_walls(
                     severity = "medium"
                 else:
                     severity = "low"
+                notional_usd = price * qty * quote_usd_rate
+                severity = notional_tier.cap(severity, notional_usd)
 
                 # Calculate distance in basis points
                 distance_bps = abs((price - mid_price) / mid_price * 10000)
@@ -186,26 +203,34 @@ def detect_liquidity_walls(
                     "side": "ask",
                     "price": float(price),
                     "quantity": float(qty),
+                    "notional_usd": round(float(notional_usd), 2),
                     "severity": severity,
                     "distance_bps": int(distance_bps)
                 })
 
+    # Proximity annotations; walls near the market matter more than deep ones
+    for wall in walls:
+        distance = abs(wall["price"] - mid_price)
+        wall["distance_ticks"] = round(distance / tick_size, 1) if tick_size else None
+        wall["in_value_area"] = value_area[0] <= wall["price"] <= value_area[1] if value_area else None
+    walls.sort(key=lambda wall: abs(wall["price"] - mid_price))
+
     return walls
 
 
 def detect_liquidity_vacuums(
     order_book: OrderBookL2,
-    quantity_history: list[float],
+    quantity_sketch: QuantileSketch,
     side: str = "both"
 ) -> list[dict]:
     """Detect liquidity vacuums in the order book.
 
     Liquidity vacuums are consecutive levels with abnormally low quantities.
-    Uses P10 percentile as threshold for "thin" levels.
+    Uses rolling P10 quantity as threshold for "thin" levels.
 
     Args:
         order_book: OrderBookL2 with current bid/ask levels
-        quantity_history: Historical quantities for percentile calculation
+        quantity_sketch: Rolling quantity distribution for percentile calculation
         side: "bid", "ask", or "both"
 
     Returns:
@@ -221,11 +246,10 @@ def detect_liquidity_vacuums(
     vacuums = []
 
     # T064: Calculate P10 threshold
-    if not quantity_history or len(quantity_history) < 10:
+    if quantity_sketch.sample_count < 10:
         return vacuums
 
-    quantities = np.array(quantity_history)
-    p10_threshold = np.percentile(quantities, 10, method='linear')
+    p10_threshold = quantity_sketch.quantile(0.10)
 
     # T065: Detect vacuums on bid side (3+ consecutive thin levels)
     if side in ("bid", "both"):
@@ -0,0 +1,42 @@
+"""Quote update rate and order book churn."""
+from ..state.symbol_state import SymbolState
+
+# Window of the baseline update rate quote-stuffing detection compares against
+BASELINE_WINDOW_SEC = 300
+
+
+def calculate_microstructure(state: SymbolState, window_sec: int = 10) -> dict:
+    """Book update frequency and churn over a window.
+
+    Churn is the quantity added plus removed per second across the tracked
+    top levels, as a fraction of their average resting volume: 0.5 means
+    half of the visible book turns over every second. Rates are over the
+    part of the window since the first book update, so they are not
+    diluted while the symbol warms up.
+
+    Args:
+        state: Symbol state with book churn counters
+        window_sec: Measurement window (NT_CHURN_WINDOW_SEC)
+
+    Returns:
+        {
+            "window_sec": 10,
+            "quote_updates_per_sec": 42.3,
+            "baseline_updates_per_sec": 18.1,  # Over BASELINE_WINDOW_SEC (None until covered)
+            "added_qty_per_sec": 12.5,
+            "removed_qty_per_sec": 11.9,
+            "churn_per_sec": 0.31
+        }
+    """
+    totals = state.book_churn.window(window_sec)
+    baseline = state.book_churn.window(BASELINE_WINDOW_SEC)
+    return {
+        "window_sec": totals.window_sec,
+        "quote_updates_per_sec": round(totals.updates_per_sec, 2),
+        "baseline_updates_per_sec": (
+            round(baseline.updates_per_sec, 2) if baseline.observed_sec >= BASELINE_WINDOW_SEC else None
+        ),
+        "added_qty_per_sec": round(totals.added_per_sec, 8),
+        "removed_qty_per_sec": round(totals.removed_per_sec, 8),
+        "churn_per_sec": round(totals.churn_per_sec, 4),
+    }
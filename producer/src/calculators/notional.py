@@ -0,0 +1,53 @@
+"""USD notional thresholds for anomaly and liquidity wall severity.
+
+Detectors classify severity relative to the book (multiples of average or
+P95 quantity), which says nothing about absolute size: on a thin book a
+0.1 BTC order can be five times the average. A notional tier caps the
+size-relative severity by the order's USD notional (qty x price, converted
+with the symbol's quote-to-USD rate), so only economically large orders
+rate "high".
+Tiers are assigned per symbol (NT_SYMBOL_TIERS) because a "large" order on
+BTCUSDT is very different from one on a small-cap pair.
+"""
+from dataclasses import dataclass
+from typing import Optional
+
+SEVERITIES = ("low", "medium", "high")
+
+# Quote assets assumed worth 1 USD; NT_STABLE_QUOTES overrides
+DEFAULT_STABLE_QUOTES = ("USDT", "USDC", "FDUSD", "TUSD", "BUSD", "USD")
+
+
+def to_usd(quote_amount: float, quote_usd_rate: Optional[float]) -> Optional[float]:
+    """Convert a quote-currency amount to USD (None when the rate is unknown)."""
+    if quote_usd_rate is None:
+        return None
+    return round(quote_amount * quote_usd_rate, 2)
+
+
+@dataclass(frozen=True)
+class NotionalTier:
+    """Minimum USD notional for each severity above "low"."""
+    medium_usd: float = 50_000.0
+    high_usd: float = 250_000.0
+
+    def max_severity(self, notional_usd: float) -> str:
+        """Highest severity an order of this notional can have."""
+        if notional_usd >= self.high_usd:
+            return "high"
+        if notional_usd >= self.medium_usd:
+            return "medium"
+        return "low"
+
+    def cap(self, severity: str, notional_usd: float) -> str:
+        """Lower a size-relative severity to what the notional supports."""
+        ceiling = self.max_severity(notional_usd)
+        return min(severity, ceiling, key=SEVERITIES.index)
+
+
+# Built-in tiers; NT_NOTIONAL_TIERS overrides or adds tiers by name
+DEFAULT_NOTIONAL_TIERS = {
+    "major": NotionalTier(medium_usd=250_000.0, high_usd=1_000_000.0),
+    "default": NotionalTier(medium_usd=50_000.0, high_usd=250_000.0),
+    "small": NotionalTier(medium_usd=10_000.0, high_usd=50_000.0),
+}
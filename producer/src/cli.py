@@ -0,0 +1,403 @@
+"""Operational subcommands for the producer.
+
+Run from the same image as the service, either through the service entry
+point or directly (which skips importing NautilusTrader):
+
+    python -m src.main [--config FILE] serve
+    python -m src.cli check-config
+    python -m src.cli probe-redis
+    python -m src.cli list-symbols
+    python -m src.cli dump-report BTCUSDT
+    python -m src.cli inject-anomaly BTCUSDT --severity high --ttl-sec 60
+    python -m src.cli janitor --dry-run
+    python -m src.cli archive-export --date 2026-10-15
+    python -m src.cli canary-divergence
+    python -m src.cli feature-flag liquidity off --symbol ETHUSDT
+    python -m src.cli detector-eval datasets/spoofing.json --tolerance-ms 10000
+"""
+import argparse
+import json
+import os
+import sys
+import time
+
+from src.calculators.notional import NotionalTier
+from src.config import SETTINGS, ProducerConfig, load_config_file
+from src.detector_eval import DetectorEvaluator, load_dataset
+from src.archive_export import ArchiveExporter, create_store
+from src.janitor import RedisJanitor
+from src.redis_client import REDIS_ROLES, RedisClient
+from src.reporters.canary import DIVERGENCE_KEY
+from src.reporters.feature_flags import REPORT_SECTIONS, FeatureFlags, read_overrides, write_override
+from src.reporters.injection import (
+    ANOMALY_TYPES,
+    INGESTION_STATUSES,
+    SEVERITIES,
+    build_injection,
+    clear_injection,
+    write_injection,
+)
+from src.reporters.redis_cache import get_report
+
+LEASE_KEY_PREFIX = "report:writer:"
+
+
+def parse_args(argv: list[str] | None = None) -> argparse.Namespace:
+    """Parse command line arguments."""
+    parser = argparse.ArgumentParser(prog="producer", description="Context8 market data producer")
+    parser.add_argument(
+        "--config",
+        default=os.getenv("CONFIG_FILE"),
+        help="YAML or TOML config file (env vars override file values; default: $CONFIG_FILE)",
+    )
+    commands = parser.add_subparsers(dest="command")
+    commands.add_parser("serve", help="Run the producer (default)")
+    commands.add_parser("check-config", help="Validate configuration and print effective values")
+    config_cmd = commands.add_parser("config", help="Configuration commands")
+    config_commands = config_cmd.add_subparsers(dest="config_command", required=True)
+    config_commands.add_parser("validate", help="Same as check-config")
+    commands.add_parser("probe-redis", help="Check Redis connectivity, latency and stream state")
+    commands.add_parser("list-symbols", help="List configured symbols with report freshness and writer")
+    dump = commands.add_parser("dump-report", help="Print the cached report for a symbol")
+    dump.add_argument("symbol", help="Trading symbol (e.g., BTCUSDT)")
+    inject = commands.add_parser(
+        "inject-anomaly",
+        help="Inject a synthetic anomaly into a symbol's reports (requires NT_ALLOW_ANOMALY_INJECTION)",
+    )
+    inject.add_argument("symbol", help="Trading symbol (e.g., BTCUSDT)")
+    inject.add_argument("--type", dest="anomaly_type", choices=ANOMALY_TYPES, default="spoofing")
+    inject.add_argument("--severity", choices=SEVERITIES, default="high")
+    inject.add_argument("--health-score", type=int, help="Override the health score (0-100)")
+    inject.add_argument("--ingestion-status", choices=INGESTION_STATUSES, help="Override the ingestion status")
+    inject.add_argument("--ttl-sec", type=int, default=60, help="Seconds the injection stays active (default: 60)")
+    inject.add_argument("--clear", action="store_true", help="Remove an active injection instead")
+    janitor = commands.add_parser(
+        "janitor", help="Enforce key retention and remove orphaned symbol keys once, reporting reclaimed space"
+    )
+    janitor.add_argument("--dry-run", action="store_true", help="Report what would change without changing it")
+    archive = commands.add_parser(
+        "archive-export", help="Export closed days' digests and daily statistics to NT_ARCHIVE_URL once"
+    )
+    archive.add_argument("--date", help="Export only this day (YYYY-MM-DD), even if exported before")
+    archive.add_argument("--dry-run", action="store_true", help="List the objects that would be written")
+    commands.add_parser(
+        "canary-divergence", help="Print the last canary/stable divergence summary (NT_CANARY_MODULES)"
+    )
+    flag = commands.add_parser(
+        "feature-flag",
+        help="Show report section flags, or turn a section on/off (clear removes the override)",
+    )
+    flag.add_argument("section", nargs="?", choices=REPORT_SECTIONS, help="Report section")
+    flag.add_argument("state", nargs="?", choices=("on", "off", "clear"), help="New state")
+    flag.add_argument("--symbol", help="Only for this symbol (default: every symbol)")
+    evaluate = commands.add_parser(
+        "detector-eval",
+        help="Score anomaly detectors under the current config against labeled datasets (precision/recall/F1)",
+    )
+    evaluate.add_argument("datasets", nargs="+", help="Labeled dataset files (events plus ground-truth labels)")
+    evaluate.add_argument(
+        "--tolerance-ms", type=int, default=0,
+        help="How long after a label ends detections still count for it (default: 0)",
+    )
+    return parser.parse_args(argv)
+
+
+def validate_config(config_path: str | None) -> int:
+    """Validate configuration and print the effective values.
+
+    Returns:
+        Process exit code (0 when valid)
+    """
+    errors = []
+    unknown = []
+    if config_path:
+        try:
+            unknown = sorted(set(load_config_file(config_path)) - set(SETTINGS))
+        except ValueError as e:
+            print(f"invalid: {e}", file=sys.stderr)
+            return 1
+
+    config = None
+    try:
+        config = ProducerConfig.load(config_path)
+        config.validate()
+    except ValueError as e:
+        errors.append(str(e))
+
+    print(json.dumps({
+        "valid": not errors,
+        "config_file": config_path,
+        "errors": errors,
+        "unknown_settings": unknown,
+        "config": config.to_dict() if config else None,
+    }, indent=2))
+    return 1 if errors else 0
+
+
+def _redis(config: ProducerConfig, role: str = "cache") -> RedisClient:
+    return RedisClient.for_endpoint(config.redis_endpoint(role))
+
+
+def _probe_endpoint(config: ProducerConfig, role: str) -> dict:
+    endpoint = config.redis_endpoint(role)
+    client = _redis(config, role)
+    redis = client.get_client()
+    try:
+        start = time.perf_counter()
+        redis.ping()
+        result = {"ok": True, "url": endpoint.url, "tls": endpoint.tls,
+                  "ping_ms": round((time.perf_counter() - start) * 1000, 2)}
+        if role == "stream":
+            result["stream_key"] = config.stream_key
+            result["stream_length"] = redis.xlen(config.stream_key) if redis.exists(config.stream_key) else 0
+        return result
+    except Exception as e:
+        return {"ok": False, "url": endpoint.url, "tls": endpoint.tls, "error": str(e)}
+    finally:
+        client.close()
+
+
+def probe_redis(config: ProducerConfig) -> int:
+    """Check Redis connectivity and print server and stream state.
+
+    The report cache (REDIS_URL) is probed in full; separate stream and
+    pub/sub endpoints are listed under endpoints.
+    """
+    client = _redis(config)
+    redis = client.get_client()
+    try:
+        start = time.perf_counter()
+        redis.ping()
+        ping_ms = (time.perf_counter() - start) * 1000
+
+        info = redis.info("server")
+        memory = redis.info("memory")
+        endpoints = {role: _probe_endpoint(config, role) for role in REDIS_ROLES}
+
+        print(json.dumps({
+            "ok": all(endpoint["ok"] for endpoint in endpoints.values()),
+            "redis_url": config.redis_url,
+            "ping_ms": round(ping_ms, 2),
+            "redis_version": info.get("redis_version"),
+            "used_memory_human": memory.get("used_memory_human"),
+            "maxmemory_policy": memory.get("maxmemory_policy"),
+            "stream_key": config.stream_key,
+            "stream_length": endpoints["stream"].get("stream_length"),
+            "endpoints": endpoints,
+        }, indent=2))
+        return 0 if all(endpoint["ok"] for endpoint in endpoints.values()) else 1
+    except Exception as e:
+        print(json.dumps({"ok": False, "redis_url": config.redis_url, "error": str(e)}, indent=2))
+        return 1
+    finally:
+        client.close()
+
+
+def list_symbols(config: ProducerConfig) -> int:
+    """List configured and cached symbols with report age and lease owner."""
+    client = _redis(config)
+    redis = client.get_client()
+    try:
+        cached = {
+            key[len("report:"):]
+            for key in redis.scan_iter("report:*")
+            if not key.startswith(LEASE_KEY_PREFIX)
+        }
+        now_ms = int(time.time() * 1000)
+
+        rows = []
+        for symbol in sorted(cached | set(config.symbols)):
+            report = get_report(redis, symbol) if symbol in cached else None
+            updated_at = report.get("updatedAt") if report else None
+            rows.append({
+                "symbol": symbol,
+                "configured": symbol in config.symbols,
+                "cached": report is not None,
+                "age_ms": now_ms - updated_at if isinstance(updated_at, (int, float)) else None,
+                "health_score": (report.get("health") or {}).get("score") if report else None,
+                "writer": redis.get(f"{LEASE_KEY_PREFIX}{symbol}"),
+            })
+
+        print(json.dumps({"symbols": rows, "count": len(rows)}, indent=2))
+        return 0
+    finally:
+        client.close()
+
+
+def dump_report(config: ProducerConfig, symbol: str) -> int:
+    """Print the cached report for a symbol."""
+    client = _redis(config)
+    try:
+        report = get_report(client.get_client(), symbol.upper())
+        if report is None:
+            print(f"no report cached for {symbol.upper()}", file=sys.stderr)
+            return 1
+        print(json.dumps(report, indent=2))
+        return 0
+    finally:
+        client.close()
+
+
+def inject_anomaly(config: ProducerConfig, args: argparse.Namespace) -> int:
+    """Write (or clear) a synthetic anomaly injection for a symbol."""
+    if not config.nt_allow_anomaly_injection:
+        print("anomaly injection is disabled; set NT_ALLOW_ANOMALY_INJECTION=true (staging only)", file=sys.stderr)
+        return 1
+
+    symbol = args.symbol.upper()
+    client = _redis(config)
+    try:
+        if args.clear:
+            cleared = clear_injection(client.get_client(), symbol)
+            print(json.dumps({"symbol": symbol, "cleared": cleared}, indent=2))
+            return 0
+
+        try:
+            injection = build_injection(
+                anomaly_type=args.anomaly_type,
+                severity=args.severity,
+                health_score=args.health_score,
+                ingestion_status=args.ingestion_status,
+                ttl_sec=args.ttl_sec,
+                injected_by=os.getenv("USER", "cli"),
+            )
+        except ValueError as e:
+            print(f"invalid injection: {e}", file=sys.stderr)
+            return 1
+
+        write_injection(client.get_client(), symbol, injection)
+        print(json.dumps({"symbol": symbol, "injection": injection}, indent=2))
+        return 0
+    finally:
+        client.close()
+
+
+def run_janitor(config: ProducerConfig, dry_run: bool) -> int:
+    """Run one janitor sweep and print its summary."""
+    client = _redis(config)
+    try:
+        summary = RedisJanitor.for_config(config, client.get_client()).sweep_once(dry_run=dry_run)
+        print(json.dumps(summary, indent=2))
+        return 0
+    finally:
+        client.close()
+
+
+def archive_export(config: ProducerConfig, args: argparse.Namespace) -> int:
+    """Run one export to object storage and print its summary."""
+    if not config.nt_archive_url:
+        print("NT_ARCHIVE_URL is not set", file=sys.stderr)
+        return 1
+    client = _redis(config)
+    try:
+        store, prefix = create_store(config.nt_archive_url)
+        summary = ArchiveExporter(client.get_client(), store, prefix=prefix).export_once(
+            date=args.date, dry_run=args.dry_run
+        )
+        print(json.dumps(summary, indent=2))
+        return 1 if summary["failed"] or summary.get("skipped") else 0
+    finally:
+        client.close()
+
+
+def canary_divergence(config: ProducerConfig) -> int:
+    """Print the last canary/stable divergence summary."""
+    client = _redis(config)
+    try:
+        summary = client.get_client().get(DIVERGENCE_KEY)
+        if summary is None:
+            print("no canary divergence recorded; is NT_CANARY_MODULES set?", file=sys.stderr)
+            return 1
+        print(json.dumps(json.loads(summary), indent=2))
+        return 0
+    finally:
+        client.close()
+
+
+def feature_flag(config: ProducerConfig, args: argparse.Namespace) -> int:
+    """Show effective report section flags, or set a Redis override."""
+    if bool(args.section) != bool(args.state):
+        print("feature-flag takes both a section and a state (on, off or clear), or neither", file=sys.stderr)
+        return 2
+
+    client = _redis(config)
+    try:
+        redis = client.get_client()
+        if args.section:
+            enabled = None if args.state == "clear" else args.state == "on"
+            write_override(redis, args.section, enabled, args.symbol.upper() if args.symbol else None)
+
+        flags = FeatureFlags(config.nt_disabled_sections, config.nt_symbol_sections)
+        flags.refresh(redis)
+        print(json.dumps({
+            "overrides": read_overrides(redis),
+            "disabled": {symbol: sorted(flags.disabled(symbol)) for symbol in config.symbols},
+        }, indent=2))
+        return 0
+    finally:
+        client.close()
+
+
+def detector_eval(config: ProducerConfig, args: argparse.Namespace) -> int:
+    """Replay labeled datasets and print per-detector precision, recall, F1 and confusion counts."""
+    evaluator = DetectorEvaluator(
+        slow_period_ms=config.nt_slow_period_ms,
+        churn_window_sec=config.nt_churn_window_sec,
+        disabled_detectors=config.nt_disabled_detectors,
+        symbol_detectors=config.nt_symbol_detectors,
+        notional_tiers={
+            name: NotionalTier(medium_usd, high_usd)
+            for name, (medium_usd, high_usd) in config.nt_notional_tiers.items()
+        },
+        symbol_tiers=config.nt_symbol_tiers,
+        tolerance_ms=args.tolerance_ms,
+    )
+    try:
+        for path in args.datasets:
+            evaluator.evaluate(load_dataset(path))
+    except (OSError, ValueError, KeyError) as e:
+        print(f"invalid dataset: {e}", file=sys.stderr)
+        return 1
+
+    print(json.dumps(evaluator.summary(), indent=2))
+    return 0
+
+
+def run_command(args: argparse.Namespace) -> int:
+    """Run an operational subcommand, returning the process exit code."""
+    if args.command in ("check-config", "config"):
+        return validate_config(args.config)
+
+    try:
+        config = ProducerConfig.load(args.config)
+    except ValueError as e:
+        print(f"invalid configuration: {e}", file=sys.stderr)
+        return 1
+
+    if args.command == "probe-redis":
+        return probe_redis(config)
+    if args.command == "list-symbols":
+        return list_symbols(config)
+    if args.command == "dump-report":
+        return dump_report(config, args.symbol)
+    if args.command == "inject-anomaly":
+        return inject_anomaly(config, args)
+    if args.command == "janitor":
+        return run_janitor(config, args.dry_run)
+    if args.command == "archive-export":
+        return archive_export(config, args)
+    if args.command == "canary-divergence":
+        return canary_divergence(config)
+    if args.command == "feature-flag":
+        return feature_flag(config, args)
+    if args.command == "detector-eval":
+        return detector_eval(config, args)
+    raise ValueError(f"Unknown command: {args.command}")
+
+
+if __name__ == "__main__":
+    parsed = parse_args()
+    if parsed.command in (None, "serve"):
+        print("use `python -m src.main serve` to run the producer", file=sys.stderr)
+        sys.exit(2)
+    sys.exit(run_command(parsed))
@@ -1,11 +1,135 @@
-"""Configuration management for producer service."""
+"""Configuration management for producer service.
+
+Settings come from environment variables, optionally seeded from a YAML
+or TOML config file. File keys map to environment variable names
+(nested sections are joined with "_", so `nt: {report_period_ms: 250}`
+is NT_REPORT_PERIOD_MS), and variables already set in the environment
+override file values.
+"""
 import os
-from dataclasses import dataclass
-from typing import List
+import tomllib
+from dataclasses import asdict, dataclass
+from typing import Any, Dict, List
+from urllib.parse import urlsplit
+import yaml
 from dotenv import load_dotenv
 
+from src.calculators.anomalies import ANOMALY_DETECTORS
+from src.calculators.notional import DEFAULT_NOTIONAL_TIERS, DEFAULT_STABLE_QUOTES
+from src.event_bus import is_memory_url
+from src.reporters.canary import CANARY_MODULES
+from src.reporters.feature_flags import REPORT_SECTIONS
+from src.archive_export import ARCHIVE_SCHEMES, EXPORT_DELAY_SEC
+from src.coordinator.profiles import PROCESSING_PROFILES
+from src.redis_client import REDIS_ROLES, RedisEndpoint
+from src.signal_bridge import BROKER_SCHEMES, check_topic_template
+
 load_dotenv()
 
+# Environment variables read by ProducerConfig.from_env
+SETTINGS = (
+    "BINANCE_API_KEY", "BINANCE_API_SECRET", "BINANCE_BASE_URL_HTTP", "BINANCE_BASE_URL_WS",
+    "REDIS_URL", "REDIS_PASSWORD", "REDIS_TLS_CA_CERT", "REDIS_TLS_CERT", "REDIS_TLS_KEY",
+    "REDIS_STREAM_URL", "REDIS_STREAM_PASSWORD",
+    "REDIS_STREAM_TLS_CA_CERT", "REDIS_STREAM_TLS_CERT", "REDIS_STREAM_TLS_KEY",
+    "REDIS_PUBSUB_URL", "REDIS_PUBSUB_PASSWORD",
+    "REDIS_PUBSUB_TLS_CA_CERT", "REDIS_PUBSUB_TLS_CERT", "REDIS_PUBSUB_TLS_KEY",
+    "STREAM_KEY",
+    "SYMBOLS", "LOG_LEVEL", "NT_LOG_LEVEL", "NT_LOG_LEVELS", "NT_LOG_SAMPLE",
+    "NT_LOG_FILE", "NT_LOG_FILE_MAX_MB", "NT_LOG_FILE_BACKUPS", "NT_LOG_SYSLOG",
+    "NT_ENABLE_KV_REPORTS", "NT_ENABLE_STREAMS",
+    "NT_REPORT_PERIOD_MS", "NT_SLOW_PERIOD_MS", "NT_PROCESSING_PROFILE",
+    "NT_ENABLE_MULTI_INSTANCE", "NT_LEASE_TTL_MS", "NT_NODE_ID",
+    "NT_HRW_STICKY_PCT", "NT_MIN_HOLD_MS",
+    "NT_METRICS_PORT", "NT_METRICS_BASIC_AUTH", "NT_HEALTH_STALL_MS",
+    "NT_REPORT_METRICS_SINK", "NT_REPORT_METRICS_TARGET", "NT_REPORT_METRICS_MAXLEN",
+    "NT_PARQUET_DIR", "NT_PARQUET_FLUSH_SEC",
+    "FLOW_WINDOW_SEC", "NT_FLOW_WINDOWS", "NT_PUBLISH_WORKERS",
+    "NT_PUBLISH_FLUSH_MS", "NT_PUBLISH_BATCH_MAX",
+    "NT_SECONDARY_REDIS_URL", "NT_SECONDARY_REDIS_PASSWORD",
+    "NT_SECONDARY_PUBLISH_WORKERS", "NT_SECONDARY_ALARM_FAILURES",
+    "NT_STREAM_MAXLEN", "NT_STREAM_RETENTION_SEC", "NT_STREAM_TRIM_INTERVAL_SEC", "NT_HEARTBEAT_INTERVAL_MS",
+    "NT_DLQ_STREAM", "NT_DLQ_MAXLEN",
+    "NT_JANITOR_INTERVAL_SEC", "NT_JANITOR_ORPHAN_AFTER_SEC", "NT_ARCHIVE_URL", "NT_ARCHIVE_INTERVAL_SEC",
+    "NT_INGESTION_DEGRADED_MS", "NT_INGESTION_DOWN_MS", "NT_INGESTION_MIN_DWELL_MS",
+    "NT_INGESTION_OVERRIDES", "NT_STALENESS_SWEEP_MS", "NT_ALLOW_ANOMALY_INJECTION",
+    "NT_INVARIANT_MODE", "NT_NOTIONAL_TIERS", "NT_SYMBOL_TIERS",
+    "NT_STABLE_QUOTES", "NT_BOOK_HISTORY_INTERVAL_MS", "NT_BOOK_HISTORY_RETENTION_SEC",
+    "NT_BOOK_HISTORY_LEVELS", "NT_DIGEST_TTL_SEC", "NT_DAILY_TTL_SEC",
+    "NT_VENUE_REPORT_INTERVAL_MS", "NT_VENUE_SPREAD_WIDEN_FACTOR",
+    "NT_RELATIVE_STRENGTH_INTERVAL_SEC", "NT_RELATIVE_STRENGTH_BENCHMARK", "NT_SIGNALS_MAXLEN",
+    "NT_ANOMALY_BUDGET_PER_HOUR",
+    "NT_WATCH_REFRESH_MS",
+    "NT_SIGNAL_BRIDGE_URL", "NT_SIGNAL_BRIDGE_TOPIC", "NT_SIGNAL_BRIDGE_EXCHANGE",
+    "NT_CANARY_MODULES", "NT_CANARY_TTL_SEC", "NT_CANARY_COMPARE_SEC", "NT_POC_TREND_WINDOW_SEC", "NT_POC_TREND_THRESHOLD_BPS",
+    "NT_FOOTPRINT_WINDOW_SEC", "NT_FOOTPRINT_BUCKET_BPS", "NT_FOOTPRINT_TOP_N",
+    "NT_DISABLED_SECTIONS", "NT_SYMBOL_SECTIONS",
+    "NT_DISABLED_DETECTORS", "NT_SYMBOL_DETECTORS", "NT_ASYNC_DETECTORS", "NT_DETECTOR_BUDGET_MS",
+    "NT_TRADE_TAPE_SIZE", "NT_REPORT_RECENT_TRADES", "NT_CHURN_WINDOW_SEC",
+    "NT_CLOCK_SKEW_WARN_MS", "NT_REORDER_WINDOW_MS",
+)
+
+# Settings masked when printing the effective configuration
+SECRET_FIELDS = (
+    "binance_api_key", "binance_api_secret", "redis_password", "nt_metrics_basic_auth",
+    "nt_secondary_redis_password", "redis_stream_password", "redis_pubsub_password",
+    "nt_signal_bridge_url",
+)
+
+
+def _flatten(data: dict, prefix: str = "") -> dict[str, str]:
+    """Flatten nested config sections into environment-style settings."""
+    settings = {}
+    for key, value in data.items():
+        name = f"{prefix}{key}".upper()
+        if isinstance(value, dict):
+            settings.update(_flatten(value, f"{name}_"))
+        elif isinstance(value, list):
+            settings[name] = ",".join(str(v) for v in value)
+        elif isinstance(value, bool):
+            settings[name] = "true" if value else "false"
+        else:
+            settings[name] = str(value)
+    return settings
+
+
+def load_config_file(path: str) -> dict[str, str]:
+    """Read a YAML (.yaml/.yml) or TOML (.toml) config file as settings.
+
+    Raises:
+        ValueError: If the file type is unsupported or the file is malformed
+    """
+    if path.endswith(".toml"):
+        try:
+            with open(path, "rb") as f:
+                data = tomllib.load(f)
+        except (OSError, tomllib.TOMLDecodeError) as e:
+            raise ValueError(f"Failed to read config file {path}: {e}") from e
+    elif path.endswith((".yaml", ".yml")):
+        try:
+            with open(path) as f:
+                data = yaml.safe_load(f) or {}
+        except (OSError, yaml.YAMLError) as e:
+            raise ValueError(f"Failed to read config file {path}: {e}") from e
+    else:
+        raise ValueError(f"Unsupported config file type: {path} (use .yaml, .yml or .toml)")
+
+    if not isinstance(data, dict):
+        raise ValueError(f"Config file {path} must contain a mapping")
+    return _flatten(data)
+
+
+def apply_config_file(path: str) -> dict[str, str]:
+    """Seed the environment from a config file; existing env vars win.
+
+    Returns:
+        Settings read from the file
+    """
+    settings = load_config_file(path)
+    for name, value in settings.items():
+        os.environ.setdefault(name, value)
+    return settings
+
 
 @dataclass
 class ProducerConfig:
@@ -14,23 +138,68 @@ class ProducerConfig:
     # Binance API
     binance_api_key: str = ""
     binance_api_secret: str = ""
+    # Alternative REST/WebSocket endpoints, e.g. the simulated exchange (empty = Binance spot)
+    binance_base_url_http: str = ""
+    binance_base_url_ws: str = ""
 
     # Redis
     redis_url: str = "redis://localhost:6379"
     redis_password: str = ""
+    # TLS for rediss:// URLs: CA bundle, client certificate and key (mutual TLS)
+    redis_tls_ca_cert: str = ""
+    redis_tls_cert: str = ""
+    redis_tls_key: str = ""
+    # Separate endpoints for the event stream and report pub/sub, each with its
+    # own credentials and TLS ("" = REDIS_URL with its password and TLS)
+    redis_stream_url: str = ""
+    redis_stream_password: str = ""
+    redis_stream_tls_ca_cert: str = ""
+    redis_stream_tls_cert: str = ""
+    redis_stream_tls_key: str = ""
+    redis_pubsub_url: str = ""
+    redis_pubsub_password: str = ""
+    redis_pubsub_tls_ca_cert: str = ""
+    redis_pubsub_tls_cert: str = ""
+    redis_pubsub_tls_key: str = ""
     stream_key: str = "nt:binance"
+    # Stream retention: XADD length cap, optional age-based XTRIM, trim interval (0 = off)
+    nt_stream_maxlen: int = 100000
+    nt_stream_retention_sec: int = 0
+    nt_stream_trim_interval_sec: int = 60
+    # Heartbeat envelopes on the event stream for pipeline latency (0 = off)
+    nt_heartbeat_interval_ms: int = 1000
+    # Stream receiving events that fail sanitation (src/sanitize.py) and its length cap
+    nt_dlq_stream: str = "dlq:market_events"
+    nt_dlq_maxlen: int = 10000
+    # Redis janitor: sweep interval (0 = off) and idle time before an unconfigured symbol's keys are removed
+    nt_janitor_interval_sec: int = 3600
+    nt_janitor_orphan_after_sec: int = 86400
+    # Object storage closed days' digests and daily statistics are exported to (s3://, gs://,
+    # file://; "" = off) and how often new days are looked for
+    nt_archive_url: str = ""
+    nt_archive_interval_sec: int = 3600
 
     # Symbols
     symbols: List[str] = None
 
     # Observability
     log_level: str = "info"
+    # Per-component level overrides (component -> level) and 1-in-N sampling per event
+    nt_log_levels: Dict[str, str] = None
+    nt_log_sample: Dict[str, int] = None
+    # Extra log sinks: rotating file and syslog (socket path or udp:// / tcp://) ("" = off)
+    nt_log_file: str = ""
+    nt_log_file_max_mb: int = 100
+    nt_log_file_backups: int = 5
+    nt_log_syslog: str = ""
 
     # Embedded Analytics (Feature: 002-nt-embedded-analytics)
     nt_enable_kv_reports: bool = False
     nt_enable_streams: bool = True
     nt_report_period_ms: int = 250
     nt_slow_period_ms: int = 2000
+    # Cycles this node runs: "full", "fast" (L1 and flow) or "deep" (slow-cycle analytics)
+    nt_processing_profile: str = "full"
     # US2: Multi-instance coordination
     nt_enable_multi_instance: bool = False
     nt_lease_ttl_ms: int = 2000
@@ -38,6 +207,110 @@ class ProducerConfig:
     nt_hrw_sticky_pct: float = 0.02
     nt_min_hold_ms: int = 2000
     nt_metrics_port: int = 9101
+    nt_metrics_basic_auth: str = ""  # "user:password" protecting /metrics
+    nt_health_stall_ms: int = 10000  # Fast cycle silence after which /health fails
+    # Per-report metrics records for offline analysis ("", "redis" or "file")
+    nt_report_metrics_sink: str = ""
+    nt_report_metrics_target: str = ""
+    nt_report_metrics_maxlen: int = 100000
+    # Directory of hourly Parquet files of published reports and trades ("" = off) and
+    # seconds between row group writes
+    nt_parquet_dir: str = ""
+    nt_parquet_flush_sec: int = 60
+    # Flow windows: headline net_flow window and the windows published in flow.windows
+    flow_window_sec: int = 30
+    nt_flow_windows: List[int] = None
+    nt_publish_workers: int = 4
+    nt_publish_flush_ms: float = 5.0  # Batch collection window for pipelined publishes
+    nt_publish_batch_max: int = 100
+    # Write-through report mirror to a second Redis, e.g. another region ("" = off)
+    nt_secondary_redis_url: str = ""
+    nt_secondary_redis_password: str = ""
+    nt_secondary_publish_workers: int = 2
+    nt_secondary_alarm_failures: int = 5  # Consecutive failures before nt_secondary_publish_healthy=0
+    # Ingestion status: data age thresholds, recovery hysteresis, per-symbol overrides
+    nt_ingestion_degraded_ms: int = 1000
+    nt_ingestion_down_ms: int = 2000
+    nt_ingestion_min_dwell_ms: int = 2000
+    nt_ingestion_overrides: Dict[str, List[int]] = None  # symbol -> [degraded_ms, down_ms]
+    nt_staleness_sweep_ms: int = 1000  # 0 disables the staleness sweep
+    # Honor synthetic anomaly injections (staging only)
+    nt_allow_anomaly_injection: bool = False
+    # Report invariant checks before publish: "off", "tag" or "block"
+    nt_invariant_mode: str = "off"
+    # Severity notional tiers: name -> [medium_usd, high_usd], and symbol -> tier name
+    nt_notional_tiers: Dict[str, List[float]] = None
+    nt_symbol_tiers: Dict[str, str] = None
+    # Quote assets valued at 1 USD for *_usd report fields
+    nt_stable_quotes: List[str] = None
+    # Order book heatmap snapshots (book_history:{symbol})
+    nt_book_history_interval_ms: int = 5000  # 0 disables snapshots
+    nt_book_history_retention_sec: int = 3600
+    nt_book_history_levels: int = 20
+
+    # Per-minute digests (digest:{symbol}:{minute}) and how long they are kept
+    nt_digest_ttl_sec: int = 86400  # 0 disables digests
+    # Daily statistics (daily:{symbol}:{date}) rolled up from the digests
+    nt_daily_ttl_sec: int = 2592000  # 0 disables the rollup
+    # Venue stress index (venue_report:{venue}) interval (0 = off) and the spread multiple
+    # of a symbol's baseline counted as widened
+    nt_venue_report_interval_ms: int = 5000
+    nt_venue_spread_widen_factor: float = 3.0
+    # Relative strength (24h performance vs benchmark and universe median) refresh interval
+    # (0 = off) and benchmark symbol
+    nt_relative_strength_interval_sec: int = 60
+    nt_relative_strength_benchmark: str = "BTCUSDT"
+    # Length cap of the signals:{venue} streams (anomaly, health and wall events; 0 = off)
+    nt_signals_maxlen: int = 100000
+    # anomaly_started signals per symbol and anomaly type per clock hour (0 = no limit)
+    nt_anomaly_budget_per_hour: int = 60
+    # Reload interval of MCP watch registrations whose threshold rules are checked on every
+    # fast report (0 = off)
+    nt_watch_refresh_ms: int = 2000
+    # Broker the signals are forwarded to (mqtt[s]:// or amqp[s]://, "" = off), topic template
+    # and AMQP topic exchange
+    nt_signal_bridge_url: str = ""
+    nt_signal_bridge_topic: str = "context8/{venue}/{symbol}/{signal}"
+    nt_signal_bridge_exchange: str = "context8"
+    # Candidate metric modules published to report_canary:{symbol} (empty = off), canary key
+    # expiry and how often canary/stable divergence is summarized
+    nt_canary_modules: List[str] = None
+    nt_canary_ttl_sec: int = 300
+    nt_canary_compare_sec: int = 60
+    # POC migration: window and drift (bps) separating "up"/"down" from "stable"
+    nt_poc_trend_window_sec: int = 900
+    nt_poc_trend_threshold_bps: float = 5.0
+    # Footprint ladder (footprint:{symbol}) window, bucket width and top levels in the report
+    nt_footprint_window_sec: int = 300
+    nt_footprint_bucket_bps: float = 1.0
+    nt_footprint_top_n: int = 5
+    # Optional report sections turned off everywhere, and per-symbol on/off overrides
+    # (Redis overrides in control:flags take precedence, see reporters/feature_flags.py)
+    nt_disabled_sections: List[str] = None
+    nt_symbol_sections: Dict[str, Dict[str, bool]] = None  # symbol -> {section: enabled}
+    # Anomaly detectors turned off everywhere, and per-symbol on/off overrides
+    nt_disabled_detectors: List[str] = None
+    nt_symbol_detectors: Dict[str, Dict[str, bool]] = None  # symbol -> {detector: enabled}
+    # Detectors run off the strategy thread (results attach to the next report), and the
+    # time budget of each detector run
+    nt_async_detectors: List[str] = None
+    nt_detector_budget_ms: float = 50.0
+    # Trade tape (trades:{symbol}) length and trades embedded in reports as recent_trades (0 = off)
+    nt_trade_tape_size: int = 500
+    nt_report_recent_trades: int = 0
+    # Window of the microstructure quote update rate and book churn
+    nt_churn_window_sec: int = 10
+    # Venue clock skew above which reports carry a clock_skew anomaly
+    nt_clock_skew_warn_ms: int = 1000
+    # Lateness tolerated for out-of-order trades and book updates (0 = drop any older event)
+    nt_reorder_window_ms: float = 0.0
+
+    @classmethod
+    def load(cls, config_path: str | None = None) -> "ProducerConfig":
+        """Load configuration from an optional config file plus environment."""
+        if config_path:
+            apply_config_file(config_path)
+        return cls.from_env()
 
     @classmethod
     def from_env(cls) -> "ProducerConfig":
@@ -45,6 +318,53 @@ class ProducerConfig:
         symbols_str = os.getenv("SYMBOLS", "BTCUSDT,ETHUSDT")
         symbols = [s.strip() for s in symbols_str.split(",")]
 
+        flow_windows_str = os.getenv("NT_FLOW_WINDOWS", "10,60,300")
+        flow_windows = [int(w) for w in flow_windows_str.split(",") if w.strip()]
+
+        # NT_INGESTION_OVERRIDES: "SYMBOL:degraded_ms:down_ms,..."
+        ingestion_overrides = {}
+        for entry in filter(None, (e.strip() for e in os.getenv("NT_INGESTION_OVERRIDES", "").split(","))):
+            symbol, degraded_ms, down_ms = entry.split(":")
+            ingestion_overrides[symbol.strip().upper()] = [int(degraded_ms), int(down_ms)]
+
+        # NT_NOTIONAL_TIERS: "name:medium_usd:high_usd,..." (added to the built-in tiers)
+        notional_tiers = {
+            name: [tier.medium_usd, tier.high_usd] for name, tier in DEFAULT_NOTIONAL_TIERS.items()
+        }
+        for entry in filter(None, (e.strip() for e in os.getenv("NT_NOTIONAL_TIERS", "").split(","))):
+            name, medium_usd, high_usd = entry.split(":")
+            notional_tiers[name.strip().lower()] = [float(medium_usd), float(high_usd)]
+
+        # NT_SYMBOL_TIERS: "SYMBOL:tier,..." (unlisted symbols use "default")
+        symbol_tiers = {}
+        for entry in filter(None, (e.strip() for e in os.getenv("NT_SYMBOL_TIERS", "BTCUSDT:major,ETHUSDT:major").split(","))):
+            symbol, tier = entry.split(":")
+            symbol_tiers[symbol.strip().upper()] = tier.strip().lower()
+
+        # NT_LOG_LEVELS: "component:level,..."; NT_LOG_SAMPLE: "event:N,..." (keep 1 in N)
+        log_levels = {}
+        for entry in filter(None, (e.strip() for e in os.getenv("NT_LOG_LEVELS", "").split(","))):
+            component, level = entry.split(":")
+            log_levels[component.strip()] = level.strip().lower()
+        log_sample = {}
+        for entry in filter(None, (e.strip() for e in os.getenv("NT_LOG_SAMPLE", "event_published:100,report_published:10").split(","))):
+            event, rate = entry.split(":")
+            log_sample[event.strip()] = int(rate)
+
+        # NT_SYMBOL_DETECTORS: "SYMBOL:-detector,SYMBOL:+detector,..." (- disables, + re-enables)
+        symbol_detectors: dict[str, dict[str, bool]] = {}
+        for entry in filter(None, (e.strip() for e in os.getenv("NT_SYMBOL_DETECTORS", "").split(","))):
+            symbol, toggle = entry.split(":")
+            toggle = toggle.strip().lower()
+            symbol_detectors.setdefault(symbol.strip().upper(), {})[toggle.lstrip("+-")] = not toggle.startswith("-")
+
+        # NT_SYMBOL_SECTIONS: "SYMBOL:-section,SYMBOL:+section,..." (- disables, + enables)
+        symbol_sections: dict[str, dict[str, bool]] = {}
+        for entry in filter(None, (e.strip() for e in os.getenv("NT_SYMBOL_SECTIONS", "").split(","))):
+            symbol, toggle = entry.split(":")
+            toggle = toggle.strip().lower()
+            symbol_sections.setdefault(symbol.strip().upper(), {})[toggle.lstrip("+-")] = not toggle.startswith("-")
+
         # Generate node_id if not provided
         import socket
         node_id = os.getenv("NT_NODE_ID", "")
@@ -54,16 +374,48 @@ class ProducerConfig:
         return cls(
             binance_api_key=os.getenv("BINANCE_API_KEY", ""),
             binance_api_secret=os.getenv("BINANCE_API_SECRET", ""),
+            binance_base_url_http=os.getenv("BINANCE_BASE_URL_HTTP", ""),
+            binance_base_url_ws=os.getenv("BINANCE_BASE_URL_WS", ""),
             redis_url=os.getenv("REDIS_URL", "redis://localhost:6379"),
             redis_password=os.getenv("REDIS_PASSWORD", ""),
+            redis_tls_ca_cert=os.getenv("REDIS_TLS_CA_CERT", ""),
+            redis_tls_cert=os.getenv("REDIS_TLS_CERT", ""),
+            redis_tls_key=os.getenv("REDIS_TLS_KEY", ""),
+            redis_stream_url=os.getenv("REDIS_STREAM_URL", ""),
+            redis_stream_password=os.getenv("REDIS_STREAM_PASSWORD", ""),
+            redis_stream_tls_ca_cert=os.getenv("REDIS_STREAM_TLS_CA_CERT", ""),
+            redis_stream_tls_cert=os.getenv("REDIS_STREAM_TLS_CERT", ""),
+            redis_stream_tls_key=os.getenv("REDIS_STREAM_TLS_KEY", ""),
+            redis_pubsub_url=os.getenv("REDIS_PUBSUB_URL", ""),
+            redis_pubsub_password=os.getenv("REDIS_PUBSUB_PASSWORD", ""),
+            redis_pubsub_tls_ca_cert=os.getenv("REDIS_PUBSUB_TLS_CA_CERT", ""),
+            redis_pubsub_tls_cert=os.getenv("REDIS_PUBSUB_TLS_CERT", ""),
+            redis_pubsub_tls_key=os.getenv("REDIS_PUBSUB_TLS_KEY", ""),
             stream_key=os.getenv("STREAM_KEY", "nt:binance"),
+            nt_stream_maxlen=int(os.getenv("NT_STREAM_MAXLEN", "100000")),
+            nt_stream_retention_sec=int(os.getenv("NT_STREAM_RETENTION_SEC", "0")),
+            nt_stream_trim_interval_sec=int(os.getenv("NT_STREAM_TRIM_INTERVAL_SEC", "60")),
+            nt_heartbeat_interval_ms=int(os.getenv("NT_HEARTBEAT_INTERVAL_MS", "1000")),
+            nt_dlq_stream=os.getenv("NT_DLQ_STREAM", "dlq:market_events"),
+            nt_dlq_maxlen=int(os.getenv("NT_DLQ_MAXLEN", "10000")),
+            nt_janitor_interval_sec=int(os.getenv("NT_JANITOR_INTERVAL_SEC", "3600")),
+            nt_janitor_orphan_after_sec=int(os.getenv("NT_JANITOR_ORPHAN_AFTER_SEC", "86400")),
+            nt_archive_url=os.getenv("NT_ARCHIVE_URL", ""),
+            nt_archive_interval_sec=int(os.getenv("NT_ARCHIVE_INTERVAL_SEC", "3600")),
             symbols=symbols,
             # T084: Support NT_LOG_LEVEL with fallback to LOG_LEVEL
             log_level=os.getenv("NT_LOG_LEVEL", os.getenv("LOG_LEVEL", "info")).lower(),
+            nt_log_levels=log_levels,
+            nt_log_sample=log_sample,
+            nt_log_file=os.getenv("NT_LOG_FILE", ""),
+            nt_log_file_max_mb=int(os.getenv("NT_LOG_FILE_MAX_MB", "100")),
+            nt_log_file_backups=int(os.getenv("NT_LOG_FILE_BACKUPS", "5")),
+            nt_log_syslog=os.getenv("NT_LOG_SYSLOG", ""),
             nt_enable_kv_reports=os.getenv("NT_ENABLE_KV_REPORTS", "false").lower() == "true",
             nt_enable_streams=os.getenv("NT_ENABLE_STREAMS", "true").lower() == "true",
             nt_report_period_ms=int(os.getenv("NT_REPORT_PERIOD_MS", "250")),
             nt_slow_period_ms=int(os.getenv("NT_SLOW_PERIOD_MS", "2000")),
+            nt_processing_profile=os.getenv("NT_PROCESSING_PROFILE", "full").strip().lower(),
             # US2: Multi-instance coordination
             nt_enable_multi_instance=os.getenv("NT_ENABLE_MULTI_INSTANCE", "false").lower() == "true",
             nt_lease_ttl_ms=int(os.getenv("NT_LEASE_TTL_MS", "2000")),
@@ -71,6 +423,78 @@ class ProducerConfig:
             nt_hrw_sticky_pct=float(os.getenv("NT_HRW_STICKY_PCT", "0.02")),
             nt_min_hold_ms=int(os.getenv("NT_MIN_HOLD_MS", "2000")),
             nt_metrics_port=int(os.getenv("NT_METRICS_PORT", "9101")),
+            nt_metrics_basic_auth=os.getenv("NT_METRICS_BASIC_AUTH", ""),
+            nt_health_stall_ms=int(os.getenv("NT_HEALTH_STALL_MS", "10000")),
+            nt_report_metrics_sink=os.getenv("NT_REPORT_METRICS_SINK", "").lower(),
+            nt_report_metrics_target=os.getenv("NT_REPORT_METRICS_TARGET", ""),
+            nt_report_metrics_maxlen=int(os.getenv("NT_REPORT_METRICS_MAXLEN", "100000")),
+            nt_parquet_dir=os.getenv("NT_PARQUET_DIR", ""),
+            nt_parquet_flush_sec=int(os.getenv("NT_PARQUET_FLUSH_SEC", "60")),
+            flow_window_sec=int(os.getenv("FLOW_WINDOW_SEC", "30")),
+            nt_flow_windows=flow_windows,
+            nt_publish_workers=int(os.getenv("NT_PUBLISH_WORKERS", "4")),
+            nt_publish_flush_ms=float(os.getenv("NT_PUBLISH_FLUSH_MS", "5")),
+            nt_publish_batch_max=int(os.getenv("NT_PUBLISH_BATCH_MAX", "100")),
+            nt_secondary_redis_url=os.getenv("NT_SECONDARY_REDIS_URL", ""),
+            nt_secondary_redis_password=os.getenv("NT_SECONDARY_REDIS_PASSWORD", ""),
+            nt_secondary_publish_workers=int(os.getenv("NT_SECONDARY_PUBLISH_WORKERS", "2")),
+            nt_secondary_alarm_failures=int(os.getenv("NT_SECONDARY_ALARM_FAILURES", "5")),
+            nt_ingestion_degraded_ms=int(os.getenv("NT_INGESTION_DEGRADED_MS", "1000")),
+            nt_ingestion_down_ms=int(os.getenv("NT_INGESTION_DOWN_MS", "2000")),
+            nt_ingestion_min_dwell_ms=int(os.getenv("NT_INGESTION_MIN_DWELL_MS", "2000")),
+            nt_ingestion_overrides=ingestion_overrides,
+            nt_staleness_sweep_ms=int(os.getenv("NT_STALENESS_SWEEP_MS", "1000")),
+            nt_allow_anomaly_injection=os.getenv("NT_ALLOW_ANOMALY_INJECTION", "false").lower() == "true",
+            nt_invariant_mode=os.getenv("NT_INVARIANT_MODE", "off").lower(),
+            nt_notional_tiers=notional_tiers,
+            nt_symbol_tiers=symbol_tiers,
+            nt_book_history_interval_ms=int(os.getenv("NT_BOOK_HISTORY_INTERVAL_MS", "5000")),
+            nt_book_history_retention_sec=int(os.getenv("NT_BOOK_HISTORY_RETENTION_SEC", "3600")),
+            nt_book_history_levels=int(os.getenv("NT_BOOK_HISTORY_LEVELS", "20")),
+            nt_digest_ttl_sec=int(os.getenv("NT_DIGEST_TTL_SEC", "86400")),
+            nt_daily_ttl_sec=int(os.getenv("NT_DAILY_TTL_SEC", "2592000")),
+            nt_venue_report_interval_ms=int(os.getenv("NT_VENUE_REPORT_INTERVAL_MS", "5000")),
+            nt_venue_spread_widen_factor=float(os.getenv("NT_VENUE_SPREAD_WIDEN_FACTOR", "3.0")),
+            nt_relative_strength_interval_sec=int(os.getenv("NT_RELATIVE_STRENGTH_INTERVAL_SEC", "60")),
+            nt_relative_strength_benchmark=os.getenv("NT_RELATIVE_STRENGTH_BENCHMARK", "BTCUSDT").strip().upper(),
+            nt_signals_maxlen=int(os.getenv("NT_SIGNALS_MAXLEN", "100000")),
+            nt_anomaly_budget_per_hour=int(os.getenv("NT_ANOMALY_BUDGET_PER_HOUR", "60")),
+            nt_watch_refresh_ms=int(os.getenv("NT_WATCH_REFRESH_MS", "2000")),
+            nt_signal_bridge_url=os.getenv("NT_SIGNAL_BRIDGE_URL", ""),
+            nt_signal_bridge_topic=os.getenv("NT_SIGNAL_BRIDGE_TOPIC", "context8/{venue}/{symbol}/{signal}"),
+            nt_signal_bridge_exchange=os.getenv("NT_SIGNAL_BRIDGE_EXCHANGE", "context8"),
+            nt_canary_modules=[
+                m.strip().lower() for m in os.getenv("NT_CANARY_MODULES", "").split(",") if m.strip()
+            ],
+            nt_canary_ttl_sec=int(os.getenv("NT_CANARY_TTL_SEC", "300")),
+            nt_canary_compare_sec=int(os.getenv("NT_CANARY_COMPARE_SEC", "60")),
+            nt_poc_trend_window_sec=int(os.getenv("NT_POC_TREND_WINDOW_SEC", "900")),
+            nt_poc_trend_threshold_bps=float(os.getenv("NT_POC_TREND_THRESHOLD_BPS", "5.0")),
+            nt_footprint_window_sec=int(os.getenv("NT_FOOTPRINT_WINDOW_SEC", "300")),
+            nt_footprint_bucket_bps=float(os.getenv("NT_FOOTPRINT_BUCKET_BPS", "1.0")),
+            nt_footprint_top_n=int(os.getenv("NT_FOOTPRINT_TOP_N", "5")),
+            nt_disabled_sections=[
+                s.strip().lower() for s in os.getenv("NT_DISABLED_SECTIONS", "").split(",") if s.strip()
+            ],
+            nt_symbol_sections=symbol_sections,
+            nt_disabled_detectors=[
+                d.strip().lower() for d in os.getenv("NT_DISABLED_DETECTORS", "").split(",") if d.strip()
+            ],
+            nt_symbol_detectors=symbol_detectors,
+            nt_async_detectors=[
+                d.strip().lower() for d in os.getenv("NT_ASYNC_DETECTORS", "iceberg,absorption").split(",") if d.strip()
+            ],
+            nt_detector_budget_ms=float(os.getenv("NT_DETECTOR_BUDGET_MS", "50")),
+            nt_trade_tape_size=int(os.getenv("NT_TRADE_TAPE_SIZE", "500")),
+            nt_report_recent_trades=int(os.getenv("NT_REPORT_RECENT_TRADES", "0")),
+            nt_churn_window_sec=int(os.getenv("NT_CHURN_WINDOW_SEC", "10")),
+            nt_clock_skew_warn_ms=int(os.getenv("NT_CLOCK_SKEW_WARN_MS", "1000")),
+            nt_reorder_window_ms=float(os.getenv("NT_REORDER_WINDOW_MS", "0")),
+            nt_stable_quotes=[
+                q.strip().upper()
+                for q in os.getenv("NT_STABLE_QUOTES", ",".join(DEFAULT_STABLE_QUOTES)).split(",")
+                if q.strip()
+            ],
         )
 
     def validate(self) -> None:
@@ -84,6 +508,250 @@ class ProducerConfig:
 
         if self.log_level not in ["debug", "info", "warn", "error"]:
             raise ValueError(f"Invalid log level: {self.log_level}")
+        for component, level in (self.nt_log_levels or {}).items():
+            if level not in ["debug", "info", "warn", "error"]:
+                raise ValueError(f"Invalid log level for {component} in NT_LOG_LEVELS: {level}")
+        for event, rate in (self.nt_log_sample or {}).items():
+            if rate < 1:
+                raise ValueError(f"NT_LOG_SAMPLE rate for {event} must be >= 1, got {rate}")
+        if self.nt_log_file and (self.nt_log_file_max_mb < 1 or self.nt_log_file_backups < 0):
+            raise ValueError("NT_LOG_FILE_MAX_MB must be >= 1 and NT_LOG_FILE_BACKUPS >= 0")
+        if self.nt_log_syslog and not (
+            self.nt_log_syslog.startswith("/") or urlsplit(self.nt_log_syslog).scheme in ("udp", "tcp")
+        ):
+            raise ValueError(f"NT_LOG_SYSLOG must be a socket path or udp://host:port or tcp://host:port, got {self.nt_log_syslog}")
+
+        for role in REDIS_ROLES:
+            self._validate_redis_endpoint(role)
+
+        # Writer leases are Lua scripts, which the in-memory bus can't run
+        if is_memory_url(self.redis_url) and self.nt_enable_multi_instance:
+            raise ValueError("NT_ENABLE_MULTI_INSTANCE requires Redis; disable it with a memory:// REDIS_URL")
+
+        if not 0 < self.nt_metrics_port < 65536:
+            raise ValueError(f"NT_METRICS_PORT must be 1-65535, got {self.nt_metrics_port}")
+
+        if self.nt_metrics_basic_auth and ":" not in self.nt_metrics_basic_auth:
+            raise ValueError("NT_METRICS_BASIC_AUTH must be in user:password form")
+
+        if self.nt_processing_profile not in PROCESSING_PROFILES:
+            raise ValueError(
+                f"NT_PROCESSING_PROFILE must be one of {', '.join(PROCESSING_PROFILES)}, "
+                f"got {self.nt_processing_profile}"
+            )
+
+        if self.nt_health_stall_ms <= self.nt_report_period_ms:
+            raise ValueError(
+                f"NT_HEALTH_STALL_MS must exceed NT_REPORT_PERIOD_MS ({self.nt_report_period_ms}), "
+                f"got {self.nt_health_stall_ms}"
+            )
+
+        # A deep node completes a cycle every slow period
+        if self.nt_processing_profile == "deep" and self.nt_health_stall_ms <= self.nt_slow_period_ms:
+            raise ValueError(
+                f"NT_HEALTH_STALL_MS must exceed NT_SLOW_PERIOD_MS ({self.nt_slow_period_ms}) "
+                f"with the deep profile, got {self.nt_health_stall_ms}"
+            )
+
+        if self.nt_report_metrics_sink not in ("", "redis", "file"):
+            raise ValueError(f"NT_REPORT_METRICS_SINK must be redis or file, got {self.nt_report_metrics_sink}")
+
+        if self.nt_report_metrics_sink == "file" and not self.nt_report_metrics_target:
+            raise ValueError("NT_REPORT_METRICS_TARGET must be set to a file path for the file sink")
+        if self.nt_parquet_dir and not 1 <= self.nt_parquet_flush_sec <= 3600:
+            raise ValueError(f"NT_PARQUET_FLUSH_SEC must be between 1 and 3600, got {self.nt_parquet_flush_sec}")
+
+        # Flow windows are served from the 30min trade buffer at most
+        for window in [self.flow_window_sec, *(self.nt_flow_windows or [])]:
+            if not 1 <= window <= 1800:
+                raise ValueError(f"Flow windows must be 1-1800 seconds, got {window}")
+
+        if self.nt_stream_maxlen < 0 or self.nt_stream_retention_sec < 0 or self.nt_stream_trim_interval_sec < 0:
+            raise ValueError("NT_STREAM_MAXLEN, NT_STREAM_RETENTION_SEC and NT_STREAM_TRIM_INTERVAL_SEC must be >= 0")
+
+        if self.nt_stream_trim_interval_sec and not (self.nt_stream_maxlen or self.nt_stream_retention_sec):
+            raise ValueError("NT_STREAM_TRIM_INTERVAL_SEC requires NT_STREAM_MAXLEN or NT_STREAM_RETENTION_SEC")
+
+        if self.nt_heartbeat_interval_ms and not 100 <= self.nt_heartbeat_interval_ms <= 60000:
+            raise ValueError(
+                f"NT_HEARTBEAT_INTERVAL_MS must be 0 (off) or 100-60000, got {self.nt_heartbeat_interval_ms}"
+            )
+
+        if not self.nt_dlq_stream or self.nt_dlq_stream == self.stream_key:
+            raise ValueError("NT_DLQ_STREAM must be set and differ from STREAM_KEY")
+        if self.nt_dlq_maxlen < 0:
+            raise ValueError(f"NT_DLQ_MAXLEN must be >= 0, got {self.nt_dlq_maxlen}")
+
+        if self.nt_janitor_interval_sec and self.nt_janitor_interval_sec < 60:
+            raise ValueError(f"NT_JANITOR_INTERVAL_SEC must be 0 or >= 60, got {self.nt_janitor_interval_sec}")
+        if self.nt_janitor_orphan_after_sec < 3600:
+            raise ValueError(f"NT_JANITOR_ORPHAN_AFTER_SEC must be >= 3600, got {self.nt_janitor_orphan_after_sec}")
+
+        if self.nt_archive_url:
+            if urlsplit(self.nt_archive_url).scheme not in ARCHIVE_SCHEMES:
+                raise ValueError("NT_ARCHIVE_URL must be s3://, gs:// or file://")
+            if self.nt_archive_interval_sec < 60:
+                raise ValueError(f"NT_ARCHIVE_INTERVAL_SEC must be >= 60, got {self.nt_archive_interval_sec}")
+            # A day's first digest must still exist when the day is exported
+            keep_sec = 86400 + EXPORT_DELAY_SEC + self.nt_archive_interval_sec
+            if min(self.nt_digest_ttl_sec, self.nt_daily_ttl_sec) < keep_sec:
+                raise ValueError(f"NT_ARCHIVE_URL requires NT_DIGEST_TTL_SEC and NT_DAILY_TTL_SEC >= {keep_sec}")
+
+        thresholds = {"default": [self.nt_ingestion_degraded_ms, self.nt_ingestion_down_ms]}
+        thresholds.update(self.nt_ingestion_overrides or {})
+        for name, (degraded_ms, down_ms) in thresholds.items():
+            if not 0 < degraded_ms < down_ms:
+                raise ValueError(
+                    f"Ingestion thresholds for {name} must satisfy 0 < degraded_ms < down_ms, "
+                    f"got {degraded_ms}/{down_ms}"
+                )
+
+        if self.nt_ingestion_min_dwell_ms < 0:
+            raise ValueError(f"NT_INGESTION_MIN_DWELL_MS must be >= 0, got {self.nt_ingestion_min_dwell_ms}")
+
+        # The sweep must not race a healthy fast cycle
+        if self.nt_staleness_sweep_ms and self.nt_staleness_sweep_ms < self.nt_report_period_ms * 2:
+            raise ValueError(
+                f"NT_STALENESS_SWEEP_MS must be 0 or >= 2x NT_REPORT_PERIOD_MS, got {self.nt_staleness_sweep_ms}"
+            )
+
+        if self.nt_invariant_mode not in ("off", "tag", "block"):
+            raise ValueError(f"NT_INVARIANT_MODE must be off, tag or block, got {self.nt_invariant_mode}")
+
+        for name, (medium_usd, high_usd) in (self.nt_notional_tiers or {}).items():
+            if not 0 <= medium_usd <= high_usd:
+                raise ValueError(
+                    f"Notional tier {name} must satisfy 0 <= medium_usd <= high_usd, got {medium_usd}/{high_usd}"
+                )
+
+        for symbol, tier in (self.nt_symbol_tiers or {}).items():
+            if tier not in (self.nt_notional_tiers or DEFAULT_NOTIONAL_TIERS):
+                raise ValueError(f"NT_SYMBOL_TIERS maps {symbol} to unknown tier {tier}")
+
+        if self.nt_book_history_interval_ms:
+            if self.nt_book_history_interval_ms < 1000:
+                raise ValueError(
+                    f"NT_BOOK_HISTORY_INTERVAL_MS must be 0 or >= 1000, got {self.nt_book_history_interval_ms}"
+                )
+            if self.nt_book_history_retention_sec * 1000 < self.nt_book_history_interval_ms:
+                raise ValueError("NT_BOOK_HISTORY_RETENTION_SEC must cover at least one snapshot interval")
+            if not 1 <= self.nt_book_history_levels <= 20:
+                raise ValueError(f"NT_BOOK_HISTORY_LEVELS must be 1-20, got {self.nt_book_history_levels}")
+
+        if self.nt_digest_ttl_sec and self.nt_digest_ttl_sec < 60:
+            raise ValueError(f"NT_DIGEST_TTL_SEC must be 0 or >= 60, got {self.nt_digest_ttl_sec}")
+        if self.nt_daily_ttl_sec:
+            if self.nt_daily_ttl_sec < 86400:
+                raise ValueError(f"NT_DAILY_TTL_SEC must be 0 or >= 86400, got {self.nt_daily_ttl_sec}")
+            if not self.nt_digest_ttl_sec:
+                raise ValueError("NT_DAILY_TTL_SEC requires digests (NT_DIGEST_TTL_SEC > 0)")
+
+        if self.nt_venue_report_interval_ms and self.nt_venue_report_interval_ms < 1000:
+            raise ValueError(
+                f"NT_VENUE_REPORT_INTERVAL_MS must be 0 or >= 1000, got {self.nt_venue_report_interval_ms}"
+            )
+        if self.nt_venue_spread_widen_factor <= 1:
+            raise ValueError(f"NT_VENUE_SPREAD_WIDEN_FACTOR must be > 1, got {self.nt_venue_spread_widen_factor}")
+
+        if self.nt_relative_strength_interval_sec:
+            if self.nt_relative_strength_interval_sec < 10:
+                raise ValueError(
+                    f"NT_RELATIVE_STRENGTH_INTERVAL_SEC must be 0 or >= 10, got {self.nt_relative_strength_interval_sec}"
+                )
+            # Performance is measured from the digest published 24h ago
+            if self.nt_digest_ttl_sec < 86400:
+                raise ValueError("NT_RELATIVE_STRENGTH_INTERVAL_SEC requires NT_DIGEST_TTL_SEC >= 86400")
+
+        if self.nt_signals_maxlen < 0:
+            raise ValueError(f"NT_SIGNALS_MAXLEN must be >= 0, got {self.nt_signals_maxlen}")
+        if self.nt_anomaly_budget_per_hour < 0:
+            raise ValueError(f"NT_ANOMALY_BUDGET_PER_HOUR must be >= 0, got {self.nt_anomaly_budget_per_hour}")
+        if self.nt_watch_refresh_ms < 0:
+            raise ValueError(f"NT_WATCH_REFRESH_MS must be >= 0, got {self.nt_watch_refresh_ms}")
+        if self.nt_signal_bridge_url:
+            if urlsplit(self.nt_signal_bridge_url).scheme not in BROKER_SCHEMES:
+                raise ValueError("NT_SIGNAL_BRIDGE_URL must be mqtt://, mqtts://, amqp:// or amqps://")
+            if not self.nt_signals_maxlen:
+                raise ValueError("NT_SIGNAL_BRIDGE_URL requires signals (NT_SIGNALS_MAXLEN > 0)")
+            check_topic_template(self.nt_signal_bridge_topic)
+            if not self.nt_signal_bridge_exchange:
+                raise ValueError("NT_SIGNAL_BRIDGE_EXCHANGE must not be empty")
+
+        for module in self.nt_canary_modules or []:
+            if module not in CANARY_MODULES:
+                raise ValueError(f"Unknown canary module {module} (expected one of {', '.join(CANARY_MODULES)})")
+        if self.nt_canary_ttl_sec < 10:
+            raise ValueError(f"NT_CANARY_TTL_SEC must be >= 10, got {self.nt_canary_ttl_sec}")
+        if self.nt_canary_compare_sec < 10:
+            raise ValueError(f"NT_CANARY_COMPARE_SEC must be >= 10, got {self.nt_canary_compare_sec}")
+
+        if self.nt_poc_trend_window_sec * 1000 < self.nt_slow_period_ms * 3:
+            raise ValueError("NT_POC_TREND_WINDOW_SEC must cover at least 3 slow cycles")
+        if self.nt_poc_trend_threshold_bps <= 0:
+            raise ValueError(f"NT_POC_TREND_THRESHOLD_BPS must be > 0, got {self.nt_poc_trend_threshold_bps}")
+
+        if not 1 <= self.nt_footprint_window_sec <= 1800:
+            raise ValueError(f"NT_FOOTPRINT_WINDOW_SEC must be 1-1800 (trade buffer), got {self.nt_footprint_window_sec}")
+        if self.nt_footprint_bucket_bps <= 0:
+            raise ValueError(f"NT_FOOTPRINT_BUCKET_BPS must be > 0, got {self.nt_footprint_bucket_bps}")
+        if self.nt_footprint_top_n < 1:
+            raise ValueError(f"NT_FOOTPRINT_TOP_N must be >= 1, got {self.nt_footprint_top_n}")
+
+        for section in [*(self.nt_disabled_sections or []),
+                        *(s for toggles in (self.nt_symbol_sections or {}).values() for s in toggles)]:
+            if section not in REPORT_SECTIONS:
+                raise ValueError(
+                    f"Unknown report section {section} (expected one of {', '.join(REPORT_SECTIONS)})"
+                )
+
+        for detector in [*(self.nt_disabled_detectors or []), *(self.nt_async_detectors or []),
+                         *(d for toggles in (self.nt_symbol_detectors or {}).values() for d in toggles)]:
+            if detector not in ANOMALY_DETECTORS:
+                raise ValueError(
+                    f"Unknown anomaly detector {detector} (expected one of {', '.join(ANOMALY_DETECTORS)})"
+                )
+
+        if self.nt_detector_budget_ms <= 0:
+            raise ValueError(f"NT_DETECTOR_BUDGET_MS must be > 0, got {self.nt_detector_budget_ms}")
+
+        if not 1 <= self.nt_trade_tape_size <= 5000:
+            raise ValueError(f"NT_TRADE_TAPE_SIZE must be 1-5000, got {self.nt_trade_tape_size}")
+        if not 0 <= self.nt_report_recent_trades <= self.nt_trade_tape_size:
+            raise ValueError(
+                f"NT_REPORT_RECENT_TRADES must be 0-NT_TRADE_TAPE_SIZE, got {self.nt_report_recent_trades}"
+            )
+
+        # Churn counters hold 5 minutes (the quote-stuffing baseline)
+        if not 1 <= self.nt_churn_window_sec <= 300:
+            raise ValueError(f"NT_CHURN_WINDOW_SEC must be 1-300, got {self.nt_churn_window_sec}")
+
+        if self.nt_clock_skew_warn_ms < 1:
+            raise ValueError(f"NT_CLOCK_SKEW_WARN_MS must be >= 1, got {self.nt_clock_skew_warn_ms}")
+
+        # Buffered trades wait up to about twice the window before they are applied
+        if not 0 <= self.nt_reorder_window_ms <= 1000:
+            raise ValueError(f"NT_REORDER_WINDOW_MS must be 0-1000, got {self.nt_reorder_window_ms}")
+
+        if not 1 <= self.nt_publish_workers <= 64:
+            raise ValueError(f"NT_PUBLISH_WORKERS must be 1-64, got {self.nt_publish_workers}")
+
+        if not 0 <= self.nt_publish_flush_ms <= 100:
+            raise ValueError(f"NT_PUBLISH_FLUSH_MS must be 0-100ms, got {self.nt_publish_flush_ms}")
+
+        if self.nt_publish_batch_max < 1:
+            raise ValueError(f"NT_PUBLISH_BATCH_MAX must be >= 1, got {self.nt_publish_batch_max}")
+
+        if self.nt_secondary_redis_url:
+            if self.nt_secondary_redis_url == self.redis_url:
+                raise ValueError("NT_SECONDARY_REDIS_URL must differ from REDIS_URL")
+            if not 1 <= self.nt_secondary_publish_workers <= 64:
+                raise ValueError(
+                    f"NT_SECONDARY_PUBLISH_WORKERS must be 1-64, got {self.nt_secondary_publish_workers}"
+                )
+            if self.nt_secondary_alarm_failures < 1:
+                raise ValueError(
+                    f"NT_SECONDARY_ALARM_FAILURES must be >= 1, got {self.nt_secondary_alarm_failures}"
+                )
 
         # Validate analytics configuration
         if self.nt_enable_kv_reports:
@@ -102,6 +770,48 @@ class ProducerConfig:
             if not self.nt_node_id:
                 raise ValueError("NT_NODE_ID must be set when analytics enabled")
 
+    def redis_endpoint(self, role: str) -> RedisEndpoint:
+        """Connection settings for a Redis role (see REDIS_ROLES).
+
+        The report cache is REDIS_URL; the stream and pub/sub roles use
+        REDIS_{ROLE}_URL with their own password and TLS files, or the
+        cache endpoint when their URL is unset.
+        """
+        if role not in REDIS_ROLES:
+            raise ValueError(f"Unknown Redis role {role}, expected one of {', '.join(REDIS_ROLES)}")
+        prefix = "redis_" if role == "cache" else f"redis_{role}_"
+        if role != "cache" and not getattr(self, f"{prefix}url"):
+            prefix = "redis_"
+        return RedisEndpoint(
+            url=getattr(self, f"{prefix}url"),
+            password=getattr(self, f"{prefix}password"),
+            tls_ca_cert=getattr(self, f"{prefix}tls_ca_cert"),
+            tls_cert=getattr(self, f"{prefix}tls_cert"),
+            tls_key=getattr(self, f"{prefix}tls_key"),
+        )
+
+    def _validate_redis_endpoint(self, role: str) -> None:
+        endpoint = self.redis_endpoint(role)
+        name = "REDIS" if role == "cache" or not getattr(self, f"redis_{role}_url") else f"REDIS_{role.upper()}"
+        if not endpoint.ssl_options():
+            return
+        if not endpoint.tls:
+            raise ValueError(f"{name}_TLS_* files need a rediss:// {name}_URL, got {endpoint.url}")
+        if bool(endpoint.tls_cert) != bool(endpoint.tls_key):
+            raise ValueError(f"{name}_TLS_CERT and {name}_TLS_KEY must be set together")
+        for path in endpoint.ssl_options().values():
+            if not os.path.isfile(path):
+                raise ValueError(f"{name}_TLS_* file not found: {path}")
+
+    def to_dict(self, mask_secrets: bool = True) -> dict[str, Any]:
+        """Effective configuration, with secrets masked by default."""
+        data = asdict(self)
+        if mask_secrets:
+            for name in SECRET_FIELDS:
+                if data.get(name):
+                    data[name] = "***"
+        return data
+
     def get_analytics_config(self) -> dict:
         """Get analytics configuration as dictionary.
 
@@ -114,13 +824,62 @@ class ProducerConfig:
             "node_id": self.nt_node_id,
             "report_period_ms": self.nt_report_period_ms,
             "slow_period_ms": self.nt_slow_period_ms,
+            "processing_profile": self.nt_processing_profile,
             # US2: Multi-instance coordination
             "enable_multi_instance": self.nt_enable_multi_instance,
             "lease_ttl_ms": self.nt_lease_ttl_ms,
             "hrw_sticky_pct": self.nt_hrw_sticky_pct,
             "min_hold_ms": self.nt_min_hold_ms,
             "metrics_port": self.nt_metrics_port,
+            "health_stall_ms": self.nt_health_stall_ms,
+            "flow_window_sec": self.flow_window_sec,
+            "flow_windows": self.nt_flow_windows,
+            "publish_workers": self.nt_publish_workers,
+            "publish_flush_ms": self.nt_publish_flush_ms,
+            "publish_batch_max": self.nt_publish_batch_max,
+            "secondary_redis_url": self.nt_secondary_redis_url,
+            "secondary_publish_workers": self.nt_secondary_publish_workers,
+            "secondary_alarm_failures": self.nt_secondary_alarm_failures,
+            "staleness_sweep_ms": self.nt_staleness_sweep_ms,
+            "allow_anomaly_injection": self.nt_allow_anomaly_injection,
+            "invariant_mode": self.nt_invariant_mode,
+            "notional_tiers": self.nt_notional_tiers,
+            "symbol_tiers": self.nt_symbol_tiers,
+            "stable_quotes": self.nt_stable_quotes,
+            "book_history_interval_ms": self.nt_book_history_interval_ms,
+            "book_history_retention_sec": self.nt_book_history_retention_sec,
+            "book_history_levels": self.nt_book_history_levels,
+            "digest_ttl_sec": self.nt_digest_ttl_sec,
+            "daily_ttl_sec": self.nt_daily_ttl_sec,
+            "venue_report_interval_ms": self.nt_venue_report_interval_ms,
+            "venue_spread_widen_factor": self.nt_venue_spread_widen_factor,
+            "relative_strength_interval_sec": self.nt_relative_strength_interval_sec,
+            "relative_strength_benchmark": self.nt_relative_strength_benchmark,
+            "signals_maxlen": self.nt_signals_maxlen,
+            "anomaly_budget_per_hour": self.nt_anomaly_budget_per_hour,
+            "watch_refresh_ms": self.nt_watch_refresh_ms,
+            "canary_modules": self.nt_canary_modules,
+            "canary_ttl_sec": self.nt_canary_ttl_sec,
+            "canary_compare_sec": self.nt_canary_compare_sec,
+            "poc_trend_window_sec": self.nt_poc_trend_window_sec,
+            "poc_trend_threshold_bps": self.nt_poc_trend_threshold_bps,
+            "footprint_window_sec": self.nt_footprint_window_sec,
+            "footprint_bucket_bps": self.nt_footprint_bucket_bps,
+            "footprint_top_n": self.nt_footprint_top_n,
+            "disabled_sections": self.nt_disabled_sections,
+            "symbol_sections": self.nt_symbol_sections,
+            "disabled_detectors": self.nt_disabled_detectors,
+            "symbol_detectors": self.nt_symbol_detectors,
+            "async_detectors": self.nt_async_detectors,
+            "detector_budget_ms": self.nt_detector_budget_ms,
+            "trade_tape_size": self.nt_trade_tape_size,
+            "report_recent_trades": self.nt_report_recent_trades,
+            "churn_window_sec": self.nt_churn_window_sec,
+            "clock_skew_warn_ms": self.nt_clock_skew_warn_ms,
+            "reorder_window_ms": self.nt_reorder_window_ms,
             "redis_url": self.redis_url,
             "redis_password": self.redis_password,
+            "redis_stream_url": self.redis_endpoint("stream").url,
+            "redis_pubsub_url": self.redis_endpoint("pubsub").url,
             "symbols": self.symbols,
         }
@@ -12,16 +12,24 @@ logger = structlog.get_logger()
 class LeaseManager:
     """Manages writer leases for symbols using Redis Lua scripts."""
 
-    def __init__(self, redis_client: Redis, node_id: str, lua_dir: Optional[Path] = None):
+    def __init__(
+        self,
+        redis_client: Redis,
+        node_id: str,
+        lua_dir: Optional[Path] = None,
+        key_prefix: str = "report:writer:",
+    ):
         """Initialize lease manager.
 
         Args:
             redis_client: Redis client instance
             node_id: Unique node identifier
             lua_dir: Directory containing Lua scripts (default: producer/lua/)
+            key_prefix: Prefix of the lease and token keys (one per processing profile group)
         """
         self.redis = redis_client
         self.node_id = node_id
+        self.key_prefix = key_prefix
 
         # Find Lua script directory
         if lua_dir is None:
@@ -69,8 +77,8 @@ class LeaseManager:
         Returns:
             Fencing token (int) if acquired, None if already held by another node
         """
-        lease_key = f"report:writer:{symbol}"
-        token_key = f"report:writer:token:{symbol}"
+        lease_key = f"{self.key_prefix}{symbol}"
+        token_key = f"{self.key_prefix}token:{symbol}"
 
         try:
             result = self.acquire_script(
@@ -110,7 +118,7 @@ class LeaseManager:
         Returns:
             True if renewed successfully, False if ownership lost
         """
-        lease_key = f"report:writer:{symbol}"
+        lease_key = f"{self.key_prefix}{symbol}"
 
         try:
             result = self.renew_script(
@@ -149,7 +157,7 @@ class LeaseManager:
         Returns:
             True if released successfully, False if not owner
         """
-        lease_key = f"report:writer:{symbol}"
+        lease_key = f"{self.key_prefix}{symbol}"
 
         try:
             result = self.release_script(
@@ -179,7 +187,7 @@ class LeaseManager:
         Returns:
             Node ID of current owner, or None if no lease
         """
-        lease_key = f"report:writer:{symbol}"
+        lease_key = f"{self.key_prefix}{symbol}"
         try:
             owner = self.redis.get(lease_key)
             # redis-py 5.x returns strings by default (not bytes)
@@ -199,7 +207,7 @@ class LeaseManager:
         Returns:
             Current fencing token, or None if not found
         """
-        token_key = f"report:writer:token:{symbol}"
+        token_key = f"{self.key_prefix}token:{symbol}"
         try:
             token = self.redis.get(token_key)
             return int(token) if token else None
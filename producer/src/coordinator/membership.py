@@ -21,7 +21,8 @@ class NodeMembership:
         pid: int,
         metrics_url: str,
         heartbeat_interval_sec: float = 1.0,
-        ttl_sec: int = 5
+        ttl_sec: int = 5,
+        key_prefix: str = "nt:"
     ):
         """Initialize node membership manager.
 
@@ -33,6 +34,7 @@ class NodeMembership:
             metrics_url: Prometheus metrics endpoint URL
             heartbeat_interval_sec: Heartbeat interval in seconds
             ttl_sec: Key TTL in seconds (should be > 2x heartbeat interval)
+            key_prefix: Prefix of the node keys (one per processing profile group)
         """
         self.redis = redis_client
         self.node_id = node_id
@@ -42,16 +44,17 @@ class NodeMembership:
         self.heartbeat_interval_sec = heartbeat_interval_sec
         self.ttl_sec = ttl_sec
         self.started_at = datetime.utcnow()
+        self.node_key_prefix = f"{key_prefix}node:"
 
         # Backup tracking ZSET
-        self.nodes_seen_key = "nt:nodes_seen"
+        self.nodes_seen_key = f"{key_prefix}nodes_seen"
 
     def heartbeat(self) -> None:
         """Send heartbeat to Redis (SET with TTL + ZADD backup).
 
         Should be called every heartbeat_interval_sec with jitter.
         """
-        key = f"nt:node:{self.node_id}"
+        key = f"{self.node_key_prefix}{self.node_id}"
 
         metadata = {
             "node_id": self.node_id,
@@ -99,9 +102,9 @@ class NodeMembership:
         active_nodes = []
 
         try:
-            # Scan for all nt:node:* keys
+            # Scan for all {prefix}node:* keys
             cursor = 0
-            pattern = "nt:node:*"
+            pattern = f"{self.node_key_prefix}*"
 
             while True:
                 cursor, keys = self.redis.scan(cursor, match=pattern, count=100)
@@ -153,7 +156,7 @@ class NodeMembership:
         Removes node from active membership and backup ZSET.
         """
         try:
-            key = f"nt:node:{self.node_id}"
+            key = f"{self.node_key_prefix}{self.node_id}"
             self.redis.delete(key)
             self.redis.zrem(self.nodes_seen_key, self.node_id)
             logger.info("membership_cleanup_complete", node_id=self.node_id)
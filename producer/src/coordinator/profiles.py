@@ -0,0 +1,43 @@
+"""Processing profiles: which report cycles a producer node runs.
+
+A node normally runs both cycles for the symbols it owns, so a slow
+detector or footprint calculation competes with the 250ms fast cycle for
+the same thread. NT_PROCESSING_PROFILE splits them across nodes:
+
+- full (default): fast and slow cycle
+- fast: only the fast cycle (L1, depth, flow, health) and the tasks built
+  on it (staleness sweep, book history, digests, venue report, relative
+  strength)
+- deep: only the slow cycle (volume profile, footprint, liquidity walls,
+  anomalies, signals), enriching the report the fast node published under
+  report:{symbol}
+
+Each profile coordinates in its own group: deep nodes heartbeat and take
+writer leases under their own keys, so a fast node and a deep node each
+own every symbol once in their group. full and fast nodes share the
+default group; don't run deep nodes next to full ones, or symbols get two
+slow cycles.
+"""
+from dataclasses import dataclass
+
+
+@dataclass(frozen=True)
+class ProcessingProfile:
+    """Cycles a profile runs and the coordination keys of its group."""
+    name: str
+    fast_cycle: bool
+    slow_cycle: bool
+    # Prefix of the writer lease keys ({prefix}{symbol}, {prefix}token:{symbol})
+    lease_prefix: str = "report:writer:"
+    # Prefix of the membership keys ({prefix}node:{node_id}, {prefix}nodes_seen)
+    membership_prefix: str = "nt:"
+
+
+PROCESSING_PROFILES = {
+    "full": ProcessingProfile("full", fast_cycle=True, slow_cycle=True),
+    "fast": ProcessingProfile("fast", fast_cycle=True, slow_cycle=False),
+    "deep": ProcessingProfile(
+        "deep", fast_cycle=False, slow_cycle=True,
+        lease_prefix="report:writer:deep:", membership_prefix="nt:deep:",
+    ),
+}
@@ -0,0 +1,259 @@
+"""Anomaly detector evaluation against labeled market events.
+
+Tuning detector settings (NT_DISABLED_DETECTORS, NT_SYMBOL_DETECTORS,
+notional tiers, NT_CHURN_WINDOW_SEC) needs a measure of what a change
+does. A labeled dataset is a contract-harness fixture
+(tests/contract/harness.py) plus ground-truth anomaly intervals:
+
+    {
+      "symbol": "BTCUSDT",
+      "start": "2026-01-01T00:00:00Z",
+      "events": [
+        {"at_ms": 0, "type": "book", "side": "bid", "price": 100.0, "qty": 1.5},
+        {"at_ms": 5, "type": "trade", "price": 100.5, "qty": 0.2, "side": "BUY"}
+      ],
+      "labels": [
+        {"type": "spoofing", "start_ms": 12000, "end_ms": 15000}
+      ]
+    }
+
+Events are replayed through SymbolState on a replay clock and the enabled
+detectors run every NT_SLOW_PERIOD_MS, as in the slow cycle. Each run is
+scored per detector: positive when the run falls inside a label of the
+detector's type (extended by the tolerance, as detectors report for a
+while after the activity), predicted when the detector fired. From the
+tp/fp/fn/tn counts come precision, recall and F1; labels_detected counts
+labels with at least one detection. Detections outside the detector's own
+labels are broken down by the label type they fell in (confused_with), or
+"unlabeled".
+
+Run with `python -m src.cli detector-eval DATASET [DATASET ...]`; counts
+are summed over all datasets.
+"""
+import json
+from contextlib import ExitStack
+from dataclasses import dataclass, field
+from datetime import datetime, timedelta
+from pathlib import Path
+from typing import Any, Optional
+from unittest import mock
+
+from src.calculators.anomalies import ANOMALY_DETECTORS
+from src.calculators.notional import DEFAULT_NOTIONAL_TIERS, NotionalTier
+from src.reporters.detectors import DetectorInputs, run_detectors
+from src.state.symbol_state import SymbolState, TradeTick
+
+# Modules whose datetime.now() reads the replay clock
+REPLAY_MODULES = (
+    "src.state.symbol_state",
+    "src.state.flow_buckets",
+    "src.state.book_churn",
+    "src.reporters.detectors",
+    "src.calculators.anomalies",
+)
+
+
+class _ReplayClock:
+    """Clock advanced by event offsets instead of wall time."""
+
+    def __init__(self, start: datetime):
+        self.current = start
+
+    def datetime_class(self) -> type:
+        clock = self
+
+        class ReplayDatetime(datetime):
+            @classmethod
+            def now(cls, tz=None):
+                return clock.current if tz is None else clock.current.astimezone(tz)
+
+        return ReplayDatetime
+
+
+@dataclass
+class DetectorScore:
+    """Per-run confusion counts of one detector."""
+    tp: int = 0
+    fp: int = 0
+    fn: int = 0
+    tn: int = 0
+    labels: int = 0
+    labels_detected: int = 0
+    # Label type (or "unlabeled") -> false positive runs inside it
+    confused_with: dict[str, int] = field(default_factory=dict)
+
+    def to_dict(self) -> dict:
+        precision = self.tp / (self.tp + self.fp) if self.tp + self.fp else None
+        recall = self.tp / (self.tp + self.fn) if self.tp + self.fn else None
+        f1 = (
+            2 * precision * recall / (precision + recall)
+            if precision is not None and recall is not None and precision + recall else None
+        )
+        return {
+            "precision": round(precision, 4) if precision is not None else None,
+            "recall": round(recall, 4) if recall is not None else None,
+            "f1": round(f1, 4) if f1 is not None else None,
+            "confusion": {"tp": self.tp, "fp": self.fp, "fn": self.fn, "tn": self.tn},
+            "labels": self.labels,
+            "labels_detected": self.labels_detected,
+            "confused_with": dict(sorted(self.confused_with.items())),
+        }
+
+
+class DetectorEvaluator:
+    """Replays labeled datasets through the detectors and scores their output."""
+
+    def __init__(
+        self,
+        slow_period_ms: int = 2000,
+        churn_window_sec: int = 10,
+        disabled_detectors: Optional[list[str]] = None,
+        symbol_detectors: Optional[dict[str, dict[str, bool]]] = None,
+        notional_tiers: Optional[dict[str, NotionalTier]] = None,
+        symbol_tiers: Optional[dict[str, str]] = None,
+        tolerance_ms: int = 0,
+    ):
+        """Initialize evaluator.
+
+        Args:
+            slow_period_ms: Interval between detector runs (NT_SLOW_PERIOD_MS)
+            churn_window_sec: Churn and pulled-level window (NT_CHURN_WINDOW_SEC)
+            disabled_detectors: Detectors off everywhere (NT_DISABLED_DETECTORS)
+            symbol_detectors: Per-symbol {detector: enabled} overrides (NT_SYMBOL_DETECTORS)
+            notional_tiers: Severity tiers by name (built-in tiers plus NT_NOTIONAL_TIERS)
+            symbol_tiers: Symbol -> tier name (NT_SYMBOL_TIERS)
+            tolerance_ms: How long after a label ends detections still count for it
+        """
+        self.slow_period_ms = slow_period_ms
+        self.churn_window_sec = churn_window_sec
+        self.disabled_detectors = frozenset(disabled_detectors or [])
+        self.symbol_detectors = symbol_detectors or {}
+        self.notional_tiers = notional_tiers or DEFAULT_NOTIONAL_TIERS
+        self.symbol_tiers = symbol_tiers or {}
+        self.tolerance_ms = tolerance_ms
+        self.scores: dict[str, DetectorScore] = {}
+        self.datasets = 0
+        self.runs = 0
+
+    def enabled_detectors(self, symbol: str) -> list[str]:
+        """Detectors that run for a symbol under the configured toggles."""
+        toggles = self.symbol_detectors.get(symbol, {})
+        return [
+            name for name in ANOMALY_DETECTORS
+            if toggles.get(name, name not in self.disabled_detectors)
+        ]
+
+    def evaluate(self, dataset: dict[str, Any]) -> None:
+        """Replay one dataset and add its runs to the scores."""
+        symbol = dataset["symbol"]
+        labels = dataset.get("labels", [])
+        for label in labels:
+            if label["type"] not in ANOMALY_DETECTORS:
+                raise ValueError(
+                    f"Unknown label type {label['type']} (expected one of {', '.join(ANOMALY_DETECTORS)})"
+                )
+
+        detectors = self.enabled_detectors(symbol)
+        detections = self._replay(dataset, detectors)
+        self.datasets += 1
+        self.runs += len(detections)
+
+        for name in detectors:
+            score = self.scores.setdefault(name, DetectorScore())
+            own = [label for label in labels if label["type"] == name]
+            score.labels += len(own)
+            score.labels_detected += sum(
+                1 for label in own
+                if any(name in fired and self._covers(label, at_ms) for at_ms, fired in detections)
+            )
+            for at_ms, fired in detections:
+                positive = any(self._covers(label, at_ms) for label in own)
+                predicted = name in fired
+                if positive and predicted:
+                    score.tp += 1
+                elif positive:
+                    score.fn += 1
+                elif predicted:
+                    score.fp += 1
+                    inside = sorted({label["type"] for label in labels if self._covers(label, at_ms)})
+                    for label_type in inside or ["unlabeled"]:
+                        score.confused_with[label_type] = score.confused_with.get(label_type, 0) + 1
+                else:
+                    score.tn += 1
+
+    def summary(self) -> dict:
+        """Scores by detector, in detector order."""
+        return {
+            "datasets": self.datasets,
+            "runs": self.runs,
+            "slow_period_ms": self.slow_period_ms,
+            "tolerance_ms": self.tolerance_ms,
+            "detectors": {
+                name: self.scores[name].to_dict() for name in ANOMALY_DETECTORS if name in self.scores
+            },
+        }
+
+    def _covers(self, label: dict, at_ms: int) -> bool:
+        return label["start_ms"] <= at_ms <= label["end_ms"] + self.tolerance_ms
+
+    def _replay(self, dataset: dict[str, Any], detectors: list[str]) -> list[tuple[int, set[str]]]:
+        """Replay events, running the detectors every slow period.
+
+        Returns:
+            (run offset in ms, detectors that fired) per run
+        """
+        symbol = dataset["symbol"]
+        start = datetime.fromisoformat(dataset["start"].replace("Z", "+00:00"))
+        clock = _ReplayClock(start)
+        state = SymbolState(
+            symbol, notional_tier=self.notional_tiers[self.symbol_tiers.get(symbol, "default")]
+        )
+        events = sorted(dataset["events"], key=lambda event: event["at_ms"])
+        end_ms = max([event["at_ms"] for event in events] + [label["end_ms"] for label in dataset.get("labels", [])])
+        detections = []
+
+        with ExitStack() as stack:
+            for module in REPLAY_MODULES:
+                stack.enter_context(mock.patch(f"{module}.datetime", clock.datetime_class()))
+
+            next_run_ms = self.slow_period_ms
+            i = 0
+            while next_run_ms <= end_ms + self.tolerance_ms:
+                while i < len(events) and events[i]["at_ms"] < next_run_ms:
+                    clock.current = start + timedelta(milliseconds=events[i]["at_ms"])
+                    self._apply(state, events[i], clock.current)
+                    i += 1
+
+                clock.current = start + timedelta(milliseconds=next_run_ms)
+                run = run_detectors(
+                    detectors,
+                    DetectorInputs.from_state(state, churn_window_sec=self.churn_window_sec),
+                    budget_ms=float("inf"),
+                )
+                detections.append((next_run_ms, {anomaly["type"] for anomaly in run.anomalies}))
+                next_run_ms += self.slow_period_ms
+
+        return detections
+
+    @staticmethod
+    def _apply(state: SymbolState, event: dict, timestamp: datetime) -> None:
+        if event["type"] == "book":
+            if event["side"] == "bid":
+                state.update_order_book_bid(event["price"], event["qty"])
+            else:
+                state.update_order_book_ask(event["price"], event["qty"])
+        elif event["type"] == "trade":
+            state.add_trade(TradeTick(
+                timestamp=timestamp, price=event["price"], volume=event["qty"], aggressor_side=event["side"]
+            ))
+        elif event["type"] != "report":
+            raise ValueError(f"Unknown dataset event type: {event['type']}")
+
+
+def load_dataset(path: str) -> dict[str, Any]:
+    """Read a labeled dataset file."""
+    dataset = json.loads(Path(path).read_text())
+    for key in ("symbol", "start", "events"):
+        if key not in dataset:
+            raise ValueError(f"{path}: missing {key}")
+    return dataset
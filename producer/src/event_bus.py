@@ -0,0 +1,536 @@
+"""Event bus interfaces (streams, KV, pub/sub) and an in-memory implementation.
+
+Everything the producer and MCP server exchange goes through Redis: market
+events on streams, reports and side keys in KV, live updates on pub/sub.
+The Protocols below name the subset of redis-py commands the code relies
+on; redis.Redis satisfies them as is, and InMemoryEventBus implements them
+in-process so the whole stack can run in one process for integration tests
+and local development without Docker.
+
+Select the backend by URL: redis://... opens a Redis client, memory://name
+returns the process-wide in-memory bus registered under name (created on
+first use), so every component configured with the same URL shares it.
+Lua scripts (writer leases) are not supported in memory; run with
+coordination disabled.
+"""
+import fnmatch
+import queue
+import threading
+import time
+from datetime import datetime
+from typing import Any, Callable, Iterator, Optional, Protocol, Union
+
+import redis
+from redis import RedisError, ResponseError
+
+MEMORY_SCHEME = "memory://"
+
+
+class KeyValueStore(Protocol):
+    """Plain keys, hashes and sorted sets (reports, side keys, registries)."""
+
+    def get(self, name: str) -> Any: ...
+    def set(self, name: str, value: Any, ex: Optional[int] = None, px: Optional[int] = None,
+            nx: bool = False, xx: bool = False, keepttl: bool = False) -> Optional[bool]: ...
+    def delete(self, *names: str) -> int: ...
+    def exists(self, *names: str) -> int: ...
+    def incr(self, name: str, amount: int = 1) -> int: ...
+    def pexpire(self, name: str, time: int) -> bool: ...
+    def pttl(self, name: str) -> int: ...
+    def memory_usage(self, key: str) -> Optional[int]: ...
+    def scan_iter(self, match: Optional[str] = None, count: Optional[int] = None) -> Iterator[str]: ...
+    def type(self, name: str) -> str: ...
+    def hset(self, name: str, key: Optional[str] = None, value: Any = None, mapping: Optional[dict] = None) -> int: ...
+    def hgetall(self, name: str) -> dict: ...
+    def hdel(self, name: str, *keys: str) -> int: ...
+    def zadd(self, name: str, mapping: dict) -> int: ...
+    def zrem(self, name: str, *values: Any) -> int: ...
+    def zrangebyscore(self, name: str, min: Any, max: Any, withscores: bool = False) -> list: ...
+    def zremrangebyscore(self, name: str, min: Any, max: Any) -> int: ...
+
+
+class StreamLog(Protocol):
+    """Append-only streams (market events, metrics, request logs)."""
+
+    def xadd(self, name: str, fields: dict, id: str = "*", maxlen: Optional[int] = None,
+             approximate: bool = True, minid: Optional[str] = None) -> Any: ...
+    def xlen(self, name: str) -> int: ...
+    def xtrim(self, name: str, maxlen: Optional[int] = None, approximate: bool = True,
+              minid: Optional[str] = None) -> int: ...
+    def xrevrange(self, name: str, max: str = "+", min: str = "-", count: Optional[int] = None) -> list: ...
+    def xread(self, streams: dict, count: Optional[int] = None, block: Optional[int] = None) -> list: ...
+
+
+class PubSubBus(Protocol):
+    """Fire-and-forget notifications (reports:{symbol} channels)."""
+
+    def publish(self, channel: str, message: Any) -> int: ...
+    def pubsub(self) -> Any: ...
+
+
+class EventBus(KeyValueStore, StreamLog, PubSubBus, Protocol):
+    """Full bus: what the producer and MCP server need from Redis."""
+
+    def pipeline(self, transaction: bool = True) -> Any: ...
+    def ping(self) -> bool: ...
+    def close(self) -> None: ...
+
+
+def is_memory_url(url: str) -> bool:
+    """Whether the URL selects the in-memory bus."""
+    return url.startswith(MEMORY_SCHEME)
+
+
+def open_bus(url: str, **kwargs) -> EventBus:
+    """Open the bus for a URL: redis.from_url(url, **kwargs), or the named in-memory bus."""
+    if is_memory_url(url):
+        return get_memory_bus(url[len(MEMORY_SCHEME):] or "default")
+    return redis.from_url(url, **kwargs)
+
+
+_buses: dict[str, "InMemoryEventBus"] = {}
+_buses_lock = threading.Lock()
+
+
+def get_memory_bus(name: str = "default") -> "InMemoryEventBus":
+    """Process-wide in-memory bus for name, created on first use."""
+    with _buses_lock:
+        if name not in _buses:
+            _buses[name] = InMemoryEventBus(name)
+        return _buses[name]
+
+
+def _now_ms() -> int:
+    return int(time.time() * 1000)
+
+
+def _score_bound(value: Any) -> tuple[float, bool]:
+    """Parse a ZRANGEBYSCORE bound into (score, exclusive)."""
+    if isinstance(value, str):
+        exclusive = value.startswith("(")
+        text = value[1:] if exclusive else value
+        return float(text), exclusive
+    return float(value), False
+
+
+def _stream_id(value: str) -> tuple[int, int]:
+    ms, _, seq = value.partition("-")
+    return int(ms), int(seq or 0)
+
+
+class _Entry:
+    __slots__ = ("kind", "value", "expire_at_ms")
+
+    def __init__(self, kind: str, value: Any):
+        self.kind = kind  # "string", "hash", "zset" or "stream"
+        self.value = value
+        self.expire_at_ms: Optional[int] = None
+
+
+class InMemoryEventBus:
+    """Thread-safe in-process EventBus mirroring redis-py semantics.
+
+    Values are stored as given (str with decode_responses-style callers,
+    bytes otherwise). Expired keys are removed lazily on access. Errors
+    are redis exceptions, so existing RedisError handling applies.
+    """
+
+    def __init__(self, name: str = "default"):
+        self.name = name
+        self._lock = threading.RLock()
+        self._data: dict[str, _Entry] = {}
+        self._listeners: list[tuple[str, bool, Callable[[dict], None]]] = []
+        self._last_stream_id: dict[str, tuple[int, int]] = {}
+
+    # -- keys -------------------------------------------------------------
+
+    def _entry(self, name: str, kind: Optional[str] = None) -> Optional[_Entry]:
+        entry = self._data.get(name)
+        if entry is None:
+            return None
+        if entry.expire_at_ms is not None and entry.expire_at_ms <= _now_ms():
+            del self._data[name]
+            return None
+        if kind and entry.kind != kind:
+            raise ResponseError("WRONGTYPE Operation against a key holding the wrong kind of value")
+        return entry
+
+    def _create(self, name: str, kind: str, value: Any) -> _Entry:
+        entry = self._entry(name, kind)
+        if entry is None:
+            entry = self._data[name] = _Entry(kind, value)
+        return entry
+
+    def get(self, name: str) -> Any:
+        with self._lock:
+            entry = self._entry(name, "string")
+            return entry.value if entry else None
+
+    def set(self, name: str, value: Any, ex: Optional[int] = None, px: Optional[int] = None,
+            nx: bool = False, xx: bool = False, keepttl: bool = False) -> Optional[bool]:
+        with self._lock:
+            existing = self._entry(name)
+            if (nx and existing) or (xx and not existing):
+                return None
+            entry = _Entry("string", value if isinstance(value, (str, bytes)) else str(value))
+            if keepttl and existing:
+                entry.expire_at_ms = existing.expire_at_ms
+            if ex is not None:
+                entry.expire_at_ms = _now_ms() + int(ex) * 1000
+            if px is not None:
+                entry.expire_at_ms = _now_ms() + int(px)
+            self._data[name] = entry
+            return True
+
+    def delete(self, *names: str) -> int:
+        with self._lock:
+            deleted = 0
+            for name in names:
+                if self._entry(name):
+                    del self._data[name]
+                    deleted += 1
+            return deleted
+
+    def exists(self, *names: str) -> int:
+        with self._lock:
+            return sum(1 for name in names if self._entry(name))
+
+    def incr(self, name: str, amount: int = 1) -> int:
+        with self._lock:
+            entry = self._entry(name, "string")
+            try:
+                value = int(entry.value if entry else 0) + amount
+            except ValueError:
+                raise ResponseError("value is not an integer or out of range")
+            if entry:
+                entry.value = str(value)
+            else:
+                self._data[name] = _Entry("string", str(value))
+            return value
+
+    def pexpire(self, name: str, time: int) -> bool:
+        with self._lock:
+            entry = self._entry(name)
+            if entry is None:
+                return False
+            entry.expire_at_ms = _now_ms() + int(time)
+            return True
+
+    def expire(self, name: str, time: int) -> bool:
+        return self.pexpire(name, int(time) * 1000)
+
+    def pttl(self, name: str) -> int:
+        with self._lock:
+            entry = self._entry(name)
+            if entry is None:
+                return -2
+            if entry.expire_at_ms is None:
+                return -1
+            return max(0, entry.expire_at_ms - _now_ms())
+
+    def memory_usage(self, key: str) -> Optional[int]:
+        """Approximate size in bytes (serialized length of the value)."""
+        with self._lock:
+            entry = self._entry(key)
+            return len(key) + len(repr(entry.value)) if entry else None
+
+    def expireat(self, name: str, when: Union[int, datetime]) -> bool:
+        with self._lock:
+            entry = self._entry(name)
+            if entry is None:
+                return False
+            seconds = when.timestamp() if isinstance(when, datetime) else when
+            entry.expire_at_ms = int(seconds * 1000)
+            return True
+
+    def scan(self, cursor: int = 0, match: Optional[str] = None, count: Optional[int] = None) -> tuple[int, list]:
+        return 0, list(self.scan_iter(match))
+
+    def scan_iter(self, match: Optional[str] = None, count: Optional[int] = None) -> Iterator[str]:
+        with self._lock:
+            keys = [name for name in list(self._data) if self._entry(name)]
+        return iter([k for k in keys if match is None or fnmatch.fnmatchcase(k, match)])
+
+    def type(self, name: str) -> str:
+        with self._lock:
+            entry = self._entry(name)
+            return entry.kind if entry else "none"
+
+    # -- hashes -----------------------------------------------------------
+
+    def hset(self, name: str, key: Optional[str] = None, value: Any = None, mapping: Optional[dict] = None) -> int:
+        items = dict(mapping or {})
+        if key is not None:
+            items[key] = value
+        if not items:
+            raise ResponseError("wrong number of arguments for 'hset' command")
+        with self._lock:
+            fields = self._create(name, "hash", {}).value
+            added = sum(1 for field in items if field not in fields)
+            for field, field_value in items.items():
+                fields[field] = field_value if isinstance(field_value, (str, bytes)) else str(field_value)
+            return added
+
+    def hgetall(self, name: str) -> dict:
+        with self._lock:
+            entry = self._entry(name, "hash")
+            return dict(entry.value) if entry else {}
+
+    def hdel(self, name: str, *keys: str) -> int:
+        with self._lock:
+            entry = self._entry(name, "hash")
+            if entry is None:
+                return 0
+            removed = sum(1 for key in keys if entry.value.pop(key, None) is not None)
+            if not entry.value:
+                del self._data[name]
+            return removed
+
+    # -- sorted sets ------------------------------------------------------
+
+    def zadd(self, name: str, mapping: dict) -> int:
+        with self._lock:
+            zset = self._create(name, "zset", {}).value
+            added = sum(1 for member in mapping if member not in zset)
+            for member, score in mapping.items():
+                zset[member] = float(score)
+            return added
+
+    def zrem(self, name: str, *values: Any) -> int:
+        with self._lock:
+            entry = self._entry(name, "zset")
+            if entry is None:
+                return 0
+            removed = sum(1 for v in values if entry.value.pop(v, None) is not None)
+            if not entry.value:
+                del self._data[name]
+            return removed
+
+    def _zmatch(self, zset: dict, min: Any, max: Any) -> list:
+        low, low_excl = _score_bound(min)
+        high, high_excl = _score_bound(max)
+        return [
+            (member, score) for member, score in sorted(zset.items(), key=lambda kv: (kv[1], kv[0]))
+            if (score > low if low_excl else score >= low) and (score < high if high_excl else score <= high)
+        ]
+
+    def zrangebyscore(self, name: str, min: Any, max: Any, withscores: bool = False) -> list:
+        with self._lock:
+            entry = self._entry(name, "zset")
+            matched = self._zmatch(entry.value, min, max) if entry else []
+            return matched if withscores else [member for member, _ in matched]
+
+    def zremrangebyscore(self, name: str, min: Any, max: Any) -> int:
+        with self._lock:
+            entry = self._entry(name, "zset")
+            if entry is None:
+                return 0
+            matched = self._zmatch(entry.value, min, max)
+            for member, _ in matched:
+                del entry.value[member]
+            if not entry.value:
+                del self._data[name]
+            return len(matched)
+
+    # -- streams ----------------------------------------------------------
+
+    def xadd(self, name: str, fields: dict, id: str = "*", maxlen: Optional[int] = None,
+             approximate: bool = True, minid: Optional[str] = None) -> str:
+        with self._lock:
+            stream = self._create(name, "stream", []).value
+            last = self._last_stream_id.get(name, (0, 0))
+            if id == "*":
+                ms = _now_ms()
+                new_id = (ms, 0) if ms > last[0] else (last[0], last[1] + 1)
+            else:
+                new_id = _stream_id(id)
+                if new_id <= last:
+                    raise ResponseError("The ID specified in XADD is equal or smaller than the target stream top item")
+            self._last_stream_id[name] = new_id
+            stream_id = f"{new_id[0]}-{new_id[1]}"
+            stream.append((stream_id, dict(fields)))
+            self._trim(stream, maxlen, minid)
+            return stream_id
+
+    def xlen(self, name: str) -> int:
+        with self._lock:
+            entry = self._entry(name, "stream")
+            return len(entry.value) if entry else 0
+
+    def xtrim(self, name: str, maxlen: Optional[int] = None, approximate: bool = True,
+              minid: Optional[str] = None) -> int:
+        with self._lock:
+            entry = self._entry(name, "stream")
+            return self._trim(entry.value, maxlen, minid) if entry else 0
+
+    @staticmethod
+    def _trim(stream: list, maxlen: Optional[int], minid: Optional[str]) -> int:
+        before = len(stream)
+        if maxlen is not None and len(stream) > maxlen:
+            del stream[:len(stream) - maxlen]
+        if minid is not None:
+            floor = _stream_id(minid)
+            stream[:] = [item for item in stream if _stream_id(item[0]) >= floor]
+        return before - len(stream)
+
+    def xrevrange(self, name: str, max: str = "+", min: str = "-", count: Optional[int] = None) -> list:
+        with self._lock:
+            entry = self._entry(name, "stream")
+            if entry is None:
+                return []
+            high = None if max == "+" else _stream_id(max)
+            low = None if min == "-" else _stream_id(min)
+            items = [
+                (stream_id, dict(fields)) for stream_id, fields in reversed(entry.value)
+                if (high is None or _stream_id(stream_id) <= high) and (low is None or _stream_id(stream_id) >= low)
+            ]
+            return items[:count] if count is not None else items
+
+    def xread(self, streams: dict, count: Optional[int] = None, block: Optional[int] = None) -> list:
+        # "$" means entries added after this call; block=0 waits forever, like Redis
+        with self._lock:
+            after = {
+                name: self._last_stream_id.get(name, (0, 0)) if last_id == "$" else _stream_id(last_id)
+                for name, last_id in streams.items()
+            }
+        deadline = None if not block else time.monotonic() + block / 1000
+        while True:
+            with self._lock:
+                result = []
+                for name, floor in after.items():
+                    entry = self._entry(name, "stream")
+                    items = [
+                        (stream_id, dict(fields)) for stream_id, fields in (entry.value if entry else [])
+                        if _stream_id(stream_id) > floor
+                    ]
+                    if items:
+                        result.append([name, items[:count] if count is not None else items])
+            if result or block is None or (deadline is not None and time.monotonic() >= deadline):
+                return result
+            time.sleep(0.01)
+
+    # -- pub/sub ----------------------------------------------------------
+
+    def add_listener(self, channel: str, callback: Callable[[dict], None], pattern: bool = False) -> Callable[[], None]:
+        """Call callback with a redis-py style message for each publish; returns an unsubscribe function."""
+        listener = (channel, pattern, callback)
+        with self._lock:
+            self._listeners.append(listener)
+
+        def remove() -> None:
+            with self._lock:
+                if listener in self._listeners:
+                    self._listeners.remove(listener)
+        return remove
+
+    def publish(self, channel: str, message: Any) -> int:
+        with self._lock:
+            listeners = list(self._listeners)
+        receivers = 0
+        for name, pattern, callback in listeners:
+            if pattern and fnmatch.fnmatchcase(channel, name):
+                callback({"type": "pmessage", "pattern": name, "channel": channel, "data": message})
+            elif not pattern and channel == name:
+                callback({"type": "message", "pattern": None, "channel": channel, "data": message})
+            else:
+                continue
+            receivers += 1
+        return receivers
+
+    def pubsub(self) -> "InMemoryPubSub":
+        return InMemoryPubSub(self)
+
+    # -- misc -------------------------------------------------------------
+
+    def pipeline(self, transaction: bool = True) -> "InMemoryPipeline":
+        return InMemoryPipeline(self)
+
+    def register_script(self, script: str):
+        raise NotImplementedError("Lua scripts need Redis; disable coordination with the in-memory bus")
+
+    def ping(self) -> bool:
+        return True
+
+    def info(self, section: Optional[str] = None) -> dict:
+        with self._lock:
+            keys = len(self._data)
+        return {"redis_version": "in-memory", "redis_mode": "memory", "used_memory_human": "n/a", "keys": keys}
+
+    def flushall(self) -> bool:
+        with self._lock:
+            self._data.clear()
+            self._last_stream_id.clear()
+        return True
+
+    def close(self) -> None:
+        """No-op: the bus lives for the process so other components keep their data."""
+
+    def __repr__(self) -> str:
+        return f"InMemoryEventBus(name={self.name}, keys={len(self._data)})"
+
+
+class InMemoryPipeline:
+    """Queues commands and runs them atomically on execute(), like a MULTI pipeline."""
+
+    def __init__(self, bus: InMemoryEventBus):
+        self._bus = bus
+        self._commands: list[tuple[str, tuple, dict]] = []
+
+    def __getattr__(self, name: str):
+        getattr(self._bus, name)  # Unknown commands fail when queued, not on execute
+
+        def queue_command(*args, **kwargs) -> "InMemoryPipeline":
+            self._commands.append((name, args, kwargs))
+            return self
+        return queue_command
+
+    def execute(self, raise_on_error: bool = True) -> list:
+        commands, self._commands = self._commands, []
+        results = []
+        with self._bus._lock:
+            for name, args, kwargs in commands:
+                try:
+                    results.append(getattr(self._bus, name)(*args, **kwargs))
+                except RedisError as e:
+                    if raise_on_error:
+                        raise
+                    results.append(e)
+        return results
+
+    def __enter__(self) -> "InMemoryPipeline":
+        return self
+
+    def __exit__(self, *exc) -> None:
+        self._commands = []
+
+
+class InMemoryPubSub:
+    """Blocking subscriber with the redis-py PubSub interface used by consumers."""
+
+    def __init__(self, bus: InMemoryEventBus):
+        self._bus = bus
+        self._messages: queue.Queue = queue.Queue()
+        self._removers: list[Callable[[], None]] = []
+
+    def subscribe(self, *channels: str) -> None:
+        for channel in channels:
+            self._removers.append(self._bus.add_listener(channel, self._messages.put))
+
+    def psubscribe(self, *patterns: str) -> None:
+        for pattern in patterns:
+            self._removers.append(self._bus.add_listener(pattern, self._messages.put, pattern=True))
+
+    def get_message(self, ignore_subscribe_messages: bool = False, timeout: float = 0.0) -> Optional[dict]:
+        try:
+            return self._messages.get(timeout=timeout) if timeout else self._messages.get_nowait()
+        except queue.Empty:
+            return None
+
+    def listen(self) -> Iterator[dict]:
+        while self._removers:
+            yield self._messages.get()
+
+    def close(self) -> None:
+        for remove in self._removers:
+            remove()
+        self._removers = []
@@ -10,7 +10,12 @@ import structlog
 log = structlog.get_logger()
 
 
-def load_binance_spot_instruments(symbols: list[str]) -> dict[InstrumentId, CurrencyPair]:
+DEFAULT_BASE_URL = "https://api.binance.com"
+
+
+def load_binance_spot_instruments(
+    symbols: list[str], base_url: str | None = None
+) -> dict[InstrumentId, CurrencyPair]:
     """
     Load Binance spot instruments from public API without authentication.
 
@@ -18,6 +23,7 @@ def load_binance_spot_instruments(symbols: list[str]) -> dict[InstrumentId, Curr
 
     Args:
         symbols: List of symbol strings (e.g., ["BTCUSDT", "ETHUSDT"])
+        base_url: REST endpoint (BINANCE_BASE_URL_HTTP, e.g. the simulated exchange; default Binance spot)
 
     Returns:
         Dictionary mapping InstrumentId to CurrencySpot instrument
@@ -26,7 +32,7 @@ def load_binance_spot_instruments(symbols: list[str]) -> dict[InstrumentId, Curr
 
     try:
         # Fetch exchange info from Binance public API (no auth required)
-        url = "https://api.binance.com/api/v3/exchangeInfo"
+        url = f"{(base_url or DEFAULT_BASE_URL).rstrip('/')}/api/v3/exchangeInfo"
 
         log.info(f"Fetching instrument data from Binance public API for symbols: {symbols}")
 
@@ -0,0 +1,206 @@
+"""Retention and orphan cleanup for the producer's Redis keys.
+
+Most key families expire on their own, but a few outlive their purpose:
+reports are written with KEEPTTL and never expire, the reports:index
+registry keeps every symbol ever published, and keys written before a
+retention setting was lowered keep their old TTL. A RedisJanitor sweeps
+the cache on an interval:
+
+- retention: digest:*, daily:* and book_history:* keys without a TTL, or
+  with one longer than the configured retention, get that retention
+- orphans: a symbol that is no longer configured and hasn't been
+  published for orphan_after_sec loses its live-state keys (report,
+  tape, footprint, book history, injection) and its reports:index entry;
+  its digests and daily statistics stay until they expire, so recent
+  history remains readable
+
+Each sweep reports the keys it touched and the memory it reclaimed
+(MEMORY USAGE before deletion). Run it once with `python -m src.cli
+janitor [--dry-run]`.
+"""
+import threading
+import time
+from typing import Optional
+
+import redis
+import structlog
+
+from src.config import ProducerConfig
+from src.event_bus import EventBus
+from src.reporters.redis_cache import REPORT_INDEX_KEY
+
+log = structlog.get_logger()
+
+# Per-symbol live-state keys removed with an orphaned symbol
+SYMBOL_KEY_FAMILIES = {
+    "report": "report:",
+    "trades": "trades:",
+    "footprint": "footprint:",
+    "book_history": "book_history:",
+    "injection": "control:inject:",
+}
+
+# Writer leases share the report: prefix but expire on their own
+LEASE_KEY_PREFIX = "report:writer:"
+
+
+class RedisJanitor:
+    """Periodically enforces key retention and removes orphaned symbol keys."""
+
+    def __init__(
+        self,
+        redis_client: EventBus,
+        symbols: list[str],
+        retention_sec: dict[str, int],
+        orphan_after_sec: int = 86400,
+        interval_sec: float = 3600.0,
+        metrics=None,
+    ):
+        """Initialize janitor.
+
+        Args:
+            redis_client: Redis client for the report cache
+            symbols: Configured symbols (never treated as orphaned)
+            retention_sec: Maximum TTL per key prefix (e.g. {"digest:": 86400}); 0 skips the prefix
+            orphan_after_sec: Seconds since an unconfigured symbol's last report before it is orphaned
+            interval_sec: Seconds between sweeps
+            metrics: Optional PrometheusMetrics for sweep stats
+        """
+        self.redis_client = redis_client
+        self.symbols = set(symbols)
+        self.retention_sec = {prefix: ttl for prefix, ttl in retention_sec.items() if ttl}
+        self.orphan_after_sec = orphan_after_sec
+        self.interval_sec = interval_sec
+        self.metrics = metrics
+
+        self._stop = threading.Event()
+        self._thread: Optional[threading.Thread] = None
+        self.log = log.bind(component="janitor")
+
+    @classmethod
+    def for_config(cls, config: ProducerConfig, redis_client: EventBus, metrics=None) -> "RedisJanitor":
+        """Janitor enforcing the configured digest, daily and book history retention."""
+        return cls(
+            redis_client=redis_client,
+            symbols=config.symbols,
+            retention_sec={
+                "digest:": config.nt_digest_ttl_sec,
+                "daily:": config.nt_daily_ttl_sec,
+                "book_history:": config.nt_book_history_retention_sec if config.nt_book_history_interval_ms else 0,
+            },
+            orphan_after_sec=config.nt_janitor_orphan_after_sec,
+            interval_sec=config.nt_janitor_interval_sec,
+            metrics=metrics,
+        )
+
+    def sweep_once(self, dry_run: bool = False) -> dict:
+        """Run one sweep.
+
+        Args:
+            dry_run: Only report what would change
+
+        Returns:
+            Summary with orphaned_symbols, deleted and expiry_set counts per
+            family, index_removed, reclaimed_bytes, scanned and latency_ms
+        """
+        start = time.perf_counter()
+        summary = {
+            "dry_run": dry_run,
+            "orphaned_symbols": [],
+            "deleted": {},
+            "index_removed": 0,
+            "expiry_set": {},
+            "reclaimed_bytes": 0,
+            "scanned": 0,
+        }
+
+        for prefix, ttl_sec in self.retention_sec.items():
+            family = prefix.rstrip(":")
+            for key in self.redis_client.scan_iter(match=f"{prefix}*", count=500):
+                summary["scanned"] += 1
+                ttl_ms = self.redis_client.pttl(key)
+                if ttl_ms == -1 or ttl_ms > ttl_sec * 1000:
+                    if not dry_run:
+                        self.redis_client.pexpire(key, ttl_sec * 1000)
+                    summary["expiry_set"][family] = summary["expiry_set"].get(family, 0) + 1
+
+        orphans = self._orphaned_symbols()
+        summary["orphaned_symbols"] = sorted(orphans)
+        for symbol in summary["orphaned_symbols"]:
+            for family, prefix in SYMBOL_KEY_FAMILIES.items():
+                key = f"{prefix}{symbol}"
+                size = self.redis_client.memory_usage(key)
+                if size is None:
+                    continue
+                if dry_run or self.redis_client.delete(key):
+                    summary["deleted"][family] = summary["deleted"].get(family, 0) + 1
+                    summary["reclaimed_bytes"] += size
+        indexed = [symbol for symbol, last_published in orphans.items() if last_published is not None]
+        if indexed:
+            summary["index_removed"] = (
+                len(indexed) if dry_run else self.redis_client.zrem(REPORT_INDEX_KEY, *indexed)
+            )
+
+        summary["latency_ms"] = round((time.perf_counter() - start) * 1000, 2)
+        if self.metrics and not dry_run:
+            for family, count in summary["deleted"].items():
+                self.metrics.janitor_keys_deleted.labels(family=family).inc(count)
+            for family, count in summary["expiry_set"].items():
+                self.metrics.janitor_expiry_set.labels(family=family).inc(count)
+            self.metrics.janitor_reclaimed_bytes.inc(summary["reclaimed_bytes"])
+            self.metrics.janitor_last_sweep.set(time.time())
+
+        self.log.info(
+            "janitor_sweep",
+            dry_run=dry_run,
+            orphaned_symbols=summary["orphaned_symbols"],
+            deleted=sum(summary["deleted"].values()),
+            expiry_set=sum(summary["expiry_set"].values()),
+            reclaimed_bytes=summary["reclaimed_bytes"],
+            latency_ms=summary["latency_ms"],
+        )
+        return summary
+
+    def _orphaned_symbols(self) -> dict[str, Optional[float]]:
+        """Unconfigured symbols with live-state keys and no report for orphan_after_sec.
+
+        Returns:
+            Map of orphaned symbol to its reports:index score (None if not indexed)
+        """
+        last_published = dict(self.redis_client.zrangebyscore(REPORT_INDEX_KEY, "-inf", "+inf", withscores=True))
+        candidates = set(last_published)
+        for prefix in SYMBOL_KEY_FAMILIES.values():
+            for key in self.redis_client.scan_iter(match=f"{prefix}*", count=500):
+                if not key.startswith(LEASE_KEY_PREFIX):
+                    candidates.add(key[len(prefix):])
+
+        cutoff_ms = (time.time() - self.orphan_after_sec) * 1000
+        return {
+            symbol: last_published.get(symbol) for symbol in candidates - self.symbols
+            if last_published.get(symbol, 0) < cutoff_ms
+        }
+
+    def _run(self) -> None:
+        while not self._stop.wait(self.interval_sec):
+            try:
+                self.sweep_once()
+            except redis.RedisError as e:
+                self.log.warning("janitor_sweep_failed", error=str(e))
+
+    def start(self) -> None:
+        """Start sweeping in a background thread."""
+        self._thread = threading.Thread(target=self._run, name="redis-janitor", daemon=True)
+        self._thread.start()
+        self.log.info(
+            "janitor_started",
+            retention_sec=self.retention_sec,
+            orphan_after_sec=self.orphan_after_sec,
+            interval_sec=self.interval_sec,
+        )
+
+    def stop(self) -> None:
+        """Stop the background thread."""
+        self._stop.set()
+        if self._thread:
+            self._thread.join(timeout=5)
+            self._thread = None
@@ -0,0 +1,158 @@
+"""Structured logging setup: levels per component, sampling and extra sinks.
+
+Logs are JSON lines on stdout. On top of the global level (NT_LOG_LEVEL):
+
+- NT_LOG_LEVELS overrides it per component, the component= value a logger
+  is bound with ("redis_publisher:warn,analytics_strategy:debug")
+- NT_LOG_SAMPLE keeps 1 in N of high-frequency events ("event_published:100");
+  kept entries carry sample_rate=N so counts can be scaled back up. Warnings
+  and errors are never sampled.
+- NT_LOG_FILE also writes to a file, rotated at NT_LOG_FILE_MAX_MB with
+  NT_LOG_FILE_BACKUPS old files kept
+- NT_LOG_SYSLOG also sends to syslog: a socket path (/dev/log) or
+  udp://host:port / tcp://host:port
+"""
+import itertools
+import logging
+import logging.handlers
+import socket
+from typing import Optional
+from urllib.parse import urlsplit
+
+import structlog
+
+LEVELS = {
+    "debug": logging.DEBUG,
+    "info": logging.INFO,
+    "warn": logging.WARNING,
+    "warning": logging.WARNING,
+    "error": logging.ERROR,
+}
+
+# structlog method name -> stdlib level
+_METHOD_LEVELS = {
+    "debug": logging.DEBUG,
+    "info": logging.INFO,
+    "warning": logging.WARNING,
+    "warn": logging.WARNING,
+    "error": logging.ERROR,
+    "exception": logging.ERROR,
+    "critical": logging.CRITICAL,
+}
+
+# Loggers of this package (structlog names them after the calling module)
+_PRODUCER_LOGGERS = ("src", "__main__")
+
+
+class ComponentLevelFilter:
+    """Drops entries below their component's level (the global level otherwise)."""
+
+    def __init__(self, default_level: int, component_levels: Optional[dict[str, int]] = None):
+        self.default_level = default_level
+        self.component_levels = component_levels or {}
+
+    def __call__(self, logger, method_name: str, event_dict: dict) -> dict:
+        level = _METHOD_LEVELS.get(method_name, logging.INFO)
+        if level < self.component_levels.get(event_dict.get("component"), self.default_level):
+            raise structlog.DropEvent
+        return event_dict
+
+
+class LogSampler:
+    """Keeps 1 in N entries of the configured events (below warning level)."""
+
+    def __init__(self, rates: Optional[dict[str, int]] = None):
+        self.set_rates(rates)
+
+    def set_rates(self, rates: Optional[dict[str, int]]) -> None:
+        self.rates = {event: rate for event, rate in (rates or {}).items() if rate > 1}
+        self._counters = {event: itertools.count() for event in self.rates}
+
+    def __call__(self, logger, method_name: str, event_dict: dict) -> dict:
+        rate = self.rates.get(event_dict.get("event"))
+        if rate is None or _METHOD_LEVELS.get(method_name, logging.INFO) >= logging.WARNING:
+            return event_dict
+        # itertools.count is atomic under the GIL, so threads share one sequence
+        if next(self._counters.get(event_dict["event"]) or itertools.count(1)) % rate:
+            raise structlog.DropEvent
+        event_dict["sample_rate"] = rate
+        return event_dict
+
+
+# Shared by every logger: loggers are cached on first use, so reconfiguration
+# updates these in place rather than installing new processors
+_level_filter = ComponentLevelFilter(logging.INFO)
+_sampler = LogSampler()
+
+# Handlers added by configure_structlog, replaced on reconfiguration
+_sinks: list[logging.Handler] = []
+
+
+def syslog_handler(target: str) -> logging.Handler:
+    """SysLogHandler for a socket path or a udp:// / tcp:// address."""
+    if target.startswith("/"):
+        return logging.handlers.SysLogHandler(address=target)
+    parts = urlsplit(target)
+    if parts.scheme not in ("udp", "tcp") or not parts.hostname:
+        raise ValueError(f"NT_LOG_SYSLOG must be a socket path or udp://host:port or tcp://host:port, got {target}")
+    return logging.handlers.SysLogHandler(
+        address=(parts.hostname, parts.port or 514),
+        socktype=socket.SOCK_DGRAM if parts.scheme == "udp" else socket.SOCK_STREAM,
+    )
+
+
+def configure_structlog(
+    log_level: str = "info",
+    component_levels: Optional[dict[str, str]] = None,
+    sample: Optional[dict[str, int]] = None,
+    log_file: str = "",
+    log_file_max_mb: int = 100,
+    log_file_backups: int = 5,
+    syslog: str = "",
+):
+    """Configure structlog and the root logger's sinks (safe to call again)."""
+    default_level = LEVELS.get(log_level.lower(), logging.INFO)
+    levels = {component: LEVELS[level.lower()] for component, level in (component_levels or {}).items()}
+
+    # Configure root logger level
+    # Note: basicConfig is a no-op on subsequent calls, so explicitly set level
+    logging.basicConfig(format="%(message)s", level=default_level)
+    root = logging.getLogger()
+    root.setLevel(default_level)
+
+    # Producer loggers pass anything a component override may want; the filter below decides
+    for name in _PRODUCER_LOGGERS:
+        logging.getLogger(name).setLevel(min([default_level, *levels.values()]))
+
+    for handler in _sinks:
+        root.removeHandler(handler)
+        handler.close()
+    _sinks.clear()
+    if log_file:
+        _sinks.append(logging.handlers.RotatingFileHandler(
+            log_file, maxBytes=log_file_max_mb * 1024 * 1024, backupCount=log_file_backups
+        ))
+    if syslog:
+        _sinks.append(syslog_handler(syslog))
+    for handler in _sinks:
+        handler.setFormatter(logging.Formatter("%(message)s"))
+        root.addHandler(handler)
+
+    _level_filter.default_level = default_level
+    _level_filter.component_levels = levels
+    _sampler.set_rates(sample)
+
+    structlog.configure(
+        processors=[
+            _level_filter,  # T084: Filter by log level
+            _sampler,
+            structlog.stdlib.add_log_level,
+            structlog.stdlib.add_logger_name,
+            structlog.processors.TimeStamper(fmt="iso"),
+            structlog.processors.JSONRenderer(),
+        ],
+        wrapper_class=structlog.stdlib.BoundLogger,
+        logger_factory=structlog.stdlib.LoggerFactory(),
+        cache_logger_on_first_use=True,
+        context_class=dict,
+    )
@@ -13,7 +13,9 @@ import asyncio
 import signal
 import sys
 from typing import Any
+from urllib.parse import urlsplit
 
+import pandas as pd
 import structlog
 from nautilus_trader.adapters.binance.common.enums import BinanceAccountType
 from nautilus_trader.adapters.binance.config import BinanceDataClientConfig
@@ -22,58 +24,25 @@ from nautilus_trader.adapters.binance import BINANCE
 from nautilus_trader.config import CacheConfig, InstrumentProviderConfig, LoggingConfig, TradingNodeConfig
 from nautilus_trader.live.node import TradingNode
 from nautilus_trader.model.identifiers import TraderId, InstrumentId
-from nautilus_trader.model.data import TradeTick, QuoteTick, OrderBookDelta, OrderBookDeltas
+from nautilus_trader.model.data import InstrumentStatus, TradeTick, QuoteTick, OrderBookDelta, OrderBookDeltas
 from nautilus_trader.trading import Strategy
 from nautilus_trader.trading.config import StrategyConfig
 
+from src.cli import parse_args, run_command
 from src.config import ProducerConfig
 from src.redis_publisher import RedisPublisher
+from src.janitor import RedisJanitor
+from src.archive_export import ArchiveExporter, create_store
+from src.signal_bridge import BROKER_SCHEMES, SignalBridge, create_publisher
+from src.stream_retention import StreamTrimmer
+from src.pipeline_latency import HeartbeatMonitor
 from src.redis_client import RedisClient
 from src.analytics_strategy import MarketAnalyticsStrategy, AnalyticsStrategyConfig
 from src.metrics.prometheus import PrometheusMetrics
+from src.reporters.metrics_sink import create_metrics_sink
+from src.reporters.parquet_sink import ParquetHistoryWriter
 from src.instrument_loader import load_binance_spot_instruments
-
-# T084: Configure structured logging with log level support
-# Will be properly configured after loading config
-def configure_structlog(log_level: str = "info"):
-    """Configure structlog with proper log level filtering."""
-    import logging
-
-    # Map string log level to logging constant
-    level_map = {
-        "debug": logging.DEBUG,
-        "info": logging.INFO,
-        "warn": logging.WARNING,
-        "warning": logging.WARNING,
-        "error": logging.ERROR,
-    }
-
-    logging_level = level_map.get(log_level.lower(), logging.INFO)
-
-    # Configure root logger level
-    # Note: basicConfig is a no-op on subsequent calls, so explicitly set level
-    logging.basicConfig(
-        format="%(message)s",
-        level=logging_level,
-    )
-
-    # Explicitly set root logger level to handle reconfiguration
-    # This ensures NT_LOG_LEVEL is honored even after initial configuration
-    logging.getLogger().setLevel(logging_level)
-
-    structlog.configure(
-        processors=[
-            structlog.stdlib.filter_by_level,  # T084: Filter by log level
-            structlog.stdlib.add_log_level,
-            structlog.stdlib.add_logger_name,
-            structlog.processors.TimeStamper(fmt="iso"),
-            structlog.processors.JSONRenderer(),
-        ],
-        wrapper_class=structlog.stdlib.BoundLogger,
-        logger_factory=structlog.stdlib.LoggerFactory(),
-        cache_logger_on_first_use=True,
-        context_class=dict,
-    )
+from src.logging_setup import configure_structlog
 
 # Initial configuration (will be reconfigured with proper level after config load)
 configure_structlog("info")
@@ -85,6 +54,8 @@ class PublisherStrategyConfig(StrategyConfig, frozen=True):
     """Configuration for publisher strategy."""
     redis_publisher: Any = None  # Injected dependency
     symbols: list[str] = []
+    node_id: str = ""
+    heartbeat_interval_ms: int = 1000  # 0 = no heartbeat envelopes
 
 
 class PublisherStrategy(Strategy):
@@ -98,6 +69,9 @@ class PublisherStrategy(Strategy):
         super().__init__(config)
         self.redis_publisher: RedisPublisher = config.redis_publisher
         self.symbols = config.symbols
+        self.node_id = config.node_id
+        self.heartbeat_interval_ms = config.heartbeat_interval_ms
+        self._heartbeat_seq = 0
         # Note: self.log is already provided by Strategy parent class
 
     def on_start(self) -> None:
@@ -127,6 +101,9 @@ class PublisherStrategy(Strategy):
                 # Subscribe to order book deltas per constitution principle 2
                 self.subscribe_order_book_deltas(instrument_id, depth=20)
 
+                # Subscribe to venue trading status (halts, auctions, maintenance)
+                self.subscribe_instrument_status(instrument_id)
+
                 self.log.info(
                     f"Subscribed to instrument: {symbol_str} ({instrument_id})"
                 )
@@ -136,8 +113,26 @@ class PublisherStrategy(Strategy):
                     f"Subscription failed for {symbol_str}: {type(e).__name__} - {str(e)}"
                 )
 
+        # Heartbeat envelopes keep the stream moving in quiet markets (see src/pipeline_latency.py)
+        if self.heartbeat_interval_ms:
+            self.clock.set_timer(
+                name="heartbeat",
+                interval=pd.Timedelta(milliseconds=self.heartbeat_interval_ms),
+                callback=self.on_heartbeat,
+            )
+
         self.log.info("publisher_strategy_started")
 
+    def on_heartbeat(self, event) -> None:
+        """Publish a heartbeat envelope to Redis Streams."""
+        self._heartbeat_seq += 1
+        try:
+            self.redis_publisher.publish_heartbeat(
+                self.clock.timestamp_ns(), self._heartbeat_seq, self.node_id, self.heartbeat_interval_ms
+            )
+        except Exception as e:
+            self.log.error(f"heartbeat_publish_failed: seq={self._heartbeat_seq}, error={str(e)}")
+
     def on_trade_tick(self, tick: TradeTick) -> None:
         """Handle trade tick event. Publish to Redis Streams."""
         try:
@@ -177,16 +172,33 @@ class PublisherStrategy(Strategy):
                 f"order_book_deltas_publish_failed: symbol={deltas.instrument_id.symbol.value}, error={str(e)}"
             )
 
+    def on_instrument_status(self, data: InstrumentStatus) -> None:
+        """Handle venue trading status change. Publish to Redis Streams."""
+        try:
+            stream_id = self.redis_publisher.publish_instrument_status(data)
+            self.log.info(
+                f"instrument_status_published: symbol={data.instrument_id.symbol.value}, "
+                f"action={data.action.name}, stream_id={stream_id}"
+            )
+        except Exception as e:
+            self.log.error(
+                f"instrument_status_publish_failed: symbol={data.instrument_id.symbol.value}, error={str(e)}"
+            )
+
     def on_stop(self) -> None:
         """Called when strategy stops. Cleanup subscriptions."""
         self.log.info("publisher_strategy_stopping")
 
+        if self.heartbeat_interval_ms:
+            self.clock.cancel_timer("heartbeat")
+
         for symbol_str in self.symbols:
             try:
                 instrument_id = InstrumentId.from_str(f"{symbol_str}.BINANCE")
                 self.unsubscribe_trade_ticks(instrument_id)
                 self.unsubscribe_quote_ticks(instrument_id)
                 self.unsubscribe_order_book_deltas(instrument_id)
+                self.unsubscribe_instrument_status(instrument_id)
             except Exception as e:
                 self.log.error(
                     f"unsubscribe_failed: symbol={symbol_str}, error={str(e)}"
@@ -195,8 +207,12 @@ class PublisherStrategy(Strategy):
         self.log.info("publisher_strategy_stopped")
 
 
-def main():
+def main(argv: list[str] | None = None):
     """Main entry point for producer service."""
+    args = parse_args(argv)
+    if args.command not in (None, "serve"):
+        sys.exit(run_command(args))
+
     log.info("producer_starting")
 
     # T057: Setup signal handlers for graceful shutdown (SIGTERM, SIGINT)
@@ -213,19 +229,33 @@ def main():
     log.info("signal_handlers_registered: SIGTERM, SIGINT")
 
     # Load configuration
-    config = ProducerConfig.from_env()
     try:
+        config = ProducerConfig.load(args.config)
         config.validate()
     except ValueError as e:
         log.error("configuration_invalid", error=str(e))
         sys.exit(1)
 
     # T084: Reconfigure logging with proper level from config
-    configure_structlog(config.log_level)
+    configure_structlog(
+        config.log_level,
+        component_levels=config.nt_log_levels,
+        sample=config.nt_log_sample,
+        log_file=config.nt_log_file,
+        log_file_max_mb=config.nt_log_file_max_mb,
+        log_file_backups=config.nt_log_file_backups,
+        syslog=config.nt_log_syslog,
+    )
+
+    stream_endpoint = config.redis_endpoint("stream")
+    pubsub_endpoint = config.redis_endpoint("pubsub")
 
     log.info(
         "configuration_loaded",
+        config_file=args.config,
         redis_url=config.redis_url,
+        redis_stream_url=stream_endpoint.url,
+        redis_pubsub_url=pubsub_endpoint.url,
         stream_key=config.stream_key,
         symbols=config.symbols,
         log_level=config.log_level,
@@ -233,9 +263,13 @@ def main():
 
     # Initialize Redis publisher
     redis_publisher = RedisPublisher(
-        redis_url=config.redis_url,
+        redis_url=stream_endpoint.url,
         stream_key=config.stream_key,
-        redis_password=config.redis_password,
+        redis_password=stream_endpoint.password,
+        maxlen=config.nt_stream_maxlen,
+        ssl_options=stream_endpoint.ssl_options(),
+        dlq_stream=config.nt_dlq_stream,
+        dlq_maxlen=config.nt_dlq_maxlen,
     )
 
     # Configure NautilusTrader trading node
@@ -256,8 +290,8 @@ def main():
                 api_key=None,  # Public data only
                 api_secret=None,
                 account_type=BinanceAccountType.SPOT,
-                base_url_http=None,
-                base_url_ws=None,
+                base_url_http=config.binance_base_url_http or None,
+                base_url_ws=config.binance_base_url_ws or None,
                 us=False,
                 testnet=False,
                 update_instruments_interval_mins=60,
@@ -278,18 +312,29 @@ def main():
     strategy_config = PublisherStrategyConfig(
         redis_publisher=redis_publisher,
         symbols=config.symbols,
+        node_id=config.nt_node_id,
+        heartbeat_interval_ms=config.nt_heartbeat_interval_ms,
     )
     strategy = PublisherStrategy(config=strategy_config)
     node.trader.add_strategy(strategy)
 
     # Conditionally add analytics strategy if enabled
+    metrics = None
+    metrics_sink = None
+    history_writer = None
     if config.nt_enable_kv_reports:
         log.info(f"analytics_enabled: node={config.nt_node_id}, period_ms={config.nt_report_period_ms}, port={config.nt_metrics_port}")
 
         # Initialize Prometheus metrics with node_id for health endpoint
-        metrics = PrometheusMetrics(port=config.nt_metrics_port, node_id=config.nt_node_id)
+        metrics = PrometheusMetrics(
+            port=config.nt_metrics_port,
+            node_id=config.nt_node_id,
+            basic_auth=config.nt_metrics_basic_auth,
+            health_stall_ms=config.nt_health_stall_ms,
+        )
         metrics.set_node_heartbeat(config.nt_node_id, alive=True)
         metrics.set_symbols_assigned(config.nt_node_id, len(config.symbols))
+        redis_publisher.quarantine.metrics = metrics
 
         # T085: Validate metrics registration
         all_present, missing = metrics.validate_metrics()
@@ -304,19 +349,105 @@ def main():
         )
 
         # Initialize Redis client for KV storage
-        analytics_redis_client = RedisClient(
-            url=config.redis_url,
-            password=config.redis_password if config.redis_password else None
+        analytics_redis_client = RedisClient.for_endpoint(config.redis_endpoint("cache"))
+        redis_checks = [analytics_redis_client.ping]
+        if stream_endpoint.url != config.redis_url:
+            redis_checks.append(redis_publisher.ping)
+
+        # Report pub/sub on its own endpoint when REDIS_PUBSUB_URL is set
+        pubsub_redis_client = None
+        if pubsub_endpoint.url != config.redis_url:
+            pubsub_redis_client = RedisClient.for_endpoint(pubsub_endpoint)
+            redis_checks.append(pubsub_redis_client.ping)
+        # Healthy only when every Redis role is reachable
+        metrics.health_status.redis_check = lambda: all(check() for check in redis_checks)
+
+        # Optional second Redis (e.g. another region) receiving a copy of every report
+        secondary_redis_client = None
+        if config.nt_secondary_redis_url:
+            secondary_redis_client = RedisClient(
+                url=config.nt_secondary_redis_url,
+                password=config.nt_secondary_redis_password or None
+            ).get_client()
+
+        # Optional per-report metrics records for offline analysis
+        metrics_sink = create_metrics_sink(
+            config.nt_report_metrics_sink,
+            redis_client=analytics_redis_client.get_client(),
+            target=config.nt_report_metrics_target,
+            maxlen=config.nt_report_metrics_maxlen,
         )
 
+        # Optional hourly Parquet files of published reports and trades
+        if config.nt_parquet_dir:
+            history_writer = ParquetHistoryWriter(
+                config.nt_parquet_dir, flush_sec=config.nt_parquet_flush_sec, metrics=metrics
+            )
+            history_writer.start()
+
         # Add analytics strategy with US2 coordination parameters
         analytics_config = AnalyticsStrategyConfig(
             redis_client=analytics_redis_client.get_client(),
+            pubsub_redis_client=pubsub_redis_client.get_client() if pubsub_redis_client else None,
             symbols=config.symbols,
             node_id=config.nt_node_id,
             report_period_ms=config.nt_report_period_ms,
             slow_period_ms=config.nt_slow_period_ms,  # US3: Slow-cycle period
+            processing_profile=config.nt_processing_profile,
             metrics=metrics,
+            metrics_sink=metrics_sink,
+            history_writer=history_writer,
+            flow_window_sec=config.flow_window_sec,
+            flow_windows=config.nt_flow_windows,
+            publish_workers=config.nt_publish_workers,
+            publish_flush_ms=config.nt_publish_flush_ms,
+            publish_batch_max=config.nt_publish_batch_max,
+            secondary_redis_client=secondary_redis_client,
+            secondary_publish_workers=config.nt_secondary_publish_workers,
+            secondary_alarm_failures=config.nt_secondary_alarm_failures,
+            ingestion_degraded_ms=config.nt_ingestion_degraded_ms,
+            ingestion_down_ms=config.nt_ingestion_down_ms,
+            ingestion_min_dwell_ms=config.nt_ingestion_min_dwell_ms,
+            ingestion_overrides=config.nt_ingestion_overrides,
+            staleness_sweep_ms=config.nt_staleness_sweep_ms,
+            allow_anomaly_injection=config.nt_allow_anomaly_injection,
+            invariant_mode=config.nt_invariant_mode,
+            notional_tiers=config.nt_notional_tiers,
+            symbol_tiers=config.nt_symbol_tiers,
+            stable_quotes=config.nt_stable_quotes,
+            book_history_interval_ms=config.nt_book_history_interval_ms,
+            book_history_retention_sec=config.nt_book_history_retention_sec,
+            book_history_levels=config.nt_book_history_levels,
+            digest_ttl_sec=config.nt_digest_ttl_sec,
+            daily_ttl_sec=config.nt_daily_ttl_sec,
+            venue_report_interval_ms=config.nt_venue_report_interval_ms,
+            venue_spread_widen_factor=config.nt_venue_spread_widen_factor,
+            relative_strength_interval_sec=config.nt_relative_strength_interval_sec,
+            relative_strength_benchmark=config.nt_relative_strength_benchmark,
+            signals_maxlen=config.nt_signals_maxlen,
+            anomaly_budget_per_hour=config.nt_anomaly_budget_per_hour,
+            watch_refresh_ms=config.nt_watch_refresh_ms,
+            dlq_stream=config.nt_dlq_stream,
+            dlq_maxlen=config.nt_dlq_maxlen,
+            canary_modules=config.nt_canary_modules,
+            canary_ttl_sec=config.nt_canary_ttl_sec,
+            canary_compare_sec=config.nt_canary_compare_sec,
+            poc_trend_window_sec=config.nt_poc_trend_window_sec,
+            poc_trend_threshold_bps=config.nt_poc_trend_threshold_bps,
+            footprint_window_sec=config.nt_footprint_window_sec,
+            footprint_bucket_bps=config.nt_footprint_bucket_bps,
+            footprint_top_n=config.nt_footprint_top_n,
+            disabled_sections=config.nt_disabled_sections,
+            symbol_sections=config.nt_symbol_sections,
+            disabled_detectors=config.nt_disabled_detectors,
+            symbol_detectors=config.nt_symbol_detectors,
+            async_detectors=config.nt_async_detectors,
+            detector_budget_ms=config.nt_detector_budget_ms,
+            trade_tape_size=config.nt_trade_tape_size,
+            report_recent_trades=config.nt_report_recent_trades,
+            churn_window_sec=config.nt_churn_window_sec,
+            clock_skew_warn_ms=config.nt_clock_skew_warn_ms,
+            reorder_window_ms=config.nt_reorder_window_ms,
             # US2: Multi-instance coordination
             enable_coordination=config.nt_enable_multi_instance,
             heartbeat_interval_sec=1.0,
@@ -342,7 +473,7 @@ def main():
 
     # Load instruments from Binance public API (no auth required)
     log.info(f"loading_instruments_from_public_api: {config.symbols}")
-    instruments = load_binance_spot_instruments(config.symbols)
+    instruments = load_binance_spot_instruments(config.symbols, base_url=config.binance_base_url_http or None)
 
     if not instruments:
         log.error("failed_to_load_instruments_cannot_continue")
@@ -355,6 +486,69 @@ def main():
 
     log.info(f"cached_{len(instruments)}_instruments_successfully")
 
+    # Periodic stream retention (XTRIM by age or length)
+    stream_trimmer = None
+    if config.nt_stream_trim_interval_sec:
+        stream_trimmer = StreamTrimmer(
+            redis_client=redis_publisher.redis_client,
+            stream_key=config.stream_key,
+            maxlen=config.nt_stream_maxlen,
+            retention_sec=config.nt_stream_retention_sec,
+            interval_sec=config.nt_stream_trim_interval_sec,
+            metrics=metrics,
+        )
+        stream_trimmer.start()
+
+    # End-to-end pipeline latency from the publisher's heartbeats
+    heartbeat_monitor = None
+    if config.nt_enable_kv_reports and config.nt_heartbeat_interval_ms:
+        heartbeat_monitor = HeartbeatMonitor(
+            redis_client=redis_publisher.redis_client,
+            stream_key=config.stream_key,
+            metrics=metrics,
+        )
+        heartbeat_monitor.start()
+
+    # Periodic key retention and orphaned symbol cleanup in the report cache
+    janitor = None
+    janitor_redis_client = None
+    if config.nt_enable_kv_reports and config.nt_janitor_interval_sec:
+        janitor_redis_client = RedisClient.for_endpoint(config.redis_endpoint("cache"))
+        janitor = RedisJanitor.for_config(config, janitor_redis_client.get_client(), metrics=metrics)
+        janitor.start()
+
+    # Export of closed days to object storage
+    archive_exporter = None
+    archive_redis_client = None
+    if config.nt_enable_kv_reports and config.nt_archive_url:
+        archive_redis_client = RedisClient.for_endpoint(config.redis_endpoint("cache"))
+        store, prefix = create_store(config.nt_archive_url)
+        archive_exporter = ArchiveExporter(
+            redis_client=archive_redis_client.get_client(),
+            store=store,
+            prefix=prefix,
+            interval_sec=config.nt_archive_interval_sec,
+            metrics=metrics,
+        )
+        archive_exporter.start()
+
+    # Signal forwarding to an MQTT or AMQP broker
+    signal_bridge = None
+    signal_bridge_redis_client = None
+    if config.nt_enable_kv_reports and config.nt_signal_bridge_url:
+        signal_bridge_redis_client = RedisClient.for_endpoint(config.redis_endpoint("cache"))
+        signal_bridge = SignalBridge(
+            redis_client=signal_bridge_redis_client.get_client(),
+            publisher=create_publisher(
+                config.nt_signal_bridge_url, client_id=config.nt_node_id, exchange=config.nt_signal_bridge_exchange
+            ),
+            consumer=config.nt_node_id,
+            topic_template=config.nt_signal_bridge_topic,
+            broker=BROKER_SCHEMES[urlsplit(config.nt_signal_bridge_url).scheme],
+            metrics=metrics,
+        )
+        signal_bridge.start()
+
     try:
         # Run the trading node (blocking)
         log.info("starting_trading_node")
@@ -372,7 +566,26 @@ def main():
         # Shutdown gracefully
         log.info("producer_shutting_down")
         node.dispose()
+        if stream_trimmer:
+            stream_trimmer.stop()
+        if heartbeat_monitor:
+            heartbeat_monitor.stop()
+        if janitor:
+            janitor.stop()
+            janitor_redis_client.close()
+        if archive_exporter:
+            archive_exporter.stop()
+            archive_redis_client.close()
+        if signal_bridge:
+            signal_bridge.stop()
+            signal_bridge_redis_client.close()
         redis_publisher.close()
+        if metrics:
+            metrics.close()
+        if metrics_sink:
+            metrics_sink.close()
+        if history_writer:
+            history_writer.close()
         log.info("producer_stopped")
 
 
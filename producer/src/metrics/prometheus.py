@@ -1,30 +1,111 @@
 """Prometheus metrics for embedded analytics."""
 from prometheus_client import Counter, Gauge, Histogram, make_wsgi_app
 from wsgiref.simple_server import make_server, WSGIRequestHandler
+import base64
+import hmac
 import json
 import time
 import threading
+from datetime import datetime, timezone
+from typing import Callable, Optional
 import structlog
 
 logger = structlog.get_logger()
 
 
 class HealthStatus:
-    """Health status information for the node."""
+    """Health status information for the node.
 
-    def __init__(self, node_id: str):
+    The strategy reports each completed fast cycle (with per-symbol data
+    age and ingestion status) and each published report; probes evaluate
+    those against the clock, so a stalled strategy thread shows up even
+    though it can no longer report anything itself.
+    """
+
+    def __init__(self, node_id: str, stall_ms: int = 10000):
         self.node_id = node_id
         self.start_time = time.time()
         self.owned_symbols: list[str] = []
         self.configured_symbols: list[str] = []
         self.coordination_enabled: bool = False
         self.is_healthy: bool = True
+        self.stall_ms = stall_ms  # Fast cycle silence after which the node is not live
+        self.redis_check: Optional[Callable[[], bool]] = None  # Set once the Redis client exists
+        self._lock = threading.Lock()
+        self._last_cycle_at: Optional[float] = None
+        self._last_publish_at: Optional[float] = None
+        self._freshness: dict[str, dict] = {}
+
+    def record_cycle(self, freshness: dict[str, dict]) -> None:
+        """Record a completed fast cycle.
+
+        Args:
+            freshness: Symbol -> {"data_age_ms", "ingestion"} for owned symbols
+        """
+        with self._lock:
+            self._last_cycle_at = time.time()
+            self._freshness = freshness
+
+    def record_publish(self) -> None:
+        """Record a report published to Redis."""
+        with self._lock:
+            self._last_publish_at = time.time()
+
+    def _checks(self) -> dict:
+        now = time.time()
+        with self._lock:
+            last_cycle_at, last_publish_at = self._last_cycle_at, self._last_publish_at
+            freshness = dict(self._freshness)
+
+        # Before the first cycle the node gets stall_ms of startup grace
+        cycle_age_ms = int((now - (last_cycle_at or self.start_time)) * 1000)
+        statuses = [entry["ingestion"] for entry in freshness.values()]
+        redis_ok = self.redis_check() if self.redis_check else False
+
+        return {
+            "consumer": {
+                "ok": cycle_age_ms <= self.stall_ms,
+                "started": last_cycle_at is not None,
+                "last_cycle_age_ms": cycle_age_ms if last_cycle_at else None,
+            },
+            "redis": {"ok": redis_ok},
+            "symbols": {
+                # A node owning no symbols (coordination) is still ready
+                "ok": not statuses or any(status != "down" for status in statuses),
+                "ok_count": statuses.count("ok"),
+                "degraded_count": statuses.count("degraded"),
+                "down_count": statuses.count("down"),
+                "freshness": freshness,
+            },
+            "last_publish": {
+                "at": _iso(last_publish_at),
+                "age_ms": int((now - last_publish_at) * 1000) if last_publish_at else None,
+            },
+        }
+
+    def liveness(self) -> tuple[bool, dict]:
+        """Whether the node is alive (fast cycle running), with the health body."""
+        checks = self._checks()
+        live = self.is_healthy and checks["consumer"]["ok"]
+        return live, self.to_dict(status="healthy" if live else "unhealthy", checks=checks)
+
+    def readiness(self) -> tuple[bool, dict]:
+        """Whether the node should receive traffic: live, Redis up, data flowing."""
+        checks = self._checks()
+        ready = (
+            self.is_healthy
+            and checks["consumer"]["ok"]
+            and checks["consumer"]["started"]
+            and checks["redis"]["ok"]
+            and checks["symbols"]["ok"]
+        )
+        return ready, self.to_dict(status="ready" if ready else "not_ready", checks=checks)
 
-    def to_dict(self) -> dict:
+    def to_dict(self, status: Optional[str] = None, checks: Optional[dict] = None) -> dict:
         """Convert health status to dictionary."""
         uptime_sec = time.time() - self.start_time
-        return {
-            "status": "healthy" if self.is_healthy else "unhealthy",
+        body = {
+            "status": status or ("healthy" if self.is_healthy else "unhealthy"),
             "node_id": self.node_id,
             "uptime_seconds": round(uptime_sec, 2),
             "coordination": {
@@ -33,6 +114,15 @@ class HealthStatus:
                 "configured_symbols": self.configured_symbols,
             }
         }
+        if checks is not None:
+            body["checks"] = checks
+        return body
+
+
+def _iso(timestamp: Optional[float]) -> Optional[str]:
+    if timestamp is None:
+        return None
+    return datetime.fromtimestamp(timestamp, tz=timezone.utc).isoformat().replace('+00:00', 'Z')
 
 
 class HealthCheckHandler(WSGIRequestHandler):
@@ -43,19 +133,36 @@ class HealthCheckHandler(WSGIRequestHandler):
         pass
 
 
-def create_wsgi_app(health_status: HealthStatus):
-    """Create WSGI app that serves both /metrics and /health endpoints."""
+def _authorized(environ, basic_auth: str) -> bool:
+    """Check the request's Basic credentials against "user:password"."""
+    expected = "Basic " + base64.b64encode(basic_auth.encode('utf-8')).decode('ascii')
+    return hmac.compare_digest(environ.get('HTTP_AUTHORIZATION', ''), expected)
+
+
+def create_wsgi_app(health_status: HealthStatus, basic_auth: str = ""):
+    """Create WSGI app that serves /metrics, /health (liveness) and /ready (readiness).
+
+    When basic_auth ("user:password") is set, /metrics requires those
+    credentials; /health and /ready stay open for container healthchecks.
+    """
     metrics_app = make_wsgi_app()
 
     def app(environ, start_response):
         path = environ.get('PATH_INFO', '/')
 
-        if path == '/health':
-            # Serve health endpoint
-            status = '200 OK' if health_status.is_healthy else '503 Service Unavailable'
+        if path in ('/metrics', '/') and basic_auth and not _authorized(environ, basic_auth):
+            start_response('401 Unauthorized', [
+                ('Content-Type', 'text/plain'),
+                ('WWW-Authenticate', 'Basic realm="metrics"'),
+            ])
+            return [b'Unauthorized']
+
+        if path in ('/health', '/ready'):
+            ok, body = health_status.liveness() if path == '/health' else health_status.readiness()
+            status = '200 OK' if ok else '503 Service Unavailable'
             headers = [('Content-Type', 'application/json')]
             start_response(status, headers)
-            return [json.dumps(health_status.to_dict()).encode('utf-8')]
+            return [json.dumps(body).encode('utf-8')]
 
         elif path == '/metrics' or path == '/':
             # Serve Prometheus metrics
@@ -74,18 +181,21 @@ def create_wsgi_app(health_status: HealthStatus):
 class PrometheusMetrics:
     """Prometheus metrics for NautilusTrader embedded analytics."""
 
-    def __init__(self, port: int = 9101, node_id: str = ""):
+    def __init__(self, port: int = 9101, node_id: str = "", basic_auth: str = "", health_stall_ms: int = 10000):
         """Initialize Prometheus metrics and start HTTP server.
 
         Args:
             port: Port for metrics HTTP server
             node_id: Node identifier for health status
+            basic_auth: Optional "user:password" required for /metrics
+            health_stall_ms: Fast cycle silence after which /health reports unhealthy
         """
         self.port = port
         self.node_id = node_id
+        self._httpd = None
 
         # T086: Initialize health status
-        self.health_status = HealthStatus(node_id=node_id)
+        self.health_status = HealthStatus(node_id=node_id, stall_ms=health_stall_ms)
 
         # Node health metrics
         self.node_heartbeat = Gauge(
@@ -122,6 +232,244 @@ class PrometheusMetrics:
             buckets=[10, 50, 100, 250, 500, 750, 1000, 1500, 2000, 5000]
         )
 
+        self.clock_skew = Gauge(
+            'nt_clock_skew_ms',
+            'Local clock minus venue clock (rolling min of event-to-receipt delay, includes best-case latency)',
+            ['symbol']
+        )
+
+        # Overload metrics
+        self.publish_queue_depth = Gauge(
+            'nt_publish_queue_depth',
+            'Symbols with a report waiting to be published'
+        )
+
+        self.publish_batch_size = Histogram(
+            'nt_publish_batch_size',
+            'Reports published per Redis pipeline',
+            buckets=[1, 2, 5, 10, 20, 50, 100]
+        )
+
+        self.reports_dropped = Counter(
+            'nt_reports_dropped_total',
+            'Reports dropped before publishing',
+            ['symbol', 'reason']
+        )
+
+        # Secondary (cross-region) report mirror
+        self.secondary_publish_lag = Histogram(
+            'nt_secondary_publish_lag_ms',
+            'Report updatedAt to its write on the secondary Redis, in milliseconds',
+            ['symbol'],
+            buckets=[10, 50, 100, 250, 500, 1000, 2000, 5000, 10000]
+        )
+
+        self.secondary_publish_failures = Counter(
+            'nt_secondary_publish_failures_total',
+            'Reports that failed to publish to the secondary Redis',
+            ['symbol']
+        )
+
+        self.secondary_publish_healthy = Gauge(
+            'nt_secondary_publish_healthy',
+            'Secondary Redis mirror health (0 after NT_SECONDARY_ALARM_FAILURES consecutive failures)'
+        )
+
+        self.events_dropped = Counter(
+            'nt_events_dropped_total',
+            'Market data events dropped under load or arriving out of order',
+            ['symbol', 'reason']
+        )
+        self.events_reordered = Counter(
+            'nt_events_reordered_total',
+            'Events arriving late within the reorder window (trades applied in event-time order)',
+            ['symbol', 'kind']
+        )
+        self.events_sanitized = Counter(
+            'nt_events_sanitized_total',
+            'Events or book levels rejected by sanitation and quarantined to the DLQ (src/sanitize.py)',
+            ['source', 'reason']
+        )
+
+        # Ingestion status metrics
+        self.ingestion_transitions = Counter(
+            'nt_ingestion_status_transitions_total',
+            'Ingestion status transitions',
+            ['symbol', 'from_status', 'to_status']
+        )
+        self.market_status = Gauge(
+            'nt_market_status',
+            'Venue trading status per symbol (1 for the current state: open, halted, auction, maintenance, closed)',
+            ['symbol', 'state']
+        )
+        self.stale_reports_republished = Counter(
+            'nt_stale_reports_republished_total',
+            'Cached reports republished by the staleness sweep',
+            ['symbol', 'status']
+        )
+        self.invariant_violations = Counter(
+            'nt_invariant_violations_total',
+            'Report invariant violations detected before publish',
+            ['symbol', 'invariant']
+        )
+        self.report_non_finite = Counter(
+            'nt_report_non_finite_total',
+            'NaN or infinite report values replaced with null before publish',
+            ['symbol', 'field']
+        )
+        self.book_history_snapshots = Counter(
+            'nt_book_history_snapshots_total',
+            'Order book snapshots written to book_history:{symbol}',
+            ['symbol']
+        )
+        self.digests_published = Counter(
+            'nt_digests_published_total',
+            'Per-minute digests written to digest:{symbol}:{minute}',
+            ['symbol']
+        )
+        self.daily_rollups = Counter(
+            'nt_daily_rollups_total',
+            'Digests rolled up into daily:{symbol}:{date} statistics',
+            ['symbol']
+        )
+        self.venue_stress_index = Gauge(
+            'nt_venue_stress_index',
+            'Venue-wide market stress index (0-100) published to venue_report:{venue}',
+            ['venue']
+        )
+        self.signals_published = Counter(
+            'nt_signals_total',
+            'Signal events appended to signals:{venue} by signal type',
+            ['symbol', 'signal']
+        )
+        self.anomalies_suppressed = Counter(
+            'nt_anomalies_suppressed_total',
+            'anomaly_started signals withheld by the per-hour anomaly budget (NT_ANOMALY_BUDGET_PER_HOUR)',
+            ['symbol', 'type']
+        )
+        self.thresholds_crossed = Counter(
+            'nt_watch_thresholds_crossed_total',
+            'Threshold rules of watch registrations that fired, by rule type',
+            ['symbol', 'rule']
+        )
+        self.watch_registrations = Gauge(
+            'nt_watch_registrations',
+            'Live watch registrations in watch:registrations'
+        )
+        self.signal_bridge_messages = Counter(
+            'nt_signal_bridge_messages_total',
+            'Signals forwarded to the MQTT/AMQP broker, by result (forwarded, failed)',
+            ['broker', 'result']
+        )
+        self.canary_reports = Counter(
+            'nt_canary_reports_total',
+            'Canary reports written to report_canary:{symbol}',
+            ['symbol']
+        )
+        self.canary_mismatch_pct = Gauge(
+            'nt_canary_mismatch_pct',
+            'Share of canary values differing from stable ones in the last comparison window',
+            ['module', 'field']
+        )
+        self.canary_mean_abs_diff = Gauge(
+            'nt_canary_mean_abs_diff',
+            'Mean absolute canary minus stable difference in the last comparison window',
+            ['module', 'field']
+        )
+        self.detector_over_budget = Counter(
+            'nt_detector_over_budget_total',
+            'Anomaly detector runs that alone exceeded NT_DETECTOR_BUDGET_MS',
+            ['symbol', 'detector']
+        )
+        self.detectors_skipped = Counter(
+            'nt_detectors_skipped_total',
+            'Anomaly detector runs skipped (budget spent or deferred worker behind)',
+            ['symbol', 'detector', 'reason']
+        )
+        self.report_section_enabled = Gauge(
+            'nt_report_section_enabled',
+            'Whether an optional report section is published for the symbol (feature flags, 1=yes)',
+            ['symbol', 'section']
+        )
+        self.anomaly_injection_active = Gauge(
+            'nt_anomaly_injection_active',
+            'Whether a synthetic anomaly injection is applied to the symbol (1=yes)',
+            ['symbol']
+        )
+
+        # Stream retention metrics
+        self.stream_trimmed = Counter(
+            'nt_stream_trimmed_total',
+            'Stream entries removed by XTRIM',
+            ['stream']
+        )
+
+        self.stream_length = Gauge(
+            'nt_stream_length',
+            'Stream length after the last trim',
+            ['stream']
+        )
+
+        self.stream_last_trim = Gauge(
+            'nt_stream_last_trim_timestamp_seconds',
+            'Unix time of the last successful trim',
+            ['stream']
+        )
+
+        # End-to-end pipeline latency from heartbeat envelopes (see src/pipeline_latency.py)
+        self.pipeline_latency = Gauge(
+            'nt_pipeline_latency_ms',
+            'Heartbeat latency from emission to being read back from the stream, by quantile (p50, p95)',
+            ['stream', 'quantile']
+        )
+
+        self.pipeline_heartbeats = Counter(
+            'nt_pipeline_heartbeats_total',
+            'Heartbeat envelopes read back from the stream',
+            ['stream']
+        )
+
+        # Parquet history metrics
+        self.parquet_rows = Counter(
+            'nt_parquet_rows_total',
+            'Report and trade rows written to Parquet files, by result (written, failed)',
+            ['kind', 'result']
+        )
+
+        # Object storage export metrics
+        self.archive_days = Counter(
+            'nt_archive_days_total',
+            'Symbol days exported to object storage, by result (exported, failed)',
+            ['result']
+        )
+        self.archive_last_export = Gauge(
+            'nt_archive_last_export_timestamp_seconds',
+            'Unix time of the last export run without failures'
+        )
+
+        # Redis janitor metrics
+        self.janitor_keys_deleted = Counter(
+            'nt_janitor_keys_deleted_total',
+            'Keys of orphaned symbols deleted by the janitor',
+            ['family']
+        )
+
+        self.janitor_expiry_set = Counter(
+            'nt_janitor_expiry_set_total',
+            'Keys given their retention TTL by the janitor',
+            ['family']
+        )
+
+        self.janitor_reclaimed_bytes = Counter(
+            'nt_janitor_reclaimed_bytes_total',
+            'Memory (MEMORY USAGE) of keys deleted by the janitor'
+        )
+
+        self.janitor_last_sweep = Gauge(
+            'nt_janitor_last_sweep_timestamp_seconds',
+            'Unix time of the last successful janitor sweep'
+        )
+
         # Coordination metrics
         self.lease_conflicts = Counter(
             'nt_lease_conflicts_total',
@@ -141,20 +489,33 @@ class PrometheusMetrics:
 
         # T086: Start HTTP server for /metrics and /health endpoints
         try:
-            wsgi_app = create_wsgi_app(self.health_status)
-            httpd = make_server('', port, wsgi_app, handler_class=HealthCheckHandler)
+            wsgi_app = create_wsgi_app(self.health_status, basic_auth)
+            self._httpd = make_server('', port, wsgi_app, handler_class=HealthCheckHandler)
 
             # Run server in background thread
-            server_thread = threading.Thread(target=httpd.serve_forever, daemon=True)
+            server_thread = threading.Thread(target=self._httpd.serve_forever, daemon=True)
             server_thread.start()
 
-            logger.info("http_server_started", port=port, endpoints=["/metrics", "/health"])
+            logger.info(
+                "http_server_started",
+                port=port,
+                endpoints=["/metrics", "/health", "/ready"],
+                basic_auth=bool(basic_auth),
+            )
         except OSError as e:
             if "Address already in use" in str(e):
                 logger.warning("http_port_already_in_use", port=port)
             else:
                 raise
 
+    def close(self) -> None:
+        """Stop the /metrics, /health and /ready HTTP server."""
+        if self._httpd:
+            self._httpd.shutdown()
+            self._httpd.server_close()
+            self._httpd = None
+            logger.info("http_server_stopped", port=self.port)
+
     def record_calculation(self, metric_name: str, cycle: str, duration_ms: float) -> None:
         """Record calculation latency.
 
@@ -246,9 +607,52 @@ class PrometheusMetrics:
             'nt_calc_latency_ms': 'calc_latency',
             'nt_report_publish_total': 'report_publish_rate',
             'nt_data_age_ms': 'data_age',
+            'nt_clock_skew_ms': 'clock_skew',
+            'nt_events_reordered_total': 'events_reordered',
             'nt_lease_conflicts_total': 'lease_conflicts',
             'nt_hrw_rebalances_total': 'hrw_rebalances',
             'nt_ws_resubscribe_total': 'ws_resubscribe',
+            'nt_publish_queue_depth': 'publish_queue_depth',
+            'nt_publish_batch_size': 'publish_batch_size',
+            'nt_reports_dropped_total': 'reports_dropped',
+            'nt_events_dropped_total': 'events_dropped',
+            'nt_events_sanitized_total': 'events_sanitized',
+            'nt_secondary_publish_lag_ms': 'secondary_publish_lag',
+            'nt_secondary_publish_failures_total': 'secondary_publish_failures',
+            'nt_secondary_publish_healthy': 'secondary_publish_healthy',
+            'nt_ingestion_status_transitions_total': 'ingestion_transitions',
+            'nt_market_status': 'market_status',
+            'nt_stale_reports_republished_total': 'stale_reports_republished',
+            'nt_book_history_snapshots_total': 'book_history_snapshots',
+            'nt_digests_published_total': 'digests_published',
+            'nt_daily_rollups_total': 'daily_rollups',
+            'nt_venue_stress_index': 'venue_stress_index',
+            'nt_signals_total': 'signals_published',
+            'nt_anomalies_suppressed_total': 'anomalies_suppressed',
+            'nt_watch_thresholds_crossed_total': 'thresholds_crossed',
+            'nt_watch_registrations': 'watch_registrations',
+            'nt_signal_bridge_messages_total': 'signal_bridge_messages',
+            'nt_canary_reports_total': 'canary_reports',
+            'nt_canary_mismatch_pct': 'canary_mismatch_pct',
+            'nt_canary_mean_abs_diff': 'canary_mean_abs_diff',
+            'nt_detector_over_budget_total': 'detector_over_budget',
+            'nt_detectors_skipped_total': 'detectors_skipped',
+            'nt_anomaly_injection_active': 'anomaly_injection_active',
+            'nt_report_section_enabled': 'report_section_enabled',
+            'nt_invariant_violations_total': 'invariant_violations',
+            'nt_report_non_finite_total': 'report_non_finite',
+            'nt_stream_trimmed_total': 'stream_trimmed',
+            'nt_stream_length': 'stream_length',
+            'nt_stream_last_trim_timestamp_seconds': 'stream_last_trim',
+            'nt_pipeline_latency_ms': 'pipeline_latency',
+            'nt_pipeline_heartbeats_total': 'pipeline_heartbeats',
+            'nt_parquet_rows_total': 'parquet_rows',
+            'nt_archive_days_total': 'archive_days',
+            'nt_archive_last_export_timestamp_seconds': 'archive_last_export',
+            'nt_janitor_keys_deleted_total': 'janitor_keys_deleted',
+            'nt_janitor_expiry_set_total': 'janitor_expiry_set',
+            'nt_janitor_reclaimed_bytes_total': 'janitor_reclaimed_bytes',
+            'nt_janitor_last_sweep_timestamp_seconds': 'janitor_last_sweep',
         }
 
         missing = []
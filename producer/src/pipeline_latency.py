@@ -0,0 +1,155 @@
+"""End-to-end pipeline latency from heartbeat envelopes.
+
+Report freshness (data_age_ms) is measured from market events, so a quiet
+market looks stale and a busy one hides a slow pipeline. With
+NT_HEARTBEAT_INTERVAL_MS set (default 1000) the publisher appends a
+heartbeat envelope to the event stream on every interval, market activity
+or not:
+
+    {"symbol": "*", "venue": "BINANCE", "type": "heartbeat",
+     "ts_event": "2026-10-16T12:00:00.000125Z",
+     "payload": {"seq": 812, "node_id": "producer-1", "interval_ms": 1000}}
+
+A HeartbeatMonitor tails the stream next to the aggregator and takes the
+time from ts_event to reading the heartbeat back as the pipeline latency
+(serialization, XADD, Redis and the read, but no market activity). The
+p50 and p95 over the last WINDOW_SEC are exported as
+nt_pipeline_latency_ms{stream,quantile}; nt_pipeline_heartbeats_total
+stops increasing when heartbeats stop arriving. Latency includes clock
+skew between the publisher and the monitor when they run on different
+hosts.
+
+The monitor reads every stream entry and skips market events without
+decoding them, so it adds one read of the stream to Redis.
+"""
+import json
+import threading
+import time
+from collections import deque
+from datetime import datetime
+from typing import Optional
+
+import redis
+import structlog
+
+from src.event_bus import StreamLog
+
+log = structlog.get_logger()
+
+HEARTBEAT_TYPE = "heartbeat"
+
+# Rolling window the quantiles are computed over
+WINDOW_SEC = 60.0
+
+# Longest a blocking read waits before checking for stop
+READ_BLOCK_MS = 1000
+
+# Entries read per round trip
+READ_COUNT = 1000
+
+QUANTILES = {"p50": 0.50, "p95": 0.95}
+
+# Cheap pre-filter: market events don't contain this, heartbeats always do
+_HEARTBEAT_MARKER = f'"type": "{HEARTBEAT_TYPE}"'.encode()
+
+
+def _quantile(ordered: list[float], q: float) -> float:
+    """Nearest-rank quantile of an ascending list."""
+    return ordered[min(len(ordered) - 1, int(q * len(ordered)))]
+
+
+def heartbeat_latency_ms(data: bytes | str, now: float) -> Optional[float]:
+    """Latency of a heartbeat envelope read at now (Unix seconds), None for other events."""
+    raw = data.encode() if isinstance(data, str) else data
+    if _HEARTBEAT_MARKER not in raw:
+        return None
+    envelope = json.loads(raw)
+    if envelope.get("type") != HEARTBEAT_TYPE:
+        return None
+    sent = datetime.fromisoformat(envelope["ts_event"].replace("Z", "+00:00"))
+    return max(0.0, (now - sent.timestamp()) * 1000)
+
+
+class HeartbeatMonitor:
+    """Tails the event stream for heartbeats and exports pipeline latency quantiles."""
+
+    def __init__(self, redis_client: StreamLog, stream_key: str, metrics=None, window_sec: float = WINDOW_SEC):
+        """Initialize monitor.
+
+        Args:
+            redis_client: Client of the Redis holding the stream (REDIS_STREAM_URL)
+            stream_key: Event stream the publisher appends heartbeats to (e.g., nt:binance)
+            metrics: Optional PrometheusMetrics for the latency gauges
+            window_sec: Seconds of heartbeats the quantiles cover
+        """
+        self.redis_client = redis_client
+        self.stream_key = stream_key
+        self.metrics = metrics
+        self.window_sec = window_sec
+
+        self._samples: deque[tuple[float, float]] = deque()
+        self._lock = threading.Lock()
+        self._stop = threading.Event()
+        self._thread: Optional[threading.Thread] = None
+        self.log = log.bind(component="heartbeat_monitor", stream_key=stream_key)
+
+    def record(self, latency_ms: float, now: float) -> None:
+        """Add a heartbeat latency and refresh the gauges."""
+        with self._lock:
+            self._samples.append((now, latency_ms))
+            while self._samples and self._samples[0][0] < now - self.window_sec:
+                self._samples.popleft()
+        if self.metrics:
+            self.metrics.pipeline_heartbeats.labels(stream=self.stream_key).inc()
+            for name, value in self.quantiles().items():
+                self.metrics.pipeline_latency.labels(stream=self.stream_key, quantile=name).set(value)
+
+    def quantiles(self) -> dict[str, float]:
+        """p50/p95 latency over the window (empty before the first heartbeat)."""
+        with self._lock:
+            ordered = sorted(latency for _, latency in self._samples)
+        if not ordered:
+            return {}
+        return {name: round(_quantile(ordered, q), 2) for name, q in QUANTILES.items()}
+
+    def poll_once(self, last_id: str) -> str:
+        """Read the entries after last_id, record heartbeat latencies and return the new last id."""
+        response = self.redis_client.xread({self.stream_key: last_id}, count=READ_COUNT, block=READ_BLOCK_MS)
+        for _, entries in response or []:
+            now = time.time()
+            for entry_id, fields in entries:
+                last_id = entry_id.decode() if isinstance(entry_id, bytes) else entry_id
+                data = fields.get(b"data", fields.get("data"))
+                if data is None:
+                    continue
+                try:
+                    latency_ms = heartbeat_latency_ms(data, now)
+                except (ValueError, KeyError) as e:
+                    self.log.warning("heartbeat_unreadable", stream_id=last_id, error=str(e))
+                    continue
+                if latency_ms is not None:
+                    self.record(latency_ms, now)
+        return last_id
+
+    def _run(self) -> None:
+        # Only heartbeats sent from now on; older ones would read as latency
+        last_id = "$"
+        while not self._stop.is_set():
+            try:
+                last_id = self.poll_once(last_id)
+            except redis.RedisError as e:
+                self.log.warning("heartbeat_read_failed", error=str(e))
+                self._stop.wait(READ_BLOCK_MS / 1000)
+
+    def start(self) -> None:
+        """Start tailing the stream in a background thread."""
+        self._thread = threading.Thread(target=self._run, name="heartbeat-monitor", daemon=True)
+        self._thread.start()
+        self.log.info("heartbeat_monitor_started", window_sec=self.window_sec)
+
+    def stop(self) -> None:
+        """Stop the background thread."""
+        self._stop.set()
+        if self._thread:
+            self._thread.join(timeout=5)
+            self._thread = None
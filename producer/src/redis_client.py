@@ -1,13 +1,49 @@
 """Redis client with connection pooling for analytics."""
 import socket
+from dataclasses import dataclass
 from typing import Optional
 from redis import Redis, ConnectionPool
 from redis.retry import Retry
 from redis.backoff import ExponentialBackoff
 import structlog
 
+from src.event_bus import EventBus, is_memory_url, open_bus
+
 logger = structlog.get_logger()
 
+# Redis roles that can be separate endpoints: market event stream, report cache, report pub/sub
+REDIS_ROLES = ("stream", "cache", "pubsub")
+
+
+@dataclass(frozen=True)
+class RedisEndpoint:
+    """Connection settings for one Redis role.
+
+    TLS is selected by a rediss:// URL; the CA bundle and client
+    certificate/key (mutual TLS) are optional and apply only to rediss://.
+    """
+    url: str
+    password: str = ""
+    tls_ca_cert: str = ""
+    tls_cert: str = ""
+    tls_key: str = ""
+
+    @property
+    def tls(self) -> bool:
+        """Whether the endpoint is reached over TLS."""
+        return self.url.startswith("rediss://")
+
+    def ssl_options(self) -> dict:
+        """redis-py ssl_* connection arguments (empty without TLS files)."""
+        options = {}
+        if self.tls_ca_cert:
+            options["ssl_ca_certs"] = self.tls_ca_cert
+        if self.tls_cert:
+            options["ssl_certfile"] = self.tls_cert
+        if self.tls_key:
+            options["ssl_keyfile"] = self.tls_key
+        return options
+
 
 class RedisClient:
     """Redis client with connection pooling and retry logic."""
@@ -20,21 +56,30 @@ class RedisClient:
         socket_timeout: int = 5,
         socket_connect_timeout: int = 2,
         retry_on_timeout: bool = True,
-        health_check_interval: int = 30
+        health_check_interval: int = 30,
+        ssl_options: Optional[dict] = None
     ):
         """Initialize Redis client with connection pool.
 
         Args:
-            url: Redis URL (e.g., redis://localhost:6379)
+            url: Redis URL (e.g., redis://localhost:6379), or memory://name for the in-memory bus
             password: Redis password (optional)
             max_connections: Maximum connections in pool
             socket_timeout: Socket operation timeout in seconds
             socket_connect_timeout: Socket connection timeout in seconds
             retry_on_timeout: Whether to retry on timeout
             health_check_interval: Health check interval in seconds
+            ssl_options: redis-py ssl_* arguments for a rediss:// URL (see RedisEndpoint)
         """
         self.url = url
 
+        # In-memory bus: no pool, shared with every component using the same URL
+        if is_memory_url(url):
+            self.pool = None
+            self.client = open_bus(url)
+            logger.info("redis_client_initialized", url=url, backend="memory")
+            return
+
         # Create connection pool
         self.pool = ConnectionPool.from_url(
             url,
@@ -51,7 +96,8 @@ class RedisClient:
             retry=Retry(ExponentialBackoff(), retries=3),
             retry_on_timeout=retry_on_timeout,
             health_check_interval=health_check_interval,
-            decode_responses=True  # Auto-decode bytes to strings
+            decode_responses=True,  # Auto-decode bytes to strings
+            **(ssl_options or {})
         )
 
         # Create client using pool
@@ -60,15 +106,26 @@ class RedisClient:
         logger.info(
             "redis_client_initialized",
             url=url,
+            tls=url.startswith("rediss://"),
             max_connections=max_connections,
             socket_timeout=socket_timeout
         )
 
-    def get_client(self) -> Redis:
+    @classmethod
+    def for_endpoint(cls, endpoint: RedisEndpoint, **kwargs) -> "RedisClient":
+        """Client for a role's endpoint (URL, password and TLS settings)."""
+        return cls(
+            url=endpoint.url,
+            password=endpoint.password or None,
+            ssl_options=endpoint.ssl_options(),
+            **kwargs
+        )
+
+    def get_client(self) -> EventBus:
         """Get Redis client instance.
 
         Returns:
-            Redis client (uses connection pool automatically)
+            Redis client (uses connection pool automatically), or the in-memory bus
         """
         return self.client
 
@@ -86,6 +143,8 @@ class RedisClient:
 
     def close(self) -> None:
         """Close connection pool and disconnect all connections."""
+        if self.pool is None:
+            return
         try:
             self.pool.disconnect()
             logger.info("redis_client_closed")
@@ -96,8 +155,10 @@ class RedisClient:
         """Get connection pool statistics.
 
         Returns:
-            Dictionary with pool stats
+            Dictionary with pool stats (empty for the in-memory bus)
         """
+        if self.pool is None:
+            return {}
         return {
             "max_connections": self.pool.max_connections,
             "created_connections": len(self.pool._created_connections) if hasattr(self.pool, '_created_connections') else 0,
@@ -105,4 +166,6 @@ class RedisClient:
         }
 
     def __repr__(self) -> str:
+        if self.pool is None:
+            return f"RedisClient(url={self.url})"
         return f"RedisClient(url={self.url}, max_connections={self.pool.max_connections})"
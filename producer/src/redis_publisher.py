@@ -6,15 +6,18 @@ Implements constitution principle 2 (Message Bus Contract) with snake_case field
 
 import json
 import time
-from typing import Any
+from typing import Any, Optional
 from dataclasses import asdict, dataclass
 
 import redis
 import structlog
 from nautilus_trader.core.data import Data
-from nautilus_trader.model.data import TradeTick, QuoteTick, OrderBookDelta, OrderBookDeltas
+from nautilus_trader.model.data import InstrumentStatus, TradeTick, QuoteTick, OrderBookDelta, OrderBookDeltas
 from nautilus_trader.model.identifiers import InstrumentId
 
+from src.event_bus import open_bus
+from src.sanitize import DEFAULT_DLQ_MAXLEN, DEFAULT_DLQ_STREAM, Quarantine, sanitize_envelope
+
 log = structlog.get_logger()
 
 
@@ -57,35 +60,56 @@ class RedisPublisher:
     - XADD ensures at-least-once delivery per constitution principle 1
     """
 
-    def __init__(self, redis_url: str, stream_key: str, redis_password: str = ""):
+    def __init__(
+        self,
+        redis_url: str,
+        stream_key: str,
+        redis_password: str = "",
+        maxlen: int = 100000,
+        ssl_options: Optional[dict] = None,
+        dlq_stream: str = DEFAULT_DLQ_STREAM,
+        dlq_maxlen: int = DEFAULT_DLQ_MAXLEN,
+    ):
         """Initialize Redis publisher.
 
         Args:
             redis_url: Redis connection URL (e.g., redis://localhost:6379)
             stream_key: Redis Stream key (e.g., nt:binance) per constitution principle 5
             redis_password: Redis password (optional)
+            maxlen: Approximate stream length cap applied on XADD (0 = no inline trim)
+            ssl_options: redis-py ssl_* arguments for a rediss:// URL (see RedisEndpoint)
+            dlq_stream: Stream receiving events that fail sanitation (see src/sanitize.py)
+            dlq_maxlen: Approximate DLQ length cap
         """
         self.redis_url = redis_url
         self.stream_key = stream_key
+        self.maxlen = maxlen or None
 
-        # Parse Redis URL and create connection
-        self.redis_client = redis.from_url(
+        # Parse Redis URL and create connection (memory:// selects the in-memory bus)
+        self.redis_client = open_bus(
             redis_url,
             password=redis_password if redis_password else None,
             decode_responses=False,  # We'll handle JSON encoding
+            **(ssl_options or {}),
         )
 
+        # Metrics are attached by main once created (quarantine.metrics)
+        self.quarantine = Quarantine(self.redis_client, "publisher", stream=dlq_stream, maxlen=dlq_maxlen)
+
         self.log = log.bind(component="redis_publisher", stream_key=stream_key)
         self.log.info("redis_publisher_initialized", redis_url=redis_url)
 
-    def publish_event(self, envelope: MarketEventEnvelope) -> str:
+    def publish_event(self, envelope: MarketEventEnvelope) -> Optional[str]:
         """Publish a market event envelope to Redis Streams.
 
+        Envelopes are sanitized first: bad book levels are dropped, and an event
+        with an absurd price or size is quarantined to the DLQ instead.
+
         Args:
             envelope: Market event envelope to publish
 
         Returns:
-            str: Redis Stream message ID (e.g., "1234567890123-0")
+            str: Redis Stream message ID (e.g., "1234567890123-0"), None if quarantined
 
         Per constitution principle 2, uses XADD to append to stream with JSON payload.
         """
@@ -93,7 +117,12 @@ class RedisPublisher:
 
         # Convert envelope to dict and serialize to JSON
         # Using snake_case per constitution principle 2 (Message Bus Contract)
-        envelope_dict = asdict(envelope)
+        original = asdict(envelope)
+        envelope_dict, problems = sanitize_envelope(original)
+        if problems:
+            self.quarantine.put(original, problems, symbol=envelope.symbol, event_type=envelope.type)
+        if envelope_dict is None or (envelope.type == "order_book_deltas" and not envelope_dict["payload"]["deltas"]):
+            return None
         json_payload = json.dumps(envelope_dict).encode('utf-8')
 
         # XADD: Append to stream
@@ -102,7 +131,7 @@ class RedisPublisher:
             stream_id = self.redis_client.xadd(
                 name=self.stream_key,
                 fields={"data": json_payload},
-                maxlen=100000,  # Trim stream to last 100k messages (prevent unbounded growth)
+                maxlen=self.maxlen,  # Trim stream to last N messages (prevent unbounded growth)
                 approximate=True,  # Approximate trimming for performance
             )
 
@@ -127,42 +156,75 @@ class RedisPublisher:
             )
             raise
 
-    def publish_trade_tick(self, tick: TradeTick) -> str:
+    def publish_trade_tick(self, tick: TradeTick) -> Optional[str]:
         """Publish a trade tick to Redis Streams.
 
         Args:
             tick: NautilusTrader TradeTick object
 
         Returns:
-            str: Redis Stream message ID
+            str: Redis Stream message ID, None if quarantined
         """
         envelope = self._trade_tick_to_envelope(tick)
         return self.publish_event(envelope)
 
-    def publish_quote_tick(self, tick: QuoteTick) -> str:
+    def publish_quote_tick(self, tick: QuoteTick) -> Optional[str]:
         """Publish a quote tick (best bid/ask) to Redis Streams.
 
         Args:
             tick: NautilusTrader QuoteTick object
 
         Returns:
-            str: Redis Stream message ID
+            str: Redis Stream message ID, None if quarantined
         """
         envelope = self._quote_tick_to_envelope(tick)
         return self.publish_event(envelope)
 
-    def publish_order_book_deltas(self, deltas: OrderBookDeltas) -> str:
+    def publish_order_book_deltas(self, deltas: OrderBookDeltas) -> Optional[str]:
         """Publish order book deltas to Redis Streams.
 
         Args:
             deltas: NautilusTrader OrderBookDeltas object
 
         Returns:
-            str: Redis Stream message ID
+            str: Redis Stream message ID, None if quarantined
         """
         envelope = self._order_book_deltas_to_envelope(deltas)
         return self.publish_event(envelope)
 
+    def publish_instrument_status(self, status: InstrumentStatus) -> Optional[str]:
+        """Publish a venue trading status change (halt, auction, maintenance) to Redis Streams.
+
+        Args:
+            status: NautilusTrader InstrumentStatus object
+
+        Returns:
+            str: Redis Stream message ID, None if quarantined
+        """
+        envelope = self._instrument_status_to_envelope(status)
+        return self.publish_event(envelope)
+
+    def publish_heartbeat(self, ts_ns: int, seq: int, node_id: str, interval_ms: int) -> Optional[str]:
+        """Publish a heartbeat envelope (pipeline liveness and latency, see src/pipeline_latency.py).
+
+        Args:
+            ts_ns: Emission time in Unix nanoseconds
+            seq: Heartbeat number since the publisher started
+            node_id: Producer node emitting it
+            interval_ms: Heartbeat interval
+
+        Returns:
+            str: Redis Stream message ID, None if quarantined
+        """
+        envelope = MarketEventEnvelope(
+            symbol="*",
+            venue="BINANCE",
+            type="heartbeat",
+            ts_event=_nanoseconds_to_rfc3339(ts_ns),
+            payload={"seq": seq, "node_id": node_id, "interval_ms": interval_ms},
+        )
+        return self.publish_event(envelope)
+
     # Note: publish_order_book_depth removed for MVP
     # Full order book snapshots will be reconstructed from deltas
     # Uncomment and implement when OrderBook import is available
@@ -234,8 +296,37 @@ class RedisPublisher:
             }
         )
 
+    def _instrument_status_to_envelope(self, status: InstrumentStatus) -> MarketEventEnvelope:
+        """Convert NautilusTrader InstrumentStatus to MarketEventEnvelope."""
+        instrument_id: InstrumentId = status.instrument_id
+
+        return MarketEventEnvelope(
+            symbol=instrument_id.symbol.value,
+            venue=instrument_id.venue.value,
+            type="instrument_status",
+            ts_event=_nanoseconds_to_rfc3339(status.ts_event),
+            payload={
+                "action": status.action.name,
+                "reason": status.reason,
+                "trading_event": status.trading_event,
+                "is_trading": status.is_trading,
+            }
+        )
+
     # _order_book_to_envelope removed for MVP - see publish_order_book_depth comment
 
+    def ping(self) -> bool:
+        """Test the stream endpoint connection.
+
+        Returns:
+            True if connection successful
+        """
+        try:
+            return bool(self.redis_client.ping())
+        except Exception as e:
+            self.log.error("redis_ping_failed", error=str(e))
+            return False
+
     def close(self):
         """Close Redis connection."""
         self.redis_client.close()
@@ -0,0 +1,70 @@
+"""Periodic top-N order book snapshots for liquidity heatmaps.
+
+Every NT_BOOK_HISTORY_INTERVAL_MS the strategy snapshots each owned
+symbol's top levels into a Redis sorted set, book_history:{symbol}, scored
+by snapshot time in epoch millis. Members are zlib-compressed JSON,
+base64-encoded so they survive decode_responses clients:
+
+    {"ts": 1760616000000, "mid": 64000.5, "bids": [[price, qty], ...], "asks": [...]}
+
+Snapshots older than the retention are trimmed on every write and the key
+expires if the symbol stops being snapshotted. The MCP server's
+get_book_history tool reads the set back as a price x time matrix.
+"""
+import base64
+import json
+import zlib
+from typing import Optional
+
+from redis import RedisError
+import structlog
+
+from ..event_bus import EventBus
+from ..state.symbol_state import SymbolState
+
+logger = structlog.get_logger()
+
+KEY_PREFIX = "book_history:"
+
+
+def build_snapshot(state: SymbolState, ts_ms: int, levels: int = 20) -> Optional[dict]:
+    """Top-N levels per side of a symbol's book, or None while the book is one-sided."""
+    if not state.best_bid or not state.best_ask:
+        return None
+    return {
+        "ts": ts_ms,
+        "mid": (state.best_bid.price + state.best_ask.price) / 2,
+        "bids": [[price, qty] for price, qty in state.order_book.top_bids[:levels]],
+        "asks": [[price, qty] for price, qty in state.order_book.top_asks[:levels]],
+    }
+
+
+def encode_snapshot(snapshot: dict) -> str:
+    """Compress a snapshot into a sorted-set member."""
+    raw = json.dumps(snapshot, separators=(",", ":")).encode()
+    return base64.b64encode(zlib.compress(raw)).decode("ascii")
+
+
+def decode_snapshot(member: str | bytes) -> dict:
+    """Inverse of encode_snapshot."""
+    return json.loads(zlib.decompress(base64.b64decode(member)))
+
+
+def record_snapshot(redis_client: EventBus, symbol: str, snapshot: dict, retention_ms: int) -> bool:
+    """Append a snapshot and trim the symbol's history to the retention window.
+
+    Returns:
+        True if the write succeeded
+    """
+    key = f"{KEY_PREFIX}{symbol}"
+    ts_ms = snapshot["ts"]
+    try:
+        pipe = redis_client.pipeline(transaction=False)
+        pipe.zadd(key, {encode_snapshot(snapshot): ts_ms})
+        pipe.zremrangebyscore(key, "-inf", ts_ms - retention_ms)
+        pipe.pexpire(key, retention_ms)
+        pipe.execute()
+        return True
+    except RedisError as e:
+        logger.warning("book_history_write_failed", symbol=symbol, error=str(e))
+        return False
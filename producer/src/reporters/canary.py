@@ -0,0 +1,227 @@
+"""Canary (shadow) reports for calculation changes.
+
+A redesigned metric module can run next to the stable one before it
+replaces it. With NT_CANARY_MODULES set, every report successfully
+published to report:{symbol} is recomputed with the listed candidate
+modules and written to report_canary:{symbol} (expiring after
+NT_CANARY_TTL_SEC), so MCP clients and dashboards can read both side by
+side. Canary reports carry a "canary" object naming the modules applied.
+
+Each module names the fields it changes. A comparison job runs every
+NT_CANARY_COMPARE_SEC and summarizes how far canary values diverged from
+stable ones over the window:
+
+    {"window_start": ..., "window_end": ..., "modules": {"health_v2": {
+        "health.score": {"samples", "mismatches", "mismatch_pct",
+                         "mean_abs_diff", "max_abs_diff", "mean_diff"}}}}
+
+mean_diff is signed (canary minus stable), so a candidate that is
+systematically stricter or looser shows up as a bias. The summary is
+logged, exported as nt_canary_* metrics and stored under canary:divergence
+(`python -m src.cli canary-divergence`). Canary publishing runs on the
+publish workers after the stable write and never affects it.
+"""
+import json
+import threading
+import time
+from dataclasses import dataclass
+from typing import Any, Callable, Optional
+
+from redis import RedisError
+import structlog
+
+from ..calculators.health import calculate_health_score_v2
+from ..event_bus import EventBus
+from .canonical import canonicalize_report
+from .publish_queue import PublishCallback
+
+logger = structlog.get_logger()
+
+KEY_PREFIX = "report_canary:"
+DIVERGENCE_KEY = "canary:divergence"
+
+
+@dataclass(frozen=True)
+class CanaryModule:
+    """A candidate metric module and the report fields it replaces."""
+
+    name: str
+    description: str
+    # Report sections recomputed from the stable report
+    apply: Callable[[dict], dict]
+    # Dotted paths compared between stable and canary reports
+    compared: tuple[str, ...]
+
+
+def _health_v2(report: dict) -> dict:
+    health = calculate_health_score_v2(
+        data_age_ms=report.get("data_age_ms"),
+        spread_bps=report.get("spread_bps"),
+        imbalance=(report.get("depth") or {}).get("imbalance"),
+        anomaly_severities=[a.get("severity") for a in report.get("anomalies") or []],
+        market_state=(report.get("market_status") or {}).get("state", "open"),
+    )
+    return {
+        "health": {
+            **(report.get("health") or {}),
+            "score": int(health["score"]),
+            "components": {**((report.get("health") or {}).get("components") or {}), **health["components"]},
+        }
+    }
+
+
+CANARY_MODULES = {
+    module.name: module
+    for module in (
+        CanaryModule(
+            name="health_v2",
+            description="Continuous, component-weighted health score",
+            apply=_health_v2,
+            compared=("health.score",),
+        ),
+    )
+}
+
+
+def build_canary_report(report: dict, modules: list[CanaryModule]) -> dict:
+    """Copy of a stable report with the candidate modules applied."""
+    canary = dict(report)
+    for module in modules:
+        canary.update(module.apply(report))
+    canary["canary"] = {"modules": [module.name for module in modules]}
+    return canonicalize_report(canary)
+
+
+def _field(report: dict, path: str) -> Any:
+    value: Any = report
+    for part in path.split("."):
+        if not isinstance(value, dict):
+            return None
+        value = value.get(part)
+    return value
+
+
+def _is_number(value: Any) -> bool:
+    return isinstance(value, (int, float)) and not isinstance(value, bool)
+
+
+class _FieldDivergence:
+    """Running divergence of one compared field over the current window."""
+
+    def __init__(self):
+        self.samples = 0
+        self.mismatches = 0
+        self.numeric = 0
+        self.sum_diff = 0.0
+        self.sum_abs_diff = 0.0
+        self.max_abs_diff = 0.0
+
+    def observe(self, stable: Any, canary: Any) -> None:
+        self.samples += 1
+        if stable != canary:
+            self.mismatches += 1
+        if _is_number(stable) and _is_number(canary):
+            diff = canary - stable
+            self.numeric += 1
+            self.sum_diff += diff
+            self.sum_abs_diff += abs(diff)
+            self.max_abs_diff = max(self.max_abs_diff, abs(diff))
+
+    def to_dict(self) -> dict:
+        return {
+            "samples": self.samples,
+            "mismatches": self.mismatches,
+            "mismatch_pct": round(self.mismatches / self.samples * 100, 2) if self.samples else None,
+            "mean_abs_diff": round(self.sum_abs_diff / self.numeric, 4) if self.numeric else None,
+            "max_abs_diff": round(self.max_abs_diff, 4) if self.numeric else None,
+            "mean_diff": round(self.sum_diff / self.numeric, 4) if self.numeric else None,
+        }
+
+
+class CanaryPublisher:
+    """Publishes canary reports next to stable ones and tracks their divergence."""
+
+    def __init__(self, redis_client: EventBus, modules: list[str], ttl_sec: int = 300, metrics=None):
+        """Initialize publisher.
+
+        Args:
+            redis_client: Redis client for the report cache
+            modules: Names of the CANARY_MODULES to apply
+            ttl_sec: Expiry of report_canary:{symbol} keys
+            metrics: Optional PrometheusMetrics for canary publish and divergence metrics
+        """
+        self.redis_client = redis_client
+        self.modules = [CANARY_MODULES[name] for name in modules]
+        self.ttl_sec = ttl_sec
+        self.metrics = metrics
+
+        self._lock = threading.Lock()
+        self._divergence: dict[tuple[str, str], _FieldDivergence] = {}
+        self._window_start = int(time.time() * 1000)
+
+    def chain(self, symbol: str, report: dict, on_done: Optional[PublishCallback]) -> PublishCallback:
+        """Wrap a stable publish callback so successful publishes get a canary."""
+
+        def with_canary(success: bool, publish_ms: float) -> None:
+            if on_done:
+                on_done(success, publish_ms)
+            if success:
+                self.publish(symbol, report)
+
+        return with_canary
+
+    def publish(self, symbol: str, report: dict) -> bool:
+        """Write the canary for a published stable report and record its divergence.
+
+        Returns:
+            True if report_canary:{symbol} was written
+        """
+        try:
+            canary = build_canary_report(report, self.modules)
+            self.redis_client.set(f"{KEY_PREFIX}{symbol}", json.dumps(canary, separators=(",", ":")), ex=self.ttl_sec)
+        except (RedisError, ValueError, TypeError) as e:
+            logger.warning("canary_publish_failed", symbol=symbol, error=str(e))
+            return False
+
+        with self._lock:
+            for module in self.modules:
+                for path in module.compared:
+                    divergence = self._divergence.setdefault((module.name, path), _FieldDivergence())
+                    divergence.observe(_field(report, path), _field(canary, path))
+
+        if self.metrics:
+            self.metrics.canary_reports.labels(symbol=symbol).inc()
+        return True
+
+    def compare(self) -> dict:
+        """Summarize divergence since the last comparison and start a new window.
+
+        The summary is logged, exported and stored under canary:divergence.
+        """
+        now_ms = int(time.time() * 1000)
+        with self._lock:
+            divergence, self._divergence = self._divergence, {}
+            window_start, self._window_start = self._window_start, now_ms
+
+        summary = {
+            "window_start": window_start,
+            "window_end": now_ms,
+            "modules": {module.name: {} for module in self.modules},
+        }
+        for (module, path), stats in sorted(divergence.items()):
+            summary["modules"][module][path] = stats.to_dict()
+            if self.metrics and stats.samples:
+                self.metrics.canary_mismatch_pct.labels(module=module, field=path).set(
+                    stats.mismatches / stats.samples * 100
+                )
+                if stats.numeric:
+                    self.metrics.canary_mean_abs_diff.labels(module=module, field=path).set(
+                        stats.sum_abs_diff / stats.numeric
+                    )
+
+        logger.info("canary_divergence", **summary)
+        try:
+            self.redis_client.set(DIVERGENCE_KEY, json.dumps(summary, separators=(",", ":")))
+        except RedisError as e:
+            logger.warning("canary_divergence_store_failed", error=str(e))
+        return summary
@@ -0,0 +1,68 @@
+"""Canonical JSON numbers for published reports.
+
+Inputs can carry prices as strings or Decimals (exchange payloads,
+NautilusTrader Price/Quantity values) and float arithmetic leaves noise
+like 0.30000000000000004. Before a report is serialized every numeric
+field becomes a JSON number with deterministic precision:
+
+- tick-aligned prices are rounded to the symbol's price_precision
+- derived prices (mid, micro, volume profile levels) keep DERIVED_PRICE_DIGITS more
+- other floats are rounded to SIGNIFICANT_DIGITS significant digits
+
+Integers stay integers; strings and Decimals become floats. Strings are
+only parsed under price and quantity keys, so symbols like 1000SHIBUSDT
+and version strings are never touched.
+"""
+from decimal import Decimal
+from typing import Any, Optional
+
+# Prices that sit on the tick grid
+PRICE_KEYS = frozenset({"price", "last_price", "high_24h", "low_24h", "price_start", "price_end"})
+
+# Prices computed between ticks
+DERIVED_PRICE_KEYS = frozenset({"mid_price", "micro_price", "POC", "VAH", "VAL", "bucket_size"})
+DERIVED_PRICE_DIGITS = 2
+
+# Quantities that may also arrive as strings
+QUANTITY_KEYS = frozenset({"qty", "size", "quantity", "visible_qty", "volume_24h"})
+
+SIGNIFICANT_DIGITS = 12
+
+
+def canonicalize_report(report: dict) -> dict:
+    """Copy of a report with canonical numbers, using its meta.price_precision."""
+    precision = (report.get("meta") or {}).get("price_precision")
+    return _canonical(report, None, precision if isinstance(precision, int) else None)
+
+
+def canonical_number(value: Any, key: Optional[str] = None, price_precision: Optional[int] = None) -> Any:
+    """Canonical form of one value (unchanged if it isn't a number for key)."""
+    if isinstance(value, bool) or value is None:
+        return value
+    if isinstance(value, str):
+        if key not in PRICE_KEYS | DERIVED_PRICE_KEYS | QUANTITY_KEYS:
+            return value
+        try:
+            value = Decimal(value.strip())
+        except ArithmeticError:
+            return value
+    if isinstance(value, Decimal):
+        value = float(value)
+    if isinstance(value, int):
+        return value
+    if not isinstance(value, float) or value != value or value in (float("inf"), float("-inf")):
+        return value
+
+    if price_precision is not None and key in PRICE_KEYS:
+        return round(value, price_precision)
+    if price_precision is not None and key in DERIVED_PRICE_KEYS:
+        return round(value, price_precision + DERIVED_PRICE_DIGITS)
+    return float(f"{value:.{SIGNIFICANT_DIGITS}g}")
+
+
+def _canonical(value: Any, key: Optional[str], price_precision: Optional[int]) -> Any:
+    if isinstance(value, dict):
+        return {k: _canonical(v, k, price_precision) for k, v in value.items()}
+    if isinstance(value, list):
+        return [_canonical(item, key, price_precision) for item in value]
+    return canonical_number(value, key, price_precision)
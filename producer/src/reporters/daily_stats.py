@@ -0,0 +1,114 @@
+"""Daily per-symbol statistics (daily:{symbol}:{date}).
+
+Each published minute digest (reporters/digest.py) is rolled up into its
+UTC day's statistics, stored as JSON under daily:{symbol}:{date} with the
+date as YYYY-MM-DD:
+
+    {"symbol": "BTCUSDT", "date": "2026-10-16", "ohlc": {...} | null,
+     "volume", "notional", "vwap", "trade_count", "avg_spread_bps",
+     "report_samples", "anomaly_totals": {...}, "minutes", "uptime_minutes",
+     "uptime_pct", "last_minute"}
+
+The rollup is a read-merge-write of the stored document, so it carries over
+producer restarts and writer handovers without in-memory state; last_minute
+makes merging a digest twice a no-op. uptime_pct is the share of the day up
+to the end of last_minute with ingestion ok, counting minutes without a
+digest (producer down, no data) as down. Keys expire after NT_DAILY_TTL_SEC
+(default 30 days) and are read by the MCP get_daily_stats tool.
+"""
+import json
+from typing import Optional
+
+from redis import RedisError
+import structlog
+
+from ..event_bus import EventBus
+from .canonical import canonicalize_report
+
+logger = structlog.get_logger()
+
+KEY_PREFIX = "daily:"
+
+
+def merge_digest(daily: Optional[dict], digest: dict) -> Optional[dict]:
+    """Day's statistics with a minute digest merged in.
+
+    Args:
+        daily: Stored statistics for the digest's day, or None for the first minute
+        digest: Digest document (see reporters/digest.py)
+
+    Returns:
+        Updated statistics, or None if the digest's minute was already merged
+    """
+    if daily and digest["minute"] <= daily["last_minute"]:
+        return None
+
+    daily = daily or {
+        "symbol": digest["symbol"],
+        "date": digest["minute"][:10],
+        "ohlc": None,
+        "volume": 0.0,
+        "notional": 0.0,
+        "vwap": None,
+        "trade_count": 0,
+        "avg_spread_bps": None,
+        "report_samples": 0,
+        "anomaly_totals": {},
+        "minutes": 0,
+        "uptime_minutes": 0.0,
+        "uptime_pct": None,
+        "last_minute": None,
+    }
+
+    ohlc, minute_ohlc = daily["ohlc"], digest["ohlc"]
+    if minute_ohlc:
+        daily["ohlc"] = {
+            "open": ohlc["open"] if ohlc else minute_ohlc["open"],
+            "high": max(ohlc["high"], minute_ohlc["high"]) if ohlc else minute_ohlc["high"],
+            "low": min(ohlc["low"], minute_ohlc["low"]) if ohlc else minute_ohlc["low"],
+            "close": minute_ohlc["close"],
+        }
+    if digest["vwap"] is not None:
+        daily["notional"] += digest["vwap"] * digest["volume"]
+    daily["volume"] += digest["volume"]
+    daily["vwap"] = daily["notional"] / daily["volume"] if daily["volume"] else None
+    daily["trade_count"] += digest["trade_count"]
+
+    samples = digest["report_samples"]
+    if samples:
+        spread_sum = (daily["avg_spread_bps"] or 0.0) * daily["report_samples"]
+        daily["report_samples"] += samples
+        daily["avg_spread_bps"] = (spread_sum + digest["avg_spread_bps"] * samples) / daily["report_samples"]
+        daily["uptime_minutes"] += digest["ingestion_ok_samples"] / samples
+
+    totals = daily["anomaly_totals"]
+    for anomaly_type, count in digest["anomaly_counts"].items():
+        totals[anomaly_type] = totals.get(anomaly_type, 0) + count
+    daily["anomaly_totals"] = dict(sorted(totals.items()))
+
+    # Minutes of the day elapsed by the end of this digest's minute
+    elapsed = int(digest["minute"][11:13]) * 60 + int(digest["minute"][14:16]) + 1
+    daily["minutes"] += 1
+    daily["uptime_pct"] = round(min(100.0, daily["uptime_minutes"] / elapsed * 100), 2)
+    daily["last_minute"] = digest["minute"]
+    return canonicalize_report(daily)
+
+
+def rollup_digest(redis_client: EventBus, digest: dict, ttl_sec: int) -> bool:
+    """Merge a digest into daily:{symbol}:{date}.
+
+    Returns:
+        True if the day's statistics were updated
+    """
+    key = f"{KEY_PREFIX}{digest['symbol']}:{digest['minute'][:10]}"
+    try:
+        stored = redis_client.get(key)
+        daily = merge_digest(json.loads(stored) if stored else None, digest)
+        if daily is None:
+            return False
+        redis_client.set(key, json.dumps(daily, separators=(",", ":")), ex=ttl_sec)
+        return True
+    except (RedisError, ValueError) as e:
+        logger.warning("daily_rollup_failed", symbol=digest["symbol"], minute=digest["minute"], error=str(e))
+        return False
+
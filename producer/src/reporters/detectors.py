@@ -0,0 +1,233 @@
+"""Anomaly detector execution under a time budget.
+
+The slow cycle runs on the strategy thread, so a detector whose history
+scan grows with window sizes delays every symbol's next report. Detectors
+run here one at a time against a DetectorInputs snapshot; once a run has
+spent its budget the remaining detectors are skipped for that cycle
+rather than letting anomaly work stretch the cycle.
+
+Heavy detectors (NT_ASYNC_DETECTORS) run on DeferredDetectorRunner's
+worker thread instead: each slow cycle submits a snapshot of the book and
+trade windows, and the anomalies it finds are attached to the symbol's
+next report. A symbol holds at most one job, so a backlogged worker
+causes skipped runs, never a growing queue.
+"""
+import threading
+import time
+from concurrent.futures import Future, ThreadPoolExecutor
+from dataclasses import dataclass, field
+from datetime import datetime, timezone
+from typing import Callable, Optional
+import structlog
+
+from src.state.level_lifetime import PulledLevel
+from src.state.symbol_state import OrderBookL2, SymbolState, TradeTick
+from src.calculators.anomalies import (
+    detect_spoofing,
+    detect_iceberg,
+    detect_absorption,
+    detect_flash_crash_risk,
+    detect_quote_stuffing,
+    calculate_flow_acceleration
+)
+from src.calculators.depth import calculate_depth_metrics
+from src.calculators.microstructure import calculate_microstructure
+from src.calculators.notional import NotionalTier
+from src.calculators.spread import calculate_mid_price
+
+logger = structlog.get_logger()
+
+
+@dataclass
+class DetectorInputs:
+    """Everything the anomaly detectors read, captured at one instant."""
+    order_book: OrderBookL2
+    trades_30s: list[TradeTick]
+    trades_10s: list[TradeTick]
+    mid_price: Optional[float]
+    spread_bps: float
+    depth_imbalance: Optional[float]
+    notional_tier: NotionalTier
+    quote_usd_rate: float
+    microstructure: dict  # Update rate and churn (calculate_microstructure)
+    pulled_levels: list[PulledLevel]  # Levels pulled without trading over the churn window
+
+    @classmethod
+    def from_state(
+        cls, state: SymbolState, copy_book: bool = False, churn_window_sec: int = 10
+    ) -> "DetectorInputs":
+        """Capture detector inputs; copy_book for use off the strategy thread."""
+        mid_price = None
+        spread_bps = 0.0
+        if state.best_bid and state.best_ask:
+            mid_price = calculate_mid_price(state.best_bid, state.best_ask)
+            if mid_price > 0:
+                spread_bps = (state.best_ask.price - state.best_bid.price) / mid_price * 10000
+
+        depth_metrics = calculate_depth_metrics(state)
+        now_ms = int(datetime.now(timezone.utc).timestamp() * 1000)
+        return cls(
+            order_book=state.order_book.snapshot() if copy_book else state.order_book,
+            trades_30s=state.trade_buffer_30s.get_all(),
+            trades_10s=state.trade_buffer_10s.get_all(),
+            mid_price=mid_price,
+            spread_bps=spread_bps,
+            depth_imbalance=depth_metrics.get("imbalance", 0.0) if depth_metrics else None,
+            notional_tier=state.notional_tier,
+            # Notionals of non-USD quotes without a known rate are taken at par
+            quote_usd_rate=state.quote_usd_rate or 1.0,
+            microstructure=calculate_microstructure(state, churn_window_sec),
+            pulled_levels=state.level_lifetimes.pulled(churn_window_sec, now_ms),
+        )
+
+
+def _spoofing(inputs: DetectorInputs) -> list[dict]:
+    if not inputs.mid_price:
+        return []
+    return detect_spoofing(
+        order_book=inputs.order_book,
+        mid_price=inputs.mid_price,
+        pulled_levels=inputs.pulled_levels,
+        notional_tier=inputs.notional_tier,
+        quote_usd_rate=inputs.quote_usd_rate,
+        churn_per_sec=inputs.microstructure["churn_per_sec"]
+    )
+
+
+def _iceberg(inputs: DetectorInputs) -> list[dict]:
+    if len(inputs.trades_30s) < 5:
+        return []
+    return detect_iceberg(
+        trades=inputs.trades_30s,
+        order_book=inputs.order_book,
+        notional_tier=inputs.notional_tier,
+        quote_usd_rate=inputs.quote_usd_rate
+    )
+
+
+def _absorption(inputs: DetectorInputs) -> list[dict]:
+    # Aggression that fails to move price, same 30s window
+    if len(inputs.trades_30s) < 10:
+        return []
+    return detect_absorption(
+        trades=inputs.trades_30s,
+        order_book=inputs.order_book,
+        notional_tier=inputs.notional_tier,
+        quote_usd_rate=inputs.quote_usd_rate
+    )
+
+
+def _flash_crash_risk(inputs: DetectorInputs) -> list[dict]:
+    # Needs a two-sided book for spread and depth imbalance
+    if inputs.mid_price is None or inputs.depth_imbalance is None:
+        return []
+    flash_crash = detect_flash_crash_risk(
+        spread_bps=inputs.spread_bps,
+        depth_imbalance=inputs.depth_imbalance,
+        flow_acceleration=calculate_flow_acceleration(inputs.trades_10s, window_sec=10)
+    )
+    return [flash_crash] if flash_crash else []
+
+
+def _quote_stuffing(inputs: DetectorInputs) -> list[dict]:
+    quote_stuffing = detect_quote_stuffing(
+        updates_per_sec=inputs.microstructure["quote_updates_per_sec"],
+        baseline_updates_per_sec=inputs.microstructure["baseline_updates_per_sec"],
+        churn_per_sec=inputs.microstructure["churn_per_sec"]
+    )
+    return [quote_stuffing] if quote_stuffing else []
+
+
+# Detector name -> runner, in execution order
+DETECTORS: dict[str, Callable[[DetectorInputs], list[dict]]] = {
+    "spoofing": _spoofing,
+    "iceberg": _iceberg,
+    "absorption": _absorption,
+    "flash_crash_risk": _flash_crash_risk,
+    "quote_stuffing": _quote_stuffing,
+}
+
+
+@dataclass
+class DetectorRun:
+    """Outcome of running a set of detectors."""
+    anomalies: list[dict] = field(default_factory=list)
+    timings_ms: dict[str, float] = field(default_factory=dict)
+    over_budget: list[str] = field(default_factory=list)  # Detectors that alone exceeded the budget
+    skipped: list[str] = field(default_factory=list)  # Not run: budget already spent
+
+
+def run_detectors(names: list[str], inputs: DetectorInputs, budget_ms: float) -> DetectorRun:
+    """Run detectors in order until the time budget is spent.
+
+    A detector can't be interrupted, so the budget is checked between
+    detectors: the one that crosses it completes, the rest are skipped.
+    """
+    run = DetectorRun()
+    start = time.perf_counter()
+    for name in names:
+        if (time.perf_counter() - start) * 1000 >= budget_ms:
+            run.skipped.append(name)
+            continue
+        detector_start = time.perf_counter()
+        run.anomalies.extend(DETECTORS[name](inputs))
+        elapsed_ms = (time.perf_counter() - detector_start) * 1000
+        run.timings_ms[name] = round(elapsed_ms, 3)
+        if elapsed_ms > budget_ms:
+            run.over_budget.append(name)
+    return run
+
+
+class DeferredDetectorRunner:
+    """Runs heavy detectors off the strategy thread, one pending job per symbol."""
+
+    def __init__(self, budget_ms: float = 50.0, max_age_ms: float = 10000.0):
+        """Start the detector worker.
+
+        Args:
+            budget_ms: Time budget of each deferred run
+            max_age_ms: Results of inputs older than this are discarded instead of published
+        """
+        self.budget_ms = budget_ms
+        self.max_age_ms = max_age_ms
+        self._executor = ThreadPoolExecutor(max_workers=1, thread_name_prefix="detectors")
+        self._lock = threading.Lock()
+        self._jobs: dict[str, tuple[float, Future]] = {}
+
+    def submit(self, symbol: str, names: list[str], inputs: DetectorInputs) -> bool:
+        """Queue a run for the symbol unless its previous run hasn't finished.
+
+        Returns:
+            False if the run was skipped because the worker is behind
+        """
+        with self._lock:
+            job = self._jobs.get(symbol)
+            if job and not job[1].done():
+                return False
+            self._jobs[symbol] = (time.monotonic(), self._executor.submit(run_detectors, names, inputs, self.budget_ms))
+            return True
+
+    def collect(self, symbol: str) -> Optional[DetectorRun]:
+        """Take the symbol's finished run, or None while it is pending or absent."""
+        with self._lock:
+            job = self._jobs.get(symbol)
+            if not job or not job[1].done():
+                return None
+            del self._jobs[symbol]
+        submitted, future = job
+
+        try:
+            run = future.result()
+        except Exception as e:
+            logger.error("deferred_detectors_failed", symbol=symbol, error=str(e), error_type=type(e).__name__)
+            return None
+        if (time.monotonic() - submitted) * 1000 > self.max_age_ms:
+            logger.warning("deferred_detectors_expired", symbol=symbol)
+            return None
+        return run
+
+    def close(self) -> None:
+        """Stop the worker, abandoning pending runs."""
+        self._executor.shutdown(wait=False, cancel_futures=True)
+        with self._lock:
+            self._jobs.clear()
@@ -0,0 +1,52 @@
+"""Per-minute digest reports (digest:{symbol}:{minute}).
+
+Alongside the real-time report the strategy publishes a summary of every
+closed minute (state/minute_digest.py) as JSON under
+digest:{symbol}:{minute}, with the minute in UTC as YYYY-MM-DDTHH:MM:
+
+    {"symbol": "BTCUSDT", "minute": "2026-10-16T12:34", "start_ms": ...,
+     "ohlc": {"open", "high", "low", "close"} | null, "volume", "vwap",
+     "trade_count", "avg_spread_bps", "avg_imbalance", "report_samples",
+     "ingestion_ok_samples", "anomaly_counts": {...}}
+
+Keys expire after NT_DIGEST_TTL_SEC (default 24h), much later than the
+report itself, so agents get recent history from the MCP server's
+get_digest tool without a persistent store.
+"""
+import json
+from datetime import datetime, timezone
+
+from redis import RedisError
+import structlog
+
+from ..event_bus import EventBus
+from .canonical import canonicalize_report
+
+logger = structlog.get_logger()
+
+KEY_PREFIX = "digest:"
+
+
+def minute_label(start_ms: int) -> str:
+    """UTC minute of a digest as used in its key (YYYY-MM-DDTHH:MM)."""
+    return datetime.fromtimestamp(start_ms / 1000, tz=timezone.utc).strftime("%Y-%m-%dT%H:%M")
+
+
+def build_digest(symbol: str, summary: dict) -> dict:
+    """Digest document for a closed minute's summary, with canonical numbers."""
+    return canonicalize_report({"symbol": symbol, "minute": minute_label(summary["start_ms"]), **summary})
+
+
+def record_digest(redis_client: EventBus, digest: dict, ttl_sec: int) -> bool:
+    """Write a digest under digest:{symbol}:{minute}.
+
+    Returns:
+        True if the write succeeded
+    """
+    key = f"{KEY_PREFIX}{digest['symbol']}:{digest['minute']}"
+    try:
+        redis_client.set(key, json.dumps(digest, separators=(",", ":")), ex=ttl_sec)
+        return True
+    except RedisError as e:
+        logger.warning("digest_write_failed", symbol=digest["symbol"], minute=digest["minute"], error=str(e))
+        return False
@@ -1,18 +1,35 @@
 """Fast-cycle report generation for market analytics."""
 from datetime import datetime, timezone
-from typing import Optional
+from typing import Optional, Sequence
 from ..state.symbol_state import SymbolState
 from ..calculators.spread import calculate_spread_metrics
 from ..calculators.depth import calculate_depth_metrics
-from ..calculators.flow import calculate_orders_per_sec, calculate_net_flow
+from ..calculators.flow import (
+    DEFAULT_FLOW_WINDOWS,
+    calculate_flow_windows,
+    calculate_net_flow,
+    calculate_orders_per_sec,
+)
 from ..calculators.health import calculate_health_score
+from ..calculators.microstructure import calculate_microstructure
+from ..calculators.notional import to_usd
+from .provenance import fast_provenance
+from .trade_tape import serialize_trades
+
+# Stamped on every report; each version's field changes are listed in
+# mcp-server/report_versions.py, which must know this version
+SCHEMA_VERSION = "1.8"
 
 
 def generate_fast_report(
     state: SymbolState,
     node_id: str,
     writer_token: int,
-    ticker_data: Optional[dict] = None
+    ticker_data: Optional[dict] = None,
+    flow_window_sec: int = 30,
+    flow_windows: Sequence[int] = DEFAULT_FLOW_WINDOWS,
+    recent_trades: int = 0,
+    churn_window_sec: int = 10,
 ) -> Optional[dict]:
     """Generate fast-cycle market report.
 
@@ -24,6 +41,10 @@ def generate_fast_report(
         node_id: Unique identifier of this producer instance
         writer_token: Monotonic fencing token from writer lease
         ticker_data: Optional 24h ticker statistics (last_price, change_24h_pct, etc.)
+        flow_window_sec: Window for the headline net_flow value (FLOW_WINDOW_SEC)
+        flow_windows: Windows published in flow.windows (NT_FLOW_WINDOWS)
+        recent_trades: Trades from the tape published as recent_trades (0 omits the section)
+        churn_window_sec: Window of the microstructure update rate and churn (NT_CHURN_WINDOW_SEC)
 
     Returns:
         Complete market report dictionary, or None if insufficient data
@@ -41,12 +62,11 @@ def generate_fast_report(
     if data_age_ms is None:
         data_age_ms = 0
 
-    if data_age_ms > 2000:
-        ingestion_status = "down"
-    elif data_age_ms > 1000:
-        ingestion_status = "degraded"
-    else:
-        ingestion_status = "ok"
+    # A halted or closed market is expected to go quiet: hold the ingestion status
+    market_status = state.market_status
+    if market_status.is_open:
+        state.ingestion.update(data_age_ms, updated_at_ms)
+    ingestion_status = state.ingestion.status
 
     last_update = state.last_event_ts or now
 
@@ -62,7 +82,7 @@ def generate_fast_report(
 
     # Calculate flow metrics
     orders_per_sec = calculate_orders_per_sec(state)
-    net_flow_data = calculate_net_flow(state)
+    net_flow_data = calculate_net_flow(state, window_seconds=flow_window_sec)
     net_flow = net_flow_data["net_flow"] if net_flow_data else 0.0
 
     # Calculate health score
@@ -70,7 +90,8 @@ def generate_fast_report(
         data_age_ms=data_age_ms,
         spread_bps=spread_metrics["spread_bps"],
         imbalance=depth_metrics["imbalance"],
-        has_anomalies=False  # Fast cycle doesn't detect anomalies yet
+        has_anomalies=False,  # Fast cycle doesn't detect anomalies yet
+        market_state=market_status.state,
     )
 
     # Build depth object with top 20 levels
@@ -98,9 +119,15 @@ def generate_fast_report(
         low_24h = last_price
         volume_24h = 0.0
 
+    # USD notionals of base-asset figures, valued at the last price
+    quote_usd_rate = state.quote_usd_rate
+    flow_windows_metrics = calculate_flow_windows(state, flow_windows)
+    for window_metrics in flow_windows_metrics.values():
+        window_metrics["net_flow_usd"] = to_usd(window_metrics["net_flow"] * last_price, quote_usd_rate)
+
     # Assemble complete report
     report = {
-        "schemaVersion": "1.1",
+        "schemaVersion": SCHEMA_VERSION,
         "writer": {
             "nodeId": node_id,
             "writerToken": writer_token,
@@ -108,17 +135,22 @@ def generate_fast_report(
         "updatedAt": updated_at_ms,
         "symbol": state.symbol,
         "venue": "BINANCE",
+        "meta": state.meta.to_dict(),
         "generated_at": now.isoformat().replace('+00:00', 'Z'),
         "data_age_ms": data_age_ms,
         "ingestion": {
             "status": ingestion_status,
+            "fresh": data_age_ms <= state.ingestion.thresholds.degraded_ms,
             "last_update": last_update.isoformat().replace('+00:00', 'Z'),
+            **state.ingestion.timeline(updated_at_ms),
         },
+        "market_status": market_status.to_dict(),
         "last_price": last_price,
         "change_24h_pct": change_24h_pct,
         "high_24h": high_24h,
         "low_24h": low_24h,
         "volume_24h": volume_24h,
+        "volume_24h_usd": to_usd(volume_24h * last_price, quote_usd_rate),
         "best_bid": {
             "price": state.best_bid.price,
             "qty": state.best_bid.qty,
@@ -135,12 +167,18 @@ def generate_fast_report(
             "top20_ask": depth_asks,
             "sum_bid": depth_metrics["total_bid_qty"],
             "sum_ask": depth_metrics["total_ask_qty"],
+            "sum_bid_usd": to_usd(depth_metrics["total_bid_qty"] * last_price, quote_usd_rate),
+            "sum_ask_usd": to_usd(depth_metrics["total_ask_qty"] * last_price, quote_usd_rate),
             "imbalance": depth_metrics["imbalance"],
         },
         "flow": {
             "orders_per_sec": orders_per_sec,
             "net_flow": net_flow,
+            "net_flow_usd": to_usd(net_flow * last_price, quote_usd_rate),
+            "windows": flow_windows_metrics,
         },
+        "microstructure": calculate_microstructure(state, churn_window_sec),
+        "anomalies": [],  # Filled in by the slow cycle
         "health": {
             "score": int(health_data["score"]),
             "components": {
@@ -152,6 +190,11 @@ def generate_fast_report(
                 "freshness": float(health_data["score"])  # MVP: use overall score for freshness
             },
         },
+        "provenance": fast_provenance(state, ticker_data, flow_window_sec, churn_window_sec, recent_trades),
     }
 
+    # Optional raw tape, oldest first
+    if recent_trades > 0:
+        report["recent_trades"] = serialize_trades(state.trade_tape.get_all()[-recent_trades:])
+
     return report
@@ -0,0 +1,122 @@
+"""Feature flags gating optional report sections.
+
+Operators can turn report sections on or off per environment or per
+symbol without redeploying, e.g. to roll out a new section to a few
+symbols first or to drop an expensive one from a staging feed. Flags
+resolve from most to least specific:
+
+1. Redis override for the symbol (control:flags, "symbols")
+2. Redis override for every symbol (control:flags, "sections")
+3. NT_SYMBOL_SECTIONS for the symbol ("SYMBOL:-section,SYMBOL:+section")
+4. NT_DISABLED_SECTIONS
+5. on
+
+The Redis overrides are a JSON document written by `python -m src.cli
+feature-flag` and re-read on every slow cycle:
+
+    {"sections": {"liquidity": false},
+     "symbols": {"BTCUSDT": {"liquidity": true}}}
+
+A disabled section is removed from the report, with its provenance
+entries, just before publishing. Only optional sections can be gated
+(REPORT_SECTIONS); new sections are gated by adding them there.
+"""
+import json
+from typing import Any, Optional
+
+from ..event_bus import EventBus
+
+FLAGS_KEY = "control:flags"
+
+# Optional report sections that can be turned off
+REPORT_SECTIONS = ("analytics", "liquidity", "microstructure", "market_status", "recent_trades", "timings", "provenance")
+
+
+def read_overrides(redis_client: EventBus) -> dict[str, Any]:
+    """Current Redis overrides ({"sections": {...}, "symbols": {...}})."""
+    raw = redis_client.get(FLAGS_KEY)
+    overrides = json.loads(raw) if raw else {}
+    return {"sections": overrides.get("sections", {}), "symbols": overrides.get("symbols", {})}
+
+
+def write_override(redis_client: EventBus, section: str, enabled: Optional[bool], symbol: Optional[str] = None) -> dict:
+    """Set (or with enabled=None, clear) one Redis override.
+
+    Returns:
+        The overrides after the change
+    """
+    if section not in REPORT_SECTIONS:
+        raise ValueError(f"Unknown report section {section} (expected one of {', '.join(REPORT_SECTIONS)})")
+
+    overrides = read_overrides(redis_client)
+    flags = overrides["symbols"].setdefault(symbol, {}) if symbol else overrides["sections"]
+    if enabled is None:
+        flags.pop(section, None)
+    else:
+        flags[section] = enabled
+    overrides["symbols"] = {s: f for s, f in overrides["symbols"].items() if f}
+
+    redis_client.set(FLAGS_KEY, json.dumps(overrides, separators=(",", ":")))
+    return overrides
+
+
+class FeatureFlags:
+    """Resolves which report sections are enabled for each symbol."""
+
+    def __init__(
+        self,
+        disabled_sections: Optional[list[str]] = None,
+        symbol_sections: Optional[dict[str, dict[str, bool]]] = None,
+    ):
+        """Initialize flags.
+
+        Args:
+            disabled_sections: Sections off for every symbol (NT_DISABLED_SECTIONS)
+            symbol_sections: Per-symbol {section: enabled} overrides (NT_SYMBOL_SECTIONS)
+        """
+        self.disabled_sections = frozenset(disabled_sections or [])
+        self.symbol_sections = symbol_sections or {}
+        self.overrides: dict[str, Any] = {"sections": {}, "symbols": {}}
+
+    def refresh(self, redis_client: EventBus) -> list[tuple[str, str, bool]]:
+        """Reload the Redis overrides.
+
+        Returns:
+            (symbol or "*", section, enabled) for each override that changed
+        """
+        previous, self.overrides = self.overrides, read_overrides(redis_client)
+        changes = []
+        for scope, old, new in [("*", previous["sections"], self.overrides["sections"])] + [
+            (symbol, previous["symbols"].get(symbol, {}), self.overrides["symbols"].get(symbol, {}))
+            for symbol in sorted(set(previous["symbols"]) | set(self.overrides["symbols"]))
+        ]:
+            for section in sorted(set(old) | set(new)):
+                if old.get(section) != new.get(section):
+                    changes.append((scope, section, new.get(section)))
+        return changes
+
+    def enabled(self, symbol: str, section: str) -> bool:
+        """Whether a section is published for a symbol."""
+        for flags in (
+            self.overrides["symbols"].get(symbol, {}),
+            self.overrides["sections"],
+            self.symbol_sections.get(symbol, {}),
+        ):
+            if section in flags:
+                return bool(flags[section])
+        return section not in self.disabled_sections
+
+    def disabled(self, symbol: str) -> frozenset[str]:
+        """Sections not published for a symbol."""
+        return frozenset(section for section in REPORT_SECTIONS if not self.enabled(symbol, section))
+
+    def apply(self, symbol: str, report: dict) -> dict:
+        """Report without the symbol's disabled sections (the report itself if none are)."""
+        disabled = self.disabled(symbol)
+        if not disabled:
+            return report
+
+        gated = {key: value for key, value in report.items() if key not in disabled}
+        if isinstance(gated.get("provenance"), list):
+            gated["provenance"] = [entry for entry in gated["provenance"] if entry.get("section") not in disabled]
+        return gated
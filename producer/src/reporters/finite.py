@@ -0,0 +1,38 @@
+"""Non-finite number guard run on every report before it is published.
+
+Calculators divide by window lengths, mid prices and depth sums; an empty
+window or a zero mid can leave NaN or ±Infinity in a report. Python's json
+module writes those as NaN/Infinity literals, which are not JSON, so Go and
+JavaScript readers reject the whole report. Such values are replaced with
+null, and the report lists the fields it happened to in non_finite_fields
+(list indices collapsed to [], e.g. "depth.top20_bid[].qty").
+"""
+import math
+from typing import Any
+
+WARNING_FIELD = "non_finite_fields"
+
+
+def scrub_non_finite(report: dict[str, Any]) -> tuple[dict[str, Any], list[str]]:
+    """Copy of a report with non-finite floats as None, and the fields that had them.
+
+    The report is returned as is (not copied) when every number is finite.
+    """
+    fields: list[str] = []
+    scrubbed = _scrub(report, "", fields)
+    if not fields:
+        return report, []
+    return scrubbed, sorted(set(fields))
+
+
+def _scrub(value: Any, path: str, fields: list[str]) -> Any:
+    if isinstance(value, float):
+        if math.isfinite(value):
+            return value
+        fields.append(path)
+        return None
+    if isinstance(value, dict):
+        return {key: _scrub(item, f"{path}.{key}" if path else str(key), fields) for key, item in value.items()}
+    if isinstance(value, list):
+        return [_scrub(item, f"{path}[]", fields) for item in value]
+    return value
@@ -0,0 +1,114 @@
+"""Synthetic anomaly injection for end-to-end alert validation.
+
+Operators write an injection to `control:inject:{symbol}` (see
+`python -m src.cli inject-anomaly`) with a TTL. While the key exists and
+NT_ALLOW_ANOMALY_INJECTION is enabled, every report published for the
+symbol carries the injected anomalies and, optionally, a degraded health
+score or ingestion status, so alerting and MCP surfacing can be verified in
+staging without waiting for real market conditions. Injected anomalies are
+marked "synthetic": true so consumers can tell them apart.
+"""
+import json
+import time
+from typing import Any, Optional
+
+from ..event_bus import EventBus
+
+INJECTION_KEY_PREFIX = "control:inject:"
+
+ANOMALY_TYPES = ("spoofing", "iceberg", "absorption", "flash_crash_risk", "quote_stuffing")
+SEVERITIES = ("low", "medium", "high")
+INGESTION_STATUSES = ("ok", "degraded", "down")
+
+
+def build_injection(
+    anomaly_type: str = "spoofing",
+    severity: str = "high",
+    health_score: Optional[int] = None,
+    ingestion_status: Optional[str] = None,
+    ttl_sec: int = 60,
+    injected_by: str = ""
+) -> dict[str, Any]:
+    """Build and validate an injection payload.
+
+    Args:
+        anomaly_type: Anomaly type to inject
+        severity: Anomaly severity
+        health_score: Optional health score override (0-100)
+        ingestion_status: Optional ingestion status override
+        ttl_sec: Seconds the injection stays active
+        injected_by: Operator or tool that requested the injection
+
+    Returns:
+        Injection payload
+
+    Raises:
+        ValueError: If any value is out of range
+    """
+    if anomaly_type not in ANOMALY_TYPES:
+        raise ValueError(f"anomaly type must be one of {', '.join(ANOMALY_TYPES)}, got {anomaly_type}")
+    if severity not in SEVERITIES:
+        raise ValueError(f"severity must be one of {', '.join(SEVERITIES)}, got {severity}")
+    if health_score is not None and not 0 <= health_score <= 100:
+        raise ValueError(f"health score must be 0-100, got {health_score}")
+    if ingestion_status is not None and ingestion_status not in INGESTION_STATUSES:
+        raise ValueError(f"ingestion status must be one of {', '.join(INGESTION_STATUSES)}, got {ingestion_status}")
+    if not 1 <= ttl_sec <= 3600:
+        raise ValueError(f"ttl must be 1-3600 seconds, got {ttl_sec}")
+
+    return {
+        "anomalies": [{
+            "type": anomaly_type,
+            "severity": severity,
+            "note": "Injected test anomaly",
+            "synthetic": True,
+        }],
+        "health_score": health_score,
+        "ingestion_status": ingestion_status,
+        "injected_by": injected_by,
+        "expires_at": int((time.time() + ttl_sec) * 1000),
+    }
+
+
+def write_injection(redis_client: EventBus, symbol: str, injection: dict[str, Any]) -> None:
+    """Store an injection for a symbol until it expires."""
+    ttl_ms = max(1, injection["expires_at"] - int(time.time() * 1000))
+    redis_client.set(f"{INJECTION_KEY_PREFIX}{symbol}", json.dumps(injection), px=ttl_ms)
+
+
+def clear_injection(redis_client: EventBus, symbol: str) -> bool:
+    """Remove an active injection, returning True if one existed."""
+    return bool(redis_client.delete(f"{INJECTION_KEY_PREFIX}{symbol}"))
+
+
+def read_injection(redis_client: EventBus, symbol: str) -> Optional[dict[str, Any]]:
+    """Read the active injection for a symbol, if any."""
+    data = redis_client.get(f"{INJECTION_KEY_PREFIX}{symbol}")
+    if not data:
+        return None
+    return json.loads(data)
+
+
+def apply_injection(report: dict[str, Any], injection: dict[str, Any]) -> dict[str, Any]:
+    """Merge an injection into a report.
+
+    Args:
+        report: Report about to be published
+        injection: Active injection payload
+
+    Returns:
+        Copy of the report with injected anomalies and overrides
+    """
+    injected = report.copy()
+    injected["anomalies"] = [
+        *(a for a in report.get("anomalies") or [] if not a.get("synthetic")),
+        *injection.get("anomalies", []),
+    ]
+
+    if injection.get("health_score") is not None:
+        injected["health"] = {**report.get("health", {}), "score": injection["health_score"]}
+
+    if injection.get("ingestion_status"):
+        injected["ingestion"] = {**report.get("ingestion", {}), "status": injection["ingestion_status"]}
+
+    return injected
@@ -0,0 +1,95 @@
+"""Report invariant checks run before publishing.
+
+Each invariant inspects one section of a complete report and returns a
+violation message or None. Checks run on the publish path when
+NT_INVARIANT_MODE is "tag" (publish with an invariant_violations list) or
+"block" (drop the report), so calculation bugs surface in production
+metrics instead of reaching clients unnoticed.
+"""
+from typing import Any, Callable, Optional
+
+INVARIANT_MODES = ("off", "tag", "block")
+
+# Float tolerance: depth sums are rounded to 8 decimals before publishing
+_TOLERANCE = 1e-6
+
+
+def _close(a: float, b: float) -> bool:
+    return abs(a - b) <= _TOLERANCE + 1e-9 * max(abs(a), abs(b))
+
+
+def check_spread(report: dict[str, Any]) -> Optional[str]:
+    """Best bid below best ask, non-negative spread, micro price inside the touch."""
+    bid = (report.get("best_bid") or {}).get("price")
+    ask = (report.get("best_ask") or {}).get("price")
+    if bid is None or ask is None:
+        return None
+    if bid >= ask:
+        return f"crossed book: best_bid {bid} >= best_ask {ask}"
+    if report.get("spread_bps", 0) < 0:
+        return f"negative spread_bps {report['spread_bps']}"
+    micro = report.get("micro_price")
+    if micro is not None and not (bid - _TOLERANCE <= micro <= ask + _TOLERANCE):
+        return f"micro_price {micro} outside [{bid}, {ask}]"
+    return None
+
+
+def check_imbalance(report: dict[str, Any]) -> Optional[str]:
+    """Depth imbalance within [-1, 1]."""
+    imbalance = (report.get("depth") or {}).get("imbalance")
+    if imbalance is not None and not -1.0 <= imbalance <= 1.0:
+        return f"imbalance {imbalance} outside [-1, 1]"
+    return None
+
+
+def check_depth(report: dict[str, Any]) -> Optional[str]:
+    """Bids strictly descending, asks strictly ascending, sums match the levels."""
+    depth = report.get("depth") or {}
+    bids = [level["price"] for level in depth.get("top20_bid") or []]
+    asks = [level["price"] for level in depth.get("top20_ask") or []]
+    if any(a <= b for a, b in zip(bids, bids[1:])):
+        return "top20_bid not sorted descending"
+    if any(b <= a for a, b in zip(asks, asks[1:])):
+        return "top20_ask not sorted ascending"
+
+    for side, key in (("top20_bid", "sum_bid"), ("top20_ask", "sum_ask")):
+        total = depth.get(key)
+        levels_total = sum(level["qty"] for level in depth.get(side) or [])
+        if total is not None and not _close(total, levels_total):
+            return f"{key} {total} != sum of {side} quantities {levels_total}"
+    return None
+
+
+def check_volume_profile(report: dict[str, Any]) -> Optional[str]:
+    """VAL <= POC <= VAH."""
+    profile = (report.get("analytics") or {}).get("volume_profile")
+    if not profile:
+        return None
+    val, poc, vah = profile.get("VAL"), profile.get("POC"), profile.get("VAH")
+    if None in (val, poc, vah):
+        return None
+    if not val <= poc <= vah:
+        return f"volume profile VAL {val} <= POC {poc} <= VAH {vah} violated"
+    return None
+
+
+INVARIANTS: dict[str, Callable[[dict[str, Any]], Optional[str]]] = {
+    "spread": check_spread,
+    "imbalance": check_imbalance,
+    "depth_sorted": check_depth,
+    "volume_profile": check_volume_profile,
+}
+
+
+def check_report(report: dict[str, Any]) -> list[dict[str, str]]:
+    """Run all invariants against a report.
+
+    Returns:
+        List of {"invariant", "message"} violations (empty when valid)
+    """
+    violations = []
+    for name, check in INVARIANTS.items():
+        message = check(report)
+        if message:
+            violations.append({"invariant": name, "message": message})
+    return violations
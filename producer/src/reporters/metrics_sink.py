@@ -0,0 +1,133 @@
+"""Per-report metrics records for offline analysis of the analytics service.
+
+Alongside Prometheus aggregates, each generated report can be summarized
+as one compact record (symbol, spread, imbalance, health score, anomaly
+count, calculation latency) written to a Redis Stream or a JSON-lines
+file, so report quality and calculator cost can be studied statistically.
+"""
+import json
+import threading
+from typing import Optional, Protocol
+from redis import RedisError
+import structlog
+
+from ..event_bus import EventBus
+
+logger = structlog.get_logger()
+
+DEFAULT_STREAM = "nt:report_metrics"
+DEFAULT_MAXLEN = 100000
+
+
+def build_metrics_record(
+    report: dict,
+    cycle: str,
+    calc_ms: float,
+    publish_ms: Optional[float] = None,
+) -> dict:
+    """Summarize a generated report as a compact metrics record.
+
+    Args:
+        report: Published market report
+        cycle: "fast" or "slow"
+        calc_ms: Time spent calculating the report (or slow-cycle metrics)
+        publish_ms: Time spent publishing to Redis, if measured
+
+    Returns:
+        Flat dictionary of scalar values
+    """
+    record = {
+        "ts": report.get("updatedAt"),
+        "symbol": report.get("symbol"),
+        "node": (report.get("writer") or {}).get("nodeId"),
+        "cycle": cycle,
+        "spread_bps": report.get("spread_bps"),
+        "imbalance": (report.get("depth") or {}).get("imbalance"),
+        "health_score": (report.get("health") or {}).get("score"),
+        "anomaly_count": len(report.get("anomalies") or []),
+        "data_age_ms": report.get("data_age_ms"),
+        "calc_ms": round(calc_ms, 3),
+    }
+    if publish_ms is not None:
+        record["publish_ms"] = round(publish_ms, 3)
+    return record
+
+
+class MetricsSink(Protocol):
+    """Destination for per-report metrics records."""
+
+    def write(self, record: dict) -> None: ...
+
+    def close(self) -> None: ...
+
+
+class RedisStreamSink:
+    """Appends metrics records to a capped Redis Stream."""
+
+    def __init__(self, redis_client: EventBus, stream: str = DEFAULT_STREAM, maxlen: int = DEFAULT_MAXLEN):
+        self.redis_client = redis_client
+        self.stream = stream
+        self.maxlen = maxlen
+
+    def write(self, record: dict) -> None:
+        fields = {k: "" if v is None else str(v) for k, v in record.items()}
+        try:
+            self.redis_client.xadd(self.stream, fields, maxlen=self.maxlen, approximate=True)
+        except RedisError as e:
+            logger.warning("report_metrics_write_failed", sink="redis", stream=self.stream, error=str(e))
+
+    def close(self) -> None:
+        pass
+
+
+class FileSink:
+    """Appends metrics records to a JSON-lines file."""
+
+    def __init__(self, path: str):
+        self.path = path
+        self._lock = threading.Lock()
+        self._file = open(path, "a", buffering=1)
+
+    def write(self, record: dict) -> None:
+        line = json.dumps(record, separators=(",", ":"))
+        try:
+            with self._lock:
+                self._file.write(line + "\n")
+        except OSError as e:
+            logger.warning("report_metrics_write_failed", sink="file", path=self.path, error=str(e))
+
+    def close(self) -> None:
+        with self._lock:
+            self._file.close()
+
+
+def create_metrics_sink(
+    kind: str,
+    redis_client: Optional[EventBus] = None,
+    target: str = "",
+    maxlen: int = DEFAULT_MAXLEN,
+) -> Optional[MetricsSink]:
+    """Create the configured sink.
+
+    Args:
+        kind: "redis", "file", or "" to disable
+        redis_client: Client for the redis sink
+        target: Stream key (redis, default nt:report_metrics) or file path (file)
+        maxlen: Approximate stream length cap (redis)
+
+    Returns:
+        Sink instance, or None when disabled
+    """
+    if not kind:
+        return None
+    if kind == "redis":
+        sink = RedisStreamSink(redis_client, stream=target or DEFAULT_STREAM, maxlen=maxlen)
+    elif kind == "file":
+        if not target:
+            raise ValueError("NT_REPORT_METRICS_TARGET must be a file path for the file sink")
+        sink = FileSink(target)
+    else:
+        raise ValueError(f"Unknown report metrics sink: {kind} (use redis or file)")
+
+    logger.info("report_metrics_sink_enabled", sink=kind, target=target or DEFAULT_STREAM)
+    return sink
@@ -0,0 +1,216 @@
+"""Hourly Parquet files of published reports and trades.
+
+With NT_PARQUET_DIR set, every published report (fast and slow cycle) and
+every trade is written to per-symbol, per-hour Parquet files that pandas,
+polars or DuckDB load without an ETL step:
+
+    {dir}/reports/symbol=BTCUSDT/2026-10-16T12.parquet
+    {dir}/trades/symbol=BTCUSDT/2026-10-16T12.parquet
+
+    SELECT * FROM read_parquet('/data/reports/*/*.parquet', hive_partitioning = true)
+
+Report rows hold the headline scalars as columns (REPORT_SCHEMA) plus the
+whole report as JSON in the report column; trade rows hold time, price,
+size and aggressor side. Hours are UTC, by report updatedAt and trade time.
+
+Rows are buffered and written as a row group every flush_sec from a
+background thread. A file stays open, named *.parquet.inprogress, until
+the first flush after its hour ends, and is then renamed to *.parquet, so
+readers globbing *.parquet never see a partial file. A restart within an
+hour starts another file for it ({hour}-1.parquet, ...). A crash loses the
+open files of the current hour.
+
+pyarrow is an optional dependency (poetry extra parquet).
+"""
+import json
+import threading
+from datetime import datetime, timezone
+from pathlib import Path
+from typing import Optional
+
+import structlog
+
+from ..state.symbol_state import TradeTick
+
+logger = structlog.get_logger()
+
+KINDS = ("reports", "trades")
+
+INPROGRESS_SUFFIX = ".inprogress"
+
+
+def hour_label(ts_ms: int) -> str:
+    """UTC hour of a row as used in file names (YYYY-MM-DDTHH)."""
+    return datetime.fromtimestamp(ts_ms / 1000, tz=timezone.utc).strftime("%Y-%m-%dT%H")
+
+
+def report_row(report: dict, cycle: str) -> dict:
+    """Row of the reports table for a published report."""
+    best_bid = report.get("best_bid") or {}
+    best_ask = report.get("best_ask") or {}
+    return {
+        "ts": report["updatedAt"],
+        "cycle": cycle,
+        "last_price": report.get("last_price"),
+        "best_bid": best_bid.get("price"),
+        "best_bid_qty": best_bid.get("qty"),
+        "best_ask": best_ask.get("price"),
+        "best_ask_qty": best_ask.get("qty"),
+        "mid_price": report.get("mid_price"),
+        "micro_price": report.get("micro_price"),
+        "spread_bps": report.get("spread_bps"),
+        "imbalance": (report.get("depth") or {}).get("imbalance"),
+        "health_score": (report.get("health") or {}).get("score"),
+        "ingestion_status": (report.get("ingestion") or {}).get("status"),
+        "anomaly_count": len(report.get("anomalies") or []),
+        "data_age_ms": report.get("data_age_ms"),
+        "report": json.dumps(report, separators=(",", ":")),
+    }
+
+
+def trade_row(trade: TradeTick) -> dict:
+    """Row of the trades table for a trade."""
+    return {
+        "ts": int(trade.timestamp.timestamp() * 1000),
+        "price": trade.price,
+        "size": trade.volume,
+        "side": trade.aggressor_side.lower(),
+    }
+
+
+def _schemas(pa) -> dict:
+    ts = pa.timestamp("ms", tz="UTC")
+    return {
+        "reports": pa.schema([
+            ("ts", ts),
+            ("cycle", pa.string()),
+            ("last_price", pa.float64()),
+            ("best_bid", pa.float64()),
+            ("best_bid_qty", pa.float64()),
+            ("best_ask", pa.float64()),
+            ("best_ask_qty", pa.float64()),
+            ("mid_price", pa.float64()),
+            ("micro_price", pa.float64()),
+            ("spread_bps", pa.float64()),
+            ("imbalance", pa.float64()),
+            ("health_score", pa.float64()),
+            ("ingestion_status", pa.string()),
+            ("anomaly_count", pa.int32()),
+            ("data_age_ms", pa.float64()),
+            ("report", pa.string()),
+        ]),
+        "trades": pa.schema([
+            ("ts", ts),
+            ("price", pa.float64()),
+            ("size", pa.float64()),
+            ("side", pa.string()),
+        ]),
+    }
+
+
+class ParquetHistoryWriter:
+    """Buffers report and trade rows and writes them to hourly Parquet files from a background thread."""
+
+    def __init__(self, directory: str, flush_sec: float = 60.0, metrics=None):
+        """Initialize writer.
+
+        Args:
+            directory: Root directory of the reports/ and trades/ trees
+            flush_sec: Seconds between row group writes
+            metrics: Optional PrometheusMetrics for row counts
+        """
+        import pyarrow as pa
+        import pyarrow.parquet as pq
+
+        self._pa = pa
+        self._pq = pq
+        self.schemas = _schemas(pa)
+        self.directory = Path(directory)
+        self.flush_sec = flush_sec
+        self.metrics = metrics
+
+        self._lock = threading.Lock()
+        # (kind, symbol, hour) -> buffered rows
+        self._buffers: dict[tuple[str, str, str], list[dict]] = {}
+        # (kind, symbol, hour) -> (open writer, in-progress path)
+        self._writers: dict[tuple[str, str, str], tuple] = {}
+        self._stop = threading.Event()
+        self._thread: Optional[threading.Thread] = None
+
+    def write_report(self, report: dict, cycle: str) -> None:
+        """Buffer a published report."""
+        self._append("reports", report["symbol"], report["updatedAt"], report_row(report, cycle))
+
+    def write_trade(self, symbol: str, trade: TradeTick) -> None:
+        """Buffer a trade."""
+        row = trade_row(trade)
+        self._append("trades", symbol, row["ts"], row)
+
+    def _append(self, kind: str, symbol: str, ts_ms: int, row: dict) -> None:
+        key = (kind, symbol, hour_label(ts_ms))
+        with self._lock:
+            self._buffers.setdefault(key, []).append(row)
+
+    def flush(self, close_all: bool = False) -> None:
+        """Write buffered rows, then finish the files of past hours (or all files)."""
+        with self._lock:
+            buffers, self._buffers = self._buffers, {}
+
+        for key, rows in buffers.items():
+            kind = key[0]
+            try:
+                writer = self._writer(key)
+                writer.write_table(self._pa.Table.from_pylist(rows, schema=self.schemas[kind]))
+            except (OSError, ValueError, self._pa.ArrowException) as e:
+                logger.warning("parquet_write_failed", kind=kind, symbol=key[1], hour=key[2], rows=len(rows), error=str(e))
+                self._count(kind, "failed", len(rows))
+                continue
+            self._count(kind, "written", len(rows))
+
+        current_hour = hour_label(int(datetime.now(timezone.utc).timestamp() * 1000))
+        for key in list(self._writers):
+            if close_all or key[2] < current_hour:
+                self._finish(key)
+
+    def _writer(self, key: tuple[str, str, str]):
+        if key not in self._writers:
+            kind, symbol, hour = key
+            folder = self.directory / kind / f"symbol={symbol}"
+            folder.mkdir(parents=True, exist_ok=True)
+            path, part = folder / f"{hour}.parquet", 0
+            while path.exists() or path.with_name(path.name + INPROGRESS_SUFFIX).exists():
+                part += 1
+                path = folder / f"{hour}-{part}.parquet"
+            tmp = path.with_name(path.name + INPROGRESS_SUFFIX)
+            self._writers[key] = (self._pq.ParquetWriter(tmp, self.schemas[kind], compression="zstd"), tmp)
+        return self._writers[key][0]
+
+    def _finish(self, key: tuple[str, str, str]) -> None:
+        writer, tmp = self._writers.pop(key)
+        try:
+            writer.close()
+            tmp.replace(tmp.with_name(tmp.name[:-len(INPROGRESS_SUFFIX)]))
+        except (OSError, self._pa.ArrowException) as e:
+            logger.warning("parquet_close_failed", path=str(tmp), error=str(e))
+
+    def _count(self, kind: str, result: str, rows: int) -> None:
+        if self.metrics:
+            self.metrics.parquet_rows.labels(kind=kind, result=result).inc(rows)
+
+    def _run(self) -> None:
+        while not self._stop.wait(self.flush_sec):
+            self.flush()
+
+    def start(self) -> None:
+        """Start writing in a background thread."""
+        self._thread = threading.Thread(target=self._run, name="parquet-writer", daemon=True)
+        self._thread.start()
+        logger.info("parquet_writer_started", directory=str(self.directory), flush_sec=self.flush_sec)
+
+    def close(self) -> None:
+        """Stop the background thread, write what is buffered and finish every file."""
+        self._stop.set()
+        if self._thread:
+            self._thread.join(timeout=5)
+            self._thread = None
+        self.flush(close_all=True)
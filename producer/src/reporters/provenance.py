@@ -0,0 +1,120 @@
+"""Field-level provenance published in each report's provenance section.
+
+One entry per report section says which inputs produced it: the source
+event types (NautilusTrader data types, or report.<field> for values
+derived from other report fields), the timestamp of the latest source
+event, the computation window and the cycle that computed it. Empty
+sources mark placeholder values. The MCP server only returns the section
+when a tool is called with verbose=true.
+"""
+from datetime import datetime
+from typing import Any, Optional, Sequence
+
+from ..state.symbol_state import SymbolState
+
+BOOK = "OrderBookDeltas"
+TRADES = "TradeTick"
+TICKER = "Ticker24h"
+INSTRUMENT = "Instrument"
+
+# Trade buffer behind the slow-cycle volume profile and footprint
+VOLUME_PROFILE_WINDOW_SEC = 30 * 60
+
+
+def fast_provenance(
+    state: SymbolState,
+    ticker_data: Optional[dict] = None,
+    flow_window_sec: int = 30,
+    churn_window_sec: int = 10,
+    recent_trades: int = 0,
+) -> list[dict]:
+    """Provenance of the fast-cycle sections of a report built from state."""
+    book_ts = state.last_book_ts
+    trade_ts = state.last_trade.timestamp if state.last_trade else None
+    entries = [
+        _entry(section, [BOOK], book_ts)
+        for section in ("best_bid", "best_ask", "spread_bps", "mid_price", "micro_price")
+    ]
+    entries += [
+        _entry("depth", [BOOK, "report.last_price"], book_ts),
+        _entry("last_price", [TRADES] if state.last_trade else ["report.mid_price"], trade_ts or book_ts),
+        *_ticker_entries(ticker_data, trade_ts),
+        _entry("volume_24h_usd", ["report.volume_24h", "report.last_price"], trade_ts),
+        _entry("flow", [TRADES, "report.last_price"], trade_ts, window_sec=flow_window_sec),
+        _entry("microstructure", [BOOK], book_ts, window_sec=churn_window_sec),
+        _entry("ingestion", [BOOK, TRADES], state.last_event_ts),
+        _entry("health", ["report.data_age_ms", "report.spread_bps", "report.depth"], state.last_event_ts),
+        _entry("meta", [INSTRUMENT], None),
+    ]
+    if recent_trades > 0:
+        entries.append(_entry("recent_trades", [TRADES], trade_ts))
+    return entries
+
+
+def _ticker_entries(ticker_data: Optional[dict], trade_ts: Optional[datetime]) -> list[dict]:
+    sections = ("change_24h_pct", "high_24h", "low_24h", "volume_24h")
+    if ticker_data:
+        return [_entry(section, [TICKER], None, window_sec=24 * 60 * 60) for section in sections]
+    # Without a ticker feed high/low fall back to the last price and the rest are placeholders
+    return [
+        _entry(section, ["report.last_price"] if section in ("high_24h", "low_24h") else [], trade_ts)
+        for section in sections
+    ]
+
+
+def slow_provenance(state: SymbolState, metrics: dict[str, Any], footprint_window_sec: int = 300,
+                    churn_window_sec: int = 10) -> list[dict]:
+    """Provenance of the sections a slow cycle adds (only those it computed)."""
+    book_ts = state.last_book_ts
+    trade_ts = state.last_trade.timestamp if state.last_trade else None
+
+    entries = []
+    if metrics.get("volume_profile"):
+        entries.append(_entry(
+            "analytics.volume_profile", [TRADES], trade_ts, window_sec=VOLUME_PROFILE_WINDOW_SEC, cycle="slow"
+        ))
+    if metrics.get("poc_trend") is not None:
+        entries.append(_entry("analytics.poc_trend", ["report.analytics.volume_profile"], trade_ts, cycle="slow"))
+    if metrics.get("developing_value_area") is not None:
+        # Window is the current UTC session
+        entries.append(_entry("analytics.developing_value_area", [TRADES], trade_ts, cycle="slow"))
+    if metrics.get("footprint"):
+        entries.append(_entry(
+            "analytics.footprint", [TRADES], trade_ts, window_sec=footprint_window_sec, cycle="slow"
+        ))
+    if metrics.get("liquidity_walls") or metrics.get("liquidity_vacuums"):
+        entries.append(_entry(
+            "liquidity", [BOOK, "report.last_price", "report.analytics.volume_profile"], book_ts, cycle="slow"
+        ))
+    # Detectors run every slow cycle (deferred ones included), even when they find nothing
+    entries.append(_entry(
+        "anomalies", [BOOK, TRADES], _latest(book_ts, trade_ts), window_sec=churn_window_sec, cycle="slow"
+    ))
+    return entries
+
+
+def merge_provenance(base: Sequence[dict], updates: Sequence[dict]) -> list[dict]:
+    """Base entries with those of the updated sections replaced."""
+    updated = {entry["section"] for entry in updates}
+    return [entry for entry in base if entry["section"] not in updated] + list(updates)
+
+
+def _entry(
+    section: str,
+    sources: list[str],
+    source_ts: Optional[datetime],
+    window_sec: Optional[int] = None,
+    cycle: str = "fast",
+) -> dict:
+    return {
+        "section": section,
+        "sources": sources,
+        "source_ts": source_ts.isoformat().replace('+00:00', 'Z') if source_ts else None,
+        "window_sec": window_sec,
+        "cycle": cycle,
+    }
+
+
+def _latest(*timestamps: Optional[datetime]) -> Optional[datetime]:
+    present = [ts for ts in timestamps if ts is not None]
+    return max(present) if present else None
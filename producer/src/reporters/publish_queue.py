@@ -0,0 +1,205 @@
+"""Background report publishing with bounded per-symbol queues.
+
+Publishing to Redis runs on worker threads so a slow Redis round trip no
+longer stalls market data callbacks and report generation for every
+symbol on the strategy thread. Each symbol holds at most one pending
+fast-cycle and one pending slow-cycle report: the two write separate hash
+fields (see redis_cache.py), so a fast report never drops a slow one, but
+a newer report of the same cycle supersedes one still waiting to be
+published, and the superseded report is dropped and counted instead of
+queued behind it.
+
+Workers flush pending reports in batches: after the first report arrives
+a worker waits flush_ms for other symbols updated in the same tick, then
+publishes up to max_batch of them in one Redis pipeline.
+"""
+import threading
+import time
+from collections import deque
+from typing import Callable, Optional
+import structlog
+
+from ..event_bus import EventBus
+from .redis_cache import publish_reports
+from .timings import stamp_queue_time
+
+logger = structlog.get_logger()
+
+# Called after a publish attempt with (success, publish_ms)
+PublishCallback = Callable[[bool, float], None]
+
+# Pending report slot: (symbol, slow cycle)
+Slot = tuple[str, bool]
+
+
+def _slot(symbol: str, report: dict) -> Slot:
+    return symbol, "slow_cycle_updated_at" in report
+
+
+class ReportPublishQueue:
+    """Worker pool publishing the latest pending fast and slow report per symbol."""
+
+    def __init__(
+        self,
+        redis_client: EventBus,
+        workers: int = 4,
+        flush_ms: float = 5.0,
+        max_batch: int = 100,
+        metrics=None,
+        pubsub_client: Optional[EventBus] = None
+    ):
+        """Start publish workers.
+
+        Args:
+            redis_client: Redis client (connection pool shared by workers)
+            workers: Number of publish worker threads
+            flush_ms: Time to collect a batch after the first pending report
+            max_batch: Maximum reports per pipeline
+            metrics: Optional PrometheusMetrics for queue depth, batch and drop metrics
+            pubsub_client: Client for a separate pub/sub endpoint (None = redis_client)
+        """
+        if workers <= 0:
+            raise ValueError(f"workers must be positive, got {workers}")
+        if max_batch <= 0:
+            raise ValueError(f"max_batch must be positive, got {max_batch}")
+
+        self.redis_client = redis_client
+        self.pubsub_client = pubsub_client
+        self.flush_sec = max(0.0, flush_ms) / 1000
+        self.max_batch = max_batch
+        self.metrics = metrics
+
+        self._cond = threading.Condition()
+        self._pending: dict[Slot, tuple[dict, Optional[PublishCallback]]] = {}
+        self._submitted: dict[Slot, float] = {}  # Monotonic time each pending report was queued
+        self._ready: deque[Slot] = deque()  # Slots with a pending report, oldest first
+        self._in_flight: set[str] = set()  # One publish per symbol at a time keeps order
+        self._stopped = False
+
+        self._threads = [
+            threading.Thread(target=self._run, name=f"report-publisher-{i}", daemon=True)
+            for i in range(workers)
+        ]
+        for thread in self._threads:
+            thread.start()
+
+        logger.info("report_publish_queue_started", workers=workers, flush_ms=flush_ms, max_batch=max_batch)
+
+    def submit(self, symbol: str, report: dict, on_done: Optional[PublishCallback] = None) -> bool:
+        """Queue a report for publishing, superseding any pending report of its cycle for the symbol.
+
+        Args:
+            symbol: Trading pair symbol
+            report: Complete market report
+            on_done: Optional callback run on a worker thread after publishing
+
+        Returns:
+            False if the queue is closed or an older pending report was superseded
+        """
+        with self._cond:
+            if self._stopped:
+                return False
+
+            slot = _slot(symbol, report)
+            superseded = slot in self._pending
+            self._pending[slot] = (report, on_done)
+            self._submitted[slot] = time.monotonic()
+            if not superseded and symbol not in self._in_flight:
+                self._ready.append(slot)
+                self._cond.notify()
+            depth = len(self._pending)
+
+        if self.metrics:
+            self.metrics.publish_queue_depth.set(depth)
+            if superseded:
+                self.metrics.reports_dropped.labels(symbol=symbol, reason="superseded").inc()
+        return not superseded
+
+    def _take_batch(self) -> Optional[dict[str, tuple[dict, Optional[PublishCallback]]]]:
+        """Wait for pending reports and claim a batch (empty if other workers took them).
+
+        A batch holds one report per symbol; a symbol's other pending report
+        waits for the batch to finish (see _done).
+        """
+        with self._cond:
+            while not self._ready and not self._stopped:
+                self._cond.wait()
+            if not self._ready:
+                return None
+            collect = not self._stopped and len(self._ready) < self.max_batch
+
+        # Let other symbols updated in the same tick join the batch
+        if collect and self.flush_sec:
+            time.sleep(self.flush_sec)
+
+        with self._cond:
+            batch = {}
+            now = time.monotonic()
+            while self._ready and len(batch) < self.max_batch:
+                slot = self._ready.popleft()
+                symbol = slot[0]
+                if symbol in batch:
+                    continue  # Still pending; rescheduled once this batch is done
+                batch[symbol] = self._pending.pop(slot)
+                self._in_flight.add(symbol)
+                stamp_queue_time(batch[symbol][0], (now - self._submitted.pop(slot)) * 1000)
+            return batch
+
+    def _done(self, symbols) -> None:
+        with self._cond:
+            for symbol in symbols:
+                self._in_flight.discard(symbol)
+                # A newer report arrived while publishing (or the other cycle's waited); schedule it now
+                for slot in ((symbol, False), (symbol, True)):
+                    if slot in self._pending:
+                        self._ready.append(slot)
+                        self._cond.notify()
+            depth = len(self._pending)
+
+        if self.metrics:
+            self.metrics.publish_queue_depth.set(depth)
+
+    def _run(self) -> None:
+        while True:
+            batch = self._take_batch()
+            if batch is None:
+                return
+            if not batch:
+                continue
+
+            try:
+                start = time.perf_counter()
+                results = publish_reports(
+                    redis_client=self.redis_client,
+                    reports={symbol: report for symbol, (report, _) in batch.items()},
+                    pubsub_client=self.pubsub_client
+                )
+                publish_ms = (time.perf_counter() - start) * 1000
+
+                if self.metrics:
+                    self.metrics.publish_batch_size.observe(len(batch))
+
+                for symbol, (_, on_done) in batch.items():
+                    if on_done:
+                        on_done(results.get(symbol, False), publish_ms)
+            except Exception as e:
+                logger.error(
+                    "report_publish_worker_error",
+                    symbols=list(batch),
+                    error=str(e),
+                    error_type=type(e).__name__
+                )
+            finally:
+                self._done(batch)
+
+    def close(self, timeout_sec: float = 5.0) -> None:
+        """Stop accepting reports, publish what is pending, and join workers."""
+        with self._cond:
+            self._stopped = True
+            self._cond.notify_all()
+
+        deadline = time.monotonic() + timeout_sec
+        for thread in self._threads:
+            thread.join(max(0.0, deadline - time.monotonic()))
+
+        logger.info("report_publish_queue_stopped", unpublished=len(self._pending))
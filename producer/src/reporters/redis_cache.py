@@ -1,137 +1,310 @@
-"""Redis report caching and publishing."""
+"""Redis report caching and publishing.
+
+A report is stored as a hash, report:{symbol}, with one JSON-encoded
+field per top-level section (best_bid, depth, flow, liquidity, ...), so
+the two cycles update their own sections without rewriting the other's:
+
+- a fast-cycle report writes every section except SLOW_FIELDS, plus
+  _sections, the list of its sections in report order; fields a later
+  fast report no longer has (a section turned off) are left behind but
+  not read
+- a slow-cycle report (one carrying slow_cycle_updated_at) writes only
+  SLOW_FIELDS, deleting the ones it doesn't have, and its provenance as
+  provenance:slow
+
+With the fast and deep processing profiles on separate nodes, each node's
+writes stay confined to its sections, so a deep node never overwrites
+fresher L1 or flow data with what it read a slow cycle ago. decode_report
+assembles the document again (readers use HGETALL).
+"""
 import json
 import time
 from typing import Optional
-from redis import Redis, RedisError
+from redis import RedisError
 import structlog
 
+from ..event_bus import EventBus
+from .canonical import canonicalize_report
+
 logger = structlog.get_logger()
 
+# Pub/sub channel prefix announcing each published report (reports:{symbol})
+REPORT_CHANNEL_PREFIX = "reports:"
+
+# Sorted set of published symbols scored by report updatedAt (ms)
+REPORT_INDEX_KEY = "reports:index"
+
+REPORT_KEY_PREFIX = "report:"
+
+# Sections owned by the slow cycle; the fast report's empty anomalies list is the default
+SLOW_FIELDS = ("analytics", "liquidity", "anomalies", "slow_cycle_updated_at")
+SLOW_DEFAULTS = {"anomalies": "[]"}
+
+SECTIONS_FIELD = "_sections"
+SLOW_PROVENANCE_FIELD = "provenance:slow"
+
+
+def encode_report(report: dict) -> tuple[dict[str, str], tuple[str, ...]]:
+    """Hash fields to set for a report, and the fields to delete.
+
+    Args:
+        report: Canonicalized fast-cycle report or slow-cycle enrichment
+
+    Returns:
+        (fields to HSET, fields to HDEL)
+    """
+    def encode(value) -> str:
+        return json.dumps(value, separators=(',', ':'), allow_nan=False)
+
+    if "slow_cycle_updated_at" in report:
+        fields = {name: encode(report[name]) for name in SLOW_FIELDS if name in report}
+        if "provenance" in report:
+            fields[SLOW_PROVENANCE_FIELD] = encode(report["provenance"])
+        stale = tuple(name for name in (*SLOW_FIELDS, SLOW_PROVENANCE_FIELD) if name not in fields)
+        return fields, stale
+
+    fields = {name: encode(value) for name, value in report.items() if name not in SLOW_FIELDS}
+    fields[SECTIONS_FIELD] = encode(list(report))
+    return fields, ()
+
+
+def decode_report(fields: dict, slow: bool = True) -> Optional[dict]:
+    """Report assembled from its hash fields (HGETALL reply).
+
+    Args:
+        fields: Hash fields of report:{symbol}
+        slow: Include the slow-cycle sections (False: the last fast report as published)
+
+    Returns:
+        Report dictionary, or None without a fast report yet
+    """
+    if not fields or SECTIONS_FIELD not in fields:
+        return None
+
+    report = {}
+    for name in json.loads(fields[SECTIONS_FIELD]):
+        if name in SLOW_FIELDS:
+            raw = fields.get(name, SLOW_DEFAULTS.get(name)) if slow else SLOW_DEFAULTS.get(name)
+        else:
+            raw = fields.get(name)
+        if raw is not None:
+            report[name] = json.loads(raw)
+    if not slow:
+        return report
+    for name in SLOW_FIELDS:
+        if name not in report and name in fields:
+            report[name] = json.loads(fields[name])
+
+    # Slow-cycle provenance entries for sections the fast report doesn't describe
+    if SLOW_PROVENANCE_FIELD in fields:
+        fast = report.get("provenance") or []
+        sections = {entry.get("section") for entry in fast}
+        slow = [entry for entry in json.loads(fields[SLOW_PROVENANCE_FIELD]) if entry.get("section") not in sections]
+        report["provenance"] = fast + slow
+    return report
+
+
+def drop_legacy_report(redis_client: EventBus, symbol: str) -> bool:
+    """Delete a report:{symbol} stored as a JSON string by an older producer (HSET would fail on it)."""
+    key = f"{REPORT_KEY_PREFIX}{symbol}"
+    try:
+        if redis_client.type(key) == "string":
+            redis_client.delete(key)
+            logger.info("legacy_report_key_dropped", symbol=symbol, key=key)
+            return True
+    except RedisError as e:
+        logger.warning("legacy_report_key_check_failed", symbol=symbol, error=str(e))
+    return False
+
 
 def publish_report(
-    redis_client: Redis,
+    redis_client: EventBus,
     symbol: str,
     report: dict,
     max_retries: int = 3,
-    retry_delay_ms: int = 100
+    retry_delay_ms: int = 100,
+    pubsub_client: Optional[EventBus] = None
 ) -> bool:
-    """Publish market report to Redis cache.
+    """Publish a single market report to Redis cache.
 
-    Uses Redis SET with KEEPTTL to preserve existing TTL on the key.
-    Includes exponential backoff retry logic on failures.
+    See publish_reports for the commands sent.
+
+    Returns:
+        True if published successfully, False otherwise
+    """
+    return publish_reports(
+        redis_client,
+        {symbol: report},
+        max_retries=max_retries,
+        retry_delay_ms=retry_delay_ms,
+        pubsub_client=pubsub_client
+    )[symbol]
+
+
+def publish_reports(
+    redis_client: EventBus,
+    reports: dict[str, dict],
+    max_retries: int = 3,
+    retry_delay_ms: int = 100,
+    pubsub_client: Optional[EventBus] = None
+) -> dict[str, bool]:
+    """Publish market reports for several symbols in one Redis round trip.
+
+    Each report is written to its report:{symbol} hash fields (see
+    encode_report; HSET keeps any existing TTL), published whole on the
+    reports:{symbol} channel for live subscribers, and recorded in the
+    reports:index registry (sorted set of symbol by updatedAt). Numbers
+    are canonicalized first (see canonical.py). All commands go through
+    one non-transactional pipeline; the whole batch is retried with
+    exponential backoff on Redis errors.
+
+    With a separate pub/sub endpoint (pubsub_client), the PUBLISHes go to
+    it in a second pipeline after the cache writes, for the reports that
+    were stored. A failed announcement is logged but doesn't fail the
+    publish: the cache is the source of truth and subscribers catch up on
+    the next report.
 
     Args:
         redis_client: Redis client instance (with connection pooling)
-        symbol: Trading pair symbol (e.g., "BTCUSDT")
-        report: Complete market report dictionary
+        reports: Complete market report dictionaries keyed by symbol
         max_retries: Maximum number of retry attempts (default 3)
         retry_delay_ms: Initial retry delay in milliseconds (doubles each retry)
+        pubsub_client: Client for the pub/sub endpoint (None = redis_client)
 
     Returns:
-        True if published successfully, False otherwise
+        Map of symbol to True if published successfully, False otherwise
     """
-    key = f"report:{symbol}"
+    results = {symbol: False for symbol in reports}
 
-    try:
-        # Serialize report to JSON
-        report_json = json.dumps(report, separators=(',', ':'))
-
-        # Attempt to publish with retries
-        for attempt in range(max_retries):
-            try:
-                # SET with KEEPTTL preserves existing TTL (or no expiry if not set)
-                result = redis_client.set(key, report_json, keepttl=True)
-
-                if result:
-                    logger.debug(
-                        "report_published",
-                        symbol=symbol,
-                        key=key,
-                        size_bytes=len(report_json),
-                        attempt=attempt + 1
-                    )
-                    return True
+    # Serialize reports to JSON with canonical numbers (a bad report fails only its own symbol;
+    # NaN/Infinity is refused rather than written as invalid JSON, see finite.py)
+    payloads = {}
+    hashes = {}
+    for symbol, report in reports.items():
+        try:
+            canonical = canonicalize_report(report)
+            payloads[symbol] = json.dumps(canonical, separators=(',', ':'), allow_nan=False)
+            hashes[symbol] = encode_report(canonical)
+        except (TypeError, ValueError) as e:
+            logger.error(
+                "report_serialization_error",
+                symbol=symbol,
+                error=str(e),
+                report_keys=list(report.keys()) if isinstance(report, dict) else "not_dict"
+            )
+
+    if not payloads:
+        return results
+
+    # Attempt to publish with retries
+    for attempt in range(max_retries):
+        try:
+            pipe = redis_client.pipeline(transaction=False)
+            for symbol, report_json in payloads.items():
+                fields, stale = hashes[symbol]
+                pipe.hset(f"{REPORT_KEY_PREFIX}{symbol}", mapping=fields)
+                if stale:
+                    pipe.hdel(f"{REPORT_KEY_PREFIX}{symbol}", *stale)
+                if pubsub_client is None:
+                    pipe.publish(f"{REPORT_CHANNEL_PREFIX}{symbol}", report_json)
+                pipe.zadd(REPORT_INDEX_KEY, {symbol: reports[symbol].get("updatedAt", 0)})
+            replies = iter(pipe.execute(raise_on_error=False))
+
+            # Replies per symbol: HSET, [HDEL,] [PUBLISH,] ZADD
+            for symbol in payloads:
+                commands = 2 + bool(hashes[symbol][1]) + (pubsub_client is None)
+                symbol_replies = [next(replies) for _ in range(commands)]
+                error = next((r for r in symbol_replies if isinstance(r, Exception)), None)
+                if error is None:
+                    results[symbol] = True
                 else:
                     logger.warning(
-                        "report_publish_failed_no_result",
+                        "report_publish_failed",
                         symbol=symbol,
-                        key=key,
-                        attempt=attempt + 1
+                        key=f"{REPORT_KEY_PREFIX}{symbol}",
+                        attempt=attempt + 1,
+                        error=str(error)
                     )
 
-            except RedisError as e:
-                logger.warning(
-                    "report_publish_redis_error",
-                    symbol=symbol,
-                    key=key,
-                    attempt=attempt + 1,
+            logger.debug(
+                "reports_published",
+                symbols=len(payloads),
+                size_bytes=sum(len(p) for p in payloads.values()),
+                attempt=attempt + 1
+            )
+            if pubsub_client is not None:
+                announce_reports(pubsub_client, {
+                    symbol: payloads[symbol] for symbol in payloads if results[symbol]
+                })
+            return results
+
+        except RedisError as e:
+            logger.warning(
+                "report_publish_redis_error",
+                symbols=list(payloads),
+                attempt=attempt + 1,
+                max_retries=max_retries,
+                error=str(e)
+            )
+
+            # Exponential backoff before retry
+            if attempt < max_retries - 1:
+                delay_sec = (retry_delay_ms * (2 ** attempt)) / 1000
+                time.sleep(delay_sec)
+            else:
+                # Final attempt failed
+                logger.error(
+                    "report_publish_max_retries_exceeded",
+                    symbols=list(payloads),
                     max_retries=max_retries,
                     error=str(e)
                 )
 
-                # Exponential backoff before retry
-                if attempt < max_retries - 1:
-                    delay_sec = (retry_delay_ms * (2 ** attempt)) / 1000
-                    time.sleep(delay_sec)
-                    continue
-                else:
-                    # Final attempt failed
-                    logger.error(
-                        "report_publish_max_retries_exceeded",
-                        symbol=symbol,
-                        key=key,
-                        max_retries=max_retries,
-                        error=str(e)
-                    )
-                    return False
+        except Exception as e:
+            # Unexpected error
+            logger.error(
+                "report_publish_unexpected_error",
+                symbols=list(payloads),
+                error=str(e),
+                error_type=type(e).__name__
+            )
+            return results
 
-    except (TypeError, ValueError) as e:
-        # JSON serialization error
-        logger.error(
-            "report_serialization_error",
-            symbol=symbol,
-            error=str(e),
-            report_keys=list(report.keys()) if isinstance(report, dict) else "not_dict"
-        )
-        return False
+    return results
 
-    except Exception as e:
-        # Unexpected error
-        logger.error(
-            "report_publish_unexpected_error",
-            symbol=symbol,
-            key=key,
-            error=str(e),
-            error_type=type(e).__name__
-        )
-        return False
 
-    return False
+def announce_reports(pubsub_client: EventBus, payloads: dict[str, str]) -> None:
+    """PUBLISH serialized reports on their reports:{symbol} channels (failures are logged)."""
+    if not payloads:
+        return
+    try:
+        pipe = pubsub_client.pipeline(transaction=False)
+        for symbol, report_json in payloads.items():
+            pipe.publish(f"{REPORT_CHANNEL_PREFIX}{symbol}", report_json)
+        pipe.execute()
+    except RedisError as e:
+        logger.warning("report_announce_failed", symbols=list(payloads), error=str(e))
 
 
 def get_report(
-    redis_client: Redis,
-    symbol: str
+    redis_client: EventBus,
+    symbol: str,
+    slow: bool = True
 ) -> Optional[dict]:
     """Retrieve market report from Redis cache.
 
     Args:
         redis_client: Redis client instance
         symbol: Trading pair symbol (e.g., "BTCUSDT")
+        slow: Include the slow-cycle sections (see decode_report)
 
     Returns:
         Report dictionary if found, None otherwise
     """
-    key = f"report:{symbol}"
+    key = f"{REPORT_KEY_PREFIX}{symbol}"
 
     try:
-        report_json = redis_client.get(key)
-
-        if report_json:
-            report = json.loads(report_json)
-            return report
-        else:
-            return None
+        return decode_report(redis_client.hgetall(key), slow=slow)
 
     except (RedisError, json.JSONDecodeError) as e:
         logger.error(
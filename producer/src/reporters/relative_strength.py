@@ -0,0 +1,146 @@
+"""Cross-market relative strength (the relative_strength report field).
+
+An agent looking at one symbol can't tell a 3% move from a market-wide
+one without reading every other report. In multi-symbol deployments each
+report therefore carries its 24h performance relative to a benchmark
+(BTCUSDT by default) and to the median of the tracked universe:
+
+    "relative_strength": {"window_sec": 86400, "performance_pct": 4.2,
+        "benchmark": "BTCUSDT", "benchmark_performance_pct": 1.1,
+        "vs_benchmark_pct": 3.1, "universe_median_pct": 0.8,
+        "vs_median_pct": 3.4, "rank": 2, "universe_size": 12,
+        "updatedAt": ...}
+
+Every NT_RELATIVE_STRENGTH_INTERVAL_SEC the producer reads the last price
+of every symbol published in the last minute (reports:index, so symbols
+owned by other nodes count too) and the close of its digest from 24h ago
+(digest:{symbol}:{minute}, see reporters/digest.py; the first minute with
+trades within a few minutes after the 24h mark). performance_pct is the
+change between them, vs_* fields are percentage-point differences and rank
+is 1 for the strongest symbol. Symbols without a digest from 24h ago (the
+producer or the symbol is younger than a day, or digests are off) have a
+null performance and are left out of the median and ranking. The field is
+omitted until at least two symbols have a performance.
+"""
+import json
+import statistics
+import time
+from typing import Optional
+
+from redis import RedisError
+import structlog
+
+from ..event_bus import EventBus
+from .digest import KEY_PREFIX as DIGEST_KEY_PREFIX, minute_label
+from .redis_cache import REPORT_INDEX_KEY, REPORT_KEY_PREFIX, decode_report
+
+logger = structlog.get_logger()
+
+WINDOW_SEC = 86400
+
+# Reports older than this are from symbols no longer published
+MAX_REPORT_AGE_MS = 60_000
+
+# Minutes after the 24h mark searched for a digest when that minute has none (no trades);
+# later rather than earlier minutes, as those expire first with NT_DIGEST_TTL_SEC=86400
+DIGEST_SEARCH_MINUTES = 5
+
+
+class RelativeStrengthTracker:
+    """Computes each tracked symbol's 24h performance against the benchmark and universe."""
+
+    def __init__(self, redis_client: EventBus, benchmark: str = "BTCUSDT"):
+        """Initialize tracker.
+
+        Args:
+            redis_client: Redis client for the report cache
+            benchmark: Symbol every other symbol is compared against
+        """
+        self.redis_client = redis_client
+        self.benchmark = benchmark
+        self.blocks: dict[str, dict] = {}
+
+    def get(self, symbol: str) -> Optional[dict]:
+        """Latest relative_strength block for a symbol, or None."""
+        return self.blocks.get(symbol)
+
+    def refresh(self, now_ms: Optional[int] = None) -> dict[str, dict]:
+        """Recompute every symbol's block.
+
+        Returns:
+            Blocks by symbol (empty if fewer than two symbols have a performance)
+        """
+        now_ms = now_ms if now_ms is not None else int(time.time() * 1000)
+        try:
+            performance = self._performance(now_ms)
+        except (RedisError, ValueError) as e:
+            logger.warning("relative_strength_failed", error=str(e))
+            return self.blocks
+
+        ranked = sorted(
+            (symbol for symbol, pct in performance.items() if pct is not None),
+            key=lambda symbol: performance[symbol],
+            reverse=True,
+        )
+        if len(ranked) < 2:
+            self.blocks = {}
+            return self.blocks
+
+        median = statistics.median(performance[symbol] for symbol in ranked)
+        benchmark_pct = performance.get(self.benchmark)
+        rank = {symbol: i + 1 for i, symbol in enumerate(ranked)}
+
+        def diff(pct: Optional[float], reference: Optional[float]) -> Optional[float]:
+            return round(pct - reference, 4) if pct is not None and reference is not None else None
+
+        self.blocks = {
+            symbol: {
+                "window_sec": WINDOW_SEC,
+                "performance_pct": round(pct, 4) if pct is not None else None,
+                "benchmark": self.benchmark,
+                "benchmark_performance_pct": round(benchmark_pct, 4) if benchmark_pct is not None else None,
+                "vs_benchmark_pct": diff(pct, benchmark_pct),
+                "universe_median_pct": round(median, 4),
+                "vs_median_pct": diff(pct, median),
+                "rank": rank.get(symbol),
+                "universe_size": len(ranked),
+                "updatedAt": now_ms,
+            }
+            for symbol, pct in performance.items()
+        }
+        return self.blocks
+
+    def _performance(self, now_ms: int) -> dict[str, Optional[float]]:
+        """24h change in percent per published symbol (None without a digest from 24h ago)."""
+        symbols = self.redis_client.zrangebyscore(REPORT_INDEX_KEY, now_ms - MAX_REPORT_AGE_MS, "+inf")
+        if not symbols:
+            return {}
+
+        minute_ms = (now_ms - WINDOW_SEC * 1000) // 60000 * 60000
+        minutes = [minute_label(minute_ms + i * 60000) for i in range(DIGEST_SEARCH_MINUTES)]
+
+        pipe = self.redis_client.pipeline(transaction=False)
+        for symbol in symbols:
+            pipe.hgetall(f"{REPORT_KEY_PREFIX}{symbol}")
+            for minute in minutes:
+                pipe.get(f"{DIGEST_KEY_PREFIX}{symbol}:{minute}")
+        values = pipe.execute()
+
+        performance: dict[str, Optional[float]] = {}
+        stride = 1 + len(minutes)
+        for i, symbol in enumerate(symbols):
+            report_fields, *raw_digests = values[i * stride:(i + 1) * stride]
+            report = decode_report(report_fields, slow=False)
+            if not report:
+                continue
+            last_price = report.get("last_price")
+            close = None
+            for raw in raw_digests:
+                ohlc = json.loads(raw).get("ohlc") if raw else None
+                if ohlc and ohlc.get("close"):
+                    close = ohlc["close"]
+                    break
+            performance[symbol] = (
+                (last_price / close - 1) * 100 if close and isinstance(last_price, (int, float)) else None
+            )
+        return performance
@@ -0,0 +1,117 @@
+"""Write-through mirroring of reports to a secondary Redis (another region).
+
+With NT_SECONDARY_REDIS_URL set, every report successfully published to
+the primary Redis is queued for the secondary endpoint, so an MCP server
+in the other region reads report:{symbol} locally instead of across
+regions. Mirroring is asynchronous and never slows or fails the primary
+publish: it runs on its own ReportPublishQueue (latest report per symbol and cycle,
+superseded reports dropped) with the same HSET / PUBLISH / index commands.
+
+Metrics:
+    nt_secondary_publish_lag_ms{symbol}       report updatedAt to secondary write
+    nt_secondary_publish_failures_total       failed secondary writes per symbol
+    nt_secondary_publish_healthy              0 after NT_SECONDARY_ALARM_FAILURES
+                                              consecutive failures, 1 once a write succeeds
+
+Only reports are mirrored; footprint, trades and book history stay on
+the primary.
+"""
+import threading
+import time
+from typing import Optional
+import structlog
+
+from ..event_bus import EventBus
+from .publish_queue import PublishCallback, ReportPublishQueue
+
+logger = structlog.get_logger()
+
+
+class SecondaryPublisher:
+    """Mirrors reports published to the primary onto a secondary Redis."""
+
+    def __init__(
+        self,
+        redis_client: EventBus,
+        workers: int = 2,
+        alarm_after: int = 5,
+        metrics=None
+    ):
+        """Start the mirroring queue.
+
+        Args:
+            redis_client: Client for the secondary Redis
+            workers: Publish worker threads for the secondary
+            alarm_after: Consecutive failed writes before the endpoint is reported unhealthy
+            metrics: Optional PrometheusMetrics for lag, failure and health metrics
+        """
+        self.alarm_after = alarm_after
+        self.metrics = metrics
+        self.queue = ReportPublishQueue(redis_client=redis_client, workers=workers)
+
+        self._lock = threading.Lock()
+        self._consecutive_failures = 0
+        self._alarmed = False
+        if self.metrics:
+            self.metrics.secondary_publish_healthy.set(1)
+
+    def chain(self, symbol: str, report: dict, on_done: Optional[PublishCallback]) -> PublishCallback:
+        """Wrap a primary publish callback so successful publishes are mirrored."""
+
+        def mirrored(success: bool, publish_ms: float) -> None:
+            if on_done:
+                on_done(success, publish_ms)
+            if success:
+                self.submit(symbol, report)
+
+        return mirrored
+
+    def submit(self, symbol: str, report: dict) -> None:
+        """Queue a report for the secondary (superseding any pending one)."""
+        # Own timings dict: the secondary queue stamps its own queue_ms
+        report = dict(report)
+        if isinstance(report.get("timings"), dict):
+            report["timings"] = dict(report["timings"])
+
+        updated_at = report.get("updatedAt")
+        queued = self.queue.submit(
+            symbol, report,
+            on_done=lambda success, publish_ms: self._on_done(symbol, updated_at, success)
+        )
+        if not queued and self.metrics:
+            self.metrics.reports_dropped.labels(symbol=symbol, reason="secondary_superseded").inc()
+
+    def _on_done(self, symbol: str, updated_at: Optional[int], success: bool) -> None:
+        with self._lock:
+            if success:
+                recovered = self._alarmed
+                self._consecutive_failures = 0
+                self._alarmed = False
+                alarm = False
+            else:
+                self._consecutive_failures += 1
+                recovered = False
+                alarm = not self._alarmed and self._consecutive_failures >= self.alarm_after
+                self._alarmed = self._alarmed or alarm
+            failures = self._consecutive_failures
+
+        if success:
+            if self.metrics and isinstance(updated_at, (int, float)):
+                lag_ms = max(0.0, time.time() * 1000 - updated_at)
+                self.metrics.secondary_publish_lag.labels(symbol=symbol).observe(lag_ms)
+            if recovered:
+                logger.info("secondary_publish_recovered", symbol=symbol)
+                if self.metrics:
+                    self.metrics.secondary_publish_healthy.set(1)
+            return
+
+        if self.metrics:
+            self.metrics.secondary_publish_failures.labels(symbol=symbol).inc()
+        if alarm:
+            logger.error("secondary_publish_failing", consecutive_failures=failures, symbol=symbol)
+            if self.metrics:
+                self.metrics.secondary_publish_healthy.set(0)
+
+    def close(self, timeout_sec: float = 5.0) -> None:
+        """Publish what is pending and stop the workers."""
+        self.queue.close(timeout_sec)
@@ -0,0 +1,261 @@
+"""Discrete signal events (signals:{venue} Redis Stream).
+
+Reports are state; a trading system that wants to act on changes would
+have to diff consecutive reports. Every slow cycle the producer compares
+each symbol's enriched report with the previous one and appends what
+changed to the venue's signals stream:
+
+- anomaly_started / anomaly_ended: an anomaly (by type, and side and price
+  for order-level anomalies) appeared or went away
+- health_changed: the health score crossed into another state (healthy
+  from 80, degraded from 50, critical below)
+- wall_appeared / wall_absorbed / wall_removed: a liquidity wall appeared,
+  or went away after trades at or through its price (absorbed) or without
+  (removed, i.e. pulled)
+
+Anomalies and walls only end after missing from END_AFTER_CYCLES
+consecutive cycles, so one cycle's flicker doesn't produce a start/end
+pair. A detector storm is capped by the anomaly budget: past
+NT_ANOMALY_BUDGET_PER_HOUR anomaly_started signals of one type for one
+symbol within a clock hour, further starts (and their ends) are suppressed,
+and the next one let through carries suppressed_count, the number
+suppressed since the last one. Entries carry one JSON field, like market
+events on the input stream:
+
+    XADD signals:BINANCE * data '{"signal": "wall_absorbed", "symbol": "BTCUSDT",
+        "venue": "BINANCE", "ts": 1767225600000, "side": "bid", "price": 49990.0,
+        "quantity": 42.5}'
+
+The stream is capped at NT_SIGNALS_MAXLEN entries (approximate trimming).
+Threshold crossings of MCP watch registrations (threshold_crossed) are
+appended to the same stream by thresholds.py.
+"""
+import json
+import time
+from dataclasses import dataclass, field
+from typing import Optional
+
+from redis import RedisError
+import structlog
+
+from ..event_bus import EventBus
+from ..state.symbol_state import TradeTick
+
+logger = structlog.get_logger()
+
+STREAM_PREFIX = "signals:"
+
+# Health score from which each state starts, best first
+HEALTH_STATES = (("healthy", 80), ("degraded", 50), ("critical", 0))
+
+# Consecutive slow cycles an anomaly or wall must be missing before it ends
+END_AFTER_CYCLES = 2
+
+HOUR_MS = 3_600_000
+
+
+def health_state(score: float) -> str:
+    """healthy, degraded or critical for a health score."""
+    for state, threshold in HEALTH_STATES:
+        if score >= threshold:
+            return state
+    return HEALTH_STATES[-1][0]
+
+
+def _anomaly_key(anomaly: dict) -> tuple:
+    return anomaly.get("type"), anomaly.get("side"), anomaly.get("price")
+
+
+def _absorbed(wall: dict, trades: list[TradeTick], since_ms: int) -> bool:
+    """Whether trades since the wall appeared reached its price."""
+    for trade in trades:
+        if trade.timestamp.timestamp() * 1000 < since_ms:
+            continue
+        if wall["side"] == "bid" and trade.price <= wall["price"]:
+            return True
+        if wall["side"] == "ask" and trade.price >= wall["price"]:
+            return True
+    return False
+
+
+@dataclass
+class _Tracked:
+    """An active anomaly or wall."""
+    item: dict
+    since_ms: int
+    missing: int = 0
+    suppressed: bool = False  # Start withheld by the anomaly budget, so the end is too
+
+
+@dataclass
+class _Budget:
+    hour: int
+    emitted: int = 0
+    suppressed: int = 0  # Since the last emitted start, carried across hours
+
+
+class AnomalyBudget:
+    """Ceiling on anomaly_started signals per symbol and anomaly type per clock hour."""
+
+    def __init__(self, per_hour: int, metrics=None):
+        """Initialize budget.
+
+        Args:
+            per_hour: Starts let through per symbol and type each hour
+            metrics: Optional PrometheusMetrics for suppression counts
+        """
+        self.per_hour = per_hour
+        self.metrics = metrics
+        self._budgets: dict[tuple[str, str], _Budget] = {}
+
+    def admit(self, symbol: str, anomaly_type: str, now_ms: int) -> tuple[bool, int]:
+        """Whether an anomaly start may be published, and how many were suppressed before it."""
+        hour = now_ms // HOUR_MS
+        budget = self._budgets.get((symbol, anomaly_type))
+        if budget is None:
+            budget = self._budgets[(symbol, anomaly_type)] = _Budget(hour)
+        elif budget.hour != hour:
+            budget.hour, budget.emitted = hour, 0
+
+        if budget.emitted < self.per_hour:
+            budget.emitted += 1
+            suppressed, budget.suppressed = budget.suppressed, 0
+            return True, suppressed
+
+        if not budget.suppressed:
+            logger.warning("anomaly_budget_exhausted", symbol=symbol, type=anomaly_type, per_hour=self.per_hour)
+        budget.suppressed += 1
+        if self.metrics:
+            self.metrics.anomalies_suppressed.labels(symbol=symbol, type=anomaly_type).inc()
+        return False, 0
+
+    def forget(self, symbol: str) -> None:
+        """Drop a symbol's budgets (symbol no longer owned)."""
+        for key in [key for key in self._budgets if key[0] == symbol]:
+            del self._budgets[key]
+
+
+@dataclass
+class _SymbolSignals:
+    anomalies: dict[tuple, _Tracked] = field(default_factory=dict)
+    walls: dict[tuple, _Tracked] = field(default_factory=dict)
+    health: Optional[str] = None
+
+
+class SignalPublisher:
+    """Derives signal events from consecutive reports and appends them to signals:{venue}."""
+
+    def __init__(self, redis_client: EventBus, maxlen: int = 100000, metrics=None, anomaly_budget_per_hour: int = 0):
+        """Initialize publisher.
+
+        Args:
+            redis_client: Redis client for the signals stream
+            maxlen: Approximate cap on each venue's stream length
+            metrics: Optional PrometheusMetrics for signal counts
+            anomaly_budget_per_hour: anomaly_started signals per symbol and type per hour (0 = no limit)
+        """
+        self.redis_client = redis_client
+        self.maxlen = maxlen
+        self.metrics = metrics
+        self.anomaly_budget = AnomalyBudget(anomaly_budget_per_hour, metrics) if anomaly_budget_per_hour else None
+        self._symbols: dict[str, _SymbolSignals] = {}
+
+    def process(self, report: dict, trades: list[TradeTick], now_ms: Optional[int] = None) -> list[dict]:
+        """Compare a symbol's enriched report with its previous one and publish the changes.
+
+        Args:
+            report: Enriched (slow-cycle) report
+            trades: Recent trades of the symbol (decides absorbed vs removed walls)
+            now_ms: Signal time (default: now)
+
+        Returns:
+            Signals published
+        """
+        now_ms = now_ms if now_ms is not None else int(time.time() * 1000)
+        symbol = report["symbol"]
+        tracked = self._symbols.setdefault(symbol, _SymbolSignals())
+        signals = []
+
+        anomalies = {_anomaly_key(a): a for a in report.get("anomalies") or [] if not a.get("synthetic")}
+        for key, anomaly in anomalies.items():
+            if key not in tracked.anomalies:
+                admitted, suppressed = True, 0
+                if self.anomaly_budget:
+                    admitted, suppressed = self.anomaly_budget.admit(symbol, str(anomaly.get("type")), now_ms)
+                if admitted:
+                    signals.append({
+                        "signal": "anomaly_started", **anomaly, **({"suppressed_count": suppressed} if suppressed else {})
+                    })
+                tracked.anomalies[key] = _Tracked(anomaly, now_ms, suppressed=not admitted)
+            else:
+                tracked.anomalies[key].missing = 0
+        for key, active in list(tracked.anomalies.items()):
+            if key in anomalies:
+                continue
+            active.missing += 1
+            if active.missing >= END_AFTER_CYCLES:
+                del tracked.anomalies[key]
+                if active.suppressed:
+                    continue
+                signals.append({
+                    "signal": "anomaly_ended",
+                    **{k: active.item[k] for k in ("type", "side", "price") if k in active.item},
+                    "duration_ms": now_ms - active.since_ms,
+                })
+
+        walls = {(w["side"], w["price"]): w for w in (report.get("liquidity") or {}).get("walls") or []}
+        for key, wall in walls.items():
+            if key not in tracked.walls:
+                signals.append({"signal": "wall_appeared", **{k: wall.get(k) for k in (
+                    "side", "price", "quantity", "notional_usd", "severity", "distance_bps")}})
+                tracked.walls[key] = _Tracked(wall, now_ms)
+            else:
+                tracked.walls[key].item = wall
+                tracked.walls[key].missing = 0
+        for key, active in list(tracked.walls.items()):
+            if key in walls:
+                continue
+            active.missing += 1
+            if active.missing >= END_AFTER_CYCLES:
+                del tracked.walls[key]
+                signals.append({
+                    "signal": "wall_absorbed" if _absorbed(active.item, trades, active.since_ms) else "wall_removed",
+                    "side": active.item["side"],
+                    "price": active.item["price"],
+                    "quantity": active.item.get("quantity"),
+                    "duration_ms": now_ms - active.since_ms,
+                })
+
+        score = (report.get("health") or {}).get("score")
+        if isinstance(score, (int, float)):
+            state = health_state(score)
+            if tracked.health is not None and state != tracked.health:
+                signals.append({"signal": "health_changed", "from": tracked.health, "to": state, "score": score})
+            tracked.health = state
+
+        venue = report.get("venue") or "UNKNOWN"
+        signals = [{**signal, "symbol": symbol, "venue": venue, "ts": now_ms} for signal in signals]
+        if signals:
+            self._publish(venue, signals)
+        return signals
+
+    def forget(self, symbol: str) -> None:
+        """Drop a symbol's tracked state (symbol no longer owned)."""
+        self._symbols.pop(symbol, None)
+        if self.anomaly_budget:
+            self.anomaly_budget.forget(symbol)
+
+    def _publish(self, venue: str, signals: list[dict]) -> None:
+        stream = f"{STREAM_PREFIX}{venue}"
+        try:
+            pipe = self.redis_client.pipeline(transaction=False)
+            for signal in signals:
+                pipe.xadd(stream, {"data": json.dumps(signal, separators=(",", ":"))}, maxlen=self.maxlen, approximate=True)
+            pipe.execute()
+        except RedisError as e:
+            logger.warning("signals_publish_failed", stream=stream, count=len(signals), error=str(e))
+            return
+
+        if self.metrics:
+            for signal in signals:
+                self.metrics.signals_published.labels(symbol=signal["symbol"], signal=signal["signal"]).inc()
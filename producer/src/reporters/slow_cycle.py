@@ -13,21 +13,24 @@ from src.calculators.liquidity import (
     detect_liquidity_walls,
     detect_liquidity_vacuums
 )
-from src.calculators.anomalies import (
-    detect_spoofing,
-    detect_iceberg,
-    detect_flash_crash_risk,
-    calculate_flow_acceleration
-)
-from src.calculators.spread import calculate_mid_price
-from src.calculators.depth import calculate_depth_metrics
+from src.calculators.footprint import calculate_footprint
+from src.calculators.anomalies import detect_clock_skew
+from src.reporters.detectors import DETECTORS, DetectorInputs, run_detectors
+from src.reporters.provenance import merge_provenance, slow_provenance
 
 logger = structlog.get_logger()
 
 
 def calculate_slow_metrics(
     state: SymbolState,
-    tick_size: float = 0.01
+    tick_size: float = 0.01,
+    footprint_window_sec: int = 300,
+    footprint_bucket_bps: float = 1.0,
+    footprint_top_n: int = 5,
+    deferred_detectors: frozenset[str] = frozenset(),
+    detector_budget_ms: float = 50.0,
+    churn_window_sec: int = 10,
+    clock_skew_warn_ms: float = 1000.0
 ) -> dict[str, Any]:
     """Calculate slow-cycle analytics (volume profile, liquidity, anomalies).
 
@@ -36,14 +39,26 @@ def calculate_slow_metrics(
     Args:
         state: SymbolState with order book and trade history
         tick_size: Minimum price increment for volume profile binning
+        footprint_window_sec: Rolling window of the footprint ladder
+        footprint_bucket_bps: Approximate footprint price bucket width
+        footprint_top_n: Most imbalanced footprint levels published in the report
+        deferred_detectors: Detectors run by DeferredDetectorRunner instead of inline
+        detector_budget_ms: Time budget of the inline anomaly detectors
+        churn_window_sec: Window of the book churn and pulled levels fed to spoofing and quote stuffing
+        clock_skew_warn_ms: Venue clock skew above which a clock_skew anomaly is added
 
     Returns:
         Dictionary with slow-cycle metrics:
         {
             "volume_profile": {...},
+            "poc_trend": "up" | "down" | "stable",  # With poc_drift_bps, once 3 POCs are recorded
+            "developing_value_area": {...},
+            "footprint": {...},  # Full ladder; the report carries top_imbalances only
             "liquidity_walls": [...],
             "liquidity_vacuums": [...],
-            "anomalies": [...]
+            "anomalies": [...],
+            "detector_run": DetectorRun,  # Timings, over-budget and skipped detectors
+            "provenance": [...]  # Entries for the sections computed above
         }
     """
     metrics = {
@@ -55,7 +70,7 @@ def calculate_slow_metrics(
 
     try:
         # Calculate volume profile from 30-minute trade window
-        trades_30min = list(state.trade_buffer_30min)
+        trades_30min = state.trade_buffer_30min.get_all()
         if len(trades_30min) >= 10:
             metrics["volume_profile"] = calculate_volume_profile(
                 trades=trades_30min,
@@ -63,75 +78,71 @@ def calculate_slow_metrics(
                 bins_per_tick=5
             )
 
-        # Calculate mid price for anomaly detection
-        mid_price = None
-        if state.best_bid and state.best_ask:
-            mid_price = calculate_mid_price(state.best_bid, state.best_ask)
+        # POC migration over the trend window
+        if metrics["volume_profile"]:
+            now_ms = int(datetime.now(timezone.utc).timestamp() * 1000)
+            state.poc_history.record(now_ms, metrics["volume_profile"]["POC"])
+        drift_bps = state.poc_history.drift_bps()
+        if drift_bps is not None:
+            metrics["poc_trend"] = state.poc_history.trend()
+            metrics["poc_drift_bps"] = round(drift_bps, 2)
+
+        # Developing value area of the current UTC session
+        metrics["developing_value_area"] = state.session_profile.value_area()
+
+        # Buy vs sell executed volume per price level
+        metrics["footprint"] = calculate_footprint(
+            trades=trades_30min,
+            now=datetime.now(timezone.utc),
+            window_sec=footprint_window_sec,
+            bucket_bps=footprint_bucket_bps,
+            top_n=footprint_top_n
+        )
 
-        # Get quantity history for liquidity calculations
-        quantity_history = list(state.quantity_history)
+        # Rolling quantity percentiles for liquidity calculations
+        quantity_sketch = state.quantity_sketch
 
         # Detect liquidity walls
-        if len(quantity_history) >= 10:
+        if quantity_sketch.sample_count >= 10:
             metrics["liquidity_walls"] = detect_liquidity_walls(
                 order_book=state.order_book,
-                quantity_history=quantity_history,
-                side="both"
+                quantity_sketch=quantity_sketch,
+                side="both",
+                notional_tier=state.notional_tier,
+                # Notionals of non-USD quotes without a known rate are taken at par
+                quote_usd_rate=state.quote_usd_rate or 1.0,
+                tick_size=state.meta.tick_size,
+                value_area=(
+                    (metrics["volume_profile"]["VAL"], metrics["volume_profile"]["VAH"])
+                    if metrics["volume_profile"] else None
+                )
             )
 
         # Detect liquidity vacuums
-        if len(quantity_history) >= 10:
+        if quantity_sketch.sample_count >= 10:
             metrics["liquidity_vacuums"] = detect_liquidity_vacuums(
                 order_book=state.order_book,
-                quantity_history=quantity_history,
+                quantity_sketch=quantity_sketch,
                 side="both"
             )
 
-        # Detect anomalies (spoofing, iceberg, flash crash risk)
-        anomalies = []
-
-        if mid_price:
-            # Spoofing detection
-            spoofing = detect_spoofing(
-                order_book=state.order_book,
-                mid_price=mid_price
-            )
-            anomalies.extend(spoofing)
-
-        # Iceberg detection (use 30s trade window)
-        trades_30s = list(state.trade_buffer_30s)
-        if len(trades_30s) >= 5:
-            iceberg = detect_iceberg(
-                trades=trades_30s,
-                order_book=state.order_book
-            )
-            anomalies.extend(iceberg)
-
-        # Flash crash risk detection
-        if state.best_bid and state.best_ask:
-            # Calculate required inputs
-            depth_metrics = calculate_depth_metrics(state)
-            trades_10s = list(state.trade_buffer_10s)
-            flow_acceleration = calculate_flow_acceleration(trades_10s, window_sec=10)
-
-            # Estimate spread in bps
-            spread_bps = 0.0
-            if mid_price and mid_price > 0:
-                spread = state.best_ask.price - state.best_bid.price
-                spread_bps = (spread / mid_price) * 10000
-
-            # Only detect flash crash if we have depth metrics
-            if depth_metrics is not None:
-                flash_crash = detect_flash_crash_risk(
-                    spread_bps=spread_bps,
-                    depth_imbalance=depth_metrics.get("imbalance", 0.0),
-                    flow_acceleration=flow_acceleration
-                )
-
-                if flash_crash:
-                    anomalies.append(flash_crash)
+        # Detect anomalies (spoofing, iceberg, absorption, flash crash risk, quote stuffing) within the
+        # time budget, skipping disabled detectors and those run on the deferred path; none run while
+        # the venue has the market halted or closed, as a frozen book reads as spoofing or vacuums
+        inline = [
+            name for name in DETECTORS
+            if name not in state.disabled_detectors and name not in deferred_detectors
+        ] if state.market_status.is_open else []
+        detector_run = run_detectors(
+            inline, DetectorInputs.from_state(state, churn_window_sec=churn_window_sec), detector_budget_ms
+        )
+        metrics["detector_run"] = detector_run
+        metrics["anomalies"] = detector_run.anomalies
 
-        metrics["anomalies"] = anomalies
+        # Data-quality warning, independent of the detector budget and toggles
+        skew_anomaly = detect_clock_skew(state.clock_skew.skew_ms, clock_skew_warn_ms)
+        if skew_anomaly:
+            metrics["anomalies"].append(skew_anomaly)
 
     except Exception as e:
         logger.error(
@@ -141,6 +152,7 @@ def calculate_slow_metrics(
             error_type=type(e).__name__
         )
 
+    metrics["provenance"] = slow_provenance(state, metrics, footprint_window_sec, churn_window_sec)
     return metrics
 
 
@@ -171,6 +183,20 @@ def enrich_report(
 
         enriched["analytics"]["volume_profile"] = slow_metrics["volume_profile"]
 
+    # POC trend and developing value area also go into analytics
+    for key in ("poc_trend", "poc_drift_bps", "developing_value_area"):
+        if slow_metrics.get(key) is not None:
+            enriched.setdefault("analytics", {})[key] = slow_metrics[key]
+
+    # Footprint summary; the full ladder is served by the get_footprint tool
+    if slow_metrics.get("footprint"):
+        footprint = slow_metrics["footprint"]
+        enriched.setdefault("analytics", {})["footprint"] = {
+            "window_sec": footprint["window_sec"],
+            "bucket_size": footprint["bucket_size"],
+            "top_imbalances": footprint["top_imbalances"],
+        }
+
     # Liquidity features
     if slow_metrics.get("liquidity_walls") or slow_metrics.get("liquidity_vacuums"):
         if "liquidity" not in enriched:
@@ -186,6 +212,10 @@ def enrich_report(
     if slow_metrics.get("anomalies"):
         enriched["anomalies"] = slow_metrics["anomalies"]
 
+    # Provenance of the sections computed by this slow cycle
+    if slow_metrics.get("provenance"):
+        enriched["provenance"] = merge_provenance(enriched.get("provenance", []), slow_metrics["provenance"])
+
     # Update timestamp to reflect enrichment
     enriched["slow_cycle_updated_at"] = int(datetime.now(timezone.utc).timestamp() * 1000)
 
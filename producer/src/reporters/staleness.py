@@ -0,0 +1,80 @@
+"""Staleness sweep for reports the fast cycle has stopped refreshing.
+
+The fast cycle only publishes when it can build a full report, so a feed
+that dies after the book is cleared (or a symbol whose report generation
+keeps failing) leaves the last "ok" report in cache until its TTL expires.
+The sweep re-evaluates ingestion status from the symbol's data age and
+republishes the cached report with the current status, even when no
+events arrive. While the venue has the symbol halted or closed the
+ingestion status is held and only the market status is refreshed.
+"""
+from datetime import datetime, timezone
+from typing import Any, Optional
+
+from src.state.symbol_state import SymbolState
+from src.calculators.health import calculate_health_score
+
+
+def mark_report_stale(
+    report: dict[str, Any],
+    state: SymbolState,
+    now: Optional[datetime] = None
+) -> dict[str, Any]:
+    """Refresh a cached report's freshness fields without new market data.
+
+    Market data sections are left as last published; only updatedAt,
+    data_age_ms, ingestion, market_status and health are re-evaluated.
+
+    Args:
+        report: Last published report for the symbol
+        state: Symbol state holding the ingestion status tracker
+        now: Evaluation time (default: current UTC time)
+
+    Returns:
+        Copy of the report with current ingestion status and health
+    """
+    now = now or datetime.now(timezone.utc)
+    now_ms = int(now.timestamp() * 1000)
+
+    data_age_ms = state.get_data_age_ms()
+    if data_age_ms is None:
+        # No event since startup: age from the last event the report saw
+        last_update = report.get("ingestion", {}).get("last_update")
+        if last_update:
+            last_update_ms = int(datetime.fromisoformat(last_update.replace('Z', '+00:00')).timestamp() * 1000)
+        else:
+            last_update_ms = report.get("updatedAt", now_ms)
+        data_age_ms = max(0, now_ms - last_update_ms)
+
+    market_status = state.market_status
+    if market_status.is_open:
+        state.ingestion.update(data_age_ms, now_ms)
+
+    stale = report.copy()
+    stale["updatedAt"] = now_ms
+    stale["generated_at"] = now.isoformat().replace('+00:00', 'Z')
+    stale["data_age_ms"] = data_age_ms
+    stale["ingestion"] = {
+        **report.get("ingestion", {}),
+        "status": state.ingestion.status,
+        "fresh": data_age_ms <= state.ingestion.thresholds.degraded_ms,
+        **state.ingestion.timeline(now_ms),
+    }
+    stale["market_status"] = market_status.to_dict()
+
+    health_data = calculate_health_score(
+        data_age_ms=data_age_ms,
+        spread_bps=report.get("spread_bps"),
+        imbalance=report.get("depth", {}).get("imbalance"),
+        has_anomalies=bool(report.get("anomalies")),
+        market_state=market_status.state,
+    )
+    health = dict(report.get("health", {}))
+    health["score"] = int(health_data["score"])
+    health["components"] = {
+        **health.get("components", {}),
+        "freshness": float(health_data["score"]),
+    }
+    stale["health"] = health
+
+    return stale
@@ -0,0 +1,233 @@
+"""Threshold-crossing detection for watch registrations.
+
+MCP clients register watches (the watch tool, /v1/stream/watch) with
+rules the producer evaluates on every fast-cycle report of the watched
+symbols. The MCP server keeps registrations in the watch:registrations
+hash, one JSON document per watch id, refreshing expires_at while the
+watch is in use:
+
+    HSET watch:registrations Zk3x9w1Qb0Lr2Aph '{"symbols": ["BTCUSDT"],
+        "rules": [{"type": "price_cross", "level": 50000},
+                  {"type": "imbalance_flip", "min_abs": 0.1},
+                  {"type": "health_below", "threshold": 50}],
+        "expires_at": 1767226200000}'
+
+Rules:
+
+- price_cross: mid_price moved from one side of level to the other
+  (direction up or down)
+- imbalance_flip: depth.imbalance changed sign; values within min_abs
+  (default 0.1) of zero keep the previous sign, so a book hovering around
+  balance doesn't flip on every report
+- health_below: health.score dropped below threshold; it must recover to
+  the threshold before it fires again
+
+The first report after a rule is registered (or after a restart) only sets
+its state. The hash is re-read every NT_WATCH_REFRESH_MS; expired
+registrations are deleted. A crossing is appended to the venue's signals
+stream (when NT_SIGNALS_MAXLEN is on) and to the watch's own
+watch:events:{id} stream, which the MCP server reads:
+
+    XADD watch:events:Zk3x9w1Qb0Lr2Aph * data '{"signal": "threshold_crossed",
+        "watch_id": "Zk3x9w1Qb0Lr2Aph", "rule": {"type": "price_cross", "level": 50000},
+        "field": "mid_price", "from": 49990.5, "to": 50003.0, "direction": "up",
+        "symbol": "BTCUSDT", "venue": "BINANCE", "ts": 1767225600000}'
+
+watch:events:{id} keeps EVENTS_MAXLEN entries and expires with its
+registration.
+"""
+import json
+import math
+import time
+from typing import Any, Optional
+
+from redis import RedisError
+import structlog
+
+from ..event_bus import EventBus
+from .signals import STREAM_PREFIX
+
+logger = structlog.get_logger()
+
+REGISTRY_KEY = "watch:registrations"
+EVENTS_PREFIX = "watch:events:"
+EVENTS_MAXLEN = 1000
+
+# Rule type -> report field it watches
+RULE_FIELDS = {
+    "price_cross": "mid_price",
+    "imbalance_flip": "depth.imbalance",
+    "health_below": "health.score",
+}
+
+DEFAULT_FLIP_MIN_ABS = 0.1
+
+
+def _finite(value: Any) -> bool:
+    return isinstance(value, (int, float)) and not isinstance(value, bool) and math.isfinite(value)
+
+
+def parse_rule(rule: Any) -> Optional[dict]:
+    """A registration rule with defaults filled in, or None if it is invalid."""
+    if not isinstance(rule, dict):
+        return None
+    kind = rule.get("type")
+    if kind == "price_cross" and _finite(rule.get("level")) and rule["level"] > 0:
+        return {"type": kind, "level": float(rule["level"])}
+    if kind == "imbalance_flip":
+        min_abs = rule.get("min_abs", DEFAULT_FLIP_MIN_ABS)
+        if _finite(min_abs) and 0 <= min_abs < 1:
+            return {"type": kind, "min_abs": float(min_abs)}
+    if kind == "health_below" and _finite(rule.get("threshold")) and 0 < rule["threshold"] <= 100:
+        return {"type": kind, "threshold": float(rule["threshold"])}
+    return None
+
+
+def _value(report: dict, field: str) -> Optional[float]:
+    value: Any = report
+    for key in field.split("."):
+        value = value.get(key) if isinstance(value, dict) else None
+    return float(value) if _finite(value) else None
+
+
+def _side(rule: dict, value: float, previous_side: Any) -> Any:
+    """State of a rule for a value: above level, sign of imbalance, or below threshold."""
+    if rule["type"] == "price_cross":
+        return value >= rule["level"]
+    if rule["type"] == "imbalance_flip":
+        if abs(value) < rule["min_abs"] or value == 0:
+            return previous_side
+        return value > 0
+    return value < rule["threshold"]
+
+
+class ThresholdDetector:
+    """Evaluates registered watch rules on reports and publishes their crossings."""
+
+    def __init__(self, redis_client: EventBus, signals_maxlen: int = 100000, metrics=None):
+        """Initialize detector.
+
+        Args:
+            redis_client: Redis client holding the registrations and event streams
+            signals_maxlen: Approximate cap of signals:{venue} (0 = don't write crossings there)
+            metrics: Optional PrometheusMetrics for crossing and registration counts
+        """
+        self.redis_client = redis_client
+        self.signals_maxlen = signals_maxlen
+        self.metrics = metrics
+        self._registrations: dict[str, dict] = {}
+        # symbol -> [(watch id, rule index, rule)]
+        self._by_symbol: dict[str, list[tuple[str, int, dict]]] = {}
+        # (watch id, rule index, symbol) -> (rule state, value)
+        self._states: dict[tuple[str, int, str], tuple[Any, float]] = {}
+
+    def refresh(self, now_ms: Optional[int] = None) -> None:
+        """Reload registrations, deleting expired and invalid ones."""
+        now_ms = now_ms if now_ms is not None else int(time.time() * 1000)
+        registrations, dead = {}, []
+        for watch_id, raw in self.redis_client.hgetall(REGISTRY_KEY).items():
+            try:
+                registration = json.loads(raw)
+                expires_at = int(registration["expires_at"])
+                symbols = [s for s in registration["symbols"] if isinstance(s, str)]
+                rules = [parse_rule(rule) for rule in registration["rules"]]
+            except (ValueError, TypeError, KeyError):
+                rules, expires_at, symbols = [None], 0, []
+            if expires_at <= now_ms or None in rules:
+                dead.append(watch_id)
+                continue
+            registrations[watch_id] = {"symbols": symbols, "rules": rules, "expires_at": expires_at}
+        if dead:
+            self.redis_client.hdel(REGISTRY_KEY, *dead)
+            logger.info("watch_registrations_expired", count=len(dead))
+
+        by_symbol: dict[str, list[tuple[str, int, dict]]] = {}
+        for watch_id, registration in registrations.items():
+            for symbol in registration["symbols"]:
+                for index, rule in enumerate(registration["rules"]):
+                    by_symbol.setdefault(symbol, []).append((watch_id, index, rule))
+
+        # Rules re-registered under the same id keep their state only if unchanged
+        self._states = {
+            key: state for key, state in self._states.items()
+            if key[0] in registrations and self._registrations.get(key[0]) is not None
+            and registrations[key[0]]["rules"] == self._registrations[key[0]]["rules"]
+        }
+        self._registrations = registrations
+        self._by_symbol = by_symbol
+        if self.metrics:
+            self.metrics.watch_registrations.set(len(registrations))
+
+    def process(self, report: dict, now_ms: Optional[int] = None) -> list[dict]:
+        """Evaluate the rules watching a report's symbol and publish crossings.
+
+        Returns:
+            Crossings published
+        """
+        symbol = report.get("symbol")
+        watched = self._by_symbol.get(symbol)
+        if not watched:
+            return []
+        now_ms = now_ms if now_ms is not None else int(time.time() * 1000)
+        venue = report.get("venue") or "UNKNOWN"
+
+        events = []
+        for watch_id, index, rule in watched:
+            field = RULE_FIELDS[rule["type"]]
+            value = _value(report, field)
+            if value is None:
+                continue
+            key = (watch_id, index, symbol)
+            previous = self._states.get(key)
+            previous_side, previous_value = previous if previous else (None, None)
+            side = _side(rule, value, previous_side)
+            self._states[key] = (side, value)
+            if previous is None or previous_side is None or side is None or side == previous_side:
+                continue
+            if rule["type"] == "health_below" and not side:
+                continue  # Recovered: re-armed, not a crossing
+            if rule["type"] == "health_below":
+                direction = "down"
+            else:
+                direction = "up" if side else "down"
+            events.append({
+                "signal": "threshold_crossed",
+                "watch_id": watch_id,
+                "rule": rule,
+                "field": field,
+                "from": previous_value,
+                "to": value,
+                "direction": direction,
+                "symbol": symbol,
+                "venue": venue,
+                "ts": now_ms,
+            })
+
+        if events:
+            self._publish(venue, events, now_ms)
+        return events
+
+    def forget(self, symbol: str) -> None:
+        """Drop a symbol's rule states (symbol no longer owned)."""
+        self._states = {key: state for key, state in self._states.items() if key[2] != symbol}
+
+    def _publish(self, venue: str, events: list[dict], now_ms: int) -> None:
+        try:
+            pipe = self.redis_client.pipeline(transaction=False)
+            for event in events:
+                data = {"data": json.dumps(event, separators=(",", ":"))}
+                if self.signals_maxlen:
+                    pipe.xadd(f"{STREAM_PREFIX}{venue}", data, maxlen=self.signals_maxlen, approximate=True)
+                stream = f"{EVENTS_PREFIX}{event['watch_id']}"
+                pipe.xadd(stream, data, maxlen=EVENTS_MAXLEN, approximate=True)
+                pipe.pexpire(stream, max(self._registrations[event["watch_id"]]["expires_at"] - now_ms, 1000))
+            pipe.execute()
+        except RedisError as e:
+            logger.warning("threshold_publish_failed", venue=venue, count=len(events), error=str(e))
+            return
+
+        if self.metrics:
+            for event in events:
+                self.metrics.thresholds_crossed.labels(symbol=event["symbol"], rule=event["rule"]["type"]).inc()
+                if self.signals_maxlen:
+                    self.metrics.signals_published.labels(symbol=event["symbol"], signal=event["signal"]).inc()
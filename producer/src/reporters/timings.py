@@ -0,0 +1,39 @@
+"""Latency breakdown published in each report's timings section.
+
+A report can't carry the duration of its own Redis write, so publish_ms
+is the write time of the previous report for the symbol; queue_ms is
+stamped by the publish worker when it claims the report. venue_lag_ms is
+the newest event's venue-to-receipt delay with the clock skew removed
+(see state/clock_skew.py), so it stays meaningful when clocks disagree.
+"""
+from typing import Optional
+
+from ..state.symbol_state import SymbolState
+
+
+def build_timings(state: SymbolState, processing_ms: float) -> dict:
+    """Timings for a report built from the symbol's current state.
+
+    Args:
+        state: Symbol state (consume lag, clock skew and last publish time)
+        processing_ms: Time spent generating the report
+    """
+    return {
+        "consume_lag_ms": _round(state.consume_lag_ms),
+        "venue_lag_ms": _round(state.clock_skew.lag_ms()),
+        "clock_skew_ms": _round(state.clock_skew.skew_ms),
+        "processing_ms": round(processing_ms, 3),
+        "queue_ms": None,
+        "publish_ms": _round(state.last_publish_ms),
+    }
+
+
+def stamp_queue_time(report: dict, queue_ms: float) -> None:
+    """Record time spent waiting in the publish queue, if the report has timings."""
+    timings = report.get("timings")
+    if isinstance(timings, dict):
+        timings["queue_ms"] = round(queue_ms, 3)
+
+
+def _round(value: Optional[float]) -> Optional[float]:
+    return round(value, 3) if value is not None else None
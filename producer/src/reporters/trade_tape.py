@@ -0,0 +1,25 @@
+"""Recent trades (the raw tape) for reports and the get_trades tool.
+
+Each symbol keeps its last NT_TRADE_TAPE_SIZE trades in a ring buffer.
+The slow cycle publishes the whole tape to trades:{symbol} for the MCP
+server's get_trades tool, and reports carry the last
+NT_REPORT_RECENT_TRADES of them as recent_trades when that is non-zero.
+"""
+from typing import Iterable
+
+from ..state.symbol_state import TradeTick
+
+KEY_PREFIX = "trades:"
+
+
+def serialize_trades(trades: Iterable[TradeTick]) -> list[dict]:
+    """Tape entries, oldest first: {"price", "size", "side": "buy" | "sell", "time"}."""
+    return [
+        {
+            "price": trade.price,
+            "size": trade.volume,
+            "side": trade.aggressor_side.lower(),
+            "time": trade.timestamp.isoformat().replace('+00:00', 'Z'),
+        }
+        for trade in trades
+    ]
@@ -0,0 +1,157 @@
+"""Venue-level market stress index (venue_report:{venue}).
+
+Single-symbol reports don't show whether trouble is local or venue-wide.
+Every NT_VENUE_REPORT_INTERVAL_MS the producer reads the cached reports of
+every symbol in reports:index (all nodes' symbols, not only its own), groups
+them by venue and scores how many are under stress:
+
+- wide_spread: spread_bps at least NT_VENUE_SPREAD_WIDEN_FACTOR times the
+  symbol's own baseline (an exponential average with a 30 minute half-life)
+- degraded_ingestion: ingestion status not ok
+- flash_crash_risk: a flash_crash_risk anomaly in the report
+
+stress_index (0-100) is the weighted share of stressed symbols (STRESS_WEIGHTS)
+and level is calm below 20, elevated below 50, stressed from 50:
+
+    {"venue": "BINANCE", "updatedAt": ..., "symbols": 12, "stress_index": 8.3,
+     "level": "calm", "components": {"wide_spread_pct", "degraded_ingestion_pct",
+     "flash_crash_risk_pct"}, "stressed_symbols": {"wide_spread": [...], ...}}
+
+Reports not updated within MAX_REPORT_AGE_MS (symbols no longer published)
+are left out. The key expires after a few intervals, so a stopped producer
+doesn't leave a stale status behind; the MCP get_venue_status tool reads it.
+"""
+import json
+import math
+import time
+from typing import Optional
+
+from redis import RedisError
+import structlog
+
+from ..event_bus import EventBus
+from .redis_cache import REPORT_INDEX_KEY, REPORT_KEY_PREFIX, decode_report
+
+logger = structlog.get_logger()
+
+KEY_PREFIX = "venue_report:"
+
+# Weight of each stress component in stress_index (sum to 1)
+STRESS_WEIGHTS = {"wide_spread": 0.4, "degraded_ingestion": 0.35, "flash_crash_risk": 0.25}
+
+# stress_index at which the level becomes elevated / stressed
+ELEVATED_INDEX = 20.0
+STRESSED_INDEX = 50.0
+
+# Reports older than this are from symbols no longer published
+MAX_REPORT_AGE_MS = 60_000
+
+# Half-life of the per-symbol spread baseline
+BASELINE_HALF_LIFE_SEC = 1800
+
+
+def stress_level(stress_index: float) -> str:
+    """calm, elevated or stressed for a stress index."""
+    if stress_index >= STRESSED_INDEX:
+        return "stressed"
+    if stress_index >= ELEVATED_INDEX:
+        return "elevated"
+    return "calm"
+
+
+class VenueStressMonitor:
+    """Scores venue-wide stress from the cached reports of every tracked symbol."""
+
+    def __init__(self, redis_client: EventBus, interval_ms: int = 5000, widen_factor: float = 3.0, metrics=None):
+        """Initialize monitor.
+
+        Args:
+            redis_client: Redis client for the report cache
+            interval_ms: Milliseconds between runs (sets the baseline decay and key expiry)
+            widen_factor: Spread multiple of the symbol's baseline counted as widened
+            metrics: Optional PrometheusMetrics for the stress index gauge
+        """
+        self.redis_client = redis_client
+        self.interval_ms = interval_ms
+        self.widen_factor = widen_factor
+        self.metrics = metrics
+        self.ttl_ms = interval_ms * 5
+        # Weight of each new spread sample in the baseline
+        self._alpha = 1 - math.exp(-math.log(2) * interval_ms / 1000 / BASELINE_HALF_LIFE_SEC)
+        self._baselines: dict[str, float] = {}
+
+    def run_once(self, now_ms: Optional[int] = None) -> dict[str, dict]:
+        """Score and publish every venue.
+
+        Returns:
+            Venue reports by venue
+        """
+        now_ms = now_ms if now_ms is not None else int(time.time() * 1000)
+        try:
+            reports = self._read_reports(now_ms)
+        except (RedisError, ValueError) as e:
+            logger.warning("venue_report_failed", error=str(e))
+            return {}
+
+        by_venue: dict[str, list[dict]] = {}
+        for report in reports:
+            by_venue.setdefault(report.get("venue") or "UNKNOWN", []).append(report)
+
+        venue_reports = {venue: self._score(venue, symbol_reports, now_ms) for venue, symbol_reports in by_venue.items()}
+        try:
+            pipe = self.redis_client.pipeline(transaction=False)
+            for venue, venue_report in venue_reports.items():
+                pipe.set(f"{KEY_PREFIX}{venue}", json.dumps(venue_report, separators=(",", ":")), px=self.ttl_ms)
+            pipe.execute()
+        except RedisError as e:
+            logger.warning("venue_report_failed", error=str(e))
+            return {}
+
+        if self.metrics:
+            for venue, venue_report in venue_reports.items():
+                self.metrics.venue_stress_index.labels(venue=venue).set(venue_report["stress_index"])
+        return venue_reports
+
+    def _read_reports(self, now_ms: int) -> list[dict]:
+        symbols = self.redis_client.zrangebyscore(REPORT_INDEX_KEY, now_ms - MAX_REPORT_AGE_MS, "+inf")
+        if not symbols:
+            return []
+        pipe = self.redis_client.pipeline(transaction=False)
+        for symbol in symbols:
+            pipe.hgetall(f"{REPORT_KEY_PREFIX}{symbol}")
+        reports = [decode_report(fields) for fields in pipe.execute()]
+        return [report for report in reports if report]
+
+    def _score(self, venue: str, reports: list[dict], now_ms: int) -> dict:
+        stressed: dict[str, list[str]] = {component: [] for component in STRESS_WEIGHTS}
+
+        for report in sorted(reports, key=lambda r: r["symbol"]):
+            symbol = report["symbol"]
+            spread_bps = report.get("spread_bps")
+            if isinstance(spread_bps, (int, float)):
+                baseline = self._baselines.get(symbol)
+                if baseline is not None and baseline > 0 and spread_bps >= baseline * self.widen_factor:
+                    stressed["wide_spread"].append(symbol)
+                    # A widened spread only nudges the baseline, so a long episode stays visible
+                    self._baselines[symbol] = baseline + self._alpha * (spread_bps - baseline) / self.widen_factor
+                else:
+                    self._baselines[symbol] = spread_bps if baseline is None else baseline + self._alpha * (spread_bps - baseline)
+
+            if (report.get("ingestion") or {}).get("status") != "ok":
+                stressed["degraded_ingestion"].append(symbol)
+            if any(a.get("type") == "flash_crash_risk" for a in report.get("anomalies") or []):
+                stressed["flash_crash_risk"].append(symbol)
+
+        count = len(reports)
+        components = {f"{name}_pct": round(len(symbols) / count * 100, 2) for name, symbols in stressed.items()}
+        stress_index = round(sum(len(stressed[name]) / count * 100 * weight for name, weight in STRESS_WEIGHTS.items()), 2)
+
+        return {
+            "venue": venue,
+            "updatedAt": now_ms,
+            "symbols": count,
+            "stress_index": stress_index,
+            "level": stress_level(stress_index),
+            "components": components,
+            "stressed_symbols": stressed,
+        }
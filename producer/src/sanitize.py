@@ -0,0 +1,248 @@
+"""Sanity checks on market event values, with a quarantine stream for rejects.
+
+Venue payloads are parsed with float(), which happily returns nan, inf,
+1e308 or a negative price; without a check those flow into the published
+stream, the calculators and the cached reports. Every market event is
+checked where it enters the producer:
+
+- envelopes: symbol, venue, type and ts_event must be strings (ts_event
+  RFC3339) and payload an object. Unknown fields are tolerated and passed on.
+- payload values, by field name wherever they appear (so both the
+  NautilusTrader and the simple producer payloads are covered): prices
+  must be finite, > 0 and <= MAX_PRICE; sizes finite, >= 0 and <= MAX_QTY;
+  signed values (price_change_pct) finite. Booleans and non-numeric strings
+  are rejected. Unknown fields may hold anything but a non-finite float,
+  which would make the published JSON invalid.
+
+A bad scalar rejects the whole event. Bad entries of a level list (bids,
+asks, deltas) are dropped and the rest of the event goes on. Either way the
+original is quarantined: appended to the DLQ stream (NT_DLQ_STREAM, default
+dlq:market_events, capped at NT_DLQ_MAXLEN) on the Redis of the component
+that rejected it, as
+
+    {"source": "publisher", "symbol": "BTCUSDT", "type": "trade_tick",
+     "problems": ["payload.price:non_positive"], "event": {...},
+     "quarantined_at": "2026-10-16T12:00:00.000125Z"}
+
+and counted in nt_events_sanitized_total{source,reason}.
+"""
+import json
+import math
+from datetime import datetime, timezone
+from typing import Any, Optional
+
+import structlog
+
+log = structlog.get_logger()
+
+DEFAULT_DLQ_STREAM = "dlq:market_events"
+DEFAULT_DLQ_MAXLEN = 10000
+
+# Far above any listed spot price or order size; beyond them a value is a parsing or venue fault
+MAX_PRICE = 1e9
+MAX_QTY = 1e12
+
+PRICE_FIELDS = frozenset({"price", "bid_price", "ask_price", "last_price", "high_24h", "low_24h"})
+QTY_FIELDS = frozenset({"size", "qty", "bid_size", "ask_size", "volume_24h"})
+SIGNED_FIELDS = frozenset({"price_change_pct"})
+# [price, qty] pairs, or lists of them
+LEVEL_FIELDS = frozenset({"bids", "asks", "best_bid", "best_ask"})
+ENVELOPE_FIELDS = ("symbol", "venue", "type", "ts_event")
+
+# Book delta actions without a meaningful price (NautilusTrader BookAction)
+_PRICELESS_ACTIONS = frozenset({"CLEAR"})
+
+REASONS = ("malformed", "not_a_number", "non_finite", "non_positive", "negative", "out_of_range")
+
+
+def _number(value: Any) -> tuple[Optional[float], Optional[str]]:
+    if isinstance(value, bool) or not isinstance(value, (int, float, str)):
+        return None, "not_a_number"
+    try:
+        number = float(value)
+    except ValueError:
+        return None, "not_a_number"
+    if not math.isfinite(number):
+        return None, "non_finite"
+    return number, None
+
+
+def price_problem(value: Any) -> Optional[str]:
+    """Why value isn't a plausible price, None if it is."""
+    number, problem = _number(value)
+    if problem:
+        return problem
+    if number <= 0:
+        return "non_positive"
+    return "out_of_range" if number > MAX_PRICE else None
+
+
+def qty_problem(value: Any) -> Optional[str]:
+    """Why value isn't a plausible size (0 allowed: a removed level), None if it is."""
+    number, problem = _number(value)
+    if problem:
+        return problem
+    if number < 0:
+        return "negative"
+    return "out_of_range" if number > MAX_QTY else None
+
+
+def level_problem(level: Any, priceless: bool = False) -> Optional[str]:
+    """Why a [price, qty] pair or a {price, size} delta is bad, None if it isn't."""
+    if isinstance(level, dict):
+        priceless = priceless or level.get("action") in _PRICELESS_ACTIONS
+        price, qty = level.get("price"), level.get("size", level.get("qty", 0))
+    elif isinstance(level, (list, tuple)) and len(level) == 2:
+        price, qty = level
+    else:
+        return "malformed"
+    return (None if priceless else price_problem(price)) or qty_problem(qty)
+
+
+def _has_non_finite(value: Any) -> bool:
+    if isinstance(value, float):
+        return not math.isfinite(value)
+    if isinstance(value, dict):
+        return any(_has_non_finite(item) for item in value.values())
+    if isinstance(value, (list, tuple)):
+        return any(_has_non_finite(item) for item in value)
+    return False
+
+
+def _scalar_problem(name: str, value: Any) -> Optional[str]:
+    if name in PRICE_FIELDS:
+        return price_problem(value)
+    if name in QTY_FIELDS:
+        return qty_problem(value)
+    if name in SIGNED_FIELDS:
+        return _number(value)[1]
+    return "non_finite" if _has_non_finite(value) else None
+
+
+def sanitize_payload(payload: dict[str, Any]) -> tuple[Optional[dict[str, Any]], list[str]]:
+    """Payload with bad levels dropped, or None if a scalar is bad; plus the problems found."""
+    problems: list[str] = []
+    clean: dict[str, Any] = {}
+    rejected = False
+    for name, value in payload.items():
+        if name in LEVEL_FIELDS and isinstance(value, (list, tuple)) and value and not isinstance(value[0], (list, tuple)):
+            # A single [price, qty] pair (best_bid / best_ask)
+            problem = level_problem(value)
+            if problem:
+                problems.append(f"payload.{name}:{problem}")
+                rejected = True
+            clean[name] = value
+        elif name in LEVEL_FIELDS or name == "deltas":
+            if not isinstance(value, list):
+                problems.append(f"payload.{name}:malformed")
+                rejected = True
+                continue
+            kept = []
+            for index, level in enumerate(value):
+                problem = level_problem(level)
+                if problem:
+                    problems.append(f"payload.{name}[{index}]:{problem}")
+                else:
+                    kept.append(level)
+            clean[name] = kept
+        else:
+            problem = _scalar_problem(name, value)
+            if problem:
+                problems.append(f"payload.{name}:{problem}")
+                rejected = True
+            clean[name] = value
+    return (None if rejected else clean), problems
+
+
+def sanitize_envelope(envelope: Any) -> tuple[Optional[dict[str, Any]], list[str]]:
+    """Envelope with bad levels dropped, or None if it must be rejected; plus the problems found."""
+    if not isinstance(envelope, dict):
+        return None, ["envelope:malformed"]
+    problems = [f"{name}:malformed" for name in ENVELOPE_FIELDS if not isinstance(envelope.get(name), str)]
+    problems += [
+        f"{name}:non_finite"
+        for name, value in envelope.items()
+        if name not in ENVELOPE_FIELDS and name != "payload" and _has_non_finite(value)
+    ]
+    if not problems:
+        try:
+            datetime.fromisoformat(envelope["ts_event"].replace("Z", "+00:00"))
+        except ValueError:
+            problems.append("ts_event:malformed")
+    if not isinstance(envelope.get("payload"), dict):
+        return None, problems + ["payload:malformed"]
+    payload, payload_problems = sanitize_payload(envelope["payload"])
+    problems += payload_problems
+    if payload is None or any(not p.startswith("payload.") for p in problems):
+        return None, problems
+    return {**envelope, "payload": payload}, problems
+
+
+def _jsonable(value: Any) -> Any:
+    """Value with non-finite floats and non-JSON types as strings, so the DLQ entry stays valid JSON."""
+    if isinstance(value, dict):
+        return {str(key): _jsonable(item) for key, item in value.items()}
+    if isinstance(value, (list, tuple)):
+        return [_jsonable(item) for item in value]
+    if isinstance(value, float) and not math.isfinite(value):
+        return str(value)
+    if value is None or isinstance(value, (bool, int, float, str)):
+        return value
+    return str(value)
+
+
+def reason_of(problem: str) -> str:
+    """Counter label of a problem ("payload.price:non_positive" -> "non_positive")."""
+    return problem.rpartition(":")[2]
+
+
+class Quarantine:
+    """Appends rejected events to the DLQ stream and counts them."""
+
+    def __init__(
+        self,
+        redis_client,
+        source: str,
+        stream: str = DEFAULT_DLQ_STREAM,
+        maxlen: int = DEFAULT_DLQ_MAXLEN,
+        metrics=None,
+    ):
+        """Initialize quarantine.
+
+        Args:
+            redis_client: Redis (or in-memory bus) client the DLQ stream lives on
+            source: Component rejecting events (publisher, analytics, simple_producer)
+            stream: DLQ stream key
+            maxlen: Approximate DLQ length cap
+            metrics: Optional PrometheusMetrics for nt_events_sanitized_total
+        """
+        self.redis_client = redis_client
+        self.source = source
+        self.stream = stream
+        self.maxlen = maxlen
+        self.metrics = metrics
+        self.log = log.bind(component="quarantine", source=source, stream=stream)
+
+    def put(self, event: Any, problems: list[str], symbol: str = "", event_type: str = "") -> None:
+        """Quarantine an event (best effort: a failing DLQ write is logged, never raised)."""
+        if self.metrics:
+            for reason in sorted({reason_of(problem) for problem in problems}):
+                self.metrics.events_sanitized.labels(source=self.source, reason=reason).inc()
+        self.log.warning("event_quarantined", symbol=symbol, type=event_type, problems=problems[:10])
+        entry = {
+            "source": self.source,
+            "symbol": symbol,
+            "type": event_type,
+            "problems": problems,
+            "event": _jsonable(event),
+            "quarantined_at": datetime.now(timezone.utc).strftime("%Y-%m-%dT%H:%M:%S.%fZ"),
+        }
+        try:
+            self.redis_client.xadd(
+                self.stream,
+                {"data": json.dumps(entry)},
+                maxlen=self.maxlen or None,
+                approximate=True,
+            )
+        except Exception as e:
+            self.log.error("quarantine_write_failed", error=str(e))
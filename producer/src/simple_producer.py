@@ -12,23 +12,34 @@ import time
 from datetime import datetime, timezone
 from typing import Any
 
-import redis
 import structlog
 import websocket
 
+from src.event_bus import open_bus
+from src.sanitize import DEFAULT_DLQ_MAXLEN, DEFAULT_DLQ_STREAM, Quarantine, sanitize_envelope
+
 log = structlog.get_logger()
 
 
 class BinancePublicProducer:
     """Producer that connects to Binance public WebSocket streams."""
 
-    def __init__(self, symbols: list[str], redis_url: str, stream_key: str):
+    def __init__(
+        self,
+        symbols: list[str],
+        redis_url: str,
+        stream_key: str,
+        dlq_stream: str = DEFAULT_DLQ_STREAM,
+        dlq_maxlen: int = DEFAULT_DLQ_MAXLEN,
+    ):
         self.symbols = [s.lower() for s in symbols]  # Binance uses lowercase
         self.redis_url = redis_url
         self.stream_key = stream_key
 
         # Connect to Redis
-        self.redis_client = redis.from_url(redis_url, decode_responses=False)
+        self.redis_client = open_bus(redis_url, decode_responses=False)
+        # Malformed messages and absurd values (see src/sanitize.py)
+        self.quarantine = Quarantine(self.redis_client, "simple_producer", stream=dlq_stream, maxlen=dlq_maxlen)
 
         # Build WebSocket URL for combined streams
         # https://binance-docs.github.io/apidocs/spot/en/#websocket-market-streams
@@ -39,7 +50,8 @@ class BinancePublicProducer:
             streams.append(f"{symbol}@depth20@100ms")  # Order book depth (20 levels, 100ms updates)
 
         stream_names = "/".join(streams)
-        self.ws_url = f"wss://stream.binance.com:9443/stream?streams={stream_names}"
+        base_url = os.getenv("BINANCE_BASE_URL_WS", "") or "wss://stream.binance.com:9443"
+        self.ws_url = f"{base_url.rstrip('/')}/stream?streams={stream_names}"
 
         log.info("producer_initialized", symbols=symbols, streams_count=len(streams))
 
@@ -57,6 +69,13 @@ class BinancePublicProducer:
                 "payload": payload,
             }
 
+            original = envelope
+            envelope, problems = sanitize_envelope(original)
+            if problems:
+                self.quarantine.put(original, problems, symbol=original["symbol"], event_type=event_type)
+            if envelope is None:
+                return
+
             # Serialize to JSON
             message_json = json.dumps(envelope)
 
@@ -100,6 +119,9 @@ class BinancePublicProducer:
             elif stream_type.startswith("depth"):
                 self.process_depth(symbol, stream_data)
 
+        except (KeyError, IndexError, TypeError, ValueError) as e:
+            # Not JSON, or a field missing or unparseable: keep the message for inspection
+            self.quarantine.put({"message": message[:4096], "error": repr(e)}, ["message:malformed"])
         except Exception as e:
             log.error("message_processing_failed", error=str(e), message=message[:200])
 
@@ -204,6 +226,8 @@ def main():
         symbols=symbols,
         redis_url=redis_url,
         stream_key=stream_key,
+        dlq_stream=os.getenv("NT_DLQ_STREAM", DEFAULT_DLQ_STREAM),
+        dlq_maxlen=int(os.getenv("NT_DLQ_MAXLEN", str(DEFAULT_DLQ_MAXLEN))),
     )
 
     try:
@@ -0,0 +1,120 @@
+"""Time-bucketed order book update rate and churn counters."""
+from dataclasses import dataclass
+from datetime import datetime, timezone
+from typing import Optional
+
+
+@dataclass
+class ChurnTotals:
+    """Aggregated book activity over a window."""
+    window_sec: int
+    observed_sec: int  # Seconds of the window since the first recorded update (rates use this)
+    updates: int = 0
+    added_qty: float = 0.0
+    removed_qty: float = 0.0
+    volume_sum: float = 0.0  # Resting top-of-book volume summed over updates
+    volume_samples: int = 0
+
+    @property
+    def updates_per_sec(self) -> float:
+        return self.updates / self.observed_sec if self.observed_sec else 0.0
+
+    @property
+    def added_per_sec(self) -> float:
+        return self.added_qty / self.observed_sec if self.observed_sec else 0.0
+
+    @property
+    def removed_per_sec(self) -> float:
+        return self.removed_qty / self.observed_sec if self.observed_sec else 0.0
+
+    @property
+    def avg_volume(self) -> float:
+        return self.volume_sum / self.volume_samples if self.volume_samples else 0.0
+
+    @property
+    def churn_per_sec(self) -> float:
+        """Quantity added plus removed per second as a fraction of the average resting volume."""
+        if self.avg_volume <= 0:
+            return 0.0
+        return (self.added_per_sec + self.removed_per_sec) / self.avg_volume
+
+
+class BookChurn:
+    """Per-second book update counts and added/removed quantity in fixed circular arrays.
+
+    Same layout as FlowBuckets: one slot per second up to the horizon, so
+    memory does not grow with the update rate. Quantities compare each
+    update against the previous state of the tracked top levels, so a level
+    entering or leaving the top N counts as added or removed.
+    """
+
+    def __init__(self, horizon_sec: int = 300):
+        """Initialize counters.
+
+        Args:
+            horizon_sec: Longest window that can be queried (also the baseline window)
+        """
+        if horizon_sec <= 0:
+            raise ValueError(f"horizon_sec must be positive, got {horizon_sec}")
+
+        self.horizon_sec = horizon_sec
+        self.first_second: Optional[int] = None  # Epoch second of the first update
+        self._second = [-1] * horizon_sec  # Epoch second held by each slot
+        self._updates = [0] * horizon_sec
+        self._added = [0.0] * horizon_sec
+        self._removed = [0.0] * horizon_sec
+        self._volume_sum = [0.0] * horizon_sec
+        self._volume_samples = [0] * horizon_sec
+
+    def add(self, timestamp: datetime, added_qty: float, removed_qty: float, book_volume: float) -> None:
+        """Count one book update in its one-second slot.
+
+        Args:
+            timestamp: Update time
+            added_qty: Quantity added across levels by the update
+            removed_qty: Quantity removed across levels by the update
+            book_volume: Resting volume of the tracked levels after the update
+        """
+        second = int(timestamp.timestamp())
+        if self.first_second is None:
+            self.first_second = second
+        idx = second % self.horizon_sec
+        if self._second[idx] != second:
+            if second < self._second[idx]:
+                return  # Older than the horizon; slot already reused
+            self._second[idx] = second
+            self._updates[idx] = 0
+            self._added[idx] = 0.0
+            self._removed[idx] = 0.0
+            self._volume_sum[idx] = 0.0
+            self._volume_samples[idx] = 0
+
+        self._updates[idx] += 1
+        self._added[idx] += added_qty
+        self._removed[idx] += removed_qty
+        self._volume_sum[idx] += book_volume
+        self._volume_samples[idx] += 1
+
+    def window(self, window_seconds: int, now: Optional[datetime] = None) -> ChurnTotals:
+        """Sum book activity over the last window_seconds (including the current second).
+
+        Args:
+            window_seconds: Window length, capped at horizon_sec
+            now: Reference time (default: current UTC time)
+        """
+        window_seconds = min(window_seconds, self.horizon_sec)
+        now_second = int((now or datetime.now(timezone.utc)).timestamp())
+        observed = 0 if self.first_second is None else now_second - self.first_second + 1
+        totals = ChurnTotals(window_sec=window_seconds, observed_sec=max(0, min(window_seconds, observed)))
+        for second in range(now_second - window_seconds + 1, now_second + 1):
+            idx = second % self.horizon_sec
+            if self._second[idx] == second:
+                totals.updates += self._updates[idx]
+                totals.added_qty += self._added[idx]
+                totals.removed_qty += self._removed[idx]
+                totals.volume_sum += self._volume_sum[idx]
+                totals.volume_samples += self._volume_samples[idx]
+        return totals
+
+    def __repr__(self) -> str:
+        return f"BookChurn(horizon_sec={self.horizon_sec})"
@@ -0,0 +1,64 @@
+"""Venue clock skew estimation.
+
+Every trade and book update carries two timestamps: the venue's event
+time (ts_event) and NautilusTrader's local receipt time (ts_init). Their
+difference is network latency plus the offset between the two clocks.
+Latency varies but never goes below zero, so the rolling minimum of the
+deltas estimates the clock offset (plus the best-case latency, typically
+a few milliseconds).
+
+A positive skew means the local clock is ahead of the venue; a negative
+skew means it is behind, which would otherwise make venue-time lags
+negative. Subtracting the skew from each delta leaves the event's
+latency above the best case, which is never negative.
+"""
+from collections import deque
+from typing import Optional
+
+# Rolling window of the minimum delta
+DEFAULT_WINDOW_SEC = 60
+
+
+class ClockSkewEstimator:
+    """Rolling minimum of venue-event-to-receipt deltas, bucketed per second."""
+
+    def __init__(self, window_sec: int = DEFAULT_WINDOW_SEC):
+        """Initialize estimator.
+
+        Args:
+            window_sec: Seconds of deltas the minimum is taken over
+        """
+        self.window_sec = window_sec
+        self._buckets: deque[tuple[int, float]] = deque()  # (receipt second, min delta ms), oldest first
+        self.last_delta_ms: Optional[float] = None
+
+    def record(self, event_ns: int, receipt_ns: int) -> None:
+        """Record one event's venue time and local receipt time (Unix nanoseconds)."""
+        if not event_ns or not receipt_ns:
+            return
+        delta_ms = (receipt_ns - event_ns) / 1_000_000
+        second = receipt_ns // 1_000_000_000
+        self.last_delta_ms = delta_ms
+
+        if self._buckets and self._buckets[-1][0] >= second:
+            if delta_ms < self._buckets[-1][1]:
+                self._buckets[-1] = (self._buckets[-1][0], delta_ms)
+        else:
+            self._buckets.append((second, delta_ms))
+
+        while self._buckets[0][0] <= second - self.window_sec:
+            self._buckets.popleft()
+
+    @property
+    def skew_ms(self) -> Optional[float]:
+        """Estimated local-minus-venue clock offset, or None before any event."""
+        if not self._buckets:
+            return None
+        return min(delta for _, delta in self._buckets)
+
+    def lag_ms(self) -> Optional[float]:
+        """Latest event's venue-to-receipt lag with the skew removed (>= 0)."""
+        skew = self.skew_ms
+        if self.last_delta_ms is None or skew is None:
+            return None
+        return max(0.0, self.last_delta_ms - skew)
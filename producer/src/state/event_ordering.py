@@ -0,0 +1,102 @@
+"""Event-time ordering guard for a symbol's trades and book updates.
+
+Events can reach the strategy out of venue event-time order (reconnects,
+snapshot resends, parallel feeds). Each kind of event is checked against
+the newest event time (ts_event) seen for it:
+
+    in_order   not older than the newest: applied
+    reordered  older, but within the reorder window: trades are held in a
+               small heap and released in event-time order; book updates
+               are applied at once (the book is copied from NautilusTrader's
+               cache, so only a stale resync could hurt)
+    stale      older than the window, or older than a trade already
+               released: dropped
+
+With a window of 0 nothing is buffered: events older than the newest are
+dropped and the rest are applied immediately. A buffered trade is released
+once a trade newer by the window arrives or once it has waited the window
+on the local clock (release()), so buffering delays trades by at most
+about twice the window.
+"""
+import heapq
+import itertools
+from typing import Any, Generic, TypeVar
+
+T = TypeVar("T")
+
+TRADE = "trade"
+DEPTH = "depth"
+
+IN_ORDER = "in_order"
+REORDERED = "reordered"
+STALE = "stale"
+
+# Kinds held back and released in event-time order within the window
+BUFFERED_KINDS = frozenset({TRADE})
+
+
+class EventOrderGuard(Generic[T]):
+    """Per-kind event-time ordering with an optional reorder window."""
+
+    def __init__(self, window_ms: float = 0.0):
+        """Initialize guard.
+
+        Args:
+            window_ms: Lateness tolerated (and trades buffered) in milliseconds; 0 = drop any older event
+        """
+        self.window_ns = int(window_ms * 1_000_000)
+        self.newest_ns: dict[str, int] = {}
+        self.released_ns: dict[str, int] = {}
+        self._pending: list[tuple[int, int, int, Any]] = []  # (event_ns, seq, receipt_ns, item) trades
+        self._seq = itertools.count()
+
+    def admit(self, kind: str, event_ns: int, receipt_ns: int, item: T) -> tuple[str, list[T]]:
+        """Check an event's order and return its outcome with the items now ready to apply.
+
+        Args:
+            kind: TRADE or DEPTH
+            event_ns: Venue event time (ts_event); events without one are treated as in order
+            receipt_ns: Local receipt time (ts_init), bounding how long a trade is held
+            item: Event to apply
+
+        Returns:
+            (outcome, items to apply in order); a stale event is never among the items
+        """
+        if event_ns <= 0:
+            return IN_ORDER, self._apply(kind, event_ns, receipt_ns, item)
+
+        newest = self.newest_ns.get(kind, 0)
+        if event_ns >= newest:
+            self.newest_ns[kind] = event_ns
+            outcome = IN_ORDER
+        elif newest - event_ns <= self.window_ns and event_ns >= self.released_ns.get(kind, 0):
+            outcome = REORDERED
+        else:
+            return STALE, []
+
+        return outcome, self._apply(kind, event_ns, receipt_ns, item)
+
+    def release(self, now_ns: int) -> list[T]:
+        """Trades due for release: beyond the window behind the newest, or held for the window."""
+        ready = []
+        newest = self.newest_ns.get(TRADE, 0)
+        while self._pending:
+            event_ns, _, receipt_ns, item = self._pending[0]
+            if event_ns > newest - self.window_ns and receipt_ns + self.window_ns > now_ns:
+                break
+            heapq.heappop(self._pending)
+            self.released_ns[TRADE] = max(self.released_ns.get(TRADE, 0), event_ns)
+            ready.append(item)
+        return ready
+
+    @property
+    def pending(self) -> int:
+        """Trades held for reordering."""
+        return len(self._pending)
+
+    def _apply(self, kind: str, event_ns: int, receipt_ns: int, item: T) -> list[T]:
+        if kind not in BUFFERED_KINDS or not self.window_ns:
+            self.released_ns[kind] = max(self.released_ns.get(kind, 0), event_ns)
+            return [item]
+        heapq.heappush(self._pending, (event_ns, next(self._seq), receipt_ns, item))
+        return self.release(receipt_ns)
@@ -0,0 +1,93 @@
+"""Time-bucketed trade flow counters for windowed flow metrics."""
+from dataclasses import dataclass
+from datetime import datetime, timezone
+from typing import Optional
+
+
+@dataclass
+class FlowTotals:
+    """Aggregated trade flow over a window."""
+    buy_volume: float = 0.0
+    sell_volume: float = 0.0
+    buy_count: int = 0
+    sell_count: int = 0
+
+    @property
+    def trade_count(self) -> int:
+        return self.buy_count + self.sell_count
+
+
+class FlowBuckets:
+    """Per-second buy/sell volume and trade counts in fixed circular arrays.
+
+    Memory is sized from the longest flow window (one slot per second), not
+    from the trade rate, so windows stay bounded and allocation-free at any
+    event rate. Window queries sum whole-second slots, so window edges have
+    one-second resolution.
+    """
+
+    def __init__(self, horizon_sec: int):
+        """Initialize counters.
+
+        Args:
+            horizon_sec: Longest window that can be queried, in seconds
+        """
+        if horizon_sec <= 0:
+            raise ValueError(f"horizon_sec must be positive, got {horizon_sec}")
+
+        self.horizon_sec = horizon_sec
+        self._second = [-1] * horizon_sec  # Epoch second held by each slot
+        self._buy_volume = [0.0] * horizon_sec
+        self._sell_volume = [0.0] * horizon_sec
+        self._buy_count = [0] * horizon_sec
+        self._sell_count = [0] * horizon_sec
+
+    def add(self, timestamp: datetime, volume: float, aggressor_side: str) -> None:
+        """Count a trade in its one-second slot.
+
+        Args:
+            timestamp: Trade time
+            volume: Base currency quantity
+            aggressor_side: "BUY" or "SELL"
+        """
+        second = int(timestamp.timestamp())
+        idx = second % self.horizon_sec
+        if self._second[idx] != second:
+            if second < self._second[idx]:
+                return  # Older than the horizon; slot already reused
+            self._second[idx] = second
+            self._buy_volume[idx] = 0.0
+            self._sell_volume[idx] = 0.0
+            self._buy_count[idx] = 0
+            self._sell_count[idx] = 0
+
+        if aggressor_side == "BUY":
+            self._buy_volume[idx] += volume
+            self._buy_count[idx] += 1
+        elif aggressor_side == "SELL":
+            self._sell_volume[idx] += volume
+            self._sell_count[idx] += 1
+
+    def window(self, window_seconds: int, now: Optional[datetime] = None) -> FlowTotals:
+        """Sum trade flow over the last window_seconds (including the current second).
+
+        Args:
+            window_seconds: Window length, capped at horizon_sec
+            now: Reference time (default: current UTC time)
+
+        Returns:
+            Totals over the window
+        """
+        now_second = int((now or datetime.now(timezone.utc)).timestamp())
+        totals = FlowTotals()
+        for second in range(now_second - min(window_seconds, self.horizon_sec) + 1, now_second + 1):
+            idx = second % self.horizon_sec
+            if self._second[idx] == second:
+                totals.buy_volume += self._buy_volume[idx]
+                totals.sell_volume += self._sell_volume[idx]
+                totals.buy_count += self._buy_count[idx]
+                totals.sell_count += self._sell_count[idx]
+        return totals
+
+    def __repr__(self) -> str:
+        return f"FlowBuckets(horizon_sec={self.horizon_sec})"
@@ -0,0 +1,131 @@
+"""Ingestion status tracking with configurable thresholds and hysteresis."""
+from collections import deque
+from dataclasses import dataclass
+from datetime import datetime, timezone
+from typing import Optional
+
+# Status order, best to worst
+STATUSES = ("ok", "degraded", "down")
+
+# Window for the uptime percentage in the report timeline
+UPTIME_WINDOW_MS = 60 * 60 * 1000
+
+
+def _iso(ms: Optional[int]) -> Optional[str]:
+    if ms is None:
+        return None
+    return datetime.fromtimestamp(ms / 1000, tz=timezone.utc).isoformat().replace('+00:00', 'Z')
+
+
+@dataclass(frozen=True)
+class IngestionThresholds:
+    """Data age thresholds for ingestion status."""
+    degraded_ms: int = 1000  # data_age_ms above this is "degraded"
+    down_ms: int = 2000  # data_age_ms above this is "down"
+
+    def classify(self, data_age_ms: int) -> str:
+        """Map data age to the raw (undamped) status."""
+        if data_age_ms > self.down_ms:
+            return "down"
+        if data_age_ms > self.degraded_ms:
+            return "degraded"
+        return "ok"
+
+
+class IngestionStatusTracker:
+    """Ingestion status for one symbol with minimum-dwell hysteresis.
+
+    Worsening transitions apply immediately so outages surface at once.
+    Recovering transitions apply only after the better status has held for
+    min_dwell_ms, so bursty feeds that briefly catch up don't flap between
+    states.
+    """
+
+    def __init__(self, thresholds: Optional[IngestionThresholds] = None, min_dwell_ms: int = 2000):
+        """Initialize tracker.
+
+        Args:
+            thresholds: Data age thresholds (default 1000ms/2000ms)
+            min_dwell_ms: Time a better status must hold before recovering to it
+        """
+        self.thresholds = thresholds or IngestionThresholds()
+        self.min_dwell_ms = min_dwell_ms
+        self.status = "ok"
+        self.since_ms: Optional[int] = None  # When the current status was entered
+        self._candidate: Optional[str] = None  # Better status waiting out the dwell
+        self._candidate_since_ms = 0
+
+        # Last time each status was entered, and (start_ms, status) segments for uptime
+        self.entered_ms: dict[str, Optional[int]] = {status: None for status in STATUSES}
+        self._segments: deque[tuple[int, str]] = deque()
+
+    def update(self, data_age_ms: int, now_ms: int) -> Optional[tuple[str, str]]:
+        """Re-evaluate status for the current data age.
+
+        Args:
+            data_age_ms: Milliseconds since the last market data event
+            now_ms: Current time in epoch milliseconds
+
+        Returns:
+            (from_status, to_status) if the status changed, else None
+        """
+        raw = self.thresholds.classify(data_age_ms)
+        if self.since_ms is None:
+            self._enter(raw, now_ms)
+            return None
+
+        if raw == self.status:
+            self._candidate = None
+            return None
+
+        if STATUSES.index(raw) < STATUSES.index(self.status):
+            # Recovering: wait until the better status has held for the dwell time
+            if self._candidate != raw:
+                self._candidate, self._candidate_since_ms = raw, now_ms
+            if now_ms - self._candidate_since_ms < self.min_dwell_ms:
+                return None
+
+        previous = self.status
+        self._enter(raw, now_ms)
+        self._candidate = None
+        return previous, raw
+
+    def _enter(self, status: str, now_ms: int) -> None:
+        self.status, self.since_ms = status, now_ms
+        self.entered_ms[status] = now_ms
+        self._segments.append((now_ms, status))
+        # Keep only the segment in progress at the start of the uptime window
+        while len(self._segments) > 1 and self._segments[1][0] <= now_ms - UPTIME_WINDOW_MS:
+            self._segments.popleft()
+
+    def uptime_pct(self, now_ms: int, window_ms: int = UPTIME_WINDOW_MS) -> Optional[float]:
+        """Percentage of observed time in "ok" over the window (None before the first update)."""
+        if not self._segments:
+            return None
+
+        window_start = now_ms - window_ms
+        observed = ok = 0
+        segments = list(self._segments)
+        for i, (start_ms, status) in enumerate(segments):
+            end_ms = segments[i + 1][0] if i + 1 < len(segments) else now_ms
+            duration = max(0, end_ms - max(start_ms, window_start))
+            observed += duration
+            if status == "ok":
+                ok += duration
+
+        if not observed:
+            return 100.0 if self.status == "ok" else 0.0
+        return round(ok / observed * 100, 2)
+
+    def timeline(self, now_ms: int) -> dict:
+        """Recent status history for the report's ingestion section.
+
+        Returns:
+            Dictionary with since (current status entered), last_transitions
+            (when each status was last entered) and uptime_pct_1h
+        """
+        return {
+            "since": _iso(self.since_ms),
+            "last_transitions": {status: _iso(ms) for status, ms in self.entered_ms.items()},
+            "uptime_pct_1h": self.uptime_pct(now_ms),
+        }
@@ -0,0 +1,91 @@
+"""Order book level lifetimes (appearance to removal) for spoofing detection.
+
+Spoofing is a large order shown to move other traders and pulled before
+it can trade. L2 data has no order ids, so each price level stands in for
+the orders resting on it: a level is timed from when it appears (quantity
+goes from 0 to positive) to when it is removed. A removal with no trade
+printed at the level's price in between is a pull; pulls are kept for
+the horizon with the largest quantity the level showed.
+
+Levels already in the book when tracking starts (the first update's
+timestamp) have an unknown age, so their removal is not counted as a pull.
+"""
+from collections import deque
+from dataclasses import dataclass
+from typing import Optional
+
+
+@dataclass(frozen=True)
+class PulledLevel:
+    """A level removed without trading."""
+    side: str  # "bid" | "ask"
+    price: float
+    qty: float  # Largest quantity shown while resting
+    lifetime_ms: int
+    removed_at_ms: int
+
+
+class LevelLifetimes:
+    """Appearance time of every resting level, and levels pulled without trading."""
+
+    def __init__(self, horizon_sec: int = 300, max_pulls: int = 5000):
+        """Initialize tracker.
+
+        Args:
+            horizon_sec: Longest window pulls can be queried over
+            max_pulls: Pulls kept at most (oldest dropped first)
+        """
+        self.horizon_sec = horizon_sec
+        self.started_ms: Optional[int] = None
+        # (side, price) -> [appeared_ms (None if unknown), largest qty, traded]
+        self._levels: dict[tuple[str, float], list] = {}
+        self._pulls: deque[PulledLevel] = deque(maxlen=max_pulls)
+
+    def update(self, side: str, price: float, qty: float, now_ms: int) -> None:
+        """Apply a level change.
+
+        Args:
+            side: "bid" or "ask"
+            price: Level price
+            qty: New quantity (0 when the level is removed)
+            now_ms: Update time (Unix milliseconds)
+        """
+        if self.started_ms is None:
+            self.started_ms = now_ms
+        key = (side, price)
+        level = self._levels.get(key)
+
+        if qty > 0:
+            if level is None:
+                self._levels[key] = [None if now_ms == self.started_ms else now_ms, qty, False]
+            elif qty > level[1]:
+                level[1] = qty
+            return
+
+        if level is None:
+            return
+        del self._levels[key]
+        appeared_ms, max_qty, traded = level
+        if appeared_ms is not None and not traded:
+            self._pulls.append(PulledLevel(side, price, max_qty, now_ms - appeared_ms, now_ms))
+        self._expire(now_ms)
+
+    def record_trade(self, price: float) -> None:
+        """Mark the levels at a trade's price as traded (their removal is a fill, not a pull)."""
+        for side in ("bid", "ask"):
+            level = self._levels.get((side, price))
+            if level is not None:
+                level[2] = True
+
+    def pulled(self, window_sec: int, now_ms: int) -> list[PulledLevel]:
+        """Levels pulled over the last window_sec, oldest first."""
+        cutoff = now_ms - min(window_sec, self.horizon_sec) * 1000
+        return [pull for pull in self._pulls if pull.removed_at_ms > cutoff]
+
+    def _expire(self, now_ms: int) -> None:
+        cutoff = now_ms - self.horizon_sec * 1000
+        while self._pulls and self._pulls[0].removed_at_ms <= cutoff:
+            self._pulls.popleft()
+
+    def __repr__(self) -> str:
+        return f"LevelLifetimes(levels={len(self._levels)}, pulls={len(self._pulls)})"
@@ -0,0 +1,90 @@
+"""Exchange trading status of a symbol (halts, auctions, maintenance).
+
+Venues announce trading-status changes as NautilusTrader InstrumentStatus
+events; their MarketStatusAction maps onto the report's market_status
+state:
+
+    open         trading normally
+    halted       trading halted, paused or suspended
+    auction      pre-open, crossing or other auction phases
+    maintenance  instrument not available for trading (venue maintenance)
+    closed       pre-close, close and post-close sessions
+
+While a symbol is not open its feed is expected to go quiet, so reports
+keep their last ingestion status instead of degrading to "down", and
+market anomaly detection and freshness health penalties are suspended.
+"""
+from datetime import datetime, timezone
+from typing import Optional
+
+OPEN = "open"
+HALTED = "halted"
+AUCTION = "auction"
+MAINTENANCE = "maintenance"
+CLOSED = "closed"
+
+MARKET_STATES = (OPEN, HALTED, AUCTION, MAINTENANCE, CLOSED)
+
+# MarketStatusAction names; actions not listed (e.g. NONE) leave the state unchanged
+ACTION_STATES = {
+    "TRADING": OPEN,
+    "HALT": HALTED,
+    "PAUSE": HALTED,
+    "SUSPEND": HALTED,
+    "PRE_OPEN": AUCTION,
+    "PRE_CROSS": AUCTION,
+    "CROSS": AUCTION,
+    "ROTATION": AUCTION,
+    "NEW_PRICE_INDICATION": AUCTION,
+    "QUOTING": AUCTION,
+    "NOT_AVAILABLE_FOR_TRADING": MAINTENANCE,
+    "PRE_CLOSE": CLOSED,
+    "CLOSE": CLOSED,
+    "POST_CLOSE": CLOSED,
+}
+
+
+class MarketStatus:
+    """Current trading status of a symbol, as last announced by the venue."""
+
+    def __init__(self):
+        self.state = OPEN
+        self.since: Optional[datetime] = None  # None: open since startup (no status event yet)
+        self.reason: Optional[str] = None
+
+    @property
+    def is_open(self) -> bool:
+        """Whether the symbol is trading normally."""
+        return self.state == OPEN
+
+    def update(self, action: str, reason: Optional[str] = None, ts: Optional[datetime] = None) -> bool:
+        """Apply a venue status action.
+
+        Args:
+            action: MarketStatusAction name (e.g. "HALT", "TRADING")
+            reason: Venue-supplied reason, if any
+            ts: Time of the status change (default: now)
+
+        Returns:
+            True if the state changed
+        """
+        state = ACTION_STATES.get(action.upper())
+        if state is None:
+            return False
+        if state == self.state:
+            # Keep the original start time; refresh the reason if the venue gave one
+            if reason:
+                self.reason = reason
+            return False
+        self.state = state
+        self.since = ts or datetime.now(timezone.utc)
+        self.reason = reason or None
+        return True
+
+    def to_dict(self) -> dict:
+        """Report market_status section."""
+        return {
+            "state": self.state,
+            "since": self.since.isoformat().replace('+00:00', 'Z') if self.since else None,
+            "reason": self.reason,
+        }
@@ -0,0 +1,108 @@
+"""Per-minute summary of a symbol's trades, reports and anomalies.
+
+Trades feed the minute's OHLC, volume and VWAP, each fast-cycle report
+adds a spread and imbalance sample (and whether ingestion was ok), and each slow cycle adds its anomalies by
+type. A minute closes when an input or drain() reaches a later minute;
+closed minutes wait in a short queue until the strategy drains and
+publishes them (see reporters/digest.py).
+"""
+from collections import Counter
+from typing import Iterable, Optional
+
+MINUTE_MS = 60_000
+
+# Closed minutes kept while the digest writer is behind (e.g. Redis down)
+MAX_PENDING = 10
+
+
+class MinuteDigest:
+    """Accumulates the current minute and queues closed ones."""
+
+    def __init__(self):
+        self.minute: Optional[int] = None  # Epoch minute being accumulated
+        self._pending: list[dict] = []
+        self._reset()
+
+    def _reset(self) -> None:
+        self.open: Optional[float] = None
+        self.high: Optional[float] = None
+        self.low: Optional[float] = None
+        self.close: Optional[float] = None
+        self.volume = 0.0
+        self.notional = 0.0
+        self.trade_count = 0
+        self.spread_sum = 0.0
+        self.imbalance_sum = 0.0
+        self.samples = 0
+        self.ingestion_ok = 0
+        self.anomalies: Counter[str] = Counter()
+
+    def add_trade(self, ts_ms: int, price: float, volume: float) -> None:
+        """Add a trade to its minute's OHLC, volume and VWAP."""
+        if not self._advance(ts_ms):
+            return
+        if self.open is None:
+            self.open = self.high = self.low = price
+        self.high = max(self.high, price)
+        self.low = min(self.low, price)
+        self.close = price
+        self.volume += volume
+        self.notional += price * volume
+        self.trade_count += 1
+
+    def add_sample(
+        self, ts_ms: int, spread_bps: Optional[float], imbalance: Optional[float], ingestion_ok: bool = True
+    ) -> None:
+        """Add one report's spread and imbalance to the minute's averages."""
+        if spread_bps is None or imbalance is None or not self._advance(ts_ms):
+            return
+        self.spread_sum += spread_bps
+        self.imbalance_sum += imbalance
+        self.samples += 1
+        self.ingestion_ok += ingestion_ok
+
+    def add_anomalies(self, ts_ms: int, anomalies: Iterable[dict]) -> None:
+        """Count a slow cycle's anomalies by type."""
+        if not self._advance(ts_ms):
+            return
+        self.anomalies.update(a.get("type", "unknown") for a in anomalies)
+
+    def drain(self, now_ms: int) -> list[dict]:
+        """Closed minutes, oldest first (closing the current one if now is past it)."""
+        self._advance(now_ms)
+        drained, self._pending = self._pending, []
+        return drained
+
+    def _advance(self, ts_ms: int) -> bool:
+        """Move to ts_ms's minute, closing the current one; False for inputs from a closed minute."""
+        minute = ts_ms // MINUTE_MS
+        if self.minute is None:
+            self.minute = minute
+        elif minute < self.minute:
+            return False
+        elif minute > self.minute:
+            if self.trade_count or self.samples:
+                self._pending.append(self._summary())
+                del self._pending[:-MAX_PENDING]
+            self.minute = minute
+            self._reset()
+        return True
+
+    def _summary(self) -> dict:
+        return {
+            "start_ms": self.minute * MINUTE_MS,
+            "ohlc": {
+                "open": self.open,
+                "high": self.high,
+                "low": self.low,
+                "close": self.close,
+            } if self.trade_count else None,
+            "volume": self.volume,
+            "vwap": self.notional / self.volume if self.volume else None,
+            "trade_count": self.trade_count,
+            "avg_spread_bps": self.spread_sum / self.samples if self.samples else None,
+            "avg_imbalance": self.imbalance_sum / self.samples if self.samples else None,
+            "report_samples": self.samples,
+            "ingestion_ok_samples": self.ingestion_ok,
+            "anomaly_counts": dict(sorted(self.anomalies.items())),
+        }
@@ -0,0 +1,115 @@
+"""Streaming quantile sketch with time decay for order book quantity percentiles."""
+import math
+import time
+from typing import Dict, Optional
+
+
+class QuantileSketch:
+    """Log-bucketed quantile sketch (DDSketch-style) with exponential time decay.
+
+    Values land in buckets whose bounds grow geometrically, so any quantile is
+    returned within `relative_accuracy` of the true sample value while memory
+    is bounded by the value range rather than the sample count. Older samples
+    fade with the configured half-life (forward decay: new samples get
+    exponentially larger weights), giving rolling-window percentiles without
+    storing or sorting raw samples.
+    """
+
+    # Rescale weights before they overflow float range (2**300 ~ 1e90)
+    _MAX_EXPONENT = 300.0
+    # Buckets whose weight falls below this fraction of the total are dropped on rescale
+    _PRUNE_FRACTION = 1e-9
+
+    def __init__(self, relative_accuracy: float = 0.01, half_life_sec: float = 300.0):
+        """Initialize sketch.
+
+        Args:
+            relative_accuracy: Maximum relative error of returned quantiles
+            half_life_sec: Time for a sample's weight to halve
+        """
+        if not 0 < relative_accuracy < 1:
+            raise ValueError(f"relative_accuracy must be in (0, 1), got {relative_accuracy}")
+        if half_life_sec <= 0:
+            raise ValueError(f"half_life_sec must be positive, got {half_life_sec}")
+
+        self.relative_accuracy = relative_accuracy
+        self.half_life_sec = half_life_sec
+        self._gamma = (1 + relative_accuracy) / (1 - relative_accuracy)
+        self._log_gamma = math.log(self._gamma)
+
+        self._buckets: Dict[int, float] = {}  # bucket index -> decayed weight
+        self._total_weight = 0.0
+        self._landmark: Optional[float] = None  # Time at which new samples weigh 1.0
+        self.sample_count = 0  # Samples added (undecayed), for minimum-sample checks
+
+    def _weight(self, now: float) -> float:
+        if self._landmark is None:
+            self._landmark = now
+        exponent = (now - self._landmark) / self.half_life_sec
+        if exponent > self._MAX_EXPONENT:
+            self._rescale(exponent)
+            self._landmark = now
+            return 1.0
+        return 2.0 ** exponent
+
+    def _rescale(self, exponent: float) -> None:
+        """Scale all weights by 2**-exponent, dropping negligible buckets."""
+        factor = 2.0 ** -exponent  # Underflows to 0.0 after very long gaps
+        self._total_weight *= factor
+        cutoff = self._total_weight * self._PRUNE_FRACTION
+        self._buckets = {
+            idx: weight * factor
+            for idx, weight in self._buckets.items()
+            if weight * factor > cutoff
+        }
+
+    def add(self, value: float, now: Optional[float] = None) -> None:
+        """Add a positive sample.
+
+        Args:
+            value: Sample value (non-positive and non-finite values are ignored)
+            now: Sample time in epoch seconds (default: current time)
+        """
+        if not math.isfinite(value) or value <= 0:
+            return
+        weight = self._weight(time.time() if now is None else now)
+        idx = math.ceil(math.log(value) / self._log_gamma)
+        self._buckets[idx] = self._buckets.get(idx, 0.0) + weight
+        self._total_weight += weight
+        self.sample_count += 1
+
+    def quantile(self, q: float) -> Optional[float]:
+        """Estimate the q-quantile of decayed samples.
+
+        Args:
+            q: Quantile in [0, 1] (e.g., 0.95 for P95)
+
+        Returns:
+            Estimated value, or None if the sketch is empty
+        """
+        if not self._buckets:
+            return None
+
+        rank = q * self._total_weight
+        cumulative = 0.0
+        for idx in sorted(self._buckets):
+            cumulative += self._buckets[idx]
+            if cumulative >= rank:
+                break
+        # Bucket midpoint (in relative terms) bounds the error by relative_accuracy
+        return 2 * self._gamma ** idx / (self._gamma + 1)
+
+    def clear(self) -> None:
+        """Remove all samples."""
+        self._buckets.clear()
+        self._total_weight = 0.0
+        self._landmark = None
+        self.sample_count = 0
+
+    def __len__(self) -> int:
+        """Return number of occupied buckets (bounded by value range, not sample count)."""
+        return len(self._buckets)
+
+    def __repr__(self) -> str:
+        return (f"QuantileSketch(buckets={len(self)}, samples={self.sample_count}, "
+                f"half_life_sec={self.half_life_sec})")
@@ -0,0 +1,70 @@
+"""Static symbol reference data published in the report meta section."""
+from dataclasses import asdict, dataclass
+from typing import Any, Optional
+
+# Quote assets recognised when splitting a bare symbol (longest match first)
+KNOWN_QUOTES = ("FDUSD", "USDT", "USDC", "TUSD", "BUSD", "BTC", "ETH", "BNB", "EUR", "TRY")
+
+# NautilusTrader instrument class name -> contract type
+CONTRACT_TYPES = {
+    "CurrencyPair": "spot",
+    "CryptoPerpetual": "perp",
+    "CryptoFuture": "future",
+}
+
+# (venue, contract type) -> display name
+VENUE_NAMES = {
+    ("BINANCE", "spot"): "Binance Spot",
+    ("BINANCE", "perp"): "Binance USDⓈ-M Futures",
+    ("BINANCE", "future"): "Binance USDⓈ-M Futures",
+}
+
+
+@dataclass(frozen=True)
+class SymbolMeta:
+    """Base/quote assets, precision and contract type of a symbol."""
+    base_asset: str
+    quote_asset: str
+    price_precision: Optional[int]  # Decimal places of the tick size (None until instruments load)
+    size_precision: Optional[int]  # Decimal places of the lot size (None until instruments load)
+    contract_type: str  # "spot", "perp" or "future"
+    venue_name: str
+
+    @classmethod
+    def from_symbol(cls, symbol: str, venue: str = "BINANCE", contract_type: str = "spot") -> "SymbolMeta":
+        """Best-effort metadata from the symbol name alone (before instruments load)."""
+        quote = next((q for q in KNOWN_QUOTES if symbol.endswith(q) and len(symbol) > len(q)), "")
+        return cls(
+            base_asset=symbol[:-len(quote)] if quote else symbol,
+            quote_asset=quote,
+            price_precision=None,
+            size_precision=None,
+            contract_type=contract_type,
+            venue_name=VENUE_NAMES.get((venue, contract_type), venue.title()),
+        )
+
+    @classmethod
+    def from_instrument(cls, instrument: Any, venue: str = "BINANCE") -> "SymbolMeta":
+        """Metadata from a NautilusTrader instrument (exchange info loaded by the instrument provider)."""
+        contract_type = CONTRACT_TYPES.get(type(instrument).__name__, "spot")
+        return cls(
+            base_asset=instrument.base_currency.code,
+            quote_asset=instrument.quote_currency.code,
+            price_precision=int(instrument.price_precision),
+            size_precision=int(instrument.size_precision),
+            contract_type=contract_type,
+            venue_name=VENUE_NAMES.get((venue, contract_type), venue.title()),
+        )
+
+    @property
+    def tick_size(self) -> Optional[float]:
+        """Price increment implied by price_precision."""
+        return 10 ** -self.price_precision if self.price_precision is not None else None
+
+    @property
+    def lot_size(self) -> Optional[float]:
+        """Quantity increment implied by size_precision."""
+        return 10 ** -self.size_precision if self.size_precision is not None else None
+
+    def to_dict(self) -> dict:
+        return asdict(self)
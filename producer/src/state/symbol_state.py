@@ -2,6 +2,18 @@
 from dataclasses import dataclass, field
 from datetime import datetime, timezone
 from typing import Dict, List, Tuple, Optional
+from ..calculators.notional import DEFAULT_NOTIONAL_TIERS, NotionalTier
+from .book_churn import BookChurn
+from .clock_skew import ClockSkewEstimator
+from .event_ordering import EventOrderGuard
+from .flow_buckets import FlowBuckets
+from .ingestion import IngestionStatusTracker, IngestionThresholds
+from .level_lifetime import LevelLifetimes
+from .market_status import MarketStatus
+from .minute_digest import MinuteDigest
+from .quantile_sketch import QuantileSketch
+from .symbol_meta import SymbolMeta
+from .value_area import PocHistory, SessionProfile
 from .ring_buffer import RingBuffer
 
 
@@ -93,15 +105,45 @@ class OrderBookL2:
             return PriceQty(price=price, qty=qty)
         return None
 
+    def snapshot(self) -> "OrderBookL2":
+        """Independent copy of the book for readers off the strategy thread."""
+        book = OrderBookL2(max_levels=self.max_levels)
+        book.bids = dict(self.bids)
+        book.asks = dict(self.asks)
+        book.top_bids = list(self.top_bids)
+        book.top_asks = list(self.top_asks)
+        return book
+
 
 class SymbolState:
     """Complete state for a tracked symbol including order book and trade history."""
 
-    def __init__(self, symbol: str):
+    def __init__(
+        self,
+        symbol: str,
+        flow_horizon_sec: int = 300,
+        ingestion_thresholds: Optional[IngestionThresholds] = None,
+        ingestion_min_dwell_ms: int = 2000,
+        notional_tier: Optional[NotionalTier] = None,
+        poc_trend_window_sec: int = 900,
+        poc_trend_threshold_bps: float = 5.0,
+        disabled_detectors: frozenset[str] = frozenset(),
+        trade_tape_size: int = 500,
+        reorder_window_ms: float = 0.0,
+    ):
         """Initialize symbol state.
 
         Args:
             symbol: Trading pair (e.g., "BTCUSDT")
+            flow_horizon_sec: Longest flow window in seconds (sizes flow counters)
+            ingestion_thresholds: Data age thresholds for ingestion status
+            ingestion_min_dwell_ms: Hysteresis before ingestion status recovers
+            notional_tier: USD notional thresholds for anomaly and wall severity
+            poc_trend_window_sec: Window over which POC drift is classified
+            poc_trend_threshold_bps: POC drift reported as "up"/"down" rather than "stable"
+            disabled_detectors: Anomaly detectors skipped for this symbol
+            trade_tape_size: Recent trades kept for the tape (get_trades, recent_trades)
+            reorder_window_ms: Lateness tolerated for out-of-order events (0 = drop any older event)
         """
         self.symbol = symbol
         self.order_book = OrderBookL2(max_levels=20)
@@ -116,12 +158,67 @@ class SymbolState:
         self.trade_buffer_30s = RingBuffer[TradeTick](3000)
         self.trade_buffer_30min = RingBuffer[TradeTick](20000)  # 30min × ~10 trades/sec
 
-        # Quantity history for percentile calculations
-        self.quantity_history = RingBuffer[float](10000)
+        # Last N trades regardless of age, for the raw tape
+        self.trade_tape = RingBuffer[TradeTick](trade_tape_size)
+
+        # Per-second flow counters for windowed flow metrics
+        self.flow = FlowBuckets(flow_horizon_sec)
+
+        # Per-second book update counts and added/removed quantity (5-minute baseline)
+        self.book_churn = BookChurn(horizon_sec=300)
+
+        # Level appearance times and levels pulled without trading (spoofing)
+        self.level_lifetimes = LevelLifetimes(horizon_sec=300)
+
+        # Rolling order book quantity distribution for percentile calculations
+        self.quantity_sketch = QuantileSketch(relative_accuracy=0.01, half_life_sec=300.0)
 
         # Last event timestamp for data freshness tracking
         self.last_event_ts: Optional[datetime] = None
 
+        # Last order book update (report provenance; trades carry their own timestamp)
+        self.last_book_ts: Optional[datetime] = None
+
+        # Clock time (ns) of the last order book copy from the NautilusTrader cache
+        self.book_synced_ns: int = 0
+
+        # Latest event's delay from NautilusTrader ingest (ts_init) to strategy handling
+        self.consume_lag_ms: Optional[float] = None
+
+        # Local clock offset from the venue, from event (ts_event) vs receipt (ts_init) times
+        self.clock_skew = ClockSkewEstimator()
+
+        # Event-time ordering of trades and book updates (stale events dropped, late trades reordered)
+        self.event_order: EventOrderGuard = EventOrderGuard(reorder_window_ms)
+
+        # Redis write time of the last published report (set by publish callbacks)
+        self.last_publish_ms: Optional[float] = None
+
+        # Ingestion status derived from data age
+        self.ingestion = IngestionStatusTracker(ingestion_thresholds, ingestion_min_dwell_ms)
+
+        # Venue trading status (halts, auctions, maintenance) from instrument status events
+        self.market_status = MarketStatus()
+
+        # Current minute's OHLC, spread/imbalance averages and anomaly counts (digest:{symbol}:{minute})
+        self.digest = MinuteDigest()
+
+        # Severity thresholds by order notional for this symbol's tier
+        self.notional_tier = notional_tier or DEFAULT_NOTIONAL_TIERS["default"]
+
+        # Anomaly detectors turned off for this symbol (e.g. noisy venues)
+        self.disabled_detectors = disabled_detectors
+
+        # Reference data for the report meta section (replaced from the instrument on subscribe)
+        self.meta = SymbolMeta.from_symbol(symbol)
+
+        # Slow-cycle POC samples and the developing session volume profile
+        self.poc_history = PocHistory(poc_trend_window_sec, poc_trend_threshold_bps)
+        self.session_profile = SessionProfile()
+
+        # USD value of one quote unit (1.0 for stable quotes, None if unknown); set by the strategy
+        self.quote_usd_rate: Optional[float] = 1.0
+
     def update_order_book_bid(self, price: float, qty: float) -> None:
         """Update bid level in order book.
 
@@ -129,14 +226,17 @@ class SymbolState:
             price: Bid price
             qty: Quantity (0 to remove)
         """
+        previous_qty = self.order_book.bids.get(price, 0.0)
         self.order_book.update_bid(price, qty)
         self.best_bid = self.order_book.get_best_bid()
 
         # Track quantity for percentile calculations
         if qty > 0:
-            self.quantity_history.append(qty)
+            self.quantity_sketch.add(qty)
 
-        self.last_event_ts = datetime.now(timezone.utc)
+        self.last_event_ts = self.last_book_ts = datetime.now(timezone.utc)
+        self.level_lifetimes.update("bid", price, qty, int(self.last_book_ts.timestamp() * 1000))
+        self._record_churn(max(qty - previous_qty, 0.0), max(previous_qty - qty, 0.0))
 
     def update_order_book_ask(self, price: float, qty: float) -> None:
         """Update ask level in order book.
@@ -145,14 +245,43 @@ class SymbolState:
             price: Ask price
             qty: Quantity (0 to remove)
         """
+        previous_qty = self.order_book.asks.get(price, 0.0)
         self.order_book.update_ask(price, qty)
         self.best_ask = self.order_book.get_best_ask()
 
         # Track quantity for percentile calculations
         if qty > 0:
-            self.quantity_history.append(qty)
+            self.quantity_sketch.add(qty)
+
+        self.last_event_ts = self.last_book_ts = datetime.now(timezone.utc)
+        self.level_lifetimes.update("ask", price, qty, int(self.last_book_ts.timestamp() * 1000))
+        self._record_churn(max(qty - previous_qty, 0.0), max(previous_qty - qty, 0.0))
+
+    def record_book_sync(self, previous_bids: Dict[float, float], previous_asks: Dict[float, float]) -> None:
+        """Count a whole-book refresh (levels replaced from the exchange book) as one update.
 
-        self.last_event_ts = datetime.now(timezone.utc)
+        Args:
+            previous_bids: Bid levels before the refresh
+            previous_asks: Ask levels before the refresh
+        """
+        added = removed = 0.0
+        now_ms = int(datetime.now(timezone.utc).timestamp() * 1000)
+        for side, previous, current in (
+            ("bid", previous_bids, self.order_book.bids), ("ask", previous_asks, self.order_book.asks)
+        ):
+            for price in previous.keys() | current.keys():
+                change = current.get(price, 0.0) - previous.get(price, 0.0)
+                if change:
+                    self.level_lifetimes.update(side, price, current.get(price, 0.0), now_ms)
+                if change > 0:
+                    added += change
+                else:
+                    removed -= change
+        self._record_churn(added, removed)
+
+    def _record_churn(self, added_qty: float, removed_qty: float) -> None:
+        book_volume = sum(q for _, q in self.order_book.top_bids) + sum(q for _, q in self.order_book.top_asks)
+        self.book_churn.add(datetime.now(timezone.utc), added_qty, removed_qty, book_volume)
 
     def add_trade(self, trade: TradeTick) -> None:
         """Add trade tick to all buffers.
@@ -164,6 +293,11 @@ class SymbolState:
         self.trade_buffer_10s.append(trade)
         self.trade_buffer_30s.append(trade)
         self.trade_buffer_30min.append(trade)
+        self.trade_tape.append(trade)
+        self.level_lifetimes.record_trade(trade.price)
+        self.flow.add(trade.timestamp, trade.volume, trade.aggressor_side)
+        self.session_profile.add(trade.timestamp, trade.price, trade.volume)
+        self.digest.add_trade(int(trade.timestamp.timestamp() * 1000), trade.price, trade.volume)
         self.last_event_ts = trade.timestamp
 
     def check_order_book_invariants(self) -> bool:
@@ -188,7 +322,6 @@ class SymbolState:
             len(self.trade_buffer_10s) <= self.trade_buffer_10s.max_size,
             len(self.trade_buffer_30s) <= self.trade_buffer_30s.max_size,
             len(self.trade_buffer_30min) <= self.trade_buffer_30min.max_size,
-            len(self.quantity_history) <= self.quantity_history.max_size,
         ]
         return all(checks)
 
@@ -200,7 +333,8 @@ class SymbolState:
         """
         if self.last_event_ts:
             age = (datetime.now(timezone.utc) - self.last_event_ts).total_seconds() * 1000
-            return int(age)
+            # A wall clock stepped back (NTP) would otherwise report a negative age
+            return max(0, int(age))
         return None
 
     def __repr__(self) -> str:
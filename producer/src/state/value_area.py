@@ -0,0 +1,121 @@
+"""POC migration and developing session value area.
+
+The slow cycle's volume profile covers a rolling 30-minute window, so its
+POC is a single number with no direction. PocHistory keeps the POC of
+each slow cycle and classifies how it drifted over a window; SessionProfile
+accumulates every trade since the UTC session start so the developing
+POC/VAH/VAL for the day can be published alongside.
+"""
+from collections import defaultdict, deque
+from datetime import datetime, timezone
+from typing import Optional
+
+# Fraction of session volume inside the value area
+VALUE_AREA_FRACTION = 0.70
+
+# Session profile bin width relative to the session's first price
+SESSION_BIN_BPS = 1.0
+
+
+class PocHistory:
+    """POC samples over a rolling window with a drift classification."""
+
+    def __init__(self, window_sec: int = 900, threshold_bps: float = 5.0):
+        """Initialize history.
+
+        Args:
+            window_sec: Window the trend is measured over
+            threshold_bps: Drift beyond which the POC is "up" or "down"
+        """
+        self.window_ms = window_sec * 1000
+        self.threshold_bps = threshold_bps
+        self._samples: deque[tuple[int, float]] = deque()
+
+    def record(self, ts_ms: int, poc: float) -> None:
+        self._samples.append((ts_ms, poc))
+        while self._samples and self._samples[0][0] < ts_ms - self.window_ms:
+            self._samples.popleft()
+
+    def drift_bps(self) -> Optional[float]:
+        """POC drift across the window from a least-squares fit (None below 3 samples).
+
+        The fitted line smooths single-cycle POC jumps between adjacent
+        high-volume bins, which a first-to-last difference would report.
+        """
+        n = len(self._samples)
+        if n < 3:
+            return None
+        t0 = self._samples[0][0]
+        xs = [ts - t0 for ts, _ in self._samples]
+        ys = [poc for _, poc in self._samples]
+        mean_x, mean_y = sum(xs) / n, sum(ys) / n
+        var_x = sum((x - mean_x) ** 2 for x in xs)
+        if var_x == 0 or mean_y <= 0:
+            return 0.0
+        slope = sum((x - mean_x) * (y - mean_y) for x, y in zip(xs, ys)) / var_x
+        return slope * (xs[-1] - xs[0]) / mean_y * 10_000
+
+    def trend(self) -> Optional[str]:
+        """Drift direction ("up", "down" or "stable"), or None without enough samples."""
+        drift = self.drift_bps()
+        if drift is None:
+            return None
+        if drift >= self.threshold_bps:
+            return "up"
+        if drift <= -self.threshold_bps:
+            return "down"
+        return "stable"
+
+
+class SessionProfile:
+    """Volume by price since the start of the current UTC session (day)."""
+
+    def __init__(self):
+        self.session_start: Optional[datetime] = None
+        self.bin_size = 0.0
+        self.trade_count = 0
+        self._volume: dict[int, float] = defaultdict(float)
+
+    def add(self, timestamp: datetime, price: float, volume: float) -> None:
+        session_start = timestamp.astimezone(timezone.utc).replace(hour=0, minute=0, second=0, microsecond=0)
+        if session_start != self.session_start:
+            # New session: bins are sized from its first price
+            self.session_start = session_start
+            self.bin_size = price * SESSION_BIN_BPS / 10_000
+            self.trade_count = 0
+            self._volume.clear()
+        self._volume[int(price // self.bin_size)] += volume
+        self.trade_count += 1
+
+    def value_area(self, min_trades: int = 10) -> Optional[dict]:
+        """Developing POC/VAH/VAL of the session, or None below min_trades."""
+        if self.trade_count < min_trades or not self._volume:
+            return None
+
+        low, high = min(self._volume), max(self._volume)
+        hist = [self._volume.get(i, 0.0) for i in range(low, high + 1)]
+        poc_idx = max(range(len(hist)), key=hist.__getitem__)
+
+        # Expand from the POC toward the heavier side until 70% of volume is covered
+        target = sum(hist) * VALUE_AREA_FRACTION
+        left = right = poc_idx
+        accumulated = hist[poc_idx]
+        while accumulated < target:
+            left_volume = hist[left - 1] if left > 0 else 0.0
+            right_volume = hist[right + 1] if right < len(hist) - 1 else 0.0
+            if left_volume >= right_volume and left > 0:
+                left -= 1
+                accumulated += hist[left]
+            elif right < len(hist) - 1:
+                right += 1
+                accumulated += hist[right]
+            else:
+                break
+
+        return {
+            "POC": round((low + poc_idx + 0.5) * self.bin_size, 8),
+            "VAH": round((low + right + 1) * self.bin_size, 8),
+            "VAL": round((low + left) * self.bin_size, 8),
+            "session_start": self.session_start.isoformat().replace('+00:00', 'Z'),
+            "trade_count": self.trade_count,
+        }
@@ -0,0 +1,105 @@
+"""Retention management for the market event stream.
+
+XADD already caps the stream by length; a StreamTrimmer additionally runs
+XTRIM on an interval, either by MINID (drop entries older than the
+retention duration) or by MAXLEN, so the stream stays bounded even when
+publishing pauses or a writer without inline trimming appends to it.
+"""
+import threading
+import time
+from typing import Optional
+
+import redis
+import structlog
+
+from src.event_bus import StreamLog
+
+log = structlog.get_logger()
+
+
+class StreamTrimmer:
+    """Periodically trims a Redis Stream by age (MINID) or length (MAXLEN)."""
+
+    def __init__(
+        self,
+        redis_client: StreamLog,
+        stream_key: str,
+        maxlen: int = 100000,
+        retention_sec: int = 0,
+        interval_sec: float = 60.0,
+        metrics=None,
+    ):
+        """Initialize trimmer.
+
+        Args:
+            redis_client: Redis client instance
+            stream_key: Stream to trim (e.g., nt:binance)
+            maxlen: Approximate maximum entries (used when retention_sec is 0)
+            retention_sec: Keep entries newer than this many seconds (0 = trim by maxlen)
+            interval_sec: Seconds between trims
+            metrics: Optional PrometheusMetrics for trim stats
+        """
+        self.redis_client = redis_client
+        self.stream_key = stream_key
+        self.maxlen = maxlen
+        self.retention_sec = retention_sec
+        self.interval_sec = interval_sec
+        self.metrics = metrics
+
+        self._stop = threading.Event()
+        self._thread: Optional[threading.Thread] = None
+        self.log = log.bind(component="stream_trimmer", stream_key=stream_key)
+
+    def trim_once(self) -> int:
+        """Trim the stream once.
+
+        Returns:
+            Number of entries removed
+        """
+        start = time.perf_counter()
+        if self.retention_sec:
+            min_id = f"{int(time.time() * 1000) - self.retention_sec * 1000}-0"
+            trimmed = self.redis_client.xtrim(self.stream_key, minid=min_id, approximate=True)
+        else:
+            trimmed = self.redis_client.xtrim(self.stream_key, maxlen=self.maxlen, approximate=True)
+        length = self.redis_client.xlen(self.stream_key)
+        elapsed_ms = (time.perf_counter() - start) * 1000
+
+        if self.metrics:
+            self.metrics.stream_trimmed.labels(stream=self.stream_key).inc(trimmed)
+            self.metrics.stream_length.labels(stream=self.stream_key).set(length)
+            self.metrics.stream_last_trim.labels(stream=self.stream_key).set(time.time())
+
+        self.log.info(
+            "stream_trimmed",
+            policy="minid" if self.retention_sec else "maxlen",
+            trimmed=trimmed,
+            length=length,
+            latency_ms=round(elapsed_ms, 2),
+        )
+        return trimmed
+
+    def _run(self) -> None:
+        while not self._stop.wait(self.interval_sec):
+            try:
+                self.trim_once()
+            except redis.RedisError as e:
+                self.log.warning("stream_trim_failed", error=str(e))
+
+    def start(self) -> None:
+        """Start trimming in a background thread."""
+        self._thread = threading.Thread(target=self._run, name="stream-trimmer", daemon=True)
+        self._thread.start()
+        self.log.info(
+            "stream_trimmer_started",
+            maxlen=self.maxlen,
+            retention_sec=self.retention_sec,
+            interval_sec=self.interval_sec,
+        )
+
+    def stop(self) -> None:
+        """Stop the background thread."""
+        self._stop.set()
+        if self._thread:
+            self._thread.join(timeout=5)
+            self._thread = None
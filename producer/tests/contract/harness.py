@@ -0,0 +1,247 @@
+"""Golden report contract harness.
+
+Replays canned market events from fixtures/*.json through SymbolState and
+the fast-cycle report generator on a frozen clock, and compares the emitted
+reports with golden/*.json (floats within a tolerance). Any change to the
+published report contract shows up as a golden diff that has to be
+reviewed and regenerated deliberately.
+
+Fixture format:
+
+    {
+      "symbol": "BTCUSDT",
+      "start": "2026-01-01T00:00:00Z",
+      "events": [
+        {"at_ms": 0, "type": "book", "side": "bid", "price": 100.0, "qty": 1.5},
+        {"at_ms": 5, "type": "trade", "price": 100.5, "qty": 0.2, "side": "BUY"},
+        {"at_ms": 250, "type": "report"}
+      ]
+    }
+
+"book" events set a level (qty 0 removes it), "trade" events add a trade
+and "report" events emit a report. The golden file holds the list of
+emitted reports.
+
+The harness also guards the cross-service contract: a fully populated
+report (each fixture report enriched by the slow cycle) may only contain
+fields present in mcp-server/report_sample.json, which the MCP servers
+check against their GraphQL and OpenAPI schemas (mcp-server/report_contract.py).
+
+Usage (from producer/):
+
+    python -m pytest tests/contract          # compare against golden files
+    python -m tests.contract.harness --update  # regenerate golden files
+"""
+import argparse
+import json
+import math
+import sys
+from contextlib import ExitStack
+from datetime import datetime, timedelta, timezone
+from pathlib import Path
+from typing import Any
+from unittest import mock
+
+from src.reporters.fast_cycle import generate_fast_report
+from src.state.symbol_state import SymbolState, TradeTick
+
+CONTRACT_DIR = Path(__file__).parent
+FIXTURES_DIR = CONTRACT_DIR / "fixtures"
+GOLDEN_DIR = CONTRACT_DIR / "golden"
+REPORT_SAMPLE = CONTRACT_DIR.parents[2] / "mcp-server" / "report_sample.json"
+
+# Modules whose datetime.now() reads the replay clock
+FROZEN_MODULES = (
+    "src.state.symbol_state",
+    "src.state.flow_buckets",
+    "src.state.book_churn",
+    "src.reporters.fast_cycle",
+)
+# Also frozen when replaying with slow-cycle enrichment
+SLOW_FROZEN_MODULES = (
+    "src.reporters.slow_cycle",
+    "src.reporters.detectors",
+    "src.calculators.anomalies",
+)
+
+NODE_ID = "golden"
+WRITER_TOKEN = 1
+
+REL_TOL = 1e-9
+ABS_TOL = 1e-9
+
+
+class ReplayClock:
+    """Clock advanced by fixture event offsets instead of wall time."""
+
+    def __init__(self, start: datetime):
+        self.current = start
+
+    def datetime_class(self) -> type:
+        """datetime subclass whose now() returns the replay time."""
+        clock = self
+
+        class FrozenDatetime(datetime):
+            @classmethod
+            def now(cls, tz=None):
+                return clock.current if tz is None else clock.current.astimezone(tz)
+
+        return FrozenDatetime
+
+
+def fixture_names() -> list[str]:
+    """Names of all fixtures (file stems), sorted."""
+    return sorted(path.stem for path in FIXTURES_DIR.glob("*.json"))
+
+
+def load_fixture(name: str) -> dict[str, Any]:
+    return json.loads((FIXTURES_DIR / f"{name}.json").read_text())
+
+
+def load_golden(name: str) -> list[dict[str, Any]]:
+    return json.loads((GOLDEN_DIR / f"{name}.json").read_text())
+
+
+def replay(fixture: dict[str, Any], enrich: bool = False) -> list[dict[str, Any]]:
+    """Replay fixture events and return the reports emitted by "report" events.
+
+    Args:
+        fixture: Loaded fixture
+        enrich: Also run the slow cycle and enrich each report (requires numpy)
+    """
+    start = datetime.fromisoformat(fixture["start"].replace("Z", "+00:00"))
+    clock = ReplayClock(start)
+    state = SymbolState(fixture["symbol"])
+    reports = []
+
+    with ExitStack() as stack:
+        for module in FROZEN_MODULES + (SLOW_FROZEN_MODULES if enrich else ()):
+            stack.enter_context(mock.patch(f"{module}.datetime", clock.datetime_class()))
+
+        for event in fixture["events"]:
+            clock.current = start + timedelta(milliseconds=event["at_ms"])
+
+            if event["type"] == "book":
+                if event["side"] == "bid":
+                    state.update_order_book_bid(event["price"], event["qty"])
+                else:
+                    state.update_order_book_ask(event["price"], event["qty"])
+            elif event["type"] == "trade":
+                state.add_trade(TradeTick(
+                    timestamp=clock.current,
+                    price=event["price"],
+                    volume=event["qty"],
+                    aggressor_side=event["side"],
+                ))
+            elif event["type"] == "report":
+                report = generate_fast_report(state, node_id=NODE_ID, writer_token=WRITER_TOKEN)
+                if enrich and report:
+                    from src.reporters.slow_cycle import calculate_slow_metrics, enrich_report
+                    report = enrich_report(report, calculate_slow_metrics(state))
+                reports.append(report)
+            else:
+                raise ValueError(f"Unknown fixture event type: {event['type']}")
+
+    return reports
+
+
+def compare(actual: Any, expected: Any, path: str = "$") -> list[str]:
+    """Structural diff of two JSON values with float tolerance.
+
+    Returns:
+        Human-readable differences (empty when equal)
+    """
+    if isinstance(expected, dict) and isinstance(actual, dict):
+        diffs = []
+        for key in sorted(set(expected) | set(actual)):
+            if key not in actual:
+                diffs.append(f"{path}.{key}: missing (expected {expected[key]!r})")
+            elif key not in expected:
+                diffs.append(f"{path}.{key}: unexpected field ({actual[key]!r})")
+            else:
+                diffs.extend(compare(actual[key], expected[key], f"{path}.{key}"))
+        return diffs
+
+    if isinstance(expected, list) and isinstance(actual, list):
+        if len(actual) != len(expected):
+            return [f"{path}: length {len(actual)} != expected {len(expected)}"]
+        diffs = []
+        for i, (a, e) in enumerate(zip(actual, expected)):
+            diffs.extend(compare(a, e, f"{path}[{i}]"))
+        return diffs
+
+    numeric = (int, float)
+    if (isinstance(expected, numeric) and isinstance(actual, numeric)
+            and not isinstance(expected, bool) and not isinstance(actual, bool)):
+        if type(actual) is not type(expected):
+            return [f"{path}: type {type(actual).__name__} != expected {type(expected).__name__}"]
+        if not math.isclose(actual, expected, rel_tol=REL_TOL, abs_tol=ABS_TOL):
+            return [f"{path}: {actual!r} != expected {expected!r}"]
+        return []
+
+    if actual != expected:
+        return [f"{path}: {actual!r} != expected {expected!r}"]
+    return []
+
+
+def unknown_fields(actual: Any, sample: Any, path: str = "$") -> list[str]:
+    """Fields in a report that the shared report sample does not have.
+
+    List elements are checked against the union of the sample elements'
+    fields, since elements of one list (e.g. anomaly types) differ in shape.
+    """
+    if isinstance(actual, dict) and isinstance(sample, dict):
+        problems = []
+        for key, value in actual.items():
+            if key not in sample:
+                problems.append(f"{path}.{key}: not in report sample")
+            else:
+                problems.extend(unknown_fields(value, sample[key], f"{path}.{key}"))
+        return problems
+
+    if isinstance(actual, list) and isinstance(sample, list):
+        merged: dict[str, Any] = {}
+        for item in sample:
+            if isinstance(item, dict):
+                merged.update(item)
+        problems = []
+        for i, item in enumerate(actual):
+            if isinstance(item, dict):
+                problems.extend(unknown_fields(item, merged, f"{path}[{i}]"))
+        return problems
+
+    return []
+
+
+def update_golden(names: list[str]) -> None:
+    """Regenerate golden files from the current code."""
+    GOLDEN_DIR.mkdir(exist_ok=True)
+    for name in names:
+        reports = replay(load_fixture(name))
+        (GOLDEN_DIR / f"{name}.json").write_text(json.dumps(reports, indent=2, sort_keys=True) + "\n")
+        print(f"updated golden/{name}.json ({len(reports)} reports)")
+
+
+def main(argv: list[str] | None = None) -> int:
+    parser = argparse.ArgumentParser(description="Golden report contract harness")
+    parser.add_argument("--update", action="store_true", help="Regenerate golden files")
+    parser.add_argument("fixtures", nargs="*", help="Fixture names (default: all)")
+    args = parser.parse_args(argv)
+    names = args.fixtures or fixture_names()
+
+    if args.update:
+        update_golden(names)
+        return 0
+
+    failed = 0
+    for name in names:
+        diffs = compare(replay(load_fixture(name)), load_golden(name))
+        print(f"{'FAIL' if diffs else 'ok'} {name}")
+        for diff in diffs:
+            print(f"  {diff}")
+        failed += bool(diffs)
+    return 1 if failed else 0
+
+
+if __name__ == "__main__":
+    sys.exit(main())
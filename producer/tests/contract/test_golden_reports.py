@@ -0,0 +1,36 @@
+"""Contract tests: replayed fixtures must reproduce the golden reports."""
+import json
+
+import pytest
+
+from tests.contract.harness import (
+    REPORT_SAMPLE,
+    compare,
+    fixture_names,
+    load_fixture,
+    load_golden,
+    replay,
+    unknown_fields,
+)
+
+
+@pytest.mark.parametrize("name", fixture_names())
+def test_report_matches_golden(name):
+    diffs = compare(replay(load_fixture(name)), load_golden(name))
+    assert not diffs, (
+        f"{name}: report contract changed; review and run "
+        f"`python -m tests.contract.harness --update {name}`\n" + "\n".join(diffs)
+    )
+
+
+@pytest.mark.parametrize("name", fixture_names())
+def test_enriched_report_fits_shared_sample(name):
+    pytest.importorskip("numpy")
+    sample = json.loads(REPORT_SAMPLE.read_text())
+    for report in replay(load_fixture(name), enrich=True):
+        problems = unknown_fields(report, sample)
+        assert not problems, (
+            f"{name}: producer emits fields the MCP servers don't know; add them to "
+            f"mcp-server/report_sample.json and run `python cli.py check-contract` there\n"
+            + "\n".join(problems)
+        )
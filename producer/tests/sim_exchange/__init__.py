@@ -0,0 +1,5 @@
+"""Simulated Binance exchange for hermetic integration tests (see server.py)."""
+from tests.sim_exchange.scenarios import SCENARIOS
+from tests.sim_exchange.server import SimulatedExchange
+
+__all__ = ["SCENARIOS", "SimulatedExchange"]
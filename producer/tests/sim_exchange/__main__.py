@@ -0,0 +1,47 @@
+"""Run the simulated exchange standalone, e.g. for a producer in another process.
+
+Usage (from producer/):
+
+    python -m tests.sim_exchange --scenario gap_resync --port 9443
+    BINANCE_BASE_URL_HTTP=http://127.0.0.1:9443 BINANCE_BASE_URL_WS=ws://127.0.0.1:9443 \
+        SYMBOLS=BTCUSDT python -m src.main
+
+--scenario takes a built-in name or a JSON scenario file. Progress is
+served on /sim/stats; the process exits once the scenario has played
+unless --linger is given.
+"""
+import argparse
+import asyncio
+import json
+
+from tests.sim_exchange.scenarios import SCENARIOS, load
+from tests.sim_exchange.server import SimulatedExchange
+
+
+async def serve(args: argparse.Namespace) -> None:
+    exchange = SimulatedExchange(load(args.scenario, args.symbol), host=args.host, port=args.port)
+    await exchange.start()
+    print(f"Simulated exchange on {exchange.base_url_http} playing {exchange.stats['scenario']}", flush=True)
+    try:
+        while not exchange.stats["done"] or args.linger:
+            await asyncio.sleep(0.2)
+    finally:
+        print(json.dumps(exchange.stats), flush=True)
+        await exchange.stop()
+
+
+def main() -> None:
+    parser = argparse.ArgumentParser(description="Mock Binance spot REST/WebSocket server")
+    parser.add_argument("--scenario", default="steady", help=f"{', '.join(SCENARIOS)} or a JSON file")
+    parser.add_argument("--symbol", default="BTCUSDT", help="Symbol of built-in scenarios")
+    parser.add_argument("--host", default="127.0.0.1")
+    parser.add_argument("--port", type=int, default=9443)
+    parser.add_argument("--linger", action="store_true", help="Keep serving after the scenario ends")
+    try:
+        asyncio.run(serve(parser.parse_args()))
+    except KeyboardInterrupt:
+        pass
+
+
+if __name__ == "__main__":
+    main()
@@ -0,0 +1,109 @@
+"""Scripted market scenarios for the simulated exchange.
+
+A scenario is one symbol and a list of steps played in order:
+
+    {"op": "wait_subscribed"}                     until a client subscribes to the symbol
+    {"op": "snapshot", "bids": [[p, q]], "asks": [[p, q]]}
+                                                  replace the book without sending an update
+                                                  (clients see it through /api/v3/depth)
+    {"op": "depth", "bids": [[p, q]], "asks": [[p, q]]}
+                                                  apply levels (q 0 removes) and send a depthUpdate
+    {"op": "gap", "bids": [...], "asks": [...], "updates": 3}
+                                                  apply levels without sending them: the next
+                                                  depthUpdate skips update ids, as after lost messages
+    {"op": "wait_resync", "timeout_ms": 5000}     until a depth snapshot is fetched after the gap
+    {"op": "trade", "price": p, "qty": q, "buyer_maker": false}
+                                                  send a trade
+    {"op": "sleep", "ms": 250}
+    {"op": "disconnect"}                          drop every WebSocket connection
+
+Prices and quantities are numbers; they are sent as strings like Binance.
+Scenarios can also be loaded from a JSON file with "symbol" and "steps".
+"""
+import json
+from pathlib import Path
+from typing import Any
+
+Scenario = dict[str, Any]
+
+
+def _ladder(best: float, side: int, levels: int = 10, tick: float = 0.5, qty: float = 1.0) -> list[list[float]]:
+    """Levels stepping away from best (side -1 for bids, +1 for asks)."""
+    return [[round(best + side * i * tick, 2), qty] for i in range(levels)]
+
+
+def gap_resync(symbol: str = "BTCUSDT", mid: float = 50000.0) -> Scenario:
+    """Snapshot, a run of deltas, a sequence gap, then deltas after the client resyncs."""
+    bid, ask = mid - 0.25, mid + 0.25
+    steps: list[dict[str, Any]] = [
+        {"op": "snapshot", "bids": _ladder(bid, -1), "asks": _ladder(ask, 1)},
+        {"op": "wait_subscribed"},
+    ]
+    for i in range(5):
+        steps.append({"op": "depth", "bids": [[bid, 1.0 + 0.1 * (i + 1)]], "asks": [[ask, 1.0 - 0.1 * (i + 1)]]})
+        steps.append({"op": "sleep", "ms": 100})
+    steps += [
+        {"op": "trade", "price": ask, "qty": 0.25, "buyer_maker": False},
+        # Lost updates move the touch up a tick; a client applying the next delta on
+        # its stale book would cross it
+        {"op": "gap", "updates": 3, "bids": [[bid, 0], [bid + 0.5, 2.0]], "asks": [[ask, 0], [ask + 0.5, 1.5]]},
+        {"op": "depth", "bids": [[bid + 0.5, 2.5]], "asks": [[ask + 0.5, 1.0]]},
+        {"op": "wait_resync", "timeout_ms": 5000},
+    ]
+    for i in range(5):
+        steps.append({"op": "depth", "bids": [[bid + 0.5, 2.5 + 0.1 * i]], "asks": [[ask + 0.5, 1.0 + 0.1 * i]]})
+        steps.append({"op": "sleep", "ms": 100})
+    return {"name": "gap_resync", "symbol": symbol, "steps": steps}
+
+
+def spoofing(symbol: str = "BTCUSDT", mid: float = 50000.0, pulls: int = 3) -> Scenario:
+    """A large bid shown a few ticks below the touch and pulled without trading, repeatedly."""
+    bid, ask = mid - 0.25, mid + 0.25
+    wall = round(bid - 2.0, 2)
+    steps: list[dict[str, Any]] = [
+        {"op": "snapshot", "bids": _ladder(bid, -1), "asks": _ladder(ask, 1)},
+        {"op": "wait_subscribed"},
+        {"op": "sleep", "ms": 500},
+    ]
+    for _ in range(pulls):
+        steps += [
+            # 8x the average level, gone after 800ms (detect_spoofing: > 2x, <= 2000ms)
+            {"op": "depth", "bids": [[wall, 8.0]], "asks": []},
+            {"op": "sleep", "ms": 800},
+            {"op": "depth", "bids": [[wall, 1.0]], "asks": []},
+            {"op": "trade", "price": ask, "qty": 0.1, "buyer_maker": False},
+            {"op": "sleep", "ms": 400},
+        ]
+    return {"name": "spoofing", "symbol": symbol, "steps": steps}
+
+
+def steady(symbol: str = "BTCUSDT", mid: float = 50000.0, seconds: int = 10) -> Scenario:
+    """A balanced book with small updates and alternating trades every 100ms."""
+    bid, ask = mid - 0.25, mid + 0.25
+    steps: list[dict[str, Any]] = [
+        {"op": "snapshot", "bids": _ladder(bid, -1), "asks": _ladder(ask, 1)},
+        {"op": "wait_subscribed"},
+    ]
+    for i in range(seconds * 10):
+        steps.append({"op": "depth", "bids": [[bid, 1.0 + (i % 5) * 0.1]], "asks": [[ask, 1.0 + (i % 3) * 0.1]]})
+        steps.append({"op": "trade", "price": ask if i % 2 else bid, "qty": 0.05, "buyer_maker": bool(i % 2)})
+        steps.append({"op": "sleep", "ms": 100})
+    return {"name": "steady", "symbol": symbol, "steps": steps}
+
+
+SCENARIOS = {
+    "gap_resync": gap_resync,
+    "spoofing": spoofing,
+    "steady": steady,
+}
+
+
+def load(name_or_path: str, symbol: str = "BTCUSDT") -> Scenario:
+    """A built-in scenario by name, or one read from a JSON file."""
+    if name_or_path in SCENARIOS:
+        return SCENARIOS[name_or_path](symbol)
+    scenario = json.loads(Path(name_or_path).read_text())
+    if not isinstance(scenario.get("symbol"), str) or not isinstance(scenario.get("steps"), list):
+        raise ValueError(f"{name_or_path}: a scenario needs a symbol and a list of steps")
+    scenario.setdefault("name", Path(name_or_path).stem)
+    return scenario
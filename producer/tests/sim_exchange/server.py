@@ -0,0 +1,434 @@
+"""Mock Binance spot REST/WebSocket server playing a scripted scenario.
+
+Serves the subset of the Binance spot API the producer uses, on one port:
+
+- REST: /api/v3/ping, /api/v3/time, /api/v3/exchangeInfo and
+  /api/v3/depth?symbol=&limit= (the current book with its lastUpdateId)
+- WebSocket: /ws, /ws/<stream> (raw payloads) and /stream?streams=a/b
+  (combined {"stream", "data"} payloads), with SUBSCRIBE, UNSUBSCRIBE and
+  LIST_SUBSCRIPTIONS requests. Streams: <symbol>@trade, <symbol>@depth
+  (diff updates, any @100ms suffix), <symbol>@depth<N> (partial book) and
+  <symbol>@bookTicker.
+- /sim/stats: snapshot requests, connections and scenario progress, for
+  tests driving a producer in another process.
+
+Diff updates carry U/u update ids like Binance, so a client following the
+documented book procedure (buffer, fetch snapshot, drop updates older than
+lastUpdateId, resync on a gap) can be checked end to end; see
+scenarios.py for the scripts. Only the standard library is used, so the
+server runs wherever the tests do.
+
+    exchange = SimulatedExchange(scenarios.gap_resync())
+    with exchange.running():
+        ...  # point BINANCE_BASE_URL_HTTP / BINANCE_BASE_URL_WS at exchange.base_url_*
+        exchange.wait_done(timeout=30)
+"""
+import asyncio
+import base64
+import hashlib
+import json
+import struct
+import threading
+import time
+from contextlib import contextmanager
+from typing import Any, Iterator, Optional
+from urllib.parse import parse_qs, urlsplit
+
+_WS_GUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+OP_TEXT, OP_CLOSE, OP_PING, OP_PONG = 0x1, 0x8, 0x9, 0xA
+
+TICK_SIZE = "0.01000000"
+STEP_SIZE = "0.00001000"
+
+
+def _now_ms() -> int:
+    return int(time.time() * 1000)
+
+
+def _fmt(value: float) -> str:
+    return f"{value:.8f}"
+
+
+def _levels(book: dict[float, float], reverse: bool, limit: Optional[int] = None) -> list[list[str]]:
+    prices = sorted(book, reverse=reverse)[:limit]
+    return [[_fmt(price), _fmt(book[price])] for price in prices]
+
+
+def _encode_frame(opcode: int, payload: bytes) -> bytes:
+    """Unmasked server frame."""
+    header = bytes([0x80 | opcode])
+    if len(payload) < 126:
+        header += bytes([len(payload)])
+    elif len(payload) < 1 << 16:
+        header += bytes([126]) + struct.pack("!H", len(payload))
+    else:
+        header += bytes([127]) + struct.pack("!Q", len(payload))
+    return header + payload
+
+
+async def _read_frame(reader: asyncio.StreamReader) -> tuple[int, bytes]:
+    """One client frame (masked); fragmented messages aren't used by the clients we serve."""
+    first, second = await reader.readexactly(2)
+    length = second & 0x7F
+    if length == 126:
+        (length,) = struct.unpack("!H", await reader.readexactly(2))
+    elif length == 127:
+        (length,) = struct.unpack("!Q", await reader.readexactly(8))
+    mask = await reader.readexactly(4) if second & 0x80 else b"\0\0\0\0"
+    payload = bytes(b ^ mask[i % 4] for i, b in enumerate(await reader.readexactly(length)))
+    return first & 0x0F, payload
+
+
+class _Connection:
+    """One WebSocket client and its subscriptions."""
+
+    def __init__(self, writer: asyncio.StreamWriter, combined: bool, streams: set[str]):
+        self.writer = writer
+        self.combined = combined
+        self.streams = streams
+
+    async def send_json(self, message: Any) -> None:
+        self.writer.write(_encode_frame(OP_TEXT, json.dumps(message).encode()))
+        await self.writer.drain()
+
+    async def publish(self, stream: str, data: dict) -> None:
+        if stream in self.streams:
+            await self.send_json({"stream": stream, "data": data} if self.combined else data)
+
+    async def close(self) -> None:
+        try:
+            self.writer.write(_encode_frame(OP_CLOSE, struct.pack("!H", 1001)))
+            await self.writer.drain()
+        except ConnectionError:
+            pass
+        self.writer.close()
+
+
+class SimulatedExchange:
+    """Binance spot look-alike playing one scenario (see scenarios.py)."""
+
+    def __init__(self, scenario: dict[str, Any], host: str = "127.0.0.1", port: int = 0):
+        self.scenario = scenario
+        self.symbol: str = scenario["symbol"].upper()
+        self.host = host
+        self.port = port
+
+        self.bids: dict[float, float] = {}
+        self.asks: dict[float, float] = {}
+        self.update_id = 1000
+        self.trade_id = 5000
+        self.stats = {
+            "scenario": scenario.get("name", "custom"),
+            "step": 0,
+            "steps": len(scenario["steps"]),
+            "snapshot_requests": 0,
+            "depth_updates": 0,
+            "trades": 0,
+            "gaps": 0,
+            "resyncs": 0,
+            "connections": 0,
+            "done": False,
+        }
+
+        self._connections: list[_Connection] = []
+        self._server: Optional[asyncio.Server] = None
+        self._player: Optional[asyncio.Task] = None
+        self._subscribed: Optional[asyncio.Event] = None
+        self._snapshot_fetched: Optional[asyncio.Event] = None
+        self._done = threading.Event()
+
+    # -- lifecycle ----------------------------------------------------------
+
+    @property
+    def base_url_http(self) -> str:
+        return f"http://{self.host}:{self.port}"
+
+    @property
+    def base_url_ws(self) -> str:
+        return f"ws://{self.host}:{self.port}"
+
+    async def start(self) -> None:
+        """Listen and start playing the scenario."""
+        self._subscribed = asyncio.Event()
+        self._snapshot_fetched = asyncio.Event()
+        self._server = await asyncio.start_server(self._handle, self.host, self.port)
+        self.port = self._server.sockets[0].getsockname()[1]
+        self._player = asyncio.create_task(self._play())
+
+    async def stop(self) -> None:
+        if self._player:
+            self._player.cancel()
+        for connection in list(self._connections):
+            await connection.close()
+        if self._server:
+            self._server.close()
+            await self._server.wait_closed()
+
+    @contextmanager
+    def running(self) -> Iterator["SimulatedExchange"]:
+        """Run the exchange on its own event loop thread (for synchronous tests and clients)."""
+        loop = asyncio.new_event_loop()
+        started = threading.Event()
+
+        def run() -> None:
+            asyncio.set_event_loop(loop)
+            loop.run_until_complete(self.start())
+            started.set()
+            loop.run_forever()
+
+        thread = threading.Thread(target=run, name="sim-exchange", daemon=True)
+        thread.start()
+        started.wait(timeout=10)
+        try:
+            yield self
+        finally:
+            asyncio.run_coroutine_threadsafe(self.stop(), loop).result(timeout=10)
+            loop.call_soon_threadsafe(loop.stop)
+            thread.join(timeout=10)
+            loop.close()
+
+    def wait_done(self, timeout: Optional[float] = None) -> bool:
+        """Block until the scenario has played to the end."""
+        return self._done.wait(timeout)
+
+    # -- scenario -------------------------------------------------------------
+
+    async def _play(self) -> None:
+        for index, step in enumerate(self.scenario["steps"]):
+            self.stats["step"] = index + 1
+            await self._run_step(step)
+        self.stats["done"] = True
+        self._done.set()
+
+    async def _run_step(self, step: dict[str, Any]) -> None:
+        op = step["op"]
+        if op == "wait_subscribed":
+            await self._subscribed.wait()
+        elif op == "snapshot":
+            self.bids = {float(p): float(q) for p, q in step.get("bids", [])}
+            self.asks = {float(p): float(q) for p, q in step.get("asks", [])}
+            await self._publish_book()
+        elif op == "depth":
+            first = self.update_id + 1
+            self._apply(step)
+            self.update_id += 1
+            await self._publish_depth(first, step)
+        elif op == "gap":
+            self._apply(step)
+            self.update_id += max(1, int(step.get("updates", 1)))
+            self.stats["gaps"] += 1
+            self._snapshot_fetched.clear()
+        elif op == "wait_resync":
+            try:
+                await asyncio.wait_for(self._snapshot_fetched.wait(), step.get("timeout_ms", 5000) / 1000)
+                self.stats["resyncs"] += 1
+            except asyncio.TimeoutError:
+                pass
+        elif op == "trade":
+            await self._publish_trade(step)
+        elif op == "sleep":
+            await asyncio.sleep(step["ms"] / 1000)
+        elif op == "disconnect":
+            for connection in list(self._connections):
+                await connection.close()
+        else:
+            raise ValueError(f"Unknown scenario step: {op}")
+
+    def _apply(self, step: dict[str, Any]) -> None:
+        for side, book in (("bids", self.bids), ("asks", self.asks)):
+            for price, qty in step.get(side, []):
+                if float(qty) == 0:
+                    book.pop(float(price), None)
+                else:
+                    book[float(price)] = float(qty)
+
+    def _stream(self, name: str) -> str:
+        return f"{self.symbol.lower()}@{name}"
+
+    async def _broadcast(self, streams: list[str], data: dict) -> None:
+        for connection in list(self._connections):
+            for stream in streams:
+                try:
+                    await connection.publish(stream, data)
+                except ConnectionError:
+                    if connection in self._connections:
+                        self._connections.remove(connection)
+                    break
+
+    async def _publish_depth(self, first: int, step: dict[str, Any]) -> None:
+        self.stats["depth_updates"] += 1
+        update = {
+            "e": "depthUpdate",
+            "E": _now_ms(),
+            "s": self.symbol,
+            "U": first,
+            "u": self.update_id,
+            "b": [[_fmt(float(p)), _fmt(float(q))] for p, q in step.get("bids", [])],
+            "a": [[_fmt(float(p)), _fmt(float(q))] for p, q in step.get("asks", [])],
+        }
+        await self._broadcast([self._stream("depth"), self._stream("depth@100ms")], update)
+        await self._publish_book()
+
+    async def _publish_book(self) -> None:
+        """Partial book and book ticker streams, which carry state rather than diffs."""
+        for levels in (5, 10, 20):
+            partial = {
+                "lastUpdateId": self.update_id,
+                "bids": _levels(self.bids, True, levels),
+                "asks": _levels(self.asks, False, levels),
+            }
+            await self._broadcast([self._stream(f"depth{levels}"), self._stream(f"depth{levels}@100ms")], partial)
+        if self.bids and self.asks:
+            bid, ask = max(self.bids), min(self.asks)
+            await self._broadcast([self._stream("bookTicker")], {
+                "u": self.update_id, "s": self.symbol,
+                "b": _fmt(bid), "B": _fmt(self.bids[bid]), "a": _fmt(ask), "A": _fmt(self.asks[ask]),
+            })
+
+    async def _publish_trade(self, step: dict[str, Any]) -> None:
+        self.trade_id += 1
+        self.stats["trades"] += 1
+        now = _now_ms()
+        await self._broadcast([self._stream("trade")], {
+            "e": "trade", "E": now, "s": self.symbol, "t": self.trade_id,
+            "p": _fmt(float(step["price"])), "q": _fmt(float(step["qty"])),
+            "T": now, "m": bool(step.get("buyer_maker", False)), "M": True,
+        })
+
+    # -- HTTP -----------------------------------------------------------------
+
+    async def _handle(self, reader: asyncio.StreamReader, writer: asyncio.StreamWriter) -> None:
+        try:
+            request_line = (await reader.readline()).decode().strip()
+            if not request_line:
+                writer.close()
+                return
+            method, target, _ = request_line.split(" ", 2)
+            headers = {}
+            while (line := (await reader.readline()).decode().strip()):
+                name, _, value = line.partition(":")
+                headers[name.strip().lower()] = value.strip()
+            url = urlsplit(target)
+            query = {k: v[0] for k, v in parse_qs(url.query).items()}
+            if headers.get("upgrade", "").lower() == "websocket":
+                await self._websocket(reader, writer, url.path, query, headers)
+            else:
+                status, body = self._rest(method, url.path, query)
+                payload = json.dumps(body).encode()
+                writer.write(
+                    f"HTTP/1.1 {status}\r\nContent-Type: application/json\r\n"
+                    f"Content-Length: {len(payload)}\r\nConnection: close\r\n\r\n".encode() + payload
+                )
+                await writer.drain()
+                writer.close()
+        except (asyncio.IncompleteReadError, ConnectionError):
+            writer.close()
+
+    def _rest(self, method: str, path: str, query: dict[str, str]) -> tuple[str, Any]:
+        if method != "GET":
+            return "405 Method Not Allowed", {"code": -1000, "msg": f"{method} not supported"}
+        if path == "/api/v3/ping":
+            return "200 OK", {}
+        if path == "/api/v3/time":
+            return "200 OK", {"serverTime": _now_ms()}
+        if path == "/api/v3/exchangeInfo":
+            return "200 OK", self._exchange_info()
+        if path == "/api/v3/depth":
+            if query.get("symbol", "").upper() != self.symbol:
+                return "400 Bad Request", {"code": -1121, "msg": "Invalid symbol."}
+            limit = int(query.get("limit", 100))
+            self.stats["snapshot_requests"] += 1
+            self._snapshot_fetched.set()
+            return "200 OK", {
+                "lastUpdateId": self.update_id,
+                "bids": _levels(self.bids, True, limit),
+                "asks": _levels(self.asks, False, limit),
+            }
+        if path == "/sim/stats":
+            return "200 OK", self.stats
+        return "404 Not Found", {"code": -1, "msg": f"Unknown path {path}"}
+
+    def _exchange_info(self) -> dict[str, Any]:
+        base = self.symbol[:-4] if self.symbol.endswith("USDT") else self.symbol[:-3]
+        return {
+            "timezone": "UTC",
+            "serverTime": _now_ms(),
+            "rateLimits": [],
+            "symbols": [{
+                "symbol": self.symbol,
+                "status": "TRADING",
+                "baseAsset": base,
+                "baseAssetPrecision": 8,
+                "quoteAsset": self.symbol[len(base):],
+                "quotePrecision": 8,
+                "quoteAssetPrecision": 8,
+                "orderTypes": ["LIMIT", "MARKET"],
+                "isSpotTradingAllowed": True,
+                "permissions": ["SPOT"],
+                "filters": [
+                    {"filterType": "PRICE_FILTER", "minPrice": TICK_SIZE, "maxPrice": "1000000.00000000", "tickSize": TICK_SIZE},
+                    {"filterType": "LOT_SIZE", "minQty": STEP_SIZE, "maxQty": "9000.00000000", "stepSize": STEP_SIZE},
+                    {"filterType": "MIN_NOTIONAL", "minNotional": "5.00000000"},
+                ],
+            }],
+        }
+
+    # -- WebSocket --------------------------------------------------------------
+
+    async def _websocket(self, reader, writer, path: str, query: dict[str, str], headers: dict[str, str]) -> None:
+        accept = base64.b64encode(hashlib.sha1((headers["sec-websocket-key"] + _WS_GUID).encode()).digest()).decode()
+        writer.write(
+            "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n"
+            f"Sec-WebSocket-Accept: {accept}\r\n\r\n".encode()
+        )
+        await writer.drain()
+
+        if path.startswith("/stream"):
+            connection = _Connection(writer, True, set(filter(None, query.get("streams", "").split("/"))))
+        else:
+            initial = path[len("/ws/"):] if path.startswith("/ws/") else ""
+            connection = _Connection(writer, False, {initial} if initial else set())
+        self._connections.append(connection)
+        self.stats["connections"] += 1
+        self._check_subscribed(connection)
+
+        try:
+            while True:
+                opcode, payload = await _read_frame(reader)
+                if opcode == OP_CLOSE:
+                    writer.write(_encode_frame(OP_CLOSE, payload[:2]))
+                    await writer.drain()
+                    break
+                if opcode == OP_PING:
+                    writer.write(_encode_frame(OP_PONG, payload))
+                    await writer.drain()
+                elif opcode == OP_TEXT:
+                    await self._ws_request(connection, json.loads(payload))
+        except (asyncio.IncompleteReadError, ConnectionError, json.JSONDecodeError):
+            pass
+        finally:
+            if connection in self._connections:
+                self._connections.remove(connection)
+            writer.close()
+
+    async def _ws_request(self, connection: _Connection, request: dict[str, Any]) -> None:
+        method, params = request.get("method"), request.get("params") or []
+        if method == "SUBSCRIBE":
+            connection.streams.update(params)
+            self._check_subscribed(connection)
+            result = None
+        elif method == "UNSUBSCRIBE":
+            connection.streams.difference_update(params)
+            result = None
+        elif method == "LIST_SUBSCRIPTIONS":
+            result = sorted(connection.streams)
+        else:
+            await connection.send_json({"error": {"code": 2, "msg": f"Invalid request: {method}"}, "id": request.get("id")})
+            return
+        await connection.send_json({"result": result, "id": request.get("id")})
+
+    def _check_subscribed(self, connection: _Connection) -> None:
+        prefix = f"{self.symbol.lower()}@"
+        if any(stream.startswith(prefix) for stream in connection.streams):
+            self._subscribed.set()
@@ -0,0 +1,108 @@
+"""Scenario checks against a client following Binance's local order book procedure.
+
+The client is the documented reference (buffer diffs, fetch a snapshot,
+drop diffs it already covers, refetch on an update id gap), so these tests
+pin what the scenarios put on the wire: a producer that resyncs correctly
+ends with the exchange's book, one that doesn't ends crossed.
+"""
+import json
+import urllib.request
+
+import websocket
+
+from tests.sim_exchange import scenarios
+from tests.sim_exchange.server import SimulatedExchange
+
+
+class ReferenceBookClient:
+    """Local book kept in sync from <symbol>@depth diffs and /api/v3/depth snapshots."""
+
+    def __init__(self, exchange: SimulatedExchange):
+        self.exchange = exchange
+        self.stream = f"{exchange.symbol.lower()}@depth"
+        self.bids: dict[float, float] = {}
+        self.asks: dict[float, float] = {}
+        self.last_update_id = 0
+        self.snapshots = 0
+        self.gaps = 0
+
+    def _snapshot(self) -> None:
+        url = f"{self.exchange.base_url_http}/api/v3/depth?symbol={self.exchange.symbol}&limit=100"
+        with urllib.request.urlopen(url, timeout=5) as response:
+            snapshot = json.load(response)
+        self.bids = {float(p): float(q) for p, q in snapshot["bids"]}
+        self.asks = {float(p): float(q) for p, q in snapshot["asks"]}
+        self.last_update_id = snapshot["lastUpdateId"]
+        self.snapshots += 1
+
+    def _apply(self, update: dict) -> None:
+        for levels, book in ((update["b"], self.bids), (update["a"], self.asks)):
+            for price, qty in levels:
+                if float(qty) == 0:
+                    book.pop(float(price), None)
+                else:
+                    book[float(price)] = float(qty)
+        self.last_update_id = update["u"]
+
+    def on_update(self, update: dict) -> None:
+        if update["u"] <= self.last_update_id:
+            return  # Already in the snapshot
+        if update["U"] > self.last_update_id + 1:
+            self.gaps += 1
+            self._snapshot()
+            if update["u"] <= self.last_update_id:
+                return
+        self._apply(update)
+
+    def run(self, timeout_sec: float = 30) -> None:
+        ws = websocket.create_connection(f"{self.exchange.base_url_ws}/ws", timeout=1)
+        try:
+            ws.send(json.dumps({"method": "SUBSCRIBE", "params": [self.stream], "id": 1}))
+            assert json.loads(ws.recv()) == {"result": None, "id": 1}
+            self._snapshot()
+            waited = 0.0
+            while not self.exchange.wait_done(0) and waited < timeout_sec:
+                try:
+                    message = json.loads(ws.recv())
+                except websocket.WebSocketTimeoutException:
+                    waited += 1
+                    continue
+                if message.get("e") == "depthUpdate":
+                    self.on_update(message)
+        finally:
+            ws.close()
+
+    @property
+    def crossed(self) -> bool:
+        return bool(self.bids and self.asks and max(self.bids) >= min(self.asks))
+
+
+def test_gap_forces_resync_and_ends_on_exchange_book():
+    exchange = SimulatedExchange(scenarios.gap_resync())
+    with exchange.running():
+        client = ReferenceBookClient(exchange)
+        client.run()
+
+    assert exchange.stats["done"]
+    assert exchange.stats["gaps"] == 1
+    assert exchange.stats["resyncs"] == 1
+    assert client.gaps == 1
+    assert client.snapshots == 2
+    assert client.bids == exchange.bids
+    assert client.asks == exchange.asks
+    assert not client.crossed
+
+
+def test_spoofing_shows_and_pulls_the_wall_without_trading_at_it():
+    scenario = scenarios.spoofing(pulls=2)
+    exchange = SimulatedExchange(scenario)
+    with exchange.running():
+        client = ReferenceBookClient(exchange)
+        client.run()
+
+    wall = max(qty for step in scenario["steps"] if step["op"] == "depth" for _, qty in step["bids"])
+    assert exchange.stats["done"]
+    assert exchange.stats["depth_updates"] == 4
+    assert client.gaps == 0
+    assert wall > 2 * 1.0  # Over twice the average level, as detect_spoofing requires
+    assert max(client.bids.values()) == 1.0  # Pulled back to an ordinary level
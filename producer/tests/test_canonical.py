@@ -0,0 +1,81 @@
+"""Canonical report numbers: strings, Decimals and float noise never reach the JSON."""
+import json
+from decimal import Decimal
+
+from src.reporters.canonical import canonical_number, canonicalize_report
+
+
+def _shib_report(**overrides):
+    report = {
+        "schemaVersion": "1.3",
+        "symbol": "1000SHIBUSDT",
+        "meta": {"base_asset": "1000SHIB", "quote_asset": "USDT", "price_precision": 6},
+        "last_price": "0.012345",
+        "best_bid": {"price": Decimal("0.012344"), "qty": "2500000"},
+        "best_ask": {"price": 0.012345000000000001, "qty": 1800000.0},
+        "mid_price": 0.0123445,
+        "spread_bps": 0.1 + 0.2,
+        "data_age_ms": 120,
+    }
+    report.update(overrides)
+    return report
+
+
+def test_tiny_prices_keep_tick_precision():
+    report = canonicalize_report(_shib_report())
+    assert report["last_price"] == 0.012345
+    assert report["best_bid"]["price"] == 0.012344
+    assert report["best_ask"]["price"] == 0.012345
+    assert report["mid_price"] == 0.0123445
+
+
+def test_sub_satoshi_prices_round_trip():
+    # SHIBUSDT trades around 1e-05 with 8 decimals; JSON may use exponent form but stays a number
+    report = canonicalize_report(_shib_report(
+        symbol="SHIBUSDT",
+        meta={"price_precision": 8},
+        last_price="0.00001234",
+        best_bid={"price": 0.000012339999999, "qty": "1e9"},
+    ))
+    decoded = json.loads(json.dumps(report))
+    assert decoded["last_price"] == 0.00001234
+    assert decoded["best_bid"]["price"] == 0.00001234
+    assert decoded["best_bid"]["qty"] == 1e9
+
+
+def test_numeric_strings_become_numbers():
+    report = canonicalize_report(_shib_report())
+    assert isinstance(report["last_price"], float)
+    assert isinstance(report["best_bid"]["qty"], float)
+    assert '"0.012345"' not in json.dumps(report)
+
+
+def test_identifiers_and_versions_stay_strings():
+    report = canonicalize_report(_shib_report())
+    assert report["symbol"] == "1000SHIBUSDT"
+    assert report["meta"]["base_asset"] == "1000SHIB"
+    assert report["schemaVersion"] == "1.3"
+
+
+def test_float_noise_and_integers():
+    report = canonicalize_report(_shib_report())
+    assert report["spread_bps"] == 0.3
+    assert report["data_age_ms"] == 120 and isinstance(report["data_age_ms"], int)
+
+
+def test_unknown_precision_keeps_significant_digits():
+    report = canonicalize_report(_shib_report(meta={"price_precision": None}))
+    assert report["last_price"] == 0.012345
+    assert report["best_ask"]["price"] == 0.012345
+
+
+def test_non_numeric_strings_under_price_keys_are_left_alone():
+    assert canonical_number("n/a", "price", 2) == "n/a"
+    assert canonical_number(None, "price", 2) is None
+    assert canonical_number(True, "qty", 2) is True
+
+
+def test_deterministic_serialization():
+    first = json.dumps(canonicalize_report(_shib_report()), sort_keys=True)
+    second = json.dumps(canonicalize_report(_shib_report(last_price=0.012345000000000002)), sort_keys=True)
+    assert first == second
@@ -0,0 +1,27 @@
+"""Non-finite report values: replaced with null and listed, finite reports untouched."""
+import json
+
+from src.reporters.finite import scrub_non_finite
+
+
+def test_finite_report_is_returned_as_is():
+    report = {"symbol": "BTCUSDT", "spread_bps": 1.5, "depth": {"top20_bid": [{"price": 1.0, "qty": 2.0}]}}
+    scrubbed, fields = scrub_non_finite(report)
+    assert scrubbed is report
+    assert fields == []
+
+
+def test_nan_and_infinity_become_null_and_are_listed():
+    report = {
+        "symbol": "BTCUSDT",
+        "spread_bps": float("nan"),
+        "flow": {"net_flow": float("-inf"), "orders_per_sec": 3.0},
+        "depth": {"top20_bid": [{"price": 1.0, "qty": float("inf")}, {"price": 0.5, "qty": float("nan")}]},
+    }
+    scrubbed, fields = scrub_non_finite(report)
+    assert fields == ["depth.top20_bid[].qty", "flow.net_flow", "spread_bps"]
+    assert scrubbed["spread_bps"] is None
+    assert scrubbed["flow"] == {"net_flow": None, "orders_per_sec": 3.0}
+    assert [level["qty"] for level in scrubbed["depth"]["top20_bid"]] == [None, None]
+    json.dumps(scrubbed, allow_nan=False)
+    assert report["spread_bps"] != report["spread_bps"]  # The input is not modified
@@ -0,0 +1,61 @@
+"""Per-second flow counters: slot reuse, horizon and window edges."""
+from datetime import datetime, timedelta, timezone
+
+from src.state.flow_buckets import FlowBuckets
+
+START = datetime(2026, 1, 1, tzinfo=timezone.utc)
+
+
+def _at(seconds: float) -> datetime:
+    return START + timedelta(seconds=seconds)
+
+
+def test_window_sums_buys_and_sells_of_its_seconds():
+    buckets = FlowBuckets(60)
+    buckets.add(_at(0.1), 1.5, "BUY")
+    buckets.add(_at(0.9), 0.5, "SELL")
+    buckets.add(_at(1.2), 2.0, "BUY")
+    buckets.add(_at(1.3), 9.0, "UNKNOWN")
+
+    totals = buckets.window(10, now=_at(1.5))
+    assert (totals.buy_volume, totals.sell_volume) == (3.5, 0.5)
+    assert (totals.buy_count, totals.sell_count, totals.trade_count) == (2, 1, 3)
+
+
+def test_window_edges_are_whole_seconds_including_the_current_one():
+    buckets = FlowBuckets(60)
+    for second in range(10):
+        buckets.add(_at(second + 0.5), 1.0, "BUY")
+
+    # Seconds 5..9 for a 5s window ending in second 9
+    assert buckets.window(5, now=_at(9.0)).buy_count == 5
+    assert buckets.window(5, now=_at(9.999)).buy_count == 5
+    # Second 10 has no trades yet, so the window keeps only 6..9
+    assert buckets.window(5, now=_at(10.0)).buy_count == 4
+
+
+def test_slot_is_reset_when_reused_for_a_later_second():
+    buckets = FlowBuckets(10)
+    buckets.add(_at(3), 5.0, "SELL")
+    buckets.add(_at(13), 1.0, "SELL")  # Same slot, one horizon later
+
+    totals = buckets.window(10, now=_at(13))
+    assert (totals.sell_volume, totals.sell_count) == (1.0, 1)
+
+
+def test_trades_older_than_the_horizon_are_ignored():
+    buckets = FlowBuckets(10)
+    buckets.add(_at(13), 1.0, "BUY")
+    buckets.add(_at(3), 5.0, "BUY")  # Slot already holds second 13
+
+    assert buckets.window(10, now=_at(13)).buy_volume == 1.0
+
+
+def test_window_is_capped_at_the_horizon_and_skips_expired_seconds():
+    buckets = FlowBuckets(10)
+    for second in range(30):
+        buckets.add(_at(second), 1.0, "BUY")
+
+    assert buckets.window(300, now=_at(29)).buy_count == 10
+    # 25 seconds later every slot is older than the window
+    assert buckets.window(10, now=_at(54)).trade_count == 0
@@ -0,0 +1,37 @@
+"""Report publish queue: one pending report per symbol and cycle."""
+from src.event_bus import get_memory_bus
+from src.reporters.publish_queue import ReportPublishQueue
+from src.reporters.redis_cache import decode_report
+
+NOW_MS = 1767225600000
+
+
+def _fast(mid: float) -> dict:
+    return {"symbol": "BTCUSDT", "updatedAt": NOW_MS, "mid_price": mid, "anomalies": []}
+
+
+def _slow() -> dict:
+    return {"symbol": "BTCUSDT", "updatedAt": NOW_MS, "analytics": {"vpin": 0.4}, "slow_cycle_updated_at": NOW_MS}
+
+
+def test_fast_report_does_not_supersede_a_pending_slow_report():
+    bus = get_memory_bus("publish-queue-cycles")
+    queue = ReportPublishQueue(bus, workers=1, flush_ms=50)
+    assert queue.submit("BTCUSDT", _slow())
+    assert queue.submit("BTCUSDT", _fast(50000.0))
+    queue.close()
+
+    report = decode_report(bus.hgetall("report:BTCUSDT"))
+    assert report["mid_price"] == 50000.0
+    assert report["analytics"] == {"vpin": 0.4}
+    assert report["slow_cycle_updated_at"] == NOW_MS
+
+
+def test_newer_report_of_the_same_cycle_supersedes_the_pending_one():
+    bus = get_memory_bus("publish-queue-supersede")
+    queue = ReportPublishQueue(bus, workers=1, flush_ms=50)
+    assert queue.submit("BTCUSDT", _fast(50000.0))
+    assert not queue.submit("BTCUSDT", _fast(50010.0))
+    queue.close()
+
+    assert decode_report(bus.hgetall("report:BTCUSDT"))["mid_price"] == 50010.0
@@ -0,0 +1,59 @@
+"""Quantile sketch: relative accuracy, time decay and ignored samples."""
+import math
+import random
+
+import pytest
+
+from src.state.quantile_sketch import QuantileSketch
+
+
+def test_quantiles_are_within_relative_accuracy():
+    rng = random.Random(7)
+    values = [rng.lognormvariate(0, 2) for _ in range(5000)]
+    sketch = QuantileSketch(relative_accuracy=0.01)
+    for value in values:
+        sketch.add(value, now=0.0)
+
+    ordered = sorted(values)
+    for q in (0.01, 0.25, 0.5, 0.75, 0.95, 0.99, 1.0):
+        exact = ordered[max(0, math.ceil(q * len(ordered)) - 1)]
+        assert sketch.quantile(q) == pytest.approx(exact, rel=0.01)
+
+
+def test_sample_weight_halves_every_half_life():
+    sketch = QuantileSketch(half_life_sec=60.0)
+    sketch.add(1.0, now=0.0)
+    sketch.add(1.0, now=0.0)
+    sketch.add(100.0, now=60.0)
+
+    # One half-life later a single new sample weighs as much as both old ones
+    assert sketch.quantile(0.5) == pytest.approx(1.0, rel=0.01)
+    assert sketch.quantile(0.51) == pytest.approx(100.0, rel=0.01)
+
+
+def test_old_samples_fade_out_of_the_distribution():
+    sketch = QuantileSketch(half_life_sec=60.0)
+    for _ in range(1000):
+        sketch.add(1.0, now=0.0)
+    sketch.add(100.0, now=1200.0)
+
+    assert sketch.quantile(0.01) == pytest.approx(100.0, rel=0.01)
+    assert sketch.sample_count == 1001
+
+
+def test_long_gaps_rescale_instead_of_overflowing():
+    sketch = QuantileSketch(half_life_sec=1.0)
+    sketch.add(5.0, now=0.0)
+    sketch.add(50.0, now=10_000.0)
+
+    assert sketch.quantile(0.0) == pytest.approx(50.0, rel=0.01)
+    assert len(sketch) == 1
+
+
+@pytest.mark.parametrize("value", [0.0, -1.0, math.nan, math.inf])
+def test_non_positive_and_non_finite_values_are_ignored(value: float):
+    sketch = QuantileSketch()
+    sketch.add(value, now=0.0)
+
+    assert sketch.quantile(0.5) is None
+    assert sketch.sample_count == 0
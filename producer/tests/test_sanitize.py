@@ -0,0 +1,118 @@
+"""Envelope sanitation: fuzzed payloads never raise, and nothing absurd gets through."""
+import json
+import math
+import random
+
+from src.sanitize import MAX_PRICE, MAX_QTY, Quarantine, sanitize_envelope
+
+ABSURD = [float("nan"), float("inf"), float("-inf"), 1e308, -1e308, -1.0, 0.0, "NaN", "1e308", "-5", "abc", "", True, None, [], {}]
+
+
+def _trade(**payload):
+    envelope = {
+        "symbol": "BTCUSDT",
+        "venue": "BINANCE",
+        "type": "trade_tick",
+        "ts_event": "2026-10-16T12:00:00.000125Z",
+        "payload": {"price": "50000.10", "size": "0.25", "aggressor_side": "BUYER", "trade_id": "1"},
+    }
+    envelope["payload"].update(payload)
+    return envelope
+
+
+def _deltas(*levels):
+    return {
+        "symbol": "BTCUSDT",
+        "venue": "BINANCE",
+        "type": "order_book_deltas",
+        "ts_event": "2026-10-16T12:00:00Z",
+        "payload": {"deltas": [{"side": "BUY", "action": "UPDATE", "price": p, "size": q} for p, q in levels]},
+    }
+
+
+def _mutate(rng, value, depth=0):
+    roll = rng.random()
+    if roll < 0.2:
+        return rng.choice(ABSURD)
+    if isinstance(value, dict) and depth < 3:
+        value = {key: _mutate(rng, item, depth + 1) if rng.random() < 0.3 else item for key, item in value.items()}
+        if rng.random() < 0.2 and value:
+            value.pop(rng.choice(list(value)))
+        if rng.random() < 0.2:
+            value[f"extra_{rng.randint(0, 9)}"] = rng.choice(ABSURD)
+        return value
+    if isinstance(value, list) and depth < 3:
+        return [_mutate(rng, item, depth + 1) if rng.random() < 0.3 else item for item in value]
+    return value
+
+
+def test_valid_event_passes_unchanged_with_unknown_fields():
+    envelope = {**_trade(maker_order_id="x", extra={"nested": 1}), "region": "eu"}
+    clean, problems = sanitize_envelope(envelope)
+    assert problems == []
+    assert clean == envelope
+
+
+def test_non_finite_unknown_field_is_rejected():
+    clean, problems = sanitize_envelope(_trade(extra={"nested": [float("inf")]}))
+    assert clean is None
+    assert problems == ["payload.extra:non_finite"]
+
+
+def test_absurd_trade_values_are_rejected():
+    for price, reason in [(float("nan"), "non_finite"), ("1e308", "out_of_range"), ("-1", "non_positive"), ("x", "not_a_number")]:
+        clean, problems = sanitize_envelope(_trade(price=price))
+        assert clean is None
+        assert problems == [f"payload.price:{reason}"]
+    clean, problems = sanitize_envelope(_trade(size="-0.5"))
+    assert clean is None and problems == ["payload.size:negative"]
+
+
+def test_bad_book_levels_are_dropped_and_the_rest_kept():
+    clean, problems = sanitize_envelope(_deltas(("50000", "1"), ("nan", "1"), ("49999", "-2"), ("49998", "0")))
+    assert [d["price"] for d in clean["payload"]["deltas"]] == ["50000", "49998"]
+    assert problems == ["payload.deltas[1]:non_finite", "payload.deltas[2]:negative"]
+
+
+def test_fuzzed_envelopes_never_raise_and_accepted_values_are_sane():
+    rng = random.Random(4479)
+    accepted = 0
+    for _ in range(5000):
+        base = _trade() if rng.random() < 0.5 else _deltas(("50000", "1"), ("49999.5", "2"))
+        clean, problems = sanitize_envelope(_mutate(rng, base))
+        if clean is None:
+            assert problems
+            continue
+        accepted += 1
+        json.dumps(clean, allow_nan=False)
+        for name in ("price", "size"):
+            for item in clean["payload"].get("deltas", [clean["payload"]]):
+                if name in item:
+                    number = float(item[name])
+                    assert math.isfinite(number)
+                    assert (0 < number <= MAX_PRICE) if name == "price" else (0 <= number <= MAX_QTY)
+    assert accepted > 500
+
+
+class _Stream:
+    def __init__(self):
+        self.entries = []
+
+    def xadd(self, name, fields, maxlen=None, approximate=True):
+        self.entries.append((name, fields))
+
+
+def test_quarantine_entry_is_valid_json():
+    stream = _Stream()
+    envelope = _trade(price=float("nan"))
+    clean, problems = sanitize_envelope(envelope)
+    Quarantine(stream, "publisher", stream="dlq:test").put(envelope, problems, symbol="BTCUSDT", event_type="trade_tick")
+    name, fields = stream.entries[0]
+    entry = json.loads(fields["data"], parse_constant=_reject_constant)
+    assert name == "dlq:test"
+    assert entry["event"]["payload"]["price"] == "nan"
+    assert entry["problems"] == ["payload.price:non_finite"]
+
+
+def _reject_constant(constant):
+    raise AssertionError(f"{constant} in a DLQ entry")
@@ -0,0 +1,57 @@
+"""Threshold rules of watch registrations: crossings, re-arming and expiry."""
+import json
+
+from src.event_bus import get_memory_bus
+from src.reporters.thresholds import EVENTS_PREFIX, REGISTRY_KEY, ThresholdDetector
+
+NOW_MS = 1767225600000
+
+
+def _detector(name, *rules, expires_at=NOW_MS + 60000):
+    bus = get_memory_bus(f"thresholds-{name}")
+    bus.hset(REGISTRY_KEY, "w1", json.dumps({"symbols": ["BTCUSDT"], "rules": list(rules), "expires_at": expires_at}))
+    detector = ThresholdDetector(bus, signals_maxlen=100)
+    detector.refresh(now_ms=NOW_MS)
+    return bus, detector
+
+
+def _report(mid=50000.0, imbalance=0.0, health=90):
+    return {"symbol": "BTCUSDT", "venue": "BINANCE", "mid_price": mid, "depth": {"imbalance": imbalance}, "health": {"score": health}}
+
+
+def _crossings(detector, *reports):
+    return [
+        [(e["field"], e["direction"]) for e in detector.process(report, now_ms=NOW_MS)]
+        for report in reports
+    ]
+
+
+def test_price_cross_fires_each_way_after_the_first_report():
+    bus, detector = _detector("price", {"type": "price_cross", "level": 50000})
+    assert _crossings(detector, _report(mid=49990), _report(mid=49995), _report(mid=50001), _report(mid=49000)) == [
+        [], [], [("mid_price", "up")], [("mid_price", "down")],
+    ]
+    assert bus.xlen(f"{EVENTS_PREFIX}w1") == 2
+    assert bus.xlen("signals:BINANCE") == 2
+
+
+def test_imbalance_flip_ignores_values_near_zero():
+    _, detector = _detector("flip", {"type": "imbalance_flip", "min_abs": 0.1})
+    assert _crossings(detector, _report(imbalance=0.3), _report(imbalance=-0.05), _report(imbalance=-0.2)) == [
+        [], [], [("depth.imbalance", "down")],
+    ]
+
+
+def test_health_below_rearms_only_after_recovering():
+    _, detector = _detector("health", {"type": "health_below", "threshold": 50})
+    assert _crossings(detector, _report(health=60), _report(health=40), _report(health=30), _report(health=55), _report(health=45)) == [
+        [], [("health.score", "down")], [], [], [("health.score", "down")],
+    ]
+
+
+def test_expired_and_invalid_registrations_are_deleted():
+    bus, detector = _detector("expired", {"type": "price_cross", "level": 50000}, expires_at=NOW_MS - 1)
+    bus.hset(REGISTRY_KEY, "bad", json.dumps({"symbols": ["BTCUSDT"], "rules": [{"type": "nope"}], "expires_at": NOW_MS + 60000}))
+    detector.refresh(now_ms=NOW_MS)
+    assert bus.hgetall(REGISTRY_KEY) == {}
+    assert _crossings(detector, _report(mid=49990), _report(mid=50010)) == [[], []]